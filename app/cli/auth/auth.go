@@ -30,11 +30,20 @@ func MustResolveAuth(requireOrg bool) {
 		term.OutputErrorAndExit("error resolving auth: api client not set")
 	}
 
+	if UsingEnvToken() {
+		mustResolveEnvTokenAuth()
+		return
+	}
+
 	// load HomeAuthPath file into ClientAuth struct
 	bytes, err := os.ReadFile(fs.HomeAuthPath)
 
 	if err != nil {
 		if os.IsNotExist(err) {
+			if term.JsonMode {
+				outputAuthErrorAndExit("not signed in—run 'plandex sign-in' or set %s", TokenEnvVar)
+			}
+
 			err = promptInitialAuth()
 
 			if err != nil {
@@ -91,6 +100,17 @@ func RefreshInvalidToken() error {
 	if Current == nil {
 		return fmt.Errorf("error refreshing token: auth not loaded")
 	}
+
+	// access tokens can't be refreshed by signing in again
+	if UsingEnvToken() {
+		outputAuthErrorAndExit("%s is invalid, expired, or revoked", TokenEnvVar)
+	}
+
+	// signing in again needs a pin from the user
+	if term.JsonMode {
+		outputAuthErrorAndExit("session expired—run 'plandex sign-in' or set %s", TokenEnvVar)
+	}
+
 	res, err := verifyEmail(Current.Email, Current.Host)
 
 	if err != nil {
@@ -110,3 +130,11 @@ func RefreshInvalidToken() error {
 
 	return nil
 }
+
+// with --json, auth failures exit with the invalid token exit code so automation can tell them apart
+func outputAuthErrorAndExit(msg string, args ...interface{}) {
+	if term.JsonMode {
+		term.OutputJsonErrorAndExit(string(shared.ApiErrorTypeInvalidToken), fmt.Sprintf(msg, args...))
+	}
+	term.OutputErrorAndExit(msg, args...)
+}
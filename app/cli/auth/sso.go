@@ -0,0 +1,245 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"plandex-cli/term"
+	"runtime"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/pkg/browser"
+)
+
+const ssoBrowserTimeout = 5 * time.Minute
+
+var errNoBrowser = errors.New("no browser available")
+
+// SignInWithSso signs in through the org's OIDC provider. It uses the authorization code flow with a browser when one is available, and falls back to the device flow otherwise (or when useDevice is set).
+func SignInWithSso(useDevice bool) error {
+	host, err := term.GetRequiredUserStringInput("Host:")
+	if err != nil {
+		return fmt.Errorf("error prompting host: %v", err)
+	}
+
+	email, err := term.GetRequiredUserStringInput("Your work email:")
+	if err != nil {
+		return fmt.Errorf("error prompting email: %v", err)
+	}
+
+	var res *shared.SessionResponse
+
+	if !useDevice && hasBrowser() {
+		res, err = ssoBrowserSignIn(email, host)
+		if errors.Is(err, errNoBrowser) {
+			fmt.Println("Couldn't open a browser—signing in with a device code instead")
+			fmt.Println()
+		} else if err != nil {
+			return err
+		}
+	}
+
+	if res == nil {
+		res, err = ssoDeviceSignIn(email, host)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = handleSignInResponse(res, host)
+	if err != nil {
+		return err
+	}
+
+	if !term.IsRepl {
+		term.PrintCmds("", "")
+	}
+
+	return nil
+}
+
+// there's no way to open a browser over ssh or on a linux machine without a display
+func hasBrowser() bool {
+	if os.Getenv("SSH_CONNECTION") != "" || os.Getenv("SSH_TTY") != "" {
+		return false
+	}
+	if runtime.GOOS == "linux" && os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+		return false
+	}
+	return true
+}
+
+type ssoCallback struct {
+	code string
+	err  error
+}
+
+func ssoBrowserSignIn(email, host string) (*shared.SessionResponse, error) {
+	codeVerifier, err := shared.GetRandomAlphanumeric(64)
+	if err != nil {
+		return nil, fmt.Errorf("error generating code verifier: %v", err)
+	}
+	state, err := shared.GetRandomAlphanumeric(32)
+	if err != nil {
+		return nil, fmt.Errorf("error generating state: %v", err)
+	}
+	nonce, err := shared.GetRandomAlphanumeric(32)
+	if err != nil {
+		return nil, fmt.Errorf("error generating nonce: %v", err)
+	}
+
+	// the provider redirects back to this loopback server with the authorization code
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("error starting callback server: %v", err)
+	}
+	redirectUri := fmt.Sprintf("http://127.0.0.1:%d/callback", listener.Addr().(*net.TCPAddr).Port)
+
+	callbackCh := make(chan ssoCallback, 1)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/callback", func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+
+		var cb ssoCallback
+		if q.Get("state") != string(state) {
+			cb.err = fmt.Errorf("invalid state in sso callback")
+		} else if q.Get("error") != "" {
+			cb.err = fmt.Errorf("sso provider returned an error: %s %s", q.Get("error"), q.Get("error_description"))
+		} else if q.Get("code") == "" {
+			cb.err = fmt.Errorf("sso callback is missing a code")
+		} else {
+			cb.code = q.Get("code")
+		}
+
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if cb.err == nil {
+			fmt.Fprint(w, "<html><body><h3>Signed in to Plandex</h3><p>You can close this tab and return to your terminal.</p></body></html>")
+		} else {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, "<html><body><h3>Sign in failed</h3><p>Check your terminal for details.</p></body></html>")
+		}
+
+		select {
+		case callbackCh <- cb:
+		default:
+		}
+	})
+
+	server := &http.Server{Handler: mux}
+	go server.Serve(listener)
+	defer server.Close()
+
+	term.StartSpinner("")
+	startRes, apiErr := apiClient.StartSso(shared.StartSsoRequest{
+		Email:         email,
+		RedirectUri:   redirectUri,
+		State:         string(state),
+		CodeChallenge: shared.SsoCodeChallenge(string(codeVerifier)),
+		Nonce:         string(nonce),
+	}, host)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		return nil, fmt.Errorf("error starting sso sign in: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("🔐 Signing in to %s with SSO\n\n", color.New(color.Bold, term.ColorHiCyan).Sprint(startRes.OrgName))
+	fmt.Printf("If your browser doesn't open automatically, use this URL:\n%s\n\n", startRes.AuthorizationUrl)
+
+	err = browser.OpenURL(startRes.AuthorizationUrl)
+	if err != nil {
+		return nil, errNoBrowser
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ssoBrowserTimeout)
+	defer cancel()
+
+	term.StartSpinner("Waiting for sign in to finish in your browser...")
+	var cb ssoCallback
+	select {
+	case cb = <-callbackCh:
+	case <-ctx.Done():
+		cb.err = fmt.Errorf("timed out waiting for sso sign in")
+	}
+	term.StopSpinner()
+
+	if cb.err != nil {
+		return nil, cb.err
+	}
+
+	term.StartSpinner("")
+	res, apiErr := apiClient.SsoSignIn(shared.SsoSignInRequest{
+		Email:        email,
+		Code:         cb.code,
+		CodeVerifier: string(codeVerifier),
+		RedirectUri:  redirectUri,
+		Nonce:        string(nonce),
+	}, host)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		return nil, fmt.Errorf("error signing in with sso: %v", apiErr.Msg)
+	}
+
+	return res, nil
+}
+
+func ssoDeviceSignIn(email, host string) (*shared.SessionResponse, error) {
+	term.StartSpinner("")
+	deviceRes, apiErr := apiClient.StartSsoDevice(shared.StartSsoDeviceRequest{
+		Email: email,
+	}, host)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		return nil, fmt.Errorf("error starting sso device sign in: %v", apiErr.Msg)
+	}
+
+	fmt.Printf("🔐 Signing in to %s with SSO\n\n", color.New(color.Bold, term.ColorHiCyan).Sprint(deviceRes.OrgName))
+	if deviceRes.VerificationUriComplete != "" {
+		fmt.Printf("On any device, go to:\n%s\n\n", deviceRes.VerificationUriComplete)
+		fmt.Printf("Or go to %s and enter the code %s\n\n", deviceRes.VerificationUri, color.New(color.Bold, term.ColorHiGreen).Sprint(deviceRes.UserCode))
+	} else {
+		fmt.Printf("On any device, go to %s and enter the code %s\n\n", deviceRes.VerificationUri, color.New(color.Bold, term.ColorHiGreen).Sprint(deviceRes.UserCode))
+	}
+
+	interval := time.Duration(deviceRes.Interval) * time.Second
+	expiresIn := time.Duration(deviceRes.ExpiresIn) * time.Second
+	if expiresIn == 0 {
+		expiresIn = ssoBrowserTimeout
+	}
+	deadline := time.Now().Add(expiresIn)
+
+	term.StartSpinner("Waiting for you to approve the sign in...")
+	defer term.StopSpinner()
+
+	for time.Now().Before(deadline) {
+		time.Sleep(interval)
+
+		pollRes, apiErr := apiClient.PollSsoDevice(shared.SsoDevicePollRequest{
+			Email:      email,
+			DeviceCode: deviceRes.DeviceCode,
+		}, host)
+
+		if apiErr != nil {
+			return nil, fmt.Errorf("error signing in with sso: %v", apiErr.Msg)
+		}
+
+		switch pollRes.Status {
+		case shared.SsoDeviceStatusComplete:
+			return pollRes.Session, nil
+		case shared.SsoDeviceStatusSlowDown:
+			// RFC 8628: back off by 5 seconds each time
+			interval += 5 * time.Second
+		}
+	}
+
+	return nil, fmt.Errorf("the device code expired before sign in was approved")
+}
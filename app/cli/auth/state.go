@@ -91,6 +91,11 @@ func writeCurrentAuth() error {
 		return fmt.Errorf("error writing auth: auth not loaded")
 	}
 
+	// never persist an access token from the environment
+	if UsingEnvToken() {
+		return nil
+	}
+
 	bytes, err := json.Marshal(Current)
 
 	if err != nil {
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"os"
+	"plandex-cli/term"
+
+	shared "plandex-shared"
+)
+
+// TokenEnvVar holds a personal access token for non-interactive auth (like in CI). When it's set, it takes precedence over signed in accounts.
+const TokenEnvVar = "PLANDEX_TOKEN"
+
+// ApiHostEnvVar points PLANDEX_TOKEN auth at a self-hosted server. Plandex Cloud is used if it's not set.
+const ApiHostEnvVar = "PLANDEX_API_HOST"
+
+func UsingEnvToken() bool {
+	return os.Getenv(TokenEnvVar) != ""
+}
+
+// mustResolveEnvTokenAuth authenticates with PLANDEX_TOKEN. Access tokens belong to a single org, so the org comes from the server rather than a prompt, and nothing is written to disk.
+func mustResolveEnvTokenAuth() {
+	token := os.Getenv(TokenEnvVar)
+
+	if !shared.IsAccessToken(token) {
+		outputAuthErrorAndExit("%s isn't a valid access token—create one with 'plandex tokens create'", TokenEnvVar)
+	}
+
+	host := os.Getenv(ApiHostEnvVar)
+
+	Current = &shared.ClientAuth{
+		ClientAccount: shared.ClientAccount{
+			IsCloud: host == "",
+			Host:    host,
+			Token:   token,
+		},
+	}
+
+	term.StartSpinner("")
+	org, apiErr := apiClient.GetOrgSession()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error authenticating with %s: %v", TokenEnvVar, apiErr.Msg)
+	}
+
+	Current.OrgId = org.Id
+	Current.OrgName = org.Name
+	Current.IntegratedModelsMode = org.IntegratedModelsMode
+}
@@ -45,6 +45,12 @@ func branches(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.JsonMode {
+		term.SetJsonData("branches", branches)
+		term.SetJsonData("currentBranch", lib.CurrentBranch)
+		return
+	}
+
 	table := tablewriter.NewWriter(os.Stdout)
 	table.SetAutoWrapText(false)
 	table.SetHeader([]string{"#", "Name", "Updated" /* "Created",*/, "Context", "Convo"})
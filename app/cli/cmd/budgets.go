@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+
+	shared "plandex-shared"
+
+	"github.com/olekukonko/tablewriter"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var budgetPlanTokens int
+var budgetPlanUsd float64
+var budgetUserDayTokens int
+var budgetUserDayUsd float64
+var budgetOrgMonthTokens int
+var budgetOrgMonthUsd float64
+var budgetWarnAtPct int
+
+var budgetsCmd = &cobra.Command{
+	Use:   "budgets",
+	Short: "Show the org's token and spend budgets",
+	Long: `Show the org's token and spend budgets.
+
+Budgets cap model usage per plan, per user per day, and per org per month, in tokens, USD, or both. They're checked before every model call on a self-hosted server. A warning is shown once a budget passes its warning threshold, and a call that would go over a budget stops the plan with an error.`,
+	Args: cobra.NoArgs,
+	Run:  showBudgets,
+}
+
+var setBudgetsCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Set one or more budgets",
+	Long: `Set one or more budgets. Budgets that aren't passed are left as they are. Pass 0 to remove a limit.
+
+Token budgets count input and output tokens. USD budgets are priced from each model's list price, so calls to models without pricing don't count toward them.`,
+	Args: cobra.NoArgs,
+	Run:  setBudgets,
+}
+
+var rmBudgetsCmd = &cobra.Command{
+	Use:     "rm",
+	Aliases: []string{"remove", "clear"},
+	Short:   "Remove all budgets",
+	Args:    cobra.NoArgs,
+	Run:     rmBudgets,
+}
+
+func init() {
+	RootCmd.AddCommand(budgetsCmd)
+	budgetsCmd.AddCommand(setBudgetsCmd)
+	budgetsCmd.AddCommand(rmBudgetsCmd)
+
+	setBudgetsCmd.Flags().IntVar(&budgetPlanTokens, "plan-tokens", 0, "Max tokens per plan")
+	setBudgetsCmd.Flags().Float64Var(&budgetPlanUsd, "plan-usd", 0, "Max spend per plan in USD")
+	setBudgetsCmd.Flags().IntVar(&budgetUserDayTokens, "user-day-tokens", 0, "Max tokens per user per day (UTC)")
+	setBudgetsCmd.Flags().Float64Var(&budgetUserDayUsd, "user-day-usd", 0, "Max spend per user per day (UTC) in USD")
+	setBudgetsCmd.Flags().IntVar(&budgetOrgMonthTokens, "org-month-tokens", 0, "Max tokens for the org per calendar month (UTC)")
+	setBudgetsCmd.Flags().Float64Var(&budgetOrgMonthUsd, "org-month-usd", 0, "Max spend for the org per calendar month (UTC) in USD")
+	setBudgetsCmd.Flags().IntVar(&budgetWarnAtPct, "warn-at", 0, fmt.Sprintf("Percent of a budget to start warning at (default %d)", shared.DefaultBudgetWarnAtPct))
+}
+
+func showBudgets(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	mustBeSelfHostedForBudgets()
+
+	term.StartSpinner("")
+	budgets, apiErr := api.Client.GetOrgBudgets()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting budgets: %v", apiErr.Msg)
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("budgets", budgets)
+	}
+
+	if budgets == nil || budgets.IsEmpty() {
+		fmt.Println("🤷‍♂️ No budgets set")
+		fmt.Println()
+		term.PrintCmds("", "budgets set")
+		return
+	}
+
+	printBudgets(budgets)
+	fmt.Println()
+	term.PrintCmds("", "budgets set", "budgets rm", "usage")
+}
+
+func setBudgets(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	mustBeSelfHostedForBudgets()
+
+	if cmd.Flags().NFlag() == 0 {
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, "pass at least one budget flag")
+		}
+		term.OutputErrorAndExit("Pass at least one budget flag—see 'plandex budgets set --help'")
+	}
+
+	term.StartSpinner("")
+	existing, apiErr := api.Client.GetOrgBudgets()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting budgets: %v", apiErr.Msg)
+	}
+
+	budgets := shared.SpendBudgets{}
+	if existing != nil {
+		budgets = *existing
+	}
+
+	flags := cmd.Flags()
+	budgets.PerPlan = updateBudgetLimit(budgets.PerPlan, flags.Changed("plan-tokens"), budgetPlanTokens, flags.Changed("plan-usd"), budgetPlanUsd)
+	budgets.PerUserDay = updateBudgetLimit(budgets.PerUserDay, flags.Changed("user-day-tokens"), budgetUserDayTokens, flags.Changed("user-day-usd"), budgetUserDayUsd)
+	budgets.PerOrgMonth = updateBudgetLimit(budgets.PerOrgMonth, flags.Changed("org-month-tokens"), budgetOrgMonthTokens, flags.Changed("org-month-usd"), budgetOrgMonthUsd)
+	if flags.Changed("warn-at") {
+		budgets.WarnAtPct = budgetWarnAtPct
+	}
+
+	err := budgets.Validate()
+	if err != nil {
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, err.Error())
+		}
+		term.OutputErrorAndExit("Invalid budgets: %v", err)
+	}
+
+	term.StartSpinner("")
+	updated, apiErr := api.Client.UpdateOrgBudgets(budgets)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating budgets: %v", apiErr.Msg)
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("budgets", updated)
+	}
+
+	fmt.Println("✅ Updated budgets")
+	fmt.Println()
+	printBudgets(updated)
+}
+
+func rmBudgets(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	mustBeSelfHostedForBudgets()
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteOrgBudgets()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing budgets: %v", apiErr.Msg)
+	}
+
+	fmt.Println("✅ Removed all budgets")
+}
+
+func mustBeSelfHostedForBudgets() {
+	if auth.Current.IsCloud {
+		term.OutputErrorAndExit("Budgets are only available on self-hosted servers. Plandex Cloud usage is limited by your credits balance—see 'plandex billing'.")
+	}
+}
+
+// updateBudgetLimit applies any changed flags to a limit. A zero value removes that part of the limit, and nil is returned once the limit is empty.
+func updateBudgetLimit(limit *shared.BudgetLimit, tokensChanged bool, tokens int, usdChanged bool, usd float64) *shared.BudgetLimit {
+	updated := shared.BudgetLimit{}
+	if limit != nil {
+		updated = *limit
+	}
+
+	if tokensChanged {
+		updated.Tokens = tokens
+	}
+	if usdChanged {
+		if usd == 0 {
+			updated.Usd = nil
+		} else {
+			d := decimal.NewFromFloat(usd)
+			updated.Usd = &d
+		}
+	}
+
+	if updated.IsEmpty() {
+		return nil
+	}
+	return &updated
+}
+
+func printBudgets(budgets *shared.SpendBudgets) {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Budget", "Tokens", "USD"})
+
+	rows := []struct {
+		label string
+		limit *shared.BudgetLimit
+	}{
+		{"Per plan", budgets.PerPlan},
+		{"Per user per day", budgets.PerUserDay},
+		{"Per org per month", budgets.PerOrgMonth},
+	}
+
+	for _, row := range rows {
+		tokens := "—"
+		usd := "—"
+		if row.limit != nil {
+			if row.limit.Tokens > 0 {
+				tokens = shared.FormatBudgetTokens(row.limit.Tokens)
+			}
+			if row.limit.Usd != nil {
+				usd = "$" + row.limit.Usd.StringFixed(2)
+			}
+		}
+		table.Append([]string{row.label, tokens, usd})
+	}
+
+	table.Render()
+	fmt.Println()
+	fmt.Printf("⚠️  Warns at %d%% of a budget. Days and months start at midnight UTC.\n", budgets.GetWarnAtPct())
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var cherryPickCmd = &cobra.Command{
+	Use:   "cherry-pick <sha>",
+	Short: "Apply a single plan update from any branch to the current branch",
+	Long: `Apply the conversation, context, and pending changes from a single plan update to the current branch. Use 'plandex log' on another branch to find the update's sha.
+
+Conflicts between pending changes are handled the same way as 'plandex merge'.`,
+	Args: cobra.ExactArgs(1),
+	Run:  cherryPick,
+}
+
+func init() {
+	RootCmd.AddCommand(cherryPickCmd)
+	addMergeResolutionFlags(cherryPickCmd)
+}
+
+func cherryPick(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	sha := strings.ToLower(strings.TrimSpace(args[0]))
+	resolution := mustGetMergeResolution()
+
+	for {
+		term.StartSpinner("")
+		res, apiErr := api.Client.CherryPick(lib.CurrentPlanId, lib.CurrentBranch, shared.CherryPickRequest{
+			Sha:        sha,
+			Resolution: resolution,
+		})
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error cherry-picking update: %v", apiErr.Msg)
+		}
+
+		resolution = handleMergeResponse(res, fmt.Sprintf("update %s", color.New(color.Bold, term.ColorHiCyan).Sprint(sha)))
+		if resolution == shared.MergeResolutionNone {
+			return
+		}
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"strings"
 	"time"
 
+	shared "plandex-shared"
+
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 )
@@ -49,7 +51,7 @@ func convo(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("Error loading conversation: %v", apiErr.Msg)
 	}
 
-	if len(conversation) == 0 {
+	if len(conversation) == 0 && !term.JsonMode {
 		fmt.Println("🤷‍♂️ No conversation history")
 		return
 	}
@@ -81,6 +83,21 @@ func convo(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	if term.JsonMode {
+		messages := []*shared.ConvoMessage{}
+		totalTokens := 0
+		for _, msg := range conversation {
+			if (msgRangeStart > 0 && msg.Num < msgRangeStart) || (msgRangeEnd > 0 && msg.Num > msgRangeEnd) {
+				continue
+			}
+			messages = append(messages, msg)
+			totalTokens += msg.Tokens
+		}
+		term.SetJsonData("messages", messages)
+		term.SetJsonData("totalTokens", totalTokens)
+		return
+	}
+
 	var convo string
 	var totalTokens int
 	var didCut bool
@@ -40,6 +40,12 @@ func current(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.JsonMode {
+		term.SetJsonData("plan", plan)
+		term.SetJsonData("branch", lib.CurrentBranch)
+		return
+	}
+
 	currentBranchesByPlanId, err := api.Client.GetCurrentBranchByPlanId(lib.CurrentProjectId, shared.GetCurrentBranchByPlanIdRequest{
 		CurrentBranchByPlanId: map[string]string{
 			lib.CurrentPlanId: lib.CurrentBranch,
@@ -64,6 +64,11 @@ func diffs(cmd *cobra.Command, args []string) {
 		diffGit = true
 	}
 
+	if term.JsonMode {
+		showDiffUi = false
+		plainTextOutput = true
+	}
+
 	diffs, err := api.Client.GetPlanDiffs(lib.CurrentPlanId, lib.CurrentBranch, plainTextOutput || showDiffUi)
 	term.StopSpinner()
 	if err != nil {
@@ -71,6 +76,11 @@ func diffs(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.JsonMode {
+		term.SetJsonData("diff", diffs)
+		return
+	}
+
 	if len(diffs) == 0 {
 		fmt.Println("🤷‍♂️ No pending changes")
 		return
@@ -0,0 +1,205 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var execPolicyCmd = &cobra.Command{
+	Use:   "exec-policy",
+	Short: "Show the project and org policies for which commands can run",
+	Args:  cobra.NoArgs,
+	Run:   showExecPolicy,
+}
+
+var checkExecPolicyCmd = &cobra.Command{
+	Use:   "check <script>",
+	Short: "Check a script's commands against the exec policies",
+	Args:  cobra.ExactArgs(1),
+	Run:   checkExecPolicy,
+}
+
+var setOrgExecPolicyCmd = &cobra.Command{
+	Use:   "set-org <file>",
+	Short: "Set the org's exec policy from a YAML file",
+	Args:  cobra.ExactArgs(1),
+	Run:   setOrgExecPolicy,
+}
+
+var rmOrgExecPolicyCmd = &cobra.Command{
+	Use:     "rm-org",
+	Aliases: []string{"remove-org"},
+	Short:   "Remove the org's exec policy",
+	Args:    cobra.NoArgs,
+	Run:     rmOrgExecPolicy,
+}
+
+func init() {
+	RootCmd.AddCommand(execPolicyCmd)
+	execPolicyCmd.AddCommand(checkExecPolicyCmd)
+	execPolicyCmd.AddCommand(setOrgExecPolicyCmd)
+	execPolicyCmd.AddCommand(rmOrgExecPolicyCmd)
+}
+
+func showExecPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	projectPolicy, err := lib.LoadProjectExecPolicy()
+	if err != nil {
+		term.OutputErrorAndExit("Error loading exec policy: %v", err)
+	}
+
+	term.StartSpinner("")
+	orgPolicy, apiErr := api.Client.GetOrgExecPolicy()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting org exec policy: %v", apiErr.Msg)
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("orgPolicy", orgPolicy)
+		term.SetJsonData("projectPolicy", projectPolicy)
+		if fs.PlandexDir != "" {
+			term.SetJsonData("projectPolicyPath", lib.ProjectExecPolicyPath())
+		}
+	}
+
+	color.New(color.Bold, term.ColorHiCyan).Println("Org policy")
+	if orgPolicy == nil {
+		fmt.Println("None")
+	} else {
+		printExecPolicy(orgPolicy)
+	}
+	fmt.Println()
+
+	color.New(color.Bold, term.ColorHiCyan).Println("Project policy")
+	if fs.PlandexDir == "" {
+		fmt.Println("No project found in this directory")
+	} else if projectPolicy == nil {
+		fmt.Printf("None—add one at %s\n", lib.ProjectExecPolicyPath())
+	} else {
+		fmt.Println(lib.ProjectExecPolicyPath())
+		fmt.Println()
+		printExecPolicy(projectPolicy)
+	}
+	fmt.Println()
+
+	fmt.Println("Commands in _apply.sh are checked against both policies before they run")
+	fmt.Println()
+	term.PrintCmds("", "exec-policy check", "exec-policy set-org", "exec-policy rm-org")
+}
+
+func checkExecPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	script, err := os.ReadFile(args[0])
+	if err != nil {
+		term.OutputErrorAndExit("Error reading script: %v", err)
+	}
+
+	projectPolicy, err := lib.LoadProjectExecPolicy()
+	if err != nil {
+		term.OutputErrorAndExit("Error loading exec policy: %v", err)
+	}
+
+	term.StartSpinner("")
+	res, err := lib.CheckExecPolicy(string(script), projectPolicy)
+	term.StopSpinner()
+
+	if err != nil {
+		term.OutputErrorAndExit("Error checking exec policy: %v", err)
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("check", res)
+	}
+
+	if res.OnViolation == "" {
+		fmt.Println("🤷‍♂️ There's no exec policy for this project or org")
+		fmt.Println()
+		term.PrintCmds("", "exec-policy")
+		return
+	}
+
+	if len(res.Violations) == 0 {
+		suffix := "s"
+		if len(res.Commands) == 1 {
+			suffix = ""
+		}
+		fmt.Printf("✅ %d command%s allowed by the exec policy\n", len(res.Commands), suffix)
+		return
+	}
+
+	lib.PrintExecPolicyViolations(res)
+	fmt.Printf("On violation: %s\n", color.New(color.Bold).Sprint(res.OnViolation))
+}
+
+func setOrgExecPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	policy, err := lib.ReadExecPolicyFile(args[0])
+	if err != nil {
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, err.Error())
+		}
+		term.OutputErrorAndExit("Error reading exec policy: %v", err)
+	}
+
+	term.StartSpinner("")
+	updated, apiErr := api.Client.UpdateOrgExecPolicy(*policy)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating org exec policy: %v", apiErr.Msg)
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("orgPolicy", updated)
+	}
+
+	fmt.Println("✅ Updated the org's exec policy")
+	fmt.Println()
+	printExecPolicy(updated)
+	fmt.Println()
+	term.PrintCmds("", "exec-policy")
+}
+
+func rmOrgExecPolicy(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteOrgExecPolicy()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error removing org exec policy: %v", apiErr.Msg)
+	}
+
+	fmt.Println("✅ Removed the org's exec policy")
+}
+
+func printExecPolicy(policy *shared.ExecPolicy) {
+	if policy.IsEmpty() {
+		fmt.Println("No rules")
+		return
+	}
+
+	bytes, err := yaml.Marshal(policy)
+	if err != nil {
+		term.OutputErrorAndExit("Error marshalling exec policy: %v", err)
+	}
+	fmt.Println(strings.TrimSpace(string(bytes)))
+}
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"regexp"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var exportOutputPath string
+
+var exportCmd = &cobra.Command{
+	Use:   "export [name-or-index]",
+	Short: "Export a plan to a portable archive",
+	Long:  "Export a plan to a portable archive with its context, conversation, pending changes, config, and the history of every branch. Use 'plandex import' to load it on the same or another server. Exports the current plan if no plan is given.",
+	Args:  cobra.MaximumNArgs(1),
+	Run:   export,
+}
+
+func init() {
+	RootCmd.AddCommand(exportCmd)
+	exportCmd.Flags().StringVarP(&exportOutputPath, "output", "o", "", "Path to write the archive to (defaults to <plan-name>.tar.gz)")
+}
+
+var unsafeFileNameRegex = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+func export(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	var nameOrIdx string
+	if len(args) > 0 {
+		nameOrIdx = strings.TrimSpace(args[0])
+	}
+
+	var plan *shared.Plan
+
+	term.StartSpinner("")
+
+	if nameOrIdx == "" {
+		if lib.CurrentPlanId == "" {
+			term.StopSpinner()
+			term.OutputNoCurrentPlanErrorAndExit()
+		}
+
+		var apiErr *shared.ApiError
+		plan, apiErr = api.Client.GetPlan(lib.CurrentPlanId)
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting plan: %v", apiErr.Msg)
+		}
+	} else {
+		plans, apiErr := api.Client.ListPlans([]string{lib.CurrentProjectId})
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting plans: %v", apiErr.Msg)
+		}
+
+		idx, err := strconv.Atoi(nameOrIdx)
+		if err == nil && idx > 0 && idx <= len(plans) {
+			plan = plans[idx-1]
+		} else {
+			for _, p := range plans {
+				if p.Name == nameOrIdx {
+					plan = p
+					break
+				}
+			}
+		}
+
+		if plan == nil {
+			term.OutputErrorAndExit("Plan not found")
+		}
+	}
+
+	archive, apiErr := api.Client.ExportPlan(plan.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error exporting plan: %v", apiErr.Msg)
+	}
+
+	outputPath := exportOutputPath
+	if outputPath == "" {
+		outputPath = unsafeFileNameRegex.ReplaceAllString(plan.Name, "-") + ".tar.gz"
+	}
+
+	err := os.WriteFile(outputPath, archive, 0644)
+	if err != nil {
+		term.OutputErrorAndExit("Error writing archive: %v", err)
+	}
+
+	fmt.Printf("✅ Exported plan %s to %s\n", color.New(color.Bold, term.ColorHiGreen).Sprint(plan.Name), outputPath)
+	fmt.Println()
+	term.PrintCmds("", "import")
+}
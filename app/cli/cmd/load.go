@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"plandex-cli/auth"
+	"plandex-cli/fs"
 	"plandex-cli/lib"
 	"plandex-cli/term"
 	"plandex-cli/types"
@@ -19,13 +20,19 @@ var (
 	forceSkipIgnore bool
 	imageDetail     string
 	defsOnly        bool
+	searchQuery     string
+	searchLimit     int
+	symbols         []string
+	gitDiffRef      string
+	gitStaged       bool
+	gitUncommitted  bool
 )
 
 var contextLoadCmd = &cobra.Command{
 	Use:     "load [files-or-urls...]",
 	Aliases: []string{"l", "add"},
 	Short:   "Load context from various inputs",
-	Long:    `Load context from a file path, a directory, a URL, an image, a note, or piped data.`,
+	Long:    `Load context from a file path, a directory, a URL, an image, a note, or piped data. Use --search to load the files that best match a query. Load a single definition from a file with path/to/file.go#FuncName, or use --symbols to pick several. Use --git-diff, --staged, or --uncommitted to load git diffs that stay up to date as the repo changes.`,
 	Run:     contextLoad,
 }
 
@@ -36,6 +43,12 @@ func init() {
 	contextLoadCmd.Flags().BoolVarP(&forceSkipIgnore, "force", "f", false, "Load files even when ignored by .gitignore or .plandexignore")
 	contextLoadCmd.Flags().StringVarP(&imageDetail, "detail", "d", "high", "Image detail level (high or low)")
 	contextLoadCmd.Flags().BoolVar(&defsOnly, "map", false, "Load file maps (function/method/class signatures, variable names, types, etc.)")
+	contextLoadCmd.Flags().StringVarP(&searchQuery, "search", "s", "", "Load the files in the project map that best match a query")
+	contextLoadCmd.Flags().IntVar(&searchLimit, "limit", 5, "Max number of files to load with --search")
+	contextLoadCmd.Flags().StringVar(&gitDiffRef, "git-diff", "", "Load the diff of the working tree against a ref, or the commits in a range like main..HEAD")
+	contextLoadCmd.Flags().BoolVar(&gitStaged, "staged", false, "Load the diff of staged changes")
+	contextLoadCmd.Flags().BoolVar(&gitUncommitted, "uncommitted", false, "Load the diff of all uncommitted changes")
+	contextLoadCmd.Flags().StringSliceVar(&symbols, "symbols", nil, "Load only these definitions (functions, types, classes, etc.) from each file, comma-separated")
 	RootCmd.AddCommand(contextLoadCmd)
 }
 
@@ -48,15 +61,45 @@ func contextLoad(cmd *cobra.Command, args []string) {
 		return
 	}
 
-	lib.MustLoadContext(args, &types.LoadContextParams{
-		Note:            note,
-		Recursive:       recursive,
-		NamesOnly:       namesOnly,
-		ForceSkipIgnore: forceSkipIgnore,
-		ImageDetail:     openai.ImageURLDetail(imageDetail),
-		DefsOnly:        defsOnly,
-		SessionId:       os.Getenv("PLANDEX_REPL_SESSION_ID"),
-	})
+	if len(symbols) > 0 && (defsOnly || namesOnly || recursive) {
+		term.OutputErrorAndExit("--symbols can't be combined with --map, --tree, or --recursive")
+	}
+
+	isGit := gitDiffRef != "" || gitStaged || gitUncommitted
+	if isGit && (defsOnly || namesOnly || recursive || len(symbols) > 0) {
+		term.OutputErrorAndExit("--git-diff, --staged, and --uncommitted can't be combined with --map, --tree, --recursive, or --symbols")
+	}
+	if gitUncommitted && (gitStaged || gitDiffRef != "") {
+		term.OutputErrorAndExit("--uncommitted can't be combined with --staged or --git-diff")
+	}
+	if isGit && !fs.ProjectRootIsGitRepo() {
+		term.OutputErrorAndExit("Git diffs can only be loaded in a git repository")
+	}
+
+	if searchQuery != "" {
+		if len(args) > 0 || note != "" || defsOnly || namesOnly || isGit {
+			term.OutputErrorAndExit("--search can't be combined with other inputs")
+		}
+
+		lib.MustSearchAndLoadContext(searchQuery, searchLimit, &types.LoadContextParams{
+			ForceSkipIgnore: forceSkipIgnore,
+			SessionId:       os.Getenv("PLANDEX_REPL_SESSION_ID"),
+		})
+	} else {
+		lib.MustLoadContext(args, &types.LoadContextParams{
+			Note:            note,
+			Recursive:       recursive,
+			NamesOnly:       namesOnly,
+			ForceSkipIgnore: forceSkipIgnore,
+			ImageDetail:     openai.ImageURLDetail(imageDetail),
+			DefsOnly:        defsOnly,
+			SessionId:       os.Getenv("PLANDEX_REPL_SESSION_ID"),
+			Symbols:         symbols,
+			GitDiffRef:      gitDiffRef,
+			GitStaged:       gitStaged,
+			GitUncommitted:  gitUncommitted,
+		})
+	}
 
 	fmt.Println()
 	term.PrintCmds("", "ls", "tell", "debug")
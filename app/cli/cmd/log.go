@@ -42,6 +42,17 @@ func runLog(cmd *cobra.Command, args []string) {
 		term.OutputErrorAndExit("Error getting logs: %v", apiErr)
 	}
 
+	// timestamps stay in UTC with --json
+	if term.JsonMode {
+		shas := res.Shas
+		if shas == nil {
+			shas = []string{}
+		}
+		term.SetJsonData("shas", shas)
+		term.SetJsonData("log", term.StripAnsi(res.Body))
+		return
+	}
+
 	withLocalTimestamps, err := convertTimestampsToLocal(res.Body)
 
 	if err != nil {
@@ -41,6 +41,11 @@ func listContext(cmd *cobra.Command, args []string) {
 	}
 	term.StopSpinner()
 
+	if term.JsonMode {
+		setContextJsonData(contexts, planConfig)
+		return
+	}
+
 	totalTokens := 0
 	totalPlannerTokens := 0
 	totalMapTokens := 0
@@ -109,6 +114,27 @@ func listContext(cmd *cobra.Command, args []string) {
 
 }
 
+func setContextJsonData(contexts []*shared.Context, planConfig *shared.PlanConfig) {
+	totalTokens := 0
+	totalMapTokens := 0
+	for _, context := range contexts {
+		totalTokens += context.NumTokens
+		if context.ContextType == shared.ContextMapType {
+			totalMapTokens += context.NumTokens
+		}
+	}
+
+	if contexts == nil {
+		contexts = []*shared.Context{}
+	}
+
+	term.SetJsonData("contexts", contexts)
+	term.SetJsonData("totalTokens", totalTokens)
+	term.SetJsonData("mapTokens", totalMapTokens)
+	term.SetJsonData("contextTokens", totalTokens-totalMapTokens)
+	term.SetJsonData("autoLoadContext", planConfig.AutoLoadContext)
+}
+
 func init() {
 	RootCmd.AddCommand(contextCmd)
 
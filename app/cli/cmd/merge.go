@@ -0,0 +1,163 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var mergeOurs bool
+var mergeTheirs bool
+
+var mergeCmd = &cobra.Command{
+	Use:   "merge <branch>",
+	Short: "Merge another plan branch into the current branch",
+	Long: `Merge another plan branch's conversation, context, and pending changes into the current branch.
+
+If both branches have pending changes to the same file that can't be applied on top of each other, you'll be prompted to keep the changes from one side. The other side's pending changes to that file are rejected. Use --ours or --theirs to resolve conflicts without a prompt.`,
+	Args: cobra.ExactArgs(1),
+	Run:  merge,
+}
+
+func init() {
+	RootCmd.AddCommand(mergeCmd)
+	addMergeResolutionFlags(mergeCmd)
+}
+
+func addMergeResolutionFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolVar(&mergeOurs, "ours", false, "Keep the current branch's pending changes when both sides change the same file")
+	cmd.Flags().BoolVar(&mergeTheirs, "theirs", false, "Keep the incoming pending changes when both sides change the same file")
+}
+
+func merge(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	source := strings.TrimSpace(args[0])
+	if source == lib.CurrentBranch {
+		term.OutputErrorAndExit("Cannot merge branch %s into itself", source)
+	}
+
+	resolution := mustGetMergeResolution()
+
+	for {
+		term.StartSpinner("")
+		res, apiErr := api.Client.MergeBranch(lib.CurrentPlanId, lib.CurrentBranch, shared.MergeBranchRequest{
+			Branch:     source,
+			Resolution: resolution,
+		})
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error merging branch: %v", apiErr.Msg)
+		}
+
+		resolution = handleMergeResponse(res, fmt.Sprintf("branch %s", color.New(color.Bold, term.ColorHiCyan).Sprint(source)))
+		if resolution == shared.MergeResolutionNone {
+			return
+		}
+	}
+}
+
+func mustGetMergeResolution() shared.MergeResolution {
+	if mergeOurs && mergeTheirs {
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, "cannot pass both --ours and --theirs")
+		}
+		term.OutputErrorAndExit("Cannot pass both --ours and --theirs")
+	}
+
+	if mergeOurs {
+		return shared.MergeResolutionOurs
+	}
+	if mergeTheirs {
+		return shared.MergeResolutionTheirs
+	}
+	return shared.MergeResolutionNone
+}
+
+// handleMergeResponse prints the outcome of a merge or cherry-pick. If there are unresolved conflicts, it prompts for which side to keep and returns the resolution to retry with. Otherwise it returns MergeResolutionNone.
+func handleMergeResponse(res *shared.MergeBranchResponse, sourceLabel string) shared.MergeResolution {
+	if term.JsonMode {
+		term.SetJsonData("merge", res)
+	}
+
+	if res.UpToDate {
+		fmt.Printf("✅ %s is already up to date with %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(lib.CurrentBranch), sourceLabel)
+		return shared.MergeResolutionNone
+	}
+
+	if !res.Merged {
+		fmt.Printf("⚠️  Pending changes from %s conflict with pending changes on %s:\n", sourceLabel, color.New(color.Bold, term.ColorHiCyan).Sprint(lib.CurrentBranch))
+		for _, path := range res.ConflictedPaths {
+			fmt.Println("  • " + path)
+		}
+		fmt.Println()
+
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, "pending changes conflict—pass --ours or --theirs with --json")
+		}
+
+		const keepOurs = "Keep pending changes on the current branch"
+		const keepTheirs = "Keep incoming pending changes"
+		const cancel = "Cancel"
+
+		sel, err := term.SelectFromList("How do you want to resolve the conflicts?", []string{keepOurs, keepTheirs, cancel})
+		if err != nil {
+			term.OutputErrorAndExit("Error selecting resolution: %v", err)
+		}
+
+		switch sel {
+		case keepOurs:
+			return shared.MergeResolutionOurs
+		case keepTheirs:
+			return shared.MergeResolutionTheirs
+		}
+
+		fmt.Println("🤷‍♂️ Nothing was merged")
+		return shared.MergeResolutionNone
+	}
+
+	fmt.Printf("✅ Merged %s into %s\n", sourceLabel, color.New(color.Bold, term.ColorHiCyan).Sprint(lib.CurrentBranch))
+
+	var parts []string
+	if res.NumMessages > 0 {
+		parts = append(parts, pluralize(res.NumMessages, "message"))
+	}
+	if res.NumContexts > 0 {
+		parts = append(parts, pluralize(res.NumContexts, "context"))
+	}
+	if res.NumResults > 0 {
+		parts = append(parts, pluralize(res.NumResults, "pending change"))
+	}
+	if len(parts) > 0 {
+		fmt.Println("📥 Added " + strings.Join(parts, ", "))
+	}
+	if len(res.ConflictedPaths) > 0 {
+		fmt.Println("🔀 Resolved conflicts in " + strings.Join(res.ConflictedPaths, ", "))
+	}
+
+	fmt.Println()
+	term.PrintCmds("", "log", "convo", "diff", "ls")
+
+	return shared.MergeResolutionNone
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
@@ -14,9 +14,11 @@ import (
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
 	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
 )
 
 var customModelPacksOnly bool
+var forceImportModelPack bool
 
 var modelPacksCmd = &cobra.Command{
 	Use:   "model-packs",
@@ -38,12 +40,35 @@ var deleteModelPackCmd = &cobra.Command{
 	Run:     deleteModelPack,
 }
 
+var exportModelPackCmd = &cobra.Command{
+	Use:   "export <name>",
+	Short: "Print a model pack as YAML",
+	Long: `Print a built-in or custom model pack as YAML, for example:
+
+  plandex model-packs export my-pack > pack.yaml
+
+The file can be edited and loaded back with 'plandex model-packs import', or added to a models file for 'plandex models sync'.`,
+	Args: cobra.ExactArgs(1),
+	Run:  exportModelPack,
+}
+
+var importModelPackCmd = &cobra.Command{
+	Use:   "import <file>",
+	Short: "Create or update a custom model pack from a YAML file",
+	Long:  `Create a custom model pack from a YAML file. If a custom model pack with the same name already exists, you'll be asked before it's overwritten.`,
+	Args:  cobra.ExactArgs(1),
+	Run:   importModelPack,
+}
+
 func init() {
 	RootCmd.AddCommand(modelPacksCmd)
 	modelPacksCmd.AddCommand(createModelPackCmd)
 	modelPacksCmd.AddCommand(deleteModelPackCmd)
+	modelPacksCmd.AddCommand(exportModelPackCmd)
+	modelPacksCmd.AddCommand(importModelPackCmd)
 
 	modelPacksCmd.Flags().BoolVarP(&customModelPacksOnly, "custom", "c", false, "Only show custom model packs")
+	importModelPackCmd.Flags().BoolVarP(&forceImportModelPack, "force", "f", false, "Overwrite an existing custom model pack with the same name without asking")
 }
 
 func deleteModelPack(cmd *cobra.Command, args []string) {
@@ -237,6 +262,136 @@ func createModelPack(cmd *cobra.Command, args []string) {
 	term.PrintCmds("", "model-packs", "model-packs --custom", "model-packs delete")
 }
 
+func exportModelPack(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	name := args[0]
+
+	term.StartSpinner("")
+	customModelPacks, apiErr := api.Client.ListModelPacks()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching model packs: %v", apiErr.Msg)
+	}
+
+	var pack *shared.ModelPack
+	for _, mp := range customModelPacks {
+		if mp.Name == name {
+			pack = mp
+			break
+		}
+	}
+	if pack == nil {
+		for _, mp := range shared.BuiltInModelPacks {
+			if mp.Name == name {
+				pack = mp
+				break
+			}
+		}
+	}
+
+	if pack == nil {
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, fmt.Sprintf("model pack %s not found", name))
+		}
+		term.OutputErrorAndExit("Model pack %s not found", name)
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("modelPack", pack)
+		return
+	}
+
+	bytes, err := yaml.Marshal(pack)
+	if err != nil {
+		term.OutputErrorAndExit("Error marshalling model pack: %v", err)
+	}
+	fmt.Print(string(bytes))
+}
+
+func importModelPack(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	pack, err := lib.ReadModelPackFile(args[0])
+	if err != nil {
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, err.Error())
+		}
+		term.OutputErrorAndExit("Error reading model pack: %v", err)
+	}
+
+	term.StartSpinner("")
+	customModelPacks, apiErr := api.Client.ListModelPacks()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching model packs: %v", apiErr.Msg)
+	}
+
+	var existing *shared.ModelPack
+	for _, mp := range customModelPacks {
+		if mp.Name == pack.Name {
+			existing = mp
+			break
+		}
+	}
+
+	if existing == nil {
+		term.StartSpinner("")
+		apiErr = api.Client.CreateModelPack(pack)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error creating model pack: %v", apiErr.Msg)
+		}
+
+		if term.JsonMode {
+			term.SetJsonData("modelPack", pack.Name)
+			term.SetJsonData("action", shared.ModelsSyncActionCreate)
+		}
+
+		fmt.Println("✅ Imported model pack", color.New(color.Bold, term.ColorHiCyan).Sprint(pack.Name))
+		fmt.Println()
+		term.PrintCmds("", "model-packs --custom", "set-model")
+		return
+	}
+
+	if !forceImportModelPack {
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, fmt.Sprintf("model pack %s already exists—pass --force to overwrite it", pack.Name))
+		}
+
+		confirmed, err := term.ConfirmYesNo("Model pack %s already exists. Overwrite it?", color.New(color.Bold, term.ColorHiCyan).Sprint(pack.Name))
+		if err != nil {
+			term.OutputErrorAndExit("Error getting confirmation: %v", err)
+		}
+		if !confirmed {
+			fmt.Println("🤷‍♂️ Model pack not imported")
+			return
+		}
+	}
+
+	pack.Id = existing.Id
+
+	term.StartSpinner("")
+	apiErr = api.Client.UpdateModelPack(pack)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating model pack: %v", apiErr.Msg)
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("modelPack", pack.Name)
+		term.SetJsonData("action", shared.ModelsSyncActionUpdate)
+	}
+
+	fmt.Println("✅ Updated model pack", color.New(color.Bold, term.ColorHiCyan).Sprint(pack.Name))
+	fmt.Println()
+	term.PrintCmds("", "model-packs --custom", "set-model")
+}
+
 func getModelRoleConfig(customModels []*shared.AvailableModel, modelRole shared.ModelRole) shared.ModelRoleConfig {
 	_, modelConfig := getModelWithRoleConfig(customModels, modelRole)
 	return modelConfig
@@ -277,13 +432,46 @@ func getModelWithRoleConfig(customModels []*shared.AvailableModel, modelRole sha
 		}
 	}
 
-	return model, shared.ModelRoleConfig{
+	config := shared.ModelRoleConfig{
 		Role:                 modelRole,
 		BaseModelConfig:      model.BaseModelConfig,
 		Temperature:          float32(temperature),
 		TopP:                 float32(topP),
 		ReservedOutputTokens: reservedOutputTokens,
 	}
+
+	addErrorFallbacks(customModels, &config)
+
+	return model, config
+}
+
+// error fallbacks are tried in order if a role's model still fails after retries -- they use the same temperature, top p, and reserved output tokens as the role's main model
+func addErrorFallbacks(customModels []*shared.AvailableModel, config *shared.ModelRoleConfig) {
+	last := config
+	for {
+		res, err := term.ConfirmYesNo("Add an error fallback for %s (used if %s fails)?", config.Role, last.BaseModelConfig.ModelId)
+		if err != nil {
+			term.OutputErrorAndExit("Error reading response: %v", err)
+		}
+		if !res {
+			return
+		}
+
+		color.New(color.Bold).Printf("Select an error fallback model for the %s role 👇\n", config.Role)
+		model := lib.SelectModelForRole(customModels, config.Role, true)
+		if model == nil {
+			return
+		}
+
+		last.ErrorFallback = &shared.ModelRoleConfig{
+			Role:                 config.Role,
+			BaseModelConfig:      model.BaseModelConfig,
+			Temperature:          config.Temperature,
+			TopP:                 config.TopP,
+			ReservedOutputTokens: config.ReservedOutputTokens,
+		}
+		last = last.ErrorFallback
+	}
 }
 
 func getPlannerRoleConfig(customModels []*shared.AvailableModel) shared.PlannerRoleConfig {
@@ -14,10 +14,15 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/olekukonko/tablewriter"
+	"github.com/plandex-ai/survey/v2"
+	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 )
 
 var customModelsOnly bool
+var discoverAll bool
+var discoverForceXml bool
+var discoverBaseUrl string
 
 func init() {
 	RootCmd.AddCommand(modelsCmd)
@@ -25,8 +30,13 @@ func init() {
 	modelsCmd.AddCommand(createCustomModelCmd)
 	modelsCmd.AddCommand(deleteCustomModelCmd)
 	modelsCmd.AddCommand(defaultModelsCmd)
+	modelsCmd.AddCommand(discoverModelsCmd)
 
 	listAvailableModelsCmd.Flags().BoolVarP(&customModelsOnly, "custom", "c", false, "List custom models only")
+
+	discoverModelsCmd.Flags().BoolVarP(&discoverAll, "all", "a", false, "Add all discovered models without prompting")
+	discoverModelsCmd.Flags().BoolVar(&discoverForceXml, "xml", false, "Use the XML output format even for models that support tool calls")
+	discoverModelsCmd.Flags().StringVar(&discoverBaseUrl, "base-url", "", "Base url the Plandex server uses to reach the model server, if it differs from the host (e.g. http://host.docker.internal:11434/v1)")
 }
 
 var modelsCmd = &cobra.Command{
@@ -55,6 +65,14 @@ var createCustomModelCmd = &cobra.Command{
 	Run:     createCustomModel,
 }
 
+var discoverModelsCmd = &cobra.Command{
+	Use:   "discover [host]",
+	Short: "Add models from a local Ollama or llama.cpp server",
+	Long:  fmt.Sprintf("Query a local model server for its models and add them as custom models. Defaults to %s.", shared.OllamaDefaultHost),
+	Args:  cobra.MaximumNArgs(1),
+	Run:   discoverModels,
+}
+
 var deleteCustomModelCmd = &cobra.Command{
 	Use:     "rm",
 	Aliases: []string{"remove", "delete"},
@@ -77,7 +95,7 @@ func createCustomModel(cmd *cobra.Command, args []string) {
 		// remove custom provider if we're in cloud
 		filtered := []string{}
 		for _, provider := range opts {
-			if provider != string(shared.ModelProviderCustom) {
+			if provider != string(shared.ModelProviderCustom) && !shared.LocalModelProviders[shared.ModelProvider(provider)] {
 				filtered = append(filtered, provider)
 			}
 		}
@@ -138,6 +156,14 @@ func createCustomModel(cmd *cobra.Command, args []string) {
 		baseUrl = strings.TrimSuffix(baseUrl, "/")
 
 		model.BaseUrl = baseUrl
+	} else if shared.LocalModelProviders[model.Provider] {
+		baseUrl, err := term.GetRequiredUserStringInputWithDefault("Base URL:", shared.BaseUrlByProvider[model.Provider])
+		if err != nil {
+			term.OutputErrorAndExit("Error reading base URL: %v", err)
+			return
+		}
+
+		model.BaseUrl = strings.TrimSuffix(baseUrl, "/")
 	} else {
 		model.BaseUrl = shared.BaseUrlByProvider[model.Provider]
 	}
@@ -270,6 +296,12 @@ func createCustomModel(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if !auth.Current.IsCloud {
+		fmt.Println("Pricing is used to estimate spend in 'plandex usage'. Enter the provider's price in USD per 1M tokens, or leave blank if it's unknown or free.")
+
+		model.Pricing = promptModelPricing()
+	}
+
 	term.StartSpinner("")
 	apiErr := api.Client.CreateCustomModel(model)
 	term.StopSpinner()
@@ -282,6 +314,41 @@ func createCustomModel(cmd *cobra.Command, args []string) {
 	fmt.Println("✅ Added custom model", color.New(color.Bold, term.ColorHiCyan).Sprint(string(model.Provider)+" → "+string(model.ModelId)))
 }
 
+func promptModelPricing() *shared.ModelPricing {
+	getPrice := func(label string) (decimal.Decimal, bool) {
+		for {
+			priceStr, err := term.GetUserStringInput(label)
+			if err != nil {
+				term.OutputErrorAndExit("Error reading price: %v", err)
+			}
+			priceStr = strings.TrimPrefix(strings.TrimSpace(priceStr), "$")
+			if priceStr == "" {
+				return decimal.Zero, false
+			}
+			price, err := decimal.NewFromString(priceStr)
+			if err != nil || price.IsNegative() {
+				fmt.Println("Invalid price—enter a number like 2.50")
+				continue
+			}
+			return price, true
+		}
+	}
+
+	input, ok := getPrice("Input price per 1M tokens (optional):")
+	if !ok {
+		return nil
+	}
+
+	output, _ := getPrice("Output price per 1M tokens:")
+	cachedInput, _ := getPrice("Cached input price per 1M tokens (optional):")
+
+	return &shared.ModelPricing{
+		InputPerMillion:       input,
+		OutputPerMillion:      output,
+		CachedInputPerMillion: cachedInput,
+	}
+}
+
 func models(cmd *cobra.Command, args []string) {
 	auth.MustResolveAuthWithOrg()
 	lib.MustResolveProject()
@@ -489,6 +556,174 @@ func deleteCustomModel(cmd *cobra.Command, args []string) {
 	term.PrintCmds("", "models available", "models add")
 }
 
+func discoverModels(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	if auth.Current.IsCloud {
+		term.OutputErrorAndExit("Local model providers are not supported on Plandex Cloud")
+		return
+	}
+
+	host := shared.OllamaDefaultHost
+	if len(args) > 0 {
+		host = args[0]
+	}
+
+	term.StartSpinner("")
+	server, err := lib.DiscoverLocalModels(host)
+	if err != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Error discovering models: %v", err)
+		return
+	}
+
+	customModels, apiErr := api.Client.ListCustomModels()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching custom models: %v", apiErr.Msg)
+		return
+	}
+
+	// models are called by the Plandex server rather than the CLI, so the url used to discover them may not be reachable from the server
+	if discoverBaseUrl != "" {
+		server.BaseUrl = strings.TrimSuffix(strings.TrimSpace(discoverBaseUrl), "/")
+	} else if server.IsLoopback() {
+		fmt.Printf("⚠️  The Plandex server will send model requests to %s. If the server runs in Docker or on another machine, it can't reach this address—run again with --base-url set to an address the server can reach (e.g. http://host.docker.internal:11434/v1).\n\n", server.BaseUrl)
+	}
+
+	if len(server.Models) == 0 {
+		fmt.Printf("🤷‍♂️ No models found on %s server at %s\n", server.ProviderLabel(), server.BaseUrl)
+		return
+	}
+
+	existing := map[string]bool{}
+	for _, m := range customModels {
+		if m.Provider == server.Provider && m.BaseUrl == server.BaseUrl {
+			existing[string(m.ModelName)] = true
+		}
+	}
+
+	color.New(color.Bold, term.ColorHiCyan).Printf("🔎 Found %d models on %s server at %s\n", len(server.Models), server.ProviderLabel(), server.BaseUrl)
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "🪙", "Images", "Tools", "Output Format", ""})
+
+	var toAdd []*shared.AvailableModel
+	var opts []string
+	anyDefaultContext := false
+	for _, m := range server.Models {
+		model := server.ToAvailableModel(m, discoverForceXml)
+
+		status := ""
+		if existing[m.Name] {
+			status = "already added"
+		} else {
+			toAdd = append(toAdd, model)
+			opts = append(opts, m.Name)
+		}
+
+		maxTokens := strconv.Itoa(m.MaxTokens)
+		if !m.RuntimeContextSet {
+			maxTokens += "*"
+			anyDefaultContext = true
+		}
+
+		table.Append([]string{
+			m.Name,
+			maxTokens,
+			yesNo(m.HasImageSupport),
+			yesNo(m.HasToolSupport),
+			string(model.PreferredModelOutputFormat),
+			status,
+		})
+	}
+	table.Render()
+
+	if anyDefaultContext {
+		fmt.Println("* The server didn't report a runtime context size, so Ollama's default context size (or for other servers, the model's maximum) is assumed. If the server is started with a larger context size (for Ollama, 'num_ctx' in the Modelfile or OLLAMA_CONTEXT_LENGTH), the extra context won't be used, and if it's smaller, prompts may be truncated.")
+	}
+	fmt.Println()
+
+	// with --json, added is updated as each model is added so that a failure still reports the models added before it
+	added := []shared.ModelName{}
+	if term.JsonMode {
+		term.SetJsonData("added", added)
+	}
+
+	if len(toAdd) == 0 {
+		fmt.Println("✅ All discovered models have already been added")
+		fmt.Println()
+		term.PrintCmds("", "models available --custom", "set-model")
+		return
+	}
+
+	if !discoverAll {
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, "pass --all to add discovered models with --json")
+		}
+
+		var selected []string
+		prompt := &survey.MultiSelect{
+			Message: "Select models to add:",
+			Options: opts,
+			Default: opts,
+		}
+
+		err := survey.AskOne(prompt, &selected)
+		if err != nil {
+			term.OutputErrorAndExit("Error getting model selection: %v", err)
+		}
+
+		selectedSet := map[string]bool{}
+		for _, name := range selected {
+			selectedSet[name] = true
+		}
+
+		var filtered []*shared.AvailableModel
+		for _, model := range toAdd {
+			if selectedSet[string(model.ModelName)] {
+				filtered = append(filtered, model)
+			}
+		}
+		toAdd = filtered
+	}
+
+	if len(toAdd) == 0 {
+		fmt.Println("No models selected")
+		return
+	}
+
+	for _, model := range toAdd {
+		term.StartSpinner("")
+		apiErr := api.Client.CreateCustomModel(model)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error adding model %s: %v", model.ModelName, apiErr.Msg)
+			return
+		}
+
+		fmt.Println("✅ Added custom model", color.New(color.Bold, term.ColorHiCyan).Sprint(server.ProviderLabel()+" → "+string(model.ModelId)))
+
+		added = append(added, model.ModelName)
+		if term.JsonMode {
+			term.SetJsonData("added", added)
+		}
+	}
+
+	fmt.Println()
+	term.PrintCmds("", "models available --custom", "set-model", "model-packs create")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
 func renderSettings(settings *shared.PlanSettings) {
 	modelPack := settings.ModelPack
 
@@ -517,6 +752,43 @@ func renderSettings(settings *shared.PlanSettings) {
 
 	anyRoleParamsDisabled := false
 
+	addErrorFallbackRows := func(config shared.ModelRoleConfig) {
+		for i, errorFallback := range config.GetErrorFallbackChain() {
+			var temp float32
+			var topP float32
+			var disabled bool
+
+			if errorFallback.BaseModelConfig.RoleParamsDisabled {
+				temp = 1
+				topP = 1
+				disabled = true
+				anyRoleParamsDisabled = true
+			} else {
+				temp = errorFallback.Temperature
+				topP = errorFallback.TopP
+			}
+
+			tempStr := fmt.Sprintf("%.1f", temp)
+			if disabled {
+				tempStr = "*" + tempStr
+			}
+
+			topPStr := fmt.Sprintf("%.1f", topP)
+			if disabled {
+				topPStr = "*" + topPStr
+			}
+
+			table.Append([]string{
+				fmt.Sprintf("└─ error-fallback-%d", i+1),
+				string(errorFallback.BaseModelConfig.Provider),
+				string(errorFallback.BaseModelConfig.ModelId),
+				tempStr,
+				topPStr,
+				fmt.Sprintf("%d 🪙", errorFallback.BaseModelConfig.MaxTokens-errorFallback.GetReservedOutputTokens()),
+			})
+		}
+	}
+
 	addModelRow := func(role string, config shared.ModelRoleConfig) {
 
 		var temp float32
@@ -615,6 +887,8 @@ func renderSettings(settings *shared.PlanSettings) {
 				fmt.Sprintf("%d 🪙", config.LargeOutputFallback.BaseModelConfig.MaxTokens-config.LargeOutputFallback.GetReservedOutputTokens()),
 			})
 		}
+
+		addErrorFallbackRows(config)
 	}
 
 	var temp float32
@@ -684,6 +958,7 @@ func renderSettings(settings *shared.PlanSettings) {
 			fmt.Sprintf("%d 🪙", modelPack.Planner.PlannerLargeContextFallback.BaseModelConfig.MaxTokens-modelPack.Planner.PlannerLargeContextFallback.GetReservedOutputTokens()),
 		})
 	}
+	addErrorFallbackRows(modelPack.Planner.ModelRoleConfig)
 
 	addModelRow(string(shared.ModelRoleArchitect), modelPack.GetArchitect())
 	addModelRow(string(shared.ModelRoleCoder), modelPack.GetCoder())
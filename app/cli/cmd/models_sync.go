@@ -0,0 +1,178 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var syncModelsDryRun bool
+var syncModelsYes bool
+
+var syncModelsCmd = &cobra.Command{
+	Use:   "sync <file>",
+	Short: "Make custom models and model packs match a YAML file",
+	Long: `Make the org's custom models and model packs match a YAML file, so they can be kept in version control.
+
+Models in the file are matched to existing custom models by provider and model name, and model packs by name. Anything in the file that doesn't exist yet is created, anything that differs is updated, and any custom model or model pack that isn't in the file is deleted.
+
+The changes are shown before anything is applied. Start from the current setup with 'plandex models export > models.yaml'.`,
+	Args: cobra.ExactArgs(1),
+	Run:  syncModels,
+}
+
+var exportModelsCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Print custom models and model packs as YAML",
+	Long: `Print the org's custom models and model packs as YAML, in the format used by 'plandex models sync', for example:
+
+  plandex models export > models.yaml`,
+	Args: cobra.NoArgs,
+	Run:  exportModels,
+}
+
+func init() {
+	modelsCmd.AddCommand(syncModelsCmd)
+	modelsCmd.AddCommand(exportModelsCmd)
+
+	syncModelsCmd.Flags().BoolVar(&syncModelsDryRun, "dry-run", false, "Show the changes without applying them")
+	syncModelsCmd.Flags().BoolVarP(&syncModelsYes, "yes", "y", false, "Apply the changes without asking")
+}
+
+func syncModels(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	path := args[0]
+
+	file, err := lib.ReadModelsFile(path)
+	if err != nil {
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, err.Error())
+		}
+		term.OutputErrorAndExit("Error reading models file: %v", err)
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.SyncModels(shared.SyncModelsRequest{ModelsFile: *file, DryRun: true})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error checking models: %v", apiErr.Msg)
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("changes", res.Changes)
+		term.SetJsonData("applied", false)
+	}
+
+	if len(res.Changes) == 0 {
+		fmt.Printf("✅ Custom models and model packs are already in sync with %s\n", path)
+		return
+	}
+
+	printModelsSyncChanges(res.Changes)
+	fmt.Println()
+
+	if syncModelsDryRun {
+		fmt.Println("🧪 Dry run—nothing was changed")
+		return
+	}
+
+	if !syncModelsYes {
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, "pass --yes to apply the changes, or --dry-run to only show them")
+		}
+
+		confirmed, err := term.ConfirmYesNo("Apply these changes?")
+		if err != nil {
+			term.OutputErrorAndExit("Error getting confirmation: %v", err)
+		}
+		if !confirmed {
+			fmt.Println("🤷‍♂️ Nothing was changed")
+			return
+		}
+	}
+
+	term.StartSpinner("")
+	res, apiErr = api.Client.SyncModels(shared.SyncModelsRequest{ModelsFile: *file})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error syncing models: %v", apiErr.Msg)
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("changes", res.Changes)
+		term.SetJsonData("applied", res.Applied)
+	}
+
+	fmt.Printf("✅ Synced custom models and model packs with %s (%s)\n", path, pluralize(len(res.Changes), "change"))
+	fmt.Println()
+	term.PrintCmds("", "models available --custom", "model-packs --custom")
+}
+
+func exportModels(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	customModels, apiErr := api.Client.ListCustomModels()
+	if apiErr != nil {
+		term.StopSpinner()
+		term.OutputErrorAndExit("Error fetching custom models: %v", apiErr.Msg)
+	}
+	customModelPacks, apiErr := api.Client.ListModelPacks()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching model packs: %v", apiErr.Msg)
+	}
+
+	file := shared.ModelsFile{
+		Models:     customModels,
+		ModelPacks: customModelPacks,
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("models", file)
+		return
+	}
+
+	bytes, err := yaml.Marshal(file)
+	if err != nil {
+		term.OutputErrorAndExit("Error marshalling models: %v", err)
+	}
+	fmt.Print(string(bytes))
+}
+
+func printModelsSyncChanges(changes []*shared.ModelsSyncChange) {
+	for _, change := range changes {
+		kind := "model"
+		if change.Kind == shared.ModelsSyncKindModelPack {
+			kind = "model pack"
+		}
+
+		var line string
+		switch change.Action {
+		case shared.ModelsSyncActionCreate:
+			line = color.New(color.FgGreen).Sprintf("+ %s %s", kind, change.Name)
+		case shared.ModelsSyncActionUpdate:
+			line = color.New(color.FgYellow).Sprintf("~ %s %s", kind, change.Name)
+			if len(change.Fields) > 0 {
+				line += fmt.Sprintf(" (%s)", strings.Join(change.Fields, ", "))
+			}
+		case shared.ModelsSyncActionDelete:
+			line = color.New(color.FgRed).Sprintf("- %s %s", kind, change.Name)
+		}
+
+		fmt.Println(line)
+	}
+}
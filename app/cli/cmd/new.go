@@ -17,6 +17,7 @@ import (
 
 var name string
 var contextBaseDir string
+var templateName string
 
 // newCmd represents the new command
 var newCmd = &cobra.Command{
@@ -32,6 +33,7 @@ func init() {
 	RootCmd.AddCommand(newCmd)
 	newCmd.Flags().StringVarP(&name, "name", "n", "", "Name of the new plan")
 	newCmd.Flags().StringVar(&contextBaseDir, "context-dir", ".", "Base directory to auto-load context from")
+	newCmd.Flags().StringVarP(&templateName, "template", "t", "", "Start from one of the org's plan templates")
 
 	AddNewPlanFlags(newCmd)
 }
@@ -42,10 +44,15 @@ func new(cmd *cobra.Command, args []string) {
 
 	term.StartSpinner("")
 
-	errCh := make(chan error, 2)
+	numRoutines := 2
+	if templateName != "" {
+		numRoutines++
+	}
+	errCh := make(chan error, numRoutines)
 
 	var planId string
 	var config *shared.PlanConfig
+	var template *shared.PlanTemplate
 
 	go func() {
 		res, apiErr := api.Client.CreatePlan(lib.CurrentProjectId, shared.CreatePlanRequest{Name: name})
@@ -67,7 +74,19 @@ func new(cmd *cobra.Command, args []string) {
 		errCh <- nil
 	}()
 
-	for i := 0; i < 2; i++ {
+	if templateName != "" {
+		go func() {
+			var apiErr *shared.ApiError
+			template, apiErr = api.Client.GetPlanTemplate(templateName)
+			if apiErr != nil {
+				errCh <- fmt.Errorf("error getting plan template: %v", apiErr.Msg)
+				return
+			}
+			errCh <- nil
+		}()
+	}
+
+	for i := 0; i < numRoutines; i++ {
 		err := <-errCh
 		if err != nil {
 			term.OutputErrorAndExit("Error: %v", err)
@@ -92,11 +111,35 @@ func new(cmd *cobra.Command, args []string) {
 	term.StopSpinner()
 
 	fmt.Printf("✅ Started new plan %s and set it to current plan\n", color.New(color.Bold, term.ColorHiGreen).Sprint(name))
-	fmt.Printf("⚙️  Using default config\n")
+
+	if term.JsonMode {
+		term.SetJsonData("planId", planId)
+		term.SetJsonData("name", name)
+		term.SetJsonData("branch", "main")
+	}
+
+	if template != nil {
+		fmt.Printf("📋 Using template %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(template.Name))
+		if term.JsonMode {
+			term.SetJsonData("template", template)
+		}
+	}
+
+	if template != nil && len(template.Config) > 0 {
+		config = mustApplyTemplateConfig(template, config)
+		fmt.Printf("⚙️  Using template config\n")
+	} else {
+		fmt.Printf("⚙️  Using default config\n")
+	}
 
 	resolveAutoMode(config)
 
-	resolveModelPack()
+	// a model pack flag takes precedence over the template's pack
+	if template != nil && template.ModelPack != "" && !(dailyModels || strongModels || cheapModels || ossModels) {
+		mustApplyTemplateModelPack(template.ModelPack)
+	} else {
+		resolveModelPack()
+	}
 
 	// autoModeLabel := shared.ConfigSettingsByKey["automode"].KeyToLabel(string(config.AutoMode))
 	// fmt.Println("⚡️ Auto-mode:", autoModeLabel)
@@ -118,6 +161,14 @@ func new(cmd *cobra.Command, args []string) {
 		fmt.Println()
 	}
 
+	if template != nil {
+		mustLoadTemplateContext(template)
+
+		if maybeSendTemplatePrompt(cmd, template, config) {
+			return
+		}
+	}
+
 	var cmds []string
 	if term.IsRepl {
 		cmds = []string{"config", "plans", "cd", "models"}
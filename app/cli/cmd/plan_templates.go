@@ -0,0 +1,361 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var templatesCmd = &cobra.Command{
+	Use:   "templates",
+	Short: "List the org's plan templates",
+	Args:  cobra.NoArgs,
+	Run:   listPlanTemplates,
+}
+
+var showTemplateCmd = &cobra.Command{
+	Use:   "show <name>",
+	Short: "Show a plan template as YAML",
+	Args:  cobra.ExactArgs(1),
+	Run:   showPlanTemplate,
+}
+
+var createTemplateCmd = &cobra.Command{
+	Use:   "create <file>",
+	Short: "Create a plan template from a YAML file",
+	Args:  cobra.ExactArgs(1),
+	Run:   createPlanTemplate,
+}
+
+var updateTemplateCmd = &cobra.Command{
+	Use:   "update <name> <file>",
+	Short: "Replace a plan template with a YAML file",
+	Args:  cobra.ExactArgs(2),
+	Run:   updatePlanTemplate,
+}
+
+var rmTemplateCmd = &cobra.Command{
+	Use:     "rm <name>",
+	Aliases: []string{"delete"},
+	Short:   "Delete a plan template",
+	Args:    cobra.ExactArgs(1),
+	Run:     rmPlanTemplate,
+}
+
+func init() {
+	RootCmd.AddCommand(templatesCmd)
+	templatesCmd.AddCommand(showTemplateCmd)
+	templatesCmd.AddCommand(createTemplateCmd)
+	templatesCmd.AddCommand(updateTemplateCmd)
+	templatesCmd.AddCommand(rmTemplateCmd)
+}
+
+func listPlanTemplates(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	templates, apiErr := api.Client.ListPlanTemplates()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching plan templates: %v", apiErr.Msg)
+		return
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("templates", templates)
+	}
+
+	if len(templates) == 0 {
+		fmt.Println("🤷‍♂️ No plan templates")
+		fmt.Println()
+		term.PrintCmds("", "templates create")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Name", "Description", "Context", "Notes", "Model Pack", "Prompt"})
+
+	for _, template := range templates {
+		modelPack := template.ModelPack
+		if modelPack == "" {
+			modelPack = "default"
+		}
+
+		hasPrompt := ""
+		if template.Prompt != "" {
+			hasPrompt = "✓"
+		}
+
+		table.Append([]string{
+			color.New(color.Bold, term.ColorHiCyan).Sprint(template.Name),
+			template.Description,
+			strings.Join(template.ContextPaths, "\n"),
+			strconv.Itoa(len(template.Notes)),
+			modelPack,
+			hasPrompt,
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "new --template", "templates show", "templates create", "templates rm")
+}
+
+func showPlanTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	template := mustGetPlanTemplate(args[0])
+
+	if term.JsonMode {
+		term.SetJsonData("template", template)
+	}
+
+	bytes, err := yaml.Marshal(template)
+	if err != nil {
+		term.OutputErrorAndExit("Error marshalling plan template: %v", err)
+	}
+	fmt.Println(strings.TrimSpace(string(bytes)))
+	fmt.Println()
+	term.PrintCmds("", "new --template", "templates update")
+}
+
+func createPlanTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	template := mustReadPlanTemplateFile(args[0])
+
+	term.StartSpinner("")
+	created, apiErr := api.Client.CreatePlanTemplate(*template)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating plan template: %v", apiErr.Msg)
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("template", created)
+	}
+
+	fmt.Println("✅ Created plan template", color.New(color.Bold, term.ColorHiCyan).Sprint(created.Name))
+	fmt.Println()
+	term.PrintCmds("", "new --template", "templates")
+}
+
+func updatePlanTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	template := mustReadPlanTemplateFile(args[1])
+
+	term.StartSpinner("")
+	updated, apiErr := api.Client.UpdatePlanTemplate(args[0], *template)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating plan template: %v", apiErr.Msg)
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("template", updated)
+	}
+
+	fmt.Println("✅ Updated plan template", color.New(color.Bold, term.ColorHiCyan).Sprint(updated.Name))
+	fmt.Println()
+	term.PrintCmds("", "templates show", "templates")
+}
+
+func rmPlanTemplate(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeletePlanTemplate(args[0])
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error deleting plan template: %v", apiErr.Msg)
+	}
+
+	fmt.Println("✅ Deleted plan template", color.New(color.Bold, term.ColorHiCyan).Sprint(args[0]))
+}
+
+func mustGetPlanTemplate(name string) *shared.PlanTemplate {
+	term.StartSpinner("")
+	template, apiErr := api.Client.GetPlanTemplate(name)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting plan template: %v", apiErr.Msg)
+	}
+
+	return template
+}
+
+func mustReadPlanTemplateFile(path string) *shared.PlanTemplate {
+	template, err := lib.ReadPlanTemplateFile(path)
+	if err != nil {
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, err.Error())
+		}
+		term.OutputErrorAndExit("Error reading plan template: %v", err)
+	}
+	return template
+}
+
+// applies a template's config overrides on top of the plan's current config
+func mustApplyTemplateConfig(template *shared.PlanTemplate, config *shared.PlanConfig) *shared.PlanConfig {
+	if len(template.Config) == 0 {
+		return config
+	}
+
+	updatedConfig, err := template.Config.Apply(config)
+	if err != nil {
+		term.OutputErrorAndExit("Error applying template config: %v", err)
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.UpdatePlanConfig(lib.CurrentPlanId, shared.UpdatePlanConfigRequest{
+		Config: updatedConfig,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating plan config: %v", apiErr.Msg)
+	}
+
+	return updatedConfig
+}
+
+// sets the plan's model pack to the template's built-in or custom pack
+func mustApplyTemplateModelPack(packName string) {
+	term.StartSpinner("")
+
+	settings, apiErr := api.Client.GetSettings(lib.CurrentPlanId, lib.CurrentBranch)
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting current settings: %v", apiErr.Msg)
+	}
+
+	var modelPack *shared.ModelPack
+	for _, pack := range shared.BuiltInModelPacks {
+		if pack.Name == packName {
+			modelPack = pack
+			break
+		}
+	}
+
+	if modelPack == nil {
+		customModelPacks, apiErr := api.Client.ListModelPacks()
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting custom model packs: %v", apiErr.Msg)
+		}
+
+		for _, pack := range customModelPacks {
+			if pack.Name == packName {
+				modelPack = pack
+				break
+			}
+		}
+	}
+
+	if modelPack == nil {
+		term.OutputErrorAndExit("Model pack %s from the template doesn't exist", packName)
+	}
+
+	if settings.ModelPack == nil || settings.ModelPack.Name != modelPack.Name {
+		settings.ModelPack = modelPack
+		_, apiErr = api.Client.UpdateSettings(lib.CurrentPlanId, lib.CurrentBranch, shared.UpdateSettingsRequest{
+			Settings: settings,
+		})
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error setting model pack: %v", apiErr.Msg)
+		}
+	}
+
+	term.StopSpinner()
+	printModelPackTable(modelPack.Name)
+}
+
+// loads a template's notes and the files its context paths match
+func mustLoadTemplateContext(template *shared.PlanTemplate) {
+	var filePaths []string
+	if len(template.ContextPaths) > 0 {
+		if fs.ProjectRoot == "" {
+			term.OutputErrorAndExit("Template context paths require a project")
+		}
+
+		var err error
+		filePaths, err = lib.ResolveTemplateContextPaths(template.ContextPaths)
+		if err != nil {
+			term.OutputErrorAndExit("Error resolving template context paths: %v", err)
+		}
+
+		if len(filePaths) == 0 {
+			fmt.Println("🤷‍♂️ No files match the template's context paths")
+		}
+	}
+
+	// each note is its own context, so the first one is loaded along with the files and the rest are loaded after
+	var firstNote string
+	if len(template.Notes) > 0 {
+		firstNote = template.Notes[0]
+	}
+
+	if len(filePaths) > 0 || firstNote != "" {
+		lib.MustLoadContext(filePaths, &types.LoadContextParams{
+			Note:              firstNote,
+			SkipIgnoreWarning: true,
+		})
+	}
+
+	for i := 1; i < len(template.Notes); i++ {
+		lib.MustLoadContext(nil, &types.LoadContextParams{
+			Note: template.Notes[i],
+		})
+	}
+}
+
+// shows a template's starter prompt and sends it if the user confirms -- returns true if it was sent
+func maybeSendTemplatePrompt(cmd *cobra.Command, template *shared.PlanTemplate, config *shared.PlanConfig) bool {
+	if template.Prompt == "" || term.JsonMode {
+		return false
+	}
+
+	fmt.Println()
+	color.New(color.Bold, term.ColorHiCyan).Println("💬 Starter prompt")
+	fmt.Println(template.Prompt)
+	fmt.Println()
+
+	send, err := term.ConfirmYesNo("Send the starter prompt now?")
+	if err != nil {
+		term.OutputErrorAndExit("Error getting user input: %v", err)
+	}
+
+	if !send {
+		return false
+	}
+
+	mustSetPlanExecFlagsWithConfig(cmd, config)
+
+	var apiKeys map[string]string
+	if !auth.Current.IntegratedModelsMode {
+		apiKeys = lib.MustVerifyApiKeys()
+	}
+
+	runTell(template.Prompt, apiKeys)
+
+	return true
+}
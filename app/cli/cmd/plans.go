@@ -113,6 +113,11 @@ func listActive() {
 	}
 
 	if len(projectIds) == 0 {
+		if term.JsonMode {
+			setPlansJsonData(nil, nil)
+			return
+		}
+
 		fmt.Println("🤷‍♂️ No plans")
 		fmt.Println()
 		term.PrintCmds("", "new")
@@ -128,6 +133,11 @@ func listActive() {
 	}
 
 	if len(plans) == 0 {
+		if term.JsonMode {
+			setPlansJsonData(nil, nil)
+			return
+		}
+
 		fmt.Println("🤷‍♂️ No plans")
 		fmt.Println()
 		term.PrintCmds("", "new")
@@ -169,22 +179,19 @@ func listActive() {
 	}
 	childProjectIdsWithPaths = childProjectIdsWithPathsFiltered
 
-	var b strings.Builder
-
-	if len(currentProjectPlanIds) > 0 {
-		currentBranchNamesByPlanId, err := lib.GetCurrentBranchNamesByPlanId(currentProjectPlanIds)
-
-		if err != nil {
-			term.OutputErrorAndExit("Error getting current branches: %v", err)
+	if term.JsonMode {
+		var currentBranchesByPlanId map[string]*shared.Branch
+		if len(currentProjectPlanIds) > 0 {
+			currentBranchesByPlanId = mustGetCurrentBranchesByPlanId(currentProjectPlanIds)
 		}
+		setPlansJsonData(plans, currentBranchesByPlanId)
+		return
+	}
 
-		currentBranchesByPlanId, apiErr := api.Client.GetCurrentBranchByPlanId(lib.CurrentProjectId, shared.GetCurrentBranchByPlanIdRequest{
-			CurrentBranchByPlanId: currentBranchNamesByPlanId,
-		})
+	var b strings.Builder
 
-		if apiErr != nil {
-			term.OutputErrorAndExit("Error getting current branches: %v", apiErr)
-		}
+	if len(currentProjectPlanIds) > 0 {
+		currentBranchesByPlanId := mustGetCurrentBranchesByPlanId(currentProjectPlanIds)
 
 		table := tablewriter.NewWriter(&b)
 		table.SetAutoWrapText(false)
@@ -352,6 +359,38 @@ func listActive() {
 	}
 }
 
+func mustGetCurrentBranchesByPlanId(planIds []string) map[string]*shared.Branch {
+	currentBranchNamesByPlanId, err := lib.GetCurrentBranchNamesByPlanId(planIds)
+
+	if err != nil {
+		term.OutputErrorAndExit("Error getting current branches: %v", err)
+	}
+
+	currentBranchesByPlanId, apiErr := api.Client.GetCurrentBranchByPlanId(lib.CurrentProjectId, shared.GetCurrentBranchByPlanIdRequest{
+		CurrentBranchByPlanId: currentBranchNamesByPlanId,
+	})
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting current branches: %v", apiErr)
+	}
+
+	return currentBranchesByPlanId
+}
+
+// setPlansJsonData sets the plans for --json output, along with the checked out branch of each plan in the current project
+func setPlansJsonData(plans []*shared.Plan, currentBranchesByPlanId map[string]*shared.Branch) {
+	if plans == nil {
+		plans = []*shared.Plan{}
+	}
+	if currentBranchesByPlanId == nil {
+		currentBranchesByPlanId = map[string]*shared.Branch{}
+	}
+
+	term.SetJsonData("plans", plans)
+	term.SetJsonData("currentPlanId", lib.CurrentPlanId)
+	term.SetJsonData("currentBranchesByPlanId", currentBranchesByPlanId)
+}
+
 func listArchived() {
 	var projectIds []string
 
@@ -367,6 +406,11 @@ func listArchived() {
 		term.OutputErrorAndExit("Error getting plans: %v", apiErr)
 	}
 
+	if term.JsonMode {
+		setPlansJsonData(plans, nil)
+		return
+	}
+
 	if len(plans) == 0 {
 		fmt.Println("🤷‍♂️ No archived plans")
 		fmt.Println()
@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var rootName string
+
+func init() {
+	RootCmd.AddCommand(projectCmd)
+	projectCmd.AddCommand(addRootCmd)
+	projectCmd.AddCommand(rmRootCmd)
+
+	addRootCmd.Flags().StringVarP(&rootName, "name", "n", "", "Name for the root, used to namespace its paths (defaults to the directory name)")
+}
+
+var projectCmd = &cobra.Command{
+	Use:   "project",
+	Short: "Show the project's roots",
+	Run:   listRoots,
+}
+
+var addRootCmd = &cobra.Command{
+	Use:   "add-root <dir>",
+	Short: "Add a directory or repo outside the project root to the project",
+	Args:  cobra.ExactArgs(1),
+	Run:   addRoot,
+}
+
+var rmRootCmd = &cobra.Command{
+	Use:     "rm-root <name>",
+	Aliases: []string{"remove-root"},
+	Short:   "Remove an additional root from the project",
+	Args:    cobra.ExactArgs(1),
+	Run:     rmRoot,
+}
+
+func listRoots(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if term.JsonMode {
+		roots := fs.Roots
+		if roots == nil {
+			roots = []*types.ProjectRoot{}
+		}
+		term.SetJsonData("projectRoot", fs.ProjectRoot)
+		term.SetJsonData("roots", roots)
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Root", "Path", "Git"})
+
+	table.Rich([]string{"(main)", fs.ProjectRoot, gitLabel(fs.ProjectRootIsGitRepo())}, []tablewriter.Colors{{tablewriter.Bold}})
+	for _, root := range fs.Roots {
+		table.Append([]string{root.Name, fs.RootDir(root), gitLabel(fs.IsGitRepo(fs.RootDir(root)))})
+	}
+
+	table.Render()
+	fmt.Println()
+
+	if len(fs.Roots) > 0 {
+		fmt.Printf("Files in additional roots are namespaced with the root's name, like %s\n", color.New(color.Bold, term.ColorHiCyan).Sprintf("%s:src/main.go", fs.Roots[0].Name))
+		fmt.Println()
+		term.PrintCmds("", "project add-root", "project rm-root")
+	} else {
+		term.PrintCmds("", "project add-root")
+	}
+}
+
+func addRoot(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	dir := args[0]
+
+	name := rootName
+	if name == "" {
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			term.OutputErrorAndExit("Error getting absolute path: %v", err)
+		}
+		name = filepath.Base(absDir)
+	}
+
+	root, err := fs.AddRoot(name, dir)
+	if err != nil {
+		term.OutputErrorAndExit("Error adding root: %v", err)
+	}
+
+	if term.JsonMode {
+		term.SetJsonData("root", root)
+	}
+
+	fmt.Printf("✅ Added root %s → %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(root.Name), fs.RootDir(root))
+	fmt.Println()
+	fmt.Printf("Load its files with paths like %s\n", color.New(color.Bold, term.ColorHiCyan).Sprintf("plandex load %s:src -r", root.Name))
+	fmt.Println()
+
+	// plans with auto-loaded context get the new root's map right away
+	if lib.CurrentPlanId != "" {
+		term.StartSpinner("")
+		config, apiErr := api.Client.GetPlanConfig(lib.CurrentPlanId)
+		term.StopSpinner()
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting plan config: %v", apiErr.Msg)
+		}
+
+		if config != nil && config.AutoLoadContext {
+			lib.MustLoadContext([]string{fs.JoinRootPath(root, ".")}, &types.LoadContextParams{
+				DefsOnly:          true,
+				SkipIgnoreWarning: true,
+				AutoLoaded:        true,
+			})
+			fmt.Println()
+		}
+	}
+
+	term.PrintCmds("", "project", "load")
+}
+
+func rmRoot(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	name := args[0]
+
+	err := fs.RemoveRoot(name)
+	if err != nil {
+		term.OutputErrorAndExit("Error removing root: %v", err)
+	}
+
+	fmt.Printf("✅ Removed root %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(name))
+
+	// context from the root can't be updated anymore, so remove it from the current plan
+	if lib.CurrentPlanId != "" {
+		term.StartSpinner("")
+		contexts, apiErr := api.Client.ListContext(lib.CurrentPlanId, lib.CurrentBranch)
+		if apiErr != nil {
+			term.StopSpinner()
+			term.OutputErrorAndExit("Error listing context: %v", apiErr.Msg)
+		}
+
+		ids := map[string]bool{}
+		prefix := name + ":"
+		for _, c := range contexts {
+			if strings.HasPrefix(c.FilePath, prefix) {
+				ids[c.Id] = true
+			}
+		}
+
+		if len(ids) > 0 {
+			res, apiErr := api.Client.DeleteContext(lib.CurrentPlanId, lib.CurrentBranch, shared.DeleteContextRequest{
+				Ids: ids,
+			})
+			term.StopSpinner()
+			if apiErr != nil {
+				term.OutputErrorAndExit("Error removing context: %v", apiErr.Msg)
+			}
+			fmt.Println("✅ " + res.Msg)
+		} else {
+			term.StopSpinner()
+		}
+	}
+
+	fmt.Println()
+	term.PrintCmds("", "project")
+}
+
+func gitLabel(isRepo bool) string {
+	if isRepo {
+		return "yes"
+	}
+	return "no"
+}
@@ -8,6 +8,7 @@ import (
 	"plandex-cli/format"
 	"plandex-cli/lib"
 	"plandex-cli/term"
+	"time"
 
 	shared "plandex-shared"
 
@@ -42,6 +43,11 @@ func ps(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if term.JsonMode {
+		setPsJsonData(res)
+		return
+	}
+
 	if len(res.Branches) == 0 {
 		fmt.Println("🤷‍♂️ No active or recently finished streams")
 		return
@@ -96,3 +102,35 @@ func ps(cmd *cobra.Command, args []string) {
 	term.PrintCmds("", "connect", "stop")
 
 }
+
+type psJsonStream struct {
+	StreamId   string            `json:"streamId"`
+	PlanId     string            `json:"planId"`
+	PlanName   string            `json:"planName"`
+	Branch     string            `json:"branch"`
+	Status     shared.PlanStatus `json:"status"`
+	StartedAt  time.Time         `json:"startedAt"`
+	FinishedAt *time.Time        `json:"finishedAt,omitempty"`
+}
+
+func setPsJsonData(res *shared.ListPlansRunningResponse) {
+	streams := []psJsonStream{}
+	for _, b := range res.Branches {
+		stream := psJsonStream{
+			StreamId:  res.StreamIdByBranchId[b.Id],
+			PlanId:    b.PlanId,
+			Branch:    b.Name,
+			Status:    b.Status,
+			StartedAt: res.StreamStartedAtByBranchId[b.Id],
+		}
+		if plan := res.PlansById[b.PlanId]; plan != nil {
+			stream.PlanName = plan.Name
+		}
+		if finishedAt, ok := res.StreamFinishedAtByBranchId[b.Id]; ok {
+			stream.FinishedAt = &finishedAt
+		}
+		streams = append(streams, stream)
+	}
+
+	term.SetJsonData("streams", streams)
+}
@@ -74,6 +74,10 @@ func reject(cmd *cobra.Command, args []string) {
 		}
 		sort.Strings(sortedFiles)
 
+		if term.JsonMode {
+			term.SetJsonData("rejectedFiles", sortedFiles)
+		}
+
 		for _, file := range sortedFiles {
 			fmt.Printf("• 📄 %s\n", file)
 		}
@@ -107,6 +111,10 @@ func reject(cmd *cobra.Command, args []string) {
 		sortedFiles := append([]string{}, args...)
 		sort.Strings(sortedFiles)
 
+		if term.JsonMode {
+			term.SetJsonData("rejectedFiles", sortedFiles)
+		}
+
 		for _, file := range sortedFiles {
 			fmt.Printf("• 📄 %s\n", file)
 		}
@@ -117,6 +125,10 @@ func reject(cmd *cobra.Command, args []string) {
 	// No args provided - use survey multiselect
 	term.StopSpinner()
 
+	if term.JsonMode {
+		term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, "pass the files to reject, or --all, with --json")
+	}
+
 	pathsToSort := make([]string, 0, len(currentFiles))
 	for path := range currentFiles {
 		pathsToSort = append(pathsToSort, path)
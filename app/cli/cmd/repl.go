@@ -220,7 +220,7 @@ func getSuggestions() []prompt.Suggest {
 		suggestions = append(suggestions, []prompt.Suggest{
 			{Text: "\\send", Description: "(\\s) Send the current prompt"},
 			{Text: "\\multi", Description: "(\\m) Turn multi-line mode off"},
-			{Text: "\\run", Description: "(\\r) Run a file through tell/chat based on current mode"},
+			{Text: "\\run", Description: "(\\r) Run a file through tell/chat based on current mode, or run a .yml recipe"},
 			{Text: "\\quit", Description: "(\\q) Exit the REPL"},
 		}...)
 
@@ -239,7 +239,7 @@ func getSuggestions() []prompt.Suggest {
 	if !lib.CurrentReplState.IsMulti {
 		suggestions = append(suggestions, []prompt.Suggest{
 			{Text: "\\multi", Description: "(\\m) Turn multi-line mode on"},
-			{Text: "\\run", Description: "(\\r) Run a file through tell/chat based on current mode"},
+			{Text: "\\run", Description: "(\\r) Run a file through tell/chat based on current mode, or run a .yml recipe"},
 			{Text: "\\quit", Description: "(\\q) Exit the REPL"},
 		}...)
 	}
@@ -1109,10 +1109,7 @@ func isFileInProjectPaths(filePath string) bool {
 
 	// Check if file is within any project path
 	for path := range projectPaths.ActivePaths {
-		projectAbs, err := filepath.Abs(path)
-		if err != nil {
-			continue
-		}
+		projectAbs := fs.AbsPath(path)
 		if strings.HasPrefix(absPath, projectAbs) {
 			return true
 		}
@@ -1132,9 +1129,11 @@ func handleRunCommand(args []string) error {
 		return fmt.Errorf("file does not exist: %s", filePath)
 	}
 
-	// Build command based on current mode
+	// Build command based on current mode, or run a recipe
 	var cmdArgs []string
-	if lib.CurrentReplState.Mode == lib.ReplModeTell {
+	if ext := filepath.Ext(filePath); ext == ".yml" || ext == ".yaml" {
+		cmdArgs = []string{"run", filePath}
+	} else if lib.CurrentReplState.Mode == lib.ReplModeTell {
 		cmdArgs = []string{"tell", "-f", filePath}
 	} else {
 		cmdArgs = []string{"chat", "-f", filePath}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/plan_exec"
+	reviewtui "plandex-cli/review_tui"
+	"plandex-cli/term"
+	"plandex-cli/types"
+
+	"github.com/spf13/cobra"
+)
+
+var reviewNoApply bool
+
+func init() {
+	initApplyFlags(reviewCmd, false)
+	initExecScriptFlags(reviewCmd)
+	RootCmd.AddCommand(reviewCmd)
+
+	reviewCmd.Flags().BoolVar(&reviewNoApply, "no-apply", false, "Only record review decisions—leave accepted changes pending")
+}
+
+var reviewCmd = &cobra.Command{
+	Use:     "review",
+	Aliases: []string{"rv"},
+	Short:   "Review pending changes hunk by hunk, then apply the accepted ones",
+	Run:     review,
+}
+
+func review(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	mustSetPlanExecFlags(cmd)
+
+	term.StartSpinner("")
+	currentPlanState, apiErr := api.Client.GetCurrentPlanState(lib.CurrentPlanId, lib.CurrentBranch)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting current plan state: %v", apiErr)
+	}
+
+	hunks := reviewtui.HunksFromPlanState(currentPlanState)
+
+	if len(hunks) == 0 {
+		fmt.Println("🤷‍♂️ No pending changes to review")
+		return
+	}
+
+	finished, err := reviewtui.StartReviewUI(hunks, defaultEditor)
+	if err != nil {
+		term.OutputErrorAndExit("Error reviewing changes: %v", err)
+	}
+
+	if !finished {
+		fmt.Println("🛑 Review cancelled—no changes were made")
+		return
+	}
+
+	req := reviewtui.RejectRequest(hunks)
+
+	if len(req.Rejected) > 0 || len(req.Edited) > 0 {
+		term.StartSpinner("")
+		apiErr = api.Client.RejectReplacements(lib.CurrentPlanId, lib.CurrentBranch, req)
+		term.StopSpinner()
+
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error saving review: %v", apiErr.Msg)
+		}
+	}
+
+	numAccepted := reviewtui.NumAccepted(hunks)
+
+	suffix := ""
+	if len(hunks) > 1 {
+		suffix = "s"
+	}
+	fmt.Printf("✅ Reviewed %d change%s: %d accepted, %d rejected", len(hunks), suffix, numAccepted, len(hunks)-numAccepted)
+	if len(req.Edited) > 0 {
+		fmt.Printf(", %d edited", len(req.Edited))
+	}
+	fmt.Println()
+
+	if numAccepted == 0 {
+		return
+	}
+
+	if reviewNoApply {
+		fmt.Println()
+		fmt.Println("Accepted changes are still pending")
+		term.PrintCmds("", "apply", "diff", "reject")
+		return
+	}
+
+	fmt.Println()
+
+	applyFlags := types.ApplyFlags{
+		AutoConfirm: true,
+		AutoCommit:  autoCommit,
+		NoCommit:    skipCommit,
+		AutoExec:    autoExec,
+		NoExec:      noExec,
+		AutoDebug:   autoDebug,
+	}
+
+	tellFlags := types.TellFlags{
+		TellBg:      tellBg,
+		TellStop:    tellStop,
+		TellNoBuild: tellNoBuild,
+		AutoContext: tellAutoContext,
+		ExecEnabled: !noExec,
+		AutoApply:   tellAutoApply,
+	}
+
+	lib.MustApplyPlan(lib.ApplyPlanParams{
+		PlanId:     lib.CurrentPlanId,
+		Branch:     lib.CurrentBranch,
+		ApplyFlags: applyFlags,
+		TellFlags:  tellFlags,
+		OnExecFail: plan_exec.GetOnApplyExecFail(applyFlags, tellFlags),
+	})
+}
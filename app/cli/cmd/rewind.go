@@ -422,7 +422,7 @@ func rewind(cmd *cobra.Command, args []string) {
 		shouldCommit := false
 		needsPrompt := true
 
-		if !fs.ProjectRootIsGitRepo() {
+		if !fs.AnyRootIsGitRepo() {
 			shouldCommit = false
 			needsPrompt = false
 		} else {
@@ -465,7 +465,7 @@ func rewind(cmd *cobra.Command, args []string) {
 				paths = append(paths, path)
 			}
 
-			err := lib.GitAddAndCommitPaths(fs.ProjectRoot, msg, paths, true)
+			err := lib.GitAddAndCommitProjectPaths(msg, paths)
 			if err != nil {
 				term.OutputErrorAndExit("Error committing changes: %v", err)
 			}
@@ -11,6 +11,7 @@ import (
 )
 
 var helpShowAll bool
+var jsonOutput bool
 
 // RootCmd represents the base command when called without any subcommands
 var RootCmd = &cobra.Command{
@@ -18,18 +19,90 @@ var RootCmd = &cobra.Command{
 	// Short: "Plandex: iterative development with AI",
 	SilenceErrors: true,
 	SilenceUsage:  true,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		if jsonOutput {
+			term.EnableJsonMode()
+			command := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+			term.SetJsonCommand(command)
+
+			if cmd != cmd.Root() && !jsonCommands[command] {
+				term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, fmt.Sprintf("%s doesn't support --json", command))
+			}
+		}
+	},
+	PersistentPostRun: func(cmd *cobra.Command, args []string) {
+		if term.JsonMode {
+			term.OutputJson()
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		run(cmd, args)
 	},
 }
 
+// jsonCommands have a JSON output shape -- any other command fails with a usage error when --json is passed rather than outputting a document with no data
+var jsonCommands = map[string]bool{
+	"tell":                true,
+	"continue":            true,
+	"chat":                true,
+	"build":               true,
+	"connect":             true,
+	"apply":               true,
+	"reject":              true,
+	"new":                 true,
+	"plans":               true,
+	"current":             true,
+	"branches":            true,
+	"ps":                  true,
+	"ls":                  true,
+	"diff":                true,
+	"log":                 true,
+	"convo":               true,
+	"merge":               true,
+	"run":                 true,
+	"version":             true,
+	"project":             true,
+	"project add-root":    true,
+	"project rm-root":     true,
+	"budgets":             true,
+	"budgets set":         true,
+	"budgets rm":          true,
+	"exec-policy":         true,
+	"exec-policy check":   true,
+	"exec-policy set-org": true,
+	"exec-policy rm-org":  true,
+	"templates":           true,
+	"templates show":      true,
+	"templates create":    true,
+	"templates update":    true,
+	"templates rm":        true,
+	"models discover":     true,
+	"models sync":         true,
+	"models export":       true,
+	"model-packs export":  true,
+	"model-packs import":  true,
+}
+
+// ResolveJsonMode checks for the global --json flag before cobra parses flags, since output and prompts need to be headless from the start (including for the upgrade check and usage errors)
+func ResolveJsonMode() {
+	for _, arg := range os.Args[1:] {
+		if arg == "--" {
+			break
+		}
+		if arg == "--json" || arg == "--json=true" {
+			term.EnableJsonMode()
+			return
+		}
+	}
+}
+
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
 func Execute() {
 	// if no arguments were passed, start the repl
-	if len(os.Args) == 1 ||
+	if !term.JsonMode && (len(os.Args) == 1 ||
 		(len(os.Args) == 2 && strings.HasPrefix(os.Args[1], "--") && os.Args[1] != "--help") ||
-		(len(os.Args) == 3 && strings.HasPrefix(os.Args[1], "--") && os.Args[1] != "--help" && strings.HasPrefix(os.Args[2], "--") && os.Args[2] != "--help") {
+		(len(os.Args) == 3 && strings.HasPrefix(os.Args[1], "--") && os.Args[1] != "--help" && strings.HasPrefix(os.Args[2], "--") && os.Args[2] != "--help")) {
 
 		// Instead of directly calling replCmd.Run, parse the flags first
 		replCmd.ParseFlags(os.Args[1:])
@@ -41,6 +114,10 @@ func Execute() {
 		// term.OutputErrorAndExit("Error executing root command: %v", err)
 		// log.Fatalf("Error executing root command: %v", err)
 
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, err.Error())
+		}
+
 		// output the error message to stderr
 		term.OutputSimpleError("Error: %v", err)
 
@@ -70,7 +147,9 @@ func Execute() {
 }
 
 func run(cmd *cobra.Command, args []string) {
-
+	if term.JsonMode {
+		term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, "a command is required with --json")
+	}
 }
 
 func init() {
@@ -88,4 +167,6 @@ func init() {
 
 	// add an --all/-a flag
 	helpCmd.Flags().BoolVarP(&helpShowAll, "all", "a", false, "Show all commands")
+
+	RootCmd.PersistentFlags().BoolVar(&jsonOutput, "json", false, "Output a versioned JSON document instead of formatted text (streaming commands also output each stream event as a line of JSON)")
 }
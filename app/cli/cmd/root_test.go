@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestJsonCommandsExist(t *testing.T) {
+	for command := range jsonCommands {
+		found, _, err := RootCmd.Find(strings.Fields(command))
+		if err != nil || found == RootCmd {
+			t.Errorf("json command %q not found", command)
+			continue
+		}
+
+		path := strings.TrimPrefix(found.CommandPath(), RootCmd.Name()+" ")
+		if path != command {
+			t.Errorf("json command %q resolves to %q", command, path)
+		}
+	}
+}
@@ -0,0 +1,596 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/lib"
+	"plandex-cli/plan_exec"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// only the tail of each step's output is kept in the report
+const recipeMaxOutputBytes = 10000
+
+var recipeVars []string
+var recipeReportPath string
+
+var runCmd = &cobra.Command{
+	Use:   "run <recipe>",
+	Short: "Run a recipe of context, prompt, apply, and exec steps",
+	Args:  cobra.ExactArgs(1),
+	Run:   runRecipe,
+}
+
+func init() {
+	RootCmd.AddCommand(runCmd)
+	runCmd.Flags().StringArrayVar(&recipeVars, "var", []string{}, "Set a recipe variable (key=value)—can be repeated")
+	runCmd.Flags().StringVar(&recipeReportPath, "report", "", "Write a JSON report of the run to this path")
+}
+
+type recipeRunner struct {
+	recipe  *lib.Recipe
+	report  *lib.RecipeReport
+	config  *shared.PlanConfig
+	apiKeys map[string]string
+	cwd     string
+}
+
+func runRecipe(cmd *cobra.Command, args []string) {
+	vars := map[string]string{}
+	for _, kv := range recipeVars {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			if term.JsonMode {
+				term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, fmt.Sprintf("invalid --var %q—use key=value", kv))
+			}
+			term.OutputErrorAndExit("Invalid --var %q—use key=value", kv)
+		}
+		vars[k] = v
+	}
+
+	recipe, err := lib.LoadRecipe(args[0], vars)
+	if err != nil {
+		if term.JsonMode {
+			term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, fmt.Sprintf("invalid recipe: %v", err))
+		}
+		term.OutputErrorAndExit("Invalid recipe: %v", err)
+	}
+
+	auth.MustResolveAuthWithOrg()
+	lib.MustResolveProject()
+
+	if lib.CurrentPlanId == "" {
+		term.OutputNoCurrentPlanErrorAndExit()
+	}
+
+	term.StartSpinner("")
+	config, apiErr := api.Client.GetPlanConfig(lib.CurrentPlanId)
+	term.StopSpinner()
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting plan config: %v", apiErr.Msg)
+	}
+	if config == nil {
+		config = &shared.PlanConfig{}
+	}
+
+	var apiKeys map[string]string
+	if !auth.Current.IntegratedModelsMode {
+		apiKeys = lib.MustVerifyApiKeys()
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		term.OutputErrorAndExit("Failed to get working directory: %v", err)
+	}
+
+	r := &recipeRunner{
+		recipe: recipe,
+		report: &lib.RecipeReport{
+			Recipe:    args[0],
+			Name:      recipe.Name,
+			PlanId:    lib.CurrentPlanId,
+			Branch:    lib.CurrentBranch,
+			Status:    lib.RecipeStepRunning,
+			Vars:      recipe.Vars,
+			Steps:     []*lib.RecipeStepResult{},
+			StartedAt: time.Now().UTC(),
+		},
+		config:  config,
+		apiKeys: apiKeys,
+		cwd:     cwd,
+	}
+
+	// steps that exit the process on an error still leave a failed report
+	term.SetOnErrorExitFn(r.onErrorExit)
+
+	r.run()
+}
+
+func (r *recipeRunner) run() {
+	failed := false
+
+	for i, step := range r.recipe.Steps {
+		kind := step.Kind()
+		res := &lib.RecipeStepResult{
+			Id:        step.Id,
+			Kind:      kind,
+			Status:    lib.RecipeStepRunning,
+			StartedAt: time.Now().UTC(),
+		}
+		r.report.Steps = append(r.report.Steps, res)
+
+		if !step.ShouldRun(r.report) {
+			res.Status = lib.RecipeStepSkipped
+			fmt.Printf("⏭️  Skipping %s (%s)\n\n", color.New(color.Bold).Sprint(step.Id), step.When)
+			continue
+		}
+
+		color.New(term.ColorHiCyan, color.Bold).Printf("▶ Step %d/%d: %s (%s)\n", i+1, len(r.recipe.Steps), step.Id, kind)
+		fmt.Println()
+
+		// saved before each step runs so a step that exits the process still leaves a report
+		r.saveReport()
+
+		err := r.runStep(step, res)
+		res.DurationMs = time.Since(res.StartedAt).Milliseconds()
+		res.Output = tailOutput(res.Output)
+
+		if err != nil {
+			res.Status = lib.RecipeStepFailed
+			res.Error = err.Error()
+			fmt.Printf("❌ %s failed: %v\n\n", step.Id, err)
+			if !step.ContinueOnError {
+				failed = true
+				break
+			}
+		} else {
+			res.Status = lib.RecipeStepSucceeded
+			fmt.Printf("✅ %s succeeded\n\n", step.Id)
+		}
+	}
+
+	r.finish(failed)
+
+	if term.JsonMode {
+		// steps set their own json data as they run—only the report is output
+		term.ClearJsonData()
+		term.SetJsonData("report", r.report)
+		if failed {
+			term.OutputJsonErrorAndExit(string(shared.ApiErrorTypeOther), fmt.Sprintf("Recipe failed at step %s", r.report.Steps[len(r.report.Steps)-1].Id))
+		}
+		return
+	}
+
+	r.printSummary()
+
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func (r *recipeRunner) finish(failed bool) {
+	finishedAt := time.Now().UTC()
+	r.report.FinishedAt = &finishedAt
+	if failed {
+		r.report.Status = lib.RecipeStepFailed
+	} else {
+		r.report.Status = lib.RecipeStepSucceeded
+	}
+	r.saveReport()
+}
+
+// onErrorExit fails the running step when it exits the process on an error, like a stream error during a tell step
+func (r *recipeRunner) onErrorExit(msg string) {
+	if r.report.FinishedAt != nil {
+		return
+	}
+
+	for _, res := range r.report.Steps {
+		if res.Status == lib.RecipeStepRunning {
+			res.Status = lib.RecipeStepFailed
+			res.Error = msg
+			res.DurationMs = time.Since(res.StartedAt).Milliseconds()
+			res.Output = tailOutput(res.Output)
+		}
+	}
+
+	r.finish(true)
+
+	if term.JsonMode {
+		term.ClearJsonData()
+		term.SetJsonData("report", r.report)
+	}
+}
+
+func (r *recipeRunner) runStep(step *lib.RecipeStep, res *lib.RecipeStepResult) error {
+	render := func(s string) (string, error) {
+		return lib.RenderRecipeString(s, r.recipe, r.report)
+	}
+
+	switch step.Kind() {
+	case lib.RecipeStepLoad:
+		var paths []string
+		for _, p := range step.Load {
+			rendered, err := render(p)
+			if err != nil {
+				return err
+			}
+			paths = append(paths, rendered)
+		}
+		note, err := render(step.Note)
+		if err != nil {
+			return err
+		}
+		lib.MustLoadContext(paths, &types.LoadContextParams{
+			Note:      note,
+			Recursive: step.Recursive,
+		})
+		return nil
+
+	case lib.RecipeStepTell:
+		prompt, err := render(step.Tell)
+		if err != nil {
+			return err
+		}
+		plan_exec.TellPlan(r.execParams(), prompt, types.TellFlags{
+			TellStop:     !r.config.AutoContinue,
+			TellNoBuild:  !r.config.AutoBuild,
+			AutoContext:  r.config.AutoLoadContext,
+			SmartContext: r.config.SmartContext,
+			ExecEnabled:  r.config.CanExec,
+			NoMenu:       true,
+		})
+		return nil
+
+	case lib.RecipeStepBuild:
+		_, err := plan_exec.Build(r.execParams(), types.BuildFlags{AutoApply: true})
+		return err
+
+	case lib.RecipeStepApply:
+		return r.apply(step, res)
+
+	case lib.RecipeStepExec:
+		cmdStr, err := render(step.Exec)
+		if err != nil {
+			return err
+		}
+		exitCode, output, err := r.execCommand(cmdStr)
+		res.ExitCode = &exitCode
+		res.Output = output
+		if err != nil {
+			return err
+		}
+		if exitCode != 0 {
+			return fmt.Errorf("exit status %d", exitCode)
+		}
+		return nil
+
+	case lib.RecipeStepDebug:
+		return r.debug(step, res, render)
+
+	case lib.RecipeStepSetConfig:
+		return r.setConfig(step, render)
+
+	case lib.RecipeStepAssert:
+		return r.assert(step, render)
+	}
+
+	return fmt.Errorf("unknown step kind")
+}
+
+// apply applies the plan, running its commands if the step allows it. When the commands fail, the changes are rolled back and the plan
+// debugs them up to apply.debug times before the step fails—there's no prompt for what to do next.
+func (r *recipeRunner) apply(step *lib.RecipeStep, res *lib.RecipeStepResult) error {
+	applyFlags := types.ApplyFlags{
+		AutoConfirm: true,
+		AutoCommit:  step.Apply.Commit,
+		NoCommit:    !step.Apply.Commit,
+		AutoExec:    step.Apply.Exec,
+		NoExec:      !step.Apply.Exec,
+	}
+	tellFlags := types.TellFlags{
+		AutoContext: r.config.AutoLoadContext,
+		ExecEnabled: step.Apply.Exec,
+	}
+
+	record := func(status int, output string) {
+		res.Attempts++
+		res.ExitCode = &status
+		res.Output = output
+	}
+
+	for attempt := 0; ; attempt++ {
+		var ran, execFailed bool
+
+		lib.MustApplyPlan(lib.ApplyPlanParams{
+			PlanId:     lib.CurrentPlanId,
+			Branch:     lib.CurrentBranch,
+			ApplyFlags: applyFlags,
+			TellFlags:  tellFlags,
+			OnExecResult: func(status int, output string) {
+				ran = true
+				record(status, output)
+			},
+			OnExecFail: func(status int, output string, _ int, toRollback *types.ApplyRollbackPlan, onErr types.OnErrFn, onSuccess func()) {
+				// exec policy violations fail before anything runs
+				if !ran {
+					record(status, output)
+				}
+				if toRollback != nil && toRollback.HasChanges() {
+					lib.Rollback(toRollback, true)
+				}
+				execFailed = true
+			},
+		})
+
+		if !execFailed {
+			return nil
+		}
+
+		if attempt >= step.Apply.Debug {
+			return fmt.Errorf("commands failed with exit status %d—changes were rolled back", *res.ExitCode)
+		}
+
+		prompt := fmt.Sprintf("Execution failed with exit status %d. Output:\n\n%s\n\n--\n\n", *res.ExitCode, res.Output)
+
+		plan_exec.TellPlan(r.execParams(), prompt, types.TellFlags{
+			AutoContext:  r.config.AutoLoadContext,
+			ExecEnabled:  true,
+			IsApplyDebug: true,
+		})
+	}
+}
+
+func (r *recipeRunner) execParams() plan_exec.ExecParams {
+	return plan_exec.ExecParams{
+		CurrentPlanId: lib.CurrentPlanId,
+		CurrentBranch: lib.CurrentBranch,
+		ApiKeys:       r.apiKeys,
+		CheckOutdatedContext: func(maybeContexts []*shared.Context, projectPaths *types.ProjectPaths) (bool, bool, error) {
+			return lib.CheckOutdatedContextWithOutput(true, true, maybeContexts, projectPaths)
+		},
+	}
+}
+
+// execCommand runs a command through the shell, showing its output as it runs and returning it along with the exit code
+func (r *recipeRunner) execCommand(cmdStr string) (int, string, error) {
+	var output strings.Builder
+	w := io.MultiWriter(os.Stdout, &output)
+
+	execCmd := exec.Command("sh", "-c", cmdStr)
+	execCmd.Dir = r.cwd
+	execCmd.Env = os.Environ()
+	execCmd.Stdout = w
+	execCmd.Stderr = w
+
+	err := execCmd.Run()
+	fmt.Println()
+
+	if err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitCode(), output.String(), nil
+		}
+		return -1, output.String(), fmt.Errorf("failed to run command: %v", err)
+	}
+
+	return 0, output.String(), nil
+}
+
+// debug works like 'plandex debug': run the command, and on failure send the output to the plan, apply the fix, and try again
+func (r *recipeRunner) debug(step *lib.RecipeStep, res *lib.RecipeStepResult, render func(string) (string, error)) error {
+	cmdStr, err := render(step.Debug.Command)
+	if err != nil {
+		return err
+	}
+
+	tries := step.Debug.Tries
+
+	for attempt := 0; attempt < tries; attempt++ {
+		res.Attempts = attempt + 1
+
+		exitCode, output, err := r.execCommand(cmdStr)
+		res.ExitCode = &exitCode
+		res.Output = output
+		if err != nil {
+			return err
+		}
+
+		if exitCode == 0 {
+			return nil
+		}
+
+		if attempt == tries-1 {
+			return fmt.Errorf("command failed after %d tries", tries)
+		}
+
+		prompt := fmt.Sprintf("'%s' failed with exit status %d. Output:\n\n%s\n\n--\n\n", cmdStr, exitCode, output)
+
+		tellFlags := types.TellFlags{
+			AutoContext: r.config.AutoLoadContext,
+			ExecEnabled: false,
+			IsUserDebug: true,
+		}
+
+		plan_exec.TellPlan(r.execParams(), prompt, tellFlags)
+
+		applyFlags := types.ApplyFlags{
+			AutoConfirm: true,
+			AutoCommit:  step.Debug.Commit,
+			NoCommit:    !step.Debug.Commit,
+			NoExec:      true,
+		}
+
+		lib.MustApplyPlan(lib.ApplyPlanParams{
+			PlanId:     lib.CurrentPlanId,
+			Branch:     lib.CurrentBranch,
+			ApplyFlags: applyFlags,
+			TellFlags:  tellFlags,
+		})
+	}
+
+	return nil
+}
+
+func (r *recipeRunner) setConfig(step *lib.RecipeStep, render func(string) (string, error)) error {
+	var keys []string
+	for k := range step.SetConfig {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	updatedConfig := *r.config
+
+	for _, k := range keys {
+		setting := strings.ToLower(strings.ReplaceAll(k, "-", ""))
+		cfgSetting, exists := shared.ConfigSettingsByKey[setting]
+		if !exists {
+			return fmt.Errorf("unknown setting: %s", k)
+		}
+
+		value, err := render(step.SetConfig[k])
+		if err != nil {
+			return err
+		}
+
+		err = setConfigValue(&updatedConfig, cfgSetting, value)
+		if err != nil {
+			return err
+		}
+	}
+
+	term.StartSpinner("")
+	apiErr := api.Client.UpdatePlanConfig(lib.CurrentPlanId, shared.UpdatePlanConfigRequest{
+		Config: &updatedConfig,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		return fmt.Errorf("error updating config: %v", apiErr.Msg)
+	}
+
+	loadMapIfNeeded(r.config, &updatedConfig)
+	removeMapIfNeeded(r.config, &updatedConfig)
+	term.StopSpinner()
+
+	r.config = &updatedConfig
+
+	fmt.Println("✅ Config updated")
+	return nil
+}
+
+func (r *recipeRunner) assert(step *lib.RecipeStep, render func(string) (string, error)) error {
+	a := step.Assert
+
+	if a.That != "" {
+		ok, err := lib.EvalRecipeCondition(a.That, r.report)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return fmt.Errorf("%s is false", a.That)
+		}
+	}
+
+	if a.FileExists != "" {
+		path, err := render(a.FileExists)
+		if err != nil {
+			return err
+		}
+		_, err = os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("%s doesn't exist", path)
+		}
+	}
+
+	if a.FileContains != nil {
+		path, err := render(a.FileContains.Path)
+		if err != nil {
+			return err
+		}
+		text, err := render(a.FileContains.Text)
+		if err != nil {
+			return err
+		}
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("error reading %s: %v", path, err)
+		}
+		if !strings.Contains(string(bytes), text) {
+			return fmt.Errorf("%s doesn't contain %q", path, text)
+		}
+	}
+
+	return nil
+}
+
+func (r *recipeRunner) saveReport() {
+	if recipeReportPath == "" {
+		return
+	}
+	err := r.report.Save(recipeReportPath)
+	if err != nil {
+		term.OutputErrorAndExit("%v", err)
+	}
+}
+
+func (r *recipeRunner) printSummary() {
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Step", "Kind", "Status", "Exit", "Time"})
+
+	for _, res := range r.report.Steps {
+		exitCode := ""
+		if res.ExitCode != nil {
+			exitCode = strconv.Itoa(*res.ExitCode)
+		}
+
+		var style []tablewriter.Colors
+		switch res.Status {
+		case lib.RecipeStepSucceeded:
+			style = []tablewriter.Colors{{tablewriter.FgGreenColor, tablewriter.Bold}}
+		case lib.RecipeStepFailed:
+			style = []tablewriter.Colors{{tablewriter.FgRedColor, tablewriter.Bold}}
+		default:
+			style = []tablewriter.Colors{{tablewriter.Bold}}
+		}
+
+		table.Rich([]string{
+			res.Id,
+			string(res.Kind),
+			string(res.Status),
+			exitCode,
+			(time.Duration(res.DurationMs) * time.Millisecond).String(),
+		}, style)
+	}
+
+	table.Render()
+	fmt.Println()
+
+	if recipeReportPath != "" {
+		fmt.Printf("📄 Report written to %s\n\n", recipeReportPath)
+	}
+}
+
+func tailOutput(output string) string {
+	if len(output) <= recipeMaxOutputBytes {
+		return output
+	}
+	return "…" + output[len(output)-recipeMaxOutputBytes:]
+}
@@ -284,28 +284,39 @@ func updateConfig(args []string, originalConfig *shared.PlanConfig) (string, *sh
 			cfgSetting.StringSetter(&config, selection)
 		}
 	} else {
-		if cfgSetting.BoolSetter != nil {
-			b, err := parseBooleanArg(value)
-			if err != nil {
-				term.OutputErrorAndExit("Invalid value for %s (%s)", cfgSetting.Name, value)
-				return "", nil
-			}
-			cfgSetting.BoolSetter(&config, b)
-		} else if cfgSetting.IntSetter != nil {
-			n, err := strconv.Atoi(value)
-			if err != nil {
-				term.OutputErrorAndExit("Invalid number value for %s (%s)", cfgSetting.Name, value)
-				return "", nil
-			}
-			cfgSetting.IntSetter(&config, n)
-		} else if cfgSetting.StringSetter != nil {
-			cfgSetting.StringSetter(&config, value)
+		err := setConfigValue(&config, cfgSetting, value)
+		if err != nil {
+			term.OutputErrorAndExit("%v", err)
+			return "", nil
 		}
 	}
 
 	return setting, &config
 }
 
+func setConfigValue(config *shared.PlanConfig, cfgSetting shared.ConfigSetting, value string) error {
+	if cfgSetting.BoolSetter != nil {
+		b, err := parseBooleanArg(value)
+		if err != nil {
+			return fmt.Errorf("Invalid value for %s (%s)", cfgSetting.Name, value)
+		}
+		cfgSetting.BoolSetter(config, b)
+	} else if cfgSetting.IntSetter != nil {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return fmt.Errorf("Invalid number value for %s (%s)", cfgSetting.Name, value)
+		}
+		cfgSetting.IntSetter(config, n)
+	} else if cfgSetting.StringSetter != nil {
+		// settings with a fixed set of plain choices only accept one of them
+		if cfgSetting.Choices != nil && !cfgSetting.HasCustomChoice && cfgSetting.ChoiceToKey == nil && !slices.Contains(*cfgSetting.Choices, value) {
+			return fmt.Errorf("Invalid value for %s (%s)—must be one of: %s", cfgSetting.Name, value, strings.Join(*cfgSetting.Choices, ", "))
+		}
+		cfgSetting.StringSetter(config, value)
+	}
+	return nil
+}
+
 func parseBooleanArg(value string) (bool, error) {
 	switch value {
 	case "enabled", "true", "t", "yes", "y", "1":
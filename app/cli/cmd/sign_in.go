@@ -8,6 +8,8 @@ import (
 )
 
 var pin string
+var signInSso bool
+var signInDevice bool
 
 var signInCmd = &cobra.Command{
 	Use:   "sign-in",
@@ -20,6 +22,8 @@ func init() {
 	RootCmd.AddCommand(signInCmd)
 
 	signInCmd.Flags().StringVar(&pin, "pin", "", "Sign in with a pin from the Plandex Cloud web UI")
+	signInCmd.Flags().BoolVar(&signInSso, "sso", false, "Sign in with your org's single sign-on provider (self-hosted)")
+	signInCmd.Flags().BoolVar(&signInDevice, "device", false, "With --sso, sign in with a device code instead of a browser redirect")
 }
 
 func signIn(cmd *cobra.Command, args []string) {
@@ -33,6 +37,16 @@ func signIn(cmd *cobra.Command, args []string) {
 		return
 	}
 
+	if signInSso || signInDevice {
+		err := auth.SignInWithSso(signInDevice)
+
+		if err != nil {
+			term.OutputErrorAndExit("Error signing in: %v", err)
+		}
+
+		return
+	}
+
 	err := auth.SelectOrSignInOrCreate()
 
 	if err != nil {
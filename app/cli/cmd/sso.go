@@ -0,0 +1,185 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var ssoIssuerUrl string
+var ssoClientId string
+var ssoClientSecret string
+var ssoScopes string
+var ssoRoleClaim string
+var ssoRoleMap []string
+
+var ssoCmd = &cobra.Command{
+	Use:   "sso",
+	Short: "Show the org's single sign-on config",
+	Run:   showSsoConfig,
+}
+
+var setSsoCmd = &cobra.Command{
+	Use:   "set",
+	Short: "Configure single sign-on with an OIDC provider",
+	Args:  cobra.NoArgs,
+	Run:   setSsoConfig,
+}
+
+var deleteSsoCmd = &cobra.Command{
+	Use:     "delete",
+	Aliases: []string{"rm"},
+	Short:   "Remove the org's single sign-on config",
+	Args:    cobra.NoArgs,
+	Run:     deleteSsoConfig,
+}
+
+func init() {
+	RootCmd.AddCommand(ssoCmd)
+	ssoCmd.AddCommand(setSsoCmd)
+	ssoCmd.AddCommand(deleteSsoCmd)
+
+	setSsoCmd.Flags().StringVar(&ssoIssuerUrl, "issuer", "", "The provider's issuer url")
+	setSsoCmd.Flags().StringVar(&ssoClientId, "client-id", "", "The client id registered with the provider")
+	setSsoCmd.Flags().StringVar(&ssoClientSecret, "client-secret", "", "The client secret (prompted for if omitted—leave empty for a public client)")
+	setSsoCmd.Flags().StringVar(&ssoScopes, "scopes", "", "Space-separated scopes to request (default \""+shared.SsoDefaultScopes+"\")")
+	setSsoCmd.Flags().StringVar(&ssoRoleClaim, "role-claim", "", "ID token claim to map to org roles, like groups (dots select nested claims)")
+	setSsoCmd.Flags().StringSliceVar(&ssoRoleMap, "role-map", nil, "Map claim values to org roles (comma-separated value=role pairs, like plandex-admins=admin)")
+
+	setSsoCmd.MarkFlagRequired("issuer")
+	setSsoCmd.MarkFlagRequired("client-id")
+}
+
+func showSsoConfig(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	config, apiErr := api.Client.GetSsoConfig()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting sso config: %v", apiErr.Msg)
+		return
+	}
+
+	if config == nil {
+		fmt.Println("🤷‍♂️ Single sign-on isn't configured")
+		fmt.Println()
+		term.PrintCmds("", "sso set")
+		return
+	}
+
+	clientSecret := "none (public client)"
+	if config.HasClientSecret {
+		clientSecret = "set"
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.Append([]string{"Domain", config.Domain})
+	table.Append([]string{"Issuer", config.IssuerUrl})
+	table.Append([]string{"Client Id", config.ClientId})
+	table.Append([]string{"Client Secret", clientSecret})
+	table.Append([]string{"Scopes", config.Scopes})
+	if config.RoleClaim != "" {
+		table.Append([]string{"Role Claim", config.RoleClaim})
+	}
+	table.Render()
+	fmt.Println()
+
+	if len(config.RoleMapping) > 0 {
+		var values []string
+		for value := range config.RoleMapping {
+			values = append(values, value)
+		}
+		sort.Strings(values)
+
+		table = tablewriter.NewWriter(os.Stdout)
+		table.SetAutoWrapText(false)
+		table.SetHeader([]string{"Claim Value", "Org Role"})
+		for _, value := range values {
+			table.Append([]string{value, config.RoleMapping[value]})
+		}
+		table.Render()
+		fmt.Println()
+	}
+
+	fmt.Printf("Users with an email on %s can sign in with %s\n", color.New(color.Bold).Sprint(config.Domain), color.New(color.Bold, term.ColorHiCyan).Sprint("plandex sign-in --sso"))
+	fmt.Println()
+	term.PrintCmds("", "sso set", "sso rm")
+}
+
+func setSsoConfig(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	req := shared.UpdateSsoConfigRequest{
+		IssuerUrl:    ssoIssuerUrl,
+		ClientId:     ssoClientId,
+		ClientSecret: ssoClientSecret,
+		Scopes:       ssoScopes,
+		RoleClaim:    ssoRoleClaim,
+	}
+
+	if len(ssoRoleMap) > 0 {
+		req.RoleMapping = shared.SsoRoleMapping{}
+		for _, pair := range ssoRoleMap {
+			value, role, ok := strings.Cut(pair, "=")
+			value = strings.TrimSpace(value)
+			role = strings.TrimSpace(role)
+			if !ok || value == "" || role == "" {
+				term.OutputErrorAndExit("Invalid --role-map entry %q—expected value=role", pair)
+			}
+			req.RoleMapping[value] = role
+		}
+	}
+
+	// prompt rather than requiring the secret on the command line, where it would end up in shell history
+	if !cmd.Flags().Changed("client-secret") {
+		secret, err := term.GetUserPasswordInput("Client secret (leave empty to keep the current secret, or for a public client):")
+		if err != nil {
+			term.OutputErrorAndExit("Error prompting client secret: %v", err)
+		}
+		req.ClientSecret = strings.TrimSpace(secret)
+	}
+
+	term.StartSpinner("")
+	config, apiErr := api.Client.UpdateSsoConfig(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error updating sso config: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Printf("✅ Single sign-on is configured for %s\n", color.New(color.Bold, term.ColorHiCyan).Sprint(config.Domain))
+	fmt.Println()
+	fmt.Println("Register this redirect uri with your provider, allowing any port for loopback redirects:")
+	fmt.Println("http://127.0.0.1/callback")
+	fmt.Println()
+	term.PrintCmds("", "sso")
+}
+
+func deleteSsoConfig(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteSsoConfig()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error deleting sso config: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Println("✅ Removed single sign-on config—members can still sign in with an email pin")
+}
@@ -62,6 +62,11 @@ func doTell(cmd *cobra.Command, args []string) {
 		}
 	}
 
+	runTell(prompt, apiKeys)
+}
+
+// runTell sends a prompt to the current plan using the exec flags, then applies the changes if --apply is set
+func runTell(prompt string, apiKeys map[string]string) {
 	tellFlags := types.TellFlags{
 		TellBg:                 tellBg,
 		TellStop:               tellStop,
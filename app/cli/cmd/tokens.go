@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+const defaultAccessTokenExpirationDays = 90
+
+var tokenPermissions []string
+var tokenExpiresInDays int
+
+var tokensCmd = &cobra.Command{
+	Use:   "tokens",
+	Short: "List your personal access tokens for the current org",
+	Run:   listAccessTokens,
+}
+
+var createTokenCmd = &cobra.Command{
+	Use:   "create <name>",
+	Short: "Create a personal access token for non-interactive auth with PLANDEX_TOKEN",
+	Args:  cobra.ExactArgs(1),
+	Run:   createAccessToken,
+}
+
+var revokeTokenCmd = &cobra.Command{
+	Use:     "revoke <name-id-or-index>",
+	Aliases: []string{"rm"},
+	Short:   "Revoke a personal access token",
+	Args:    cobra.ExactArgs(1),
+	Run:     revokeAccessToken,
+}
+
+func init() {
+	RootCmd.AddCommand(tokensCmd)
+	tokensCmd.AddCommand(createTokenCmd)
+	tokensCmd.AddCommand(revokeTokenCmd)
+
+	var permissionNames []string
+	for _, permission := range shared.AllPermissions {
+		permissionNames = append(permissionNames, string(permission))
+	}
+
+	createTokenCmd.Flags().StringSliceVarP(&tokenPermissions, "permissions", "p", nil, "Limit the token to these permissions (comma-separated, default all of yours): "+strings.Join(permissionNames, ", "))
+	createTokenCmd.Flags().IntVarP(&tokenExpiresInDays, "expires", "e", defaultAccessTokenExpirationDays, fmt.Sprintf("Days until the token expires (0 for never, max %d)", shared.MaxAccessTokenExpirationDays))
+}
+
+func listAccessTokens(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	tokens, apiErr := api.Client.ListAccessTokens()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching access tokens: %v", apiErr.Msg)
+		return
+	}
+
+	if len(tokens) == 0 {
+		fmt.Println("🤷‍♂️ No access tokens")
+		fmt.Println()
+		term.PrintCmds("", "tokens create")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Name", "Permissions", "Expires", "Last Used", "Created"})
+
+	for i, token := range tokens {
+		permissions := "all"
+		if len(token.Permissions) > 0 {
+			var names []string
+			for _, permission := range token.Permissions {
+				names = append(names, string(permission))
+			}
+			permissions = strings.Join(names, "\n")
+		}
+
+		expires := "never"
+		if token.ExpiresAt != nil {
+			expires = token.ExpiresAt.Local().Format("Jan 2, 2006")
+		}
+
+		lastUsed := "never"
+		if token.LastUsedAt != nil {
+			lastUsed = token.LastUsedAt.Local().Format("Jan 2 15:04")
+		}
+
+		table.Append([]string{strconv.Itoa(i + 1), token.Name, permissions, expires, lastUsed, token.CreatedAt.Local().Format("Jan 2, 2006")})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "tokens create", "tokens revoke")
+}
+
+func createAccessToken(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	if tokenExpiresInDays < 0 || tokenExpiresInDays > shared.MaxAccessTokenExpirationDays {
+		term.OutputErrorAndExit("--expires must be between 0 (never) and %d days", shared.MaxAccessTokenExpirationDays)
+	}
+
+	req := shared.CreateAccessTokenRequest{
+		Name:          args[0],
+		ExpiresInDays: tokenExpiresInDays,
+	}
+
+	for _, permission := range tokenPermissions {
+		permission := shared.Permission(strings.TrimSpace(permission))
+		if !shared.IsValidPermission(permission) {
+			term.OutputErrorAndExit("Invalid permission: %s", permission)
+		}
+		req.Permissions = append(req.Permissions, permission)
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.CreateAccessToken(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error creating access token: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Println("✅ Created access token", color.New(color.Bold, term.ColorHiCyan).Sprint(res.AccessToken.Name))
+	if res.AccessToken.ExpiresAt != nil {
+		fmt.Println("⏳ Expires " + res.AccessToken.ExpiresAt.Local().Format("Jan 2, 2006"))
+	}
+	fmt.Println()
+	fmt.Println("🔑 " + color.New(color.Bold).Sprint(res.Token))
+	fmt.Println()
+	fmt.Printf("Store the token somewhere safe—it won't be shown again. Set it as %s to authenticate without signing in, like in CI.\n", auth.TokenEnvVar)
+	fmt.Println()
+	term.PrintCmds("", "tokens", "tokens revoke")
+}
+
+func revokeAccessToken(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	tokens, apiErr := api.Client.ListAccessTokens()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching access tokens: %v", apiErr.Msg)
+	}
+
+	var token *shared.AccessToken
+	idx, err := strconv.Atoi(args[0])
+	if err == nil && idx > 0 && idx <= len(tokens) {
+		token = tokens[idx-1]
+	} else {
+		for _, t := range tokens {
+			if t.Id == args[0] || t.Name == args[0] {
+				token = t
+				break
+			}
+		}
+	}
+
+	if token == nil {
+		term.OutputErrorAndExit("Access token not found: %s", args[0])
+	}
+
+	term.StartSpinner("")
+	apiErr = api.Client.RevokeAccessToken(token.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error revoking access token: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Println("✅ Revoked access token", color.New(color.Bold, term.ColorHiCyan).Sprint(token.Name))
+}
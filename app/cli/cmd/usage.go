@@ -38,8 +38,11 @@ var creditsCurrentPlan bool
 
 var usageCmd = &cobra.Command{
 	Use:   "usage",
-	Short: "Display credits balance and usage report",
-	Run:   usage,
+	Short: "Display usage and spend report",
+	Long: `Display a usage and spend report.
+
+On Plandex Cloud, this shows your credits balance and spend. On self-hosted servers, it shows spend from the server's usage ledger, which records every model call and prices it with the model's list price.`,
+	Run: usage,
 }
 
 func init() {
@@ -52,11 +55,22 @@ func init() {
 	usageCmd.Flags().BoolVar(&logCreditsCreditsOnly, "purchases", false, "Show only purchases in the log")
 
 	usageCmd.Flags().BoolVar(&creditsToday, "today", false, "Show usage for today")
-	usageCmd.Flags().BoolVar(&creditsMonth, "month", false, "Show usage for current billing month")
+	usageCmd.Flags().BoolVar(&creditsMonth, "month", false, "Show usage for the current billing month (calendar month on self-hosted servers)")
 	usageCmd.Flags().BoolVar(&creditsCurrentPlan, "plan", false, "Show usage for the current plan")
 }
 
 func usage(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	if !auth.Current.IsCloud {
+		if showUsageLog {
+			showLocalLog(cmd, args)
+		} else {
+			showLocalUsage()
+		}
+		return
+	}
+
 	if showUsageLog {
 		showLog(cmd, args)
 	} else {
@@ -65,8 +79,6 @@ func usage(cmd *cobra.Command, args []string) {
 }
 
 func showUsage() {
-	auth.MustResolveAuthWithOrg()
-
 	term.StartSpinner("")
 
 	if !(creditsSession || creditsToday || creditsMonth || creditsCurrentPlan) {
@@ -234,8 +246,6 @@ func showUsage() {
 }
 
 func showLog(cmd *cobra.Command, args []string) {
-	auth.MustResolveAuthWithOrg()
-
 	if !(creditsSession || creditsToday || creditsMonth || creditsCurrentPlan) {
 		if os.Getenv("PLANDEX_REPL_SESSION_ID") != "" {
 			creditsSession = true
@@ -418,124 +428,125 @@ func showLog(cmd *cobra.Command, args []string) {
 
 	term.PageOutput(output)
 
-	var inputFn func()
-	inputFn = func() {
-		fmt.Println("\n" + pageLine)
+	if res.NumPages > 1 {
+		promptForLogPage(pageLine, res.NumPages, res.NumPagesMax, func() {
+			showLog(cmd, args)
+		})
+	}
+}
 
-		prompts := []string{}
+// promptForLogPage lets the user page through a log with more than one page, calling showPage after updating logCreditsPage
+func promptForLogPage(pageLine string, numPages int, numPagesMax bool, showPage func()) {
+	fmt.Println("\n" + pageLine)
 
-		if res.NumPages > 1 && logCreditsPage < res.NumPages {
-			prompts = append(prompts, "Press 'n' for next page")
-		}
+	prompts := []string{}
 
-		if logCreditsPage > 1 {
-			prompts = append(prompts, "Press 'p' for previous page")
-		}
+	if numPages > 1 && logCreditsPage < numPages {
+		prompts = append(prompts, "Press 'n' for next page")
+	}
 
-		prompts = append(prompts, "Type any number and press enter to jump to a page")
+	if logCreditsPage > 1 {
+		prompts = append(prompts, "Press 'p' for previous page")
+	}
 
-		prompts = append(prompts, "Press 'q' to quit")
+	prompts = append(prompts, "Type any number and press enter to jump to a page")
 
-		color.New(term.ColorHiMagenta, color.Bold).Println(strings.Join(prompts, "\n"))
-		color.New(term.ColorHiMagenta, color.Bold).Print("> ")
+	prompts = append(prompts, "Press 'q' to quit")
 
-		char, _, err := term.GetUserKeyInput()
+	color.New(term.ColorHiMagenta, color.Bold).Println(strings.Join(prompts, "\n"))
+	color.New(term.ColorHiMagenta, color.Bold).Print("> ")
 
-		if err != nil {
-			term.OutputErrorAndExit("Failed to get user input: %v", err)
-		}
+	char, _, err := term.GetUserKeyInput()
 
-		// Check if the input is a digit
-		if unicode.IsDigit(char) {
-			var numberInput strings.Builder
-			numberInput.WriteRune(char)
+	if err != nil {
+		term.OutputErrorAndExit("Failed to get user input: %v", err)
+	}
 
-			fmt.Print(string(char)) // Show the initial digit
+	// Check if the input is a digit
+	if unicode.IsDigit(char) {
+		var numberInput strings.Builder
+		numberInput.WriteRune(char)
 
-			for {
-				char, key, err := term.GetUserKeyInput()
-				if err != nil {
-					term.OutputErrorAndExit("Failed to get user input: %v", err)
-				}
+		fmt.Print(string(char)) // Show the initial digit
+
+		for {
+			char, key, err := term.GetUserKeyInput()
+			if err != nil {
+				term.OutputErrorAndExit("Failed to get user input: %v", err)
+			}
 
-				// If Enter is pressed, commit the input
-				if key == keyboard.KeyEnter {
-					pageNumber, err := strconv.Atoi(numberInput.String())
-					if err != nil {
-						fmt.Println("Invalid page number.")
-						return
-					}
-
-					// Check if the page number is valid
-					if pageNumber >= 1 && (pageNumber <= res.NumPages || res.NumPagesMax) {
-						logCreditsPage = pageNumber
-						showLog(cmd, args) // Re-run the log command with the new page
-					} else {
-						fmt.Println()
-						fmt.Println("Invalid page number.")
-						inputFn()
-					}
+			// If Enter is pressed, commit the input
+			if key == keyboard.KeyEnter {
+				pageNumber, err := strconv.Atoi(numberInput.String())
+				if err != nil {
+					fmt.Println("Invalid page number.")
 					return
 				}
 
-				// If another digit is pressed, add it to the input
-				if unicode.IsDigit(char) {
-					numberInput.WriteRune(char)
-					fmt.Print(string(char)) // Show the digit
-				} else if key == keyboard.KeyBackspace || key == keyboard.KeyBackspace2 {
-					// Handle backspace
-					if numberInput.Len() > 0 {
-						// Remove the last rune
-						input := numberInput.String()
-						numberInput.Reset()
-						numberInput.WriteString(input[:len(input)-1])
-						fmt.Print("\b \b") // Erase the digit
-					}
-
+				// Check if the page number is valid
+				if pageNumber >= 1 && (pageNumber <= numPages || numPagesMax) {
+					logCreditsPage = pageNumber
+					showPage() // Re-run the log command with the new page
 				} else {
-					// Handle invalid input while typing a number
 					fmt.Println()
-					fmt.Println("\nInvalid input. Please enter a valid page number.")
-					inputFn()
-					return
+					fmt.Println("Invalid page number.")
+					promptForLogPage(pageLine, numPages, numPagesMax, showPage)
 				}
+				return
 			}
-		}
 
-		// Handle non-digit hotkeys
-		fmt.Print(string(char))
-		switch char {
-		case 'n':
-			if logCreditsPage < res.NumPages || res.NumPagesMax {
-				logCreditsPage++
-				showLog(cmd, args)
-			} else {
-				fmt.Println()
-				fmt.Println("Already on last page.")
-				inputFn()
-			}
-		case 'p':
-			if logCreditsPage > 1 {
-				logCreditsPage--
-				showLog(cmd, args)
+			// If another digit is pressed, add it to the input
+			if unicode.IsDigit(char) {
+				numberInput.WriteRune(char)
+				fmt.Print(string(char)) // Show the digit
+			} else if key == keyboard.KeyBackspace || key == keyboard.KeyBackspace2 {
+				// Handle backspace
+				if numberInput.Len() > 0 {
+					// Remove the last rune
+					input := numberInput.String()
+					numberInput.Reset()
+					numberInput.WriteString(input[:len(input)-1])
+					fmt.Print("\b \b") // Erase the digit
+				}
+
 			} else {
+				// Handle invalid input while typing a number
 				fmt.Println()
-				fmt.Println("Already on first page.")
-				inputFn()
+				fmt.Println("\nInvalid input. Please enter a valid page number.")
+				promptForLogPage(pageLine, numPages, numPagesMax, showPage)
+				return
 			}
-		case 'q':
-			fmt.Println()
-			return
-		default:
-			fmt.Println()
-			fmt.Println("Invalid input.")
-			inputFn()
 		}
-
 	}
 
-	if res.NumPages > 1 {
-		inputFn()
+	// Handle non-digit hotkeys
+	fmt.Print(string(char))
+	switch char {
+	case 'n':
+		if logCreditsPage < numPages || numPagesMax {
+			logCreditsPage++
+			showPage()
+		} else {
+			fmt.Println()
+			fmt.Println("Already on last page.")
+			promptForLogPage(pageLine, numPages, numPagesMax, showPage)
+		}
+	case 'p':
+		if logCreditsPage > 1 {
+			logCreditsPage--
+			showPage()
+		} else {
+			fmt.Println()
+			fmt.Println("Already on first page.")
+			promptForLogPage(pageLine, numPages, numPagesMax, showPage)
+		}
+	case 'q':
+		fmt.Println()
+		return
+	default:
+		fmt.Println()
+		fmt.Println("Invalid input.")
+		promptForLogPage(pageLine, numPages, numPagesMax, showPage)
 	}
 }
 
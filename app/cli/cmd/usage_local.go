@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"sort"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+// self-hosted servers keep their own usage ledger, priced from each model's list price, in place of Plandex Cloud credits
+
+func showLocalUsage() {
+	req, planName := mustGetLocalUsageRequest()
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.GetUsageReport(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting usage report: %v", apiErr.Msg)
+	}
+
+	if res.Total.NumCalls == 0 {
+		fmt.Println(localUsageEmptyLabel(planName))
+		return
+	}
+
+	builder := strings.Builder{}
+
+	spendLbl := "💸 Spent"
+	if creditsSession {
+		spendLbl += " This Session"
+	} else if creditsToday {
+		spendLbl += " Today"
+	} else if creditsMonth {
+		spendLbl += " This Month"
+	} else if creditsCurrentPlan {
+		spendLbl += fmt.Sprintf(" On Plan 📋 %s", planName)
+	}
+
+	table := tablewriter.NewWriter(&builder)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{spendLbl, "🔁 Calls", "🪙 Input", "🪙 Output"})
+	table.Append(localUsageRow("", res.Total)[1:])
+	table.Render()
+	fmt.Fprintln(&builder)
+
+	if res.Total.NumUnpriced > 0 {
+		fmt.Fprintf(&builder, "⚠️  %d calls to models without pricing aren't included in spend (marked with *)\n\n", res.Total.NumUnpriced)
+	}
+
+	if !creditsCurrentPlan {
+		renderLocalUsageTable(&builder, "📋 Plan", res.ByPlanId, func(id string) string {
+			if name, ok := res.PlanNamesById[id]; ok {
+				return name
+			}
+			return "(deleted plan)"
+		}, false)
+	}
+
+	if res.OrgWide {
+		renderLocalUsageTable(&builder, "👤 User", res.ByUserId, func(id string) string {
+			if name, ok := res.UserNamesById[id]; ok {
+				return name
+			}
+			return "(removed user)"
+		}, false)
+	}
+
+	renderLocalUsageTable(&builder, "🤖 Model", res.ByModel, nil, false)
+
+	if len(res.ByDay) > 1 {
+		renderLocalUsageTable(&builder, "📅 Day", res.ByDay, nil, true)
+	}
+
+	term.PageOutput(builder.String())
+
+	term.PrintCmds("", "usage --log", "usage --month", "usage --plan")
+}
+
+func showLocalLog(cmd *cobra.Command, args []string) {
+	if logCreditsDebitsOnly || logCreditsCreditsOnly {
+		term.OutputErrorAndExit("--debits and --purchases are only available on Plandex Cloud")
+	}
+
+	req, planName := mustGetLocalUsageRequest()
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.GetUsageLog(logCreditsPageSize, logCreditsPage, req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting usage log: %v", apiErr.Msg)
+	}
+
+	if len(res.Usage) == 0 {
+		fmt.Println(localUsageEmptyLabel(planName))
+		return
+	}
+
+	tableString := &strings.Builder{}
+	table := tablewriter.NewWriter(tableString)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Cost", "Model Call"})
+
+	for _, usage := range res.Usage {
+		desc := usage.CreatedAt.Local().Format("2006-01-02 15:04:05.000 EST") + "\n"
+
+		if usage.PlanName != nil {
+			desc += fmt.Sprintf("Plan → %s", *usage.PlanName)
+			if usage.Branch != "" && usage.Branch != "main" {
+				desc += fmt.Sprintf(" (%s)", usage.Branch)
+			}
+			desc += "\n"
+		}
+		if usage.UserName != nil {
+			desc += fmt.Sprintf("👤 %s\n", *usage.UserName)
+		}
+
+		desc += fmt.Sprintf("⚡️ %s\n", usage.Purpose)
+		desc += fmt.Sprintf("🧠 %s (%s)\n", usage.ModelString(), usage.ModelRole)
+		desc += fmt.Sprintf("🪙 Used → %d input / %d output", usage.InputTokens, usage.OutputTokens)
+		if usage.CachedTokens > 0 {
+			desc += fmt.Sprintf(" / %d cached", usage.CachedTokens)
+		}
+		desc += "\n"
+
+		if usage.LatencyMs > 0 {
+			desc += fmt.Sprintf("⏱️  %s\n", (time.Duration(usage.LatencyMs) * time.Millisecond).Round(time.Millisecond*100))
+		}
+		if usage.HadError {
+			desc += "🚨 Stopped with an error\n"
+		} else if usage.StoppedEarly {
+			desc += "🛑 Stopped early\n"
+		}
+
+		costStr := "unpriced"
+		if usage.Cost != nil {
+			costStr = formatSpend(*usage.Cost)
+		}
+
+		table.Append([]string{
+			color.New(term.ColorHiRed).Sprint(costStr),
+			desc,
+		})
+	}
+
+	table.Render()
+
+	var output string
+	var pageLine string
+
+	if res.NumPages > 1 {
+		pageLine = fmt.Sprintf("Page size %d. Showing page %d of %d", logCreditsPageSize, logCreditsPage, res.NumPages)
+		output = pageLine + "\n\n" + tableString.String()
+	} else {
+		output = tableString.String()
+	}
+
+	term.PageOutput(output)
+
+	if res.NumPages > 1 {
+		promptForLogPage(pageLine, res.NumPages, false, func() {
+			showLocalLog(cmd, args)
+		})
+	}
+}
+
+func mustGetLocalUsageRequest() (shared.UsageRequest, string) {
+	if !(creditsSession || creditsToday || creditsMonth || creditsCurrentPlan) {
+		if os.Getenv("PLANDEX_REPL_SESSION_ID") != "" {
+			creditsSession = true
+		} else {
+			creditsToday = true
+		}
+	}
+
+	now := time.Now()
+	_, utcOffset := now.Zone()
+
+	req := shared.UsageRequest{UtcOffset: utcOffset}
+
+	if creditsSession {
+		req.SessionId = os.Getenv("PLANDEX_REPL_SESSION_ID")
+		if req.SessionId == "" {
+			term.OutputErrorAndExit("Session ID is not set. The --session flag should be used in the Plandex REPL.")
+		}
+	}
+
+	if creditsToday {
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		req.Since = &midnight
+	} else if creditsMonth {
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		req.Since = &monthStart
+	}
+
+	var planName string
+	if creditsCurrentPlan {
+		lib.MustResolveProject()
+		req.PlanId = lib.CurrentPlanId
+
+		term.StartSpinner("")
+		plan, apiErr := api.Client.GetPlan(req.PlanId)
+		term.StopSpinner()
+		if apiErr != nil {
+			term.OutputErrorAndExit("Error getting plan: %v", apiErr.Msg)
+		}
+		planName = plan.Name
+	}
+
+	return req, planName
+}
+
+func localUsageEmptyLabel(planName string) string {
+	if creditsSession {
+		return "🤷‍♂️ No usage so far this session"
+	} else if creditsToday {
+		tz, _ := time.Now().Zone()
+		return fmt.Sprintf("🤷‍♂️ No usage so far today (since midnight %s)", tz)
+	} else if creditsMonth {
+		return "🤷‍♂️ No usage so far this month"
+	} else if creditsCurrentPlan {
+		return "🤷‍♂️ No usage so far for current plan 👉 " + planName
+	}
+	return "🤷‍♂️ No usage"
+}
+
+// renderLocalUsageTable writes one row per key, sorted by spend (or by key for chronological tables). labelFn maps keys to display names when set.
+func renderLocalUsageTable(builder *strings.Builder, header string, summaries map[string]*shared.UsageSummary, labelFn func(string) string, sortByKey bool) {
+	if len(summaries) == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(summaries))
+	for key := range summaries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if sortByKey {
+			return keys[i] < keys[j]
+		}
+		a, b := summaries[keys[i]], summaries[keys[j]]
+		if !a.Spend.Equal(b.Spend) {
+			return a.Spend.GreaterThan(b.Spend)
+		}
+		return a.NumCalls > b.NumCalls
+	})
+
+	table := tablewriter.NewWriter(builder)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{header, "💸 Spent", "🔁 Calls", "🪙 Input", "🪙 Output"})
+
+	for _, key := range keys {
+		label := key
+		if labelFn != nil {
+			label = labelFn(key)
+		}
+		table.Append(localUsageRow(label, summaries[key]))
+	}
+
+	table.Render()
+	fmt.Fprintln(builder)
+}
+
+func localUsageRow(label string, summary *shared.UsageSummary) []string {
+	spend := formatSpend(summary.Spend)
+	if summary.NumUnpriced > 0 {
+		spend += "*"
+	}
+
+	input := fmt.Sprintf("%d", summary.InputTokens)
+	if summary.CachedTokens > 0 {
+		input += fmt.Sprintf(" (%d cached)", summary.CachedTokens)
+	}
+
+	return []string{label, spend, fmt.Sprintf("%d", summary.NumCalls), input, fmt.Sprintf("%d", summary.OutputTokens)}
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 
+	"plandex-cli/term"
 	"plandex-cli/version"
 
 	"github.com/spf13/cobra"
@@ -13,6 +14,9 @@ var versionCmd = &cobra.Command{
 	Short: "Print the version number of Plandex",
 	Long:  `All software has versions. This is Plandex's`,
 	Run: func(cmd *cobra.Command, args []string) {
+		if term.JsonMode {
+			term.SetJsonData("version", version.Version)
+		}
 		fmt.Println(version.Version)
 	},
 }
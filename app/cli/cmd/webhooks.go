@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/auth"
+	"plandex-cli/term"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"github.com/olekukonko/tablewriter"
+	"github.com/spf13/cobra"
+)
+
+var webhookEvents []string
+var webhookDescription string
+
+var webhooksCmd = &cobra.Command{
+	Use:   "webhooks",
+	Short: "List the org's webhooks",
+	Run:   listWebhooks,
+}
+
+var addWebhookCmd = &cobra.Command{
+	Use:   "add <url>",
+	Short: "Add a webhook that receives signed plan events",
+	Args:  cobra.ExactArgs(1),
+	Run:   addWebhook,
+}
+
+var deleteWebhookCmd = &cobra.Command{
+	Use:     "delete <id-or-index>",
+	Aliases: []string{"rm"},
+	Short:   "Delete a webhook",
+	Args:    cobra.ExactArgs(1),
+	Run:     deleteWebhook,
+}
+
+var webhookDeliveriesCmd = &cobra.Command{
+	Use:   "deliveries <id-or-index>",
+	Short: "Show a webhook's recent deliveries",
+	Args:  cobra.ExactArgs(1),
+	Run:   listWebhookDeliveries,
+}
+
+var testWebhookCmd = &cobra.Command{
+	Use:   "test <id-or-index>",
+	Short: "Send a ping event to a webhook",
+	Args:  cobra.ExactArgs(1),
+	Run:   testWebhook,
+}
+
+func init() {
+	RootCmd.AddCommand(webhooksCmd)
+	webhooksCmd.AddCommand(addWebhookCmd)
+	webhooksCmd.AddCommand(deleteWebhookCmd)
+	webhooksCmd.AddCommand(webhookDeliveriesCmd)
+	webhooksCmd.AddCommand(testWebhookCmd)
+
+	var eventNames []string
+	for _, event := range shared.WebhookEvents {
+		eventNames = append(eventNames, string(event))
+	}
+
+	addWebhookCmd.Flags().StringSliceVarP(&webhookEvents, "events", "e", nil, "Events to send (comma-separated, default all): "+strings.Join(eventNames, ", "))
+	addWebhookCmd.Flags().StringVarP(&webhookDescription, "description", "d", "", "Description of the webhook")
+}
+
+func listWebhooks(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	term.StartSpinner("")
+	webhooks, apiErr := api.Client.ListWebhooks()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching webhooks: %v", apiErr.Msg)
+		return
+	}
+
+	if len(webhooks) == 0 {
+		fmt.Println("🤷‍♂️ No webhooks")
+		fmt.Println()
+		term.PrintCmds("", "webhooks add")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "Id", "Url", "Events", "Description"})
+
+	for i, webhook := range webhooks {
+		var events []string
+		for _, event := range webhook.Events {
+			events = append(events, string(event))
+		}
+		table.Append([]string{strconv.Itoa(i + 1), webhook.Id, webhook.Url, strings.Join(events, "\n"), webhook.Description})
+	}
+
+	table.Render()
+	fmt.Println()
+	term.PrintCmds("", "webhooks add", "webhooks test", "webhooks deliveries", "webhooks rm")
+}
+
+func addWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	req := shared.CreateWebhookRequest{
+		Url:         args[0],
+		Description: webhookDescription,
+	}
+
+	if len(webhookEvents) == 0 {
+		req.Events = shared.WebhookEvents
+	} else {
+		for _, event := range webhookEvents {
+			event := shared.WebhookEvent(strings.TrimSpace(event))
+			if !shared.IsValidWebhookEvent(event) {
+				term.OutputErrorAndExit("Invalid event: %s", event)
+			}
+			req.Events = append(req.Events, event)
+		}
+	}
+
+	term.StartSpinner("")
+	res, apiErr := api.Client.CreateWebhook(req)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error adding webhook: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Println("✅ Added webhook", color.New(color.Bold, term.ColorHiCyan).Sprint(res.Webhook.Id))
+	fmt.Println()
+	fmt.Println("🔑 Signing secret: " + color.New(color.Bold).Sprint(res.Secret))
+	fmt.Println()
+	fmt.Println("Store the secret somewhere safe—it won't be shown again. Use it to verify the X-Plandex-Signature header on each request.")
+	fmt.Println()
+	term.PrintCmds("", "webhooks test", "webhooks")
+}
+
+func deleteWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	webhook := mustResolveWebhook(args[0])
+
+	term.StartSpinner("")
+	apiErr := api.Client.DeleteWebhook(webhook.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error deleting webhook: %v", apiErr.Msg)
+		return
+	}
+
+	fmt.Println("✅ Deleted webhook", color.New(color.Bold, term.ColorHiCyan).Sprint(webhook.Url))
+}
+
+func listWebhookDeliveries(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	webhook := mustResolveWebhook(args[0])
+
+	term.StartSpinner("")
+	deliveries, apiErr := api.Client.ListWebhookDeliveries(webhook.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching webhook deliveries: %v", apiErr.Msg)
+		return
+	}
+
+	if len(deliveries) == 0 {
+		fmt.Println("🤷‍♂️ No deliveries yet")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"Sent", "Event", "Status", "Attempts", "Response", "Error"})
+
+	for _, delivery := range deliveries {
+		table.Append(webhookDeliveryRow(delivery))
+	}
+
+	table.Render()
+}
+
+func testWebhook(cmd *cobra.Command, args []string) {
+	auth.MustResolveAuthWithOrg()
+
+	webhook := mustResolveWebhook(args[0])
+
+	term.StartSpinner("")
+	delivery, apiErr := api.Client.TestWebhook(webhook.Id)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error testing webhook: %v", apiErr.Msg)
+		return
+	}
+
+	if delivery.Status == shared.WebhookDeliveryStatusSucceeded {
+		fmt.Println("✅ Ping delivered to", color.New(color.Bold, term.ColorHiCyan).Sprint(webhook.Url))
+		return
+	}
+
+	msg := "unknown error"
+	if delivery.Error != nil {
+		msg = *delivery.Error
+	}
+	term.OutputErrorAndExit("Ping to %s failed: %s", webhook.Url, msg)
+}
+
+func mustResolveWebhook(idOrIndex string) *shared.Webhook {
+	term.StartSpinner("")
+	webhooks, apiErr := api.Client.ListWebhooks()
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error fetching webhooks: %v", apiErr.Msg)
+	}
+
+	idx, err := strconv.Atoi(idOrIndex)
+	if err == nil && idx > 0 && idx <= len(webhooks) {
+		return webhooks[idx-1]
+	}
+
+	for _, webhook := range webhooks {
+		if webhook.Id == idOrIndex {
+			return webhook
+		}
+	}
+
+	term.OutputErrorAndExit("Webhook not found: %s", idOrIndex)
+	return nil
+}
+
+func webhookDeliveryRow(delivery *shared.WebhookDelivery) []string {
+	var status string
+	switch delivery.Status {
+	case shared.WebhookDeliveryStatusSucceeded:
+		status = color.New(color.FgGreen).Sprint("succeeded")
+	case shared.WebhookDeliveryStatusFailed:
+		status = color.New(color.FgRed).Sprint("failed")
+	default:
+		status = string(delivery.Status)
+	}
+
+	response := ""
+	if delivery.ResponseStatus != nil {
+		response = strconv.Itoa(*delivery.ResponseStatus)
+	}
+
+	errMsg := ""
+	if delivery.Error != nil {
+		errMsg = *delivery.Error
+		if len(errMsg) > 60 {
+			errMsg = errMsg[:60] + "…"
+		}
+	}
+
+	return []string{
+		delivery.CreatedAt.Local().Format("Jan 2 15:04:05"),
+		string(delivery.Event),
+		status,
+		strconv.Itoa(delivery.NumAttempts),
+		response,
+		errMsg,
+	}
+}
@@ -58,6 +58,10 @@ func init() {
 	FindPlandexDir()
 	if PlandexDir != "" {
 		ProjectRoot = Cwd
+		err = LoadRoots()
+		if err != nil {
+			term.OutputErrorAndExit(err.Error())
+		}
 	}
 }
 
@@ -65,6 +69,10 @@ func FindOrCreatePlandex() (string, bool, error) {
 	FindPlandexDir()
 	if PlandexDir != "" {
 		ProjectRoot = Cwd
+		err := LoadRoots()
+		if err != nil {
+			return "", false, err
+		}
 		return PlandexDir, false, nil
 	}
 
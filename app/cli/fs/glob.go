@@ -0,0 +1,50 @@
+package fs
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IsGlobPattern returns true if a path has any glob wildcards
+func IsGlobPattern(p string) bool {
+	return strings.ContainsAny(p, "*?[")
+}
+
+// GlobMatch matches a project path against a glob pattern. '**' matches any number of directories, including none, and other segments use path.Match syntax, so 'src/**/*.go' matches both 'src/main.go' and 'src/lib/util.go'.
+func GlobMatch(pattern, p string) bool {
+	pattern = strings.TrimPrefix(filepath.ToSlash(pattern), "./")
+	p = filepath.ToSlash(p)
+	return globMatchSegments(strings.Split(pattern, "/"), strings.Split(p, "/"))
+}
+
+func globMatchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			rest := pattern[1:]
+			if len(rest) == 0 {
+				return len(segments) > 0
+			}
+			for i := 0; i <= len(segments); i++ {
+				if globMatchSegments(rest, segments[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+
+		matched, err := path.Match(pattern[0], segments[0])
+		if err != nil || !matched {
+			return false
+		}
+
+		pattern = pattern[1:]
+		segments = segments[1:]
+	}
+
+	return len(segments) == 0
+}
@@ -19,7 +19,25 @@ func GetProjectPaths(baseDir string) (*types.ProjectPaths, error) {
 		return nil, fmt.Errorf("no project root found")
 	}
 
-	return GetPaths(baseDir, ProjectRoot)
+	paths, err := GetPaths(baseDir, ProjectRoot)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, root := range Roots {
+		rootDir := RootDir(root)
+		if _, err := os.Stat(rootDir); err != nil {
+			return nil, fmt.Errorf("root %s (%s) can't be read—fix the path or remove it with 'plandex project rm-root %s': %v", root.Name, root.Path, root.Name, err)
+		}
+
+		rootPaths, err := GetPaths(rootDir, rootDir)
+		if err != nil {
+			return nil, fmt.Errorf("error getting paths for root %s: %v", root.Name, err)
+		}
+		mergeRootPaths(paths, root, rootPaths)
+	}
+
+	return paths, nil
 }
 
 func GetPaths(baseDir, currentDir string) (*types.ProjectPaths, error) {
@@ -50,6 +68,7 @@ func GetPaths(baseDir, currentDir string) (*types.ProjectPaths, error) {
 		numRoutines++
 		go func() {
 			cmd := exec.Command("git", "rev-parse", "--show-toplevel")
+			cmd.Dir = baseDir
 			output, err := cmd.Output()
 			if err != nil {
 				errCh <- fmt.Errorf("error getting git root: %s", err)
@@ -411,19 +430,11 @@ func GetBaseDirForFilePaths(paths []string) string {
 // Both 'parent' and 'child' can be absolute or relative to 'baseDir';
 // we’ll convert them to absolute paths based on 'baseDir' and then compare.
 func IsSubpathOf(parent, child, baseDir string) (bool, error) {
-	// Convert 'parent' -> absolute
-	absParent := parent
-	if !filepath.IsAbs(parent) {
-		absParent = filepath.Join(baseDir, parent)
-	}
-	absParent = filepath.Clean(absParent)
+	// Convert 'parent' -> absolute (namespaced paths resolve to their root)
+	absParent := absPathFrom(baseDir, parent)
 
 	// Convert 'child' -> absolute
-	absChild := child
-	if !filepath.IsAbs(child) {
-		absChild = filepath.Join(baseDir, child)
-	}
-	absChild = filepath.Clean(absChild)
+	absChild := absPathFrom(baseDir, child)
 
 	// filepath.Rel(absParent, absChild) will be something like:
 	//   - ".",  "foo",  "foo/bar", or ".." references
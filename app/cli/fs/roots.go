@@ -0,0 +1,331 @@
+package fs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"plandex-cli/types"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Roots are the project's additional roots, loaded from roots.json in the .plandex-v2 directory. Files in the main project root keep plain relative paths, while files in an additional root are namespaced with the root's name, like "web:src/app.ts".
+var Roots []*types.ProjectRoot
+
+// at least two characters so a root can't be mistaken for a windows drive letter
+var rootNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]+$`)
+
+func rootsPath() string {
+	return filepath.Join(PlandexDir, "roots.json")
+}
+
+func LoadRoots() error {
+	Roots = nil
+
+	if PlandexDir == "" {
+		return nil
+	}
+
+	bytes, err := os.ReadFile(rootsPath())
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("error reading roots.json: %v", err)
+	}
+
+	var config types.ProjectRootsConfig
+	err = json.Unmarshal(bytes, &config)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling roots.json: %v", err)
+	}
+
+	Roots = config.Roots
+	return nil
+}
+
+func saveRoots() error {
+	if len(Roots) == 0 {
+		err := os.Remove(rootsPath())
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("error removing roots.json: %v", err)
+		}
+		return nil
+	}
+
+	bytes, err := json.MarshalIndent(types.ProjectRootsConfig{Roots: Roots}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling roots: %v", err)
+	}
+
+	err = os.WriteFile(rootsPath(), bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing roots.json: %v", err)
+	}
+
+	return nil
+}
+
+// AddRoot adds a directory outside the main project root. dir can be absolute or relative to the current directory.
+func AddRoot(name, dir string) (*types.ProjectRoot, error) {
+	if ProjectRoot == "" {
+		return nil, fmt.Errorf("no project root found")
+	}
+
+	if !rootNameRegex.MatchString(name) {
+		return nil, fmt.Errorf("root name %q must start with a letter, be at least 2 characters, and only have letters, numbers, '-', and '_'", name)
+	}
+
+	if GetRoot(name) != nil {
+		return nil, fmt.Errorf("there's already a root named %s", name)
+	}
+
+	for _, root := range Roots {
+		if RootEnvVar(root.Name) == RootEnvVar(name) {
+			return nil, fmt.Errorf("root name %s is too similar to %s—they'd have the same %s environment variable", name, root.Name, RootEnvVar(name))
+		}
+	}
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error getting absolute path: %v", err)
+	}
+
+	info, err := os.Stat(absDir)
+	if err != nil {
+		return nil, fmt.Errorf("error checking %s: %v", dir, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("%s isn't a directory", dir)
+	}
+
+	// roots can't overlap, or a file would have two paths
+	existing := map[string]string{"the main project root": ProjectRoot}
+	for _, root := range Roots {
+		existing["root "+root.Name] = RootDir(root)
+	}
+	for label, existingDir := range existing {
+		inside, err := IsSubpathOf(existingDir, absDir, ProjectRoot)
+		if err != nil {
+			return nil, err
+		}
+		contains, err := IsSubpathOf(absDir, existingDir, ProjectRoot)
+		if err != nil {
+			return nil, err
+		}
+		if inside || contains {
+			return nil, fmt.Errorf("%s overlaps with %s", dir, label)
+		}
+	}
+
+	relDir, err := filepath.Rel(ProjectRoot, absDir)
+	if err != nil {
+		return nil, fmt.Errorf("error getting relative path: %v", err)
+	}
+
+	root := &types.ProjectRoot{Name: name, Path: relDir}
+	Roots = append(Roots, root)
+
+	err = saveRoots()
+	if err != nil {
+		return nil, err
+	}
+
+	return root, nil
+}
+
+func RemoveRoot(name string) error {
+	var updated []*types.ProjectRoot
+	found := false
+	for _, root := range Roots {
+		if root.Name == name {
+			found = true
+			continue
+		}
+		updated = append(updated, root)
+	}
+
+	if !found {
+		return fmt.Errorf("there's no root named %s", name)
+	}
+
+	Roots = updated
+	return saveRoots()
+}
+
+func GetRoot(name string) *types.ProjectRoot {
+	for _, root := range Roots {
+		if root.Name == name {
+			return root
+		}
+	}
+	return nil
+}
+
+// RootDir is the absolute directory of an additional root
+func RootDir(root *types.ProjectRoot) string {
+	if filepath.IsAbs(root.Path) {
+		return filepath.Clean(root.Path)
+	}
+	return filepath.Join(ProjectRoot, root.Path)
+}
+
+// RootEnvVar is the environment variable that has an additional root's absolute directory when _apply.sh runs, like PLANDEX_ROOT_WEB for a root named web
+func RootEnvVar(name string) string {
+	return "PLANDEX_ROOT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// RootsEnv sets each additional root's environment variable, for commands that run in the main project root but need to reach the other roots
+func RootsEnv() []string {
+	var env []string
+	for _, root := range Roots {
+		env = append(env, RootEnvVar(root.Name)+"="+RootDir(root))
+	}
+	return env
+}
+
+// SplitRootPath returns the root a namespaced path belongs to, along with the path relative to the root. For paths in the main project root, the root is nil and the path is returned unchanged.
+func SplitRootPath(path string) (*types.ProjectRoot, string) {
+	if len(Roots) == 0 {
+		return nil, path
+	}
+
+	name, rel, ok := strings.Cut(path, ":")
+	if !ok {
+		return nil, path
+	}
+
+	root := GetRoot(name)
+	if root == nil {
+		return nil, path
+	}
+
+	if rel == "" {
+		rel = "."
+	}
+
+	return root, filepath.Clean(rel)
+}
+
+func JoinRootPath(root *types.ProjectRoot, rel string) string {
+	if root == nil {
+		return rel
+	}
+	return root.Name + ":" + rel
+}
+
+// AbsPath resolves a project path (relative to the main project root, or namespaced with a root's name) to an absolute path
+func AbsPath(path string) string {
+	return absPathFrom(ProjectRoot, path)
+}
+
+func absPathFrom(baseDir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+
+	root, rel := SplitRootPath(path)
+	if root != nil {
+		return filepath.Join(RootDir(root), rel)
+	}
+
+	return filepath.Join(baseDir, path)
+}
+
+// ToRootPath converts a filesystem path (absolute, or relative to the current directory) to a namespaced path if it's inside one of the additional roots, and cleans up paths that are already namespaced. Other paths are returned unchanged.
+func ToRootPath(path string) string {
+	if len(Roots) == 0 {
+		return path
+	}
+
+	if root, rel := SplitRootPath(path); root != nil {
+		return JoinRootPath(root, rel)
+	}
+
+	absPath := path
+	if !filepath.IsAbs(path) {
+		absPath = filepath.Join(Cwd, path)
+	}
+
+	for _, root := range Roots {
+		rel, err := filepath.Rel(RootDir(root), absPath)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(os.PathSeparator)) {
+			continue
+		}
+		return JoinRootPath(root, rel)
+	}
+
+	return path
+}
+
+// GroupPathsByRoot splits project paths by root for per-repo operations like commits. Paths in the main project root are under the "" key, and all paths are relative to their root.
+func GroupPathsByRoot(paths []string) map[string][]string {
+	res := map[string][]string{}
+	for _, path := range paths {
+		root, rel := SplitRootPath(path)
+		name := ""
+		if root != nil {
+			name = root.Name
+		}
+		res[name] = append(res[name], rel)
+	}
+	for _, rels := range res {
+		sort.Strings(rels)
+	}
+	return res
+}
+
+// RootDirByName is the absolute directory for a GroupPathsByRoot key
+func RootDirByName(name string) string {
+	if name == "" {
+		return ProjectRoot
+	}
+	root := GetRoot(name)
+	if root == nil {
+		return ""
+	}
+	return RootDir(root)
+}
+
+// RootDirForPath is the absolute directory of the root that a project path belongs to
+func RootDirForPath(path string) string {
+	root, _ := SplitRootPath(path)
+	if root == nil {
+		return ProjectRoot
+	}
+	return RootDir(root)
+}
+
+// AnyRootIsGitRepo is true if the main project root or any additional root is in a git repo
+func AnyRootIsGitRepo() bool {
+	if ProjectRootIsGitRepo() {
+		return true
+	}
+	for _, root := range Roots {
+		if IsGitRepo(RootDir(root)) {
+			return true
+		}
+	}
+	return false
+}
+
+// adds an additional root's paths to the project's paths, namespaced with the root's name
+func mergeRootPaths(paths *types.ProjectPaths, root *types.ProjectRoot, rootPaths *types.ProjectPaths) {
+	prefixKeys := func(dst, src map[string]bool) {
+		for k, v := range src {
+			dst[JoinRootPath(root, k)] = v
+		}
+	}
+
+	prefixKeys(paths.ActivePaths, rootPaths.ActivePaths)
+	prefixKeys(paths.AllPaths, rootPaths.AllPaths)
+	prefixKeys(paths.ActiveDirs, rootPaths.ActiveDirs)
+	prefixKeys(paths.AllDirs, rootPaths.AllDirs)
+	prefixKeys(paths.GitIgnoredDirs, rootPaths.GitIgnoredDirs)
+
+	for k, v := range rootPaths.IgnoredPaths {
+		paths.IgnoredPaths[JoinRootPath(root, k)] = v
+	}
+}
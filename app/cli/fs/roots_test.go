@@ -0,0 +1,209 @@
+package fs
+
+import (
+	"os"
+	"path/filepath"
+	"plandex-cli/types"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// sets up a main project root at <tmp>/app with a web root at <tmp>/web and a lib root at <tmp>/lib, and restores the package state after the test
+func setupRoots(t *testing.T) string {
+	t.Helper()
+
+	prevCwd, prevPlandexDir, prevProjectRoot, prevRoots := Cwd, PlandexDir, ProjectRoot, Roots
+	t.Cleanup(func() {
+		Cwd, PlandexDir, ProjectRoot, Roots = prevCwd, prevPlandexDir, prevProjectRoot, prevRoots
+	})
+
+	base := t.TempDir()
+	for _, dir := range []string{"app/.plandex-v2", "app/src", "web/src", "lib", "other"} {
+		err := os.MkdirAll(filepath.Join(base, dir), 0755)
+		if err != nil {
+			t.Fatalf("error creating %s: %v", dir, err)
+		}
+	}
+
+	ProjectRoot = filepath.Join(base, "app")
+	PlandexDir = filepath.Join(ProjectRoot, ".plandex-v2")
+	Cwd = ProjectRoot
+	Roots = []*types.ProjectRoot{
+		{Name: "web", Path: "../web"},
+		{Name: "lib", Path: filepath.Join(base, "lib")},
+	}
+
+	return base
+}
+
+func TestSplitRootPath(t *testing.T) {
+	setupRoots(t)
+
+	tests := []struct {
+		path     string
+		wantRoot string
+		wantRel  string
+	}{
+		{"src/main.go", "", "src/main.go"},
+		{"web:src/app.ts", "web", "src/app.ts"},
+		{"web:src/../index.ts", "web", "index.ts"},
+		{"web:", "web", "."},
+		{"lib:util.go", "lib", "util.go"},
+		// unknown names and drive letters aren't roots
+		{"api:main.go", "", "api:main.go"},
+		{"C:main.go", "", "C:main.go"},
+		{"notes.txt:old", "", "notes.txt:old"},
+	}
+
+	for _, test := range tests {
+		root, rel := SplitRootPath(test.path)
+		name := ""
+		if root != nil {
+			name = root.Name
+		}
+		if name != test.wantRoot || rel != test.wantRel {
+			t.Errorf("SplitRootPath(%q) = (%q, %q), want (%q, %q)", test.path, name, rel, test.wantRoot, test.wantRel)
+		}
+	}
+
+	// without any roots, nothing is namespaced
+	Roots = nil
+	if root, rel := SplitRootPath("web:src/app.ts"); root != nil || rel != "web:src/app.ts" {
+		t.Errorf("expected no root without any roots, got (%v, %q)", root, rel)
+	}
+}
+
+func TestToRootPath(t *testing.T) {
+	base := setupRoots(t)
+
+	tests := []struct {
+		name string
+		path string
+		cwd  string
+		want string
+	}{
+		{name: "main root relative", path: "src/main.go", want: "src/main.go"},
+		{name: "already namespaced", path: "web:src/./app.ts", want: "web:src/app.ts"},
+		{name: "relative into a root", path: "../web/src/app.ts", want: "web:src/app.ts"},
+		{name: "absolute in a root", path: filepath.Join(base, "lib", "util.go"), want: "lib:util.go"},
+		{name: "root dir itself", path: filepath.Join(base, "web"), want: "web:."},
+		{name: "relative to another cwd", path: "src/app.ts", cwd: filepath.Join(base, "web"), want: "web:src/app.ts"},
+		{name: "outside every root", path: filepath.Join(base, "other", "x.go"), want: filepath.Join(base, "other", "x.go")},
+		// a sibling whose name starts with a root's name isn't in the root
+		{name: "prefix sibling", path: "../web2/x.go", want: "../web2/x.go"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Cwd = ProjectRoot
+			if test.cwd != "" {
+				Cwd = test.cwd
+			}
+			if got := ToRootPath(test.path); got != test.want {
+				t.Errorf("ToRootPath(%q) = %q, want %q", test.path, got, test.want)
+			}
+		})
+	}
+}
+
+func TestGroupPathsByRoot(t *testing.T) {
+	setupRoots(t)
+
+	got := GroupPathsByRoot([]string{
+		"web:src/b.ts",
+		"main.go",
+		"lib:util.go",
+		"web:src/a.ts",
+		"cmd/run.go",
+		"api:main.go",
+	})
+
+	want := map[string][]string{
+		"":    {"api:main.go", "cmd/run.go", "main.go"},
+		"web": {"src/a.ts", "src/b.ts"},
+		"lib": {"util.go"},
+	}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+}
+
+func TestAddRoot(t *testing.T) {
+	base := setupRoots(t)
+
+	for _, dir := range []string{"web/src/nested", "shared", "my-app", "my_app"} {
+		err := os.MkdirAll(filepath.Join(base, dir), 0755)
+		if err != nil {
+			t.Fatalf("error creating %s: %v", dir, err)
+		}
+	}
+	err := os.WriteFile(filepath.Join(base, "file.txt"), []byte("x"), 0644)
+	if err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		rootName string
+		dir      string
+		wantErr  string
+	}{
+		{name: "valid", rootName: "shared", dir: filepath.Join(base, "shared")},
+		{name: "duplicate name", rootName: "web", dir: filepath.Join(base, "other"), wantErr: "already a root named web"},
+		{name: "similar name", rootName: "my_app", dir: filepath.Join(base, "my_app"), wantErr: "PLANDEX_ROOT_MY_APP"},
+		{name: "single character name", rootName: "w", dir: filepath.Join(base, "other"), wantErr: "at least 2 characters"},
+		{name: "invalid name", rootName: "web:2", dir: filepath.Join(base, "other"), wantErr: "must start with a letter"},
+		{name: "missing dir", rootName: "missing", dir: filepath.Join(base, "missing"), wantErr: "error checking"},
+		{name: "file", rootName: "file", dir: filepath.Join(base, "file.txt"), wantErr: "isn't a directory"},
+		{name: "inside the main root", rootName: "src", dir: filepath.Join(base, "app", "src"), wantErr: "overlaps with the main project root"},
+		{name: "contains the main root", rootName: "base", dir: base, wantErr: "overlaps with"},
+		{name: "inside another root", rootName: "nested", dir: filepath.Join(base, "web", "src", "nested"), wantErr: "overlaps with root web"},
+		{name: "same dir as another root", rootName: "lib2", dir: filepath.Join(base, "lib"), wantErr: "overlaps with root lib"},
+	}
+
+	// added first so the similar name case has something to conflict with
+	_, err = AddRoot("my-app", filepath.Join(base, "my-app"))
+	if err != nil {
+		t.Fatalf("error adding my-app root: %v", err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			numRoots := len(Roots)
+
+			root, err := AddRoot(test.rootName, test.dir)
+			if test.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+					t.Errorf("expected error containing %q, got %v", test.wantErr, err)
+				}
+				if len(Roots) != numRoots {
+					t.Errorf("expected roots to be unchanged after an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if root.Path != filepath.Join("..", test.rootName) || RootDir(root) != test.dir {
+				t.Errorf("unexpected root: %+v", root)
+			}
+		})
+	}
+
+	// added roots are saved to roots.json
+	Roots = nil
+	err = LoadRoots()
+	if err != nil {
+		t.Fatalf("error loading roots: %v", err)
+	}
+	var names []string
+	for _, root := range Roots {
+		names = append(names, root.Name)
+	}
+	if strings.Join(names, ",") != "web,lib,my-app,shared" {
+		t.Errorf("unexpected saved roots: %v", names)
+	}
+}
@@ -18,6 +18,7 @@ require (
 	golang.org/x/sys v0.30.0
 	golang.org/x/term v0.19.0
 	gopkg.in/natefinch/lumberjack.v2 v2.2.1
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
@@ -32,6 +32,9 @@ type ApplyPlanParams struct {
 	TellFlags   types.TellFlags
 	OnExecFail  types.OnApplyExecFailFn
 	ExecCommand string
+
+	// called with the exit status and output whenever _apply.sh finishes running
+	OnExecResult func(status int, output string)
 }
 
 func MustApplyPlan(
@@ -75,6 +78,9 @@ func MustApplyPlanAttempt(
 
 		for _, b := range plansRunningRes.Branches {
 			if b.PlanId == planId && b.Name == branch {
+				if term.JsonMode {
+					term.OutputErrorAndExit("this plan is currently active—wait for it to finish before applying")
+				}
 				fmt.Println("This plan is currently active. Please wait for it to finish before applying.")
 				fmt.Println()
 				term.PrintCmds("", "ps", "connect")
@@ -87,10 +93,15 @@ func MustApplyPlanAttempt(
 		fmt.Println("This plan has changes that need to be built before applying")
 		fmt.Println()
 
-		shouldBuild, err := term.ConfirmYesNo("Build changes now?")
+		// with --json, applying implies building whatever is needed first
+		var err error
+		shouldBuild := term.JsonMode
+		if !shouldBuild {
+			shouldBuild, err = term.ConfirmYesNo("Build changes now?")
 
-		if err != nil {
-			term.OutputErrorAndExit("failed to get confirmation user input: %s", err)
+			if err != nil {
+				term.OutputErrorAndExit("failed to get confirmation user input: %s", err)
+			}
 		}
 
 		if !shouldBuild {
@@ -111,6 +122,14 @@ func MustApplyPlanAttempt(
 		term.OutputErrorAndExit("error getting project paths: %v", err)
 	}
 
+	// loaded before any files are written so the plan's own changes can't affect it
+	execPolicy, err := LoadProjectExecPolicy()
+
+	if err != nil {
+		term.OutputErrorAndExit("error loading exec policy: %v", err)
+	}
+	checkExecPolicy := execPolicyConfigured(execPolicy, currentPlanState.HasOrgExecPolicy)
+
 	anyOutdated, didUpdate, err := CheckOutdatedContextWithOutput(true, autoConfirm, nil, paths)
 
 	if err != nil {
@@ -126,7 +145,7 @@ func MustApplyPlanAttempt(
 	term.ResumeSpinner()
 
 	currentPlanFiles := currentPlanState.CurrentPlanFiles
-	isRepo := fs.ProjectRootIsGitRepo()
+	isRepo := fs.AnyRootIsGitRepo()
 
 	toApply := currentPlanFiles.Files
 	toRemove := currentPlanFiles.Removed
@@ -136,10 +155,18 @@ func MustApplyPlanAttempt(
 
 	if len(toApply) == 0 && !hasExec {
 		term.StopSpinner()
+		if term.JsonMode {
+			term.SetJsonData("updatedFiles", []string{})
+		}
 		fmt.Println("🤷‍♂️ No changes to apply")
 		return
 	}
 
+	// commands can't be confirmed with --json, so it has to be decided up front, before any files are written
+	if term.JsonMode && hasExec && !noExec && !applyFlags.AutoExec {
+		term.OutputJsonErrorAndExit(term.JsonErrorTypeUsage, "the plan has commands to execute—pass --auto-exec or --no-exec with --json")
+	}
+
 	hasFileChanges := !hasExec || len(toApply) > 1
 
 	var toRollback *types.ApplyRollbackPlan
@@ -208,6 +235,14 @@ func MustApplyPlanAttempt(
 			onErr("apply plan server error: %s", err)
 		}
 
+		if term.JsonMode {
+			files := updatedFiles
+			if files == nil {
+				files = []string{}
+			}
+			term.SetJsonData("updatedFiles", files)
+		}
+
 		if len(updatedFiles) == 0 {
 			term.StopSpinner()
 			fmt.Println("✅ Applied changes, but no files were updated")
@@ -224,12 +259,15 @@ func MustApplyPlanAttempt(
 				fmt.Println()
 			}
 
-			if isRepo && !noCommit {
+			// with --json, only commit when --commit is passed rather than prompting
+			if isRepo && !noCommit && (autoCommit || !term.JsonMode) {
 				term.StopSpinner()
 				gitErr := commitApplied(autoCommit, commitSummary, updatedFiles, currentPlanState)
 				appliedMsgFn()
 				if gitErr != nil {
 					onGitErr("Failed to commit changes:", gitErr.Error())
+				} else if term.JsonMode {
+					term.SetJsonData("committed", true)
 				}
 			} else {
 				term.StopSpinner()
@@ -239,7 +277,7 @@ func MustApplyPlanAttempt(
 	}
 
 	if _, ok := toApply["_apply.sh"]; ok && !noExec {
-		handleApplyScript(params, toApply, onErr, toRollback, onExecFail, attempt, onExecSuccess)
+		handleApplyScript(params, toApply, execPolicy, checkExecPolicy, onErr, toRollback, onExecFail, attempt, onExecSuccess)
 	} else {
 		onExecSuccess()
 	}
@@ -248,6 +286,8 @@ func MustApplyPlanAttempt(
 func handleApplyScript(
 	params ApplyPlanParams,
 	toApply map[string]string,
+	execPolicy *shared.ExecPolicy,
+	checkExecPolicy bool,
 	onErr types.OnErrFn,
 	toRollback *types.ApplyRollbackPlan,
 	onExecFail types.OnApplyExecFailFn,
@@ -275,13 +315,60 @@ func handleApplyScript(
 
 	fmt.Println(strings.TrimSpace(md))
 
+	// commands passed in by the user (like with 'plandex debug') aren't checked, and without a policy there's nothing to check them against
+	var policyViolated bool
+	if params.ExecCommand == "" && checkExecPolicy {
+		log.Println("Checking apply script against exec policy")
+
+		term.StartSpinner("")
+		res, err := CheckExecPolicy(content, execPolicy)
+		term.StopSpinner()
+
+		if err != nil {
+			onErr("failed to check exec policy: %s", err)
+		}
+
+		if len(res.Violations) > 0 {
+			fmt.Println()
+			PrintExecPolicyViolations(res)
+
+			onViolation := res.OnViolation
+			// there's no one to ask with --json
+			if onViolation == shared.ExecPolicyOnViolationPrompt && term.JsonMode {
+				onViolation = shared.ExecPolicyOnViolationBlock
+			}
+
+			switch onViolation {
+			case shared.ExecPolicyOnViolationBlock:
+				if term.JsonMode {
+					if toRollback != nil && toRollback.HasChanges() {
+						Rollback(toRollback, true)
+					}
+					term.OutputErrorAndExit("commands were blocked by the exec policy—changes were rolled back")
+				}
+				color.New(term.ColorHiRed, color.Bold).Println("Commands blocked by the exec policy")
+				skipApplyScript(toRollback, onErr, onSuccess)
+				return
+			case shared.ExecPolicyOnViolationDebug:
+				onExecFail(-1, execPolicyDebugOutput(res), attempt, toRollback, onErr, onSuccess)
+				return
+			default:
+				policyViolated = true
+			}
+		}
+	}
+
 	log.Println("Asking user to confirm executing apply script")
 
 	var confirmed bool
-	if params.ApplyFlags.AutoExec {
+	if params.ApplyFlags.AutoExec && !policyViolated {
 		confirmed = true
 	} else {
-		confirmed, err = term.ConfirmYesNo("Execute now?")
+		prompt := "Execute now?"
+		if policyViolated {
+			prompt = "Execute anyway?"
+		}
+		confirmed, err = term.ConfirmYesNo(prompt)
 		if err != nil {
 			onErr("failed to get confirmation user input: %s", err)
 		}
@@ -291,20 +378,24 @@ func handleApplyScript(
 		log.Println("Executing apply script")
 		execApplyScript(params, toApply, onErr, toRollback, onExecFail, attempt, onSuccess)
 	} else {
-		if toRollback != nil && toRollback.HasChanges() {
-			res, err := term.SelectFromList("Skipping execution. Apply file changes or roll back?", []string{string(types.ApplyRollbackOptionKeep), string(types.ApplyRollbackOptionRollback)})
+		skipApplyScript(toRollback, onErr, onSuccess)
+	}
+}
 
-			if err != nil {
-				onErr("failed to get rollback confirmation user input: %s", err)
-			}
+func skipApplyScript(toRollback *types.ApplyRollbackPlan, onErr types.OnErrFn, onSuccess func()) {
+	if toRollback != nil && toRollback.HasChanges() {
+		res, err := term.SelectFromList("Skipping execution. Apply file changes or roll back?", []string{string(types.ApplyRollbackOptionKeep), string(types.ApplyRollbackOptionRollback)})
 
-			if res == string(types.ApplyRollbackOptionRollback) {
-				Rollback(toRollback, true)
-				fmt.Println()
-				os.Exit(0)
-			} else {
-				onSuccess()
-			}
+		if err != nil {
+			onErr("failed to get rollback confirmation user input: %s", err)
+		}
+
+		if res == string(types.ApplyRollbackOptionRollback) {
+			Rollback(toRollback, true)
+			fmt.Println()
+			os.Exit(0)
+		} else {
+			onSuccess()
 		}
 	}
 }
@@ -332,7 +423,18 @@ func execApplyScript(
 ) {
 	log.Println("Executing apply script")
 
+	term.StartSpinner("")
+	config, apiErr := api.Client.GetPlanConfig(params.PlanId)
+	term.StopSpinner()
+	if apiErr != nil {
+		onErr("failed to get plan config: %s", apiErr.Msg)
+	}
+	runner := GetApplyScriptRunner(config)
+
 	color.New(term.ColorHiCyan, color.Bold).Println("🚀 Executing... Output below:")
+	if description := runner.Description(); description != "" {
+		color.New(term.ColorHiCyan).Println(description)
+	}
 	fmt.Println()
 
 	var content string
@@ -379,6 +481,9 @@ func execApplyScript(
 	}
 
 	header := shebang + "\n" + errorHandling
+	if runnerHeader := runner.ScriptHeader(); runnerHeader != "" {
+		header += "\n" + runnerHeader
+	}
 	content = header + "\n" + strings.Join(filteredLines, "\n")
 	err := os.WriteFile(dstPath, []byte(content), 0755)
 
@@ -386,9 +491,11 @@ func execApplyScript(
 		onErr("failed to write _apply.sh: %s", err)
 	}
 
-	execCmd := exec.Command(shell, "-l", dstPath)
-	execCmd.Dir = fs.ProjectRoot
-	execCmd.Env = os.Environ()
+	execCmd, err := runner.Command(shell, dstPath)
+	if err != nil {
+		os.Remove(dstPath)
+		onErr("failed to set up command: %s", err)
+	}
 	execCmd.Stdin = os.Stdin
 
 	// Create a pipe for both stdout and stderr
@@ -411,6 +518,18 @@ func execApplyScript(
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// Kill the commands if they run past the runner's time limit
+	var timedOut atomic.Bool
+	var timeoutTimer *time.Timer
+	if timeout := runner.Timeout(); timeout > 0 {
+		timeoutTimer = time.AfterFunc(timeout, func() {
+			timedOut.Store(true)
+			if err := KillProcessGroup(execCmd, syscall.SIGKILL); err != nil {
+				log.Printf("Failed to kill timed out process group: %v", err)
+			}
+		})
+	}
+
 	// Use atomic variable to prevent data races
 	var interrupted atomic.Bool
 
@@ -475,6 +594,18 @@ func execApplyScript(
 
 	err = execCmd.Wait()
 
+	if timeoutTimer != nil {
+		timeoutTimer.Stop()
+	}
+
+	// included in the output so debugging knows why the commands stopped
+	if timedOut.Load() {
+		msg := fmt.Sprintf("Commands timed out after %s and were killed", runner.Timeout())
+		fmt.Println()
+		color.New(term.ColorHiYellow, color.Bold).Println("👉 " + msg)
+		outputBuilder.WriteString(msg + "\n")
+	}
+
 	// Ensure interrupt handler fully completes before proceeding
 	cancel()           // cancel the context, if not already
 	interruptWG.Wait() // wait until the interrupt handler goroutine finishes
@@ -513,15 +644,22 @@ func execApplyScript(
 		}
 	}
 
+	status := 0
+	if !success {
+		status = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			status = exitErr.ExitCode()
+		}
+	}
+
+	if params.OnExecResult != nil {
+		params.OnExecResult(status, outputBuilder.String())
+	}
+
 	if !success {
 		fmt.Println()
 		color.New(term.ColorHiRed, color.Bold).Println("🚨 Commands failed")
 
-		exitErr, ok := err.(*exec.ExitError)
-		status := -1
-		if ok {
-			status = exitErr.ExitCode()
-		}
 		onExecFail(status, outputBuilder.String(), attempt, toRollback, onErr, onSuccess)
 	} else {
 		fmt.Println()
@@ -579,7 +717,7 @@ func commitApplied(autoCommit bool, commitSummary string, updatedFiles []string,
 		// log.Println("Committing changes with message:")
 		// log.Println(msg)
 		// spew.Dump(currentPlanState)
-		err = GitAddAndCommitPaths(fs.ProjectRoot, msg, updatedFiles, true)
+		err = GitAddAndCommitProjectPaths(msg, updatedFiles)
 		if err != nil {
 			return fmt.Errorf("failed to commit changes: %s", err.Error())
 		}
@@ -604,7 +742,7 @@ func ApplyFiles(toApply map[string]string, toRemove map[string]bool, projectPath
 		}
 		go func(path, content string) {
 			// Compute destination path
-			dstPath := filepath.Join(fs.ProjectRoot, path)
+			dstPath := fs.AbsPath(path)
 			content = strings.ReplaceAll(content, "\\`\\`\\`", "```")
 			// Check if the file exists
 			var exists bool
@@ -669,7 +807,7 @@ func ApplyFiles(toApply map[string]string, toRemove map[string]bool, projectPath
 				return
 			}
 			// Compute destination path
-			dstPath := filepath.Join(fs.ProjectRoot, path)
+			dstPath := fs.AbsPath(path)
 			// Check if the file exists
 			var exists bool
 			var mode os.FileMode
@@ -755,7 +893,7 @@ func Rollback(rollbackPlan *types.ApplyRollbackPlan, msg bool) error {
 		pathsErrCh := make(chan error, len(toRemove))
 		for _, path := range toRemove {
 			go func(path string) {
-				err := os.Remove(path)
+				err := os.Remove(fs.AbsPath(path))
 				pathsErrCh <- err
 			}(path)
 		}
@@ -7,6 +7,7 @@ import (
 	"log"
 	"os"
 	"plandex-cli/api"
+	"plandex-cli/fs"
 	"plandex-cli/types"
 	shared "plandex-shared"
 	"sync"
@@ -44,7 +45,7 @@ func AutoLoadContextFiles(ctx context.Context, files []string) (string, error) {
 		}
 
 		go func(index int, path string) {
-			fileInfo, err := os.Stat(path)
+			fileInfo, err := os.Stat(fs.AbsPath(path))
 			if err != nil {
 				errCh <- fmt.Errorf("failed to get file info for %s: %v", path, err)
 				return
@@ -76,7 +77,7 @@ func AutoLoadContextFiles(ctx context.Context, files []string) (string, error) {
 			totalSize += size
 			mu.Unlock()
 
-			b, err := os.ReadFile(path)
+			b, err := os.ReadFile(fs.AbsPath(path))
 			if err != nil {
 				errCh <- fmt.Errorf("failed to read file %s: %v", path, err)
 				return
@@ -159,7 +160,12 @@ func AutoLoadContextFiles(ctx context.Context, files []string) (string, error) {
 }
 
 func MustLoadAutoContextMap() {
-	MustLoadContext([]string{"."}, &types.LoadContextParams{
+	paths := []string{"."}
+	for _, root := range fs.Roots {
+		paths = append(paths, fs.JoinRootPath(root, "."))
+	}
+
+	MustLoadContext(paths, &types.LoadContextParams{
 		DefsOnly:          true,
 		SkipIgnoreWarning: true,
 		AutoLoaded:        true,
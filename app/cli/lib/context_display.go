@@ -27,6 +27,18 @@ func GetContextLabelAndIcon(contextType shared.ContextType) (string, string) {
 	case shared.ContextMapType:
 		icon = "🗺️ "
 		lbl = "map"
+	case shared.ContextSymbolType:
+		icon = "🧩"
+		lbl = "symbol"
+	case shared.ContextGitDiffType:
+		icon = "🔀"
+		lbl = "diff"
+	case shared.ContextGitRefDiffType:
+		icon = "🔀"
+		lbl = "ref diff"
+	case shared.ContextGitCommitsType:
+		icon = "🔀"
+		lbl = "commits"
 	}
 
 	return lbl, icon
@@ -0,0 +1,109 @@
+package lib
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+// GitContextInput describes a git diff to load into context -- the diff is re-computed from these whenever context is checked for updates
+type GitContextInput struct {
+	ContextType shared.ContextType
+	Ref         string
+	Staged      bool
+}
+
+// ParseGitContextInput maps load flags to a git context: a range like main..HEAD loads the commits in the range, a single ref loads
+// the diff of the working tree against it, and no ref loads the uncommitted (or with staged, only the staged) changes
+func ParseGitContextInput(ref string, staged bool) (*GitContextInput, error) {
+	ref = strings.TrimSpace(ref)
+
+	if ref == "" {
+		return &GitContextInput{ContextType: shared.ContextGitDiffType, Staged: staged}, nil
+	}
+
+	if strings.HasPrefix(ref, "-") {
+		return nil, fmt.Errorf("invalid git ref: %s", ref)
+	}
+
+	if staged {
+		return nil, fmt.Errorf("--staged can't be combined with a ref")
+	}
+
+	if strings.Contains(ref, "..") {
+		return &GitContextInput{ContextType: shared.ContextGitCommitsType, Ref: ref}, nil
+	}
+
+	return &GitContextInput{ContextType: shared.ContextGitRefDiffType, Ref: ref}, nil
+}
+
+func gitContextInputFor(context *shared.Context) *GitContextInput {
+	return &GitContextInput{
+		ContextType: context.ContextType,
+		Ref:         context.GitRef,
+		Staged:      context.GitStaged,
+	}
+}
+
+func (input *GitContextInput) Name() string {
+	switch input.ContextType {
+	case shared.ContextGitCommitsType:
+		return "git log " + input.Ref
+	case shared.ContextGitRefDiffType:
+		return "git diff " + input.Ref
+	default:
+		if input.Staged {
+			return "git diff --staged"
+		}
+		return "git diff HEAD"
+	}
+}
+
+func (input *GitContextInput) args() ([]string, error) {
+	// refs are stored with the context, so check them again before passing them to git
+	if strings.HasPrefix(input.Ref, "-") {
+		return nil, fmt.Errorf("invalid git ref: %s", input.Ref)
+	}
+
+	var cmd string
+	var rest []string
+	switch input.ContextType {
+	case shared.ContextGitCommitsType:
+		cmd = "log"
+		rest = []string{"--patch", input.Ref}
+	case shared.ContextGitRefDiffType:
+		cmd = "diff"
+		rest = []string{input.Ref}
+	case shared.ContextGitDiffType:
+		cmd = "diff"
+		if input.Staged {
+			rest = []string{"--staged"}
+		} else {
+			rest = []string{"HEAD"}
+		}
+	default:
+		return nil, fmt.Errorf("not a git context type: %s", input.ContextType)
+	}
+
+	args := []string{"--no-pager", cmd, "--no-color", "--no-ext-diff"}
+	args = append(args, rest...)
+	// end with -- so refs are never read as paths
+	return append(args, "--"), nil
+}
+
+// Body runs git for the input and returns its output
+func (input *GitContextInput) Body() (string, error) {
+	args, err := input.args()
+	if err != nil {
+		return "", err
+	}
+
+	res, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error running '%s': %v, output: %s", input.Name(), err, string(res))
+	}
+
+	return string(res), nil
+}
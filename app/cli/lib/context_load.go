@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"path/filepath"
 	"plandex-cli/api"
 	"plandex-cli/auth"
 	"plandex-cli/fs"
@@ -23,10 +22,16 @@ import (
 
 const maxSkippedFileList = 20
 
+type symbolInput struct {
+	path   string
+	symbol string
+}
+
 func MustLoadContext(resources []string, params *types.LoadContextParams) {
 	if params.DefsOnly {
 		// while caching is set up to work with multiple map paths, it can end up in a partially loaded state if token limits are exceeded, so better to just load one at a time
-		if len(resources) > 1 {
+		// the auto-loaded map is the exception, with a map for each project root
+		if len(resources) > 1 && !params.AutoLoaded {
 			term.OutputErrorAndExit("Please load a single map directory at a time")
 		}
 
@@ -96,6 +101,7 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 
 	var inputUrls []string
 	var inputFilePaths []string
+	var inputSymbols []symbolInput
 
 	if len(resources) > 0 {
 		for _, resource := range resources {
@@ -107,7 +113,26 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 					resource = resource[2:]
 				}
 
-				inputFilePaths = append(inputFilePaths, resource)
+				// paths inside an additional project root are namespaced with the root's name
+				resource = fs.ToRootPath(resource)
+
+				// path/to/file.go#Symbol selects a single definition, unless a file with that exact name exists
+				path, symbol, hasSymbol := strings.Cut(resource, "#")
+				if hasSymbol && symbol != "" {
+					if _, err := os.Stat(fs.AbsPath(resource)); err == nil {
+						hasSymbol = false
+					}
+				}
+
+				if hasSymbol && symbol != "" {
+					inputSymbols = append(inputSymbols, symbolInput{path: path, symbol: symbol})
+				} else if len(params.Symbols) > 0 {
+					for _, symbol := range params.Symbols {
+						inputSymbols = append(inputSymbols, symbolInput{path: resource, symbol: symbol})
+					}
+				} else {
+					inputFilePaths = append(inputFilePaths, resource)
+				}
 			}
 		}
 	}
@@ -142,6 +167,46 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 			existsByComposite[strings.Join([]string{string(context.ContextType), context.FilePath}, "|")] = context
 		case shared.ContextURLType:
 			existsByComposite[strings.Join([]string{string(context.ContextType), context.Url}, "|")] = context
+		case shared.ContextSymbolType, shared.ContextGitDiffType, shared.ContextGitRefDiffType, shared.ContextGitCommitsType:
+			existsByComposite[strings.Join([]string{string(context.ContextType), context.Name}, "|")] = context
+		}
+	}
+
+	if params.GitDiffRef != "" || params.GitStaged || params.GitUncommitted {
+		input, err := ParseGitContextInput(params.GitDiffRef, params.GitStaged)
+		if err != nil {
+			onErr(err)
+		}
+
+		body, err := input.Body()
+		if err != nil {
+			onErr(err)
+		}
+
+		if strings.TrimSpace(body) == "" {
+			term.StopSpinner()
+			fmt.Printf("🤷‍♂️ No changes in %s\n", input.Name())
+			os.Exit(0)
+		}
+
+		size := int64(len(body))
+		if size > shared.MaxContextBodySize {
+			onErr(fmt.Errorf("%s is too large to load (%d bytes, limit is %d)", input.Name(), size, shared.MaxContextBodySize))
+		}
+		totalSize += size
+
+		composite := strings.Join([]string{string(input.ContextType), input.Name()}, "|")
+		if existsByComposite[composite] != nil {
+			alreadyLoadedByComposite[composite] = existsByComposite[composite]
+		} else {
+			loadContextReq = append(loadContextReq, &shared.LoadContextParams{
+				ContextType: input.ContextType,
+				Name:        input.Name(),
+				Body:        body,
+				GitRef:      input.Ref,
+				GitStaged:   input.Staged,
+				AutoLoaded:  params.AutoLoaded,
+			})
 		}
 	}
 
@@ -220,6 +285,12 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 			if !params.ForceSkipIgnore {
 				var filteredPaths []string
 				for _, inputFilePath := range inputFilePaths {
+					// globs are matched against active paths in ParseInputPaths
+					if fs.IsGlobPattern(inputFilePath) {
+						filteredPaths = append(filteredPaths, inputFilePath)
+						continue
+					}
+
 					if _, ok := paths.ActivePaths[inputFilePath]; !ok {
 						ignored, reason, err := fs.IsIgnored(paths, inputFilePath, baseDir)
 						if err != nil {
@@ -410,14 +481,8 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 					var mapInputPath string
 					if params.DefsOnly {
 						for _, inputPath := range toLoadMapPaths {
-							absPath, err := filepath.Abs(path)
-							if err != nil {
-								continue
-							}
-							absInputPath, err := filepath.Abs(inputPath)
-							if err != nil {
-								continue
-							}
+							absPath := fs.AbsPath(path)
+							absInputPath := fs.AbsPath(inputPath)
 							if absPath == absInputPath ||
 								strings.HasPrefix(absPath+string(os.PathSeparator), absInputPath+string(os.PathSeparator)) {
 								mapInputPath = inputPath
@@ -458,7 +523,7 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 
 						var size int64
 
-						fileInfo, err := os.Stat(path)
+						fileInfo, err := os.Stat(fs.AbsPath(path))
 						if err != nil {
 							errCh <- fmt.Errorf("failed to get file info for %s: %v", path, err)
 							return
@@ -515,7 +580,7 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 							}
 
 						} else if isImage {
-							fileContent, err := os.ReadFile(path)
+							fileContent, err := os.ReadFile(fs.AbsPath(path))
 							if err != nil {
 								errCh <- fmt.Errorf("failed to read the file %s: %v", path, err)
 								return
@@ -533,7 +598,7 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 								AutoLoaded:  params.AutoLoaded,
 							})
 						} else {
-							fileContent, err := os.ReadFile(path)
+							fileContent, err := os.ReadFile(fs.AbsPath(path))
 							if err != nil {
 								errCh <- fmt.Errorf("failed to read the file %s: %v", path, err)
 								return
@@ -614,6 +679,74 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 		}
 	}
 
+	for _, input := range inputSymbols {
+		name := input.path + "#" + input.symbol
+		composite := strings.Join([]string{string(shared.ContextSymbolType), name}, "|")
+		if existsByComposite[composite] != nil {
+			alreadyLoadedByComposite[composite] = existsByComposite[composite]
+			continue
+		}
+
+		numRoutines++
+		go func(input symbolInput, name string) {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			fileInfo, err := os.Stat(fs.AbsPath(input.path))
+			if err != nil {
+				errCh <- fmt.Errorf("failed to get file info for %s: %v", input.path, err)
+				return
+			}
+			if fileInfo.IsDir() {
+				errCh <- fmt.Errorf("%s is a directory—symbols can only be loaded from a file", input.path)
+				return
+			}
+			if !shared.HasFileMapSupport(input.path) {
+				errCh <- fmt.Errorf("symbols can't be loaded from %s—the file type isn't supported", input.path)
+				return
+			}
+
+			// the whole file is sent so the server can extract the symbol, so it counts toward the size limits like a file
+			size := fileInfo.Size()
+
+			contextMu.Lock()
+			if size > shared.MaxContextBodySize {
+				filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: name, Size: size})
+				contextMu.Unlock()
+				errCh <- nil
+				return
+			}
+			if totalSize+size > shared.MaxContextBodySize {
+				filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, name)
+				contextMu.Unlock()
+				errCh <- nil
+				return
+			}
+			totalSize += size
+			contextMu.Unlock()
+
+			fileContent, err := os.ReadFile(fs.AbsPath(input.path))
+			if err != nil {
+				errCh <- fmt.Errorf("failed to read the file %s: %v", input.path, err)
+				return
+			}
+
+			contextMu.Lock()
+			defer contextMu.Unlock()
+
+			loadContextReq = append(loadContextReq, &shared.LoadContextParams{
+				ContextType: shared.ContextSymbolType,
+				Name:        name,
+				Body:        string(fileContent),
+				FilePath:    input.path,
+				Symbol:      input.symbol,
+				AutoLoaded:  params.AutoLoaded,
+			})
+
+			errCh <- nil
+		}(input, name)
+	}
+
 	for i := 0; i < numRoutines; i++ {
 		err := <-errCh
 		if err != nil {
@@ -633,6 +766,8 @@ func MustLoadContext(resources []string, params *types.LoadContextParams) {
 				name = "cwd"
 			} else if inputPath == ".." {
 				name = "parent"
+			} else if root, rel := fs.SplitRootPath(inputPath); root != nil && rel == "." {
+				name = root.Name
 			} else {
 				name = inputPath
 			}
@@ -25,6 +25,15 @@ func ParseInputPaths(params ParseInputPathsParams) ([]string, error) {
 		// see if it's a child of any of the fileOrDirPaths
 		found := false
 		for _, p := range fileOrDirPaths {
+			// globs only match files, so 'src/**' loads the files under src without requiring --recursive
+			if fs.IsGlobPattern(p) {
+				found = !projectPaths.AllDirs[path] && fs.GlobMatch(p, path)
+				if found {
+					break
+				}
+				continue
+			}
+
 			var err error
 			found, err = fs.IsSubpathOf(p, path, baseDir)
 			if err != nil {
@@ -0,0 +1,77 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/term"
+	"plandex-cli/types"
+	"strconv"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/olekukonko/tablewriter"
+)
+
+// MustSearchAndLoadContext ranks the project map's files by semantic similarity to the query and loads the top matches.
+// If the plan doesn't have a project map yet, one is loaded first since the search index is built from it.
+func MustSearchAndLoadContext(query string, limit int, params *types.LoadContextParams) {
+	term.StartSpinner("")
+	contexts, apiErr := api.Client.ListContext(CurrentPlanId, CurrentBranch)
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error listing context: %v", apiErr.Msg)
+	}
+
+	hasMap := false
+	for _, context := range contexts {
+		if context.ContextType == shared.ContextMapType {
+			hasMap = true
+			break
+		}
+	}
+
+	if !hasMap {
+		fmt.Println("🗺️  Search uses the project map, which isn't loaded yet")
+		MustLoadAutoContextMap()
+		fmt.Println()
+	}
+
+	term.LongSpinnerWithWarning("🔎 Searching project...", "🔎 Indexing the project for search—this can take a while the first time...")
+	res, apiErr := api.Client.SearchContext(CurrentPlanId, CurrentBranch, shared.SearchContextRequest{
+		Query: query,
+		Limit: limit,
+	})
+	term.StopSpinner()
+
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error searching project: %v", apiErr.Msg)
+	}
+
+	if len(res.Results) == 0 {
+		fmt.Println("🤷‍♂️ No files matched the search")
+		return
+	}
+
+	table := tablewriter.NewWriter(os.Stdout)
+	table.SetAutoWrapText(false)
+	table.SetHeader([]string{"#", "File", "Score", "Matching Definitions"})
+
+	var paths []string
+	for i, result := range res.Results {
+		paths = append(paths, result.Path)
+		table.Append([]string{
+			strconv.Itoa(i + 1),
+			result.Path,
+			fmt.Sprintf("%.2f", result.Score),
+			strings.Join(result.Definitions, "\n"),
+		})
+	}
+
+	table.Render()
+	fmt.Println()
+
+	MustLoadContext(paths, params)
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"os"
 	"plandex-cli/api"
+	"plandex-cli/fs"
 	"strings"
 	"sync"
 
@@ -108,7 +109,7 @@ type mapFileContent struct {
 }
 
 func getMapFileContent(path string) (mapFileContent, error) {
-	f, err := os.Open(path)
+	f, err := os.Open(fs.AbsPath(path))
 	if err != nil {
 		return mapFileContent{}, err
 	}
@@ -175,7 +176,7 @@ func processMapBatches(mapInputBatches []shared.FileMapInputs) (shared.FileMapBo
 }
 
 func readImageTokensForDefsOnly(path string, size int64, detail openai.ImageURLDetail, headerBytes int64) (int, error) {
-	file, err := os.Open(path)
+	file, err := os.Open(fs.AbsPath(path))
 	if err != nil {
 		return 0, fmt.Errorf("failed to open file %s: %w", path, err)
 	}
@@ -89,6 +89,14 @@ func CheckOutdatedContextWithOutput(quiet, autoConfirm bool, maybeContexts []*sh
 			lbl = strconv.Itoa(outdatedRes.NumMaps) + " " + lbl
 			types = append(types, lbl)
 		}
+		if outdatedRes.NumDiffs > 0 {
+			lbl := "git diff"
+			if outdatedRes.NumDiffs > 1 {
+				lbl = "git diffs"
+			}
+			lbl = strconv.Itoa(outdatedRes.NumDiffs) + " " + lbl
+			types = append(types, lbl)
+		}
 
 		var msg string
 		if len(types) <= 2 {
@@ -330,6 +338,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 	var numUrls int
 	var numTrees int
 	var numMaps int
+	var numDiffs int
 	var numFilesRemoved int
 	var numTreesRemoved int
 	var mu sync.Mutex
@@ -371,7 +380,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 				sem <- struct{}{}
 				defer func() { <-sem }()
 
-				if _, err := os.Stat(ctx.FilePath); os.IsNotExist(err) {
+				if _, err := os.Stat(fs.AbsPath(ctx.FilePath)); os.IsNotExist(err) {
 					mu.Lock()
 					defer mu.Unlock()
 
@@ -381,14 +390,14 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 					return
 				}
 
-				fileContent, err := os.ReadFile(ctx.FilePath)
+				fileContent, err := os.ReadFile(fs.AbsPath(ctx.FilePath))
 				if err != nil {
 					mu.Lock()
 					defer mu.Unlock()
 					errs = append(errs, fmt.Errorf("failed to read the file %s: %v", ctx.FilePath, err))
 					return
 				}
-				fileInfo, err := os.Stat(ctx.FilePath)
+				fileInfo, err := os.Stat(fs.AbsPath(ctx.FilePath))
 				if err != nil {
 					mu.Lock()
 					defer mu.Unlock()
@@ -472,6 +481,65 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 				}
 			}(context)
 
+		case shared.ContextSymbolType:
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				if _, err := os.Stat(fs.AbsPath(ctx.FilePath)); os.IsNotExist(err) {
+					mu.Lock()
+					defer mu.Unlock()
+
+					deleteIds[ctx.Id] = true
+					numFilesRemoved++
+					tokenDiffsById[ctx.Id] = -ctx.NumTokens
+					return
+				}
+
+				fileContent, err := os.ReadFile(fs.AbsPath(ctx.FilePath))
+				if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, fmt.Errorf("failed to read the file %s: %v", ctx.FilePath, err))
+					return
+				}
+				size := int64(len(fileContent))
+
+				// the sha is for the whole file, so any change to the file sends it to the server to re-extract the symbol, which finds it again if it moved
+				hash := sha256.Sum256(fileContent)
+				sha := hex.EncodeToString(hash[:])
+
+				if sha == ctx.Sha {
+					return
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if size > shared.MaxContextBodySize {
+					filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: ctx.Name, Size: size})
+					return
+				}
+				if totalSize+size > shared.MaxContextBodySize {
+					filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.Name)
+					return
+				}
+				totalSize += size
+
+				// the new slice isn't known until the server extracts it, so the token diff is reported after the update
+				tokenDiffsById[ctx.Id] = 0
+				numFiles++
+				updatedContexts = append(updatedContexts, ctx)
+
+				reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+					return &shared.UpdateContextParams{
+						Body: string(fileContent),
+					}, nil
+				}
+			}(context)
+
 		case shared.ContextDirectoryTreeType:
 			wg.Add(1)
 			go func(ctx *shared.Context) {
@@ -479,7 +547,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 				sem <- struct{}{}
 				defer func() { <-sem }()
 
-				if _, err := os.Stat(ctx.FilePath); os.IsNotExist(err) {
+				if _, err := os.Stat(fs.AbsPath(ctx.FilePath)); os.IsNotExist(err) {
 					mu.Lock()
 					deleteIds[ctx.Id] = true
 					numTreesRemoved++
@@ -657,7 +725,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 						mu.Unlock()
 
 						if !(hasFileInfo || removed) {
-							fileInfo, err := os.Stat(path)
+							fileInfo, err := os.Stat(fs.AbsPath(path))
 							if err != nil {
 								if os.IsNotExist(err) {
 									removed = true
@@ -841,6 +909,56 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 
 			}(context)
 
+		case shared.ContextGitDiffType, shared.ContextGitRefDiffType, shared.ContextGitCommitsType:
+			wg.Add(1)
+			go func(ctx *shared.Context) {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+
+				// diffs are re-computed on every check so they follow new commits and working tree changes
+				body, err := gitContextInputFor(ctx).Body()
+				if err != nil {
+					mu.Lock()
+					defer mu.Unlock()
+					errs = append(errs, err)
+					return
+				}
+
+				hash := sha256.Sum256([]byte(body))
+				newSha := hex.EncodeToString(hash[:])
+				if newSha == ctx.Sha {
+					return
+				}
+
+				size := int64(len(body))
+
+				mu.Lock()
+				defer mu.Unlock()
+
+				if size > shared.MaxContextBodySize {
+					filesSkippedTooLarge = append(filesSkippedTooLarge, filePathWithSize{Path: ctx.Name, Size: size})
+					return
+				}
+				oldBodySize := int64(len(ctx.Body))
+				if totalSize+size > shared.MaxContextBodySize || totalBodySize+(size-oldBodySize) > shared.MaxContextBodySize {
+					filesSkippedAfterSizeLimit = append(filesSkippedAfterSizeLimit, ctx.Name)
+					return
+				}
+
+				totalSize += size
+				totalBodySize += (size - oldBodySize)
+
+				tokenDiffsById[ctx.Id] = shared.GetNumTokensEstimate(body) - ctx.NumTokens
+				numDiffs++
+				updatedContexts = append(updatedContexts, ctx)
+				reqFns[ctx.Id] = func() (*shared.UpdateContextParams, error) {
+					return &shared.UpdateContextParams{
+						Body: body,
+					}, nil
+				}
+			}(context)
+
 		case shared.ContextURLType:
 			wg.Add(1)
 			go func(ctx *shared.Context) {
@@ -966,6 +1084,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 		NumUrls:         numUrls,
 		NumTrees:        numTrees,
 		NumMaps:         numMaps,
+		NumDiffs:        numDiffs,
 		NumFilesRemoved: numFilesRemoved,
 		NumTreesRemoved: numTreesRemoved,
 		ReqFn:           reqFn,
@@ -996,6 +1115,7 @@ func checkOutdatedAndMaybeUpdateContext(doUpdate bool, maybeContexts []*shared.C
 			NumTrees:    numTrees,
 			NumUrls:     numUrls,
 			NumMaps:     numMaps,
+			NumDiffs:    numDiffs,
 			TokensDiff:  tokensDiff,
 			TotalTokens: newTotal,
 		})
@@ -0,0 +1,122 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"plandex-cli/api"
+	"plandex-cli/fs"
+	"plandex-cli/term"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+	"gopkg.in/yaml.v3"
+)
+
+func ProjectExecPolicyPath() string {
+	return filepath.Join(fs.PlandexDir, "exec-policy.yml")
+}
+
+// LoadProjectExecPolicy loads the project's exec policy file, or returns nil if there isn't one
+func LoadProjectExecPolicy() (*shared.ExecPolicy, error) {
+	if fs.PlandexDir == "" {
+		return nil, nil
+	}
+
+	path := ProjectExecPolicyPath()
+	policy, err := ReadExecPolicyFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// ReadExecPolicyFile reads and validates a YAML exec policy
+func ReadExecPolicyFile(path string) (*shared.ExecPolicy, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var policy shared.ExecPolicy
+
+	// unknown keys are errors so a typo can't silently loosen the policy
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&policy)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	err = policy.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid exec policy in %s: %v", path, err)
+	}
+
+	return &policy, nil
+}
+
+// commands only need to be checked if the project or org has a policy that restricts them
+func execPolicyConfigured(projectPolicy *shared.ExecPolicy, hasOrgPolicy bool) bool {
+	return hasOrgPolicy || (projectPolicy != nil && !projectPolicy.IsEmpty())
+}
+
+func CheckExecPolicy(script string, projectPolicy *shared.ExecPolicy) (*shared.CheckExecPolicyResponse, error) {
+	res, apiErr := api.Client.CheckExecPolicy(shared.CheckExecPolicyRequest{
+		Script:        script,
+		ProjectPolicy: projectPolicy,
+	})
+	if apiErr != nil {
+		return nil, fmt.Errorf("%s", apiErr.Msg)
+	}
+	return res, nil
+}
+
+func PrintExecPolicyViolations(res *shared.CheckExecPolicyResponse) {
+	color.New(term.ColorHiRed, color.Bold).Println("🚫 Commands violate the exec policy")
+	fmt.Println()
+	for _, v := range res.Violations {
+		fmt.Println(" • " + formatExecPolicyViolation(v))
+	}
+	fmt.Println()
+}
+
+func formatExecPolicyViolation(v *shared.ExecPolicyViolation) string {
+	var location []string
+	if v.Line > 0 {
+		location = append(location, fmt.Sprintf("line %d", v.Line))
+	}
+	if v.Source != "" {
+		location = append(location, string(v.Source)+" policy")
+	}
+
+	res := fmt.Sprintf("%s %s", color.New(color.Bold).Sprint(v.Command), v.Reason)
+	if len(location) > 0 {
+		res += fmt.Sprintf(" (%s)", strings.Join(location, ", "))
+	}
+	return res
+}
+
+// the output sent to the model when violations are debugged, in place of the commands' output
+func execPolicyDebugOutput(res *shared.CheckExecPolicyResponse) string {
+	var sb strings.Builder
+	sb.WriteString("The commands in _apply.sh were not run because they violate the project's exec policy:\n\n")
+	for _, v := range res.Violations {
+		line := ""
+		if v.Line > 0 {
+			line = fmt.Sprintf(" (line %d)", v.Line)
+		}
+		sb.WriteString(fmt.Sprintf("- `%s`%s %s\n", v.Command, line, v.Reason))
+	}
+	sb.WriteString("\nUpdate _apply.sh so that every command complies with the policy, or remove commands that can't be made to comply.\n")
+	return sb.String()
+}
@@ -0,0 +1,69 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"plandex-cli/fs"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestExecPolicyConfigured(t *testing.T) {
+	tests := []struct {
+		name          string
+		projectPolicy *shared.ExecPolicy
+		hasOrgPolicy  bool
+		want          bool
+	}{
+		{name: "no policies", want: false},
+		{name: "org policy only", hasOrgPolicy: true, want: true},
+		{name: "project policy", projectPolicy: &shared.ExecPolicy{Deny: []string{"rm -rf *"}}, want: true},
+		{name: "project category", projectPolicy: &shared.ExecPolicy{DenyNetwork: true}, want: true},
+		// a policy that only sets onViolation doesn't restrict anything
+		{name: "empty project policy", projectPolicy: &shared.ExecPolicy{OnViolation: shared.ExecPolicyOnViolationBlock}, want: false},
+	}
+
+	for _, test := range tests {
+		if got := execPolicyConfigured(test.projectPolicy, test.hasOrgPolicy); got != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.want)
+		}
+	}
+}
+
+func TestLoadProjectExecPolicy(t *testing.T) {
+	prevPlandexDir := fs.PlandexDir
+	t.Cleanup(func() {
+		fs.PlandexDir = prevPlandexDir
+	})
+	fs.PlandexDir = t.TempDir()
+
+	policy, err := LoadProjectExecPolicy()
+	if err != nil || policy != nil {
+		t.Fatalf("expected no policy without a file, got %v, %v", policy, err)
+	}
+
+	write := func(content string) {
+		t.Helper()
+		err := os.WriteFile(filepath.Join(fs.PlandexDir, "exec-policy.yml"), []byte(content), 0644)
+		if err != nil {
+			t.Fatalf("error writing policy: %v", err)
+		}
+	}
+
+	write("onViolation: debug\ndeny:\n  - \"docker *\"\n")
+	policy, err = LoadProjectExecPolicy()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if policy.GetOnViolation() != shared.ExecPolicyOnViolationDebug || len(policy.Deny) != 1 {
+		t.Errorf("unexpected policy: %+v", policy)
+	}
+
+	// unknown keys are errors so a typo can't loosen the policy
+	write("denyNetwrok: true\n")
+	_, err = LoadProjectExecPolicy()
+	if err == nil {
+		t.Error("expected an error for an unknown key")
+	}
+}
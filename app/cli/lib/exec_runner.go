@@ -0,0 +1,256 @@
+package lib
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/auth"
+	"plandex-cli/fs"
+	"runtime"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// ApplyScriptRunner is the backend that runs _apply.sh. The host runner executes the script directly, while the sandbox runner isolates it with Linux namespaces.
+type ApplyScriptRunner interface {
+	// Description is shown before the script runs, or empty for nothing
+	Description() string
+
+	// ScriptHeader is added to the script after the shebang and error handling
+	ScriptHeader() string
+
+	// Command builds the command that runs the script with the given shell
+	Command(shell, scriptPath string) (*exec.Cmd, error)
+
+	// Timeout is how long the script can run before it's killed, or 0 for no limit
+	Timeout() time.Duration
+}
+
+func GetApplyScriptRunner(config *shared.PlanConfig) ApplyScriptRunner {
+	if config != nil && config.GetExecBackend() == shared.ExecBackendSandbox {
+		return &sandboxRunner{
+			network:    config.SandboxNetwork,
+			timeout:    time.Duration(config.SandboxTimeout) * time.Second,
+			cpuSeconds: config.SandboxCpuSeconds,
+			memoryMb:   config.SandboxMemoryMb,
+		}
+	}
+	return &hostRunner{}
+}
+
+type hostRunner struct{}
+
+func (r *hostRunner) Description() string {
+	return ""
+}
+
+func (r *hostRunner) ScriptHeader() string {
+	return ""
+}
+
+func (r *hostRunner) Command(shell, scriptPath string) (*exec.Cmd, error) {
+	cmd := exec.Command(shell, "-l", scriptPath)
+	cmd.Dir = fs.ProjectRoot
+	cmd.Env = append(os.Environ(), fs.RootsEnv()...)
+	return cmd, nil
+}
+
+func (r *hostRunner) Timeout() time.Duration {
+	return 0
+}
+
+type sandboxRunner struct {
+	network    bool
+	timeout    time.Duration
+	cpuSeconds int
+	memoryMb   int
+}
+
+func (r *sandboxRunner) Description() string {
+	var parts []string
+	if r.network {
+		parts = append(parts, "network allowed")
+	} else {
+		parts = append(parts, "no network")
+	}
+	if r.timeout > 0 {
+		parts = append(parts, fmt.Sprintf("%s timeout", r.timeout))
+	}
+	if r.cpuSeconds > 0 {
+		parts = append(parts, fmt.Sprintf("%ds CPU", r.cpuSeconds))
+	}
+	if r.memoryMb > 0 {
+		parts = append(parts, fmt.Sprintf("%d MB memory", r.memoryMb))
+	}
+	return fmt.Sprintf("🔒 Sandboxed: read-only system, writable project, %s", strings.Join(parts, ", "))
+}
+
+// ulimit applies to the script's shell and everything it starts
+func (r *sandboxRunner) ScriptHeader() string {
+	var lines []string
+	if r.cpuSeconds > 0 {
+		lines = append(lines, fmt.Sprintf("ulimit -t %d", r.cpuSeconds))
+	}
+	if r.memoryMb > 0 {
+		lines = append(lines, fmt.Sprintf("ulimit -v %d", r.memoryMb*1024))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (r *sandboxRunner) Timeout() time.Duration {
+	return r.timeout
+}
+
+func (r *sandboxRunner) Command(shell, scriptPath string) (*exec.Cmd, error) {
+	if runtime.GOOS != "linux" {
+		return nil, fmt.Errorf("the sandbox exec backend is only available on Linux—use 'plandex set-config exec-backend host' to run commands directly")
+	}
+
+	// the project root and any additional roots are the only writable dirs
+	writableDirs := []string{fs.ProjectRoot}
+	for _, root := range fs.Roots {
+		writableDirs = append(writableDirs, fs.RootDir(root))
+	}
+
+	hiddenDirs := sandboxHiddenDirs(writableDirs)
+
+	var args []string
+
+	if bwrap, err := lookPath("bwrap"); err == nil {
+		args = []string{
+			bwrap,
+			"--ro-bind", "/", "/",
+			"--dev", "/dev",
+			"--proc", "/proc",
+			"--tmpfs", "/tmp",
+		}
+		// after the /tmp tmpfs so a project under /tmp stays visible
+		for _, dir := range writableDirs {
+			args = append(args, "--bind", dir, dir)
+		}
+		// after the writable dirs so a project that contains one, like a project in the home dir, doesn't uncover it
+		for _, dir := range hiddenDirs {
+			args = append(args, "--tmpfs", dir)
+		}
+		args = append(args, "--unshare-all")
+		if r.network {
+			args = append(args, "--share-net")
+		}
+		args = append(args, "--die-with-parent", "--chdir", fs.ProjectRoot, "--")
+	} else if unshare, err := lookPath("unshare"); err == nil {
+		args = []string{
+			unshare,
+			"--user", "--map-root-user",
+			"--mount", "--pid", "--fork", "--kill-child", "--mount-proc",
+		}
+		if !r.network {
+			args = append(args, "--net")
+		}
+		// unshare can't give the script a private /tmp without hiding a project under /tmp, so /tmp stays writable
+		args = append(args, "sh", "-c", unshareSandboxScript, "sh", "/tmp")
+		args = append(args, writableDirs...)
+		args = append(args, "--")
+		args = append(args, hiddenDirs...)
+		args = append(args, "--")
+	} else {
+		return nil, fmt.Errorf("the sandbox exec backend needs bubblewrap (bwrap) or unshare—install bubblewrap or use 'plandex set-config exec-backend host' to run commands directly")
+	}
+
+	args = append(args, shell, "-l", scriptPath)
+
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Dir = fs.ProjectRoot
+	cmd.Env = append(sandboxEnv(os.Environ()), fs.RootsEnv()...)
+	return cmd, nil
+}
+
+// overridden in tests
+var lookPath = exec.LookPath
+
+// credential dirs in the home directory that the sandbox covers with an empty tmpfs, along with Plandex's own home dir
+var sandboxHiddenHomeDirs = []string{".ssh", ".aws", ".config/gcloud", ".config/gh", ".azure", ".kube", ".docker", ".gnupg"}
+
+// sandboxHiddenDirs is the credential dirs that exist, apart from any that contain a writable dir, since hiding it would hide the project too
+func sandboxHiddenDirs(writableDirs []string) []string {
+	var candidates []string
+	if fs.HomePlandexDir != "" {
+		candidates = append(candidates, fs.HomePlandexDir)
+	}
+	if fs.HomeDir != "" {
+		for _, dir := range sandboxHiddenHomeDirs {
+			candidates = append(candidates, filepath.Join(fs.HomeDir, dir))
+		}
+	}
+
+	var res []string
+	for _, dir := range candidates {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() {
+			continue
+		}
+
+		containsWritable := false
+		for _, writableDir := range writableDirs {
+			inside, err := fs.IsSubpathOf(dir, writableDir, fs.ProjectRoot)
+			if err != nil || inside {
+				containsWritable = true
+				break
+			}
+		}
+		if !containsWritable {
+			res = append(res, dir)
+		}
+	}
+	return res
+}
+
+// sandboxEnv drops Plandex's own credentials from the script's environment: the PLANDEX_TOKEN access token and model provider API keys
+func sandboxEnv(environ []string) []string {
+	env := make([]string, 0, len(environ))
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if name == auth.TokenEnvVar || strings.HasSuffix(strings.ToUpper(name), "_API_KEY") {
+			continue
+		}
+		env = append(env, kv)
+	}
+	return env
+}
+
+// runs inside the new user and mount namespaces with the writable dirs as args, then '--', the dirs to hide, another '--', and the command to run. Each writable dir gets its own bind mount and each hidden dir gets an empty, read-only tmpfs on top, then every other mount is remounted read-only. If any of them can't be, the script exits without running the command rather than leaving part of the system writable.
+const unshareSandboxScript = `set -e
+mount --make-rprivate /
+mounted=""
+while [ "$1" != "--" ]; do
+  mount --bind "$1" "$1"
+  mounted="$mounted
+$1"
+  shift
+done
+shift
+while [ "$1" != "--" ]; do
+  mount -t tmpfs -o ro,mode=0700 tmpfs "$1"
+  mounted="$mounted
+$1"
+  shift
+done
+shift
+failed=$(awk '{print $5}' /proc/self/mountinfo | sort -u | while IFS= read -r m; do
+  m=$(printf '%b' "$m")
+  if printf '%s\n' "$mounted" | grep -qxF -- "$m"; then
+    continue
+  fi
+  mount -o remount,bind,ro "$m" 2>/dev/null || printf '%s\n' "$m"
+done)
+if [ -n "$failed" ]; then
+  echo "sandbox: couldn't make $(printf '%s\n' "$failed" | wc -l) mount(s) read-only:" >&2
+  printf '%s\n' "$failed" >&2
+  exit 1
+fi
+# re-enter the working dir so it resolves to the writable bind mount
+cd "$(pwd)"
+exec "$@"
+`
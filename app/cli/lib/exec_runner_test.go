@@ -0,0 +1,230 @@
+package lib
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plandex-cli/fs"
+	"plandex-cli/types"
+	"reflect"
+	"runtime"
+	"slices"
+	"testing"
+)
+
+func TestSandboxRunnerCommand(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the sandbox is linux only")
+	}
+
+	prevRoot, prevRoots, prevLookPath := fs.ProjectRoot, fs.Roots, lookPath
+	t.Cleanup(func() {
+		fs.ProjectRoot, fs.Roots, lookPath = prevRoot, prevRoots, prevLookPath
+	})
+
+	home := setupSandboxHome(t, ".plandex-home-v2", ".ssh", ".aws")
+	plandexHome, ssh, aws := filepath.Join(home, ".plandex-home-v2"), filepath.Join(home, ".ssh"), filepath.Join(home, ".aws")
+
+	fs.ProjectRoot = "/home/dev/app"
+	fs.Roots = []*types.ProjectRoot{
+		{Name: "web", Path: "../web"},
+		{Name: "lib", Path: "/opt/lib"},
+	}
+
+	tests := []struct {
+		name      string
+		available []string
+		runner    sandboxRunner
+		want      []string
+		wantErr   bool
+	}{
+		{
+			name:      "bwrap without network",
+			available: []string{"bwrap", "unshare"},
+			want: []string{
+				"/usr/bin/bwrap",
+				"--ro-bind", "/", "/",
+				"--dev", "/dev",
+				"--proc", "/proc",
+				"--tmpfs", "/tmp",
+				"--bind", "/home/dev/app", "/home/dev/app",
+				"--bind", "/home/dev/web", "/home/dev/web",
+				"--bind", "/opt/lib", "/opt/lib",
+				"--tmpfs", plandexHome,
+				"--tmpfs", ssh,
+				"--tmpfs", aws,
+				"--unshare-all",
+				"--die-with-parent", "--chdir", "/home/dev/app", "--",
+				"bash", "-l", "_apply.sh",
+			},
+		},
+		{
+			name:      "bwrap with network",
+			available: []string{"bwrap"},
+			runner:    sandboxRunner{network: true},
+			want: []string{
+				"/usr/bin/bwrap",
+				"--ro-bind", "/", "/",
+				"--dev", "/dev",
+				"--proc", "/proc",
+				"--tmpfs", "/tmp",
+				"--bind", "/home/dev/app", "/home/dev/app",
+				"--bind", "/home/dev/web", "/home/dev/web",
+				"--bind", "/opt/lib", "/opt/lib",
+				"--tmpfs", plandexHome,
+				"--tmpfs", ssh,
+				"--tmpfs", aws,
+				"--unshare-all", "--share-net",
+				"--die-with-parent", "--chdir", "/home/dev/app", "--",
+				"bash", "-l", "_apply.sh",
+			},
+		},
+		{
+			name:      "unshare without network",
+			available: []string{"unshare"},
+			want: []string{
+				"/usr/bin/unshare",
+				"--user", "--map-root-user",
+				"--mount", "--pid", "--fork", "--kill-child", "--mount-proc",
+				"--net",
+				"sh", "-c", unshareSandboxScript, "sh",
+				"/tmp", "/home/dev/app", "/home/dev/web", "/opt/lib", "--",
+				plandexHome, ssh, aws, "--",
+				"bash", "-l", "_apply.sh",
+			},
+		},
+		{
+			name:      "unshare with network",
+			available: []string{"unshare"},
+			runner:    sandboxRunner{network: true},
+			want: []string{
+				"/usr/bin/unshare",
+				"--user", "--map-root-user",
+				"--mount", "--pid", "--fork", "--kill-child", "--mount-proc",
+				"sh", "-c", unshareSandboxScript, "sh",
+				"/tmp", "/home/dev/app", "/home/dev/web", "/opt/lib", "--",
+				plandexHome, ssh, aws, "--",
+				"bash", "-l", "_apply.sh",
+			},
+		},
+		{
+			name:    "neither available",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			lookPath = func(file string) (string, error) {
+				if slices.Contains(test.available, file) {
+					return "/usr/bin/" + file, nil
+				}
+				return "", exec.ErrNotFound
+			}
+
+			cmd, err := test.runner.Command("bash", "_apply.sh")
+			if test.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got %v", cmd.Args)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(cmd.Args, test.want) {
+				t.Errorf("unexpected args\ngot:  %q\nwant: %q", cmd.Args, test.want)
+			}
+			if cmd.Dir != fs.ProjectRoot {
+				t.Errorf("expected dir %s, got %s", fs.ProjectRoot, cmd.Dir)
+			}
+			for _, kv := range []string{"PLANDEX_ROOT_WEB=/home/dev/web", "PLANDEX_ROOT_LIB=/opt/lib"} {
+				if !slices.Contains(cmd.Env, kv) {
+					t.Errorf("expected %s in the environment", kv)
+				}
+			}
+		})
+	}
+}
+
+// points the home dirs at a temp dir with the given subdirs
+func setupSandboxHome(t *testing.T, dirs ...string) string {
+	t.Helper()
+
+	prevHome, prevPlandexHome := fs.HomeDir, fs.HomePlandexDir
+	t.Cleanup(func() {
+		fs.HomeDir, fs.HomePlandexDir = prevHome, prevPlandexHome
+	})
+
+	home := t.TempDir()
+	for _, dir := range dirs {
+		err := os.MkdirAll(filepath.Join(home, dir), 0755)
+		if err != nil {
+			t.Fatalf("error creating %s: %v", dir, err)
+		}
+	}
+
+	fs.HomeDir = home
+	fs.HomePlandexDir = filepath.Join(home, ".plandex-home-v2")
+	return home
+}
+
+func TestSandboxHiddenDirs(t *testing.T) {
+	home := setupSandboxHome(t, ".plandex-home-v2", ".ssh", ".config/gcloud", ".docker/projects/app", ".kube")
+
+	// a file isn't hidden, and a dir with a writable dir inside it stays visible
+	err := os.WriteFile(filepath.Join(home, ".aws"), []byte("x"), 0644)
+	if err != nil {
+		t.Fatalf("error writing file: %v", err)
+	}
+
+	got := sandboxHiddenDirs([]string{filepath.Join(home, ".docker", "projects", "app"), filepath.Join(home, ".kube")})
+	want := []string{
+		filepath.Join(home, ".plandex-home-v2"),
+		filepath.Join(home, ".ssh"),
+		filepath.Join(home, ".config", "gcloud"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestSandboxEnv(t *testing.T) {
+	env := sandboxEnv([]string{
+		"PATH=/usr/bin",
+		"HOME=/home/dev",
+		"PLANDEX_TOKEN=secret",
+		"OPENAI_API_KEY=secret",
+		"ANTHROPIC_API_KEY=secret",
+		"my_service_api_key=secret",
+		"PLANDEX_ENV=development",
+		"GOPATH=/home/dev/go",
+	})
+
+	want := []string{"PATH=/usr/bin", "HOME=/home/dev", "PLANDEX_ENV=development", "GOPATH=/home/dev/go"}
+	if !reflect.DeepEqual(env, want) {
+		t.Errorf("got %q, want %q", env, want)
+	}
+}
+
+func TestSandboxRunnerScriptHeader(t *testing.T) {
+	tests := []struct {
+		name   string
+		runner sandboxRunner
+		want   string
+	}{
+		{"no limits", sandboxRunner{}, ""},
+		{"cpu only", sandboxRunner{cpuSeconds: 120}, "ulimit -t 120"},
+		{"memory only", sandboxRunner{memoryMb: 512}, "ulimit -v 524288"},
+		{"both", sandboxRunner{cpuSeconds: 30, memoryMb: 4096}, "ulimit -t 30\nulimit -v 4194304"},
+		// the timeout is enforced by the caller rather than in the script
+		{"timeout only", sandboxRunner{timeout: 60}, ""},
+	}
+
+	for _, test := range tests {
+		if got := test.runner.ScriptHeader(); got != test.want {
+			t.Errorf("%s: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"log"
 	"os/exec"
+	"plandex-cli/fs"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -56,6 +58,34 @@ func GitAddAndCommitPaths(dir, message string, paths []string, lockMutex bool) e
 	return nil
 }
 
+// GitAddAndCommitProjectPaths commits project paths separately in each project root that's in a git repo, since additional roots are usually their own repos
+func GitAddAndCommitProjectPaths(message string, paths []string) error {
+	byRoot := fs.GroupPathsByRoot(paths)
+
+	var names []string
+	for name := range byRoot {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		dir := fs.RootDirByName(name)
+		if dir == "" || !fs.IsGitRepo(dir) {
+			continue
+		}
+
+		err := GitAddAndCommitPaths(dir, message, byRoot[name], true)
+		if err != nil {
+			if name != "" {
+				return fmt.Errorf("root %s: %v", name, err)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
 func GitAdd(repoDir, path string, lockMutex bool) error {
 	if lockMutex {
 		gitMutex.Lock()
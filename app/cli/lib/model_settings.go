@@ -170,6 +170,12 @@ func mustVerifyApiKeys(silent bool) map[string]string {
 
 	missingAny := false
 	for envVar := range requiredEnvVars {
+		if shared.OptionalApiKeyEnvVars[envVar] {
+			// local servers usually don't need a key, so send whatever is set (even if empty)
+			apiKeys[envVar] = os.Getenv(envVar)
+			continue
+		}
+
 		if os.Getenv(envVar) == "" {
 			fmt.Fprintln(os.Stderr, color.New(color.Bold, term.ColorHiRed).Sprintf("🚨 %s environment variable is not set.\n", envVar))
 			missingAny = true
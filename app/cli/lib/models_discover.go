@@ -0,0 +1,323 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+const discoverRequestTimeout = 10 * time.Second
+
+// used when a server doesn't report a context size for a model
+const defaultLocalContextTokens = 8192
+
+// the context size Ollama uses for requests when num_ctx isn't set in the Modelfile (and OLLAMA_CONTEXT_LENGTH isn't set on the server)
+const ollamaDefaultNumCtx = 4096
+
+type LocalModelServer struct {
+	Provider       shared.ModelProvider
+	CustomProvider *string
+	BaseUrl        string
+	ApiKeyEnvVar   string
+	Models         []*DiscoveredModel
+}
+
+type DiscoveredModel struct {
+	Name            string
+	MaxTokens       int
+	HasImageSupport bool
+	HasToolSupport  bool
+
+	// true if MaxTokens is what the server will actually use at runtime (as opposed to the model's trained maximum or a default)
+	RuntimeContextSet bool
+}
+
+var discoverClient = &http.Client{Timeout: discoverRequestTimeout}
+
+// DiscoverLocalModels probes a local model server, trying the Ollama api first, then falling back to an OpenAI-compatible /v1/models endpoint (llama.cpp server, etc.)
+func DiscoverLocalModels(host string) (*LocalModelServer, error) {
+	host = strings.TrimSuffix(strings.TrimSpace(host), "/")
+	host = strings.TrimSuffix(host, "/v1")
+	if host == "" {
+		host = shared.OllamaDefaultHost
+	}
+	if !strings.HasPrefix(host, "http://") && !strings.HasPrefix(host, "https://") {
+		host = "http://" + host
+	}
+
+	ollamaModels, ollamaErr := discoverOllamaModels(host)
+	if ollamaErr == nil {
+		return &LocalModelServer{
+			Provider:     shared.ModelProviderOllama,
+			BaseUrl:      host + "/v1",
+			ApiKeyEnvVar: shared.OllamaApiKeyEnvVar,
+			Models:       ollamaModels,
+		}, nil
+	}
+
+	openAIModels, openAIErr := discoverOpenAICompatibleModels(host)
+	if openAIErr == nil {
+		customProvider := shared.LlamaCppCustomProvider
+		return &LocalModelServer{
+			Provider:       shared.ModelProviderCustom,
+			CustomProvider: &customProvider,
+			BaseUrl:        host + "/v1",
+			ApiKeyEnvVar:   shared.LlamaCppApiKeyEnvVar,
+			Models:         openAIModels,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("couldn't list models at %s\nollama: %v\nopenai-compatible: %v", host, ollamaErr, openAIErr)
+}
+
+func (s *LocalModelServer) ProviderLabel() string {
+	if s.CustomProvider != nil {
+		return *s.CustomProvider
+	}
+	return string(s.Provider)
+}
+
+// IsLoopback is true if BaseUrl points at this machine -- the Plandex server sends model requests to BaseUrl, so it won't be able to reach a loopback address if it runs in Docker or on another machine
+func (s *LocalModelServer) IsLoopback() bool {
+	u, err := url.Parse(s.BaseUrl)
+	if err != nil {
+		return false
+	}
+	hostname := u.Hostname()
+	if hostname == "localhost" || strings.HasSuffix(hostname, ".localhost") {
+		return true
+	}
+	ip := net.ParseIP(hostname)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *LocalModelServer) ToAvailableModel(m *DiscoveredModel, forceXml bool) *shared.AvailableModel {
+	maxOutputTokens, reservedOutputTokens, defaultMaxConvoTokens := localModelTokenDefaults(m.MaxTokens)
+
+	outputFormat := shared.ModelOutputFormatXml
+	if m.HasToolSupport && !forceXml {
+		outputFormat = shared.ModelOutputFormatToolCallJson
+	}
+
+	return &shared.AvailableModel{
+		BaseModelConfig: shared.BaseModelConfig{
+			Provider:                   s.Provider,
+			CustomProvider:             s.CustomProvider,
+			BaseUrl:                    s.BaseUrl,
+			ModelName:                  shared.ModelName(m.Name),
+			ModelId:                    shared.ModelId(m.Name),
+			MaxTokens:                  m.MaxTokens,
+			MaxOutputTokens:            maxOutputTokens,
+			ReservedOutputTokens:       reservedOutputTokens,
+			ApiKeyEnvVar:               s.ApiKeyEnvVar,
+			PreferredModelOutputFormat: outputFormat,
+			ModelCompatibility: shared.ModelCompatibility{
+				HasImageSupport: m.HasImageSupport,
+			},
+		},
+		Description:           fmt.Sprintf("%s (discovered via %s at %s)", m.Name, s.ProviderLabel(), s.BaseUrl),
+		DefaultMaxConvoTokens: defaultMaxConvoTokens,
+	}
+}
+
+// local models generally have no separate output limit, so output is bounded by the context size -- the defaults mirror those suggested by 'plandex models add'
+func localModelTokenDefaults(maxTokens int) (maxOutputTokens, reservedOutputTokens, defaultMaxConvoTokens int) {
+	maxOutputTokens = maxTokens
+
+	reservedOutputTokens = min(8192, maxTokens/4)
+
+	if maxTokens >= 180000 {
+		defaultMaxConvoTokens = 15000
+	} else if maxTokens >= 100000 {
+		defaultMaxConvoTokens = 10000
+	} else {
+		defaultMaxConvoTokens = max(maxTokens/8, 1000)
+	}
+
+	return maxOutputTokens, reservedOutputTokens, defaultMaxConvoTokens
+}
+
+type ollamaTagsResponse struct {
+	Models []struct {
+		Name    string `json:"name"`
+		Model   string `json:"model"`
+		Details struct {
+			Family   string   `json:"family"`
+			Families []string `json:"families"`
+		} `json:"details"`
+	} `json:"models"`
+}
+
+type ollamaShowResponse struct {
+	Parameters    string         `json:"parameters"`
+	ModelInfo     map[string]any `json:"model_info"`
+	ProjectorInfo map[string]any `json:"projector_info"`
+	Capabilities  []string       `json:"capabilities"`
+	Details       struct {
+		Families []string `json:"families"`
+	} `json:"details"`
+}
+
+func discoverOllamaModels(host string) ([]*DiscoveredModel, error) {
+	var tags ollamaTagsResponse
+	err := getJson(host+"/api/tags", &tags)
+	if err != nil {
+		return nil, err
+	}
+
+	var models []*DiscoveredModel
+	for _, m := range tags.Models {
+		name := m.Model
+		if name == "" {
+			name = m.Name
+		}
+
+		var show ollamaShowResponse
+		err := postJson(host+"/api/show", map[string]string{"model": name}, &show)
+		if err != nil {
+			return nil, fmt.Errorf("error getting details for %s: %v", name, err)
+		}
+
+		// skip embedding-only models
+		if len(show.Capabilities) > 0 && !slices.Contains(show.Capabilities, "completion") {
+			continue
+		}
+
+		model := &DiscoveredModel{
+			Name:            name,
+			HasToolSupport:  slices.Contains(show.Capabilities, "tools"),
+			HasImageSupport: slices.Contains(show.Capabilities, "vision") || len(show.ProjectorInfo) > 0 || slices.Contains(show.Details.Families, "clip"),
+		}
+
+		// num_ctx set in the Modelfile is what Ollama will actually use for requests
+		if numCtx := parseOllamaNumCtx(show.Parameters); numCtx > 0 {
+			model.MaxTokens = numCtx
+			model.RuntimeContextSet = true
+		} else {
+			// otherwise Ollama truncates prompts to its default context size, however large the model's trained context is
+			model.MaxTokens = ollamaDefaultNumCtx
+			for key, val := range show.ModelInfo {
+				if strings.HasSuffix(key, ".context_length") {
+					if n, ok := val.(float64); ok && n > 0 {
+						model.MaxTokens = min(model.MaxTokens, int(n))
+					}
+					break
+				}
+			}
+		}
+
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+func parseOllamaNumCtx(parameters string) int {
+	for _, line := range strings.Split(parameters, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "num_ctx" {
+			n, err := strconv.Atoi(fields[1])
+			if err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+type openAIModelsResponse struct {
+	Data []struct {
+		Id   string `json:"id"`
+		Meta *struct {
+			NCtxTrain int `json:"n_ctx_train"`
+		} `json:"meta,omitempty"`
+	} `json:"data"`
+}
+
+// llama.cpp server props -- other OpenAI-compatible servers won't have this endpoint
+type llamaCppPropsResponse struct {
+	DefaultGenerationSettings struct {
+		NCtx int `json:"n_ctx"`
+	} `json:"default_generation_settings"`
+	Modalities struct {
+		Vision bool `json:"vision"`
+	} `json:"modalities"`
+}
+
+func discoverOpenAICompatibleModels(host string) ([]*DiscoveredModel, error) {
+	var res openAIModelsResponse
+	err := getJson(host+"/v1/models", &res)
+	if err != nil {
+		return nil, err
+	}
+
+	var props *llamaCppPropsResponse
+	var p llamaCppPropsResponse
+	if err := getJson(host+"/props", &p); err == nil {
+		props = &p
+	}
+
+	var models []*DiscoveredModel
+	for _, m := range res.Data {
+		model := &DiscoveredModel{
+			Name: m.Id,
+		}
+
+		if props != nil && props.DefaultGenerationSettings.NCtx > 0 {
+			model.MaxTokens = props.DefaultGenerationSettings.NCtx
+			model.RuntimeContextSet = true
+		} else if m.Meta != nil && m.Meta.NCtxTrain > 0 {
+			model.MaxTokens = m.Meta.NCtxTrain
+		} else {
+			model.MaxTokens = defaultLocalContextTokens
+		}
+
+		if props != nil {
+			model.HasImageSupport = props.Modalities.Vision
+		}
+
+		models = append(models, model)
+	}
+
+	return models, nil
+}
+
+func getJson(url string, v any) error {
+	resp, err := discoverClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeJsonResponse(resp, v)
+}
+
+func postJson(url string, body any, v any) error {
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := discoverClient.Post(url, "application/json", bytes.NewReader(jsonBody))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return decodeJsonResponse(resp, v)
+}
+
+func decodeJsonResponse(resp *http.Response, v any) error {
+	if resp.StatusCode >= 400 {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
@@ -0,0 +1,239 @@
+package lib
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestParseOllamaNumCtx(t *testing.T) {
+	tests := []struct {
+		parameters string
+		want       int
+	}{
+		{"", 0},
+		{"num_ctx                        32768", 32768},
+		{"temperature                    0.7\nnum_ctx                        8192\nstop                           \"<|im_end|>\"", 8192},
+		{"num_ctx abc", 0},
+		{"num_ctx", 0},
+		{"num_ctx_max 8192", 0},
+	}
+
+	for _, test := range tests {
+		if got := parseOllamaNumCtx(test.parameters); got != test.want {
+			t.Errorf("parseOllamaNumCtx(%q) = %d, want %d", test.parameters, got, test.want)
+		}
+	}
+}
+
+func TestLocalModelTokenDefaults(t *testing.T) {
+	tests := []struct {
+		maxTokens    int
+		wantOutput   int
+		wantReserved int
+		wantMaxConvo int
+	}{
+		{2048, 2048, 512, 1000},
+		{4096, 4096, 1024, 1000},
+		{32768, 32768, 8192, 4096},
+		{131072, 131072, 8192, 10000},
+		{200000, 200000, 8192, 15000},
+	}
+
+	for _, test := range tests {
+		output, reserved, maxConvo := localModelTokenDefaults(test.maxTokens)
+		if output != test.wantOutput || reserved != test.wantReserved || maxConvo != test.wantMaxConvo {
+			t.Errorf("localModelTokenDefaults(%d) = (%d, %d, %d), want (%d, %d, %d)", test.maxTokens, output, reserved, maxConvo, test.wantOutput, test.wantReserved, test.wantMaxConvo)
+		}
+	}
+}
+
+func writeJson(t *testing.T, w http.ResponseWriter, v any) {
+	t.Helper()
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		t.Errorf("error writing response: %v", err)
+	}
+}
+
+func TestDiscoverOllamaModels(t *testing.T) {
+	shows := map[string]map[string]any{
+		"qwen3:32b": {
+			"parameters":   "num_ctx 32768",
+			"model_info":   map[string]any{"qwen3.context_length": 40960},
+			"capabilities": []string{"completion", "tools"},
+		},
+		// num_ctx unset, so Ollama's default is used rather than the trained context length
+		"llama3.2:latest": {
+			"model_info":   map[string]any{"llama.context_length": 131072},
+			"capabilities": []string{"completion", "tools"},
+		},
+		"tinyllama:latest": {
+			"model_info":   map[string]any{"llama.context_length": 2048},
+			"capabilities": []string{"completion"},
+		},
+		"llava:latest": {
+			"model_info":     map[string]any{"llama.context_length": 32768},
+			"projector_info": map[string]any{"clip.has_vision_encoder": true},
+		},
+		"nomic-embed-text:latest": {
+			"model_info":   map[string]any{"nomic-bert.context_length": 2048},
+			"capabilities": []string{"embedding"},
+		},
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			var models []map[string]string
+			for _, name := range []string{"qwen3:32b", "llama3.2:latest", "tinyllama:latest", "llava:latest", "nomic-embed-text:latest"} {
+				models = append(models, map[string]string{"name": name, "model": name})
+			}
+			writeJson(t, w, map[string]any{"models": models})
+		case "/api/show":
+			var req struct {
+				Model string `json:"model"`
+			}
+			json.NewDecoder(r.Body).Decode(&req)
+			show, ok := shows[req.Model]
+			if !ok {
+				http.Error(w, "model not found", http.StatusNotFound)
+				return
+			}
+			writeJson(t, w, show)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	server, err := DiscoverLocalModels(srv.URL + "/v1/")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if server.Provider != shared.ModelProviderOllama || server.CustomProvider != nil || server.BaseUrl != srv.URL+"/v1" || server.ApiKeyEnvVar != shared.OllamaApiKeyEnvVar {
+		t.Errorf("unexpected server: %+v", server)
+	}
+	if !server.IsLoopback() {
+		t.Errorf("expected %s to be a loopback url", server.BaseUrl)
+	}
+
+	want := []DiscoveredModel{
+		{Name: "qwen3:32b", MaxTokens: 32768, HasToolSupport: true, RuntimeContextSet: true},
+		{Name: "llama3.2:latest", MaxTokens: ollamaDefaultNumCtx, HasToolSupport: true},
+		{Name: "tinyllama:latest", MaxTokens: 2048},
+		{Name: "llava:latest", MaxTokens: ollamaDefaultNumCtx, HasImageSupport: true},
+	}
+
+	if len(server.Models) != len(want) {
+		t.Fatalf("expected %d models, got %d", len(want), len(server.Models))
+	}
+	for i, m := range server.Models {
+		if *m != want[i] {
+			t.Errorf("model %d: got %+v, want %+v", i, *m, want[i])
+		}
+	}
+}
+
+func TestDiscoverOpenAICompatibleModels(t *testing.T) {
+	tests := []struct {
+		name  string
+		props map[string]any
+		meta  map[string]any
+		want  DiscoveredModel
+	}{
+		{
+			name:  "llama.cpp with props",
+			props: map[string]any{"default_generation_settings": map[string]any{"n_ctx": 16384}, "modalities": map[string]any{"vision": true}},
+			meta:  map[string]any{"n_ctx_train": 131072},
+			want:  DiscoveredModel{Name: "model.gguf", MaxTokens: 16384, HasImageSupport: true, RuntimeContextSet: true},
+		},
+		{
+			name: "trained context only",
+			meta: map[string]any{"n_ctx_train": 131072},
+			want: DiscoveredModel{Name: "model.gguf", MaxTokens: 131072},
+		},
+		{
+			name: "no context size",
+			want: DiscoveredModel{Name: "model.gguf", MaxTokens: defaultLocalContextTokens},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/v1/models":
+					model := map[string]any{"id": "model.gguf"}
+					if test.meta != nil {
+						model["meta"] = test.meta
+					}
+					writeJson(t, w, map[string]any{"data": []any{model}})
+				case "/props":
+					if test.props == nil {
+						http.NotFound(w, r)
+						return
+					}
+					writeJson(t, w, test.props)
+				default:
+					// no ollama api
+					http.NotFound(w, r)
+				}
+			}))
+			defer srv.Close()
+
+			server, err := DiscoverLocalModels(srv.URL)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if server.Provider != shared.ModelProviderCustom || server.ProviderLabel() != shared.LlamaCppCustomProvider || server.BaseUrl != srv.URL+"/v1" || server.ApiKeyEnvVar != shared.LlamaCppApiKeyEnvVar {
+				t.Errorf("unexpected server: %+v", server)
+			}
+
+			if len(server.Models) != 1 {
+				t.Fatalf("expected 1 model, got %d", len(server.Models))
+			}
+			if *server.Models[0] != test.want {
+				t.Errorf("got %+v, want %+v", *server.Models[0], test.want)
+			}
+		})
+	}
+}
+
+func TestDiscoverLocalModelsUnreachable(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	_, err := DiscoverLocalModels(srv.URL)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+}
+
+func TestLocalModelServerIsLoopback(t *testing.T) {
+	tests := []struct {
+		baseUrl string
+		want    bool
+	}{
+		{"http://localhost:11434/v1", true},
+		{"http://127.0.0.1:8080/v1", true},
+		{"http://[::1]:8080/v1", true},
+		{"http://ollama.localhost/v1", true},
+		{"http://host.docker.internal:11434/v1", false},
+		{"http://192.168.1.20:11434/v1", false},
+		{"https://models.example.com/v1", false},
+	}
+
+	for _, test := range tests {
+		server := &LocalModelServer{BaseUrl: test.baseUrl}
+		if got := server.IsLoopback(); got != test.want {
+			t.Errorf("IsLoopback(%q) = %v, want %v", test.baseUrl, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	shared "plandex-shared"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadModelPackFile reads and validates a YAML model pack, as written by 'plandex model-packs export'
+func ReadModelPackFile(path string) (*shared.ModelPack, error) {
+	var pack shared.ModelPack
+
+	err := decodeYamlFile(path, &pack)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pack.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid model pack in %s: %v", path, err)
+	}
+
+	return &pack, nil
+}
+
+// ReadModelsFile reads and validates a YAML file of custom models and model packs for 'plandex models sync'
+func ReadModelsFile(path string) (*shared.ModelsFile, error) {
+	var file shared.ModelsFile
+
+	err := decodeYamlFile(path, &file)
+	if err != nil {
+		return nil, err
+	}
+
+	err = file.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid models file %s: %v", path, err)
+	}
+
+	return &file, nil
+}
+
+// unknown fields are an error so that typos don't silently fall back to defaults
+func decodeYamlFile(path string, v any) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	err = decoder.Decode(v)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,74 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"plandex-cli/fs"
+	"plandex-cli/types"
+	"sort"
+
+	shared "plandex-shared"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ReadPlanTemplateFile reads and validates a YAML plan template
+func ReadPlanTemplateFile(path string) (*shared.PlanTemplate, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var template shared.PlanTemplate
+
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	err = decoder.Decode(&template)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	err = template.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid plan template in %s: %v", path, err)
+	}
+
+	return &template, nil
+}
+
+// ResolveTemplateContextPaths expands a template's context paths, which are relative to the project root, into the project files they match. Globs match files, directories include every file under them, and ignored files are skipped.
+func ResolveTemplateContextPaths(contextPaths []string) ([]string, error) {
+	if len(contextPaths) == 0 {
+		return nil, nil
+	}
+
+	baseDir := fs.GetBaseDirForFilePaths(contextPaths)
+
+	paths, err := fs.GetProjectPaths(baseDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project paths: %v", err)
+	}
+
+	flattenedPaths, err := ParseInputPaths(ParseInputPathsParams{
+		FileOrDirPaths: contextPaths,
+		BaseDir:        baseDir,
+		ProjectPaths:   paths,
+		LoadParams:     &types.LoadContextParams{Recursive: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse context paths: %v", err)
+	}
+
+	var res []string
+	for _, path := range flattenedPaths {
+		if paths.ActivePaths[path] {
+			res = append(res, path)
+		}
+	}
+	sort.Strings(res)
+
+	return res, nil
+}
@@ -0,0 +1,454 @@
+package lib
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// A Recipe is a declarative sequence of plan steps loaded from a YAML file and run with 'plandex run'
+type Recipe struct {
+	Name   string            `yaml:"name"`
+	Vars   map[string]string `yaml:"vars"`
+	Config map[string]string `yaml:"config"`
+	Steps  []*RecipeStep     `yaml:"steps"`
+}
+
+// Each step sets exactly one of the action fields (load, tell, build, apply, exec, debug, set_config, assert)
+type RecipeStep struct {
+	Id              string `yaml:"id"`
+	When            string `yaml:"when"`
+	ContinueOnError bool   `yaml:"continue_on_error"`
+
+	Load      []string `yaml:"load"`
+	Recursive bool     `yaml:"recursive"`
+	Note      string   `yaml:"note"`
+
+	Tell      string            `yaml:"tell"`
+	Build     bool              `yaml:"build"`
+	Apply     RecipeApply       `yaml:"apply"`
+	Exec      string            `yaml:"exec"`
+	Debug     *RecipeDebug      `yaml:"debug"`
+	SetConfig map[string]string `yaml:"set_config"`
+	Assert    *RecipeAssert     `yaml:"assert"`
+
+	condition *recipeCondition
+}
+
+type RecipeApply struct {
+	Enabled bool `yaml:"-"`
+	Commit  bool `yaml:"commit"`
+	Exec    bool `yaml:"exec"`
+	Debug   int  `yaml:"debug"`
+}
+
+// apply can be set to true, or to a map of options
+func (a *RecipeApply) UnmarshalYAML(value *yaml.Node) error {
+	if value.Kind == yaml.ScalarNode {
+		return value.Decode(&a.Enabled)
+	}
+
+	type applyOpts RecipeApply
+	var opts applyOpts
+	err := value.Decode(&opts)
+	if err != nil {
+		return err
+	}
+	*a = RecipeApply(opts)
+	a.Enabled = true
+	return nil
+}
+
+type RecipeDebug struct {
+	Command string `yaml:"command"`
+	Tries   int    `yaml:"tries"`
+	Commit  bool   `yaml:"commit"`
+}
+
+type RecipeAssert struct {
+	That         string              `yaml:"that"`
+	FileExists   string              `yaml:"file_exists"`
+	FileContains *RecipeFileContains `yaml:"file_contains"`
+}
+
+type RecipeFileContains struct {
+	Path string `yaml:"path"`
+	Text string `yaml:"text"`
+}
+
+type RecipeStepKind string
+
+const (
+	RecipeStepLoad      RecipeStepKind = "load"
+	RecipeStepTell      RecipeStepKind = "tell"
+	RecipeStepBuild     RecipeStepKind = "build"
+	RecipeStepApply     RecipeStepKind = "apply"
+	RecipeStepExec      RecipeStepKind = "exec"
+	RecipeStepDebug     RecipeStepKind = "debug"
+	RecipeStepSetConfig RecipeStepKind = "set_config"
+	RecipeStepAssert    RecipeStepKind = "assert"
+)
+
+const RecipeDefaultDebugTries = 5
+
+func (s *RecipeStep) Kind() RecipeStepKind {
+	kinds := s.kinds()
+	if len(kinds) != 1 {
+		return ""
+	}
+	return kinds[0]
+}
+
+func (s *RecipeStep) kinds() []RecipeStepKind {
+	var kinds []RecipeStepKind
+	if len(s.Load) > 0 {
+		kinds = append(kinds, RecipeStepLoad)
+	}
+	if s.Tell != "" {
+		kinds = append(kinds, RecipeStepTell)
+	}
+	if s.Build {
+		kinds = append(kinds, RecipeStepBuild)
+	}
+	if s.Apply.Enabled {
+		kinds = append(kinds, RecipeStepApply)
+	}
+	if s.Exec != "" {
+		kinds = append(kinds, RecipeStepExec)
+	}
+	if s.Debug != nil {
+		kinds = append(kinds, RecipeStepDebug)
+	}
+	if len(s.SetConfig) > 0 {
+		kinds = append(kinds, RecipeStepSetConfig)
+	}
+	if s.Assert != nil {
+		kinds = append(kinds, RecipeStepAssert)
+	}
+	return kinds
+}
+
+var recipeStepIdRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// LoadRecipe reads and validates a recipe. vars override the recipe's own vars.
+func LoadRecipe(path string, vars map[string]string) (*Recipe, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading recipe: %v", err)
+	}
+
+	var recipe Recipe
+	err = yaml.Unmarshal(bytes, &recipe)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing recipe: %v", err)
+	}
+
+	if recipe.Vars == nil {
+		recipe.Vars = map[string]string{}
+	}
+	for k, v := range vars {
+		recipe.Vars[k] = v
+	}
+
+	for i, step := range recipe.Steps {
+		if step != nil && step.Id == "" {
+			step.Id = fmt.Sprintf("step_%d", i+1)
+		}
+	}
+
+	// top-level config overrides run first, like a set_config step
+	if len(recipe.Config) > 0 {
+		recipe.Steps = append([]*RecipeStep{{Id: "config", SetConfig: recipe.Config}}, recipe.Steps...)
+	}
+
+	if len(recipe.Steps) == 0 {
+		return nil, fmt.Errorf("recipe has no steps")
+	}
+
+	err = recipe.validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return &recipe, nil
+}
+
+func (r *Recipe) validate() error {
+	seen := map[string]bool{}
+
+	for i, step := range r.Steps {
+		if step == nil {
+			return fmt.Errorf("step %d is empty", i+1)
+		}
+
+		if !recipeStepIdRegex.MatchString(step.Id) {
+			return fmt.Errorf("step %d: id %q can only have letters, numbers, and underscores", i+1, step.Id)
+		}
+		if seen[step.Id] {
+			return fmt.Errorf("step %d: duplicate id %q", i+1, step.Id)
+		}
+
+		kinds := step.kinds()
+		if len(kinds) == 0 {
+			return fmt.Errorf("step %s: needs one of load, tell, build, apply, exec, debug, set_config, or assert", step.Id)
+		}
+		if len(kinds) > 1 {
+			return fmt.Errorf("step %s: has more than one action (%s and %s)—split it into separate steps", step.Id, kinds[0], kinds[1])
+		}
+
+		if step.When != "" {
+			cond, err := parseRecipeCondition(step.When, seen)
+			if err != nil {
+				return fmt.Errorf("step %s: invalid when: %v", step.Id, err)
+			}
+			step.condition = cond
+		}
+
+		switch step.Kind() {
+		case RecipeStepDebug:
+			if step.Debug.Command == "" {
+				return fmt.Errorf("step %s: debug needs a command", step.Id)
+			}
+			if step.Debug.Tries < 0 {
+				return fmt.Errorf("step %s: debug tries must be greater than 0", step.Id)
+			}
+			if step.Debug.Tries == 0 {
+				step.Debug.Tries = RecipeDefaultDebugTries
+			}
+		case RecipeStepAssert:
+			if step.Assert.That == "" && step.Assert.FileExists == "" && step.Assert.FileContains == nil {
+				return fmt.Errorf("step %s: assert needs that, file_exists, or file_contains", step.Id)
+			}
+			if step.Assert.That != "" {
+				_, err := parseRecipeCondition(step.Assert.That, seen)
+				if err != nil {
+					return fmt.Errorf("step %s: invalid assert: %v", step.Id, err)
+				}
+			}
+		}
+
+		// catch template syntax errors before anything runs
+		for _, s := range step.templateStrings() {
+			_, err := template.New(step.Id).Parse(s)
+			if err != nil {
+				return fmt.Errorf("step %s: %v", step.Id, err)
+			}
+		}
+
+		seen[step.Id] = true
+	}
+
+	return nil
+}
+
+func (s *RecipeStep) templateStrings() []string {
+	res := append([]string{s.Tell, s.Exec, s.Note}, s.Load...)
+	if s.Debug != nil {
+		res = append(res, s.Debug.Command)
+	}
+	for _, v := range s.SetConfig {
+		res = append(res, v)
+	}
+	if s.Assert != nil {
+		res = append(res, s.Assert.FileExists)
+		if s.Assert.FileContains != nil {
+			res = append(res, s.Assert.FileContains.Path, s.Assert.FileContains.Text)
+		}
+	}
+	return res
+}
+
+type RecipeStepStatus string
+
+const (
+	RecipeStepRunning   RecipeStepStatus = "running"
+	RecipeStepSucceeded RecipeStepStatus = "succeeded"
+	RecipeStepFailed    RecipeStepStatus = "failed"
+	RecipeStepSkipped   RecipeStepStatus = "skipped"
+)
+
+// RecipeStepResult is kept for every step that has started, and is available to later steps' templates and conditions
+type RecipeStepResult struct {
+	Id         string           `json:"id"`
+	Kind       RecipeStepKind   `json:"kind"`
+	Status     RecipeStepStatus `json:"status"`
+	ExitCode   *int             `json:"exitCode,omitempty"`
+	Attempts   int              `json:"attempts,omitempty"`
+	Output     string           `json:"output,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	StartedAt  time.Time        `json:"startedAt"`
+	DurationMs int64            `json:"durationMs"`
+}
+
+// RecipeReport is the machine-readable result of a recipe run
+type RecipeReport struct {
+	Recipe     string              `json:"recipe"`
+	Name       string              `json:"name,omitempty"`
+	PlanId     string              `json:"planId"`
+	Branch     string              `json:"branch"`
+	Status     RecipeStepStatus    `json:"status"`
+	Vars       map[string]string   `json:"vars,omitempty"`
+	Steps      []*RecipeStepResult `json:"steps"`
+	StartedAt  time.Time           `json:"startedAt"`
+	FinishedAt *time.Time          `json:"finishedAt,omitempty"`
+}
+
+func (r *RecipeReport) StepResult(id string) *RecipeStepResult {
+	for _, res := range r.Steps {
+		if res.Id == id {
+			return res
+		}
+	}
+	return nil
+}
+
+func (r *RecipeReport) Save(path string) error {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling recipe report: %v", err)
+	}
+	err = os.WriteFile(path, bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing recipe report: %v", err)
+	}
+	return nil
+}
+
+// RenderRecipeString fills in {{.vars.name}}, {{.env.NAME}}, and {{.steps.id.output}} (or .status/.exitCode) from earlier steps
+func RenderRecipeString(s string, recipe *Recipe, report *RecipeReport) (string, error) {
+	if !strings.Contains(s, "{{") {
+		return s, nil
+	}
+
+	steps := map[string]any{}
+	for _, res := range report.Steps {
+		exitCode := 0
+		if res.ExitCode != nil {
+			exitCode = *res.ExitCode
+		}
+		steps[res.Id] = map[string]any{
+			"status":   string(res.Status),
+			"exitCode": exitCode,
+			"output":   res.Output,
+		}
+	}
+
+	env := map[string]string{}
+	for _, kv := range os.Environ() {
+		k, v, _ := strings.Cut(kv, "=")
+		env[k] = v
+	}
+
+	data := map[string]any{
+		"vars":  recipe.Vars,
+		"env":   env,
+		"steps": steps,
+	}
+
+	tmpl, err := template.New("recipe").Option("missingkey=error").Parse(s)
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// conditions are either <id>.succeeded, <id>.failed, <id>.skipped, or <id>.exitCode (== or !=) <n>
+type recipeCondition struct {
+	stepId   string
+	field    string
+	op       string
+	exitCode int
+}
+
+var recipeConditionRegex = regexp.MustCompile(`^\s*([A-Za-z_][A-Za-z0-9_]*)\.(succeeded|failed|skipped|exitCode)\s*(?:(==|!=)\s*(-?\d+))?\s*$`)
+
+func parseRecipeCondition(s string, knownIds map[string]bool) (*recipeCondition, error) {
+	matches := recipeConditionRegex.FindStringSubmatch(s)
+	if matches == nil {
+		return nil, fmt.Errorf("%q should look like tests.failed, tests.succeeded, tests.skipped, or tests.exitCode == 1", s)
+	}
+
+	cond := &recipeCondition{
+		stepId: matches[1],
+		field:  matches[2],
+		op:     matches[3],
+	}
+
+	if !knownIds[cond.stepId] {
+		return nil, fmt.Errorf("%q refers to %s, which isn't an earlier step", s, cond.stepId)
+	}
+
+	if cond.field == "exitCode" {
+		if cond.op == "" {
+			return nil, fmt.Errorf("%q needs a comparison, like exitCode == 1", s)
+		}
+		cond.exitCode, _ = strconv.Atoi(matches[4])
+	} else if cond.op != "" {
+		return nil, fmt.Errorf("%q can't compare %s", s, cond.field)
+	}
+
+	return cond, nil
+}
+
+// ShouldRun is false when the step's when condition isn't met
+func (s *RecipeStep) ShouldRun(report *RecipeReport) bool {
+	if s.condition == nil {
+		return true
+	}
+	return s.condition.eval(report)
+}
+
+// EvalRecipeCondition evaluates an assert's that condition
+func EvalRecipeCondition(s string, report *RecipeReport) (bool, error) {
+	known := map[string]bool{}
+	for _, res := range report.Steps {
+		known[res.Id] = true
+	}
+	cond, err := parseRecipeCondition(s, known)
+	if err != nil {
+		return false, err
+	}
+	return cond.eval(report), nil
+}
+
+func (c *recipeCondition) eval(report *RecipeReport) bool {
+	res := report.StepResult(c.stepId)
+
+	if res == nil || res.Status == RecipeStepSkipped {
+		return c.field == "skipped"
+	}
+
+	switch c.field {
+	case "succeeded":
+		return res.Status == RecipeStepSucceeded
+	case "failed":
+		return res.Status == RecipeStepFailed
+	case "skipped":
+		return false
+	case "exitCode":
+		if res.ExitCode == nil {
+			return false
+		}
+		if c.op == "==" {
+			return *res.ExitCode == c.exitCode
+		}
+		return *res.ExitCode != c.exitCode
+	}
+
+	return false
+}
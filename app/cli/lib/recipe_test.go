@@ -0,0 +1,214 @@
+package lib
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeRecipe(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "recipe.yaml")
+	err := os.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("error writing recipe: %v", err)
+	}
+	return path
+}
+
+func TestLoadRecipe(t *testing.T) {
+	tests := []struct {
+		name    string
+		recipe  string
+		wantErr string
+	}{
+		{
+			name: "valid",
+			recipe: `
+steps:
+  - load: [src]
+  - id: plan
+    tell: "add tests"
+  - apply: { commit: true, exec: true, debug: 2 }
+  - id: tests
+    exec: npm test
+  - when: tests.failed
+    debug: { command: npm test }
+  - assert: { that: tests.exitCode == 0 }
+`,
+		},
+		{name: "no steps", recipe: "name: empty\n", wantErr: "recipe has no steps"},
+		{name: "empty step", recipe: "steps:\n  -\n", wantErr: "step 1 is empty"},
+		{name: "no action", recipe: "steps:\n  - id: nothing\n", wantErr: "needs one of"},
+		{name: "two actions", recipe: "steps:\n  - tell: hi\n    exec: ls\n", wantErr: "more than one action (tell and exec)"},
+		{name: "invalid id", recipe: "steps:\n  - id: run-tests\n    exec: ls\n", wantErr: `id "run-tests" can only have`},
+		{name: "duplicate id", recipe: "steps:\n  - id: a\n    exec: ls\n  - id: a\n    exec: ls\n", wantErr: `duplicate id "a"`},
+		{name: "when on a later step", recipe: "steps:\n  - when: b.failed\n    exec: ls\n  - id: b\n    exec: ls\n", wantErr: "isn't an earlier step"},
+		{name: "malformed when", recipe: "steps:\n  - id: a\n    exec: ls\n  - when: a is done\n    exec: ls\n", wantErr: "invalid when"},
+		{name: "debug without command", recipe: "steps:\n  - debug: { tries: 2 }\n", wantErr: "debug needs a command"},
+		{name: "negative debug tries", recipe: "steps:\n  - debug: { command: ls, tries: -1 }\n", wantErr: "tries must be greater than 0"},
+		{name: "empty assert", recipe: "steps:\n  - assert: {}\n", wantErr: "assert needs that"},
+		{name: "invalid assert", recipe: "steps:\n  - id: a\n    exec: ls\n  - assert: { that: a.succeeded == 1 }\n", wantErr: "invalid assert"},
+		{name: "template syntax error", recipe: "steps:\n  - exec: \"echo {{.vars.x\"\n", wantErr: "unclosed action"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := LoadRecipe(writeRecipe(t, test.recipe), nil)
+			if test.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), test.wantErr) {
+				t.Errorf("expected error containing %q, got %v", test.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestLoadRecipeDefaults(t *testing.T) {
+	path := writeRecipe(t, `
+vars:
+  module: auth
+  env: dev
+config:
+  auto-context: "false"
+steps:
+  - apply: true
+  - debug: { command: make test }
+`)
+
+	recipe, err := LoadRecipe(path, map[string]string{"env": "ci"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if recipe.Vars["module"] != "auth" || recipe.Vars["env"] != "ci" {
+		t.Errorf("unexpected vars: %v", recipe.Vars)
+	}
+
+	// config becomes the first step, and default ids are based on each step's position in the file
+	var ids []string
+	for _, step := range recipe.Steps {
+		ids = append(ids, step.Id)
+	}
+	if strings.Join(ids, ",") != "config,step_1,step_2" {
+		t.Errorf("unexpected ids: %v", ids)
+	}
+
+	if recipe.Steps[0].Kind() != RecipeStepSetConfig {
+		t.Errorf("expected a set_config step first, got %s", recipe.Steps[0].Kind())
+	}
+	if apply := recipe.Steps[1].Apply; !apply.Enabled || apply.Commit || apply.Exec {
+		t.Errorf("unexpected apply options: %+v", apply)
+	}
+	if tries := recipe.Steps[2].Debug.Tries; tries != RecipeDefaultDebugTries {
+		t.Errorf("expected %d default debug tries, got %d", RecipeDefaultDebugTries, tries)
+	}
+}
+
+func TestRenderRecipeString(t *testing.T) {
+	t.Setenv("RECIPE_TEST_ENV", "from env")
+
+	exitCode := 2
+	recipe := &Recipe{Vars: map[string]string{"module": "auth"}}
+	report := &RecipeReport{
+		Steps: []*RecipeStepResult{
+			{Id: "tests", Status: RecipeStepFailed, ExitCode: &exitCode, Output: "1 failed"},
+			{Id: "plan", Status: RecipeStepSucceeded},
+		},
+	}
+
+	tests := []struct {
+		name    string
+		s       string
+		want    string
+		wantErr bool
+	}{
+		{name: "no template", s: "plain {text}", want: "plain {text}"},
+		{name: "var", s: "src/{{.vars.module}}", want: "src/auth"},
+		{name: "env", s: "{{.env.RECIPE_TEST_ENV}}", want: "from env"},
+		{name: "step output", s: "{{.steps.tests.output}}", want: "1 failed"},
+		{name: "step status and exit code", s: "{{.steps.tests.status}} {{.steps.tests.exitCode}}", want: "failed 2"},
+		{name: "missing exit code", s: "{{.steps.plan.exitCode}}", want: "0"},
+		{name: "missing var", s: "{{.vars.missing}}", wantErr: true},
+		{name: "missing step", s: "{{.steps.later.output}}", wantErr: true},
+		{name: "syntax error", s: "{{.vars.module", wantErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := RenderRecipeString(test.s, recipe, report)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("expected an error, got %q", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != test.want {
+				t.Errorf("got %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestRecipeCondition(t *testing.T) {
+	exitZero, exitOne := 0, 1
+	report := &RecipeReport{
+		Steps: []*RecipeStepResult{
+			{Id: "build", Status: RecipeStepSucceeded, ExitCode: &exitZero},
+			{Id: "tests", Status: RecipeStepFailed, ExitCode: &exitOne},
+			{Id: "lint", Status: RecipeStepSkipped},
+			{Id: "plan", Status: RecipeStepSucceeded},
+		},
+	}
+
+	tests := []struct {
+		cond    string
+		want    bool
+		wantErr bool
+	}{
+		{cond: "build.succeeded", want: true},
+		{cond: "build.failed", want: false},
+		{cond: "tests.failed", want: true},
+		{cond: "tests.skipped", want: false},
+		{cond: "lint.skipped", want: true},
+		{cond: "lint.succeeded", want: false},
+		{cond: "lint.exitCode == 0", want: false},
+		{cond: " tests.exitCode==1 ", want: true},
+		{cond: "tests.exitCode != 0", want: true},
+		{cond: "build.exitCode != 0", want: false},
+		{cond: "build.exitCode == -1", want: false},
+		// a step without an exit code doesn't match either comparison
+		{cond: "plan.exitCode == 0", want: false},
+		{cond: "plan.exitCode != 0", want: false},
+		{cond: "tests.exitCode", wantErr: true},
+		{cond: "tests.failed == 1", wantErr: true},
+		{cond: "tests.output", wantErr: true},
+		{cond: "later.succeeded", wantErr: true},
+		{cond: "", wantErr: true},
+	}
+
+	for _, test := range tests {
+		got, err := EvalRecipeCondition(test.cond, report)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("%q: expected an error", test.cond)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", test.cond, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%q: got %v, want %v", test.cond, got, test.want)
+		}
+	}
+}
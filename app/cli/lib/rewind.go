@@ -172,7 +172,7 @@ func AnalyzeRewind(targetState, currentState *shared.CurrentPlanState) (*RewindA
 			}
 
 			// Get the actual file content from disk
-			dstPath := filepath.Join(fs.ProjectRoot, path)
+			dstPath := fs.AbsPath(path)
 			diskContent, err := os.ReadFile(dstPath)
 			if err != nil {
 				if os.IsNotExist(err) {
@@ -261,13 +261,14 @@ func ApplyRewindChanges(requiredChanges map[string]string) error {
 	}
 
 	// Track directories that might need cleanup
-	dirsToCheck := make(map[string]bool)
+	// dirs to check, mapped to the root dir that cleanup stops at
+	dirsToCheck := make(map[string]string)
 
 	errCh := make(chan error, len(requiredChanges))
 
 	for path, content := range requiredChanges {
 		go func(path, content string) {
-			dstPath := filepath.Join(fs.ProjectRoot, path)
+			dstPath := fs.AbsPath(path)
 
 			if content == "" {
 				// Remove the file
@@ -278,7 +279,7 @@ func ApplyRewindChanges(requiredChanges map[string]string) error {
 				}
 				// Mark parent directory for cleanup
 				parentDir := filepath.Dir(dstPath)
-				dirsToCheck[parentDir] = true
+				dirsToCheck[parentDir] = fs.RootDirForPath(path)
 				errCh <- nil
 				return
 			}
@@ -307,8 +308,8 @@ func ApplyRewindChanges(requiredChanges map[string]string) error {
 	}
 
 	// Clean up empty directories
-	for dir := range dirsToCheck {
-		if err := RemoveEmptyDirs(dir, fs.ProjectRoot); err != nil {
+	for dir, rootDir := range dirsToCheck {
+		if err := RemoveEmptyDirs(dir, rootDir); err != nil {
 			// Log but don't fail the operation for directory cleanup errors
 			fmt.Printf("Warning: failed to clean up directory %s: %v\n", dir, err)
 		}
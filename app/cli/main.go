@@ -62,6 +62,9 @@ func init() {
 }
 
 func main() {
+	// with --json, output and prompts have to be headless before anything runs
+	cmd.ResolveJsonMode()
+
 	checkForUpgrade()
 
 	// Manually check for help flags at the root level
@@ -41,6 +41,14 @@ func getOnApplyExecFail(applyFlags types.ApplyFlags, tellFlags types.TellFlags,
 			}
 		}
 
+		// there's no one to ask with --json, so roll back rather than leave the failed changes applied
+		if !proceed && term.JsonMode {
+			if toRollback != nil && toRollback.HasChanges() {
+				lib.Rollback(toRollback, true)
+			}
+			term.OutputErrorAndExit("commands failed with exit status %d—changes were rolled back", status)
+		}
+
 		if !proceed {
 			const (
 				DebugAndRetry          = "Debug and retry once"
@@ -35,6 +35,7 @@ func TellPlan(
 	autoApply := flags.AutoApply
 	isApplyDebug := flags.IsApplyDebug
 	isImplementationOfChat := flags.IsImplementationOfChat
+	noMenu := flags.NoMenu
 	done := make(chan struct{})
 
 	if prompt == "" && isImplementationOfChat {
@@ -153,6 +154,10 @@ func TellPlan(
 		term.StopSpinner()
 
 		if apiErr != nil {
+			if term.JsonMode {
+				term.HandleApiError(apiErr)
+			}
+
 			if apiErr.Type == shared.ApiErrorTypeTrialMessagesExceeded {
 				fmt.Fprintf(os.Stderr, "\n🚨 You've reached the Plandex Cloud trial limit of %d messages per plan\n", apiErr.TrialMessagesExceededError.MaxReplies)
 
@@ -187,7 +192,7 @@ func TellPlan(
 				err := streamtui.StartStreamUI(
 					prompt,
 					false,
-					!(autoApply || autoContext || isApplyDebug || isDebugCmd),
+					!(autoApply || autoContext || isApplyDebug || isDebugCmd || noMenu),
 				)
 
 				if err != nil {
@@ -195,12 +200,18 @@ func TellPlan(
 					term.OutputErrorAndExit("Error starting stream UI: %v", err)
 				}
 
+				if term.JsonMode {
+					setTellJsonData(params, isChatOnly)
+					close(done)
+					return
+				}
+
 				if isChatOnly {
 					if !term.IsRepl {
 						term.PrintCmds("", "tell", "convo", "summary", "log")
 					}
-				} else if autoApply || isDebugCmd || isApplyDebug {
-					// do nothing, allow auto apply to run
+				} else if autoApply || isDebugCmd || isApplyDebug || noMenu {
+					// do nothing, allow auto apply (or the caller) to run
 				} else {
 					term.StartSpinner("")
 					diffs, apiErr := getDiffs(params)
@@ -242,6 +253,13 @@ func TellPlan(
 	}
 
 	if tellBg {
+		if term.JsonMode {
+			term.SetJsonData("planId", params.CurrentPlanId)
+			term.SetJsonData("branch", params.CurrentBranch)
+			term.SetJsonData("status", "background")
+			return
+		}
+
 		outputPromptIfTell()
 		fmt.Println("✅ Plan is active in the background")
 		fmt.Println()
@@ -250,3 +268,22 @@ func TellPlan(
 		<-done
 	}
 }
+
+// with --json, the reply and status come from the stream and the pending files are added here instead of showing the hotkey menu
+func setTellJsonData(params ExecParams, isChatOnly bool) {
+	term.SetJsonData("planId", params.CurrentPlanId)
+	term.SetJsonData("branch", params.CurrentBranch)
+
+	if isChatOnly {
+		return
+	}
+
+	diffs, apiErr := getDiffs(params)
+	if apiErr != nil {
+		term.OutputErrorAndExit("Error getting plan diffs: %v", apiErr.Msg)
+	}
+	if diffs == nil {
+		diffs = []string{}
+	}
+	term.SetJsonData("pendingFiles", diffs)
+}
@@ -0,0 +1,130 @@
+package reviewtui
+
+import (
+	shared "plandex-shared"
+)
+
+type Decision string
+
+const (
+	DecisionPending  Decision = ""
+	DecisionAccepted Decision = "accepted"
+	DecisionRejected Decision = "rejected"
+)
+
+// Hunk is a single reviewable change -- one pending replacement, or a whole pending result for new, rewritten, or removed files
+type Hunk struct {
+	Path     string
+	ResultId string
+	// empty for whole-result hunks
+	ReplacementId string
+	Summary       string
+	Old           string
+	New           string
+	NewFile       bool
+	RemovedFile   bool
+
+	Decision Decision
+	Edited   bool
+}
+
+func (h *Hunk) label() string {
+	if h.RemovedFile {
+		return "removed file"
+	}
+	if h.ReplacementId == "" {
+		if h.NewFile {
+			return "new file"
+		}
+		return "full rewrite"
+	}
+	return "change"
+}
+
+// HunksFromPlanState lists every pending hunk in the plan, in path order and then in the order the changes were made
+func HunksFromPlanState(state *shared.CurrentPlanState) []*Hunk {
+	planRes := state.PlanResult
+	var hunks []*Hunk
+
+	for _, path := range planRes.SortedPaths {
+		// the apply script is confirmed when it's executed rather than reviewed here
+		if path == "_apply.sh" {
+			continue
+		}
+
+		context := state.ContextsByPath[path]
+
+		for _, result := range planRes.FileResultsByPath[path] {
+			if !result.IsPending() {
+				continue
+			}
+
+			if result.RemovedFile || len(result.Replacements) == 0 {
+				hunk := &Hunk{
+					Path:        path,
+					ResultId:    result.Id,
+					New:         result.Content,
+					NewFile:     context == nil,
+					RemovedFile: result.RemovedFile,
+				}
+				if context != nil {
+					hunk.Old = context.Body
+				}
+				hunks = append(hunks, hunk)
+				continue
+			}
+
+			for _, replacement := range result.Replacements {
+				if !replacement.IsPending() {
+					continue
+				}
+
+				hunks = append(hunks, &Hunk{
+					Path:          path,
+					ResultId:      result.Id,
+					ReplacementId: replacement.Id,
+					Summary:       replacement.Summary,
+					Old:           shared.RemoveLineNums(shared.LineNumberedTextType(replacement.Old)),
+					New:           shared.RemoveLineNums(shared.LineNumberedTextType(replacement.New)),
+				})
+			}
+		}
+	}
+
+	return hunks
+}
+
+// RejectRequest collects the rejected and edited hunks from a finished review
+func RejectRequest(hunks []*Hunk) shared.RejectReplacementsRequest {
+	req := shared.RejectReplacementsRequest{}
+
+	for _, hunk := range hunks {
+		switch hunk.Decision {
+		case DecisionRejected:
+			req.Rejected = append(req.Rejected, shared.ReplacementRef{
+				ResultId:      hunk.ResultId,
+				ReplacementId: hunk.ReplacementId,
+			})
+		case DecisionAccepted:
+			if hunk.Edited {
+				req.Edited = append(req.Edited, shared.ReplacementEdit{
+					ResultId:      hunk.ResultId,
+					ReplacementId: hunk.ReplacementId,
+					New:           hunk.New,
+				})
+			}
+		}
+	}
+
+	return req
+}
+
+func NumAccepted(hunks []*Hunk) int {
+	num := 0
+	for _, hunk := range hunks {
+		if hunk.Decision == DecisionAccepted {
+			num++
+		}
+	}
+	return num
+}
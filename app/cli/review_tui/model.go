@@ -0,0 +1,112 @@
+package reviewtui
+
+import (
+	bubbleKey "github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+type reviewUIModel struct {
+	keymap keymap
+	editor string
+
+	hunks       []*Hunk
+	selectedIdx int
+
+	viewport viewport.Model
+
+	ready  bool
+	width  int
+	height int
+
+	// shown below the hunk until the next key press
+	status string
+
+	finished bool
+}
+
+type keymap = struct {
+	accept,
+	reject,
+	edit,
+	acceptRest,
+	next,
+	prev,
+	scrollUp,
+	scrollDown,
+	pageUp,
+	pageDown,
+	done,
+	quit bubbleKey.Binding
+}
+
+func initialModel(hunks []*Hunk, editor string) *reviewUIModel {
+	return &reviewUIModel{
+		hunks:  hunks,
+		editor: editor,
+		keymap: keymap{
+			accept: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("a", "y"),
+				bubbleKey.WithHelp("a", "accept"),
+			),
+			reject: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("r", "n"),
+				bubbleKey.WithHelp("r", "reject"),
+			),
+			edit: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("e"),
+				bubbleKey.WithHelp("e", "edit"),
+			),
+			acceptRest: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("A"),
+				bubbleKey.WithHelp("A", "accept remaining"),
+			),
+			next: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("j", "right", "tab"),
+				bubbleKey.WithHelp("j", "next"),
+			),
+			prev: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("k", "left", "shift+tab"),
+				bubbleKey.WithHelp("k", "prev"),
+			),
+			scrollDown: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("down"),
+				bubbleKey.WithHelp("down", "scroll down"),
+			),
+			scrollUp: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("up"),
+				bubbleKey.WithHelp("up", "scroll up"),
+			),
+			pageDown: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("d", "pgdown"),
+				bubbleKey.WithHelp("d", "page down"),
+			),
+			pageUp: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("u", "pgup"),
+				bubbleKey.WithHelp("u", "page up"),
+			),
+			done: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("enter"),
+				bubbleKey.WithHelp("enter", "done"),
+			),
+			quit: bubbleKey.NewBinding(
+				bubbleKey.WithKeys("q", "esc", "ctrl+c"),
+				bubbleKey.WithHelp("q", "quit"),
+			),
+		},
+	}
+}
+
+func (m *reviewUIModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *reviewUIModel) numPending() int {
+	num := 0
+	for _, hunk := range m.hunks {
+		if hunk.Decision == DecisionPending {
+			num++
+		}
+	}
+	return num
+}
@@ -0,0 +1,25 @@
+package reviewtui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// StartReviewUI walks through the hunks, recording a decision on each. It returns false if the review was quit before every hunk was decided.
+func StartReviewUI(hunks []*Hunk, editor string) (bool, error) {
+	if len(hunks) == 0 {
+		return true, nil
+	}
+
+	initial := initialModel(hunks, editor)
+
+	ui := tea.NewProgram(initial, tea.WithAltScreen())
+
+	m, err := ui.Run()
+	if err != nil {
+		return false, fmt.Errorf("error running review UI: %v", err)
+	}
+
+	return m.(*reviewUIModel).finished, nil
+}
@@ -0,0 +1,219 @@
+package reviewtui
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	bubbleKey "github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type editFinishedMsg struct {
+	idx      int
+	filename string
+	err      error
+}
+
+func (m *reviewUIModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+
+	switch msg := msg.(type) {
+
+	case tea.WindowSizeMsg:
+		m.windowResized(msg.Width, msg.Height)
+
+	case editFinishedMsg:
+		m.editFinished(msg)
+
+	case tea.KeyMsg:
+		m.status = ""
+
+		switch {
+		case bubbleKey.Matches(msg, m.keymap.quit):
+			return m, tea.Quit
+
+		case bubbleKey.Matches(msg, m.keymap.accept):
+			m.decide(DecisionAccepted)
+
+		case bubbleKey.Matches(msg, m.keymap.reject):
+			m.decide(DecisionRejected)
+
+		case bubbleKey.Matches(msg, m.keymap.edit):
+			cmd = m.startEdit()
+
+		case bubbleKey.Matches(msg, m.keymap.acceptRest):
+			for _, hunk := range m.hunks {
+				if hunk.Decision == DecisionPending {
+					hunk.Decision = DecisionAccepted
+				}
+			}
+			m.updateViewportContent()
+
+		case bubbleKey.Matches(msg, m.keymap.next):
+			m.selectHunk(m.selectedIdx + 1)
+
+		case bubbleKey.Matches(msg, m.keymap.prev):
+			m.selectHunk(m.selectedIdx - 1)
+
+		case bubbleKey.Matches(msg, m.keymap.scrollDown):
+			m.viewport.LineDown(1)
+
+		case bubbleKey.Matches(msg, m.keymap.scrollUp):
+			m.viewport.LineUp(1)
+
+		case bubbleKey.Matches(msg, m.keymap.pageDown):
+			m.viewport.ViewDown()
+
+		case bubbleKey.Matches(msg, m.keymap.pageUp):
+			m.viewport.ViewUp()
+
+		case bubbleKey.Matches(msg, m.keymap.done):
+			numPending := m.numPending()
+			if numPending == 0 {
+				m.finished = true
+				return m, tea.Quit
+			}
+
+			m.selectNextPending()
+			suffix := ""
+			if numPending > 1 {
+				suffix = "s"
+			}
+			m.status = fmt.Sprintf("%d change%s still need a decision — press A to accept the rest", numPending, suffix)
+		}
+	}
+
+	// the status line changes the height of the help area
+	if m.ready {
+		m.viewport.Height = m.viewportHeight()
+	}
+
+	return m, cmd
+}
+
+func (m *reviewUIModel) windowResized(w, h int) {
+	m.width = w
+	m.height = h
+
+	viewportHeight := m.viewportHeight()
+
+	if m.ready {
+		m.viewport.Width = w
+		m.viewport.Height = viewportHeight
+	} else {
+		m.viewport = viewport.New(w, viewportHeight)
+		m.viewport.Style = lipgloss.NewStyle().Padding(0, 1, 0, 1)
+		m.ready = true
+	}
+
+	m.updateViewportContent()
+}
+
+func (m *reviewUIModel) viewportHeight() int {
+	headerHeight := lipgloss.Height(m.renderHeader())
+	helpHeight := lipgloss.Height(m.renderHelp())
+	return max(m.height-headerHeight-helpHeight, 1)
+}
+
+func (m *reviewUIModel) decide(decision Decision) {
+	hunk := m.hunks[m.selectedIdx]
+	hunk.Decision = decision
+	m.selectNextPending()
+}
+
+// selectNextPending moves to the next hunk without a decision, wrapping around, or stays put if every hunk is decided
+func (m *reviewUIModel) selectNextPending() {
+	for i := 1; i <= len(m.hunks); i++ {
+		idx := (m.selectedIdx + i) % len(m.hunks)
+		if m.hunks[idx].Decision == DecisionPending {
+			m.selectHunk(idx)
+			return
+		}
+	}
+	m.updateViewportContent()
+}
+
+func (m *reviewUIModel) selectHunk(idx int) {
+	if idx < 0 || idx >= len(m.hunks) {
+		return
+	}
+	m.selectedIdx = idx
+	m.updateViewportContent()
+	m.viewport.GotoTop()
+}
+
+func (m *reviewUIModel) updateViewportContent() {
+	if !m.ready {
+		return
+	}
+	m.viewport.Height = m.viewportHeight()
+	m.viewport.SetContent(m.renderHunk())
+}
+
+func (m *reviewUIModel) startEdit() tea.Cmd {
+	hunk := m.hunks[m.selectedIdx]
+
+	if hunk.ReplacementId == "" {
+		m.status = "Only individual changes can be edited — accept or reject the whole file"
+		return nil
+	}
+
+	tempFile, err := os.CreateTemp(os.TempDir(), "plandex_review_*"+filepath.Ext(hunk.Path))
+	if err != nil {
+		m.status = fmt.Sprintf("Error creating temp file: %v", err)
+		return nil
+	}
+	filename := tempFile.Name()
+
+	_, err = tempFile.WriteString(hunk.New)
+	tempFile.Close()
+	if err != nil {
+		os.Remove(filename)
+		m.status = fmt.Sprintf("Error writing temp file: %v", err)
+		return nil
+	}
+
+	idx := m.selectedIdx
+	cmd := exec.Command(m.editor, filename)
+	return tea.ExecProcess(cmd, func(err error) tea.Msg {
+		return editFinishedMsg{idx: idx, filename: filename, err: err}
+	})
+}
+
+func (m *reviewUIModel) editFinished(msg editFinishedMsg) {
+	defer os.Remove(msg.filename)
+
+	if msg.err != nil {
+		m.status = fmt.Sprintf("Error opening editor: %v", msg.err)
+		return
+	}
+
+	bytes, err := os.ReadFile(msg.filename)
+	if err != nil {
+		m.status = fmt.Sprintf("Error reading edited change: %v", err)
+		return
+	}
+
+	hunk := m.hunks[msg.idx]
+	updated := string(bytes)
+
+	// most editors add a trailing newline on save
+	if !strings.HasSuffix(hunk.New, "\n") {
+		updated = strings.TrimSuffix(updated, "\n")
+	}
+
+	if updated != hunk.New {
+		hunk.New = updated
+		hunk.Edited = true
+	}
+
+	// editing a change means keeping it
+	hunk.Decision = DecisionAccepted
+	m.selectedIdx = msg.idx
+	m.selectNextPending()
+}
@@ -0,0 +1,101 @@
+package reviewtui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+var borderColor = lipgloss.Color("#444")
+var helpTextColor = lipgloss.Color("#ddd")
+var removedColor = lipgloss.Color("#ff6b6b")
+var addedColor = lipgloss.Color("#69db7c")
+var acceptedColor = lipgloss.Color("#69db7c")
+var rejectedColor = lipgloss.Color("#ff6b6b")
+var pendingColor = lipgloss.Color("#ffd43b")
+
+func (m *reviewUIModel) View() string {
+	if !m.ready {
+		return ""
+	}
+
+	return lipgloss.JoinVertical(lipgloss.Left,
+		m.renderHeader(),
+		m.viewport.View(),
+		m.renderHelp(),
+	)
+}
+
+func (m *reviewUIModel) renderHeader() string {
+	hunk := m.hunks[m.selectedIdx]
+
+	style := lipgloss.NewStyle().Width(m.width).BorderStyle(lipgloss.NormalBorder()).BorderBottom(true).BorderForeground(lipgloss.Color(borderColor))
+
+	head := fmt.Sprintf(" 🔍 %d/%d • 📄 %s • %s", m.selectedIdx+1, len(m.hunks), hunk.Path, hunk.label())
+
+	var decision string
+	switch hunk.Decision {
+	case DecisionAccepted:
+		label := "✅ accepted"
+		if hunk.Edited {
+			label += " (edited)"
+		}
+		decision = lipgloss.NewStyle().Foreground(acceptedColor).Render(label)
+	case DecisionRejected:
+		decision = lipgloss.NewStyle().Foreground(rejectedColor).Render("❌ rejected")
+	default:
+		decision = lipgloss.NewStyle().Foreground(pendingColor).Render("• pending")
+	}
+
+	rows := []string{head + " • " + decision}
+
+	if hunk.Summary != "" {
+		rows = append(rows, " "+hunk.Summary)
+	}
+
+	numPending := m.numPending()
+	progress := fmt.Sprintf(" %d accepted • %d rejected • %d pending", NumAccepted(m.hunks), len(m.hunks)-NumAccepted(m.hunks)-numPending, numPending)
+	rows = append(rows, lipgloss.NewStyle().Foreground(helpTextColor).Render(progress))
+
+	return style.Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+}
+
+func (m *reviewUIModel) renderHunk() string {
+	hunk := m.hunks[m.selectedIdx]
+	lineStyle := lipgloss.NewStyle().Width(m.width - 2)
+
+	var lines []string
+	addLines := func(s, prefix string, color lipgloss.Color) {
+		if s == "" {
+			return
+		}
+		style := lineStyle.Foreground(color)
+		for _, line := range strings.Split(strings.TrimSuffix(s, "\n"), "\n") {
+			lines = append(lines, style.Render(prefix+line))
+		}
+	}
+
+	addLines(hunk.Old, "- ", removedColor)
+	if !hunk.RemovedFile {
+		addLines(hunk.New, "+ ", addedColor)
+	}
+
+	if len(lines) == 0 {
+		lines = append(lines, lineStyle.Foreground(helpTextColor).Render("(empty)"))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (m *reviewUIModel) renderHelp() string {
+	style := lipgloss.NewStyle().Width(m.width).Foreground(lipgloss.Color(helpTextColor)).BorderStyle(lipgloss.NormalBorder()).BorderTop(true).BorderForeground(lipgloss.Color(borderColor))
+
+	s := " (a)ccept • (r)eject • (e)dit • (A)ccept remaining • (j/k) next/prev • (↑/↓) scroll • (enter) done • (q)uit"
+
+	if m.status != "" {
+		s = " " + m.status + "\n" + s
+	}
+
+	return style.Render(s)
+}
@@ -0,0 +1,166 @@
+package streamtui
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"plandex-cli/api"
+	"plandex-cli/fs"
+	"plandex-cli/lib"
+	"plandex-cli/term"
+	"sync"
+
+	shared "plandex-shared"
+)
+
+// with --json there's no UI: each stream message is written to stdout as a line of json, and prompts for missing files are answered by loading the file
+
+type headlessState struct {
+	reply   string
+	stopped bool
+	apiErr  *shared.ApiError
+	err     error
+	done    bool
+}
+
+var headlessMu sync.Mutex
+var headless headlessState
+var headlessDone = make(chan struct{})
+
+func sendHeadless(msg shared.StreamMessage) {
+	if msg.Type == shared.StreamMessageMulti {
+		for _, subMsg := range msg.StreamMessages {
+			sendHeadless(subMsg)
+		}
+		return
+	}
+
+	if msg.Type == shared.StreamMessageHeartbeat {
+		return
+	}
+
+	term.OutputJsonEvent(msg)
+
+	switch msg.Type {
+	case shared.StreamMessageConnectActive:
+		if len(msg.InitReplies) > 0 {
+			headlessMu.Lock()
+			headless.reply = ""
+			for i, reply := range msg.InitReplies {
+				if i > 0 {
+					headless.reply += "\n\n"
+				}
+				headless.reply += reply
+			}
+			headlessMu.Unlock()
+		}
+		if msg.MissingFilePath != "" {
+			go respondMissingFileHeadless(msg.MissingFilePath)
+		}
+
+	case shared.StreamMessagePromptMissingFile:
+		go respondMissingFileHeadless(msg.MissingFilePath)
+
+	case shared.StreamMessageReply:
+		headlessMu.Lock()
+		headless.reply += msg.ReplyChunk
+		headlessMu.Unlock()
+
+	case shared.StreamMessageLoadContext:
+		go loadContextHeadless(msg.LoadContextFiles)
+
+	case shared.StreamMessageError:
+		finishHeadless(func() {
+			headless.apiErr = msg.Error
+		})
+
+	case shared.StreamMessageFinished:
+		finishHeadless(func() {})
+
+	case shared.StreamMessageAborted:
+		finishHeadless(func() {
+			headless.stopped = true
+		})
+	}
+}
+
+func finishHeadless(fn func()) {
+	headlessMu.Lock()
+	defer headlessMu.Unlock()
+
+	fn()
+
+	if !headless.done {
+		headless.done = true
+		close(headlessDone)
+	}
+}
+
+func respondMissingFileHeadless(path string) {
+	log.Println("headless stream - loading missing file:", path)
+
+	bytes, err := os.ReadFile(fs.AbsPath(path))
+	if err != nil {
+		finishHeadless(func() {
+			headless.err = fmt.Errorf("failed to read file: %w", err)
+		})
+		return
+	}
+
+	apiErr := api.Client.RespondMissingFile(lib.CurrentPlanId, lib.CurrentBranch, shared.RespondMissingFileRequest{
+		Choice:   shared.RespondMissingFileChoiceLoad,
+		FilePath: path,
+		Body:     string(bytes),
+	})
+
+	if apiErr != nil {
+		finishHeadless(func() {
+			headless.apiErr = apiErr
+		})
+	}
+}
+
+func loadContextHeadless(files []string) {
+	_, err := lib.AutoLoadContextFiles(context.Background(), files)
+	if err != nil {
+		finishHeadless(func() {
+			headless.err = fmt.Errorf("failed to auto load context files: %w", err)
+		})
+	}
+}
+
+func waitHeadless() error {
+	headlessMu.Lock()
+	done := headlessDone
+	headlessMu.Unlock()
+
+	<-done
+
+	// reset for the next stream, like when auto-debugging sends another prompt
+	headlessMu.Lock()
+	state := headless
+	headless = headlessState{}
+	headlessDone = make(chan struct{})
+	headlessMu.Unlock()
+
+	if state.err != nil {
+		term.OutputErrorAndExit(state.err.Error())
+	}
+
+	if state.apiErr != nil {
+		term.HandleApiError(state.apiErr)
+	}
+
+	term.SetJsonData("reply", state.reply)
+
+	if state.stopped {
+		term.SetJsonData("status", "stopped")
+		term.OutputJson()
+		os.Exit(0)
+	}
+
+	term.SetJsonData("status", "finished")
+
+	return nil
+}
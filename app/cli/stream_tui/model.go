@@ -79,6 +79,12 @@ type streamUIModel struct {
 	err    error
 	apiErr *shared.ApiError
 
+	// set when the server switches a role to its error fallback model
+	modelFallback *shared.ModelFallbackInfo
+
+	// set when a spend budget passes its warning threshold
+	budgetWarning *shared.BudgetStatus
+
 	updateDebouncer *UpdateDebouncer
 
 	autoLoadContextCancelFn context.CancelFunc
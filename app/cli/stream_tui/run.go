@@ -22,6 +22,10 @@ var prestartErr *shared.ApiError
 var prestartAbort bool
 
 func StartStreamUI(prompt string, buildOnly, canSendToBg bool) error {
+	if term.JsonMode {
+		return waitHeadless()
+	}
+
 	if prestartErr != nil {
 		log.Println("stream UI - prestart error: ", prestartErr)
 		term.HandleApiError(prestartErr)
@@ -125,6 +129,11 @@ func Quit() {
 }
 
 func Send(msg shared.StreamMessage) {
+	if term.JsonMode {
+		sendHeadless(msg)
+		return
+	}
+
 	if ui == nil {
 		log.Println("stream ui is nil")
 
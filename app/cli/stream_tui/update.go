@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"plandex-cli/api"
+	"plandex-cli/fs"
 	"plandex-cli/lib"
 	"plandex-cli/term"
 	"strings"
@@ -259,7 +260,17 @@ func (m *streamUIModel) getViewportDimensions() (int, int) {
 		processingHeight = lipgloss.Height(m.renderProcessing())
 	}
 
-	maxViewportHeight := h - (helpHeight + processingHeight + buildHeight)
+	var modelFallbackHeight int
+	if m.modelFallback != nil {
+		modelFallbackHeight = lipgloss.Height(m.renderModelFallback())
+	}
+
+	var budgetWarningHeight int
+	if m.budgetWarning != nil {
+		budgetWarningHeight = lipgloss.Height(m.renderBudgetWarning())
+	}
+
+	maxViewportHeight := h - (helpHeight + processingHeight + buildHeight + modelFallbackHeight + budgetWarningHeight)
 	viewportHeight := min(maxViewportHeight, lipgloss.Height(m.mainDisplay))
 	viewportWidth := w
 
@@ -515,6 +526,24 @@ func (m *streamUIModel) streamUpdate(msg *shared.StreamMessage, deferUIUpdate bo
 		})
 		return m, tea.Quit
 
+	case shared.StreamMessageModelFallback:
+		log.Println("Model fallback:", spew.Sdump(msg.ModelFallback))
+
+		m.updateState(func() {
+			m.modelFallback = msg.ModelFallback
+		})
+		m.updateViewportDimensions()
+		return m, m.Tick()
+
+	case shared.StreamMessageBudgetWarning:
+		log.Println("Budget warning:", spew.Sdump(msg.BudgetWarning))
+
+		m.updateState(func() {
+			m.budgetWarning = msg.BudgetWarning
+		})
+		m.updateViewportDimensions()
+		return m, m.Tick()
+
 	case shared.StreamMessageFinished:
 		m.updateState(func() {
 			m.finished = true
@@ -676,7 +705,7 @@ func (m *streamUIModel) checkMissingFile(msg *shared.StreamMessage) (tea.Model,
 					return spinner.TickMsg{}
 				},
 				func() tea.Msg {
-					bytes, err := os.ReadFile(msg.MissingFilePath)
+					bytes, err := os.ReadFile(fs.AbsPath(msg.MissingFilePath))
 					if err != nil {
 						log.Println("failed to read file:", err)
 						m.err = fmt.Errorf("failed to read file: %w", err)
@@ -711,7 +740,7 @@ func (m *streamUIModel) checkMissingFile(msg *shared.StreamMessage) (tea.Model,
 			m.missingFilePath = msg.MissingFilePath
 		})
 
-		bytes, err := os.ReadFile(m.missingFilePath)
+		bytes, err := os.ReadFile(fs.AbsPath(m.missingFilePath))
 		if err != nil {
 			log.Println("failed to read file:", err)
 			m.updateState(func() {
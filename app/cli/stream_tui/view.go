@@ -24,6 +24,12 @@ func (m streamUIModel) View() string {
 	if !m.buildOnly {
 		views = append(views, m.renderMainView())
 	}
+	if m.modelFallback != nil {
+		views = append(views, m.renderModelFallback())
+	}
+	if m.budgetWarning != nil {
+		views = append(views, m.renderBudgetWarning())
+	}
 	if m.processing || m.starting {
 		views = append(views, m.renderProcessing())
 	}
@@ -66,6 +72,23 @@ func (m streamUIModel) renderProcessing() string {
 	}
 }
 
+func (m streamUIModel) renderModelFallback() string {
+	if m.modelFallback == nil {
+		return ""
+	}
+	style := lipgloss.NewStyle().Width(m.width).Foreground(lipgloss.Color(helpTextColor))
+	fallback := m.modelFallback
+	return style.Render(fmt.Sprintf(" ⚠️  %s model %s failed → switched to error fallback %s", fallback.Role, fallback.FromModel, fallback.ToModel))
+}
+
+func (m streamUIModel) renderBudgetWarning() string {
+	if m.budgetWarning == nil {
+		return ""
+	}
+	style := lipgloss.NewStyle().Width(m.width).Foreground(lipgloss.Color(helpTextColor))
+	return style.Render(fmt.Sprintf(" 💸 Used %s", m.budgetWarning.String()))
+}
+
 func (m streamUIModel) renderBuild() string {
 	return m.doRenderBuild(false)
 }
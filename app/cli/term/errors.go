@@ -13,6 +13,7 @@ import (
 var openUnauthenticatedCloudURL func(msg, path string)
 var openAuthenticatedURL func(msg, path string)
 var convertTrial func()
+var onErrorExit func(msg string)
 
 func SetOpenUnauthenticatedCloudURLFn(fn func(msg, path string)) {
 	openUnauthenticatedCloudURL = fn
@@ -24,7 +25,26 @@ func SetConvertTrialFn(fn func()) {
 	convertTrial = fn
 }
 
+// SetOnErrorExitFn sets a function that's called with the error message right before the process exits on an error
+func SetOnErrorExitFn(fn func(msg string)) {
+	onErrorExit = fn
+}
+
+func runOnErrorExit(msg string) {
+	if onErrorExit == nil {
+		return
+	}
+	// cleared first so it only runs once, even if it exits itself
+	fn := onErrorExit
+	onErrorExit = nil
+	fn(msg)
+}
+
 func OutputNoOpenAIApiKeyMsgAndExit() {
+	if JsonMode {
+		OutputJsonErrorAndExit(string(shared.ApiErrorTypeOther), "OPENAI_API_KEY environment variable is not set")
+	}
+
 	fmt.Fprintln(os.Stderr, color.New(color.Bold, ColorHiRed).Sprintln("\n🚨 OPENAI_API_KEY environment variable is not set.")+color.New().Sprintln("\nSet it with:\n\nexport OPENAI_API_KEY=your-api-key\n\nThen try again.\n\n👉 If you don't have an OpenAI account, sign up here → https://platform.openai.com/signup\n\n🔑 Generate an api key here → https://platform.openai.com/api-keys"))
 	os.Exit(1)
 }
@@ -37,6 +57,11 @@ func OutputSimpleError(msg string, args ...interface{}) {
 func OutputErrorAndExit(msg string, args ...interface{}) {
 	StopSpinner()
 	msg = fmt.Sprintf(msg, args...)
+	runOnErrorExit(msg)
+
+	if JsonMode {
+		OutputJsonErrorAndExit(string(shared.ApiErrorTypeOther), msg)
+	}
 
 	displayMsg := ""
 	errorParts := strings.Split(msg, ": ")
@@ -93,11 +118,21 @@ func OutputErrorAndExit(msg string, args ...interface{}) {
 
 func OutputUnformattedErrorAndExit(msg string) {
 	StopSpinner()
+	runOnErrorExit(msg)
+
+	if JsonMode {
+		OutputJsonErrorAndExit(string(shared.ApiErrorTypeOther), msg)
+	}
+
 	fmt.Fprintln(os.Stderr, msg)
 	os.Exit(1)
 }
 
 func OutputNoCurrentPlanErrorAndExit() {
+	if JsonMode {
+		OutputJsonErrorAndExit(string(shared.ApiErrorTypeOther), "no current plan")
+	}
+
 	fmt.Println("🤷‍♂️ No current plan")
 	fmt.Println()
 	PrintCmds("", "new", "cd")
@@ -105,6 +140,17 @@ func OutputNoCurrentPlanErrorAndExit() {
 }
 
 func HandleApiError(apiError *shared.ApiError) {
+	runOnErrorExit(apiError.Msg)
+
+	// billing and trial errors prompt for what to do next, which isn't possible with --json
+	if JsonMode {
+		errType := apiError.Type
+		if errType == "" {
+			errType = shared.ApiErrorTypeOther
+		}
+		OutputJsonErrorAndExit(string(errType), apiError.Msg)
+	}
+
 	if apiError.Type == shared.ApiErrorTypeCloudSubscriptionPaused {
 		if apiError.BillingError.HasBillingPermission {
 			StopSpinner()
@@ -181,6 +227,15 @@ func HandleApiError(apiError *shared.ApiError) {
 		}
 	}
 
+	if apiError.Type == shared.ApiErrorTypeBudgetExceeded && apiError.BudgetExceededError != nil {
+		StopSpinner()
+		fmt.Fprintf(os.Stderr, "\n🚨 Budget reached—%s\n", apiError.BudgetExceededError.String())
+		fmt.Fprintln(os.Stderr, "The model call wasn't sent. Raise or remove the budget to continue.")
+		fmt.Println()
+		PrintCmds("", "budgets", "usage")
+		os.Exit(1)
+	}
+
 	if apiError.Type == shared.ApiErrorTypeTrialMessagesExceeded {
 		StopSpinner()
 		fmt.Fprintf(os.Stderr, "\n🚨 You've reached the Plandex Cloud trial limit of %d messages per plan\n", apiError.TrialMessagesExceededError.MaxReplies)
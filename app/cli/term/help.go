@@ -42,6 +42,13 @@ var CliCommands = []CmdConfig{
 	{"new --strong", "", fmt.Sprintf("start a new plan with %s model pack", "'strong'"), true},
 	{"new --cheap", "", fmt.Sprintf("start a new plan with %s model pack", "'cheap'"), true},
 	{"new --oss", "", fmt.Sprintf("start a new plan with %s model pack", "'oss'"), true},
+	{"new --template", "", "start a new plan from one of your org's plan templates", true},
+
+	{"templates", "", "list your org's plan templates", true},
+	{"templates show", "", "show a plan template as YAML", true},
+	{"templates create", "", "create a plan template from a YAML file", true},
+	{"templates update", "", "replace a plan template with a YAML file", true},
+	{"templates rm", "", "delete a plan template", true},
 
 	{"plans", "pl", "list plans", true},
 	{"cd", "", "set current plan by name or index", true},
@@ -49,6 +56,10 @@ var CliCommands = []CmdConfig{
 	{"rename", "", "rename the current plan", true},
 	{"delete-plan", "dp", "delete plan by name or index", true},
 
+	{"project", "", "show the project's roots", true},
+	{"project add-root", "", "add another directory or repo to the project", true},
+	{"project rm-root", "", "remove an additional root from the project", true},
+
 	{"config", "", "show current plan config", true},
 	{"set-config", "", "update current plan config", true},
 	{"config default", "", "show the default config for new plans", true},
@@ -66,7 +77,7 @@ var CliCommands = []CmdConfig{
 	{"tell", "t", "describe a task to complete", false},
 	{"chat", "ch", "ask a question or chat", false},
 
-	{"load", "l", "load files/dirs/urls/notes/images or pipe data into context", true},
+	{"load", "l", "load files/dirs/symbols/urls/notes/images or pipe data into context", true},
 	{"ls", "", "list everything in context", true},
 	{"rm", "", "remove context by index, range, name, or glob", true},
 	{"clear", "", "remove all context", true},
@@ -80,6 +91,7 @@ var CliCommands = []CmdConfig{
 
 	{"apply", "ap", "apply pending changes to project files", true},
 	{"reject", "rj", "reject pending changes to one or more project files", true},
+	{"review", "rv", "accept, reject, or edit pending changes hunk by hunk", true},
 
 	{"log", "", "show log of plan updates", true},
 	{"rewind", "rw", "rewind to a previous state", true},
@@ -87,6 +99,7 @@ var CliCommands = []CmdConfig{
 	{"continue", "c", "continue the plan", true},
 	{"debug", "db", "repeatedly run a command and auto-apply fixes until it succeeds", true},
 	{"build", "b", "build any pending changes", true},
+	{"run", "", "run a recipe of context, prompt, apply, and exec steps", false},
 
 	{"convo", "", "show plan conversation", true},
 	{"convo 1", "", "show a specific message in the conversation", false},
@@ -96,10 +109,14 @@ var CliCommands = []CmdConfig{
 	{"branches", "br", "list plan branches", true},
 	{"checkout", "co", "checkout or create a branch", true},
 	{"delete-branch", "dlb", "delete a branch by name or index", true},
+	{"merge", "", "merge another branch into the current branch", true},
+	{"cherry-pick", "", "apply a single update from any branch to the current branch", true},
 
 	{"plans --archived", "", "list archived plans", true},
 	{"archive", "arc", "archive a plan", true},
 	{"unarchive", "unarc", "unarchive a plan", true},
+	{"export", "", "export a plan to a portable archive", true},
+	{"import", "", "import a plan from an archive", true},
 
 	{"models", "", "show current plan model settings", true},
 	{"models default", "", "show the default model settings for new plans", true},
@@ -107,10 +124,15 @@ var CliCommands = []CmdConfig{
 	{"models available --custom", "", "show available custom models only", true},
 	{"models delete", "", "delete a custom model", true},
 	{"models add", "", "add a custom model", true},
+	{"models discover", "", "add models from a local Ollama or llama.cpp server", true},
+	{"models export", "", "print custom models and model packs as YAML", true},
+	{"models sync", "", "make custom models and model packs match a YAML file", true},
 	{"model-packs", "", "show all available model packs", true},
 	{"model-packs create", "", "create a new custom model pack", true},
 	{"model-packs delete", "", "delete a custom model pack", true},
 	{"model-packs --custom", "", "show custom model packs only", true},
+	{"model-packs export", "", "print a model pack as YAML", true},
+	{"model-packs import", "", "create or update a custom model pack from a YAML file", true},
 	{"set-model", "", "update current plan model settings", true},
 	{"set-model default", "", "update the default model settings for new plans", true},
 
@@ -127,13 +149,35 @@ var CliCommands = []CmdConfig{
 	{"invite", "", "invite a user to join your org", true},
 	{"revoke", "", "revoke an invite or remove a user from your org", true},
 	{"users", "", "list users and pending invites in your org", true},
-
-	{"usage", "", "show Plandex Cloud current balance and usage report", true},
-	{"usage --today", "", "show Plandex Cloud usage for the day so far", true},
-	{"usage --month", "", "show Plandex Cloud usage for the current billing month", true},
-	{"usage --plan", "", "show Plandex Cloud usage for the current plan", true},
-
-	{"usage --log", "", "show Plandex Cloud transaction log", true},
+	{"tokens", "", "list your personal access tokens", true},
+	{"tokens create", "", "create a personal access token for PLANDEX_TOKEN auth", true},
+	{"tokens revoke", "", "revoke a personal access token", true},
+	{"sign-in --sso", "", "sign in with your org's single sign-on provider", true},
+	{"sso", "", "show your org's single sign-on config", true},
+	{"sso set", "", "configure single sign-on with an OIDC provider", true},
+	{"sso rm", "", "remove your org's single sign-on config", true},
+
+	{"exec-policy", "", "show the project and org policies for which commands can run", true},
+	{"exec-policy check", "", "check a script's commands against the exec policies", true},
+	{"exec-policy set-org", "", "set your org's exec policy from a YAML file", true},
+	{"exec-policy rm-org", "", "remove your org's exec policy", true},
+
+	{"webhooks", "", "list your org's webhooks", true},
+	{"webhooks add", "", "add a webhook that receives signed plan events", true},
+	{"webhooks rm", "", "delete a webhook", true},
+	{"webhooks deliveries", "", "show a webhook's recent deliveries", true},
+	{"webhooks test", "", "send a ping event to a webhook", true},
+
+	{"usage", "", "show spend and usage report (and current balance on Plandex Cloud)", true},
+	{"usage --today", "", "show usage for the day so far", true},
+	{"usage --month", "", "show usage for the current billing month (calendar month when self-hosted)", true},
+	{"usage --plan", "", "show usage for the current plan", true},
+
+	{"usage --log", "", "show log of model calls (transaction log on Plandex Cloud)", true},
+
+	{"budgets", "", "show your org's token and spend budgets", true},
+	{"budgets set", "", "set budgets per plan, per user per day, or per org per month", true},
+	{"budgets rm", "", "remove your org's budgets", true},
 
 	{"billing", "", "show Plandex Cloud billing settings", true},
 }
@@ -155,7 +199,7 @@ func PrintCmdsWithColors(prefix string, colors []color.Attribute, cmds ...string
 }
 
 func printCmds(w io.Writer, prefix string, colors []color.Attribute, cmds ...string) {
-	if os.Getenv("PLANDEX_DISABLE_SUGGESTIONS") != "" {
+	if os.Getenv("PLANDEX_DISABLE_SUGGESTIONS") != "" || JsonMode {
 		return
 	}
 
@@ -277,19 +321,23 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Plans ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new", "plans", "cd", "current", "delete-plan", "rename", "archive", "plans --archived", "unarchive")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new", "plans", "cd", "current", "delete-plan", "rename", "archive", "plans --archived", "unarchive", "export", "import")
+	fmt.Fprintln(builder)
+
+	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Templates ")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "new --template", "templates", "templates show", "templates create", "templates update", "templates rm")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Changes ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "diff", "diff --ui", "diff --plain", "apply", "reject")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "diff", "diff --ui", "diff --plain", "review", "apply", "reject")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Context ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "load", "ls", "rm", "update", "clear")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "load", "ls", "rm", "update", "clear", "project", "project add-root", "project rm-root")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Branches ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "branches", "checkout", "delete-branch")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "branches", "checkout", "delete-branch", "merge", "cherry-pick")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " History ")
@@ -297,7 +345,7 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Control ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "tell", "continue", "build", "debug", "chat")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "tell", "continue", "build", "debug", "chat", "run")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Streams ")
@@ -305,7 +353,7 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Config ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "config", "set-config", "config default", "set-config default")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "config", "set-config", "config default", "set-config default", "exec-policy")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Autonomy ")
@@ -317,15 +365,23 @@ func PrintHelpAllCommands() {
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Custom Models ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "models available", "models available --custom", "models add", "models delete", "model-packs --custom", "model-packs create", "model-packs delete")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "models available", "models available --custom", "models add", "models discover", "models delete", "models export", "models sync", "model-packs --custom", "model-packs create", "model-packs delete", "model-packs export", "model-packs import")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Accounts ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "sign-in", "invite", "revoke", "users")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "sign-in", "invite", "revoke", "users", "tokens", "tokens create", "tokens revoke", "sign-in --sso", "sso", "sso set", "sso rm")
+	fmt.Fprintln(builder)
+
+	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Webhooks ")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "webhooks", "webhooks add", "webhooks rm", "webhooks deliveries", "webhooks test")
+	fmt.Fprintln(builder)
+
+	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Usage ")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "usage", "usage --today", "usage --month", "usage --plan", "usage --log", "budgets", "budgets set", "budgets rm")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " Cloud ")
-	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "usage", "usage --today", "usage --month", "usage --plan", "usage --log", "billing")
+	printCmds(builder, " ", []color.Attribute{color.Bold, ColorHiCyan}, "billing")
 	fmt.Fprintln(builder)
 
 	color.New(color.Bold, color.BgCyan, color.FgHiWhite).Fprintln(builder, " New Plan Shortcuts ")
@@ -0,0 +1,185 @@
+package term
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sync"
+
+	shared "plandex-shared"
+
+	"github.com/fatih/color"
+)
+
+// JsonOutputVersion is bumped whenever a field is removed or changes meaning in the JSON output—new fields can be added without a bump
+const JsonOutputVersion = 1
+
+const JsonErrorTypeUsage = "usage"
+
+// exit codes used with --json so that automation can branch on the kind of failure
+const (
+	ExitCodeError      = 1
+	ExitCodeUsage      = 2
+	ExitCodeAuth       = 3
+	ExitCodeTrial      = 4
+	ExitCodeBilling    = 5
+	ExitCodeNoMessages = 6
+)
+
+var ErrJsonModePrompt = errors.New("input is required, but prompts are disabled with --json")
+
+// JsonMode is set by the global --json flag. Human-readable output goes to stderr, and stdout only gets JSON: stream events as they arrive (one per line) for streaming commands, followed by a single JSON document.
+var JsonMode bool
+
+type JsonOutput struct {
+	Version int            `json:"version"`
+	Command string         `json:"command"`
+	Ok      bool           `json:"ok"`
+	Data    map[string]any `json:"data,omitempty"`
+	Error   *JsonError     `json:"error,omitempty"`
+}
+
+type JsonError struct {
+	Type     string `json:"type"`
+	Msg      string `json:"msg"`
+	ExitCode int    `json:"exitCode"`
+}
+
+var jsonMu sync.Mutex
+var jsonOut io.Writer = os.Stdout
+var jsonCommand string
+var jsonData = map[string]any{}
+var jsonDone bool
+
+func EnableJsonMode() {
+	if JsonMode {
+		return
+	}
+	JsonMode = true
+
+	// keep the real stdout for json and send everything else to stderr
+	jsonOut = os.Stdout
+	os.Stdout = os.Stderr
+	color.Output = os.Stderr
+	color.NoColor = true
+}
+
+func SetJsonCommand(command string) {
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+	jsonCommand = command
+}
+
+// SetJsonData adds a field to the data object of the command's JSON document
+func SetJsonData(key string, value any) {
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+	jsonData[key] = value
+}
+
+// ClearJsonData drops any data set so far, for commands that run other commands and report their own result
+func ClearJsonData() {
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+	jsonData = map[string]any{}
+}
+
+// OutputJson writes the command's JSON document if it hasn't been written yet
+func OutputJson() {
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+
+	if jsonDone {
+		return
+	}
+
+	out := JsonOutput{
+		Version: JsonOutputVersion,
+		Command: jsonCommand,
+		Ok:      true,
+	}
+	if len(jsonData) > 0 {
+		out.Data = jsonData
+	}
+
+	writeJsonLine(out)
+	jsonDone = true
+}
+
+func OutputJsonEvent(msg shared.StreamMessage) {
+	jsonMu.Lock()
+	defer jsonMu.Unlock()
+
+	if jsonDone {
+		return
+	}
+
+	writeJsonLine(msg)
+}
+
+func OutputJsonErrorAndExit(errType, msg string) {
+	runOnErrorExit(msg)
+
+	exitCode := ExitCodeForErrorType(errType)
+
+	jsonMu.Lock()
+	if !jsonDone {
+		out := JsonOutput{
+			Version: JsonOutputVersion,
+			Command: jsonCommand,
+			Ok:      false,
+			Error: &JsonError{
+				Type:     errType,
+				Msg:      msg,
+				ExitCode: exitCode,
+			},
+		}
+		// include any partial results set before the error
+		if len(jsonData) > 0 {
+			out.Data = jsonData
+		}
+		writeJsonLine(out)
+		jsonDone = true
+	}
+	jsonMu.Unlock()
+
+	os.Exit(exitCode)
+}
+
+func ExitCodeForErrorType(errType string) int {
+	switch shared.ApiErrorType(errType) {
+	case shared.ApiErrorTypeInvalidToken:
+		return ExitCodeAuth
+	case shared.ApiErrorTypeTrialPlansExceeded,
+		shared.ApiErrorTypeTrialMessagesExceeded,
+		shared.ApiErrorTypeTrialActionNotAllowed:
+		return ExitCodeTrial
+	case shared.ApiErrorTypeCloudInsufficientCredits,
+		shared.ApiErrorTypeCloudMonthlyMaxReached,
+		shared.ApiErrorTypeCloudSubscriptionPaused,
+		shared.ApiErrorTypeCloudSubscriptionOverdue,
+		shared.ApiErrorTypeBudgetExceeded:
+		return ExitCodeBilling
+	case shared.ApiErrorTypeContinueNoMessages:
+		return ExitCodeNoMessages
+	}
+
+	if errType == JsonErrorTypeUsage {
+		return ExitCodeUsage
+	}
+
+	return ExitCodeError
+}
+
+// every document and event is a single line so stdout can be read as newline-delimited json
+func writeJsonLine(v any) {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Error marshalling json output: %v", err)
+		bytes = []byte(fmt.Sprintf(`{"version":%d,"command":%q,"ok":false,"error":{"type":"other","msg":"error marshalling json output","exitCode":%d}}`, JsonOutputVersion, jsonCommand, ExitCodeError))
+	}
+	fmt.Fprintln(jsonOut, string(bytes))
+}
@@ -0,0 +1,162 @@
+package term
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func resetJsonOutput(t *testing.T) *bytes.Buffer {
+	t.Helper()
+
+	var buf bytes.Buffer
+	prevOut := jsonOut
+	jsonOut = &buf
+	jsonCommand = ""
+	jsonData = map[string]any{}
+	jsonDone = false
+
+	t.Cleanup(func() {
+		jsonOut = prevOut
+		jsonCommand = ""
+		jsonData = map[string]any{}
+		jsonDone = false
+	})
+
+	return &buf
+}
+
+func TestExitCodeForErrorType(t *testing.T) {
+	tests := []struct {
+		errType string
+		want    int
+	}{
+		{JsonErrorTypeUsage, ExitCodeUsage},
+		{string(shared.ApiErrorTypeInvalidToken), ExitCodeAuth},
+		{string(shared.ApiErrorTypeTrialPlansExceeded), ExitCodeTrial},
+		{string(shared.ApiErrorTypeTrialMessagesExceeded), ExitCodeTrial},
+		{string(shared.ApiErrorTypeTrialActionNotAllowed), ExitCodeTrial},
+		{string(shared.ApiErrorTypeCloudInsufficientCredits), ExitCodeBilling},
+		{string(shared.ApiErrorTypeCloudMonthlyMaxReached), ExitCodeBilling},
+		{string(shared.ApiErrorTypeCloudSubscriptionPaused), ExitCodeBilling},
+		{string(shared.ApiErrorTypeCloudSubscriptionOverdue), ExitCodeBilling},
+		{string(shared.ApiErrorTypeBudgetExceeded), ExitCodeBilling},
+		{string(shared.ApiErrorTypeContinueNoMessages), ExitCodeNoMessages},
+		{string(shared.ApiErrorTypeOther), ExitCodeError},
+		{"", ExitCodeError},
+	}
+
+	for _, test := range tests {
+		if got := ExitCodeForErrorType(test.errType); got != test.want {
+			t.Errorf("ExitCodeForErrorType(%q) = %d, want %d", test.errType, got, test.want)
+		}
+	}
+}
+
+func TestOutputJson(t *testing.T) {
+	buf := resetJsonOutput(t)
+
+	SetJsonCommand("plans")
+	SetJsonData("plans", []string{"a", "b"})
+	SetJsonData("currentPlanId", "a")
+	OutputJson()
+
+	// only the first document is written
+	OutputJson()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("expected a single line of output, got %q", buf.String())
+	}
+
+	var out JsonOutput
+	if err := json.Unmarshal([]byte(lines[0]), &out); err != nil {
+		t.Fatalf("error unmarshalling output: %v", err)
+	}
+
+	if out.Version != JsonOutputVersion || out.Command != "plans" || !out.Ok || out.Error != nil {
+		t.Errorf("unexpected document: %+v", out)
+	}
+	if out.Data["currentPlanId"] != "a" || len(out.Data["plans"].([]any)) != 2 {
+		t.Errorf("unexpected data: %+v", out.Data)
+	}
+}
+
+func TestOutputJsonWithoutData(t *testing.T) {
+	buf := resetJsonOutput(t)
+
+	SetJsonCommand("budgets rm")
+	OutputJson()
+
+	want := `{"version":1,"command":"budgets rm","ok":true}` + "\n"
+	if buf.String() != want {
+		t.Errorf("got %q, want %q", buf.String(), want)
+	}
+}
+
+func TestOutputJsonEvents(t *testing.T) {
+	buf := resetJsonOutput(t)
+
+	SetJsonCommand("tell")
+	OutputJsonEvent(shared.StreamMessage{Type: shared.StreamMessageReply, ReplyChunk: "hello\nworld"})
+	OutputJsonEvent(shared.StreamMessage{Type: shared.StreamMessageFinished})
+	SetJsonData("status", "finished")
+	OutputJson()
+
+	// events after the final document are dropped
+	OutputJsonEvent(shared.StreamMessage{Type: shared.StreamMessageReply, ReplyChunk: "late"})
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 2 events and a document, got %d lines: %q", len(lines), buf.String())
+	}
+
+	var event shared.StreamMessage
+	if err := json.Unmarshal([]byte(lines[0]), &event); err != nil {
+		t.Fatalf("error unmarshalling event: %v", err)
+	}
+	if event.Type != shared.StreamMessageReply || event.ReplyChunk != "hello\nworld" {
+		t.Errorf("unexpected event: %+v", event)
+	}
+
+	// the final document is the only line with a version
+	for i, line := range lines {
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(line), &fields); err != nil {
+			t.Fatalf("line %d isn't valid json: %v", i, err)
+		}
+		_, hasVersion := fields["version"]
+		if hasVersion != (i == len(lines)-1) {
+			t.Errorf("line %d: unexpected version field in %s", i, line)
+		}
+	}
+
+	var out JsonOutput
+	if err := json.Unmarshal([]byte(lines[2]), &out); err != nil {
+		t.Fatalf("error unmarshalling document: %v", err)
+	}
+	if !out.Ok || out.Data["status"] != "finished" {
+		t.Errorf("unexpected document: %+v", out)
+	}
+}
+
+func TestClearJsonData(t *testing.T) {
+	buf := resetJsonOutput(t)
+
+	SetJsonCommand("run")
+	SetJsonData("reply", "from a nested command")
+	ClearJsonData()
+	SetJsonData("report", "done")
+	OutputJson()
+
+	var out JsonOutput
+	if err := json.Unmarshal(buf.Bytes(), &out); err != nil {
+		t.Fatalf("error unmarshalling output: %v", err)
+	}
+	if _, ok := out.Data["reply"]; ok || out.Data["report"] != "done" {
+		t.Errorf("unexpected data: %+v", out.Data)
+	}
+}
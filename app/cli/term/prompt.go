@@ -29,6 +29,10 @@ func GetUserStringInput(msg string) (string, error) {
 }
 
 func GetUserStringInputWithDefault(msg, def string) (string, error) {
+	if JsonMode {
+		return "", ErrJsonModePrompt
+	}
+
 	res, err := prompt.New().Ask(msg).Input(def)
 
 	if err != nil && err.Error() == "user quit prompt" {
@@ -53,6 +57,10 @@ func GetRequiredUserStringInputWithDefault(msg, def string) (string, error) {
 }
 
 func GetUserPasswordInput(msg string) (string, error) {
+	if JsonMode {
+		return "", ErrJsonModePrompt
+	}
+
 	res, err := prompt.New().Ask(msg).Input("", input.WithEchoMode(input.EchoPassword))
 
 	if err != nil && err.Error() == "user quit prompt" {
@@ -63,6 +71,10 @@ func GetUserPasswordInput(msg string) (string, error) {
 }
 
 func GetUserKeyInput() (rune, keyboard.Key, error) {
+	if JsonMode {
+		return 0, 0, ErrJsonModePrompt
+	}
+
 	if err := keyboard.Open(); err != nil {
 		return 0, 0, fmt.Errorf("failed to open keyboard: %s", err)
 	}
@@ -79,6 +91,10 @@ func GetUserKeyInput() (rune, keyboard.Key, error) {
 }
 
 func ConfirmYesNo(fmtStr string, fmtArgs ...interface{}) (bool, error) {
+	if JsonMode {
+		return false, ErrJsonModePrompt
+	}
+
 	color.New(ColorHiMagenta, color.Bold).Printf(fmtStr+" (y)es | (n)o", fmtArgs...)
 	color.New(ColorHiMagenta, color.Bold).Print("> ")
 
@@ -105,6 +121,10 @@ func ConfirmYesNo(fmtStr string, fmtArgs ...interface{}) (bool, error) {
 }
 
 func ConfirmYesNoCancel(fmtStr string, fmtArgs ...interface{}) (bool, bool, error) {
+	if JsonMode {
+		return false, false, ErrJsonModePrompt
+	}
+
 	color.New(ColorHiMagenta, color.Bold).Printf(fmtStr+" (y)es | (n)o | (c)ancel", fmtArgs...)
 	color.New(ColorHiMagenta, color.Bold).Print("> ")
 
@@ -9,6 +9,10 @@ import (
 )
 
 func SelectFromList(msg string, options []string) (string, error) {
+	if JsonMode {
+		return "", ErrJsonModePrompt
+	}
+
 	var selected string
 	prompt := &survey.Select{
 		Message:       color.New(ColorHiMagenta, color.Bold).Sprint(msg),
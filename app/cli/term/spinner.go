@@ -18,6 +18,10 @@ var active bool
 var currentWarningLoop int32
 
 func StartSpinner(msg string) {
+	if JsonMode {
+		return
+	}
+
 	if active {
 		if msg == lastMessage {
 			return
@@ -34,6 +38,10 @@ func StartSpinner(msg string) {
 }
 
 func StopSpinner() {
+	if JsonMode {
+		return
+	}
+
 	elapsed := time.Since(startedAt)
 
 	if lastMessage != "" && elapsed < withMessageMinDuration {
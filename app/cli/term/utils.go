@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -36,6 +37,12 @@ func ClearCurrentLine() {
 	fmt.Print("\033[2K")
 }
 
+var ansiRegex = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+func StripAnsi(s string) string {
+	return ansiRegex.ReplaceAllString(s, "")
+}
+
 func MoveUpLines(numLines int) {
 	fmt.Printf("\033[%dA", numLines)
 }
@@ -46,6 +53,12 @@ func BackToMain() {
 }
 
 func PageOutput(output string) {
+	// there's nothing to page with --json, so human-readable output just goes to stderr
+	if JsonMode {
+		fmt.Fprintln(os.Stderr, output)
+		return
+	}
+
 	cmd := exec.Command("less", "-R")
 	cmd.Env = append(os.Environ(), "LESS=FRX", "LESSCHARSET=utf-8")
 	cmd.Stdin = strings.NewReader(output)
@@ -58,6 +71,11 @@ func PageOutput(output string) {
 }
 
 func PageOutputReverse(output string) {
+	if JsonMode {
+		fmt.Fprintln(os.Stderr, output)
+		return
+	}
+
 	cmd := exec.Command("less", "-RX", "+G")
 	cmd.Stdin = strings.NewReader(output)
 	cmd.Stdout = os.Stdout
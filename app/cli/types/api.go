@@ -67,18 +67,23 @@ type ApiClient interface {
 	UnarchivePlan(planId string) *shared.ApiError
 	RenamePlan(planId string, name string) *shared.ApiError
 
+	ExportPlan(planId string) ([]byte, *shared.ApiError)
+	ImportPlan(projectId string, archive []byte, name string) (*shared.CreatePlanResponse, *shared.ApiError)
+
 	GetCurrentPlanState(planId, branch string) (*shared.CurrentPlanState, *shared.ApiError)
 	GetCurrentPlanStateAtSha(planId, sha string) (*shared.CurrentPlanState, *shared.ApiError)
 	ApplyPlan(planId, branch string, req shared.ApplyPlanRequest) (string, *shared.ApiError)
 	RejectAllChanges(planId, branch string) *shared.ApiError
 	RejectFile(planId, branch, filePath string) *shared.ApiError
 	RejectFiles(planId, branch string, paths []string) *shared.ApiError
+	RejectReplacements(planId, branch string, req shared.RejectReplacementsRequest) *shared.ApiError
 	GetPlanDiffs(planId, branch string, plain bool) (string, *shared.ApiError)
 
 	LoadContext(planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError)
 	UpdateContext(planId, branch string, req shared.UpdateContextRequest) (*shared.UpdateContextResponse, *shared.ApiError)
 	DeleteContext(planId, branch string, req shared.DeleteContextRequest) (*shared.DeleteContextResponse, *shared.ApiError)
 	ListContext(planId, branch string) ([]*shared.Context, *shared.ApiError)
+	SearchContext(planId, branch string, req shared.SearchContextRequest) (*shared.SearchContextResponse, *shared.ApiError)
 	LoadCachedFileMap(planId, branch string, req shared.LoadCachedFileMapRequest) (*shared.LoadCachedFileMapResponse, *shared.ApiError)
 
 	ListConvo(planId, branch string) ([]*shared.ConvoMessage, *shared.ApiError)
@@ -89,6 +94,8 @@ type ApiClient interface {
 	ListBranches(planId string) ([]*shared.Branch, *shared.ApiError)
 	DeleteBranch(planId, branch string) *shared.ApiError
 	CreateBranch(planId, branch string, req shared.CreateBranchRequest) *shared.ApiError
+	MergeBranch(planId, branch string, req shared.MergeBranchRequest) (*shared.MergeBranchResponse, *shared.ApiError)
+	CherryPick(planId, branch string, req shared.CherryPickRequest) (*shared.MergeBranchResponse, *shared.ApiError)
 
 	GetSettings(planId, branch string) (*shared.PlanSettings, *shared.ApiError)
 	UpdateSettings(planId, branch string, req shared.UpdateSettingsRequest) (*shared.UpdateSettingsResponse, *shared.ApiError)
@@ -108,12 +115,49 @@ type ApiClient interface {
 	CreateModelPack(set *shared.ModelPack) *shared.ApiError
 	ListModelPacks() ([]*shared.ModelPack, *shared.ApiError)
 	DeleteModelPack(setId string) *shared.ApiError
+	UpdateModelPack(set *shared.ModelPack) *shared.ApiError
+	SyncModels(req shared.SyncModelsRequest) (*shared.SyncModelsResponse, *shared.ApiError)
 
 	GetCreditsTransactions(pageSize, pageNum int, req shared.CreditsLogRequest) (*shared.CreditsLogResponse, *shared.ApiError)
 	GetCreditsSummary(req shared.CreditsLogRequest) (*shared.CreditsSummaryResponse, *shared.ApiError)
+	GetUsageReport(req shared.UsageRequest) (*shared.UsageReportResponse, *shared.ApiError)
+	GetUsageLog(pageSize, pageNum int, req shared.UsageRequest) (*shared.UsageLogResponse, *shared.ApiError)
 
 	GetFileMap(req shared.GetFileMapRequest) (*shared.GetFileMapResponse, *shared.ApiError)
 	GetContextBody(planId, branch, contextId string) (*shared.GetContextBodyResponse, *shared.ApiError)
 	AutoLoadContext(ctx context.Context, planId, branch string, req shared.LoadContextRequest) (*shared.LoadContextResponse, *shared.ApiError)
 	GetBuildStatus(planId, branch string) (*shared.GetBuildStatusResponse, *shared.ApiError)
+
+	ListAccessTokens() ([]*shared.AccessToken, *shared.ApiError)
+	CreateAccessToken(req shared.CreateAccessTokenRequest) (*shared.CreateAccessTokenResponse, *shared.ApiError)
+	RevokeAccessToken(tokenId string) *shared.ApiError
+
+	StartSso(req shared.StartSsoRequest, customHost string) (*shared.StartSsoResponse, *shared.ApiError)
+	SsoSignIn(req shared.SsoSignInRequest, customHost string) (*shared.SessionResponse, *shared.ApiError)
+	StartSsoDevice(req shared.StartSsoDeviceRequest, customHost string) (*shared.StartSsoDeviceResponse, *shared.ApiError)
+	PollSsoDevice(req shared.SsoDevicePollRequest, customHost string) (*shared.SsoDevicePollResponse, *shared.ApiError)
+	GetSsoConfig() (*shared.SsoConfig, *shared.ApiError)
+	UpdateSsoConfig(req shared.UpdateSsoConfigRequest) (*shared.SsoConfig, *shared.ApiError)
+	DeleteSsoConfig() *shared.ApiError
+
+	GetOrgExecPolicy() (*shared.ExecPolicy, *shared.ApiError)
+	UpdateOrgExecPolicy(policy shared.ExecPolicy) (*shared.ExecPolicy, *shared.ApiError)
+	DeleteOrgExecPolicy() *shared.ApiError
+	CheckExecPolicy(req shared.CheckExecPolicyRequest) (*shared.CheckExecPolicyResponse, *shared.ApiError)
+
+	GetOrgBudgets() (*shared.SpendBudgets, *shared.ApiError)
+	UpdateOrgBudgets(budgets shared.SpendBudgets) (*shared.SpendBudgets, *shared.ApiError)
+	DeleteOrgBudgets() *shared.ApiError
+
+	ListPlanTemplates() ([]*shared.PlanTemplate, *shared.ApiError)
+	GetPlanTemplate(name string) (*shared.PlanTemplate, *shared.ApiError)
+	CreatePlanTemplate(template shared.PlanTemplate) (*shared.PlanTemplate, *shared.ApiError)
+	UpdatePlanTemplate(name string, template shared.PlanTemplate) (*shared.PlanTemplate, *shared.ApiError)
+	DeletePlanTemplate(name string) *shared.ApiError
+
+	ListWebhooks() ([]*shared.Webhook, *shared.ApiError)
+	CreateWebhook(req shared.CreateWebhookRequest) (*shared.CreateWebhookResponse, *shared.ApiError)
+	DeleteWebhook(webhookId string) *shared.ApiError
+	ListWebhookDeliveries(webhookId string) ([]*shared.WebhookDelivery, *shared.ApiError)
+	TestWebhook(webhookId string) (*shared.WebhookDelivery, *shared.ApiError)
 }
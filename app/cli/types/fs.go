@@ -11,3 +11,14 @@ type ProjectPaths struct {
 	IgnoredPaths   map[string]string
 	GitIgnoredDirs map[string]bool
 }
+
+// ProjectRoot is an additional directory (often a separate repo) that's part of the project. Paths in the root are namespaced with the root's name, like "web:src/app.ts".
+type ProjectRoot struct {
+	Name string `json:"name"`
+	// relative to the main project root
+	Path string `json:"path"`
+}
+
+type ProjectRootsConfig struct {
+	Roots []*ProjectRoot `json:"roots"`
+}
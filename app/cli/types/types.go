@@ -16,6 +16,10 @@ type LoadContextParams struct {
 	SkipIgnoreWarning bool
 	AutoLoaded        bool
 	SessionId         string
+	Symbols           []string
+	GitDiffRef        string
+	GitStaged         bool
+	GitUncommitted    bool
 }
 
 type ContextOutdatedResult struct {
@@ -27,6 +31,7 @@ type ContextOutdatedResult struct {
 	NumUrls         int
 	NumTrees        int
 	NumMaps         int
+	NumDiffs        int
 	NumFilesRemoved int
 	NumTreesRemoved int
 	ReqFn           func() (map[string]*shared.UpdateContextParams, error)
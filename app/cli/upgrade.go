@@ -33,6 +33,11 @@ func checkForUpgrade() {
 		return
 	}
 
+	// don't upgrade (or prompt to) in the middle of automation
+	if term.JsonMode {
+		return
+	}
+
 	term.StartSpinner("")
 	defer term.StopSpinner()
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -0,0 +1,112 @@
+package db
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/pkg/errors"
+)
+
+// last_used_at is only written this often so authenticating with a token doesn't mean a write on every request
+const accessTokenLastUsedInterval = time.Minute
+
+// CreateAccessToken stores a new personal access token and returns the token itself, which is only kept as a hash
+func CreateAccessToken(accessToken *AccessToken) (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", fmt.Errorf("error generating access token: %v", err)
+	}
+
+	token := shared.AccessTokenPrefix + hex.EncodeToString(bytes)
+	accessToken.TokenHash = hashAccessToken(token)
+
+	err = Conn.QueryRow(
+		"INSERT INTO access_tokens (org_id, user_id, name, token_hash, permissions, expires_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at",
+		accessToken.OrgId,
+		accessToken.UserId,
+		accessToken.Name,
+		accessToken.TokenHash,
+		accessToken.Permissions,
+		accessToken.ExpiresAt,
+	).Scan(&accessToken.Id, &accessToken.CreatedAt, &accessToken.UpdatedAt)
+
+	if err != nil {
+		return "", fmt.Errorf("error creating access token: %v", err)
+	}
+
+	return token, nil
+}
+
+func ValidateAccessToken(token string) (*AccessToken, error) {
+	var accessToken AccessToken
+	err := Conn.Get(&accessToken, "SELECT * FROM access_tokens WHERE token_hash = $1 AND revoked_at IS NULL", hashAccessToken(token))
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			log.Println("access token error - no rows found")
+			return nil, errors.New("invalid token")
+		}
+
+		return nil, fmt.Errorf("error validating access token: %v", err)
+	}
+
+	now := time.Now()
+
+	if accessToken.ExpiresAt != nil && accessToken.ExpiresAt.Before(now) {
+		log.Println("access token error - expired")
+		return nil, errors.New("invalid token")
+	}
+
+	if accessToken.LastUsedAt == nil || now.Sub(*accessToken.LastUsedAt) > accessTokenLastUsedInterval {
+		_, err = Conn.Exec("UPDATE access_tokens SET last_used_at = $1 WHERE id = $2", now, accessToken.Id)
+		if err != nil {
+			// not worth failing the request over
+			log.Printf("Error updating access token last used at: %v\n", err)
+		}
+	}
+
+	return &accessToken, nil
+}
+
+func ListAccessTokens(orgId, userId string) ([]*AccessToken, error) {
+	var accessTokens []*AccessToken
+	err := Conn.Select(&accessTokens, "SELECT * FROM access_tokens WHERE org_id = $1 AND user_id = $2 AND revoked_at IS NULL ORDER BY created_at", orgId, userId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing access tokens: %v", err)
+	}
+
+	return accessTokens, nil
+}
+
+func RevokeAccessToken(orgId, userId, id string) error {
+	res, err := Conn.Exec("UPDATE access_tokens SET revoked_at = $1 WHERE org_id = $2 AND user_id = $3 AND id = $4 AND revoked_at IS NULL", time.Now(), orgId, userId, id)
+
+	if err != nil {
+		return fmt.Errorf("error revoking access token: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("access token not found")
+	}
+
+	return nil
+}
+
+func hashAccessToken(token string) string {
+	hashBytes := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hashBytes[:])
+}
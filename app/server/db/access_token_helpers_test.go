@@ -0,0 +1,102 @@
+package db
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func TestAccessTokens(t *testing.T) {
+	user, org, _ := setupSqliteTestDb(t)
+
+	accessToken := &AccessToken{
+		OrgId:       org.Id,
+		UserId:      user.Id,
+		Name:        "ci",
+		Permissions: shared.PermissionList{shared.PermissionCreatePlan},
+	}
+	token, err := CreateAccessToken(accessToken)
+	if err != nil {
+		t.Fatalf("error creating access token: %v", err)
+	}
+
+	if !shared.IsAccessToken(token) {
+		t.Errorf("expected token with access token prefix, got %s", token)
+	}
+	if accessToken.TokenHash == "" || strings.Contains(token, accessToken.TokenHash) {
+		t.Error("expected only the token hash to be stored")
+	}
+
+	validated, err := ValidateAccessToken(token)
+	if err != nil {
+		t.Fatalf("error validating access token: %v", err)
+	}
+	if validated.Id != accessToken.Id || validated.OrgId != org.Id || validated.UserId != user.Id {
+		t.Errorf("unexpected validated token: %+v", validated)
+	}
+	if len(validated.Permissions) != 1 || validated.Permissions[0] != shared.PermissionCreatePlan {
+		t.Errorf("expected stored permissions, got %v", validated.Permissions)
+	}
+
+	// owners have every permission, so the token's list is what limits them
+	permissions, err := GetUserPermissions(user.Id, org.Id)
+	if err != nil {
+		t.Fatalf("error getting permissions: %v", err)
+	}
+	perms := shared.Permissions{}
+	for _, p := range permissions {
+		perms[p] = true
+	}
+	scoped := validated.Permissions.Scope(perms)
+	if !scoped.HasPermission(shared.PermissionCreatePlan) || scoped.HasPermission(shared.PermissionDeleteOrg) {
+		t.Errorf("expected permissions scoped to create_plan, got %v", scoped)
+	}
+
+	if _, err := ValidateAccessToken(shared.AccessTokenPrefix + "invalid"); err == nil {
+		t.Error("expected error validating unknown token")
+	}
+
+	expiresAt := time.Now().Add(-time.Hour)
+	expired := &AccessToken{
+		OrgId:     org.Id,
+		UserId:    user.Id,
+		Name:      "expired",
+		ExpiresAt: &expiresAt,
+	}
+	expiredToken, err := CreateAccessToken(expired)
+	if err != nil {
+		t.Fatalf("error creating access token: %v", err)
+	}
+	if _, err := ValidateAccessToken(expiredToken); err == nil {
+		t.Error("expected error validating expired token")
+	}
+
+	tokens, err := ListAccessTokens(org.Id, user.Id)
+	if err != nil {
+		t.Fatalf("error listing access tokens: %v", err)
+	}
+	if len(tokens) != 2 {
+		t.Fatalf("expected 2 access tokens, got %d", len(tokens))
+	}
+
+	err = RevokeAccessToken(org.Id, user.Id, accessToken.Id)
+	if err != nil {
+		t.Fatalf("error revoking access token: %v", err)
+	}
+	if _, err := ValidateAccessToken(token); err == nil {
+		t.Error("expected error validating revoked token")
+	}
+	if err := RevokeAccessToken(org.Id, user.Id, accessToken.Id); err == nil {
+		t.Error("expected error revoking an already revoked token")
+	}
+
+	tokens, err = ListAccessTokens(org.Id, user.Id)
+	if err != nil {
+		t.Fatalf("error listing access tokens: %v", err)
+	}
+	if len(tokens) != 1 || tokens[0].Id != expired.Id {
+		t.Errorf("expected only the expired token to still be listed, got %+v", tokens)
+	}
+}
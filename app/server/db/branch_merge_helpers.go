@@ -0,0 +1,365 @@
+package db
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+)
+
+var shaRegex = regexp.MustCompile(`^[0-9a-f]{4,40}$`)
+
+type MergeParams struct {
+	Repo       *GitRepo
+	OrgId      string
+	PlanId     string
+	Branch     string
+	Resolution shared.MergeResolution
+}
+
+type mergeSource struct {
+	sha           string
+	baseSha       string
+	isMergeCommit bool
+	cherryPick    bool
+	commitMsg     string
+}
+
+// MergeBranch merges another branch's conversation, context, and pending changes into params.Branch, which must already be checked out by the repo lock. If both branches have pending changes to the same paths that can't be applied on top of each other, the conflicted paths are returned and nothing is merged unless params.Resolution says which side to keep.
+func MergeBranch(params MergeParams, sourceBranch string) (*shared.MergeBranchResponse, error) {
+	repo := params.Repo
+
+	sha, err := repo.GitResolveCommit("refs/heads/" + sourceBranch)
+	if err != nil {
+		return nil, err
+	}
+
+	upToDate, err := repo.GitIsAncestor(sha)
+	if err != nil {
+		return nil, err
+	}
+	if upToDate {
+		return &shared.MergeBranchResponse{UpToDate: true}, nil
+	}
+
+	baseSha, err := repo.GitMergeBase(sha)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeIntoBranch(params, mergeSource{
+		sha:       sha,
+		baseSha:   baseSha,
+		commitMsg: fmt.Sprintf("🔀 Merged branch '%s' into '%s'", sourceBranch, params.Branch),
+	})
+}
+
+// CherryPickCommit applies the changes from a single plan commit (from any branch) to params.Branch, handling conflicts the same way as MergeBranch
+func CherryPickCommit(params MergeParams, sha string) (*shared.MergeBranchResponse, error) {
+	repo := params.Repo
+
+	if !shaRegex.MatchString(sha) {
+		return nil, fmt.Errorf("invalid commit sha: %s", sha)
+	}
+
+	fullSha, err := repo.GitResolveCommit(sha)
+	if err != nil {
+		return nil, err
+	}
+
+	upToDate, err := repo.GitIsAncestor(fullSha)
+	if err != nil {
+		return nil, err
+	}
+	if upToDate {
+		return &shared.MergeBranchResponse{UpToDate: true}, nil
+	}
+
+	parents, err := repo.GitParentShas(fullSha)
+	if err != nil {
+		return nil, err
+	}
+	if len(parents) == 0 {
+		return nil, fmt.Errorf("can't cherry-pick the plan's initial commit")
+	}
+
+	msg, err := repo.GitShowCommitMessage(fullSha)
+	if err != nil {
+		return nil, err
+	}
+
+	commitMsg := fmt.Sprintf("🍒 Cherry-picked %s", sha)
+	if msg != "" {
+		commitMsg += "\n\n" + msg
+	}
+
+	return mergeIntoBranch(params, mergeSource{
+		sha:           fullSha,
+		baseSha:       parents[0],
+		isMergeCommit: len(parents) > 1,
+		cherryPick:    true,
+		commitMsg:     commitMsg,
+	})
+}
+
+func mergeIntoBranch(params MergeParams, source mergeSource) (*shared.MergeBranchResponse, error) {
+	repo := params.Repo
+	orgId := params.OrgId
+	planId := params.PlanId
+
+	res := &shared.MergeBranchResponse{}
+
+	incomingPaths, err := repo.GitDiffPaths(source.baseSha, source.sha)
+	if err != nil {
+		return nil, err
+	}
+
+	planDir := getPlanDir(orgId, planId)
+
+	var incomingContextIds []string
+	var incomingResults []*PlanFileResult
+
+	for _, path := range incomingPaths {
+		// records that both branches already share are merged at the file level -- only new ones are counted and checked for conflicts
+		if _, err := os.Stat(filepath.Join(planDir, path)); err == nil {
+			continue
+		}
+
+		dir, name, _ := strings.Cut(path, "/")
+
+		switch dir {
+		case "conversation":
+			res.NumMessages++
+
+		case "context":
+			if strings.HasSuffix(name, ".meta") {
+				incomingContextIds = append(incomingContextIds, strings.TrimSuffix(name, ".meta"))
+			}
+
+		case "results":
+			bytes, err := repo.GitShowFile(source.sha, path)
+			if err != nil {
+				return nil, err
+			}
+
+			var result PlanFileResult
+			err = json.Unmarshal(bytes, &result)
+			if err != nil {
+				return nil, fmt.Errorf("error unmarshalling result file %s: %v", path, err)
+			}
+
+			if result.ToApi().IsPending() {
+				incomingResults = append(incomingResults, &result)
+			}
+		}
+	}
+
+	targetResults, err := GetPlanFileResults(orgId, planId)
+	if err != nil {
+		return nil, fmt.Errorf("error getting plan file results: %v", err)
+	}
+
+	targetState, err := GetCurrentPlanState(CurrentPlanStateParams{
+		OrgId:           orgId,
+		PlanId:          planId,
+		PlanFileResults: targetResults,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting current plan state: %v", err)
+	}
+
+	conflictedPaths := getMergeConflictedPaths(targetState, incomingResults)
+
+	for path := range conflictedPaths {
+		res.ConflictedPaths = append(res.ConflictedPaths, path)
+	}
+	sort.Strings(res.ConflictedPaths)
+
+	if len(conflictedPaths) > 0 && params.Resolution == shared.MergeResolutionNone {
+		log.Printf("[Merge] %s | conflicted paths without a resolution: %v", planId, res.ConflictedPaths)
+		return res, nil
+	}
+
+	targetContexts, err := GetPlanContexts(orgId, planId, false, false)
+	if err != nil {
+		return nil, fmt.Errorf("error getting contexts: %v", err)
+	}
+
+	if source.cherryPick {
+		err = repo.GitCherryPickNoCommit(source.sha, source.isMergeCommit)
+	} else {
+		err = repo.GitMergeNoCommit(source.sha)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if source.cherryPick {
+		hasChanges, err := repo.GitHasStagedChanges()
+		if err != nil {
+			return nil, err
+		}
+		if !hasChanges {
+			err = repo.GitClearUncommittedChanges(params.Branch)
+			if err != nil {
+				return nil, err
+			}
+			return &shared.MergeBranchResponse{UpToDate: true}, nil
+		}
+	}
+
+	// reject the pending changes on the side that isn't kept for each conflicted path
+	var toReject []string
+	switch params.Resolution {
+	case shared.MergeResolutionOurs:
+		for _, result := range incomingResults {
+			if conflictedPaths[result.Path] {
+				toReject = append(toReject, result.Id)
+			}
+		}
+	case shared.MergeResolutionTheirs:
+		for _, result := range targetResults {
+			if conflictedPaths[result.Path] && result.ToApi().IsPending() {
+				toReject = append(toReject, result.Id)
+			}
+		}
+	}
+
+	err = rejectResultsById(orgId, planId, toReject, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, result := range incomingResults {
+		if params.Resolution != shared.MergeResolutionOurs || !conflictedPaths[result.Path] {
+			res.NumResults++
+		}
+	}
+
+	res.NumContexts, err = removeDuplicateMergedContexts(orgId, planId, targetContexts, incomingContextIds)
+	if err != nil {
+		return nil, err
+	}
+
+	err = repo.GitAddAndCommit(params.Branch, source.commitMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	err = SyncPlanTokens(orgId, planId, params.Branch)
+	if err != nil {
+		return nil, fmt.Errorf("error syncing plan tokens: %v", err)
+	}
+
+	res.Merged = true
+
+	return res, nil
+}
+
+// getMergeConflictedPaths checks whether incoming pending changes still apply on top of the target branch's version of each path that also has pending changes on the target
+func getMergeConflictedPaths(targetState *shared.CurrentPlanState, incomingResults []*PlanFileResult) map[string]bool {
+	var apiResults []*shared.PlanFileResult
+	for _, result := range incomingResults {
+		apiResults = append(apiResults, result.ToApi())
+	}
+	incomingPlanResult := GetPlanResult(apiResults)
+
+	filesByPath := map[string]string{}
+	fullFileConflicts := map[string]bool{}
+	for path, results := range incomingPlanResult.FileResultsByPath {
+		if len(targetState.PlanResult.FileResultsByPath[path]) == 0 {
+			continue
+		}
+
+		filesByPath[path] = targetState.CurrentPlanFiles.Files[path]
+
+		// full-file replacements and removals can't be stacked on the target's changes
+		for _, result := range results {
+			if len(result.Replacements) == 0 {
+				fullFileConflicts[path] = true
+				break
+			}
+		}
+	}
+
+	conflictedPaths := incomingPlanResult.FileResultsByPath.ConflictedPaths(filesByPath)
+
+	for path := range fullFileConflicts {
+		conflictedPaths[path] = true
+	}
+
+	return conflictedPaths
+}
+
+func rejectResultsById(orgId, planId string, ids []string, now time.Time) error {
+	for _, id := range ids {
+		result, err := GetPlanFileResultById(orgId, planId, id)
+		if err != nil {
+			return err
+		}
+
+		if !result.ToApi().IsPending() {
+			continue
+		}
+
+		result.RejectedAt = &now
+
+		err = StorePlanResult(result)
+		if err != nil {
+			return fmt.Errorf("error rejecting result %s: %v", id, err)
+		}
+	}
+
+	return nil
+}
+
+// removeDuplicateMergedContexts drops newly merged contexts that the target branch already has loaded for the same file, url, or symbol, keeping the target's version. It returns the number of merged contexts that were kept.
+func removeDuplicateMergedContexts(orgId, planId string, targetContexts []*Context, incomingIds []string) (int, error) {
+	contextKey := func(context *Context) string {
+		if context.FilePath == "" && context.Url == "" {
+			return ""
+		}
+		return strings.Join([]string{string(context.ContextType), context.FilePath, context.Url, context.Symbol, context.GitRef}, "|")
+	}
+
+	targetKeys := map[string]bool{}
+	for _, context := range targetContexts {
+		if key := contextKey(context); key != "" {
+			targetKeys[key] = true
+		}
+	}
+
+	contextDir := getPlanContextDir(orgId, planId)
+	numKept := 0
+
+	for _, id := range incomingIds {
+		context, err := GetContext(orgId, planId, id, false, false)
+		if err != nil {
+			return 0, fmt.Errorf("error getting merged context: %v", err)
+		}
+
+		key := contextKey(context)
+		if key == "" || !targetKeys[key] {
+			numKept++
+			continue
+		}
+
+		log.Printf("[Merge] %s | removing duplicate merged context %s (%s)", planId, id, context.Name)
+
+		for _, ext := range []string{".meta", ".body", ".map-parts"} {
+			err := os.Remove(filepath.Join(contextDir, id+ext))
+			if err != nil && !os.IsNotExist(err) {
+				return 0, fmt.Errorf("error removing context file: %v", err)
+			}
+		}
+	}
+
+	return numKept, nil
+}
@@ -0,0 +1,170 @@
+package db
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestMergeBranchAndCherryPick(t *testing.T) {
+	user, org, projectId := setupSqliteTestDb(t)
+	ctx := context.Background()
+
+	plan, err := CreatePlan(ctx, org.Id, projectId, user.Id, "merged plan")
+	if err != nil {
+		t.Fatalf("error creating plan: %v", err)
+	}
+	repo := getGitRepo(org.Id, plan.Id)
+
+	writeMessage := func(id string) {
+		msg := `{"id":"` + id + `","orgId":"` + org.Id + `","planId":"` + plan.Id + `","userId":"` + user.Id + `","message":"` + id + `","tokens":1}`
+		err := os.WriteFile(filepath.Join(getPlanConversationDir(org.Id, plan.Id), id+".json"), []byte(msg), 0644)
+		if err != nil {
+			t.Fatalf("error writing convo message: %v", err)
+		}
+	}
+
+	storeResult := func(id, old, new string) {
+		err := StorePlanResult(&PlanFileResult{
+			Id:           id,
+			OrgId:        org.Id,
+			PlanId:       plan.Id,
+			Path:         "main.go",
+			Replacements: []*shared.Replacement{{Id: id + "-rep", Old: old, New: new}},
+		})
+		if err != nil {
+			t.Fatalf("error storing result: %v", err)
+		}
+	}
+
+	commit := func(branch, msg string) {
+		if err := repo.GitAddAndCommit(branch, msg); err != nil {
+			t.Fatalf("error committing: %v", err)
+		}
+	}
+
+	err = StoreContext(&Context{
+		OrgId:       org.Id,
+		PlanId:      plan.Id,
+		ContextType: shared.ContextFileType,
+		Name:        "main.go",
+		FilePath:    "main.go",
+		Body:        "package main\n\nfunc foo() {}\n",
+		NumTokens:   10,
+	}, true)
+	if err != nil {
+		t.Fatalf("error storing context: %v", err)
+	}
+	writeMessage("msg-1")
+	commit("main", "first message")
+
+	mainBranch, err := GetDbBranch(plan.Id, "main")
+	if err != nil {
+		t.Fatalf("error getting main branch: %v", err)
+	}
+	err = WithTx(ctx, "test create branch", func(tx *sqlx.Tx) error {
+		_, err := CreateBranch(repo, plan, mainBranch, "feature", tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error creating branch: %v", err)
+	}
+
+	writeMessage("msg-2")
+	storeResult("feature-result", "func foo() {}", "func bar() {}")
+	commit("feature", "feature changes")
+
+	writeMessage("msg-3")
+	commit("feature", "another feature message")
+	cherrySha, err := repo.GitResolveCommit("HEAD")
+	if err != nil {
+		t.Fatalf("error resolving head: %v", err)
+	}
+
+	if err := repo.GitCheckoutBranch("main"); err != nil {
+		t.Fatalf("error checking out main: %v", err)
+	}
+	storeResult("main-result", "func foo() {}", "func baz() {}")
+	commit("main", "main changes")
+
+	params := MergeParams{Repo: repo, OrgId: org.Id, PlanId: plan.Id, Branch: "main"}
+
+	res, err := CherryPickCommit(params, cherrySha[:10])
+	if err != nil {
+		t.Fatalf("error cherry-picking: %v", err)
+	}
+	if !res.Merged || res.NumMessages != 1 || res.NumResults != 0 {
+		t.Errorf("unexpected cherry-pick response: %+v", res)
+	}
+	if _, err := os.Stat(filepath.Join(getPlanConversationDir(org.Id, plan.Id), "msg-3.json")); err != nil {
+		t.Errorf("expected cherry-picked message on main: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(getPlanConversationDir(org.Id, plan.Id), "msg-2.json")); err == nil {
+		t.Error("expected only the picked commit's changes on main")
+	}
+
+	res, err = MergeBranch(params, "feature")
+	if err != nil {
+		t.Fatalf("error merging: %v", err)
+	}
+	if res.Merged || len(res.ConflictedPaths) != 1 || res.ConflictedPaths[0] != "main.go" {
+		t.Fatalf("expected a conflict on main.go without merging, got %+v", res)
+	}
+	if _, err := os.Stat(filepath.Join(getPlanConversationDir(org.Id, plan.Id), "msg-2.json")); err == nil {
+		t.Fatal("expected nothing to be merged while conflicts are unresolved")
+	}
+
+	params.Resolution = shared.MergeResolutionTheirs
+	res, err = MergeBranch(params, "feature")
+	if err != nil {
+		t.Fatalf("error merging: %v", err)
+	}
+	if !res.Merged || res.NumMessages != 1 || res.NumResults != 1 || res.NumContexts != 0 {
+		t.Errorf("unexpected merge response: %+v", res)
+	}
+
+	mainResult, err := GetPlanFileResultById(org.Id, plan.Id, "main-result")
+	if err != nil {
+		t.Fatalf("error getting main result: %v", err)
+	}
+	if mainResult.RejectedAt == nil {
+		t.Error("expected main's conflicting result to be rejected")
+	}
+
+	featureResult, err := GetPlanFileResultById(org.Id, plan.Id, "feature-result")
+	if err != nil {
+		t.Fatalf("error getting merged result: %v", err)
+	}
+	if !featureResult.ToApi().IsPending() {
+		t.Error("expected feature's result to be pending on main")
+	}
+
+	convo, err := GetPlanConvo(org.Id, plan.Id)
+	if err != nil {
+		t.Fatalf("error getting convo: %v", err)
+	}
+	if len(convo) != 3 {
+		t.Errorf("expected 3 messages after merging, got %d", len(convo))
+	}
+
+	branch, err := GetDbBranch(plan.Id, "main")
+	if err != nil {
+		t.Fatalf("error getting main branch: %v", err)
+	}
+	if branch.ConvoTokens != 3 || branch.ContextTokens != 10 {
+		t.Errorf("expected synced token counts, got convo=%d context=%d", branch.ConvoTokens, branch.ContextTokens)
+	}
+
+	res, err = MergeBranch(params, "feature")
+	if err != nil {
+		t.Fatalf("error merging again: %v", err)
+	}
+	if !res.UpToDate || res.Merged {
+		t.Errorf("expected an up-to-date merge, got %+v", res)
+	}
+}
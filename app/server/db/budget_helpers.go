@@ -0,0 +1,64 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	shared "plandex-shared"
+)
+
+func GetOrgBudgets(orgId string) (*OrgBudgets, error) {
+	var budgets OrgBudgets
+	err := Conn.Get(&budgets, "SELECT * FROM org_budgets WHERE org_id = $1", orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting budgets: %v", err)
+	}
+
+	return &budgets, nil
+}
+
+// StoreOrgBudgets creates or replaces an org's budgets -- each org has at most one set
+func StoreOrgBudgets(orgId string, budgets *shared.SpendBudgets) (*OrgBudgets, error) {
+	res := OrgBudgets{
+		OrgId:   orgId,
+		Budgets: *budgets,
+	}
+
+	query := `INSERT INTO org_budgets (org_id, budgets)
+	VALUES ($1, $2)
+	ON CONFLICT (org_id) DO UPDATE SET
+		budgets = excluded.budgets
+	RETURNING id, created_at, updated_at`
+
+	err := Conn.QueryRow(query, orgId, res.Budgets).Scan(&res.Id, &res.CreatedAt, &res.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error storing budgets: %v", err)
+	}
+
+	return &res, nil
+}
+
+func DeleteOrgBudgets(orgId string) error {
+	res, err := Conn.Exec("DELETE FROM org_budgets WHERE org_id = $1", orgId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting budgets: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("budgets not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,123 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestOrgBudgets(t *testing.T) {
+	_, org, _ := setupSqliteTestDb(t)
+
+	found, err := GetOrgBudgets(org.Id)
+	if err != nil {
+		t.Fatalf("error getting budgets: %v", err)
+	}
+	if found != nil {
+		t.Fatalf("expected no budgets, got %+v", found)
+	}
+
+	usd := decimal.RequireFromString("5.50")
+	_, err = StoreOrgBudgets(org.Id, &shared.SpendBudgets{
+		PerPlan: &shared.BudgetLimit{Usd: &usd},
+	})
+	if err != nil {
+		t.Fatalf("error storing budgets: %v", err)
+	}
+
+	// storing again replaces the org's budgets
+	_, err = StoreOrgBudgets(org.Id, &shared.SpendBudgets{
+		PerOrgMonth: &shared.BudgetLimit{Tokens: 1000000, Usd: &usd},
+		WarnAtPct:   90,
+	})
+	if err != nil {
+		t.Fatalf("error updating budgets: %v", err)
+	}
+
+	found, err = GetOrgBudgets(org.Id)
+	if err != nil {
+		t.Fatalf("error getting budgets: %v", err)
+	}
+	if found == nil || found.Budgets.PerPlan != nil || found.Budgets.PerOrgMonth.Tokens != 1000000 || !found.Budgets.PerOrgMonth.Usd.Equal(usd) || found.Budgets.GetWarnAtPct() != 90 {
+		t.Fatalf("unexpected budgets: %+v", found)
+	}
+
+	err = DeleteOrgBudgets(org.Id)
+	if err != nil {
+		t.Fatalf("error deleting budgets: %v", err)
+	}
+
+	err = DeleteOrgBudgets(org.Id)
+	if err == nil {
+		t.Error("expected an error deleting missing budgets")
+	}
+}
+
+func TestUsageTotals(t *testing.T) {
+	user, org, projectId := setupSqliteTestDb(t)
+
+	plan, err := CreatePlan(context.Background(), org.Id, projectId, user.Id, "budget plan")
+	if err != nil {
+		t.Fatalf("error creating plan: %v", err)
+	}
+
+	totals, err := GetUsageTotals(UsageFilter{OrgId: org.Id})
+	if err != nil {
+		t.Fatalf("error getting usage totals: %v", err)
+	}
+	if totals.NumCalls != 0 || !totals.Spend.IsZero() {
+		t.Fatalf("expected no usage, got %+v", totals)
+	}
+
+	for i, cost := range []string{"0.25", "1.5", ""} {
+		usage := &ModelUsage{
+			OrgId:        org.Id,
+			UserId:       &user.Id,
+			Branch:       "main",
+			ModelRole:    shared.ModelRolePlanner,
+			ModelName:    "gpt-4o",
+			Provider:     shared.ModelProviderOpenAI,
+			InputTokens:  1000,
+			OutputTokens: 100,
+		}
+		// only the first call is on the plan
+		if i == 0 {
+			usage.PlanId = &plan.Id
+		}
+		if cost != "" {
+			usage.Cost = decimal.NewNullDecimal(decimal.RequireFromString(cost))
+		}
+		if err := StoreModelUsage(usage); err != nil {
+			t.Fatalf("error storing usage: %v", err)
+		}
+	}
+
+	totals, err = GetUsageTotals(UsageFilter{OrgId: org.Id, UserId: user.Id})
+	if err != nil {
+		t.Fatalf("error getting usage totals: %v", err)
+	}
+	if totals.NumCalls != 3 || totals.InputTokens != 3000 || totals.OutputTokens != 300 || totals.NumUnpriced != 1 || !totals.Spend.Equal(decimal.RequireFromString("1.75")) {
+		t.Errorf("unexpected user totals: %+v", totals)
+	}
+
+	totals, err = GetUsageTotals(UsageFilter{OrgId: org.Id, PlanId: plan.Id})
+	if err != nil {
+		t.Fatalf("error getting usage totals: %v", err)
+	}
+	if totals.NumCalls != 1 || !totals.Spend.Equal(decimal.RequireFromString("0.25")) {
+		t.Errorf("unexpected plan totals: %+v", totals)
+	}
+
+	future := time.Now().Add(time.Hour)
+	totals, err = GetUsageTotals(UsageFilter{OrgId: org.Id, Since: &future})
+	if err != nil {
+		t.Fatalf("error getting usage totals: %v", err)
+	}
+	if totals.NumCalls != 0 {
+		t.Errorf("expected no usage after %v, got %+v", future, totals)
+	}
+}
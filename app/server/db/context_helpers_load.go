@@ -6,6 +6,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"log"
+	"plandex-server/syntax/file_map"
 	shared "plandex-shared"
 	"strings"
 	"sync"
@@ -144,6 +145,24 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 
 	*req = filteredReq
 
+	// symbol contexts are sent with the whole file -- the sha is kept for the whole file so the client can check it for changes,
+	// while the body is just the extracted symbol
+	symbolShas := make(map[*shared.LoadContextParams]string)
+	for _, contextParams := range *req {
+		if contextParams.ContextType != shared.ContextSymbolType {
+			continue
+		}
+
+		hash := sha256.Sum256([]byte(contextParams.Body))
+		symbolShas[contextParams] = hex.EncodeToString(hash[:])
+
+		slice, err := file_map.ExtractSymbol(ctx, contextParams.FilePath, []byte(contextParams.Body), contextParams.Symbol)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error extracting symbol %s from %s: %v", contextParams.Symbol, contextParams.FilePath, err)
+		}
+		contextParams.Body = slice.Body
+	}
+
 	for _, contextParams := range *req {
 		tempId := uuid.New().String()
 
@@ -291,6 +310,9 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 		go func(tempId string, loadParams *shared.LoadContextParams) {
 			hash := sha256.Sum256([]byte(loadParams.Body))
 			sha := hex.EncodeToString(hash[:])
+			if symbolSha, ok := symbolShas[loadParams]; ok {
+				sha = symbolSha
+			}
 
 			var context Context
 			if mapContext, ok := mapContextsByFilePath[loadParams.FilePath]; ok {
@@ -309,6 +331,9 @@ func LoadContexts(ctx Ctx, params LoadContextsParams) (*shared.LoadContextRespon
 					Name:            loadParams.Name,
 					Url:             loadParams.Url,
 					FilePath:        loadParams.FilePath,
+					Symbol:          loadParams.Symbol,
+					GitRef:          loadParams.GitRef,
+					GitStaged:       loadParams.GitStaged,
 					NumTokens:       numTokensByTempId[tempId],
 					Sha:             sha,
 					Body:            loadParams.Body,
@@ -1,9 +1,12 @@
 package db
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"plandex-server/syntax/file_map"
 	shared "plandex-shared"
 	"sync"
 )
@@ -120,6 +123,10 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 	numUrls := 0
 	numTrees := 0
 	numMaps := 0
+	numDiffs := 0
+
+	symbolShasById := make(map[string]string)
+	symbolCtx := context.Background()
 
 	var mu sync.Mutex
 	errCh := make(chan error, len(*req))
@@ -146,6 +153,22 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 			contextsById[id] = context
 			updatedContexts = append(updatedContexts, context.ToApi())
 
+			if context.ContextType == shared.ContextSymbolType {
+				// re-extract by name so the context follows the symbol if it moved within the file
+				hash := sha256.Sum256([]byte(params.Body))
+				symbolShasById[id] = hex.EncodeToString(hash[:])
+
+				slice, err := file_map.ExtractSymbol(symbolCtx, context.FilePath, []byte(params.Body), context.Symbol)
+				if errors.Is(err, file_map.ErrSymbolNotFound) {
+					params.Body = fmt.Sprintf("[symbol %s no longer found in %s]", context.Symbol, context.FilePath)
+				} else if err != nil {
+					errCh <- fmt.Errorf("error extracting symbol %s from %s: %v", context.Symbol, context.FilePath, err)
+					return
+				} else {
+					params.Body = slice.Body
+				}
+			}
+
 			if context.ContextType != shared.ContextMapType {
 				var updateNumTokens int
 				var err error
@@ -176,7 +199,7 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 			}
 
 			switch context.ContextType {
-			case shared.ContextFileType:
+			case shared.ContextFileType, shared.ContextSymbolType:
 				numFiles++
 			case shared.ContextURLType:
 				numUrls++
@@ -184,6 +207,8 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 				numTrees++
 			case shared.ContextMapType:
 				numMaps++
+			case shared.ContextGitDiffType, shared.ContextGitRefDiffType, shared.ContextGitCommitsType:
+				numDiffs++
 			}
 
 			errCh <- nil
@@ -300,8 +325,12 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 				context.NumTokens = newNumTokens
 			} else {
 				context.Body = params.Body
-				hash := sha256.Sum256([]byte(context.Body))
-				context.Sha = hex.EncodeToString(hash[:])
+				if symbolSha, ok := symbolShasById[id]; ok {
+					context.Sha = symbolSha
+				} else {
+					hash := sha256.Sum256([]byte(context.Body))
+					context.Sha = hex.EncodeToString(hash[:])
+				}
 			}
 
 			// log.Println("storing context", id)
@@ -348,6 +377,7 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 		NumUrls:         numUrls,
 		NumTrees:        numTrees,
 		NumMaps:         numMaps,
+		NumDiffs:        numDiffs,
 		MaxTokens:       plannerMaxTokens,
 	}
 
@@ -361,6 +391,7 @@ func UpdateContexts(params UpdateContextsParams) (*shared.UpdateContextResponse,
 		NumTrees:    numTrees,
 		NumUrls:     numUrls,
 		NumMaps:     numMaps,
+		NumDiffs:    numDiffs,
 		TokensDiff:  aggregateTokensDiff,
 		TotalTokens: totalTokens,
 	}) + "\n\n" + shared.TableForContextUpdate(updateRes)
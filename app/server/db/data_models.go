@@ -6,6 +6,7 @@ import (
 	shared "plandex-shared"
 
 	"github.com/sashabaranov/go-openai"
+	"github.com/shopspring/decimal"
 )
 
 // The models below should only be used server-side.
@@ -21,6 +22,174 @@ type AuthToken struct {
 	DeletedAt *time.Time `db:"deleted_at"`
 }
 
+type AccessToken struct {
+	Id          string                `db:"id"`
+	OrgId       string                `db:"org_id"`
+	UserId      string                `db:"user_id"`
+	Name        string                `db:"name"`
+	TokenHash   string                `db:"token_hash"`
+	Permissions shared.PermissionList `db:"permissions"`
+	ExpiresAt   *time.Time            `db:"expires_at"`
+	LastUsedAt  *time.Time            `db:"last_used_at"`
+	RevokedAt   *time.Time            `db:"revoked_at"`
+	CreatedAt   time.Time             `db:"created_at"`
+	UpdatedAt   time.Time             `db:"updated_at"`
+}
+
+func (token *AccessToken) ToApi() *shared.AccessToken {
+	return &shared.AccessToken{
+		Id:          token.Id,
+		Name:        token.Name,
+		Permissions: token.Permissions,
+		ExpiresAt:   token.ExpiresAt,
+		LastUsedAt:  token.LastUsedAt,
+		CreatedAt:   token.CreatedAt,
+	}
+}
+
+type OrgSsoConfig struct {
+	Id           string                `db:"id"`
+	OrgId        string                `db:"org_id"`
+	Domain       string                `db:"domain"`
+	IssuerUrl    string                `db:"issuer_url"`
+	ClientId     string                `db:"client_id"`
+	ClientSecret string                `db:"client_secret"`
+	Scopes       string                `db:"scopes"`
+	RoleClaim    string                `db:"role_claim"`
+	RoleMapping  shared.SsoRoleMapping `db:"role_mapping"`
+	CreatedAt    time.Time             `db:"created_at"`
+	UpdatedAt    time.Time             `db:"updated_at"`
+}
+
+func (config *OrgSsoConfig) ToApi() *shared.SsoConfig {
+	return &shared.SsoConfig{
+		Domain:          config.Domain,
+		IssuerUrl:       config.IssuerUrl,
+		ClientId:        config.ClientId,
+		HasClientSecret: config.ClientSecret != "",
+		Scopes:          config.Scopes,
+		RoleClaim:       config.RoleClaim,
+		RoleMapping:     config.RoleMapping,
+		CreatedAt:       config.CreatedAt,
+		UpdatedAt:       config.UpdatedAt,
+	}
+}
+
+type OrgExecPolicy struct {
+	Id        string            `db:"id"`
+	OrgId     string            `db:"org_id"`
+	Policy    shared.ExecPolicy `db:"policy"`
+	CreatedAt time.Time         `db:"created_at"`
+	UpdatedAt time.Time         `db:"updated_at"`
+}
+
+type OrgBudgets struct {
+	Id        string              `db:"id"`
+	OrgId     string              `db:"org_id"`
+	Budgets   shared.SpendBudgets `db:"budgets"`
+	CreatedAt time.Time           `db:"created_at"`
+	UpdatedAt time.Time           `db:"updated_at"`
+}
+
+type PlanTemplate struct {
+	Id           string                     `db:"id"`
+	OrgId        string                     `db:"org_id"`
+	CreatedBy    *string                    `db:"created_by"`
+	Name         string                     `db:"name"`
+	Description  string                     `db:"description"`
+	Notes        shared.StringList          `db:"notes"`
+	ContextPaths shared.StringList          `db:"context_paths"`
+	Config       shared.PlanConfigOverrides `db:"config"`
+	ModelPack    string                     `db:"model_pack"`
+	Prompt       string                     `db:"prompt"`
+	CreatedAt    time.Time                  `db:"created_at"`
+	UpdatedAt    time.Time                  `db:"updated_at"`
+}
+
+func (template *PlanTemplate) ToApi() *shared.PlanTemplate {
+	return &shared.PlanTemplate{
+		Id:           template.Id,
+		Name:         template.Name,
+		Description:  template.Description,
+		Notes:        template.Notes,
+		ContextPaths: template.ContextPaths,
+		Config:       template.Config,
+		ModelPack:    template.ModelPack,
+		Prompt:       template.Prompt,
+		CreatedAt:    template.CreatedAt,
+		UpdatedAt:    template.UpdatedAt,
+	}
+}
+
+type ModelUsage struct {
+	Id           string               `db:"id"`
+	OrgId        string               `db:"org_id"`
+	UserId       *string              `db:"user_id"`
+	PlanId       *string              `db:"plan_id"`
+	Branch       string               `db:"branch"`
+	ModelRole    shared.ModelRole     `db:"model_role"`
+	ModelId      shared.ModelId       `db:"model_id"`
+	ModelName    shared.ModelName     `db:"model_name"`
+	Provider     shared.ModelProvider `db:"provider"`
+	ModelPack    string               `db:"model_pack"`
+	Purpose      string               `db:"purpose"`
+	InputTokens  int                  `db:"input_tokens"`
+	OutputTokens int                  `db:"output_tokens"`
+	CachedTokens int                  `db:"cached_tokens"`
+	LatencyMs    int                  `db:"latency_ms"`
+	Cost         decimal.NullDecimal  `db:"cost"`
+	SessionId    string               `db:"session_id"`
+	StoppedEarly bool                 `db:"stopped_early"`
+	HadError     bool                 `db:"had_error"`
+	CreatedAt    time.Time            `db:"created_at"`
+}
+
+func (usage *ModelUsage) ToApi() *shared.ModelUsage {
+	var cost *decimal.Decimal
+	if usage.Cost.Valid {
+		cost = &usage.Cost.Decimal
+	}
+
+	return &shared.ModelUsage{
+		Id:            usage.Id,
+		OrgId:         usage.OrgId,
+		UserId:        usage.UserId,
+		PlanId:        usage.PlanId,
+		Branch:        usage.Branch,
+		ModelRole:     usage.ModelRole,
+		ModelId:       usage.ModelId,
+		ModelName:     usage.ModelName,
+		ModelProvider: usage.Provider,
+		ModelPackName: usage.ModelPack,
+		Purpose:       usage.Purpose,
+		InputTokens:   usage.InputTokens,
+		OutputTokens:  usage.OutputTokens,
+		CachedTokens:  usage.CachedTokens,
+		LatencyMs:     usage.LatencyMs,
+		Cost:          cost,
+		SessionId:     usage.SessionId,
+		StoppedEarly:  usage.StoppedEarly,
+		HadError:      usage.HadError,
+		CreatedAt:     usage.CreatedAt,
+	}
+}
+
+// ModelUsageEntry is a usage record joined with the names of its plan and user
+type ModelUsageEntry struct {
+	ModelUsage
+	PlanName  *string `db:"plan_name"`
+	UserName  *string `db:"user_name"`
+	UserEmail *string `db:"user_email"`
+}
+
+func (entry *ModelUsageEntry) ToApi() *shared.ModelUsage {
+	usage := entry.ModelUsage.ToApi()
+	usage.PlanName = entry.PlanName
+	usage.UserName = entry.UserName
+	usage.UserEmail = entry.UserEmail
+	return usage
+}
+
 type Org struct {
 	Id                 string  `db:"id"`
 	Name               string  `db:"name"`
@@ -314,62 +483,126 @@ type ModelPack struct {
 
 func (modelPack *ModelPack) ToApi() *shared.ModelPack {
 	return &shared.ModelPack{
-		Id:          modelPack.Id,
-		Name:        modelPack.Name,
-		Description: modelPack.Description,
-		Planner:     modelPack.Planner,
-		Architect:   modelPack.Architect,
-		Coder:       modelPack.Coder,
-		PlanSummary: modelPack.PlanSummary,
-		Builder:     modelPack.Builder,
-		Namer:       modelPack.Namer,
-		CommitMsg:   modelPack.CommitMsg,
-		ExecStatus:  modelPack.ExecStatus,
+		Id:               modelPack.Id,
+		Name:             modelPack.Name,
+		Description:      modelPack.Description,
+		Planner:          modelPack.Planner,
+		Architect:        modelPack.Architect,
+		Coder:            modelPack.Coder,
+		PlanSummary:      modelPack.PlanSummary,
+		Builder:          modelPack.Builder,
+		WholeFileBuilder: modelPack.WholeFileBuilder,
+		Namer:            modelPack.Namer,
+		CommitMsg:        modelPack.CommitMsg,
+		ExecStatus:       modelPack.ExecStatus,
+	}
+}
+
+func ModelPackFromApi(orgId string, ms *shared.ModelPack) *ModelPack {
+	return &ModelPack{
+		Id:               ms.Id,
+		OrgId:            orgId,
+		Name:             ms.Name,
+		Description:      ms.Description,
+		Planner:          ms.Planner,
+		Coder:            ms.Coder,
+		PlanSummary:      ms.PlanSummary,
+		Builder:          ms.Builder,
+		WholeFileBuilder: ms.WholeFileBuilder,
+		Namer:            ms.Namer,
+		CommitMsg:        ms.CommitMsg,
+		ExecStatus:       ms.ExecStatus,
+		Architect:        ms.Architect,
 	}
 }
 
 type AvailableModel struct {
-	Id                    string                   `db:"id"`
-	OrgId                 string                   `db:"org_id"`
-	Provider              shared.ModelProvider     `db:"provider"`
-	CustomProvider        *string                  `db:"custom_provider"`
-	BaseUrl               string                   `db:"base_url"`
-	ModelName             shared.ModelName         `db:"model_name"`
-	Description           string                   `db:"description"`
-	MaxTokens             int                      `db:"max_tokens"`
-	ApiKeyEnvVar          string                   `db:"api_key_env_var"`
-	DefaultMaxConvoTokens int                      `db:"default_max_convo_tokens"`
-	MaxOutputTokens       int                      `db:"max_output_tokens"`
-	ReservedOutputTokens  int                      `db:"reserved_output_tokens"`
-	HasImageSupport       bool                     `db:"has_image_support"`
-	PreferredOutputFormat shared.ModelOutputFormat `db:"preferred_output_format"`
-	CreatedAt             time.Time                `db:"created_at"`
-	UpdatedAt             time.Time                `db:"updated_at"`
+	Id                         string                   `db:"id"`
+	OrgId                      string                   `db:"org_id"`
+	Provider                   shared.ModelProvider     `db:"provider"`
+	CustomProvider             *string                  `db:"custom_provider"`
+	BaseUrl                    string                   `db:"base_url"`
+	ModelName                  shared.ModelName         `db:"model_name"`
+	Description                string                   `db:"description"`
+	MaxTokens                  int                      `db:"max_tokens"`
+	ApiKeyEnvVar               string                   `db:"api_key_env_var"`
+	DefaultMaxConvoTokens      int                      `db:"default_max_convo_tokens"`
+	MaxOutputTokens            int                      `db:"max_output_tokens"`
+	ReservedOutputTokens       int                      `db:"reserved_output_tokens"`
+	HasImageSupport            bool                     `db:"has_image_support"`
+	PreferredOutputFormat      shared.ModelOutputFormat `db:"preferred_output_format"`
+	InputPricePerMillion       decimal.NullDecimal      `db:"input_price_per_million"`
+	OutputPricePerMillion      decimal.NullDecimal      `db:"output_price_per_million"`
+	CachedInputPricePerMillion decimal.NullDecimal      `db:"cached_input_price_per_million"`
+	CreatedAt                  time.Time                `db:"created_at"`
+	UpdatedAt                  time.Time                `db:"updated_at"`
 }
 
 func (model *AvailableModel) ToApi() *shared.AvailableModel {
+	var pricing *shared.ModelPricing
+	if model.InputPricePerMillion.Valid && model.OutputPricePerMillion.Valid {
+		pricing = &shared.ModelPricing{
+			InputPerMillion:       model.InputPricePerMillion.Decimal,
+			OutputPerMillion:      model.OutputPricePerMillion.Decimal,
+			CachedInputPerMillion: model.CachedInputPricePerMillion.Decimal,
+		}
+	}
+
 	return &shared.AvailableModel{
 		Id: model.Id,
 		BaseModelConfig: shared.BaseModelConfig{
-			Provider:             model.Provider,
-			CustomProvider:       model.CustomProvider,
-			BaseUrl:              model.BaseUrl,
-			ModelName:            model.ModelName,
-			MaxTokens:            model.MaxTokens,
-			ApiKeyEnvVar:         model.ApiKeyEnvVar,
-			MaxOutputTokens:      model.MaxOutputTokens,
-			ReservedOutputTokens: model.ReservedOutputTokens,
+			Provider:                   model.Provider,
+			CustomProvider:             model.CustomProvider,
+			BaseUrl:                    model.BaseUrl,
+			ModelName:                  model.ModelName,
+			MaxTokens:                  model.MaxTokens,
+			ApiKeyEnvVar:               model.ApiKeyEnvVar,
+			MaxOutputTokens:            model.MaxOutputTokens,
+			ReservedOutputTokens:       model.ReservedOutputTokens,
+			PreferredModelOutputFormat: model.PreferredOutputFormat,
 			ModelCompatibility: shared.ModelCompatibility{
 				HasImageSupport: model.HasImageSupport,
 			},
 		},
 		Description:           model.Description,
 		DefaultMaxConvoTokens: model.DefaultMaxConvoTokens,
+		Pricing:               pricing,
 		CreatedAt:             model.CreatedAt,
 		UpdatedAt:             model.UpdatedAt,
 	}
 }
 
+func CustomModelFromApi(orgId string, model *shared.AvailableModel) *AvailableModel {
+	dbModel := &AvailableModel{
+		Id:                    model.Id,
+		OrgId:                 orgId,
+		Provider:              model.Provider,
+		CustomProvider:        model.CustomProvider,
+		BaseUrl:               model.BaseUrl,
+		ModelName:             model.ModelName,
+		Description:           model.Description,
+		MaxTokens:             model.MaxTokens,
+		ApiKeyEnvVar:          model.ApiKeyEnvVar,
+		HasImageSupport:       model.HasImageSupport,
+		DefaultMaxConvoTokens: model.DefaultMaxConvoTokens,
+		MaxOutputTokens:       model.MaxOutputTokens,
+		ReservedOutputTokens:  model.ReservedOutputTokens,
+		PreferredOutputFormat: model.PreferredModelOutputFormat,
+	}
+
+	if dbModel.PreferredOutputFormat == "" {
+		dbModel.PreferredOutputFormat = shared.ModelOutputFormatXml
+	}
+
+	if model.Pricing != nil {
+		dbModel.InputPricePerMillion = decimal.NewNullDecimal(model.Pricing.InputPerMillion)
+		dbModel.OutputPricePerMillion = decimal.NewNullDecimal(model.Pricing.OutputPerMillion)
+		dbModel.CachedInputPricePerMillion = decimal.NewNullDecimal(model.Pricing.CachedInputPerMillion)
+	}
+
+	return dbModel
+}
+
 type DefaultPlanSettings struct {
 	Id           string              `db:"id"`
 	OrgId        string              `db:"org_id"`
@@ -391,6 +624,9 @@ type Context struct {
 	Name            string                `json:"name"`
 	Url             string                `json:"url"`
 	FilePath        string                `json:"filePath"`
+	Symbol          string                `json:"symbol,omitempty"`
+	GitRef          string                `json:"gitRef,omitempty"`
+	GitStaged       bool                  `json:"gitStaged,omitempty"`
 	Sha             string                `json:"sha"`
 	NumTokens       int                   `json:"numTokens"`
 	Body            string                `json:"body,omitempty"`
@@ -418,6 +654,9 @@ func (context *Context) ToMeta() *Context {
 		Name:            context.Name,
 		Url:             context.Url,
 		FilePath:        context.FilePath,
+		Symbol:          context.Symbol,
+		GitRef:          context.GitRef,
+		GitStaged:       context.GitStaged,
 		Sha:             context.Sha,
 		NumTokens:       context.NumTokens,
 		BodySize:        context.BodySize,
@@ -440,6 +679,9 @@ func (context *Context) ToApi() *shared.Context {
 		Name:            context.Name,
 		Url:             context.Url,
 		FilePath:        context.FilePath,
+		Symbol:          context.Symbol,
+		GitRef:          context.GitRef,
+		GitStaged:       context.GitStaged,
 		Sha:             context.Sha,
 		NumTokens:       context.NumTokens,
 		Body:            context.Body,
@@ -620,3 +862,55 @@ func (subtask *Subtask) ToApi() *shared.Subtask {
 		IsFinished:  subtask.IsFinished,
 	}
 }
+
+type Webhook struct {
+	Id          string                  `db:"id"`
+	OrgId       string                  `db:"org_id"`
+	CreatedBy   *string                 `db:"created_by"`
+	Url         string                  `db:"url"`
+	Description string                  `db:"description"`
+	Secret      string                  `db:"secret"`
+	Events      shared.WebhookEventList `db:"events"`
+	CreatedAt   time.Time               `db:"created_at"`
+	UpdatedAt   time.Time               `db:"updated_at"`
+}
+
+func (webhook *Webhook) ToApi() *shared.Webhook {
+	return &shared.Webhook{
+		Id:          webhook.Id,
+		Url:         webhook.Url,
+		Description: webhook.Description,
+		Events:      webhook.Events,
+		CreatedAt:   webhook.CreatedAt,
+		UpdatedAt:   webhook.UpdatedAt,
+	}
+}
+
+type WebhookDelivery struct {
+	Id             string                       `db:"id"`
+	OrgId          string                       `db:"org_id"`
+	WebhookId      string                       `db:"webhook_id"`
+	Event          shared.WebhookEvent          `db:"event"`
+	Payload        string                       `db:"payload"`
+	Status         shared.WebhookDeliveryStatus `db:"status"`
+	NumAttempts    int                          `db:"num_attempts"`
+	ResponseStatus *int                         `db:"response_status"`
+	Error          *string                      `db:"error"`
+	CreatedAt      time.Time                    `db:"created_at"`
+	UpdatedAt      time.Time                    `db:"updated_at"`
+	DeliveredAt    *time.Time                   `db:"delivered_at"`
+}
+
+func (delivery *WebhookDelivery) ToApi() *shared.WebhookDelivery {
+	return &shared.WebhookDelivery{
+		Id:             delivery.Id,
+		WebhookId:      delivery.WebhookId,
+		Event:          delivery.Event,
+		Status:         delivery.Status,
+		NumAttempts:    delivery.NumAttempts,
+		ResponseStatus: delivery.ResponseStatus,
+		Error:          delivery.Error,
+		CreatedAt:      delivery.CreatedAt,
+		DeliveredAt:    delivery.DeliveredAt,
+	}
+}
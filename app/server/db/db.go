@@ -6,10 +6,13 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -41,6 +44,10 @@ func Connect() error {
 		}
 	}
 
+	if isSqliteUrl(dbUrl) {
+		return connectSqlite(dbUrl)
+	}
+
 	if strings.Contains(dbUrl, "?") {
 		dbUrl += fmt.Sprintf("&statement_timeout=%d&lock_timeout=%d&timezone=UTC&idle_in_transaction_session_timeout=%d", StatementTimeout, LockTimeout, IdleInTransactionSessionTimeout)
 	} else {
@@ -111,15 +118,23 @@ func migrationsUp(dir string) error {
 		return errors.New("db not initialized")
 	}
 
-	driver, err := postgres.WithInstance(Conn.DB, &postgres.Config{})
+	var driver database.Driver
+	var err error
+	if IsSqlite() {
+		// sqlite migrations are kept separately, starting from a single schema that matches the postgres migrations as of its version
+		dir = filepath.Join(dir, "sqlite")
+		driver, err = sqlite3.WithInstance(Conn.DB, &sqlite3.Config{})
+	} else {
+		driver, err = postgres.WithInstance(Conn.DB, &postgres.Config{})
+	}
 
 	if err != nil {
-		return fmt.Errorf("error creating postgres driver: %v", err)
+		return fmt.Errorf("error creating %s driver: %v", Dialect, err)
 	}
 
 	m, err := migrate.NewWithDatabaseInstance(
 		"file://"+dir,
-		"postgres", driver)
+		string(Dialect), driver)
 
 	if err != nil {
 		return fmt.Errorf("error creating migration instance: %v", err)
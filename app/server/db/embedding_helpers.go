@@ -0,0 +1,107 @@
+package db
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+// EmbeddingInput is a vector to store in a project's embedding index, keyed by the sha of the text it was computed from
+type EmbeddingInput struct {
+	Path   string
+	Sha    string
+	Vector []float32
+}
+
+// GetEmbeddings returns stored vectors by sha -- shas that haven't been embedded yet are omitted
+func GetEmbeddings(projectId, embedder string, shas []string) (map[string][]float32, error) {
+	res := map[string][]float32{}
+	if len(shas) == 0 {
+		return res, nil
+	}
+
+	var rows []struct {
+		Sha    string `db:"sha"`
+		Vector []byte `db:"vector"`
+	}
+	err := Conn.Select(&rows, "SELECT sha, vector FROM embeddings WHERE project_id = $1 AND embedder = $2 AND sha = ANY($3)", projectId, embedder, pq.Array(shas))
+
+	if err != nil {
+		return nil, fmt.Errorf("error getting embeddings: %v", err)
+	}
+
+	for _, row := range rows {
+		vector, err := decodeVector(row.Vector)
+		if err != nil {
+			return nil, fmt.Errorf("error decoding embedding %s: %v", row.Sha, err)
+		}
+		res[row.Sha] = vector
+	}
+
+	return res, nil
+}
+
+func StoreEmbeddings(ctx context.Context, orgId, projectId, embedder string, inputs []*EmbeddingInput) error {
+	if len(inputs) == 0 {
+		return nil
+	}
+
+	return WithTx(ctx, "store embeddings", func(tx *sqlx.Tx) error {
+		for _, input := range inputs {
+			_, err := tx.Exec(
+				"INSERT INTO embeddings (org_id, project_id, embedder, path, sha, vector) VALUES ($1, $2, $3, $4, $5, $6) ON CONFLICT (project_id, embedder, sha) DO NOTHING",
+				orgId,
+				projectId,
+				embedder,
+				input.Path,
+				input.Sha,
+				encodeVector(input.Vector),
+			)
+
+			if err != nil {
+				return fmt.Errorf("error storing embedding: %v", err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// DeleteStaleEmbeddings removes vectors for the given paths that weren't computed from their current content
+func DeleteStaleEmbeddings(projectId, embedder string, paths, currentShas []string) error {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	_, err := Conn.Exec("DELETE FROM embeddings WHERE project_id = $1 AND embedder = $2 AND path = ANY($3) AND NOT (sha = ANY($4))", projectId, embedder, pq.Array(paths), pq.Array(currentShas))
+
+	if err != nil {
+		return fmt.Errorf("error deleting stale embeddings: %v", err)
+	}
+
+	return nil
+}
+
+func encodeVector(vector []float32) []byte {
+	bytes := make([]byte, 4*len(vector))
+	for i, v := range vector {
+		binary.LittleEndian.PutUint32(bytes[i*4:], math.Float32bits(v))
+	}
+	return bytes
+}
+
+func decodeVector(bytes []byte) ([]float32, error) {
+	if len(bytes)%4 != 0 {
+		return nil, fmt.Errorf("invalid vector length %d", len(bytes))
+	}
+
+	vector := make([]float32, len(bytes)/4)
+	for i := range vector {
+		vector[i] = math.Float32frombits(binary.LittleEndian.Uint32(bytes[i*4:]))
+	}
+	return vector, nil
+}
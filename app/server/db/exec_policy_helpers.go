@@ -0,0 +1,64 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+
+	shared "plandex-shared"
+)
+
+func GetOrgExecPolicy(orgId string) (*OrgExecPolicy, error) {
+	var policy OrgExecPolicy
+	err := Conn.Get(&policy, "SELECT * FROM org_exec_policies WHERE org_id = $1", orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting exec policy: %v", err)
+	}
+
+	return &policy, nil
+}
+
+// StoreOrgExecPolicy creates or replaces an org's exec policy -- each org has at most one
+func StoreOrgExecPolicy(orgId string, policy *shared.ExecPolicy) (*OrgExecPolicy, error) {
+	res := OrgExecPolicy{
+		OrgId:  orgId,
+		Policy: *policy,
+	}
+
+	query := `INSERT INTO org_exec_policies (org_id, policy)
+	VALUES ($1, $2)
+	ON CONFLICT (org_id) DO UPDATE SET
+		policy = excluded.policy
+	RETURNING id, created_at, updated_at`
+
+	err := Conn.QueryRow(query, orgId, res.Policy).Scan(&res.Id, &res.CreatedAt, &res.UpdatedAt)
+
+	if err != nil {
+		return nil, fmt.Errorf("error storing exec policy: %v", err)
+	}
+
+	return &res, nil
+}
+
+func DeleteOrgExecPolicy(orgId string) error {
+	res, err := Conn.Exec("DELETE FROM org_exec_policies WHERE org_id = $1", orgId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting exec policy: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("exec policy not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestOrgExecPolicy(t *testing.T) {
+	_, org, _ := setupSqliteTestDb(t)
+
+	found, err := GetOrgExecPolicy(org.Id)
+	if err != nil {
+		t.Fatalf("error getting exec policy: %v", err)
+	}
+	if found != nil {
+		t.Fatalf("expected no exec policy, got %+v", found)
+	}
+
+	_, err = StoreOrgExecPolicy(org.Id, &shared.ExecPolicy{
+		OnViolation: shared.ExecPolicyOnViolationBlock,
+		Deny:        []string{"rm -rf *"},
+	})
+	if err != nil {
+		t.Fatalf("error storing exec policy: %v", err)
+	}
+
+	// storing again replaces the org's policy
+	_, err = StoreOrgExecPolicy(org.Id, &shared.ExecPolicy{
+		OnViolation: shared.ExecPolicyOnViolationDebug,
+		DenyNetwork: true,
+	})
+	if err != nil {
+		t.Fatalf("error updating exec policy: %v", err)
+	}
+
+	found, err = GetOrgExecPolicy(org.Id)
+	if err != nil {
+		t.Fatalf("error getting exec policy: %v", err)
+	}
+	if found == nil || found.Policy.OnViolation != shared.ExecPolicyOnViolationDebug || !found.Policy.DenyNetwork || len(found.Policy.Deny) != 0 {
+		t.Fatalf("unexpected exec policy: %+v", found)
+	}
+
+	err = DeleteOrgExecPolicy(org.Id)
+	if err != nil {
+		t.Fatalf("error deleting exec policy: %v", err)
+	}
+
+	err = DeleteOrgExecPolicy(org.Id)
+	if err == nil {
+		t.Error("expected an error deleting a missing exec policy")
+	}
+}
@@ -2,6 +2,7 @@ package db
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -397,6 +398,229 @@ func (repo *GitRepo) GitCheckoutBranch(branch string) error {
 	return nil
 }
 
+// GitResolveCommit resolves a branch name or (possibly abbreviated) sha to a full commit sha
+func (repo *GitRepo) GitResolveCommit(ref string) (string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "rev-parse", "--verify", "--quiet", ref+"^{commit}").Output()
+	if err != nil {
+		return "", fmt.Errorf("commit not found: %s", ref)
+	}
+
+	return strings.TrimSpace(string(res)), nil
+}
+
+// GitIsAncestor returns true if the commit is already part of the current branch's history
+func (repo *GitRepo) GitIsAncestor(sha string) (bool, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	err := exec.Command("git", "-C", dir, "merge-base", "--is-ancestor", sha, "HEAD").Run()
+	if err == nil {
+		return true, nil
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+
+	return false, fmt.Errorf("error checking ancestry of %s for dir: %s, err: %v", sha, dir, err)
+}
+
+// GitMergeBase returns the best common ancestor of the current branch and the given commit
+func (repo *GitRepo) GitMergeBase(sha string) (string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "merge-base", "HEAD", sha).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error getting merge base for dir: %s, err: %v, output: %s", dir, err, string(res))
+	}
+
+	return strings.TrimSpace(string(res)), nil
+}
+
+// GitParentShas returns a commit's parents -- more than one for a merge commit, none for the initial commit
+func (repo *GitRepo) GitParentShas(sha string) ([]string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "rev-list", "--parents", "-n", "1", sha).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("error getting parents of %s for dir: %s, err: %v, output: %s", sha, dir, err, string(res))
+	}
+
+	fields := strings.Fields(string(res))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("commit not found: %s", sha)
+	}
+
+	return fields[1:], nil
+}
+
+// GitShowCommitMessage returns a commit's full message
+func (repo *GitRepo) GitShowCommitMessage(sha string) (string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	res, err := exec.Command("git", "-C", dir, "log", "-1", "--format=%B", sha).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("error getting commit message for %s in dir: %s, err: %v, output: %s", sha, dir, err, string(res))
+	}
+
+	return strings.TrimSpace(string(res)), nil
+}
+
+// GitDiffPaths lists the paths that were added or modified between two commits
+func (repo *GitRepo) GitDiffPaths(fromSha, toSha string) ([]string, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	var out, stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", dir, "diff", "--name-only", "--no-renames", "--diff-filter=AM", fromSha, toSha)
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("error diffing %s..%s for dir: %s, err: %v, output: %s", fromSha, toSha, dir, err, stderr.String())
+	}
+
+	var paths []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+
+	return paths, nil
+}
+
+// GitShowFile reads a file as of the given commit without checking it out
+func (repo *GitRepo) GitShowFile(sha, path string) ([]byte, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	var out, stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", dir, "show", sha+":"+path)
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s at %s for dir: %s, err: %v, output: %s", path, sha, dir, err, stderr.String())
+	}
+
+	return out.Bytes(), nil
+}
+
+// GitMergeNoCommit merges a commit into the current branch and leaves the result staged. Files that both sides changed keep the current branch's version, since plan files are keyed by id and a conflict means both branches updated the same record.
+func (repo *GitRepo) GitMergeNoCommit(sha string) error {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	return gitWriteOperation(func() error {
+		res, err := exec.Command("git", "-C", dir, "merge", "--no-ff", "--no-commit", sha).CombinedOutput()
+		if err != nil {
+			return gitKeepOursForConflicts(dir, fmt.Sprintf("error merging %s for dir: %s, err: %v, output: %s", sha, dir, err, string(res)))
+		}
+		return nil
+	}, dir, fmt.Sprintf("GitMergeNoCommit > gitMerge: plan=%s sha=%s", repo.planId, sha))
+}
+
+// GitCherryPickNoCommit applies a single commit's changes to the current branch and leaves them staged, resolving conflicts the same way as GitMergeNoCommit. Merge commits are picked relative to their first parent.
+func (repo *GitRepo) GitCherryPickNoCommit(sha string, isMergeCommit bool) error {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	args := []string{"-C", dir, "cherry-pick", "--no-commit"}
+	if isMergeCommit {
+		args = append(args, "-m", "1")
+	}
+	args = append(args, sha)
+
+	return gitWriteOperation(func() error {
+		res, err := exec.Command("git", args...).CombinedOutput()
+		if err != nil {
+			return gitKeepOursForConflicts(dir, fmt.Sprintf("error cherry-picking %s for dir: %s, err: %v, output: %s", sha, dir, err, string(res)))
+		}
+		return nil
+	}, dir, fmt.Sprintf("GitCherryPickNoCommit > gitCherryPick: plan=%s sha=%s", repo.planId, sha))
+}
+
+// GitHasStagedChanges returns true if a merge or cherry-pick left anything to commit
+func (repo *GitRepo) GitHasStagedChanges() (bool, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	err := exec.Command("git", "-C", dir, "diff", "--cached", "--quiet").Run()
+	if err == nil {
+		return false, nil
+	}
+
+	if exitErr, ok := err.(*exec.ExitError); ok && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+
+	return false, fmt.Errorf("error checking for staged changes for dir: %s, err: %v", dir, err)
+}
+
+// gitKeepOursForConflicts resolves any unmerged files left by a merge or cherry-pick in favor of the current branch. If nothing is unmerged, the original failure wasn't a conflict and is returned as an error.
+func gitKeepOursForConflicts(repoDir, failureMsg string) error {
+	res, err := exec.Command("git", "-C", repoDir, "diff", "--name-only", "--diff-filter=U").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error listing conflicted files for dir: %s, err: %v, output: %s", repoDir, err, string(res))
+	}
+
+	var conflicted []string
+	for _, line := range strings.Split(string(res), "\n") {
+		if line != "" {
+			conflicted = append(conflicted, line)
+		}
+	}
+
+	if len(conflicted) == 0 {
+		return errors.New(failureMsg)
+	}
+
+	for _, path := range conflicted {
+		log.Printf("[Git] gitKeepOursForConflicts - keeping current branch version of %s", path)
+
+		res, err := exec.Command("git", "-C", repoDir, "checkout", "--ours", "--", path).CombinedOutput()
+		if err != nil {
+			// the current branch deleted the file
+			res, err = exec.Command("git", "-C", repoDir, "rm", "-q", "--", path).CombinedOutput()
+		}
+		if err != nil {
+			return fmt.Errorf("error resolving conflict for %s in dir: %s, err: %v, output: %s", path, repoDir, err, string(res))
+		}
+	}
+
+	return gitAdd(repoDir, ".")
+}
+
+// GitFastExport returns a fast-export stream of all branches with their full history
+func (repo *GitRepo) GitFastExport() ([]byte, error) {
+	dir := getPlanDir(repo.orgId, repo.planId)
+
+	var out, stderr bytes.Buffer
+	cmd := exec.Command("git", "-C", dir, "fast-export", "--all", "--signed-tags=strip")
+	cmd.Stdout = &out
+	cmd.Stderr = &stderr
+	err := cmd.Run()
+	if err != nil {
+		return nil, fmt.Errorf("error exporting git repository for dir: %s, err: %v, output: %s", dir, err, stderr.String())
+	}
+
+	return out.Bytes(), nil
+}
+
+// gitFastImport loads a fast-export stream into a freshly initialized repo and checks out main
+func gitFastImport(repoDir string, stream []byte) error {
+	cmd := exec.Command("git", "-C", repoDir, "fast-import", "--quiet")
+	cmd.Stdin = bytes.NewReader(stream)
+	res, err := cmd.CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error importing git repository for dir: %s, err: %v, output: %s", repoDir, err, string(res))
+	}
+
+	res, err = exec.Command("git", "-C", repoDir, "reset", "--hard", "main").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("error checking out imported main branch for dir: %s, err: %v, output: %s", repoDir, err, string(res))
+	}
+
+	return nil
+}
+
 func gitAdd(repoDir, path string) error {
 
 	if err := gitRemoveIndexLockFileIfExists(repoDir); err != nil {
@@ -100,6 +100,14 @@ func lockRepoDB(params LockRepoParams, numRetry int) (string, error) {
 		return "", fmt.Errorf("invalid lock scope: %s", scope)
 	}
 
+	if IsSqlite() {
+		unlockLocal, err := lockPlanLocal(ctx, planId)
+		if err != nil {
+			return "", fmt.Errorf("error acquiring local plan lock: %v", err)
+		}
+		defer unlockLocal()
+	}
+
 	tx, err := Conn.BeginTxx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead})
 	if err != nil {
 		if locksVerboseLogging {
@@ -148,13 +156,18 @@ func lockRepoDB(params LockRepoParams, numRetry int) (string, error) {
 		}
 	}
 
-	lockablePlanIdQuery := "SELECT * FROM lockable_plan_ids WHERE plan_id = $1"
-	if forUpdate {
-		lockablePlanIdQuery += " FOR UPDATE"
-	} else {
-		lockablePlanIdQuery += " FOR SHARE"
+	// with sqlite, the local plan lock serializes acquisition instead of row locks
+	var forClause string
+	if !IsSqlite() {
+		if forUpdate {
+			forClause = " FOR UPDATE"
+		} else {
+			forClause = " FOR SHARE"
+		}
 	}
 
+	lockablePlanIdQuery := "SELECT * FROM lockable_plan_ids WHERE plan_id = $1" + forClause
+
 	_, err = tx.Exec(lockablePlanIdQuery, planId)
 	if err != nil {
 		log.Printf("[Lock][%d] getting lockable plan id for %s: %v | reason: %s", goroutineID, planId, err, params.Reason)
@@ -165,12 +178,7 @@ func lockRepoDB(params LockRepoParams, numRetry int) (string, error) {
 
 	log.Printf("[Lock][%d] got lockable plan id for %s | reason: %s", goroutineID, planId, params.Reason)
 
-	query := "SELECT id, org_id, user_id, plan_id, plan_build_id, scope, branch, last_heartbeat_at, created_at FROM repo_locks WHERE plan_id = $1"
-	if forUpdate {
-		query += " FOR UPDATE"
-	} else {
-		query += " FOR SHARE"
-	}
+	query := "SELECT id, org_id, user_id, plan_id, plan_build_id, scope, branch, last_heartbeat_at, created_at FROM repo_locks WHERE plan_id = $1" + forClause
 	queryArgs := []interface{}{planId}
 
 	var locks []*repoLock
@@ -479,6 +487,14 @@ func deleteRepoLockDB(id, planId, reason string, numRetry int) error {
 		}()
 	}
 
+	if IsSqlite() {
+		unlockLocal, err := lockPlanLocal(shutdown.ShutdownCtx, planId)
+		if err != nil {
+			return fmt.Errorf("error acquiring local plan lock: %v", err)
+		}
+		defer unlockLocal()
+	}
+
 	result, err := Conn.Exec("DELETE FROM repo_locks WHERE id = $1", id)
 	if err != nil {
 		log.Printf("[Lock][Delete][%d] Error deleting lock: %v | reason: %s", goroutineID, err, reason)
@@ -548,6 +564,10 @@ func isDeadlockError(err error) bool {
 		return true
 	}
 
+	if isSqliteBusyErr(err) {
+		return true
+	}
+
 	return false
 }
 
@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// With sqlite there are no row locks to serialize lock acquisition, so the check-then-insert in lockRepoDB is guarded by an in-process lock per plan, plus a file lock in case more than one server process shares the same database file.
+
+const localPlanLockPollInterval = 10 * time.Millisecond
+
+var localPlanLocks = make(map[string]chan struct{})
+var localPlanLocksMu sync.Mutex
+
+func getLocalPlanLockCh(planId string) chan struct{} {
+	localPlanLocksMu.Lock()
+	defer localPlanLocksMu.Unlock()
+
+	ch, ok := localPlanLocks[planId]
+	if !ok {
+		ch = make(chan struct{}, 1)
+		localPlanLocks[planId] = ch
+	}
+	return ch
+}
+
+func lockPlanLocal(ctx context.Context, planId string) (func(), error) {
+	ch := getLocalPlanLockCh(planId)
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case ch <- struct{}{}:
+	}
+
+	unlockFile, err := lockPlanFile(ctx, planId)
+	if err != nil {
+		<-ch
+		return nil, err
+	}
+
+	return func() {
+		unlockFile()
+		<-ch
+	}, nil
+}
+
+func lockPlanFile(ctx context.Context, planId string) (func(), error) {
+	dir := filepath.Join(BaseDir, "locks")
+	err := os.MkdirAll(dir, os.ModePerm)
+	if err != nil {
+		return nil, fmt.Errorf("error creating locks dir: %v", err)
+	}
+
+	f, err := os.OpenFile(filepath.Join(dir, planId+".lock"), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("error opening lock file: %v", err)
+	}
+
+	for {
+		locked, err := tryLockFile(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("error locking file: %v", err)
+		}
+		if locked {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			f.Close()
+			return nil, ctx.Err()
+		case <-time.After(localPlanLockPollInterval):
+		}
+	}
+
+	return func() {
+		unlockFile(f)
+		f.Close()
+	}, nil
+}
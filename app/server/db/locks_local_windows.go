@@ -0,0 +1,12 @@
+//go:build windows
+
+package db
+
+import "os"
+
+// file locks aren't supported on windows -- the in-process lock still applies, so only a single server process should use a sqlite database
+func tryLockFile(f *os.File) (bool, error) {
+	return true, nil
+}
+
+func unlockFile(f *os.File) {}
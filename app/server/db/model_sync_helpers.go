@@ -0,0 +1,198 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+	"gopkg.in/yaml.v3"
+)
+
+// SyncModels makes an org's custom models and model packs match the given file. Custom models are matched by provider and model name, and packs by name. Anything the org has that isn't in the file is deleted. With dryRun, the changes are returned without being applied.
+func SyncModels(ctx context.Context, orgId string, file *shared.ModelsFile, dryRun bool) ([]*shared.ModelsSyncChange, error) {
+	var changes []*shared.ModelsSyncChange
+
+	err := WithTx(ctx, "sync models", func(tx *sqlx.Tx) error {
+		var existingModels []*AvailableModel
+		err := tx.Select(&existingModels, "SELECT * FROM custom_models WHERE org_id = $1 ORDER BY created_at", orgId)
+		if err != nil {
+			return fmt.Errorf("error fetching custom models: %v", err)
+		}
+
+		var existingPacks []*ModelPack
+		err = tx.Select(&existingPacks, "SELECT * FROM model_sets WHERE org_id = $1 ORDER BY created_at", orgId)
+		if err != nil {
+			return fmt.Errorf("error fetching model packs: %v", err)
+		}
+
+		existingModelsByKey := map[string]*AvailableModel{}
+		for _, model := range existingModels {
+			existingModelsByKey[model.ToApi().CustomModelKey()] = model
+		}
+
+		wantModelKeys := map[string]bool{}
+		for _, model := range file.Models {
+			key := model.CustomModelKey()
+			wantModelKeys[key] = true
+
+			dbModel := CustomModelFromApi(orgId, model)
+			existing := existingModelsByKey[key]
+
+			if existing == nil {
+				changes = append(changes, &shared.ModelsSyncChange{Action: shared.ModelsSyncActionCreate, Kind: shared.ModelsSyncKindModel, Name: key})
+				if !dryRun {
+					err = insertCustomModel(tx, dbModel)
+					if err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			dbModel.Id = existing.Id
+			fields, err := changedFields(syncableModel(existing.ToApi()), syncableModel(dbModel.ToApi()))
+			if err != nil {
+				return err
+			}
+			if len(fields) == 0 {
+				continue
+			}
+
+			changes = append(changes, &shared.ModelsSyncChange{Action: shared.ModelsSyncActionUpdate, Kind: shared.ModelsSyncKindModel, Name: key, Fields: fields})
+			if !dryRun {
+				err = updateCustomModel(tx, dbModel)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, model := range existingModels {
+			key := model.ToApi().CustomModelKey()
+			if wantModelKeys[key] {
+				continue
+			}
+			changes = append(changes, &shared.ModelsSyncChange{Action: shared.ModelsSyncActionDelete, Kind: shared.ModelsSyncKindModel, Name: key})
+			if !dryRun {
+				_, err = tx.Exec("DELETE FROM custom_models WHERE id = $1 AND org_id = $2", model.Id, orgId)
+				if err != nil {
+					return fmt.Errorf("error deleting custom model: %v", err)
+				}
+			}
+		}
+
+		existingPacksByName := map[string]*ModelPack{}
+		for _, pack := range existingPacks {
+			existingPacksByName[pack.Name] = pack
+		}
+
+		wantPackNames := map[string]bool{}
+		for _, pack := range file.ModelPacks {
+			wantPackNames[pack.Name] = true
+
+			dbPack := ModelPackFromApi(orgId, pack)
+			existing := existingPacksByName[pack.Name]
+
+			if existing == nil {
+				changes = append(changes, &shared.ModelsSyncChange{Action: shared.ModelsSyncActionCreate, Kind: shared.ModelsSyncKindModelPack, Name: pack.Name})
+				if !dryRun {
+					err = insertModelPack(tx, dbPack)
+					if err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			dbPack.Id = existing.Id
+			fields, err := changedFields(existing.ToApi(), dbPack.ToApi())
+			if err != nil {
+				return err
+			}
+			if len(fields) == 0 {
+				continue
+			}
+
+			changes = append(changes, &shared.ModelsSyncChange{Action: shared.ModelsSyncActionUpdate, Kind: shared.ModelsSyncKindModelPack, Name: pack.Name, Fields: fields})
+			if !dryRun {
+				err = updateModelPack(tx, dbPack)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, pack := range existingPacks {
+			if wantPackNames[pack.Name] {
+				continue
+			}
+			changes = append(changes, &shared.ModelsSyncChange{Action: shared.ModelsSyncActionDelete, Kind: shared.ModelsSyncKindModelPack, Name: pack.Name})
+			if !dryRun {
+				_, err = tx.Exec("DELETE FROM model_sets WHERE id = $1 AND org_id = $2", pack.Id, orgId)
+				if err != nil {
+					return fmt.Errorf("error deleting model pack: %v", err)
+				}
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+// syncableModel clears the timestamps set by the database so they don't show up as changes
+func syncableModel(model *shared.AvailableModel) *shared.AvailableModel {
+	model.CreatedAt = time.Time{}
+	model.UpdatedAt = time.Time{}
+	return model
+}
+
+// changedFields compares two values by their YAML fields, so the names match what's in a models file, and returns the top-level fields that differ in sorted order
+func changedFields(a, b interface{}) ([]string, error) {
+	aMap, err := toYamlMap(a)
+	if err != nil {
+		return nil, err
+	}
+	bMap, err := toYamlMap(b)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields []string
+	for key, aVal := range aMap {
+		if !reflect.DeepEqual(aVal, bMap[key]) {
+			fields = append(fields, key)
+		}
+	}
+	for key := range bMap {
+		if _, ok := aMap[key]; !ok {
+			fields = append(fields, key)
+		}
+	}
+	sort.Strings(fields)
+
+	return fields, nil
+}
+
+func toYamlMap(v interface{}) (map[string]interface{}, error) {
+	bytes, err := yaml.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling for comparison: %v", err)
+	}
+	var m map[string]interface{}
+	err = yaml.Unmarshal(bytes, &m)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling for comparison: %v", err)
+	}
+	return m, nil
+}
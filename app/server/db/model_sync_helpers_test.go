@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestSyncModels(t *testing.T) {
+	_, org, _ := setupSqliteTestDb(t)
+	ctx := context.Background()
+
+	customProvider := "internal-gateway"
+	model := &shared.AvailableModel{
+		Description: "Internal gateway model",
+		BaseModelConfig: shared.BaseModelConfig{
+			Provider:        shared.ModelProviderCustom,
+			CustomProvider:  &customProvider,
+			BaseUrl:         "http://localhost:8080/v1",
+			ModelName:       "gateway-large",
+			MaxTokens:       128000,
+			MaxOutputTokens: 16000,
+			ApiKeyEnvVar:    "GATEWAY_API_KEY",
+		},
+	}
+
+	pack := shared.BuiltInModelPacks[0]
+	packCopy := *pack
+	packCopy.Name = "team-pack"
+
+	file := &shared.ModelsFile{
+		Models:     []*shared.AvailableModel{model},
+		ModelPacks: []*shared.ModelPack{&packCopy},
+	}
+
+	if err := file.Validate(); err != nil {
+		t.Fatalf("expected models file to be valid: %v", err)
+	}
+
+	changes, err := SyncModels(ctx, org.Id, file, true)
+	if err != nil {
+		t.Fatalf("error running dry run: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Action != shared.ModelsSyncActionCreate || changes[1].Action != shared.ModelsSyncActionCreate {
+		t.Fatalf("expected 2 creates, got %+v", changes)
+	}
+
+	models, err := ListCustomModels(org.Id)
+	if err != nil {
+		t.Fatalf("error listing models: %v", err)
+	}
+	if len(models) != 0 {
+		t.Fatalf("dry run should not create models, got %d", len(models))
+	}
+
+	_, err = SyncModels(ctx, org.Id, file, false)
+	if err != nil {
+		t.Fatalf("error syncing: %v", err)
+	}
+
+	models, err = ListCustomModels(org.Id)
+	if err != nil {
+		t.Fatalf("error listing models: %v", err)
+	}
+	if len(models) != 1 || models[0].ModelName != "gateway-large" {
+		t.Fatalf("expected synced model, got %+v", models)
+	}
+
+	packs, err := ListModelPacks(org.Id)
+	if err != nil {
+		t.Fatalf("error listing model packs: %v", err)
+	}
+	if len(packs) != 1 || packs[0].Name != "team-pack" {
+		t.Fatalf("expected synced model pack, got %+v", packs)
+	}
+
+	// syncing the same file again is a no-op
+	changes, err = SyncModels(ctx, org.Id, file, false)
+	if err != nil {
+		t.Fatalf("error re-syncing: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", changes[0])
+	}
+
+	model.MaxOutputTokens = 32000
+	changes, err = SyncModels(ctx, org.Id, file, false)
+	if err != nil {
+		t.Fatalf("error syncing update: %v", err)
+	}
+	if len(changes) != 1 || changes[0].Action != shared.ModelsSyncActionUpdate || !reflect.DeepEqual(changes[0].Fields, []string{"maxOutputTokens"}) {
+		t.Fatalf("expected a maxOutputTokens update, got %+v", changes)
+	}
+
+	models, err = ListCustomModels(org.Id)
+	if err != nil {
+		t.Fatalf("error listing models: %v", err)
+	}
+	if models[0].MaxOutputTokens != 32000 {
+		t.Errorf("expected updated maxOutputTokens, got %d", models[0].MaxOutputTokens)
+	}
+
+	changes, err = SyncModels(ctx, org.Id, &shared.ModelsFile{}, false)
+	if err != nil {
+		t.Fatalf("error syncing empty file: %v", err)
+	}
+	if len(changes) != 2 || changes[0].Action != shared.ModelsSyncActionDelete || changes[1].Action != shared.ModelsSyncActionDelete {
+		t.Fatalf("expected 2 deletes, got %+v", changes)
+	}
+
+	models, err = ListCustomModels(org.Id)
+	if err != nil {
+		t.Fatalf("error listing models: %v", err)
+	}
+	packs, err = ListModelPacks(org.Id)
+	if err != nil {
+		t.Fatalf("error listing model packs: %v", err)
+	}
+	if len(models) != 0 || len(packs) != 0 {
+		t.Errorf("expected everything deleted, got %d models and %d packs", len(models), len(packs))
+	}
+}
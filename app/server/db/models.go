@@ -2,13 +2,19 @@ package db
 
 import (
 	"fmt"
+
+	"github.com/jmoiron/sqlx"
 )
 
 func CreateCustomModel(model *AvailableModel) error {
-	query := `INSERT INTO custom_models (org_id, provider, custom_provider, base_url, model_name, description, max_tokens, api_key_env_var, default_max_convo_tokens, max_output_tokens, reserved_output_tokens, preferred_output_format, has_image_support) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+	return insertCustomModel(Conn, model)
+}
+
+func insertCustomModel(q sqlx.Queryer, model *AvailableModel) error {
+	query := `INSERT INTO custom_models (org_id, provider, custom_provider, base_url, model_name, description, max_tokens, api_key_env_var, default_max_convo_tokens, max_output_tokens, reserved_output_tokens, preferred_output_format, has_image_support, input_price_per_million, output_price_per_million, cached_input_price_per_million) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16)
 	RETURNING id, created_at, updated_at`
 
-	err := Conn.QueryRow(query, model.OrgId, model.Provider, model.CustomProvider, model.BaseUrl, model.ModelName, model.Description, model.MaxTokens, model.ApiKeyEnvVar, model.DefaultMaxConvoTokens, model.MaxOutputTokens, model.ReservedOutputTokens, model.PreferredOutputFormat, model.HasImageSupport).Scan(&model.Id, &model.CreatedAt, &model.UpdatedAt)
+	err := q.QueryRowx(query, model.OrgId, model.Provider, model.CustomProvider, model.BaseUrl, model.ModelName, model.Description, model.MaxTokens, model.ApiKeyEnvVar, model.DefaultMaxConvoTokens, model.MaxOutputTokens, model.ReservedOutputTokens, model.PreferredOutputFormat, model.HasImageSupport, model.InputPricePerMillion, model.OutputPricePerMillion, model.CachedInputPricePerMillion).Scan(&model.Id, &model.CreatedAt, &model.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("error inserting new custom model: %v", err)
 	}
@@ -16,6 +22,18 @@ func CreateCustomModel(model *AvailableModel) error {
 	return nil
 }
 
+func updateCustomModel(e sqlx.Execer, model *AvailableModel) error {
+	query := `UPDATE custom_models SET provider = $1, custom_provider = $2, base_url = $3, model_name = $4, description = $5, max_tokens = $6, api_key_env_var = $7, default_max_convo_tokens = $8, max_output_tokens = $9, reserved_output_tokens = $10, preferred_output_format = $11, has_image_support = $12, input_price_per_million = $13, output_price_per_million = $14, cached_input_price_per_million = $15
+	WHERE id = $16 AND org_id = $17`
+
+	_, err := e.Exec(query, model.Provider, model.CustomProvider, model.BaseUrl, model.ModelName, model.Description, model.MaxTokens, model.ApiKeyEnvVar, model.DefaultMaxConvoTokens, model.MaxOutputTokens, model.ReservedOutputTokens, model.PreferredOutputFormat, model.HasImageSupport, model.InputPricePerMillion, model.OutputPricePerMillion, model.CachedInputPricePerMillion, model.Id, model.OrgId)
+	if err != nil {
+		return fmt.Errorf("error updating custom model: %v", err)
+	}
+
+	return nil
+}
+
 func ListCustomModels(orgId string) ([]*AvailableModel, error) {
 	var models []*AvailableModel
 
@@ -42,10 +60,14 @@ func DeleteAvailableModel(modelId string) error {
 }
 
 func CreateModelPack(ms *ModelPack) error {
-	query := `INSERT INTO model_sets (org_id, name, description, planner, plan_summary, builder, namer, commit_msg, exec_status, context_loader, coder) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+	return insertModelPack(Conn, ms)
+}
+
+func insertModelPack(q sqlx.Queryer, ms *ModelPack) error {
+	query := `INSERT INTO model_sets (org_id, name, description, planner, plan_summary, builder, whole_file_builder, namer, commit_msg, exec_status, context_loader, coder) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
 	RETURNING id, created_at`
 
-	err := Conn.QueryRow(query, ms.OrgId, ms.Name, ms.Description, ms.Planner, ms.PlanSummary, ms.Builder, ms.Namer, ms.CommitMsg, ms.ExecStatus, ms.Architect, ms.Coder).Scan(&ms.Id, &ms.CreatedAt)
+	err := q.QueryRowx(query, ms.OrgId, ms.Name, ms.Description, ms.Planner, ms.PlanSummary, ms.Builder, ms.WholeFileBuilder, ms.Namer, ms.CommitMsg, ms.ExecStatus, ms.Architect, ms.Coder).Scan(&ms.Id, &ms.CreatedAt)
 
 	if err != nil {
 		return fmt.Errorf("error inserting new model pack: %v", err)
@@ -54,6 +76,32 @@ func CreateModelPack(ms *ModelPack) error {
 	return nil
 }
 
+// UpdateModelPack replaces every role config of an existing pack
+func UpdateModelPack(ms *ModelPack) error {
+	return updateModelPack(Conn, ms)
+}
+
+func updateModelPack(e sqlx.Execer, ms *ModelPack) error {
+	query := `UPDATE model_sets SET name = $1, description = $2, planner = $3, plan_summary = $4, builder = $5, whole_file_builder = $6, namer = $7, commit_msg = $8, exec_status = $9, context_loader = $10, coder = $11
+	WHERE id = $12 AND org_id = $13`
+
+	res, err := e.Exec(query, ms.Name, ms.Description, ms.Planner, ms.PlanSummary, ms.Builder, ms.WholeFileBuilder, ms.Namer, ms.CommitMsg, ms.ExecStatus, ms.Architect, ms.Coder, ms.Id, ms.OrgId)
+	if err != nil {
+		return fmt.Errorf("error updating model pack: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("model pack not found")
+	}
+
+	return nil
+}
+
 func ListModelPacks(orgId string) ([]*ModelPack, error) {
 	var modelPacks []*ModelPack
 
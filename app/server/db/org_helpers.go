@@ -206,6 +206,16 @@ func CreateOrgUser(orgId, userId, orgRoleId string, tx *sqlx.Tx) error {
 	return nil
 }
 
+func UpdateOrgUserRole(orgId, userId, orgRoleId string, tx *sqlx.Tx) error {
+	_, err := tx.Exec("UPDATE orgs_users SET org_role_id = $1 WHERE org_id = $2 AND user_id = $3", orgRoleId, orgId, userId)
+
+	if err != nil {
+		return fmt.Errorf("error updating org member role: %v", err)
+	}
+
+	return nil
+}
+
 func ListOrgRoles(orgId string) ([]*OrgRole, error) {
 	var orgRoles []*OrgRole
 	err := Conn.Select(&orgRoles, "SELECT * FROM org_roles WHERE org_id IS NULL OR org_id = $1", orgId)
@@ -0,0 +1,288 @@
+package db
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// A plan archive is a tar.gz that holds everything needed to recreate a plan on another server:
+//   - manifest.json: the plan, its branches, and its conversation summaries from the db
+//   - repo.fast-export: the plan's git repo (contexts, convo messages, results, subtasks, descriptions) with the history of every branch
+// On import, the plan, branches, and summaries get fresh ids under the target org and project. The ids that are embedded in the repo's files (org, plan, and owner) are swapped for their new values throughout the history, so rewinding an imported plan works the same as rewinding the original.
+
+const PlanArchiveVersion = 1
+
+const (
+	planArchiveManifestName = "manifest.json"
+	planArchiveRepoName     = "repo.fast-export"
+)
+
+type PlanArchiveManifest struct {
+	Version    int             `json:"version"`
+	ExportedAt time.Time       `json:"exportedAt"`
+	Plan       *Plan           `json:"plan"`
+	Branches   []*Branch       `json:"branches"`
+	Summaries  []*ConvoSummary `json:"summaries"`
+}
+
+type PlanArchive struct {
+	Manifest   PlanArchiveManifest
+	RepoStream []byte
+}
+
+// WritePlanArchive should be called with a read lock on the plan's repo
+func WritePlanArchive(repo *GitRepo, plan *Plan, w io.Writer) error {
+	branches, err := ListPlanBranches(repo, plan.Id)
+	if err != nil {
+		return err
+	}
+
+	var summaries []*ConvoSummary
+	err = Conn.Select(&summaries, "SELECT * FROM convo_summaries WHERE plan_id = $1 ORDER BY created_at", plan.Id)
+	if err != nil {
+		return fmt.Errorf("error getting plan summaries: %v", err)
+	}
+
+	stream, err := repo.GitFastExport()
+	if err != nil {
+		return err
+	}
+
+	manifestBytes, err := json.Marshal(PlanArchiveManifest{
+		Version:    PlanArchiveVersion,
+		ExportedAt: time.Now(),
+		Plan:       plan,
+		Branches:   branches,
+		Summaries:  summaries,
+	})
+	if err != nil {
+		return fmt.Errorf("error marshalling plan archive manifest: %v", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+
+	for _, file := range []struct {
+		name string
+		body []byte
+	}{
+		{planArchiveManifestName, manifestBytes},
+		{planArchiveRepoName, stream},
+	} {
+		err = tw.WriteHeader(&tar.Header{
+			Name:    file.name,
+			Mode:    0644,
+			Size:    int64(len(file.body)),
+			ModTime: time.Now(),
+		})
+		if err != nil {
+			return fmt.Errorf("error writing plan archive header for %s: %v", file.name, err)
+		}
+
+		_, err = tw.Write(file.body)
+		if err != nil {
+			return fmt.Errorf("error writing plan archive file %s: %v", file.name, err)
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return fmt.Errorf("error closing plan archive: %v", err)
+	}
+
+	err = gz.Close()
+	if err != nil {
+		return fmt.Errorf("error closing plan archive gzip writer: %v", err)
+	}
+
+	return nil
+}
+
+func ReadPlanArchive(r io.Reader) (*PlanArchive, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("error reading plan archive gzip: %v", err)
+	}
+	defer gz.Close()
+
+	var archive PlanArchive
+	var hasManifest, hasRepo bool
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading plan archive: %v", err)
+		}
+
+		switch header.Name {
+		case planArchiveManifestName:
+			err = json.NewDecoder(tr).Decode(&archive.Manifest)
+			if err != nil {
+				return nil, fmt.Errorf("error unmarshalling plan archive manifest: %v", err)
+			}
+			hasManifest = true
+		case planArchiveRepoName:
+			archive.RepoStream, err = io.ReadAll(tr)
+			if err != nil {
+				return nil, fmt.Errorf("error reading plan archive repo: %v", err)
+			}
+			hasRepo = true
+		}
+	}
+
+	if !hasManifest || !hasRepo || archive.Manifest.Plan == nil {
+		return nil, fmt.Errorf("invalid plan archive: missing %s or %s", planArchiveManifestName, planArchiveRepoName)
+	}
+
+	if archive.Manifest.Version > PlanArchiveVersion {
+		return nil, fmt.Errorf("plan archive version %d is newer than the latest supported version (%d) - upgrade the server to import it", archive.Manifest.Version, PlanArchiveVersion)
+	}
+
+	return &archive, nil
+}
+
+// ImportPlanArchive creates a new plan from an archive under the given org and project, owned by userId
+func ImportPlanArchive(ctx context.Context, archive *PlanArchive, orgId, projectId, userId, name string) (*Plan, error) {
+	source := archive.Manifest.Plan
+
+	plan := &Plan{
+		OrgId:          orgId,
+		OwnerId:        userId,
+		ProjectId:      projectId,
+		Name:           name,
+		TotalReplies:   source.TotalReplies,
+		ActiveBranches: len(archive.Manifest.Branches),
+		PlanConfig:     source.PlanConfig,
+	}
+
+	var planDirCreated bool
+	err := WithTx(ctx, "import plan", func(tx *sqlx.Tx) error {
+		err := tx.QueryRow(
+			`INSERT INTO plans (org_id, owner_id, project_id, name, plan_config, total_replies, active_branches)
+			VALUES ($1, $2, $3, $4, $5, $6, $7)
+			RETURNING id, created_at, updated_at`,
+			plan.OrgId,
+			plan.OwnerId,
+			plan.ProjectId,
+			plan.Name,
+			plan.PlanConfig,
+			plan.TotalReplies,
+			plan.ActiveBranches,
+		).Scan(&plan.Id, &plan.CreatedAt, &plan.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("error creating plan: %v", err)
+		}
+
+		_, err = tx.Exec("INSERT INTO lockable_plan_ids (plan_id) VALUES ($1)", plan.Id)
+		if err != nil {
+			return fmt.Errorf("error inserting lockable plan id: %v", err)
+		}
+
+		// branches are ordered by creation, so a parent is always inserted before its children
+		branchIds := map[string]string{}
+		for _, branch := range archive.Manifest.Branches {
+			var parentBranchId *string
+			if branch.ParentBranchId != nil {
+				if id, ok := branchIds[*branch.ParentBranchId]; ok {
+					parentBranchId = &id
+				}
+			}
+
+			var id string
+			err = tx.QueryRow(
+				`INSERT INTO branches (org_id, owner_id, plan_id, parent_branch_id, name, status, error, context_tokens, convo_tokens)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+				RETURNING id`,
+				plan.OrgId,
+				plan.OwnerId,
+				plan.Id,
+				parentBranchId,
+				branch.Name,
+				branch.Status,
+				branch.Error,
+				branch.ContextTokens,
+				branch.ConvoTokens,
+			).Scan(&id)
+			if err != nil {
+				return fmt.Errorf("error creating branch %s: %v", branch.Name, err)
+			}
+			branchIds[branch.Id] = id
+		}
+
+		for _, summary := range archive.Manifest.Summaries {
+			_, err = tx.Exec(
+				`INSERT INTO convo_summaries (org_id, plan_id, latest_convo_message_id, latest_convo_message_created_at, summary, tokens, num_messages)
+				VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+				plan.OrgId,
+				plan.Id,
+				summary.LatestConvoMessageId,
+				summary.LatestConvoMessageCreatedAt,
+				summary.Summary,
+				summary.Tokens,
+				summary.NumMessages,
+			)
+			if err != nil {
+				return fmt.Errorf("error creating convo summary: %v", err)
+			}
+		}
+
+		stream, err := replaceArchiveIds(archive.RepoStream, map[string]string{
+			source.Id:      plan.Id,
+			source.OrgId:   plan.OrgId,
+			source.OwnerId: plan.OwnerId,
+		})
+		if err != nil {
+			return err
+		}
+
+		planDirCreated = true
+		err = InitPlan(plan.OrgId, plan.Id)
+		if err != nil {
+			return fmt.Errorf("error initializing plan dir: %v", err)
+		}
+
+		return gitFastImport(getPlanDir(plan.OrgId, plan.Id), stream)
+	})
+
+	if err != nil {
+		// the plan dir is created inside the transaction, so it's orphaned if the import or the commit fails
+		if planDirCreated {
+			deleteErr := DeletePlanDir(plan.OrgId, plan.Id)
+			if deleteErr != nil {
+				log.Printf("Error cleaning up plan dir after failed import: %v\n", deleteErr)
+			}
+		}
+		return nil, err
+	}
+
+	log.Printf("Imported plan %s from archive of plan %s with %d branches and %d summaries\n", plan.Id, source.Id, len(archive.Manifest.Branches), len(archive.Manifest.Summaries))
+
+	return plan, nil
+}
+
+// ids are replaced byte-for-byte in the fast-export stream, which is only valid since old and new ids are all uuids of the same length -- the stream's data lengths stay correct
+func replaceArchiveIds(stream []byte, ids map[string]string) ([]byte, error) {
+	for from, to := range ids {
+		if from == to {
+			continue
+		}
+		if len(from) != len(to) {
+			return nil, fmt.Errorf("can't replace archive id %s with %s - ids must be the same length", from, to)
+		}
+		stream = bytes.ReplaceAll(stream, []byte(from), []byte(to))
+	}
+	return stream, nil
+}
@@ -0,0 +1,201 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestPlanArchiveRoundTrip(t *testing.T) {
+	user, org, projectId := setupSqliteTestDb(t)
+	ctx := context.Background()
+
+	plan, err := CreatePlan(ctx, org.Id, projectId, user.Id, "archived plan")
+	if err != nil {
+		t.Fatalf("error creating plan: %v", err)
+	}
+	repo := getGitRepo(org.Id, plan.Id)
+
+	writeMessage := func(id, content string) {
+		msg := `{"id":"` + id + `","orgId":"` + org.Id + `","planId":"` + plan.Id + `","userId":"` + user.Id + `","message":"` + content + `"}`
+		err := os.WriteFile(filepath.Join(getPlanConversationDir(org.Id, plan.Id), id+".json"), []byte(msg), 0644)
+		if err != nil {
+			t.Fatalf("error writing convo message: %v", err)
+		}
+	}
+
+	writeMessage("msg-1", "first")
+	if err := repo.GitAddAndCommit("main", "first message"); err != nil {
+		t.Fatalf("error committing: %v", err)
+	}
+
+	mainBranch, err := GetDbBranch(plan.Id, "main")
+	if err != nil {
+		t.Fatalf("error getting main branch: %v", err)
+	}
+	err = WithTx(ctx, "test create branch", func(tx *sqlx.Tx) error {
+		_, err := CreateBranch(repo, plan, mainBranch, "feature", tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error creating branch: %v", err)
+	}
+	writeMessage("msg-2", "second")
+	if err := repo.GitAddAndCommit("feature", "second message"); err != nil {
+		t.Fatalf("error committing: %v", err)
+	}
+	if err := repo.GitCheckoutBranch("main"); err != nil {
+		t.Fatalf("error checking out main: %v", err)
+	}
+
+	err = StoreSummary(&ConvoSummary{
+		OrgId:                       org.Id,
+		PlanId:                      plan.Id,
+		LatestConvoMessageId:        "msg-1",
+		LatestConvoMessageCreatedAt: time.Now(),
+		Summary:                     "summary",
+		Tokens:                      10,
+		NumMessages:                 1,
+	})
+	if err != nil {
+		t.Fatalf("error storing summary: %v", err)
+	}
+
+	var buf bytes.Buffer
+	err = WritePlanArchive(repo, plan, &buf)
+	if err != nil {
+		t.Fatalf("error writing archive: %v", err)
+	}
+
+	// import into a different org, owned by a different user
+	var user2 *User
+	var org2 *Org
+	var projectId2 string
+	err = WithTx(ctx, "test import setup", func(tx *sqlx.Tx) error {
+		var err error
+		user2, err = CreateUser("Test 2", "test2@example.com", tx)
+		if err != nil {
+			return err
+		}
+		org2, err = CreateOrg(&shared.CreateOrgRequest{Name: "Test Org 2"}, user2.Id, nil, tx)
+		if err != nil {
+			return err
+		}
+		projectId2, err = CreateProject(org2.Id, "test", tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error creating import target: %v", err)
+	}
+
+	archive, err := ReadPlanArchive(&buf)
+	if err != nil {
+		t.Fatalf("error reading archive: %v", err)
+	}
+
+	imported, err := ImportPlanArchive(ctx, archive, org2.Id, projectId2, user2.Id, "imported")
+	if err != nil {
+		t.Fatalf("error importing archive: %v", err)
+	}
+
+	if imported.Id == plan.Id {
+		t.Fatal("expected imported plan to get a fresh id")
+	}
+
+	bytes, err := os.ReadFile(filepath.Join(getPlanConversationDir(org2.Id, imported.Id), "msg-1.json"))
+	if err != nil {
+		t.Fatalf("error reading imported convo message: %v", err)
+	}
+	msg := string(bytes)
+	if !strings.Contains(msg, imported.Id) || !strings.Contains(msg, org2.Id) || !strings.Contains(msg, user2.Id) {
+		t.Errorf("expected imported message to reference new ids, got %s", msg)
+	}
+	if strings.Contains(msg, plan.Id) || strings.Contains(msg, org.Id) || strings.Contains(msg, user.Id) {
+		t.Errorf("expected imported message not to reference old ids, got %s", msg)
+	}
+
+	importedRepo := getGitRepo(org2.Id, imported.Id)
+	branches, err := ListPlanBranches(importedRepo, imported.Id)
+	if err != nil {
+		t.Fatalf("error listing imported branches: %v", err)
+	}
+	if len(branches) != 2 {
+		t.Fatalf("expected 2 imported branches, got %d", len(branches))
+	}
+	branchesByName := map[string]*Branch{}
+	for _, branch := range branches {
+		branchesByName[branch.Name] = branch
+	}
+	mainId := branchesByName["main"].Id
+	feature := branchesByName["feature"]
+	if feature == nil || feature.ParentBranchId == nil || *feature.ParentBranchId != mainId {
+		t.Errorf("expected feature branch with imported main as parent, got %+v", feature)
+	}
+
+	if err := importedRepo.GitCheckoutBranch("feature"); err != nil {
+		t.Fatalf("error checking out imported feature branch: %v", err)
+	}
+	_, shas, err := importedRepo.GetGitCommitHistory("feature")
+	if err != nil {
+		t.Fatalf("error getting imported history: %v", err)
+	}
+	if len(shas) < 2 {
+		t.Errorf("expected branch history to be preserved, got %d commits", len(shas))
+	}
+
+	summaries, err := GetPlanSummaries(imported.Id, []string{"msg-1"})
+	if err != nil {
+		t.Fatalf("error getting imported summaries: %v", err)
+	}
+	if len(summaries) != 1 || summaries[0].Summary != "summary" || summaries[0].OrgId != org2.Id {
+		t.Errorf("expected imported summary, got %+v", summaries)
+	}
+}
+
+func TestImportPlanArchiveCleansUpOnFailure(t *testing.T) {
+	user, org, projectId := setupSqliteTestDb(t)
+	ctx := context.Background()
+
+	archive := &PlanArchive{
+		Manifest: PlanArchiveManifest{
+			Version: 1,
+			Plan: &Plan{
+				Id:      "00000000-0000-0000-0000-000000000000",
+				OrgId:   org.Id,
+				OwnerId: user.Id,
+			},
+			Branches: []*Branch{{Id: "00000000-0000-0000-0000-000000000001", Name: "main"}},
+		},
+		RepoStream: []byte("not a fast-import stream\n"),
+	}
+
+	_, err := ImportPlanArchive(ctx, archive, org.Id, projectId, user.Id, "imported")
+	if err == nil {
+		t.Fatal("expected import of an invalid repo stream to fail")
+	}
+
+	entries, err := os.ReadDir(filepath.Join(getOrgDir(org.Id), "plans"))
+	if err != nil && !os.IsNotExist(err) {
+		t.Fatalf("error reading plans dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected the plan dir to be cleaned up, found %d", len(entries))
+	}
+
+	var numPlans int
+	err = Conn.Get(&numPlans, "SELECT COUNT(*) FROM plans WHERE project_id = $1", projectId)
+	if err != nil {
+		t.Fatalf("error counting plans: %v", err)
+	}
+	if numPlans != 0 {
+		t.Errorf("expected the plan rows to be rolled back, found %d plans", numPlans)
+	}
+}
@@ -0,0 +1,98 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+func CreatePlanTemplate(template *PlanTemplate) error {
+	err := Conn.QueryRow(
+		"INSERT INTO plan_templates (org_id, created_by, name, description, notes, context_paths, config, model_pack, prompt) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id, created_at, updated_at",
+		template.OrgId,
+		template.CreatedBy,
+		template.Name,
+		template.Description,
+		template.Notes,
+		template.ContextPaths,
+		template.Config,
+		template.ModelPack,
+		template.Prompt,
+	).Scan(&template.Id, &template.CreatedAt, &template.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating plan template: %v", err)
+	}
+
+	return nil
+}
+
+func GetPlanTemplateByName(orgId, name string) (*PlanTemplate, error) {
+	var template PlanTemplate
+	err := Conn.Get(&template, "SELECT * FROM plan_templates WHERE org_id = $1 AND name = $2", orgId, name)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting plan template: %v", err)
+	}
+
+	return &template, nil
+}
+
+func ListPlanTemplates(orgId string) ([]*PlanTemplate, error) {
+	var templates []*PlanTemplate
+	err := Conn.Select(&templates, "SELECT * FROM plan_templates WHERE org_id = $1 ORDER BY name", orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing plan templates: %v", err)
+	}
+
+	return templates, nil
+}
+
+// UpdatePlanTemplate replaces every field of the template with the given id, including its name
+func UpdatePlanTemplate(template *PlanTemplate) error {
+	err := Conn.QueryRow(
+		"UPDATE plan_templates SET name = $1, description = $2, notes = $3, context_paths = $4, config = $5, model_pack = $6, prompt = $7 WHERE org_id = $8 AND id = $9 RETURNING updated_at",
+		template.Name,
+		template.Description,
+		template.Notes,
+		template.ContextPaths,
+		template.Config,
+		template.ModelPack,
+		template.Prompt,
+		template.OrgId,
+		template.Id,
+	).Scan(&template.UpdatedAt)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("plan template not found")
+		}
+
+		return fmt.Errorf("error updating plan template: %v", err)
+	}
+
+	return nil
+}
+
+func DeletePlanTemplate(orgId, name string) error {
+	res, err := Conn.Exec("DELETE FROM plan_templates WHERE org_id = $1 AND name = $2", orgId, name)
+
+	if err != nil {
+		return fmt.Errorf("error deleting plan template: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("plan template not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestPlanTemplates(t *testing.T) {
+	user, org, _ := setupSqliteTestDb(t)
+
+	template := &PlanTemplate{
+		OrgId:        org.Id,
+		CreatedBy:    &user.Id,
+		Name:         "api-endpoint",
+		Notes:        shared.StringList{"Follow the handler conventions in handlers/"},
+		ContextPaths: shared.StringList{"handlers/*.go", "routes/**"},
+		Config:       shared.PlanConfigOverrides{"autoApply": true},
+		ModelPack:    shared.StrongModelPack.Name,
+		Prompt:       "Add a new endpoint",
+	}
+
+	err := CreatePlanTemplate(template)
+	if err != nil {
+		t.Fatalf("error creating plan template: %v", err)
+	}
+	if template.Id == "" {
+		t.Fatal("expected the template id to be set")
+	}
+
+	found, err := GetPlanTemplateByName(org.Id, "api-endpoint")
+	if err != nil {
+		t.Fatalf("error getting plan template: %v", err)
+	}
+	if found == nil || len(found.ContextPaths) != 2 || found.Config["autoApply"] != true || found.ModelPack != shared.StrongModelPack.Name {
+		t.Fatalf("unexpected plan template: %+v", found)
+	}
+
+	// names are unique within an org
+	err = CreatePlanTemplate(&PlanTemplate{OrgId: org.Id, Name: "api-endpoint"})
+	if err == nil {
+		t.Error("expected an error creating a template with a duplicate name")
+	}
+
+	found.Name = "endpoint"
+	found.ContextPaths = nil
+	err = UpdatePlanTemplate(found)
+	if err != nil {
+		t.Fatalf("error updating plan template: %v", err)
+	}
+
+	templates, err := ListPlanTemplates(org.Id)
+	if err != nil {
+		t.Fatalf("error listing plan templates: %v", err)
+	}
+	if len(templates) != 1 || templates[0].Name != "endpoint" || len(templates[0].ContextPaths) != 0 || len(templates[0].Notes) != 1 {
+		t.Fatalf("unexpected plan templates: %+v", templates)
+	}
+
+	err = DeletePlanTemplate(org.Id, "api-endpoint")
+	if err == nil {
+		t.Error("expected an error deleting a template by its old name")
+	}
+
+	err = DeletePlanTemplate(org.Id, "endpoint")
+	if err != nil {
+		t.Fatalf("error deleting plan template: %v", err)
+	}
+
+	found, err = GetPlanTemplateByName(org.Id, "endpoint")
+	if err != nil {
+		t.Fatalf("error getting plan template: %v", err)
+	}
+	if found != nil {
+		t.Fatalf("expected the template to be deleted, got %+v", found)
+	}
+}
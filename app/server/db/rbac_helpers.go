@@ -1,6 +1,7 @@
 package db
 
 import (
+	"database/sql"
 	"fmt"
 	"log"
 )
@@ -97,3 +98,19 @@ func cacheOrgMemberRoleId() error {
 
 	return nil
 }
+
+// GetOrgRoleByName looks up a built-in role or one of the org's custom roles -- nil if there's no role with that name
+func GetOrgRoleByName(orgId, name string) (*OrgRole, error) {
+	var orgRole OrgRole
+	err := Conn.Get(&orgRole, "SELECT * FROM org_roles WHERE (org_id IS NULL OR org_id = $1) AND name = $2", orgId, name)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting org role: %v", err)
+	}
+
+	return &orgRole, nil
+}
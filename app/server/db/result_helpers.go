@@ -866,39 +866,76 @@ func RejectPlanFile(orgId, planId, filePathOrResultId string, now time.Time) err
 	return nil
 }
 
-func RejectReplacement(orgId, planId, resultId, replacementId string) error {
+// RejectReplacements rejects individual replacements (or whole results when no replacement id is given) and stores edits to
+// the new content of replacements, so that only the remaining hunks are applied
+func RejectReplacements(orgId, planId string, req *shared.RejectReplacementsRequest, now time.Time) error {
 	resultsDir := getPlanResultsDir(orgId, planId)
 
-	bytes, err := os.ReadFile(filepath.Join(resultsDir, resultId+".json"))
+	rejectedByResultId := map[string][]string{}
+	editsByResultId := map[string][]shared.ReplacementEdit{}
+	var resultIds []string
+	seenResultIds := map[string]bool{}
+	addResultId := func(resultId string) {
+		if !seenResultIds[resultId] {
+			seenResultIds[resultId] = true
+			resultIds = append(resultIds, resultId)
+		}
+	}
 
-	if err != nil {
-		return fmt.Errorf("error reading result file: %v", err)
+	for _, ref := range req.Rejected {
+		addResultId(ref.ResultId)
+		rejectedByResultId[ref.ResultId] = append(rejectedByResultId[ref.ResultId], ref.ReplacementId)
+	}
+	for _, edit := range req.Edited {
+		addResultId(edit.ResultId)
+		editsByResultId[edit.ResultId] = append(editsByResultId[edit.ResultId], edit)
 	}
 
-	var result PlanFileResult
-	err = json.Unmarshal(bytes, &result)
+	for _, resultId := range resultIds {
+		result, err := GetPlanFileResultById(orgId, planId, resultId)
+		if err != nil {
+			return err
+		}
 
-	if err != nil {
-		return fmt.Errorf("error unmarshalling result file: %v", err)
-	}
+		if result.AppliedAt != nil || result.RejectedAt != nil {
+			return fmt.Errorf("result is no longer pending: %s", resultId)
+		}
 
-	if result.RejectedAt != nil {
-		return nil
-	}
+		replacementsById := map[string]*shared.Replacement{}
+		for _, replacement := range result.Replacements {
+			replacementsById[replacement.Id] = replacement
+		}
 
-	now := time.Now()
+		for _, replacementId := range rejectedByResultId[resultId] {
+			if replacementId == "" {
+				result.RejectedAt = &now
+				continue
+			}
 
-	foundReplacement := false
-	for _, replacement := range result.Replacements {
-		if replacement.Id == replacementId {
-			replacement.RejectedAt = &now
-			foundReplacement = true
-			break
+			replacement, ok := replacementsById[replacementId]
+			if !ok {
+				return fmt.Errorf("replacement not found: %s", replacementId)
+			}
+			replacement.SetRejected(now)
+		}
+
+		for _, edit := range editsByResultId[resultId] {
+			replacement, ok := replacementsById[edit.ReplacementId]
+			if !ok {
+				return fmt.Errorf("replacement not found: %s", edit.ReplacementId)
+			}
+			replacement.New = edit.New
 		}
-	}
 
-	if !foundReplacement {
-		return fmt.Errorf("replacement not found: %s", replacementId)
+		bytes, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error marshalling result: %v", err)
+		}
+
+		err = os.WriteFile(filepath.Join(resultsDir, result.Id+".json"), bytes, 0644)
+		if err != nil {
+			return fmt.Errorf("error writing result file: %v", err)
+		}
 	}
 
 	return nil
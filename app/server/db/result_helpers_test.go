@@ -0,0 +1,94 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func TestRejectReplacements(t *testing.T) {
+	user, org, projectId := setupSqliteTestDb(t)
+
+	plan, err := CreatePlan(context.Background(), org.Id, projectId, user.Id, "review plan")
+	if err != nil {
+		t.Fatalf("error creating plan: %v", err)
+	}
+
+	storeResult := func(path string, replacements []*shared.Replacement) *PlanFileResult {
+		result := &PlanFileResult{
+			OrgId:        org.Id,
+			PlanId:       plan.Id,
+			Path:         path,
+			Replacements: replacements,
+		}
+		if err := StorePlanResult(result); err != nil {
+			t.Fatalf("error storing result: %v", err)
+		}
+		return result
+	}
+
+	edited := storeResult("main.go", []*shared.Replacement{
+		{Id: "rep-1", Old: "one", New: "1"},
+		{Id: "rep-2", Old: "two", New: "2"},
+		{Id: "rep-3", Old: "three", New: "3"},
+	})
+	removed := storeResult("old.go", []*shared.Replacement{
+		{Id: "rep-4", Old: "four", New: "4"},
+	})
+
+	err = RejectReplacements(org.Id, plan.Id, &shared.RejectReplacementsRequest{
+		Rejected: []shared.ReplacementRef{
+			{ResultId: edited.Id, ReplacementId: "rep-2"},
+			{ResultId: removed.Id},
+		},
+		Edited: []shared.ReplacementEdit{
+			{ResultId: edited.Id, ReplacementId: "rep-3", New: "THREE"},
+		},
+	}, time.Now())
+	if err != nil {
+		t.Fatalf("error rejecting replacements: %v", err)
+	}
+
+	res, err := GetPlanFileResultById(org.Id, plan.Id, edited.Id)
+	if err != nil {
+		t.Fatalf("error getting result: %v", err)
+	}
+	if res.RejectedAt != nil {
+		t.Error("expected partially rejected result to stay pending")
+	}
+	if res.Replacements[0].RejectedAt != nil || res.Replacements[1].RejectedAt == nil || res.Replacements[2].RejectedAt != nil {
+		t.Errorf("expected only rep-2 to be rejected, got %+v", res.Replacements)
+	}
+	if res.Replacements[2].New != "THREE" {
+		t.Errorf("expected rep-3 to be edited, got %q", res.Replacements[2].New)
+	}
+
+	updated, allSucceeded := shared.ApplyReplacements("one two three", res.Replacements, false)
+	if !allSucceeded || updated != "1 two THREE" {
+		t.Errorf("expected rejected hunk to be left out, got %q", updated)
+	}
+
+	res, err = GetPlanFileResultById(org.Id, plan.Id, removed.Id)
+	if err != nil {
+		t.Fatalf("error getting result: %v", err)
+	}
+	if res.RejectedAt == nil {
+		t.Error("expected whole result to be rejected")
+	}
+
+	err = RejectReplacements(org.Id, plan.Id, &shared.RejectReplacementsRequest{
+		Rejected: []shared.ReplacementRef{{ResultId: removed.Id}},
+	}, time.Now())
+	if err == nil {
+		t.Error("expected error rejecting a result that's no longer pending")
+	}
+
+	err = RejectReplacements(org.Id, plan.Id, &shared.RejectReplacementsRequest{
+		Rejected: []shared.ReplacementRef{{ResultId: edited.Id, ReplacementId: "missing"}},
+	}, time.Now())
+	if err == nil {
+		t.Error("expected error rejecting a missing replacement")
+	}
+}
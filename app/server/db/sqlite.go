@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+)
+
+// SQLite is an embedded alternative to Postgres for single-user local mode and CI, selected with DATABASE_URL=sqlite://<path>
+// Queries are written for Postgres throughout the db package, so the sqlite driver below rewrites the few Postgres-specific constructs we use ($n placeholders, NOW(), = ANY($n) with pq.Array, FOR UPDATE/FOR SHARE) as each query is run.
+
+const sqliteUrlPrefix = "sqlite://"
+const sqliteDriverName = "plandex-sqlite3"
+const sqliteBusyTimeout = 10000
+
+// the same format as mattn/go-sqlite3 uses for time.Time params, always in UTC, so timestamps sort correctly as text
+const sqliteNowExpr = "strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')"
+
+type DbDialect string
+
+const (
+	DbDialectPostgres DbDialect = "postgres"
+	DbDialectSqlite   DbDialect = "sqlite"
+)
+
+var Dialect DbDialect = DbDialectPostgres
+
+func IsSqlite() bool {
+	return Dialect == DbDialectSqlite
+}
+
+func isSqliteUrl(dbUrl string) bool {
+	return strings.HasPrefix(dbUrl, sqliteUrlPrefix)
+}
+
+func init() {
+	sql.Register(sqliteDriverName, &sqliteDriver{})
+
+	// named queries are compiled to $n placeholders like they are for postgres, then rewritten by the driver
+	sqlx.BindDriver(sqliteDriverName, sqlx.DOLLAR)
+}
+
+func connectSqlite(dbUrl string) error {
+	path := strings.TrimPrefix(dbUrl, sqliteUrlPrefix)
+	if path == "" {
+		path = filepath.Join(BaseDir, "plandex.db")
+	}
+
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error creating sqlite db dir: %v", err)
+	}
+
+	// WAL allows reads concurrent with a write, and immediate transactions avoid deadlocks from upgrading read locks to write locks
+	dsn := fmt.Sprintf("file:%s?_busy_timeout=%d&_foreign_keys=on&_journal_mode=WAL&_txlock=immediate", path, sqliteBusyTimeout)
+
+	Conn, err = sqlx.Connect(sqliteDriverName, dsn)
+	if err != nil {
+		return err
+	}
+
+	Dialect = DbDialectSqlite
+
+	Conn.SetMaxOpenConns(10)
+	Conn.SetMaxIdleConns(5)
+
+	log.Printf("connected to sqlite database at %s\n", path)
+
+	return nil
+}
+
+type sqliteDriver struct {
+	sqlite3.SQLiteDriver
+}
+
+func (d *sqliteDriver) Open(dsn string) (driver.Conn, error) {
+	conn, err := d.SQLiteDriver.Open(dsn)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteConn{SQLiteConn: conn.(*sqlite3.SQLiteConn)}, nil
+}
+
+type sqliteConn struct {
+	*sqlite3.SQLiteConn
+}
+
+func (c *sqliteConn) Prepare(query string) (driver.Stmt, error) {
+	return c.SQLiteConn.Prepare(rewriteSqliteQuery(query))
+}
+
+func (c *sqliteConn) PrepareContext(ctx context.Context, query string) (driver.Stmt, error) {
+	return c.SQLiteConn.PrepareContext(ctx, rewriteSqliteQuery(query))
+}
+
+func (c *sqliteConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	return c.SQLiteConn.ExecContext(ctx, rewriteSqliteQuery(query), args)
+}
+
+func (c *sqliteConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	return c.SQLiteConn.QueryContext(ctx, rewriteSqliteQuery(query), args)
+}
+
+// CheckNamedValue converts params that sqlite can't bind directly -- pq arrays become json arrays (for json_each) and times are stored in UTC
+func (c *sqliteConn) CheckNamedValue(nv *driver.NamedValue) error {
+	switch v := nv.Value.(type) {
+	case *pq.StringArray:
+		return setJsonNamedValue(nv, []string(*v))
+	case pq.StringArray:
+		return setJsonNamedValue(nv, []string(v))
+	case pq.GenericArray:
+		return setJsonNamedValue(nv, v.A)
+	case *pq.GenericArray:
+		return setJsonNamedValue(nv, v.A)
+	case time.Time:
+		nv.Value = v.UTC()
+		return nil
+	case *time.Time:
+		if v != nil {
+			nv.Value = v.UTC()
+			return nil
+		}
+	}
+	return driver.ErrSkip
+}
+
+func setJsonNamedValue(nv *driver.NamedValue, v any) error {
+	bytes, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("error marshalling array param: %v", err)
+	}
+	// json_each needs an array, not null
+	if string(bytes) == "null" {
+		bytes = []byte("[]")
+	}
+	nv.Value = string(bytes)
+	return nil
+}
+
+var (
+	sqliteAnyRegex       = regexp.MustCompile(`(?i)=\s*ANY\s*\(\s*\$(\d+)\s*\)`)
+	sqliteNowRegex       = regexp.MustCompile(`(?i)\bNOW\(\)`)
+	sqliteForLockRegex   = regexp.MustCompile(`(?i)\s+FOR\s+(UPDATE|SHARE)\b`)
+	sqlitePlaceholderReg = regexp.MustCompile(`\$(\d+)`)
+)
+
+// rewriteSqliteQuery translates the Postgres-specific syntax used in our queries -- string literals are left untouched
+func rewriteSqliteQuery(query string) string {
+	var sb strings.Builder
+	sb.Grow(len(query))
+
+	inLiteral := false
+	segmentStart := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] != '\'' {
+			continue
+		}
+		if inLiteral {
+			// include the closing quote with the literal (an escaped '' just closes and reopens)
+			sb.WriteString(query[segmentStart : i+1])
+		} else {
+			sb.WriteString(rewriteSqliteSegment(query[segmentStart:i]))
+			sb.WriteByte('\'')
+		}
+		inLiteral = !inLiteral
+		segmentStart = i + 1
+	}
+
+	if inLiteral {
+		sb.WriteString(query[segmentStart:])
+	} else {
+		sb.WriteString(rewriteSqliteSegment(query[segmentStart:]))
+	}
+
+	return sb.String()
+}
+
+func rewriteSqliteSegment(s string) string {
+	s = sqliteAnyRegex.ReplaceAllString(s, "IN (SELECT value FROM json_each($$$1))")
+	s = sqliteNowRegex.ReplaceAllString(s, sqliteNowExpr)
+	s = sqliteForLockRegex.ReplaceAllString(s, "")
+	// ?NNN binds by position like $n does in postgres -- a bare $n is a named param in sqlite and binds in order of first appearance
+	s = sqlitePlaceholderReg.ReplaceAllString(s, "?$1")
+	return s
+}
+
+func isSqliteUniqueErr(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || sqliteErr.ExtendedCode == sqlite3.ErrConstraintPrimaryKey
+	}
+	return false
+}
+
+func isSqliteBusyErr(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked
+	}
+	return false
+}
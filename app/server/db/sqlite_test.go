@@ -0,0 +1,180 @@
+package db
+
+import (
+	"context"
+	"path/filepath"
+	"plandex-server/shutdown"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestRewriteSqliteQuery(t *testing.T) {
+	tests := []struct {
+		query    string
+		expected string
+	}{
+		{
+			"SELECT * FROM users WHERE id = $1 AND org_id = $2",
+			"SELECT * FROM users WHERE id = ?1 AND org_id = ?2",
+		},
+		{
+			"SELECT * FROM branches WHERE plan_id = ANY($1) ORDER BY created_at",
+			"SELECT * FROM branches WHERE plan_id IN (SELECT value FROM json_each(?1)) ORDER BY created_at",
+		},
+		{
+			"UPDATE repo_locks SET last_heartbeat_at = NOW() WHERE id = $1",
+			"UPDATE repo_locks SET last_heartbeat_at = " + sqliteNowExpr + " WHERE id = ?1",
+		},
+		{
+			"SELECT * FROM default_plan_settings WHERE org_id = $1 FOR UPDATE",
+			"SELECT * FROM default_plan_settings WHERE org_id = ?1",
+		},
+		{
+			"SELECT * FROM plans WHERE name = 'costs $1 NOW()' AND id = $1",
+			"SELECT * FROM plans WHERE name = 'costs $1 NOW()' AND id = ?1",
+		},
+		{
+			"INSERT INTO permissions (description) VALUES ('Manage an org''s $2 billing') RETURNING $1",
+			"INSERT INTO permissions (description) VALUES ('Manage an org''s $2 billing') RETURNING ?1",
+		},
+	}
+
+	for _, test := range tests {
+		res := rewriteSqliteQuery(test.query)
+		if res != test.expected {
+			t.Errorf("rewriteSqliteQuery(%q)\n  got:  %q\n  want: %q", test.query, res, test.expected)
+		}
+	}
+}
+
+// setupSqliteTestDb connects to a fresh sqlite db in a temp base dir and creates a user, org, and project
+func setupSqliteTestDb(t *testing.T) (user *User, org *Org, projectId string) {
+	BaseDir = t.TempDir()
+	shutdown.ShutdownCtx = context.Background()
+
+	// role ids are cached per process, but each test db seeds its own
+	orgOwnerRoleId = ""
+	orgMemberRoleId = ""
+	t.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(BaseDir, "test.db"))
+
+	err := Connect()
+	if err != nil {
+		t.Fatalf("error connecting: %v", err)
+	}
+	t.Cleanup(func() {
+		Conn.Close()
+		Dialect = DbDialectPostgres
+	})
+
+	err = MigrationsUpWithDir("../migrations")
+	if err != nil {
+		t.Fatalf("error running migrations: %v", err)
+	}
+
+	err = WithTx(context.Background(), "test setup", func(tx *sqlx.Tx) error {
+		var err error
+		user, err = CreateUser("Test", "test@example.com", tx)
+		if err != nil {
+			return err
+		}
+		org, err = CreateOrg(&shared.CreateOrgRequest{Name: "Test Org"}, user.Id, nil, tx)
+		if err != nil {
+			return err
+		}
+		projectId, err = CreateProject(org.Id, "test", tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error creating user, org, and project: %v", err)
+	}
+
+	return user, org, projectId
+}
+
+func TestSqliteBackend(t *testing.T) {
+	user, org, projectId := setupSqliteTestDb(t)
+
+	ctx := context.Background()
+
+	err := WithTx(ctx, "test duplicate user", func(tx *sqlx.Tx) error {
+		_, err := tx.Exec("INSERT INTO users (name, email, domain) VALUES ($1, $2, $3)", "Dup", "test@example.com", "example.com")
+		return err
+	})
+	if !IsNonUniqueErr(err) {
+		t.Errorf("expected unique constraint error, got %v", err)
+	}
+
+	orgs, err := GetAccessibleOrgsForUser(user)
+	if err != nil {
+		t.Fatalf("error getting orgs: %v", err)
+	}
+	if len(orgs) != 1 || orgs[0].Id != org.Id {
+		t.Errorf("expected user's org, got %+v", orgs)
+	}
+
+	plan, err := CreatePlan(ctx, org.Id, projectId, user.Id, "draft")
+	if err != nil {
+		t.Fatalf("error creating plan: %v", err)
+	}
+	if plan.CreatedAt.IsZero() || time.Since(plan.CreatedAt) > time.Minute {
+		t.Errorf("expected created_at to be set to now, got %v", plan.CreatedAt)
+	}
+
+	lockCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	lockId, err := lockRepoDB(LockRepoParams{
+		OrgId:    org.Id,
+		UserId:   user.Id,
+		PlanId:   plan.Id,
+		Branch:   "main",
+		Scope:    LockScopeWrite,
+		Ctx:      lockCtx,
+		CancelFn: cancel,
+		Reason:   "test",
+	}, 0)
+	if err != nil {
+		t.Fatalf("error acquiring write lock: %v", err)
+	}
+
+	conflictCtx, conflictCancel := context.WithTimeout(ctx, 300*time.Millisecond)
+	defer conflictCancel()
+	_, err = lockRepoDB(LockRepoParams{
+		OrgId:    org.Id,
+		UserId:   user.Id,
+		PlanId:   plan.Id,
+		Branch:   "main",
+		Scope:    LockScopeRead,
+		Ctx:      conflictCtx,
+		CancelFn: conflictCancel,
+		Reason:   "test conflict",
+	}, 0)
+	if err == nil {
+		t.Fatal("expected read lock to conflict with write lock")
+	}
+
+	err = deleteRepoLockDB(lockId, plan.Id, "test", 0)
+	if err != nil {
+		t.Fatalf("error deleting lock: %v", err)
+	}
+
+	readCtx, readCancel := context.WithCancel(ctx)
+	defer readCancel()
+	_, err = lockRepoDB(LockRepoParams{
+		OrgId:    org.Id,
+		UserId:   user.Id,
+		PlanId:   plan.Id,
+		Branch:   "main",
+		Scope:    LockScopeRead,
+		Ctx:      readCtx,
+		CancelFn: readCancel,
+		Reason:   "test read",
+	}, 0)
+	if err != nil {
+		t.Fatalf("error acquiring read lock after release: %v", err)
+	}
+}
@@ -0,0 +1,95 @@
+package db
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var ErrSsoDomainTaken = errors.New("sso is already configured for this domain by another org")
+
+func GetOrgSsoConfig(orgId string) (*OrgSsoConfig, error) {
+	var config OrgSsoConfig
+	err := Conn.Get(&config, "SELECT * FROM org_sso_configs WHERE org_id = $1", orgId)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting sso config: %v", err)
+	}
+
+	return &config, nil
+}
+
+// GetSsoConfigForDomain finds the sso config that users with an email on this domain sign in with
+func GetSsoConfigForDomain(domain string) (*OrgSsoConfig, error) {
+	var config OrgSsoConfig
+	err := Conn.Get(&config, "SELECT * FROM org_sso_configs WHERE domain = $1", domain)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting sso config for domain: %v", err)
+	}
+
+	return &config, nil
+}
+
+// StoreOrgSsoConfig creates or replaces an org's sso config -- each org has at most one
+func StoreOrgSsoConfig(config *OrgSsoConfig) error {
+	query := `INSERT INTO org_sso_configs (org_id, domain, issuer_url, client_id, client_secret, scopes, role_claim, role_mapping)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	ON CONFLICT (org_id) DO UPDATE SET
+		domain = excluded.domain,
+		issuer_url = excluded.issuer_url,
+		client_id = excluded.client_id,
+		client_secret = excluded.client_secret,
+		scopes = excluded.scopes,
+		role_claim = excluded.role_claim,
+		role_mapping = excluded.role_mapping
+	RETURNING id, created_at, updated_at`
+
+	err := Conn.QueryRow(
+		query,
+		config.OrgId,
+		config.Domain,
+		config.IssuerUrl,
+		config.ClientId,
+		config.ClientSecret,
+		config.Scopes,
+		config.RoleClaim,
+		config.RoleMapping,
+	).Scan(&config.Id, &config.CreatedAt, &config.UpdatedAt)
+
+	if err != nil {
+		if IsNonUniqueErr(err) {
+			return ErrSsoDomainTaken
+		}
+		return fmt.Errorf("error storing sso config: %v", err)
+	}
+
+	return nil
+}
+
+func DeleteOrgSsoConfig(orgId string) error {
+	res, err := Conn.Exec("DELETE FROM org_sso_configs WHERE org_id = $1", orgId)
+
+	if err != nil {
+		return fmt.Errorf("error deleting sso config: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("sso config not found")
+	}
+
+	return nil
+}
@@ -0,0 +1,97 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func TestOrgSsoConfig(t *testing.T) {
+	user, org, _ := setupSqliteTestDb(t)
+
+	config := &OrgSsoConfig{
+		OrgId:        org.Id,
+		Domain:       "example.com",
+		IssuerUrl:    "https://idp.example.com",
+		ClientId:     "plandex",
+		ClientSecret: "secret",
+		Scopes:       shared.SsoDefaultScopes,
+		RoleClaim:    "groups",
+		RoleMapping:  shared.SsoRoleMapping{"plandex-admins": "admin"},
+	}
+	err := StoreOrgSsoConfig(config)
+	if err != nil {
+		t.Fatalf("error storing sso config: %v", err)
+	}
+
+	found, err := GetSsoConfigForDomain("example.com")
+	if err != nil {
+		t.Fatalf("error getting sso config for domain: %v", err)
+	}
+	if found == nil || found.OrgId != org.Id || found.RoleMapping["plandex-admins"] != "admin" {
+		t.Fatalf("unexpected sso config: %+v", found)
+	}
+	if api := found.ToApi(); !api.HasClientSecret {
+		t.Error("expected api config to report a client secret")
+	}
+
+	// storing again replaces the org's config
+	config.ClientId = "plandex-2"
+	config.RoleMapping = nil
+	err = StoreOrgSsoConfig(config)
+	if err != nil {
+		t.Fatalf("error updating sso config: %v", err)
+	}
+
+	found, err = GetOrgSsoConfig(org.Id)
+	if err != nil {
+		t.Fatalf("error getting sso config: %v", err)
+	}
+	if found.ClientId != "plandex-2" || len(found.RoleMapping) != 0 {
+		t.Errorf("expected updated sso config, got %+v", found)
+	}
+
+	var otherOrg *Org
+	err = WithTx(context.Background(), "test other org", func(tx *sqlx.Tx) error {
+		var err error
+		otherOrg, err = CreateOrg(&shared.CreateOrgRequest{Name: "Other"}, user.Id, nil, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error creating org: %v", err)
+	}
+
+	err = StoreOrgSsoConfig(&OrgSsoConfig{
+		OrgId:     otherOrg.Id,
+		Domain:    "example.com",
+		IssuerUrl: "https://other-idp.example.com",
+		ClientId:  "other",
+		Scopes:    shared.SsoDefaultScopes,
+	})
+	if !errors.Is(err, ErrSsoDomainTaken) {
+		t.Errorf("expected domain conflict, got %v", err)
+	}
+
+	role, err := GetOrgRoleByName(org.Id, "admin")
+	if err != nil || role == nil {
+		t.Fatalf("expected admin role, got %v, %v", role, err)
+	}
+	if role, _ := GetOrgRoleByName(org.Id, "nonexistent"); role != nil {
+		t.Errorf("expected no role, got %+v", role)
+	}
+
+	err = DeleteOrgSsoConfig(org.Id)
+	if err != nil {
+		t.Fatalf("error deleting sso config: %v", err)
+	}
+	if found, _ := GetSsoConfigForDomain("example.com"); found != nil {
+		t.Error("expected sso config to be deleted")
+	}
+	if err := DeleteOrgSsoConfig(org.Id); err == nil {
+		t.Error("expected error deleting a missing sso config")
+	}
+}
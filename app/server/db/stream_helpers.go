@@ -123,7 +123,7 @@ func GetActiveModelStream(planId, branch string) (*ModelStream, error) {
 
 func GetActiveOrRecentModelStreams(planIds []string) ([]*ModelStream, error) {
 	var streams []*ModelStream
-	err := Conn.Select(&streams, "SELECT * FROM model_streams WHERE plan_id = ANY($1) AND (finished_at IS NULL OR finished_at > NOW() - INTERVAL '1 hour') ORDER BY created_at", pq.Array(planIds))
+	err := Conn.Select(&streams, "SELECT * FROM model_streams WHERE plan_id = ANY($1) AND (finished_at IS NULL OR finished_at > $2) ORDER BY created_at", pq.Array(planIds), time.Now().UTC().Add(-time.Hour))
 
 	if err != nil {
 		return nil, fmt.Errorf("error getting active or recent model streams: %v", err)
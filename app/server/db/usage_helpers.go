@@ -0,0 +1,210 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+// UsageFilter narrows the usage ledger for reports and logs. Empty fields aren't filtered on.
+type UsageFilter struct {
+	OrgId     string
+	UserId    string
+	PlanId    string
+	SessionId string
+	Since     *time.Time
+}
+
+func StoreModelUsage(usage *ModelUsage) error {
+	err := Conn.QueryRow(
+		"INSERT INTO model_usage (org_id, user_id, plan_id, branch, model_role, model_id, model_name, provider, model_pack, purpose, input_tokens, output_tokens, cached_tokens, latency_ms, cost, session_id, stopped_early, had_error) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18) RETURNING id, created_at",
+		usage.OrgId,
+		usage.UserId,
+		usage.PlanId,
+		usage.Branch,
+		usage.ModelRole,
+		usage.ModelId,
+		usage.ModelName,
+		usage.Provider,
+		usage.ModelPack,
+		usage.Purpose,
+		usage.InputTokens,
+		usage.OutputTokens,
+		usage.CachedTokens,
+		usage.LatencyMs,
+		usage.Cost,
+		usage.SessionId,
+		usage.StoppedEarly,
+		usage.HadError,
+	).Scan(&usage.Id, &usage.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error storing model usage: %v", err)
+	}
+
+	return nil
+}
+
+// GetUsageReport totals calls, tokens, and spend by plan, user, model, and day. utcOffset is the caller's offset from UTC in seconds, so that days match the caller's local time.
+func GetUsageReport(filter UsageFilter, utcOffset int) (*shared.UsageReportResponse, error) {
+	total, err := GetUsageTotals(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &shared.UsageReportResponse{
+		Total:         total,
+		ByPlanId:      map[string]*shared.UsageSummary{},
+		PlanNamesById: map[string]string{},
+		ByUserId:      map[string]*shared.UsageSummary{},
+		UserNamesById: map[string]string{},
+		ByModel:       map[string]*shared.UsageSummary{},
+		ByDay:         map[string]*shared.UsageSummary{},
+	}
+
+	// usage for deleted plans and users is kept under an empty id
+	groups := []struct {
+		keyExpr  string
+		nameExpr string
+		keyArg   interface{}
+		summary  map[string]*shared.UsageSummary
+		names    map[string]string
+	}{
+		{"COALESCE(u.plan_id, '')", "MAX(p.name)", nil, res.ByPlanId, res.PlanNamesById},
+		{"COALESCE(u.user_id, '')", "MAX(us.name)", nil, res.ByUserId, res.UserNamesById},
+		// matches shared.ModelUsage.ModelString
+		{"CASE WHEN u.provider = $%d THEN u.model_name ELSE u.provider || '/' || u.model_name END", "NULL", shared.ModelProviderOpenAI, res.ByModel, nil},
+		{usageDayExpr(), "NULL", utcOffset, res.ByDay, nil},
+	}
+
+	for _, group := range groups {
+		where, args := filter.whereClause()
+
+		keyExpr := group.keyExpr
+		if group.keyArg != nil {
+			args = append(args, group.keyArg)
+			keyExpr = fmt.Sprintf(keyExpr, len(args))
+		}
+
+		var rows []struct {
+			Key  string  `db:"key"`
+			Name *string `db:"name"`
+			usageTotals
+		}
+
+		query := fmt.Sprintf("SELECT %s AS key, %s AS name, %s FROM model_usage u LEFT JOIN plans p ON p.id = u.plan_id LEFT JOIN users us ON us.id = u.user_id%s GROUP BY 1", keyExpr, group.nameExpr, usageTotalsSelect, where)
+
+		err := Conn.Select(&rows, query, args...)
+		if err != nil {
+			return nil, fmt.Errorf("error getting usage report: %v", err)
+		}
+
+		for _, row := range rows {
+			group.summary[row.Key] = row.usageTotals.toSummary()
+			if group.names != nil && row.Key != "" && row.Name != nil {
+				group.names[row.Key] = *row.Name
+			}
+		}
+	}
+
+	return res, nil
+}
+
+// usageDayExpr is the date of a usage record in the caller's time zone, with the offset in seconds as a query param
+func usageDayExpr() string {
+	if IsSqlite() {
+		return "strftime('%%Y-%%m-%%d', u.created_at, $%d || ' seconds')"
+	}
+	return "to_char(u.created_at + make_interval(secs => $%d), 'YYYY-MM-DD')"
+}
+
+// GetUsageTotals sums tokens and spend for the filtered usage without grouping, for checking budgets before each model call
+func GetUsageTotals(filter UsageFilter) (*shared.UsageSummary, error) {
+	where, args := filter.whereClause()
+
+	var totals usageTotals
+	err := Conn.Get(&totals, "SELECT "+usageTotalsSelect+" FROM model_usage u"+where, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error getting usage totals: %v", err)
+	}
+
+	return totals.toSummary(), nil
+}
+
+const usageTotalsSelect = "COUNT(*) AS num_calls, COALESCE(SUM(u.input_tokens), 0) AS input_tokens, COALESCE(SUM(u.output_tokens), 0) AS output_tokens, COALESCE(SUM(u.cached_tokens), 0) AS cached_tokens, SUM(u.cost) AS spend, COUNT(*) - COUNT(u.cost) AS num_unpriced"
+
+type usageTotals struct {
+	NumCalls     int                 `db:"num_calls"`
+	InputTokens  int                 `db:"input_tokens"`
+	OutputTokens int                 `db:"output_tokens"`
+	CachedTokens int                 `db:"cached_tokens"`
+	Spend        decimal.NullDecimal `db:"spend"`
+	NumUnpriced  int                 `db:"num_unpriced"`
+}
+
+func (totals usageTotals) toSummary() *shared.UsageSummary {
+	return &shared.UsageSummary{
+		NumCalls:     totals.NumCalls,
+		InputTokens:  totals.InputTokens,
+		OutputTokens: totals.OutputTokens,
+		CachedTokens: totals.CachedTokens,
+		// sqlite sums costs as floats, so round to the precision costs are stored with in postgres
+		Spend:       totals.Spend.Decimal.Round(10),
+		NumUnpriced: totals.NumUnpriced,
+	}
+}
+
+// ListModelUsage returns a page of usage records, most recent first, along with the total number of pages
+func ListModelUsage(filter UsageFilter, pageSize, pageNum int) ([]*ModelUsageEntry, int, error) {
+	where, args := filter.whereClause()
+
+	var count int
+	err := Conn.Get(&count, "SELECT COUNT(*) FROM model_usage u"+where, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error counting model usage: %v", err)
+	}
+
+	numPages := (count + pageSize - 1) / pageSize
+
+	query := fmt.Sprintf("%s%s ORDER BY u.created_at DESC LIMIT $%d OFFSET $%d", usageEntrySelect, where, len(args)+1, len(args)+2)
+	args = append(args, pageSize, (pageNum-1)*pageSize)
+
+	var entries []*ModelUsageEntry
+	err = Conn.Select(&entries, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error listing model usage: %v", err)
+	}
+
+	return entries, numPages, nil
+}
+
+const usageEntrySelect = "SELECT u.*, p.name AS plan_name, us.name AS user_name, us.email AS user_email FROM model_usage u LEFT JOIN plans p ON p.id = u.plan_id LEFT JOIN users us ON us.id = u.user_id"
+
+func (filter UsageFilter) whereClause() (string, []interface{}) {
+	conditions := []string{"u.org_id = $1"}
+	args := []interface{}{filter.OrgId}
+
+	addCondition := func(column string, value interface{}, op string) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf("%s %s $%d", column, op, len(args)))
+	}
+
+	if filter.UserId != "" {
+		addCondition("u.user_id", filter.UserId, "=")
+	}
+	if filter.PlanId != "" {
+		addCondition("u.plan_id", filter.PlanId, "=")
+	}
+	if filter.SessionId != "" {
+		addCondition("u.session_id", filter.SessionId, "=")
+	}
+	if filter.Since != nil {
+		addCondition("u.created_at", *filter.Since, ">=")
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
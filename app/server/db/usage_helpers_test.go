@@ -0,0 +1,161 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestModelUsageLedger(t *testing.T) {
+	user, org, projectId := setupSqliteTestDb(t)
+
+	plan, err := CreatePlan(context.Background(), org.Id, projectId, user.Id, "usage plan")
+	if err != nil {
+		t.Fatalf("error creating plan: %v", err)
+	}
+
+	pricing := shared.GetAvailableModel(shared.ModelProviderOpenAI, "openai/gpt-4o").Pricing
+
+	store := func(modelName shared.ModelName, input, output, cached int, cost *decimal.Decimal) {
+		usage := &ModelUsage{
+			OrgId:        org.Id,
+			UserId:       &user.Id,
+			PlanId:       &plan.Id,
+			Branch:       "main",
+			ModelRole:    shared.ModelRolePlanner,
+			ModelName:    modelName,
+			Provider:     shared.ModelProviderOpenAI,
+			InputTokens:  input,
+			OutputTokens: output,
+			CachedTokens: cached,
+		}
+		if cost != nil {
+			usage.Cost = decimal.NewNullDecimal(*cost)
+		}
+		if err := StoreModelUsage(usage); err != nil {
+			t.Fatalf("error storing usage: %v", err)
+		}
+	}
+
+	cost := pricing.Cost(1000000, 100000, 400000)
+	if !cost.Equal(decimal.RequireFromString("3")) {
+		t.Errorf("expected $3.00 for 600k uncached, 400k cached, and 100k output tokens, got %s", cost)
+	}
+
+	store("gpt-4o", 1000000, 100000, 400000, &cost)
+	store("gpt-4o", 1000, 100, 0, nil)
+	store("gpt-4o-mini", 2000, 200, 0, nil)
+
+	res, err := GetUsageReport(UsageFilter{OrgId: org.Id}, 0)
+	if err != nil {
+		t.Fatalf("error getting usage report: %v", err)
+	}
+
+	if res.Total.NumCalls != 3 || res.Total.NumUnpriced != 2 || !res.Total.Spend.Equal(cost) {
+		t.Errorf("unexpected total: %+v", res.Total)
+	}
+	if res.ByModel["gpt-4o"].NumCalls != 2 || res.ByModel["gpt-4o-mini"].InputTokens != 2000 {
+		t.Errorf("unexpected usage by model: %+v", res.ByModel)
+	}
+	if res.ByPlanId[plan.Id].NumCalls != 3 || res.PlanNamesById[plan.Id] != "usage plan" {
+		t.Errorf("unexpected usage by plan: %+v", res.ByPlanId)
+	}
+	if res.ByUserId[user.Id].NumCalls != 3 || res.UserNamesById[user.Id] != "Test" {
+		t.Errorf("unexpected usage by user: %+v", res.ByUserId)
+	}
+	if len(res.ByDay) != 1 {
+		t.Errorf("expected a single day, got %+v", res.ByDay)
+	}
+
+	future := time.Now().Add(time.Hour)
+	res, err = GetUsageReport(UsageFilter{OrgId: org.Id, Since: &future}, 0)
+	if err != nil {
+		t.Fatalf("error getting usage report: %v", err)
+	}
+	if res.Total.NumCalls != 0 {
+		t.Errorf("expected no usage after %v, got %d calls", future, res.Total.NumCalls)
+	}
+
+	entries, numPages, err := ListModelUsage(UsageFilter{OrgId: org.Id, PlanId: plan.Id}, 2, 1)
+	if err != nil {
+		t.Fatalf("error listing usage: %v", err)
+	}
+	if numPages != 2 || len(entries) != 2 {
+		t.Fatalf("expected 2 of 2 pages, got %d entries and %d pages", len(entries), numPages)
+	}
+	if entries[0].PlanName == nil || *entries[0].PlanName != "usage plan" || entries[0].UserEmail == nil {
+		t.Errorf("expected joined plan and user names, got %+v", entries[0])
+	}
+
+	entries, _, err = ListModelUsage(UsageFilter{OrgId: org.Id}, 2, 2)
+	if err != nil {
+		t.Fatalf("error listing usage: %v", err)
+	}
+	if len(entries) != 1 || !entries[0].Cost.Valid || !entries[0].Cost.Decimal.Equal(cost) {
+		t.Errorf("expected the oldest, priced call on the last page, got %+v", entries)
+	}
+}
+
+func TestUsageReportGrouping(t *testing.T) {
+	user, org, _ := setupSqliteTestDb(t)
+
+	store := func(provider shared.ModelProvider, modelName shared.ModelName, createdAt time.Time) {
+		usage := &ModelUsage{
+			OrgId:       org.Id,
+			UserId:      &user.Id,
+			Branch:      "main",
+			ModelRole:   shared.ModelRolePlanner,
+			ModelName:   modelName,
+			Provider:    provider,
+			InputTokens: 100,
+		}
+		if err := StoreModelUsage(usage); err != nil {
+			t.Fatalf("error storing usage: %v", err)
+		}
+		_, err := Conn.Exec("UPDATE model_usage SET created_at = $1 WHERE id = $2", createdAt, usage.Id)
+		if err != nil {
+			t.Fatalf("error setting created_at: %v", err)
+		}
+	}
+
+	lateUtc := time.Date(2026, 1, 1, 23, 30, 0, 0, time.UTC)
+	store(shared.ModelProviderOpenAI, "gpt-4o", lateUtc)
+	store(shared.ModelProviderAnthropic, "claude-sonnet-4", lateUtc.Add(-time.Hour))
+
+	res, err := GetUsageReport(UsageFilter{OrgId: org.Id}, 0)
+	if err != nil {
+		t.Fatalf("error getting usage report: %v", err)
+	}
+	if res.ByDay["2026-01-01"] == nil || res.ByDay["2026-01-01"].NumCalls != 2 || len(res.ByDay) != 1 {
+		t.Errorf("expected both calls on 2026-01-01 in UTC, got %+v", res.ByDay)
+	}
+	if res.ByModel["gpt-4o"] == nil || res.ByModel["anthropic/claude-sonnet-4"] == nil {
+		t.Errorf("expected usage keyed by model string, got %+v", res.ByModel)
+	}
+	// usage without a plan is kept under an empty id
+	if res.ByPlanId[""] == nil || res.ByPlanId[""].InputTokens != 200 || len(res.PlanNamesById) != 0 {
+		t.Errorf("unexpected usage by plan: %+v", res.ByPlanId)
+	}
+
+	// an hour ahead of UTC, the later call is on the next day
+	res, err = GetUsageReport(UsageFilter{OrgId: org.Id}, 60*60)
+	if err != nil {
+		t.Fatalf("error getting usage report: %v", err)
+	}
+	if res.ByDay["2026-01-01"] == nil || res.ByDay["2026-01-01"].NumCalls != 1 || res.ByDay["2026-01-02"] == nil || res.ByDay["2026-01-02"].NumCalls != 1 {
+		t.Errorf("expected calls split across days in UTC+1, got %+v", res.ByDay)
+	}
+
+	// and behind UTC, both stay on the first day
+	res, err = GetUsageReport(UsageFilter{OrgId: org.Id}, -5*60*60)
+	if err != nil {
+		t.Fatalf("error getting usage report: %v", err)
+	}
+	if res.ByDay["2026-01-01"] == nil || res.ByDay["2026-01-01"].NumCalls != 2 {
+		t.Errorf("expected both calls on 2026-01-01 in UTC-5, got %+v", res.ByDay)
+	}
+}
@@ -5,6 +5,9 @@ import (
 )
 
 func IsNonUniqueErr(err error) bool {
+	if IsSqlite() {
+		return isSqliteUniqueErr(err)
+	}
 	if err, ok := err.(*pq.Error); ok {
 		if err.Code == "23505" {
 			return true
@@ -0,0 +1,148 @@
+package db
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	shared "plandex-shared"
+)
+
+func CreateWebhook(webhook *Webhook) error {
+	err := Conn.QueryRow(
+		"INSERT INTO webhooks (org_id, created_by, url, description, secret, events) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id, created_at, updated_at",
+		webhook.OrgId,
+		webhook.CreatedBy,
+		webhook.Url,
+		webhook.Description,
+		webhook.Secret,
+		webhook.Events,
+	).Scan(&webhook.Id, &webhook.CreatedAt, &webhook.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating webhook: %v", err)
+	}
+
+	return nil
+}
+
+func GetWebhook(orgId, id string) (*Webhook, error) {
+	var webhook Webhook
+	err := Conn.Get(&webhook, "SELECT * FROM webhooks WHERE org_id = $1 AND id = $2", orgId, id)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("error getting webhook: %v", err)
+	}
+
+	return &webhook, nil
+}
+
+func ListWebhooks(orgId string) ([]*Webhook, error) {
+	var webhooks []*Webhook
+	err := Conn.Select(&webhooks, "SELECT * FROM webhooks WHERE org_id = $1 ORDER BY created_at", orgId)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhooks: %v", err)
+	}
+
+	return webhooks, nil
+}
+
+// events are stored as json, so they're filtered here rather than in the query to work the same with postgres and sqlite
+func ListWebhooksForEvent(orgId string, event shared.WebhookEvent) ([]*Webhook, error) {
+	webhooks, err := ListWebhooks(orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	var res []*Webhook
+	for _, webhook := range webhooks {
+		if webhook.Events.Includes(event) {
+			res = append(res, webhook)
+		}
+	}
+
+	return res, nil
+}
+
+func DeleteWebhook(orgId, id string) error {
+	res, err := Conn.Exec("DELETE FROM webhooks WHERE org_id = $1 AND id = $2", orgId, id)
+
+	if err != nil {
+		return fmt.Errorf("error deleting webhook: %v", err)
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("error getting rows affected: %v", err)
+	}
+
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook not found")
+	}
+
+	return nil
+}
+
+// the delivery id is set by the caller since it's included in the payload
+func CreateWebhookDelivery(delivery *WebhookDelivery) error {
+	err := Conn.QueryRow(
+		"INSERT INTO webhook_deliveries (id, org_id, webhook_id, event, payload, status) VALUES ($1, $2, $3, $4, $5, $6) RETURNING created_at, updated_at",
+		delivery.Id,
+		delivery.OrgId,
+		delivery.WebhookId,
+		delivery.Event,
+		delivery.Payload,
+		delivery.Status,
+	).Scan(&delivery.CreatedAt, &delivery.UpdatedAt)
+
+	if err != nil {
+		return fmt.Errorf("error creating webhook delivery: %v", err)
+	}
+
+	return nil
+}
+
+func UpdateWebhookDelivery(delivery *WebhookDelivery) error {
+	_, err := Conn.Exec(
+		"UPDATE webhook_deliveries SET status = $1, num_attempts = $2, response_status = $3, error = $4, delivered_at = $5 WHERE id = $6",
+		delivery.Status,
+		delivery.NumAttempts,
+		delivery.ResponseStatus,
+		delivery.Error,
+		delivery.DeliveredAt,
+		delivery.Id,
+	)
+
+	if err != nil {
+		return fmt.Errorf("error updating webhook delivery: %v", err)
+	}
+
+	return nil
+}
+
+func ListWebhookDeliveries(webhookId string, limit int) ([]*WebhookDelivery, error) {
+	var deliveries []*WebhookDelivery
+	err := Conn.Select(&deliveries, "SELECT * FROM webhook_deliveries WHERE webhook_id = $1 ORDER BY created_at DESC LIMIT $2", webhookId, limit)
+
+	if err != nil {
+		return nil, fmt.Errorf("error listing webhook deliveries: %v", err)
+	}
+
+	return deliveries, nil
+}
+
+// DeleteWebhookDeliveriesBefore prunes the delivery log
+func DeleteWebhookDeliveriesBefore(t time.Time) error {
+	_, err := Conn.Exec("DELETE FROM webhook_deliveries WHERE created_at < $1", t)
+
+	if err != nil {
+		return fmt.Errorf("error deleting webhook deliveries: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Embedder turns texts into vectors for similarity search
+type Embedder interface {
+	// Id identifies the embedder and its model -- vectors from different embedders aren't comparable, so the index is keyed by it
+	Id() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+const (
+	ProviderLocal  = "local"
+	ProviderOpenAI = "openai"
+	ProviderNone   = "none"
+
+	defaultOpenAIModel = "text-embedding-3-small"
+	openAIBatchSize    = 100
+)
+
+// GetEmbedder returns the embedder configured with the EMBEDDINGS_* environment variables, or nil if embeddings are disabled.
+// Embeddings are opt-in: they're disabled unless EMBEDDINGS_PROVIDER is set. The local hashing embedder only matches on shared
+// words and identifiers, so it's meant for tests and for servers that explicitly ask for it with EMBEDDINGS_PROVIDER=local.
+func GetEmbedder() (Embedder, error) {
+	provider := strings.ToLower(os.Getenv("EMBEDDINGS_PROVIDER"))
+
+	switch provider {
+	case "", ProviderNone:
+		return nil, nil
+	case ProviderLocal:
+		return NewHashEmbedder(defaultHashDims), nil
+	case ProviderOpenAI:
+		apiKey := os.Getenv("EMBEDDINGS_API_KEY")
+		if apiKey == "" {
+			apiKey = os.Getenv("OPENAI_API_KEY")
+		}
+		model := os.Getenv("EMBEDDINGS_MODEL")
+		if model == "" {
+			model = defaultOpenAIModel
+		}
+		return NewOpenAIEmbedder(os.Getenv("EMBEDDINGS_BASE_URL"), apiKey, model), nil
+	default:
+		return nil, fmt.Errorf("unknown EMBEDDINGS_PROVIDER: %s", provider)
+	}
+}
+
+// OpenAIEmbedder works with any OpenAI-compatible /embeddings endpoint
+type OpenAIEmbedder struct {
+	client  *openai.Client
+	baseUrl string
+	model   string
+}
+
+func NewOpenAIEmbedder(baseUrl, apiKey, model string) *OpenAIEmbedder {
+	config := openai.DefaultConfig(apiKey)
+	if baseUrl != "" {
+		config.BaseURL = baseUrl
+	}
+
+	return &OpenAIEmbedder{
+		client:  openai.NewClientWithConfig(config),
+		baseUrl: config.BaseURL,
+		model:   model,
+	}
+}
+
+func (e *OpenAIEmbedder) Id() string {
+	return ProviderOpenAI + ":" + e.baseUrl + ":" + e.model
+}
+
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	res := make([][]float32, 0, len(texts))
+
+	for start := 0; start < len(texts); start += openAIBatchSize {
+		end := min(start+openAIBatchSize, len(texts))
+
+		resp, err := e.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+			Input: texts[start:end],
+			Model: openai.EmbeddingModel(e.model),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating embeddings: %v", err)
+		}
+
+		if len(resp.Data) != end-start {
+			return nil, fmt.Errorf("expected %d embeddings, got %d", end-start, len(resp.Data))
+		}
+
+		batch := make([][]float32, end-start)
+		for _, data := range resp.Data {
+			if data.Index < 0 || data.Index >= len(batch) {
+				return nil, fmt.Errorf("embedding index out of range: %d", data.Index)
+			}
+			batch[data.Index] = data.Embedding
+		}
+
+		res = append(res, batch...)
+	}
+
+	return res, nil
+}
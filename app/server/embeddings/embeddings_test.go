@@ -0,0 +1,194 @@
+package embeddings
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"plandex-server/db"
+	"plandex-server/shutdown"
+	"reflect"
+	"testing"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// org role ids are cached per process by the db package, so all tests share one db
+func TestMain(m *testing.M) {
+	baseDir, err := os.MkdirTemp("", "plandex-embeddings-test")
+	if err != nil {
+		log.Fatalf("error creating temp dir: %v", err)
+	}
+
+	db.BaseDir = baseDir
+	shutdown.ShutdownCtx = context.Background()
+	os.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(baseDir, "test.db"))
+
+	err = db.Connect()
+	if err != nil {
+		log.Fatalf("error connecting: %v", err)
+	}
+
+	err = db.MigrationsUpWithDir("../migrations")
+	if err != nil {
+		log.Fatalf("error running migrations: %v", err)
+	}
+
+	code := m.Run()
+
+	db.Conn.Close()
+	os.RemoveAll(baseDir)
+	os.Exit(code)
+}
+
+func setupProject(t *testing.T) (orgId, projectId string) {
+	err := db.WithTx(context.Background(), "test setup", func(tx *sqlx.Tx) error {
+		user, err := db.CreateUser("Test", t.Name()+"@example.com", tx)
+		if err != nil {
+			return err
+		}
+		org, err := db.CreateOrg(&shared.CreateOrgRequest{Name: t.Name()}, user.Id, nil, tx)
+		if err != nil {
+			return err
+		}
+		orgId = org.Id
+		projectId, err = db.CreateProject(org.Id, "test", tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error creating project: %v", err)
+	}
+	return orgId, projectId
+}
+
+type countingEmbedder struct {
+	*HashEmbedder
+	numEmbedded int
+}
+
+func (e *countingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.numEmbedded += len(texts)
+	return e.HashEmbedder.Embed(ctx, texts)
+}
+
+var testMapParts = shared.FileMapBodies{
+	"auth/session.go":    "func CreateSession(userId string) (*Session, error)\nfunc ValidateSessionToken(token string) bool\ntype Session struct\n  - UserId string\n  - ExpiresAt time.Time",
+	"billing/invoice.go": "func GenerateInvoice(orgId string) (*Invoice, error)\nfunc SendInvoiceEmail(invoice *Invoice) error",
+	"ui/button.tsx":      "export function Button(props: ButtonProps)\ninterface ButtonProps",
+}
+
+func TestGetEmbedder(t *testing.T) {
+	for _, provider := range []string{"", "none"} {
+		t.Setenv("EMBEDDINGS_PROVIDER", provider)
+		embedder, err := GetEmbedder()
+		if err != nil || embedder != nil {
+			t.Errorf("expected embeddings to be disabled for provider %q, got %v, %v", provider, embedder, err)
+		}
+	}
+
+	t.Setenv("EMBEDDINGS_PROVIDER", "local")
+	embedder, err := GetEmbedder()
+	if err != nil || embedder == nil {
+		t.Fatalf("expected local embedder, got %v, %v", embedder, err)
+	}
+
+	t.Setenv("EMBEDDINGS_PROVIDER", "unknown")
+	if _, err := GetEmbedder(); err == nil {
+		t.Error("expected error for unknown provider")
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := tokenize("getUserName HTTPServer snake_case x")
+	want := []string{"getusername", "get", "user", "name", "httpserver", "http", "server", "snake", "case"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("tokenize: got %v, want %v", got, want)
+	}
+}
+
+func TestChunksFromMapParts(t *testing.T) {
+	chunks := ChunksFromMapParts(shared.FileMapBodies{"auth/session.go": testMapParts["auth/session.go"]})
+
+	var names []string
+	for _, chunk := range chunks {
+		names = append(names, chunk.Name)
+	}
+	want := []string{"", "func CreateSession(userId string) (*Session, error)", "func ValidateSessionToken(token string) bool", "type Session struct"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("chunk names: got %q, want %q", names, want)
+	}
+
+	// nested members stay with their parent definition
+	if chunks[3].Text != "auth/session.go\ntype Session struct\n  - UserId string\n  - ExpiresAt time.Time" {
+		t.Errorf("unexpected struct chunk text: %q", chunks[3].Text)
+	}
+}
+
+func TestMapPartsFromCombinedBody(t *testing.T) {
+	combined := testMapParts.CombinedMap(map[string]int{"auth/session.go": 12})
+	got := MapPartsFromContexts([]*db.Context{{ContextType: shared.ContextMapType, Body: combined}})
+	if !reflect.DeepEqual(got, testMapParts) {
+		t.Errorf("got %v, want %v", got, testMapParts)
+	}
+}
+
+func TestSearch(t *testing.T) {
+	orgId, projectId := setupProject(t)
+	embedder := &countingEmbedder{HashEmbedder: NewHashEmbedder(defaultHashDims)}
+	ctx := context.Background()
+
+	search := func(mapParts shared.FileMapBodies, query string) []*shared.SemanticSearchResult {
+		results, err := Search(ctx, SearchParams{
+			Embedder:  embedder,
+			OrgId:     orgId,
+			ProjectId: projectId,
+			MapParts:  mapParts,
+			Query:     query,
+			Limit:     2,
+		})
+		if err != nil {
+			t.Fatalf("error searching: %v", err)
+		}
+		return results
+	}
+
+	results := search(testMapParts, "validate the user's session token")
+	if len(results) == 0 || results[0].Path != "auth/session.go" {
+		t.Fatalf("expected auth/session.go to rank first, got %+v", results)
+	}
+	if len(results[0].Definitions) == 0 || results[0].Definitions[0] != "func ValidateSessionToken(token string) bool" {
+		t.Errorf("expected best matching definition first, got %v", results[0].Definitions)
+	}
+
+	numChunks := len(ChunksFromMapParts(testMapParts))
+	if embedder.numEmbedded != numChunks+1 {
+		t.Errorf("expected %d chunks and the query to be embedded, got %d", numChunks, embedder.numEmbedded)
+	}
+
+	results = search(testMapParts, "send invoice email")
+	if len(results) == 0 || results[0].Path != "billing/invoice.go" {
+		t.Fatalf("expected billing/invoice.go to rank first, got %+v", results)
+	}
+	if embedder.numEmbedded != numChunks+2 {
+		t.Errorf("expected indexed chunks to be reused, got %d embedded", embedder.numEmbedded)
+	}
+
+	// changing a file re-embeds it and drops its stale vectors
+	changed := shared.FileMapBodies{}
+	for path, body := range testMapParts {
+		changed[path] = body
+	}
+	changed["ui/button.tsx"] = "export function IconButton(props: IconButtonProps)\ninterface IconButtonProps"
+	search(changed, "icon button")
+
+	var count int
+	err := db.Conn.Get(&count, "SELECT COUNT(*) FROM embeddings WHERE project_id = $1 AND path = $2", projectId, "ui/button.tsx")
+	if err != nil {
+		t.Fatalf("error counting embeddings: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected 3 current embeddings for changed file, got %d", count)
+	}
+}
@@ -0,0 +1,117 @@
+package embeddings
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const defaultHashDims = 512
+
+// HashEmbedder is a deterministic local embedder that hashes identifier-aware tokens into a fixed number of dimensions.
+// It only captures lexical overlap, but it's free, needs no network access, and gives stable results for tests.
+type HashEmbedder struct {
+	dims int
+}
+
+func NewHashEmbedder(dims int) *HashEmbedder {
+	return &HashEmbedder{dims: dims}
+}
+
+func (e *HashEmbedder) Id() string {
+	return fmt.Sprintf("%s:hash-%d", ProviderLocal, e.dims)
+}
+
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	res := make([][]float32, len(texts))
+	for i, text := range texts {
+		res[i] = e.embed(text)
+	}
+	return res, nil
+}
+
+func (e *HashEmbedder) embed(text string) []float32 {
+	vector := make([]float32, e.dims)
+
+	for _, token := range tokenize(text) {
+		h := fnv.New64a()
+		h.Write([]byte(token))
+		sum := h.Sum64()
+
+		idx := int(sum % uint64(e.dims))
+		// use a separate bit for the sign so collisions tend to cancel out rather than pile up
+		if sum&(1<<63) == 0 {
+			vector[idx] += 1
+		} else {
+			vector[idx] -= 1
+		}
+	}
+
+	normalize(vector)
+	return vector
+}
+
+// tokenize splits text into lowercased words, also splitting camelCase and snake_case identifiers so 'getUserName' matches 'user name'
+func tokenize(text string) []string {
+	var tokens []string
+
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for _, word := range words {
+		parts := splitCamelCase(word)
+		if len(parts) > 1 {
+			tokens = append(tokens, strings.ToLower(word))
+		}
+		for _, part := range parts {
+			if len(part) < 2 {
+				continue
+			}
+			tokens = append(tokens, strings.ToLower(part))
+		}
+	}
+
+	return tokens
+}
+
+func splitCamelCase(word string) []string {
+	var parts []string
+	runes := []rune(word)
+	start := 0
+
+	for i := 1; i < len(runes); i++ {
+		prev, cur := runes[i-1], runes[i]
+		var next rune
+		if i+1 < len(runes) {
+			next = runes[i+1]
+		}
+
+		// split on lower->upper ('userName') and at the end of an acronym ('HTTPServer')
+		if (unicode.IsLower(prev) && unicode.IsUpper(cur)) ||
+			(unicode.IsUpper(prev) && unicode.IsUpper(cur) && unicode.IsLower(next)) ||
+			(unicode.IsDigit(prev) != unicode.IsDigit(cur)) {
+			parts = append(parts, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	return append(parts, string(runes[start:]))
+}
+
+func normalize(vector []float32) {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+}
@@ -0,0 +1,273 @@
+package embeddings
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"math"
+	"plandex-server/db"
+	"sort"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+const (
+	maxDefinitionsPerFile = 50
+	maxChunkLength        = 2000
+	maxResultDefinitions  = 3
+)
+
+// Chunk is a unit of a project map that's embedded and ranked -- one per file, plus one per top-level definition in the file
+type Chunk struct {
+	Path string
+	// definition signature, or empty for the whole-file chunk
+	Name string
+	Text string
+	Sha  string
+}
+
+// ChunksFromMapParts splits project map bodies (as produced by syntax/file_map) into chunks.
+// Top-level lines in a map body are definitions; indented lines that follow belong to the definition above them.
+func ChunksFromMapParts(mapParts shared.FileMapBodies) []*Chunk {
+	paths := make([]string, 0, len(mapParts))
+	for path := range mapParts {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var chunks []*Chunk
+	for _, path := range paths {
+		body := mapParts[path]
+
+		chunks = append(chunks, newChunk(path, "", path+"\n"+body))
+
+		var name string
+		var lines []string
+		numDefs := 0
+		flush := func() {
+			if name != "" && numDefs < maxDefinitionsPerFile {
+				chunks = append(chunks, newChunk(path, name, path+"\n"+strings.Join(lines, "\n")))
+				numDefs++
+			}
+			name = ""
+			lines = nil
+		}
+
+		for _, line := range strings.Split(body, "\n") {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+				flush()
+				name = strings.TrimSpace(line)
+			}
+			lines = append(lines, line)
+		}
+		flush()
+	}
+
+	return chunks
+}
+
+func newChunk(path, name, text string) *Chunk {
+	if len(text) > maxChunkLength {
+		text = text[:maxChunkLength]
+	}
+	sum := sha256.Sum256([]byte(text))
+	return &Chunk{
+		Path: path,
+		Name: name,
+		Text: text,
+		Sha:  hex.EncodeToString(sum[:]),
+	}
+}
+
+type SearchParams struct {
+	Embedder  Embedder
+	OrgId     string
+	ProjectId string
+	MapParts  shared.FileMapBodies
+	Query     string
+	Limit     int
+}
+
+// Search ranks a project's files by similarity to the query, embedding any chunks that aren't in the project's index yet.
+// A file's score is the score of its best matching chunk, and the definitions that matched best are included with each result.
+func Search(ctx context.Context, params SearchParams) ([]*shared.SemanticSearchResult, error) {
+	embedder := params.Embedder
+
+	chunks := ChunksFromMapParts(params.MapParts)
+	if len(chunks) == 0 {
+		return nil, nil
+	}
+
+	vectors, err := syncIndex(ctx, embedder, params.OrgId, params.ProjectId, chunks)
+	if err != nil {
+		return nil, err
+	}
+
+	queryVectors, err := embedder.Embed(ctx, []string{params.Query})
+	if err != nil {
+		return nil, fmt.Errorf("error embedding query: %v", err)
+	}
+	queryVector := queryVectors[0]
+
+	type scoredChunk struct {
+		chunk *Chunk
+		score float64
+	}
+	chunksByPath := map[string][]scoredChunk{}
+
+	for _, chunk := range chunks {
+		vector, ok := vectors[chunk.Sha]
+		if !ok {
+			continue
+		}
+		chunksByPath[chunk.Path] = append(chunksByPath[chunk.Path], scoredChunk{chunk, cosineSimilarity(queryVector, vector)})
+	}
+
+	var results []*shared.SemanticSearchResult
+	for path, scored := range chunksByPath {
+		sort.SliceStable(scored, func(i, j int) bool {
+			return scored[i].score > scored[j].score
+		})
+
+		result := &shared.SemanticSearchResult{
+			Path:  path,
+			Score: scored[0].score,
+		}
+		for _, s := range scored {
+			if len(result.Definitions) >= maxResultDefinitions {
+				break
+			}
+			if s.chunk.Name != "" && s.score > 0 {
+				result.Definitions = append(result.Definitions, s.chunk.Name)
+			}
+		}
+
+		if result.Score > 0 {
+			results = append(results, result)
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			return results[i].Path < results[j].Path
+		}
+		return results[i].Score > results[j].Score
+	})
+
+	if params.Limit > 0 && len(results) > params.Limit {
+		results = results[:params.Limit]
+	}
+
+	return results, nil
+}
+
+// syncIndex loads stored vectors for the chunks, embeds and stores any that are missing, and drops vectors for older versions of the chunks' files
+func syncIndex(ctx context.Context, embedder Embedder, orgId, projectId string, chunks []*Chunk) (map[string][]float32, error) {
+	var shas []string
+	pathSet := map[string]bool{}
+	var paths []string
+	for _, chunk := range chunks {
+		shas = append(shas, chunk.Sha)
+		if !pathSet[chunk.Path] {
+			pathSet[chunk.Path] = true
+			paths = append(paths, chunk.Path)
+		}
+	}
+
+	vectors, err := db.GetEmbeddings(projectId, embedder.Id(), shas)
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []*Chunk
+	missingSet := map[string]bool{}
+	for _, chunk := range chunks {
+		if _, ok := vectors[chunk.Sha]; !ok && !missingSet[chunk.Sha] {
+			missing = append(missing, chunk)
+			missingSet[chunk.Sha] = true
+		}
+	}
+
+	if len(missing) == 0 {
+		return vectors, nil
+	}
+
+	log.Printf("Embedding %d of %d chunks for project %s\n", len(missing), len(chunks), projectId)
+
+	texts := make([]string, len(missing))
+	for i, chunk := range missing {
+		texts[i] = chunk.Text
+	}
+
+	embedded, err := embedder.Embed(ctx, texts)
+	if err != nil {
+		return nil, fmt.Errorf("error embedding chunks: %v", err)
+	}
+
+	inputs := make([]*db.EmbeddingInput, len(missing))
+	for i, chunk := range missing {
+		vectors[chunk.Sha] = embedded[i]
+		inputs[i] = &db.EmbeddingInput{
+			Path:   chunk.Path,
+			Sha:    chunk.Sha,
+			Vector: embedded[i],
+		}
+	}
+
+	err = db.StoreEmbeddings(ctx, orgId, projectId, embedder.Id(), inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.DeleteStaleEmbeddings(projectId, embedder.Id(), paths, shas)
+	if err != nil {
+		// the index still works with stale rows, so just log
+		log.Printf("Error deleting stale embeddings: %v\n", err)
+	}
+
+	return vectors, nil
+}
+
+func cosineSimilarity(a, b []float32) float64 {
+	if len(a) != len(b) {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// MapPartsFromContexts merges the map bodies of a plan's map contexts, which are what the index is built from.
+// Contexts loaded without map parts are parsed from their combined body.
+func MapPartsFromContexts(contexts []*db.Context) shared.FileMapBodies {
+	res := shared.FileMapBodies{}
+	for _, context := range contexts {
+		if context.ContextType != shared.ContextMapType {
+			continue
+		}
+		mapParts := context.MapParts
+		if len(mapParts) == 0 {
+			mapParts = shared.ParseCombinedMap(context.Body)
+		}
+		for path, body := range mapParts {
+			res[path] = body
+		}
+	}
+	return res
+}
@@ -0,0 +1,221 @@
+package eval
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+const usage = `Usage:
+  plandex-server eval run [flags] <fixtures-dir>
+  plandex-server eval compare <results-a.json> <results-b.json>
+
+'eval run' replays each fixture through the file build pipeline using recorded model responses, then scores the results for correctness, syntax validity, and token cost. Pass --pack twice to compare two model packs, or write results with --out from two server versions and compare them with 'eval compare'.
+
+Flags for 'eval run':
+`
+
+type packFlags []string
+
+func (p *packFlags) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *packFlags) Set(v string) error {
+	*p = append(*p, v)
+	return nil
+}
+
+// Main runs the eval subcommand and returns the process exit code
+func Main(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprint(os.Stderr, usage)
+		newRunFlagSet().PrintDefaults()
+		return 2
+	}
+
+	switch args[0] {
+	case "run":
+		return runCmd(args[1:])
+	case "compare":
+		return compareCmd(args[1:])
+	case "-h", "--help", "help":
+		fmt.Fprint(os.Stderr, usage)
+		newRunFlagSet().PrintDefaults()
+		return 0
+	}
+
+	fmt.Fprintf(os.Stderr, "Unknown eval command: %s\n\n", args[0])
+	fmt.Fprint(os.Stderr, usage)
+	newRunFlagSet().PrintDefaults()
+	return 2
+}
+
+type runOpts struct {
+	packs   packFlags
+	label   string
+	out     string
+	verbose bool
+}
+
+var opts runOpts
+
+func newRunFlagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("eval run", flag.ContinueOnError)
+	fs.Var(&opts.packs, "pack", fmt.Sprintf("Built-in model pack name or a model pack YAML file from 'plandex model-packs export' (default %s). Pass twice to compare two packs.", shared.DefaultModelPack.Name))
+	fs.StringVar(&opts.label, "label", "", "Name for this run in reports, like a git commit (default: the model pack name)")
+	fs.StringVar(&opts.out, "out", "", "Write results as JSON to this file, for 'eval compare'. With two packs, the pack name is added to the file name.")
+	fs.BoolVar(&opts.verbose, "verbose", false, "Show build pipeline logs")
+	return fs
+}
+
+func runCmd(args []string) int {
+	opts = runOpts{}
+	fs := newRunFlagSet()
+
+	// allow flags both before and after the fixtures dir
+	err := fs.Parse(args)
+	var positional []string
+	for err == nil && fs.NArg() > 0 {
+		positional = append(positional, fs.Arg(0))
+		err = fs.Parse(fs.Args()[1:])
+	}
+	if err == flag.ErrHelp {
+		return 0
+	} else if err != nil {
+		return 2
+	}
+
+	if len(positional) != 1 {
+		fmt.Fprintln(os.Stderr, "Pass a single fixtures directory")
+		return 2
+	}
+	if len(opts.packs) > 2 {
+		fmt.Fprintln(os.Stderr, "Pass --pack at most twice")
+		return 2
+	}
+	if len(opts.packs) == 0 {
+		opts.packs = packFlags{shared.DefaultModelPack.Name}
+	}
+
+	// the build pipeline logs and dumps debug output to stdout, so keep the real stdout for the report
+	out := os.Stdout
+	if !opts.verbose {
+		log.SetOutput(io.Discard)
+
+		devNull, err := os.Open(os.DevNull)
+		if err == nil {
+			os.Stdout = devNull
+			defer func() {
+				os.Stdout = out
+				devNull.Close()
+			}()
+		}
+	}
+
+	fixtures, err := LoadFixtures(positional[0])
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error loading fixtures: %v\n", err)
+		return 1
+	}
+	if len(fixtures) == 0 {
+		fmt.Fprintf(os.Stderr, "No fixtures found in %s\n", positional[0])
+		return 1
+	}
+
+	var allResults []*Results
+	for _, packArg := range opts.packs {
+		pack, err := resolveModelPack(packArg)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error loading model pack: %v\n", err)
+			return 1
+		}
+
+		label := opts.label
+		if len(opts.packs) > 1 {
+			// each run is labeled by its pack when comparing packs
+			label = ""
+		}
+
+		results := Run(context.Background(), fixtures, pack, label)
+		allResults = append(allResults, results)
+
+		PrintResults(out, results)
+		fmt.Fprintln(out)
+
+		if opts.out != "" {
+			path := opts.out
+			if len(opts.packs) > 1 {
+				ext := filepath.Ext(path)
+				path = strings.TrimSuffix(path, ext) + "-" + pack.Name + ext
+			}
+			err = WriteResults(path, results)
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				return 1
+			}
+			fmt.Fprintf(out, "Wrote results to %s\n\n", path)
+		}
+	}
+
+	if len(allResults) == 2 {
+		PrintComparison(out, allResults[0], allResults[1])
+	}
+
+	return 0
+}
+
+func compareCmd(args []string) int {
+	if len(args) != 2 {
+		fmt.Fprint(os.Stderr, usage)
+		return 2
+	}
+
+	a, err := ReadResults(args[0])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	b, err := ReadResults(args[1])
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	PrintComparison(os.Stdout, a, b)
+	return 0
+}
+
+// resolveModelPack finds a built-in model pack by name, or loads one from a YAML file
+func resolveModelPack(nameOrPath string) (*shared.ModelPack, error) {
+	for _, pack := range shared.BuiltInModelPacks {
+		if pack.Name == nameOrPath {
+			return pack, nil
+		}
+	}
+
+	ext := filepath.Ext(nameOrPath)
+	if ext != ".yaml" && ext != ".yml" {
+		return nil, fmt.Errorf("%s isn't a built-in model pack or a YAML file", nameOrPath)
+	}
+
+	var pack shared.ModelPack
+	err := decodeYamlFile(nameOrPath, &pack)
+	if err != nil {
+		return nil, err
+	}
+
+	err = pack.Validate()
+	if err != nil {
+		return nil, fmt.Errorf("invalid model pack in %s: %v", nameOrPath, err)
+	}
+
+	return &pack, nil
+}
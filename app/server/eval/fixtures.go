@@ -0,0 +1,166 @@
+package eval
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+const defaultResponsesName = "default"
+
+// Fixture is a single build case: a file before the build, the changes the plan proposed for it, and the file that applying them should produce. Each fixture is a directory:
+//
+//	case.yaml           path and description of the change
+//	original.<ext>      the file before the build
+//	proposed.<ext>      the proposed changes, with "... existing code ..." references
+//	expected.<ext>      the correct result
+//	responses/<pack>.yaml
+//
+// <ext> is the extension of the path in case.yaml. Recorded responses are looked up by model pack name, falling back to responses/default.yaml.
+type Fixture struct {
+	Name        string
+	Path        string
+	Description string
+	Original    string
+	Proposed    string
+	Expected    string
+	Responses   map[string]*RecordedResponses
+}
+
+type fixtureConfig struct {
+	Path        string `yaml:"path"`
+	Description string `yaml:"description"`
+}
+
+// RecordedResponses stand in for the model calls a build would make after the changes fail to apply cleanly. Validation holds builder responses in the order they'd be returned, one per attempt. FastApply is the merged file from the fast apply hook, and WholeFile is the whole file builder's response.
+type RecordedResponses struct {
+	Validation []string `yaml:"validation,omitempty"`
+	FastApply  string   `yaml:"fastApply,omitempty"`
+	WholeFile  string   `yaml:"wholeFile,omitempty"`
+}
+
+// LoadFixtures loads every fixture directory under dir, sorted by name. Directories without a case.yaml are skipped.
+func LoadFixtures(dir string) ([]*Fixture, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("error reading fixtures dir: %v", err)
+	}
+
+	var fixtures []*Fixture
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		fixtureDir := filepath.Join(dir, entry.Name())
+		_, err := os.Stat(filepath.Join(fixtureDir, "case.yaml"))
+		if os.IsNotExist(err) {
+			continue
+		}
+
+		fixture, err := loadFixture(fixtureDir)
+		if err != nil {
+			return nil, fmt.Errorf("fixture %s: %v", entry.Name(), err)
+		}
+		fixtures = append(fixtures, fixture)
+	}
+
+	sort.Slice(fixtures, func(i, j int) bool {
+		return fixtures[i].Name < fixtures[j].Name
+	})
+
+	return fixtures, nil
+}
+
+func loadFixture(dir string) (*Fixture, error) {
+	var config fixtureConfig
+	err := decodeYamlFile(filepath.Join(dir, "case.yaml"), &config)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.Path == "" {
+		return nil, fmt.Errorf("path is required in case.yaml")
+	}
+
+	ext := filepath.Ext(config.Path)
+
+	fixture := &Fixture{
+		Name:        filepath.Base(dir),
+		Path:        config.Path,
+		Description: config.Description,
+		Responses:   map[string]*RecordedResponses{},
+	}
+
+	files := []struct {
+		name string
+		dest *string
+	}{
+		{"original", &fixture.Original},
+		{"proposed", &fixture.Proposed},
+		{"expected", &fixture.Expected},
+	}
+	for _, f := range files {
+		bytes, err := os.ReadFile(filepath.Join(dir, f.name+ext))
+		if err != nil {
+			return nil, fmt.Errorf("error reading %s: %v", f.name+ext, err)
+		}
+		*f.dest = string(bytes)
+	}
+
+	responsesDir := filepath.Join(dir, "responses")
+	entries, err := os.ReadDir(responsesDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("error reading responses dir: %v", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".yaml" {
+			continue
+		}
+
+		var responses RecordedResponses
+		err := decodeYamlFile(filepath.Join(responsesDir, entry.Name()), &responses)
+		if err != nil {
+			return nil, err
+		}
+
+		fixture.Responses[strings.TrimSuffix(entry.Name(), ".yaml")] = &responses
+	}
+
+	return fixture, nil
+}
+
+// responsesForPack returns the responses recorded with a model pack, or the default recording if there aren't any for that pack
+func (f *Fixture) responsesForPack(packName string) *RecordedResponses {
+	if responses, ok := f.Responses[packName]; ok {
+		return responses
+	}
+	if responses, ok := f.Responses[defaultResponsesName]; ok {
+		return responses
+	}
+	return &RecordedResponses{}
+}
+
+func decodeYamlFile(path string, v any) error {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	err = decoder.Decode(v)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("error parsing %s: %v", path, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,238 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/shopspring/decimal"
+)
+
+type Summary struct {
+	NumCases         int
+	NumCorrect       int
+	NumSyntaxChecked int
+	NumSyntaxValid   int
+	ModelCalls       int
+	InputTokens      int
+	OutputTokens     int
+	Cost             decimal.Decimal
+	NumUnpriced      int
+	NumByStage       map[Stage]int
+}
+
+func (r *Results) Summary() Summary {
+	summary := Summary{NumByStage: map[Stage]int{}}
+
+	for _, c := range r.Cases {
+		summary.NumCases++
+		if c.Correct {
+			summary.NumCorrect++
+		}
+		if c.SyntaxChecked {
+			summary.NumSyntaxChecked++
+			if c.SyntaxValid {
+				summary.NumSyntaxValid++
+			}
+		}
+		summary.ModelCalls += c.ModelCalls
+		summary.InputTokens += c.InputTokens
+		summary.OutputTokens += c.OutputTokens
+		summary.Cost = summary.Cost.Add(c.Cost)
+		summary.NumUnpriced += c.NumUnpriced
+		summary.NumByStage[c.Stage]++
+	}
+
+	return summary
+}
+
+func WriteResults(path string, results *Results) error {
+	bytes, err := json.MarshalIndent(results, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error marshalling results: %v", err)
+	}
+
+	err = os.WriteFile(path, bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing results: %v", err)
+	}
+
+	return nil
+}
+
+func ReadResults(path string) (*Results, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading results: %v", err)
+	}
+
+	var results Results
+	err = json.Unmarshal(bytes, &results)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing results in %s: %v", path, err)
+	}
+
+	return &results, nil
+}
+
+// PrintResults writes a table of every case followed by the totals
+func PrintResults(w io.Writer, results *Results) {
+	fmt.Fprintf(w, "Results for %s\n\n", results.Label)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Case\tStage\tCorrect\tSyntax\tCalls\tTokens\tCost")
+	for _, c := range results.Cases {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%s\t%s\n",
+			c.Name,
+			c.Stage,
+			check(c.Correct),
+			syntaxCheck(c),
+			c.ModelCalls,
+			formatTokens(c.InputTokens+c.OutputTokens),
+			formatCost(c.Cost),
+		)
+	}
+	tw.Flush()
+
+	for _, c := range results.Cases {
+		if c.Correct || (len(c.Problems) == 0 && c.Diff == "") {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", c.Name)
+		for _, problem := range c.Problems {
+			fmt.Fprintf(w, "  - %s\n", strings.ReplaceAll(problem, "\n", "\n    "))
+		}
+		if c.Diff != "" {
+			fmt.Fprintf(w, "  - result differs from expected file:\n    %s\n", strings.ReplaceAll(strings.TrimRight(c.Diff, "\n"), "\n", "\n    "))
+		}
+	}
+
+	summary := results.Summary()
+
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "Correct: %s\n", formatRatio(summary.NumCorrect, summary.NumCases))
+	fmt.Fprintf(w, "Syntax valid: %s\n", formatRatio(summary.NumSyntaxValid, summary.NumSyntaxChecked))
+	fmt.Fprintf(w, "Model calls: %d (%s input, %s output tokens)\n", summary.ModelCalls, formatTokens(summary.InputTokens), formatTokens(summary.OutputTokens))
+	fmt.Fprintf(w, "Cost: %s", formatCost(summary.Cost))
+	if summary.NumUnpriced > 0 {
+		fmt.Fprintf(w, " (%d calls to models without pricing not included)", summary.NumUnpriced)
+	}
+	fmt.Fprintln(w)
+
+	var stages []string
+	for _, stage := range AllStages {
+		if n := summary.NumByStage[stage]; n > 0 {
+			stages = append(stages, fmt.Sprintf("%s %d", stage, n))
+		}
+	}
+	fmt.Fprintf(w, "By stage: %s\n", strings.Join(stages, ", "))
+}
+
+// PrintComparison writes the totals for two runs side by side, then every case whose outcome differs between them
+func PrintComparison(w io.Writer, a, b *Results) {
+	fmt.Fprintf(w, "Comparing %s (A) with %s (B)\n\n", a.Label, b.Label)
+
+	sa := a.Summary()
+	sb := b.Summary()
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "\tA\tB\tChange")
+	fmt.Fprintf(tw, "Correct\t%s\t%s\t%+d\n", formatRatio(sa.NumCorrect, sa.NumCases), formatRatio(sb.NumCorrect, sb.NumCases), sb.NumCorrect-sa.NumCorrect)
+	fmt.Fprintf(tw, "Syntax valid\t%s\t%s\t%+d\n", formatRatio(sa.NumSyntaxValid, sa.NumSyntaxChecked), formatRatio(sb.NumSyntaxValid, sb.NumSyntaxChecked), sb.NumSyntaxValid-sa.NumSyntaxValid)
+	fmt.Fprintf(tw, "Model calls\t%d\t%d\t%+d\n", sa.ModelCalls, sb.ModelCalls, sb.ModelCalls-sa.ModelCalls)
+	fmt.Fprintf(tw, "Input tokens\t%s\t%s\t%+d\n", formatTokens(sa.InputTokens), formatTokens(sb.InputTokens), sb.InputTokens-sa.InputTokens)
+	fmt.Fprintf(tw, "Output tokens\t%s\t%s\t%+d\n", formatTokens(sa.OutputTokens), formatTokens(sb.OutputTokens), sb.OutputTokens-sa.OutputTokens)
+	fmt.Fprintf(tw, "Cost\t%s\t%s\t%s\n", formatCost(sa.Cost), formatCost(sb.Cost), formatCostChange(sb.Cost.Sub(sa.Cost)))
+	tw.Flush()
+
+	byName := map[string]*CaseResult{}
+	for _, c := range b.Cases {
+		byName[c.Name] = c
+	}
+
+	fmt.Fprintln(w)
+
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	numChanged := 0
+	for _, ca := range a.Cases {
+		cb := byName[ca.Name]
+		if cb == nil {
+			continue
+		}
+		if ca.Stage == cb.Stage && ca.Correct == cb.Correct && ca.SyntaxValid == cb.SyntaxValid {
+			continue
+		}
+		if numChanged == 0 {
+			fmt.Fprintln(tw, "Changed case\tA\tB")
+		}
+		numChanged++
+		fmt.Fprintf(tw, "%s\t%s %s\t%s %s\n", ca.Name, ca.Stage, check(ca.Correct), cb.Stage, check(cb.Correct))
+	}
+	tw.Flush()
+
+	if numChanged == 0 {
+		fmt.Fprintln(w, "No cases changed")
+	}
+
+	inA := map[string]bool{}
+	var onlyA, onlyB []string
+	for _, ca := range a.Cases {
+		inA[ca.Name] = true
+		if byName[ca.Name] == nil {
+			onlyA = append(onlyA, ca.Name)
+		}
+	}
+	for _, cb := range b.Cases {
+		if !inA[cb.Name] {
+			onlyB = append(onlyB, cb.Name)
+		}
+	}
+	if len(onlyA) > 0 {
+		fmt.Fprintf(w, "\nOnly in A: %s\n", strings.Join(onlyA, ", "))
+	}
+	if len(onlyB) > 0 {
+		fmt.Fprintf(w, "\nOnly in B: %s\n", strings.Join(onlyB, ", "))
+	}
+}
+
+func check(ok bool) string {
+	if ok {
+		return "✓"
+	}
+	return "✗"
+}
+
+func syntaxCheck(c *CaseResult) string {
+	if !c.SyntaxChecked {
+		return "—"
+	}
+	return check(c.SyntaxValid)
+}
+
+func formatRatio(n, total int) string {
+	if total == 0 {
+		return "—"
+	}
+	return fmt.Sprintf("%d/%d (%.0f%%)", n, total, float64(n)/float64(total)*100)
+}
+
+func formatTokens(n int) string {
+	if n >= 1000 {
+		return fmt.Sprintf("%.1fk", float64(n)/1000)
+	}
+	return fmt.Sprintf("%d", n)
+}
+
+func formatCost(cost decimal.Decimal) string {
+	return "$" + cost.StringFixed(4)
+}
+
+func formatCostChange(change decimal.Decimal) string {
+	if change.IsNegative() {
+		return "-$" + change.Abs().StringFixed(4)
+	}
+	return "+$" + change.StringFixed(4)
+}
@@ -0,0 +1,322 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"log"
+	diff_pkg "plandex-server/diff"
+	"plandex-server/model"
+	"plandex-server/model/plan"
+	"plandex-server/model/prompts"
+	"plandex-server/syntax"
+	"plandex-server/types"
+	"plandex-server/utils"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+	"github.com/shopspring/decimal"
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// Stage is the step of the build pipeline that produced a case's final file
+type Stage string
+
+const (
+	StageAutoApply  Stage = "auto_apply"
+	StageValidation Stage = "validation"
+	StageFastApply  Stage = "fast_apply"
+	StageWholeFile  Stage = "whole_file"
+	StageFailed     Stage = "failed"
+)
+
+var AllStages = []Stage{StageAutoApply, StageValidation, StageFastApply, StageWholeFile, StageFailed}
+
+type CaseResult struct {
+	Name          string          `json:"name"`
+	Path          string          `json:"path"`
+	Stage         Stage           `json:"stage"`
+	Correct       bool            `json:"correct"`
+	SyntaxChecked bool            `json:"syntaxChecked"`
+	SyntaxValid   bool            `json:"syntaxValid"`
+	ModelCalls    int             `json:"modelCalls"`
+	InputTokens   int             `json:"inputTokens"`
+	OutputTokens  int             `json:"outputTokens"`
+	Cost          decimal.Decimal `json:"cost"`
+	NumUnpriced   int             `json:"numUnpriced"`
+	Problems      []string        `json:"problems,omitempty"`
+	Diff          string          `json:"diff,omitempty"`
+}
+
+// Results are the scored cases from one run, written with --out so that runs from different server versions can be compared
+type Results struct {
+	Label     string        `json:"label"`
+	ModelPack string        `json:"modelPack"`
+	CreatedAt time.Time     `json:"createdAt"`
+	Cases     []*CaseResult `json:"cases"`
+}
+
+// Run replays each fixture through the build pipeline with the given model pack's recorded responses
+func Run(ctx context.Context, fixtures []*Fixture, pack *shared.ModelPack, label string) *Results {
+	if label == "" {
+		label = pack.Name
+	}
+
+	results := &Results{
+		Label:     label,
+		ModelPack: pack.Name,
+		CreatedAt: time.Now(),
+	}
+
+	for _, fixture := range fixtures {
+		results.Cases = append(results.Cases, runCase(ctx, fixture, pack))
+	}
+
+	return results
+}
+
+// runCase follows the same steps as a structured edits build: apply the changes directly, then fall back to the builder's validation and replacements, fast apply, and finally a whole file build. A real build races the fallbacks against each other—here they're resolved in that order so results are repeatable, and every recorded call the build would have made counts toward token cost.
+func runCase(ctx context.Context, fixture *Fixture, pack *shared.ModelPack) *CaseResult {
+	res := &CaseResult{
+		Name: fixture.Name,
+		Path: fixture.Path,
+	}
+	responses := fixture.responsesForPack(pack.Name)
+
+	original := fixture.Original
+	proposed := fixture.Proposed
+	desc := fixture.Description
+
+	checker, err := newSyntaxChecker(ctx, fixture.Path, original)
+	if err != nil {
+		res.Stage = StageFailed
+		res.Problems = append(res.Problems, err.Error())
+		return res
+	}
+
+	applyRes := syntax.ApplyChanges(ctx, syntax.ApplyChangesParams{
+		Original:               original,
+		Proposed:               proposed,
+		Desc:                   desc,
+		AddMissingStartEndRefs: true,
+		Parser:                 checker.newParser(),
+		Language:               checker.lang,
+	})
+
+	updated := applyRes.NewFile
+	syntaxErrors := checker.check(ctx, updated)
+
+	final, stage := "", StageFailed
+
+	if len(syntaxErrors) == 0 && len(applyRes.NeedsVerifyReasons) == 0 {
+		final, stage = updated, StageAutoApply
+	}
+
+	originalWithLineNums := shared.AddLineNums(original)
+	proposedWithLineNums := shared.AddLineNums(proposed)
+
+	if stage == StageFailed {
+		reasons := applyRes.NeedsVerifyReasons
+
+		for i, content := range responses.Validation {
+			if i >= plan.MaxValidationFixAttempts {
+				break
+			}
+
+			diff, err := diff_pkg.GetDiffs(original, updated)
+			if err != nil {
+				res.Problems = append(res.Problems, fmt.Sprintf("error getting diffs: %v", err))
+				break
+			}
+
+			prompt, _ := prompts.GetValidationReplacementsXmlPrompt(prompts.ValidationPromptParams{
+				Path:                 fixture.Path,
+				OriginalWithLineNums: originalWithLineNums,
+				Desc:                 desc,
+				ProposedWithLineNums: proposedWithLineNums,
+				Diff:                 diff,
+				SyntaxErrors:         syntaxErrors,
+				Reasons:              reasons,
+			})
+
+			// same model selection as the validation loop: switch to the strong model after two failed attempts
+			modelConfig := pack.Builder
+			if i+1 > 2 && modelConfig.StrongModel != nil {
+				modelConfig = *modelConfig.StrongModel
+			}
+			res.addCall(modelConfig, prompt, content, 0)
+
+			reasons = nil
+
+			validateRes, err := plan.ApplyValidationResponse(content, originalWithLineNums, updated, false)
+			if err != nil {
+				res.Problems = append(res.Problems, fmt.Sprintf("validation attempt %d: %v", i+1, err))
+				continue
+			}
+
+			updated = validateRes.Updated
+			syntaxErrors = checker.check(ctx, updated)
+
+			if validateRes.Valid && len(syntaxErrors) == 0 {
+				final, stage = updated, StageValidation
+				break
+			}
+
+			if validateRes.Problem != "" {
+				res.Problems = append(res.Problems, fmt.Sprintf("validation attempt %d: %s", i+1, strings.TrimSpace(validateRes.Problem)))
+			}
+		}
+	}
+
+	if stage == StageFailed && responses.FastApply != "" {
+		if len(checker.check(ctx, responses.FastApply)) == 0 {
+			final, stage = responses.FastApply, StageFastApply
+		} else {
+			res.Problems = append(res.Problems, "fast apply result has syntax errors")
+		}
+	}
+
+	if stage == StageFailed && responses.WholeFile != "" {
+		prompt, _ := prompts.GetWholeFilePrompt(fixture.Path, originalWithLineNums, proposedWithLineNums, desc, "")
+
+		res.addCall(pack.GetWholeFileBuilder(), prompt, responses.WholeFile, shared.GetNumTokensEstimate(original+proposed))
+
+		wholeFile := utils.GetXMLContent(responses.WholeFile, "PlandexWholeFile")
+		if wholeFile == "" {
+			res.Problems = append(res.Problems, "no whole file found in response")
+		} else {
+			final, stage = wholeFile, StageWholeFile
+		}
+	}
+
+	if stage == StageFailed && len(responses.Validation) == 0 && responses.FastApply == "" && responses.WholeFile == "" {
+		res.Problems = append(res.Problems, fmt.Sprintf("changes didn't apply cleanly and there are no recorded responses for %s", pack.Name))
+	}
+
+	res.Stage = stage
+
+	if stage != StageFailed {
+		res.Correct = normalizeFile(final) == normalizeFile(fixture.Expected)
+
+		if !res.Correct {
+			diff, err := diff_pkg.GetDiffs(fixture.Expected, final)
+			if err != nil {
+				res.Problems = append(res.Problems, fmt.Sprintf("error getting diff from expected file: %v", err))
+			} else {
+				res.Diff = diff
+			}
+		}
+
+		if checker.enabled() {
+			res.SyntaxChecked = true
+			res.SyntaxValid = len(checker.check(ctx, final)) == 0
+		}
+	}
+
+	return res
+}
+
+// addCall adds the tokens and cost of a model call, resolving large context and output fallbacks the same way a model request does
+func (res *CaseResult) addCall(modelConfig shared.ModelRoleConfig, prompt, content string, expectedOutputTokens int) {
+	messages := []types.ExtendedChatMessage{
+		{
+			Role: openai.ChatMessageRoleSystem,
+			Content: []types.ExtendedChatMessagePart{
+				{
+					Type: openai.ChatMessagePartTypeText,
+					Text: prompt,
+				},
+			},
+		},
+	}
+
+	inputTokens := model.GetMessagesTokenEstimate(messages...) + model.TokensPerRequest
+	outputTokens := shared.GetNumTokensEstimate(content)
+
+	modelConfig = modelConfig.GetRoleForInputTokens(inputTokens)
+	if expectedOutputTokens > 0 {
+		modelConfig = modelConfig.GetRoleForOutputTokens(expectedOutputTokens)
+	}
+
+	res.ModelCalls++
+	res.InputTokens += inputTokens
+	res.OutputTokens += outputTokens
+
+	baseConfig := modelConfig.BaseModelConfig
+	availableModel := shared.GetAvailableModel(baseConfig.Provider, baseConfig.ModelId)
+	if availableModel == nil || availableModel.Pricing == nil {
+		res.NumUnpriced++
+		return
+	}
+
+	res.Cost = res.Cost.Add(availableModel.Pricing.Cost(inputTokens, outputTokens, 0))
+}
+
+// syntaxChecker validates files the same way a build does: with the parser that accepted the original file, and not at all if the original file already had syntax errors or there's no parser for the language
+type syntaxChecker struct {
+	lang     shared.Language
+	parser   *tree_sitter.Parser
+	disabled bool
+}
+
+func newSyntaxChecker(ctx context.Context, path, original string) (*syntaxChecker, error) {
+	parser, lang, fallbackParser, fallbackLang := syntax.GetParserForPath(path)
+
+	checker := &syntaxChecker{lang: lang}
+	if parser == nil {
+		return checker, nil
+	}
+
+	validationRes, err := syntax.ValidateWithParsers(ctx, lang, parser, fallbackLang, fallbackParser, original)
+	if err != nil {
+		return nil, fmt.Errorf("error validating original file syntax: %v", err)
+	}
+
+	checker.lang = validationRes.Lang
+	checker.parser = validationRes.Parser
+	checker.disabled = validationRes.TimedOut || !validationRes.Valid
+
+	return checker, nil
+}
+
+func (c *syntaxChecker) enabled() bool {
+	return c.parser != nil && !c.disabled
+}
+
+// newParser returns a fresh parser for the checker's language. A parser that's been used with a cancelable context can be left cancelled after the parse finishes, failing the next parse, so one isn't reused across parses.
+func (c *syntaxChecker) newParser() *tree_sitter.Parser {
+	if c.parser == nil {
+		return nil
+	}
+	return syntax.GetParserForLanguage(c.lang)
+}
+
+func (c *syntaxChecker) check(ctx context.Context, file string) []string {
+	if !c.enabled() {
+		return nil
+	}
+
+	validationRes, err := syntax.ValidateWithParsers(ctx, c.lang, c.newParser(), "", nil, file)
+	if err != nil {
+		log.Printf("eval - error validating file: %v\n", err)
+		return nil
+	}
+	if validationRes.TimedOut {
+		c.disabled = true
+		return nil
+	}
+
+	return validationRes.Errors
+}
+
+// normalizeFile ignores line endings, trailing whitespace, and blank lines at the start and end when comparing a result with the expected file
+func normalizeFile(file string) string {
+	file = strings.ReplaceAll(file, "\r\n", "\n")
+	lines := strings.Split(file, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
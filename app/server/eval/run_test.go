@@ -0,0 +1,186 @@
+package eval
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+const goOriginal = `package config
+
+import "os"
+
+func Default() *Config {
+	return &Config{Port: 8080}
+}
+
+// Load reads the config file at path
+func Load(path string) (*Config, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(bytes)
+}
+`
+
+const goProposed = `// ... existing code ...
+
+// Load reads the config file at path, or returns the default config if it doesn't exist
+func Load(path string) (*Config, error) {
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(bytes)
+}
+`
+
+const goExpected = `package config
+
+import "os"
+
+func Default() *Config {
+	return &Config{Port: 8080}
+}
+
+// Load reads the config file at path, or returns the default config if it doesn't exist
+func Load(path string) (*Config, error) {
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(bytes)
+}
+`
+
+const goDesc = `Type: replace
+Summary: Replace the implementation of ` + "`Load`" + ` so that a missing file returns the default config
+Replace: lines 9-17
+Context: Located after the ` + "`Default`" + ` function`
+
+func writeFixture(t *testing.T, dir, name, path, desc string, files map[string]string) {
+	t.Helper()
+
+	fixtureDir := filepath.Join(dir, name)
+	err := os.MkdirAll(filepath.Join(fixtureDir, "responses"), 0755)
+	if err != nil {
+		t.Fatalf("error creating fixture dir: %v", err)
+	}
+
+	files["case.yaml"] = "path: " + path + "\ndescription: |\n" + indent(desc)
+	for file, contents := range files {
+		err := os.WriteFile(filepath.Join(fixtureDir, file), []byte(contents), 0644)
+		if err != nil {
+			t.Fatalf("error writing %s: %v", file, err)
+		}
+	}
+}
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFixture(t, dir, "clean", "counter.ts", "Type: add\nSummary: Add a `reset` method to the `Counter` class\nContext: Located in the `Counter` class, after the `value` getter", map[string]string{
+		"original.ts": "export class Counter {\n  private count = 0;\n\n  get value() {\n    return this.count;\n  }\n}\n",
+		"proposed.ts": "export class Counter {\n  // ... existing code ...\n\n  get value() {\n    return this.count;\n  }\n\n  reset() {\n    this.count = 0;\n  }\n}\n",
+		"expected.ts": "export class Counter {\n  private count = 0;\n\n  get value() {\n    return this.count;\n  }\n\n  reset() {\n    this.count = 0;\n  }\n}\n",
+	})
+
+	writeFixture(t, dir, "whole-file", "config/load.go", goDesc, map[string]string{
+		"original.go": goOriginal,
+		"proposed.go": goProposed,
+		"expected.go": goExpected,
+		// the cheap pack's recording can't fix the edit, so it falls back to a whole file build
+		"responses/cheap.yaml": "validation:\n  - |\n    <PlandexIncorrect/>\n    <PlandexReplacements></PlandexReplacements>\nwholeFile: |\n  <PlandexWholeFile>\n" + indent(goExpected) + "  </PlandexWholeFile>\n",
+	})
+
+	writeFixture(t, dir, "not-recorded", "config/load.go", goDesc, map[string]string{
+		"original.go": goOriginal,
+		"proposed.go": goProposed,
+		"expected.go": goExpected,
+	})
+
+	fixtures, err := LoadFixtures(dir)
+	if err != nil {
+		t.Fatalf("error loading fixtures: %v", err)
+	}
+	if len(fixtures) != 3 || fixtures[0].Name != "clean" || fixtures[2].Name != "whole-file" {
+		t.Fatalf("unexpected fixtures: %+v", fixtures)
+	}
+
+	var cheap *shared.ModelPack
+	for _, pack := range shared.BuiltInModelPacks {
+		if pack.Name == "cheap" {
+			cheap = pack
+		}
+	}
+
+	results := Run(context.Background(), fixtures, cheap, "")
+	if results.Label != "cheap" {
+		t.Errorf("expected run to be labeled with the pack name, got %s", results.Label)
+	}
+
+	clean := results.Cases[0]
+	if clean.Stage != StageAutoApply || !clean.Correct || !clean.SyntaxChecked || !clean.SyntaxValid || clean.ModelCalls != 0 {
+		t.Errorf("unexpected result for clean case: %+v", clean)
+	}
+
+	notRecorded := results.Cases[1]
+	if notRecorded.Stage != StageFailed || notRecorded.Correct || len(notRecorded.Problems) == 0 {
+		t.Errorf("unexpected result for case without responses: %+v", notRecorded)
+	}
+
+	wholeFile := results.Cases[2]
+	if wholeFile.Stage != StageWholeFile || !wholeFile.Correct || !wholeFile.SyntaxValid {
+		t.Errorf("unexpected result for whole file case: %+v", wholeFile)
+	}
+	if wholeFile.ModelCalls != 2 || wholeFile.InputTokens == 0 || wholeFile.OutputTokens == 0 || !wholeFile.Cost.IsPositive() {
+		t.Errorf("expected tokens and cost for two model calls, got %+v", wholeFile)
+	}
+
+	summary := results.Summary()
+	if summary.NumCases != 3 || summary.NumCorrect != 2 || summary.NumByStage[StageFailed] != 1 || !summary.Cost.Equal(wholeFile.Cost) {
+		t.Errorf("unexpected summary: %+v", summary)
+	}
+
+	// a pack without its own recording uses the default one, and there isn't one here
+	results = Run(context.Background(), fixtures, shared.DefaultModelPack, "v2")
+	if results.Label != "v2" || results.Cases[2].Stage != StageFailed {
+		t.Errorf("expected whole file case to fail without a recording for %s, got %+v", shared.DefaultModelPack.Name, results.Cases[2])
+	}
+}
+
+func TestNormalizeFile(t *testing.T) {
+	a := "\nfunc main() {  \r\n\tprintln()\r\n}\n\n"
+	b := "func main() {\n\tprintln()\n}"
+	if normalizeFile(a) != normalizeFile(b) {
+		t.Errorf("expected %q and %q to match", a, b)
+	}
+
+	if normalizeFile("a\n\nb") == normalizeFile("a\nb") {
+		t.Error("blank lines inside the file should still count")
+	}
+}
+
+func indent(s string) string {
+	lines := strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
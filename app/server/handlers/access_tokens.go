@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"strings"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func ListAccessTokensHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListAccessTokensHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	dbTokens, err := db.ListAccessTokens(auth.OrgId, auth.User.Id)
+	if err != nil {
+		log.Printf("Error listing access tokens: %v\n", err)
+		http.Error(w, "Error listing access tokens: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var apiTokens []*shared.AccessToken
+	for _, token := range dbTokens {
+		apiTokens = append(apiTokens, token.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiTokens)
+	if err != nil {
+		log.Printf("Error marshalling access tokens: %v\n", err)
+		http.Error(w, "Error marshalling access tokens: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed access tokens")
+}
+
+func CreateAccessTokenHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateAccessTokenHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	// otherwise a leaked token could be used to mint new ones that outlive its revocation
+	if auth.AccessToken != nil {
+		log.Println("Can't create an access token with an access token")
+		http.Error(w, "Access tokens can only be created from a signed in session", http.StatusForbidden)
+		return
+	}
+
+	var req shared.CreateAccessTokenRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		http.Error(w, "Name is required", http.StatusBadRequest)
+		return
+	}
+	if len(req.Name) > 255 {
+		http.Error(w, "Name is too long", http.StatusBadRequest)
+		return
+	}
+
+	if req.ExpiresInDays < 0 || req.ExpiresInDays > shared.MaxAccessTokenExpirationDays {
+		http.Error(w, fmt.Sprintf("Expiration must be between 0 (never) and %d days", shared.MaxAccessTokenExpirationDays), http.StatusBadRequest)
+		return
+	}
+
+	for _, permission := range req.Permissions {
+		if !shared.IsValidPermission(permission) {
+			http.Error(w, fmt.Sprintf("Invalid permission: %s", permission), http.StatusBadRequest)
+			return
+		}
+
+		if !auth.HasPermission(permission) {
+			http.Error(w, fmt.Sprintf("You don't have the %s permission in this org", permission), http.StatusForbidden)
+			return
+		}
+	}
+
+	existing, err := db.ListAccessTokens(auth.OrgId, auth.User.Id)
+	if err != nil {
+		log.Printf("Error listing access tokens: %v\n", err)
+		http.Error(w, "Error listing access tokens: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	for _, token := range existing {
+		if token.Name == req.Name {
+			http.Error(w, "An access token with this name already exists: "+req.Name, http.StatusConflict)
+			return
+		}
+	}
+
+	accessToken := &db.AccessToken{
+		OrgId:       auth.OrgId,
+		UserId:      auth.User.Id,
+		Name:        req.Name,
+		Permissions: req.Permissions,
+	}
+
+	if req.ExpiresInDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		accessToken.ExpiresAt = &expiresAt
+	}
+
+	token, err := db.CreateAccessToken(accessToken)
+	if err != nil {
+		log.Printf("Error creating access token: %v\n", err)
+		http.Error(w, "Error creating access token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.CreateAccessTokenResponse{
+		AccessToken: accessToken.ToApi(),
+		Token:       token,
+	})
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully created access token", accessToken.Id)
+}
+
+func RevokeAccessTokenHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for RevokeAccessTokenHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	tokenId := mux.Vars(r)["tokenId"]
+
+	tokens, err := db.ListAccessTokens(auth.OrgId, auth.User.Id)
+	if err != nil {
+		log.Printf("Error listing access tokens: %v\n", err)
+		http.Error(w, "Error listing access tokens: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	found := false
+	for _, token := range tokens {
+		if token.Id == tokenId {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		log.Printf("Access token not found: %s\n", tokenId)
+		http.Error(w, "Access token not found: "+tokenId, http.StatusNotFound)
+		return
+	}
+
+	err = db.RevokeAccessToken(auth.OrgId, auth.User.Id, tokenId)
+	if err != nil {
+		log.Printf("Error revoking access token: %v\n", err)
+		http.Error(w, "Error revoking access token: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully revoked access token", tokenId)
+}
@@ -459,6 +459,10 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 		return nil
 	}
 
+	if shared.IsAccessToken(parsed.Token) {
+		return authenticateAccessToken(w, r, parsed, raiseErr)
+	}
+
 	// validate the token
 	authToken, err := db.ValidateAuthToken(parsed.Token)
 
@@ -585,6 +589,97 @@ func execAuthenticate(w http.ResponseWriter, r *http.Request, requireOrg bool, r
 
 }
 
+// authenticateAccessToken resolves a personal access token. Tokens are bound to a single org, and the user's permissions in the org are limited to the token's permissions.
+func authenticateAccessToken(w http.ResponseWriter, r *http.Request, parsed *shared.AuthHeader, raiseErr bool) *types.ServerAuth {
+	accessToken, err := db.ValidateAccessToken(parsed.Token)
+
+	if err != nil {
+		log.Printf("error validating access token: %v\n", err)
+
+		writeApiError(w, shared.ApiError{
+			Type:   shared.ApiErrorTypeInvalidToken,
+			Status: http.StatusUnauthorized,
+			Msg:    "Invalid access token",
+		})
+		return nil
+	}
+
+	if parsed.OrgId != "" && parsed.OrgId != accessToken.OrgId {
+		log.Println("access token org mismatch")
+		if raiseErr {
+			http.Error(w, "access token is not valid for this org", http.StatusUnauthorized)
+		}
+		return nil
+	}
+
+	user, err := db.GetUser(accessToken.UserId)
+
+	if err != nil {
+		log.Printf("error getting user: %v\n", err)
+		if raiseErr {
+			http.Error(w, "error getting user", http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	// the token stops working if the user leaves the org
+	isMember, err := db.ValidateOrgMembership(accessToken.UserId, accessToken.OrgId)
+
+	if err != nil {
+		log.Printf("error validating org membership: %v\n", err)
+		if raiseErr {
+			http.Error(w, "error validating org membership", http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	if !isMember {
+		log.Println("access token user is not a member of the org")
+		if raiseErr {
+			http.Error(w, "not a member of org", http.StatusUnauthorized)
+		}
+		return nil
+	}
+
+	permissions, err := db.GetUserPermissions(accessToken.UserId, accessToken.OrgId)
+
+	if err != nil {
+		log.Printf("error getting user permissions: %v\n", err)
+		if raiseErr {
+			http.Error(w, "error getting user permissions", http.StatusInternalServerError)
+		}
+		return nil
+	}
+
+	permissionsMap := make(shared.Permissions)
+	for _, permission := range permissions {
+		permissionsMap[permission] = true
+	}
+
+	auth := &types.ServerAuth{
+		AccessToken: accessToken,
+		User:        user,
+		OrgId:       accessToken.OrgId,
+		Permissions: accessToken.Permissions.Scope(permissionsMap),
+	}
+
+	_, apiErr := hooks.ExecHook(hooks.Authenticate, hooks.HookParams{
+		Auth: auth,
+		AuthenticateHookRequestParams: &hooks.AuthenticateHookRequestParams{
+			Path: r.URL.Path,
+		},
+	})
+
+	if apiErr != nil {
+		writeApiError(w, *apiErr)
+		return nil
+	}
+
+	log.Printf("UserId: %s, Email: %s, OrgId: %s, AccessTokenId: %s\n", user.Id, user.Email, accessToken.OrgId, accessToken.Id)
+
+	return auth
+}
+
 func authorizeProject(w http.ResponseWriter, projectId string, auth *types.ServerAuth) bool {
 	return authorizeProjectOptional(w, projectId, auth, true)
 }
@@ -205,3 +205,163 @@ func DeleteBranchHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("Successfully deleted branch")
 }
+
+func MergeBranchHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for MergeBranchHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId)
+
+	if authorizePlan(w, planId, auth) == nil {
+		return
+	}
+
+	var req shared.MergeBranchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if !validMergeResolution(w, req.Resolution) {
+		return
+	}
+
+	if req.Branch == branch {
+		http.Error(w, "Cannot merge a branch into itself", http.StatusBadRequest)
+		return
+	}
+
+	sourceBranch, err := db.GetDbBranch(planId, req.Branch)
+	if err != nil {
+		log.Printf("Error getting branch: %v\n", err)
+		http.Error(w, "Error getting branch: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if sourceBranch == nil {
+		http.Error(w, "Branch not found: "+req.Branch, http.StatusNotFound)
+		return
+	}
+
+	execMerge(w, r, auth.OrgId, auth.User.Id, planId, branch, "merge branch", func(params db.MergeParams) (*shared.MergeBranchResponse, error) {
+		params.Resolution = req.Resolution
+		return db.MergeBranch(params, req.Branch)
+	})
+}
+
+func CherryPickHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CherryPickHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId)
+
+	if authorizePlan(w, planId, auth) == nil {
+		return
+	}
+
+	var req shared.CherryPickRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if !validMergeResolution(w, req.Resolution) {
+		return
+	}
+
+	if req.Sha == "" {
+		http.Error(w, "Commit sha is required", http.StatusBadRequest)
+		return
+	}
+
+	execMerge(w, r, auth.OrgId, auth.User.Id, planId, branch, "cherry-pick", func(params db.MergeParams) (*shared.MergeBranchResponse, error) {
+		params.Resolution = req.Resolution
+		return db.CherryPickCommit(params, req.Sha)
+	})
+}
+
+func validMergeResolution(w http.ResponseWriter, resolution shared.MergeResolution) bool {
+	switch resolution {
+	case shared.MergeResolutionNone, shared.MergeResolutionOurs, shared.MergeResolutionTheirs:
+		return true
+	}
+
+	http.Error(w, "Invalid resolution: "+string(resolution), http.StatusBadRequest)
+	return false
+}
+
+// execMerge runs a merge or cherry-pick under a write lock on the target branch and writes the response
+func execMerge(w http.ResponseWriter, r *http.Request, orgId, userId, planId, branch, reason string, fn func(params db.MergeParams) (*shared.MergeBranchResponse, error)) {
+	ctx, cancel := context.WithCancel(r.Context())
+
+	var res *shared.MergeBranchResponse
+
+	err := db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:          orgId,
+		UserId:         userId,
+		PlanId:         planId,
+		Branch:         branch,
+		Reason:         reason,
+		Scope:          db.LockScopeWrite,
+		Ctx:            ctx,
+		CancelFn:       cancel,
+		ClearRepoOnErr: true,
+	}, func(repo *db.GitRepo) error {
+		var err error
+		res, err = fn(db.MergeParams{
+			Repo:   repo,
+			OrgId:  orgId,
+			PlanId: planId,
+			Branch: branch,
+		})
+		if err != nil {
+			return err
+		}
+
+		if res.Merged {
+			res.LatestSha, res.LatestCommit, err = repo.GetLatestCommit(branch)
+			if err != nil {
+				return fmt.Errorf("error getting latest commit: %v", err)
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error running %s: %v\n", reason, err)
+		http.Error(w, fmt.Sprintf("Error running %s: %v", reason, err), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Printf("Successfully processed %s\n", reason)
+}
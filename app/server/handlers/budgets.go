@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/types"
+
+	shared "plandex-shared"
+)
+
+func GetOrgBudgetsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetOrgBudgetsHandler")
+
+	if os.Getenv("IS_CLOUD") != "" {
+		http.Error(w, "Budgets are only available on self-hosted servers", http.StatusBadRequest)
+		return
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	orgBudgets, err := db.GetOrgBudgets(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting budgets: %v\n", err)
+		http.Error(w, "Error getting budgets: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var budgets *shared.SpendBudgets
+	if orgBudgets != nil {
+		budgets = &orgBudgets.Budgets
+	}
+
+	bytes, err := json.Marshal(budgets)
+	if err != nil {
+		log.Printf("Error marshalling budgets: %v\n", err)
+		http.Error(w, "Error marshalling budgets: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got budgets")
+}
+
+func UpdateOrgBudgetsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpdateOrgBudgetsHandler")
+
+	auth := authenticateBudgets(w, r)
+	if auth == nil {
+		return
+	}
+
+	var budgets shared.SpendBudgets
+	err := json.NewDecoder(r.Body).Decode(&budgets)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = budgets.Validate()
+	if err != nil {
+		http.Error(w, "Invalid budgets: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	orgBudgets, err := db.StoreOrgBudgets(auth.OrgId, &budgets)
+	if err != nil {
+		log.Printf("Error storing budgets: %v\n", err)
+		http.Error(w, "Error storing budgets: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(orgBudgets.Budgets)
+	if err != nil {
+		log.Printf("Error marshalling budgets: %v\n", err)
+		http.Error(w, "Error marshalling budgets: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully updated budgets")
+}
+
+func DeleteOrgBudgetsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteOrgBudgetsHandler")
+
+	auth := authenticateBudgets(w, r)
+	if auth == nil {
+		return
+	}
+
+	err := db.DeleteOrgBudgets(auth.OrgId)
+	if err != nil {
+		log.Printf("Error deleting budgets: %v\n", err)
+		http.Error(w, "Error deleting budgets: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	log.Println("Successfully deleted budgets")
+}
+
+func authenticateBudgets(w http.ResponseWriter, r *http.Request) *types.ServerAuth {
+	if os.Getenv("IS_CLOUD") != "" {
+		http.Error(w, "Budgets are only available on self-hosted servers", http.StatusBadRequest)
+		return nil
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return nil
+	}
+
+	if !auth.HasPermission(shared.PermissionManageBudgets) {
+		log.Println("User does not have permission to manage budgets")
+		http.Error(w, "User does not have permission to manage budgets", http.StatusForbidden)
+		return nil
+	}
+
+	return auth
+}
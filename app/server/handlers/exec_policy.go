@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/syntax"
+	"plandex-server/types"
+
+	shared "plandex-shared"
+)
+
+func GetOrgExecPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetOrgExecPolicyHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	orgPolicy, err := db.GetOrgExecPolicy(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting exec policy: %v\n", err)
+		http.Error(w, "Error getting exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var policy *shared.ExecPolicy
+	if orgPolicy != nil {
+		policy = &orgPolicy.Policy
+	}
+
+	bytes, err := json.Marshal(policy)
+	if err != nil {
+		log.Printf("Error marshalling exec policy: %v\n", err)
+		http.Error(w, "Error marshalling exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got exec policy")
+}
+
+func UpdateOrgExecPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpdateOrgExecPolicyHandler")
+
+	auth := authenticateExecPolicy(w, r)
+	if auth == nil {
+		return
+	}
+
+	var policy shared.ExecPolicy
+	err := json.NewDecoder(r.Body).Decode(&policy)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	err = policy.Validate()
+	if err != nil {
+		http.Error(w, "Invalid exec policy: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	orgPolicy, err := db.StoreOrgExecPolicy(auth.OrgId, &policy)
+	if err != nil {
+		log.Printf("Error storing exec policy: %v\n", err)
+		http.Error(w, "Error storing exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(orgPolicy.Policy)
+	if err != nil {
+		log.Printf("Error marshalling exec policy: %v\n", err)
+		http.Error(w, "Error marshalling exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully updated exec policy")
+}
+
+func DeleteOrgExecPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteOrgExecPolicyHandler")
+
+	auth := authenticateExecPolicy(w, r)
+	if auth == nil {
+		return
+	}
+
+	err := db.DeleteOrgExecPolicy(auth.OrgId)
+	if err != nil {
+		log.Printf("Error deleting exec policy: %v\n", err)
+		http.Error(w, "Error deleting exec policy: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	log.Println("Successfully deleted exec policy")
+}
+
+func CheckExecPolicyHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CheckExecPolicyHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	var req shared.CheckExecPolicyRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if req.ProjectPolicy != nil {
+		err = req.ProjectPolicy.Validate()
+		if err != nil {
+			http.Error(w, "Invalid project exec policy: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	orgPolicy, err := db.GetOrgExecPolicy(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting exec policy: %v\n", err)
+		http.Error(w, "Error getting exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	policies := map[shared.ExecPolicySource]*shared.ExecPolicy{}
+	if orgPolicy != nil {
+		policies[shared.ExecPolicySourceOrg] = &orgPolicy.Policy
+	}
+	if req.ProjectPolicy != nil {
+		policies[shared.ExecPolicySourceProject] = req.ProjectPolicy
+	}
+
+	res := checkExecPolicies(r.Context(), req.Script, policies)
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Printf("Successfully checked exec policy: %d violations\n", len(res.Violations))
+}
+
+// a command has to pass every policy, and the strictest onViolation action among the policies wins
+func checkExecPolicies(ctx context.Context, script string, policies map[shared.ExecPolicySource]*shared.ExecPolicy) *shared.CheckExecPolicyResponse {
+	res := &shared.CheckExecPolicyResponse{
+		Commands:   []*shared.ExecCommand{},
+		Violations: []*shared.ExecPolicyViolation{},
+	}
+
+	var onViolation shared.ExecPolicyOnViolation
+	for _, source := range []shared.ExecPolicySource{shared.ExecPolicySourceOrg, shared.ExecPolicySourceProject} {
+		policy := policies[source]
+		if policy == nil || policy.IsEmpty() {
+			continue
+		}
+		if onViolation == "" {
+			onViolation = policy.GetOnViolation()
+		} else {
+			onViolation = shared.StricterExecPolicyOnViolation(onViolation, policy.GetOnViolation())
+		}
+	}
+
+	// nothing to enforce
+	if onViolation == "" {
+		return res
+	}
+	res.OnViolation = onViolation
+
+	commands, err := syntax.ParseScriptCommands(ctx, script)
+	if err != nil {
+		res.Violations = append(res.Violations, &shared.ExecPolicyViolation{
+			Command: "(script)",
+			Reason:  err.Error(),
+		})
+		return res
+	}
+	res.Commands = commands
+
+	for _, source := range []shared.ExecPolicySource{shared.ExecPolicySourceOrg, shared.ExecPolicySourceProject} {
+		policy := policies[source]
+		if policy == nil {
+			continue
+		}
+		res.Violations = append(res.Violations, policy.Check(source, commands)...)
+	}
+
+	return res
+}
+
+func authenticateExecPolicy(w http.ResponseWriter, r *http.Request) *types.ServerAuth {
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return nil
+	}
+
+	if !auth.HasPermission(shared.PermissionManageExecPolicy) {
+		log.Println("User does not have permission to manage exec policy")
+		http.Error(w, "User does not have permission to manage exec policy", http.StatusForbidden)
+		return nil
+	}
+
+	return auth
+}
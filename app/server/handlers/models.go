@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
 	"os"
@@ -27,26 +28,12 @@ func CreateCustomModelHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if os.Getenv("IS_CLOUD") != "" && model.Provider == shared.ModelProviderCustom {
-		http.Error(w, "Custom model providers are not supported on Plandex Cloud", http.StatusBadRequest)
+	if err := checkCustomModelProvider(&model); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	dbModel := &db.AvailableModel{
-		Id:                    model.Id,
-		OrgId:                 auth.OrgId,
-		Provider:              model.Provider,
-		CustomProvider:        model.CustomProvider,
-		BaseUrl:               model.BaseUrl,
-		ModelName:             model.ModelName,
-		Description:           model.Description,
-		MaxTokens:             model.MaxTokens,
-		ApiKeyEnvVar:          model.ApiKeyEnvVar,
-		HasImageSupport:       model.HasImageSupport,
-		DefaultMaxConvoTokens: model.DefaultMaxConvoTokens,
-		MaxOutputTokens:       model.MaxOutputTokens,
-		ReservedOutputTokens:  model.ReservedOutputTokens,
-	}
+	dbModel := db.CustomModelFromApi(auth.OrgId, &model)
 
 	if err := db.CreateCustomModel(dbModel); err != nil {
 		log.Printf("Error creating custom model: %v\n", err)
@@ -113,19 +100,13 @@ func CreateModelPackHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	dbMs := &db.ModelPack{
-		OrgId:       auth.OrgId,
-		Name:        ms.Name,
-		Description: ms.Description,
-		PlanSummary: ms.PlanSummary,
-		Builder:     ms.Builder,
-		Namer:       ms.Namer,
-		CommitMsg:   ms.CommitMsg,
-		ExecStatus:  ms.ExecStatus,
-		Architect:   ms.Architect,
-		Coder:       ms.Coder,
+	if err := ms.Validate(); err != nil {
+		http.Error(w, "Invalid model pack: "+err.Error(), http.StatusBadRequest)
+		return
 	}
 
+	dbMs := db.ModelPackFromApi(auth.OrgId, &ms)
+
 	if err := db.CreateModelPack(dbMs); err != nil {
 		log.Printf("Error creating model pack: %v\n", err)
 		http.Error(w, "Failed to create model pack: "+err.Error(), http.StatusInternalServerError)
@@ -137,6 +118,37 @@ func CreateModelPackHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Successfully created model pack")
 }
 
+func UpdateModelPackHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpdateModelPackHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	var ms shared.ModelPack
+	if err := json.NewDecoder(r.Body).Decode(&ms); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := ms.Validate(); err != nil {
+		http.Error(w, "Invalid model pack: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dbMs := db.ModelPackFromApi(auth.OrgId, &ms)
+	dbMs.Id = mux.Vars(r)["setId"]
+
+	if err := db.UpdateModelPack(dbMs); err != nil {
+		log.Printf("Error updating model pack: %v\n", err)
+		http.Error(w, "Failed to update model pack: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully updated model pack")
+}
+
 func ListModelPacksHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Received request for ListModelPacksHandler")
 
@@ -185,3 +197,66 @@ func DeleteModelPackHandler(w http.ResponseWriter, r *http.Request) {
 
 	log.Println("Successfully deleted model pack")
 }
+
+func SyncModelsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SyncModelsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	var req shared.SyncModelsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := req.ModelsFile.Validate(); err != nil {
+		http.Error(w, "Invalid models file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for _, model := range req.Models {
+		if err := checkCustomModelProvider(model); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	changes, err := db.SyncModels(r.Context(), auth.OrgId, &req.ModelsFile, req.DryRun)
+	if err != nil {
+		log.Printf("Error syncing models: %v\n", err)
+		http.Error(w, "Error syncing models: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.SyncModelsResponse{
+		Changes: changes,
+		Applied: !req.DryRun,
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Printf("Successfully synced models - %d changes, dry run: %v\n", len(changes), req.DryRun)
+}
+
+func checkCustomModelProvider(model *shared.AvailableModel) error {
+	if os.Getenv("IS_CLOUD") != "" && model.Provider == shared.ModelProviderCustom {
+		return fmt.Errorf("Custom model providers are not supported on Plandex Cloud")
+	}
+
+	if os.Getenv("IS_CLOUD") != "" && shared.LocalModelProviders[model.Provider] {
+		return fmt.Errorf("Local model providers are not supported on Plandex Cloud")
+	}
+
+	return nil
+}
@@ -100,7 +100,7 @@ func CreateOrgHandler(w http.ResponseWriter, r *http.Request) {
 		}
 
 		// create a new org
-		org, err = db.CreateOrg(&req, auth.AuthToken.UserId, domain, tx)
+		org, err = db.CreateOrg(&req, auth.User.Id, domain, tx)
 
 		if err != nil {
 			log.Printf("Error creating org: %v\n", err)
@@ -0,0 +1,296 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+func ListPlanTemplatesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListPlanTemplatesHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	dbTemplates, err := db.ListPlanTemplates(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing plan templates: %v\n", err)
+		http.Error(w, "Error listing plan templates: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	apiTemplates := []*shared.PlanTemplate{}
+	for _, template := range dbTemplates {
+		apiTemplates = append(apiTemplates, template.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiTemplates)
+	if err != nil {
+		log.Printf("Error marshalling plan templates: %v\n", err)
+		http.Error(w, "Error marshalling plan templates: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed plan templates")
+}
+
+func GetPlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetPlanTemplateHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	template := getPlanTemplate(w, auth, mux.Vars(r)["name"])
+	if template == nil {
+		return
+	}
+
+	bytes, err := json.Marshal(template.ToApi())
+	if err != nil {
+		log.Printf("Error marshalling plan template: %v\n", err)
+		http.Error(w, "Error marshalling plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got plan template", template.Name)
+}
+
+func CreatePlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreatePlanTemplateHandler")
+
+	auth := authenticatePlanTemplates(w, r)
+	if auth == nil {
+		return
+	}
+
+	req := decodePlanTemplate(w, r, auth)
+	if req == nil {
+		return
+	}
+
+	existing, err := db.GetPlanTemplateByName(auth.OrgId, req.Name)
+	if err != nil {
+		log.Printf("Error getting plan template: %v\n", err)
+		http.Error(w, "Error getting plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if existing != nil {
+		http.Error(w, "A plan template with this name already exists: "+req.Name, http.StatusConflict)
+		return
+	}
+
+	template := &db.PlanTemplate{
+		OrgId:        auth.OrgId,
+		CreatedBy:    &auth.User.Id,
+		Name:         req.Name,
+		Description:  req.Description,
+		Notes:        req.Notes,
+		ContextPaths: req.ContextPaths,
+		Config:       req.Config,
+		ModelPack:    req.ModelPack,
+		Prompt:       req.Prompt,
+	}
+
+	err = db.CreatePlanTemplate(template)
+	if err != nil {
+		log.Printf("Error creating plan template: %v\n", err)
+		http.Error(w, "Error creating plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(template.ToApi())
+	if err != nil {
+		log.Printf("Error marshalling plan template: %v\n", err)
+		http.Error(w, "Error marshalling plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully created plan template", template.Name)
+}
+
+func UpdatePlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpdatePlanTemplateHandler")
+
+	auth := authenticatePlanTemplates(w, r)
+	if auth == nil {
+		return
+	}
+
+	template := getPlanTemplate(w, auth, mux.Vars(r)["name"])
+	if template == nil {
+		return
+	}
+
+	req := decodePlanTemplate(w, r, auth)
+	if req == nil {
+		return
+	}
+
+	if req.Name != template.Name {
+		existing, err := db.GetPlanTemplateByName(auth.OrgId, req.Name)
+		if err != nil {
+			log.Printf("Error getting plan template: %v\n", err)
+			http.Error(w, "Error getting plan template: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if existing != nil {
+			http.Error(w, "A plan template with this name already exists: "+req.Name, http.StatusConflict)
+			return
+		}
+	}
+
+	template.Name = req.Name
+	template.Description = req.Description
+	template.Notes = req.Notes
+	template.ContextPaths = req.ContextPaths
+	template.Config = req.Config
+	template.ModelPack = req.ModelPack
+	template.Prompt = req.Prompt
+
+	err := db.UpdatePlanTemplate(template)
+	if err != nil {
+		log.Printf("Error updating plan template: %v\n", err)
+		http.Error(w, "Error updating plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(template.ToApi())
+	if err != nil {
+		log.Printf("Error marshalling plan template: %v\n", err)
+		http.Error(w, "Error marshalling plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully updated plan template", template.Name)
+}
+
+func DeletePlanTemplateHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeletePlanTemplateHandler")
+
+	auth := authenticatePlanTemplates(w, r)
+	if auth == nil {
+		return
+	}
+
+	name := mux.Vars(r)["name"]
+
+	if getPlanTemplate(w, auth, name) == nil {
+		return
+	}
+
+	err := db.DeletePlanTemplate(auth.OrgId, name)
+	if err != nil {
+		log.Printf("Error deleting plan template: %v\n", err)
+		http.Error(w, "Error deleting plan template: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully deleted plan template", name)
+}
+
+func authenticatePlanTemplates(w http.ResponseWriter, r *http.Request) *types.ServerAuth {
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return nil
+	}
+
+	if !auth.HasPermission(shared.PermissionManagePlanTemplates) {
+		log.Println("User does not have permission to manage plan templates")
+		http.Error(w, "User does not have permission to manage plan templates", http.StatusForbidden)
+		return nil
+	}
+
+	return auth
+}
+
+func getPlanTemplate(w http.ResponseWriter, auth *types.ServerAuth, name string) *db.PlanTemplate {
+	template, err := db.GetPlanTemplateByName(auth.OrgId, name)
+	if err != nil {
+		log.Printf("Error getting plan template: %v\n", err)
+		http.Error(w, "Error getting plan template: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if template == nil {
+		log.Printf("Plan template not found: %s\n", name)
+		http.Error(w, "Plan template not found: "+name, http.StatusNotFound)
+		return nil
+	}
+
+	return template
+}
+
+// decodes and validates a template in the request body, including that its model pack exists for the org
+func decodePlanTemplate(w http.ResponseWriter, r *http.Request, auth *types.ServerAuth) *shared.PlanTemplate {
+	var req shared.PlanTemplate
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return nil
+	}
+	defer r.Body.Close()
+
+	req.Name = strings.TrimSpace(req.Name)
+
+	err = req.Validate()
+	if err != nil {
+		http.Error(w, "Invalid plan template: "+err.Error(), http.StatusBadRequest)
+		return nil
+	}
+
+	if req.ModelPack != "" {
+		found := false
+		for _, pack := range shared.BuiltInModelPacks {
+			if pack.Name == req.ModelPack {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			customPacks, err := db.ListModelPacks(auth.OrgId)
+			if err != nil {
+				log.Printf("Error listing model packs: %v\n", err)
+				http.Error(w, "Error listing model packs: "+err.Error(), http.StatusInternalServerError)
+				return nil
+			}
+
+			for _, pack := range customPacks {
+				if pack.Name == req.ModelPack {
+					found = true
+					break
+				}
+			}
+		}
+
+		if !found {
+			http.Error(w, fmt.Sprintf("Invalid plan template: model pack not found: %s", req.ModelPack), http.StatusBadRequest)
+			return nil
+		}
+	}
+
+	return &req
+}
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/hooks"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+const maxPlanArchiveBytes = 1024 * 1024 * 1024 // 1GB
+
+func ExportPlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ExportPlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+
+	log.Println("planId: ", planId)
+
+	plan := authorizePlan(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	var buf bytes.Buffer
+	err := db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:    auth.OrgId,
+		UserId:   auth.User.Id,
+		PlanId:   planId,
+		Branch:   "main",
+		Reason:   "export plan",
+		Scope:    db.LockScopeRead,
+		Ctx:      ctx,
+		CancelFn: cancel,
+	}, func(repo *db.GitRepo) error {
+		return db.WritePlanArchive(repo, plan, &buf)
+	})
+
+	if err != nil {
+		log.Printf("Error exporting plan: %v\n", err)
+		http.Error(w, "Error exporting plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/gzip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", plan.Name+".tar.gz"))
+	w.Write(buf.Bytes())
+
+	log.Printf("Successfully exported plan %s (%d bytes)\n", planId, buf.Len())
+}
+
+func ImportPlanHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ImportPlanHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	if !auth.HasPermission(shared.PermissionCreatePlan) {
+		log.Println("User does not have permission to create a plan")
+		http.Error(w, "User does not have permission to create a plan", http.StatusForbidden)
+		return
+	}
+
+	vars := mux.Vars(r)
+	projectId := vars["projectId"]
+
+	log.Println("projectId: ", projectId)
+
+	if !authorizeProject(w, projectId, auth) {
+		return
+	}
+
+	_, apiErr := hooks.ExecHook(hooks.WillCreatePlan, hooks.HookParams{Auth: auth})
+	if apiErr != nil {
+		writeApiError(w, *apiErr)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxPlanArchiveBytes)
+	defer r.Body.Close()
+
+	archive, err := db.ReadPlanArchive(r.Body)
+	if err != nil {
+		log.Printf("Error reading plan archive: %v\n", err)
+		http.Error(w, "Error reading plan archive: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	if name == "" {
+		name = archive.Manifest.Plan.Name
+	}
+
+	name, err = getUniquePlanName(projectId, auth.User.Id, name)
+	if err != nil {
+		log.Printf("Error checking if plan exists: %v\n", err)
+		http.Error(w, "Error checking if plan exists: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	plan, err := db.ImportPlanArchive(r.Context(), archive, auth.OrgId, projectId, auth.User.Id, name)
+	if err != nil {
+		log.Printf("Error importing plan: %v\n", err)
+		http.Error(w, "Error importing plan: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := shared.CreatePlanResponse{
+		Id:   plan.Id,
+		Name: plan.Name,
+	}
+
+	bytes, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Printf("Successfully imported plan: %v\n", plan.Id)
+}
@@ -9,6 +9,8 @@ import (
 	"net/http"
 	"plandex-server/db"
 	modelPlan "plandex-server/model/plan"
+	"plandex-server/webhooks"
+	"strings"
 	"time"
 
 	shared "plandex-shared"
@@ -90,6 +92,14 @@ func CurrentPlanHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	orgPolicy, err := db.GetOrgExecPolicy(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting exec policy: %v\n", err)
+		http.Error(w, "Error getting exec policy: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	planState.HasOrgExecPolicy = orgPolicy != nil && !orgPolicy.Policy.IsEmpty()
+
 	jsonBytes, err := json.Marshal(planState)
 
 	if err != nil {
@@ -232,6 +242,14 @@ func ApplyPlanHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Write([]byte(commitMsg))
 
+	webhooks.Dispatch(auth.OrgId, shared.WebhookEventPlanApplied, &shared.WebhookEventData{
+		PlanId:    planId,
+		PlanName:  plan.Name,
+		Branch:    branch,
+		UserId:    auth.User.Id,
+		CommitMsg: commitMsg,
+	})
+
 	log.Println("Successfully applied plan", planId)
 }
 
@@ -416,6 +434,89 @@ func RejectFilesHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Successfully rejected plan files", req.Paths)
 }
 
+func RejectReplacementsHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for RejectReplacementsHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	if authorizePlan(w, planId, auth) == nil {
+		return
+	}
+
+	var req shared.RejectReplacementsRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error decoding request: %v\n", err)
+		http.Error(w, "Error decoding request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Rejected) == 0 && len(req.Edited) == 0 {
+		http.Error(w, "No replacements to reject or edit", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+
+	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:          auth.OrgId,
+		UserId:         auth.User.Id,
+		PlanId:         planId,
+		Branch:         branch,
+		Scope:          db.LockScopeWrite,
+		Ctx:            ctx,
+		CancelFn:       cancel,
+		ClearRepoOnErr: true,
+	}, func(repo *db.GitRepo) error {
+		err := db.RejectReplacements(auth.OrgId, planId, &req, time.Now())
+		if err != nil {
+			return err
+		}
+
+		// make sure the remaining changes still apply cleanly -- if not, the error clears the repo and the rejections are rolled back
+		_, err = db.GetCurrentPlanState(db.CurrentPlanStateParams{
+			OrgId:  auth.OrgId,
+			PlanId: planId,
+		})
+		if err != nil {
+			return fmt.Errorf("remaining changes don't apply cleanly: %v", err)
+		}
+
+		var parts []string
+		if len(req.Rejected) > 0 {
+			parts = append(parts, fmt.Sprintf("rejected %d", len(req.Rejected)))
+		}
+		if len(req.Edited) > 0 {
+			parts = append(parts, fmt.Sprintf("edited %d", len(req.Edited)))
+		}
+		msg := "🚫 Reviewed pending changes: " + strings.Join(parts, ", ")
+
+		err = repo.GitAddAndCommit(branch, msg)
+		if err != nil {
+			return fmt.Errorf("error committing rejected changes: %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error rejecting replacements: %v\n", err)
+		http.Error(w, "Error rejecting replacements: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully rejected replacements")
+}
+
 func ArchivePlanHandler(w http.ResponseWriter, r *http.Request) {
 	auth := Authenticate(w, r, true)
 	if auth == nil {
@@ -8,6 +8,8 @@ import (
 	"log"
 	"net/http"
 	"plandex-server/db"
+	"plandex-server/embeddings"
+	"plandex-server/webhooks"
 
 	shared "plandex-shared"
 
@@ -209,6 +211,30 @@ func LoadContextHandler(w http.ResponseWriter, r *http.Request) {
 	log.Println("Successfully processed LoadContextHandler request")
 
 	w.Write(bytes)
+
+	if !res.MaxTokensExceeded {
+		var contextNames []string
+		for _, params := range requestBody {
+			name := params.Name
+			if name == "" {
+				name = params.FilePath
+			}
+			if name == "" {
+				name = params.Url
+			}
+			contextNames = append(contextNames, name)
+		}
+
+		webhooks.Dispatch(auth.OrgId, shared.WebhookEventContextLoaded, &shared.WebhookEventData{
+			PlanId:       planId,
+			PlanName:     plan.Name,
+			Branch:       branchName,
+			UserId:       auth.User.Id,
+			ContextNames: contextNames,
+			TokensAdded:  res.TokensAdded,
+			TotalTokens:  res.TotalTokens,
+		})
+	}
 }
 
 func UpdateContextHandler(w http.ResponseWriter, r *http.Request) {
@@ -445,3 +471,116 @@ func DeleteContextHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Write(bytes)
 }
+
+const defaultSearchContextLimit = 10
+
+func SearchContextHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SearchContextHandler")
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return
+	}
+
+	vars := mux.Vars(r)
+	planId := vars["planId"]
+	branch := vars["branch"]
+	log.Println("planId: ", planId, "branch: ", branch)
+
+	plan := authorizePlan(w, planId, auth)
+	if plan == nil {
+		return
+	}
+
+	var requestBody shared.SearchContextRequest
+	if err := json.NewDecoder(r.Body).Decode(&requestBody); err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	if requestBody.Query == "" {
+		http.Error(w, "Query is required", http.StatusBadRequest)
+		return
+	}
+
+	limit := requestBody.Limit
+	if limit <= 0 {
+		limit = defaultSearchContextLimit
+	}
+
+	embedder, err := embeddings.GetEmbedder()
+	if err != nil {
+		log.Printf("Error getting embedder: %v\n", err)
+		http.Error(w, "Error getting embedder: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if embedder == nil {
+		http.Error(w, "Semantic search isn't enabled on this server. Set EMBEDDINGS_PROVIDER to enable it.", http.StatusBadRequest)
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	var dbContexts []*db.Context
+
+	err = db.ExecRepoOperation(db.ExecRepoOperationParams{
+		OrgId:    auth.OrgId,
+		UserId:   auth.User.Id,
+		PlanId:   planId,
+		Branch:   branch,
+		Reason:   "search contexts",
+		Scope:    db.LockScopeRead,
+		Ctx:      ctx,
+		CancelFn: cancel,
+	}, func(repo *db.GitRepo) error {
+		res, err := db.GetPlanContexts(auth.OrgId, planId, false, true)
+		if err != nil {
+			return err
+		}
+
+		dbContexts = res
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error getting contexts: %v\n", err)
+		http.Error(w, "Error getting contexts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	mapParts := embeddings.MapPartsFromContexts(dbContexts)
+	if len(mapParts) == 0 {
+		http.Error(w, "No project map is loaded in the plan", http.StatusBadRequest)
+		return
+	}
+
+	results, err := embeddings.Search(r.Context(), embeddings.SearchParams{
+		Embedder:  embedder,
+		OrgId:     auth.OrgId,
+		ProjectId: plan.ProjectId,
+		MapParts:  mapParts,
+		Query:     requestBody.Query,
+		Limit:     limit,
+	})
+
+	if err != nil {
+		log.Printf("Error searching contexts: %v\n", err)
+		http.Error(w, "Error searching contexts: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.SearchContextResponse{Results: results})
+
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("Successfully searched contexts - %d results\n", len(results))
+
+	w.Write(bytes)
+}
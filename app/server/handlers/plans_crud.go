@@ -77,24 +77,12 @@ func CreatePlanHandler(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 	} else {
-		i := 2
-		originalName := name
-		for {
-			var count int
-			err := db.Conn.Get(&count, "SELECT COUNT(*) FROM plans WHERE project_id = $1 AND owner_id = $2 AND name = $3", projectId, auth.User.Id, name)
-
-			if err != nil {
-				log.Printf("Error checking if plan exists: %v\n", err)
-				http.Error(w, "Error checking if plan exists: "+err.Error(), http.StatusInternalServerError)
-				return
-			}
-
-			if count == 0 {
-				break
-			}
-
-			name = originalName + "." + fmt.Sprint(i)
-			i++
+		name, err = getUniquePlanName(projectId, auth.User.Id, name)
+
+		if err != nil {
+			log.Printf("Error checking if plan exists: %v\n", err)
+			http.Error(w, "Error checking if plan exists: "+err.Error(), http.StatusInternalServerError)
+			return
 		}
 	}
 
@@ -639,3 +627,24 @@ func GetCurrentBranchByPlanIdHandler(w http.ResponseWriter, r *http.Request) {
 
 	w.Write(bytes)
 }
+
+// appends .2, .3, etc. to the name if the user already has a plan with the same name in the project
+func getUniquePlanName(projectId, userId, name string) (string, error) {
+	i := 2
+	originalName := name
+	for {
+		var count int
+		err := db.Conn.Get(&count, "SELECT COUNT(*) FROM plans WHERE project_id = $1 AND owner_id = $2 AND name = $3", projectId, userId, name)
+
+		if err != nil {
+			return "", err
+		}
+
+		if count == 0 {
+			return name, nil
+		}
+
+		name = originalName + "." + fmt.Sprint(i)
+		i++
+	}
+}
@@ -257,6 +257,11 @@ func SignOutHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if auth.AccessToken != nil {
+		http.Error(w, "Can't sign out of an access token—revoke it instead", http.StatusBadRequest)
+		return
+	}
+
 	_, err := db.Conn.Exec("UPDATE auth_tokens SET deleted_at = NOW() WHERE token_hash = $1", auth.AuthToken.TokenHash)
 
 	if err != nil {
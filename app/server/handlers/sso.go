@@ -0,0 +1,621 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/sso"
+	"plandex-server/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+func GetSsoConfigHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetSsoConfigHandler")
+
+	auth := authenticateSso(w, r)
+	if auth == nil {
+		return
+	}
+
+	config, err := db.GetOrgSsoConfig(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting sso config: %v\n", err)
+		http.Error(w, "Error getting sso config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var apiConfig *shared.SsoConfig
+	if config != nil {
+		apiConfig = config.ToApi()
+	}
+
+	bytes, err := json.Marshal(apiConfig)
+	if err != nil {
+		log.Printf("Error marshalling sso config: %v\n", err)
+		http.Error(w, "Error marshalling sso config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got sso config")
+}
+
+func UpdateSsoConfigHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for UpdateSsoConfigHandler")
+
+	auth := authenticateSso(w, r)
+	if auth == nil {
+		return
+	}
+
+	var req shared.UpdateSsoConfigRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	req.IssuerUrl = strings.TrimSuffix(strings.TrimSpace(req.IssuerUrl), "/")
+	req.ClientId = strings.TrimSpace(req.ClientId)
+	req.Scopes = strings.Join(strings.Fields(req.Scopes), " ")
+	req.RoleClaim = strings.TrimSpace(req.RoleClaim)
+
+	err = sso.ValidateIssuerUrl(req.IssuerUrl)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.ClientId == "" {
+		http.Error(w, "Client id is required", http.StatusBadRequest)
+		return
+	}
+
+	if req.Scopes == "" {
+		req.Scopes = shared.SsoDefaultScopes
+	} else if !strings.Contains(" "+req.Scopes+" ", " openid ") {
+		http.Error(w, "Scopes must include openid", http.StatusBadRequest)
+		return
+	}
+
+	if len(req.RoleMapping) > 0 && req.RoleClaim == "" {
+		http.Error(w, "A role claim is required to map roles", http.StatusBadRequest)
+		return
+	}
+
+	for value, roleName := range req.RoleMapping {
+		role, err := db.GetOrgRoleByName(auth.OrgId, roleName)
+		if err != nil {
+			log.Printf("Error getting org role: %v\n", err)
+			http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if role == nil {
+			http.Error(w, fmt.Sprintf("Role %q (mapped from %q) doesn't exist", roleName, value), http.StatusBadRequest)
+			return
+		}
+	}
+
+	org, err := db.GetOrg(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting org: %v\n", err)
+		http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	// users are routed to the org's provider by their email domain
+	domain := auth.User.Domain
+	if org.Domain != nil {
+		domain = *org.Domain
+	}
+
+	if shared.IsEmailServiceDomain(domain) {
+		log.Printf("Invalid domain: %v\n", domain)
+		http.Error(w, "SSO can't be configured for an email service domain: "+domain, http.StatusBadRequest)
+		return
+	}
+
+	existing, err := db.GetOrgSsoConfig(auth.OrgId)
+	if err != nil {
+		log.Printf("Error getting sso config: %v\n", err)
+		http.Error(w, "Error getting sso config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	clientSecret := req.ClientSecret
+	if clientSecret == "" && existing != nil && existing.ClientId == req.ClientId {
+		clientSecret = existing.ClientSecret
+	}
+
+	// make sure the provider is reachable and valid before users try to sign in with it
+	_, err = sso.Discover(r.Context(), req.IssuerUrl)
+	if err != nil {
+		log.Printf("Error discovering sso provider: %v\n", err)
+		http.Error(w, "Error discovering sso provider: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	config := &db.OrgSsoConfig{
+		OrgId:        auth.OrgId,
+		Domain:       domain,
+		IssuerUrl:    req.IssuerUrl,
+		ClientId:     req.ClientId,
+		ClientSecret: clientSecret,
+		Scopes:       req.Scopes,
+		RoleClaim:    req.RoleClaim,
+		RoleMapping:  req.RoleMapping,
+	}
+
+	err = db.StoreOrgSsoConfig(config)
+	if err != nil {
+		if errors.Is(err, db.ErrSsoDomainTaken) {
+			http.Error(w, "SSO is already configured for "+domain+" by another org", http.StatusConflict)
+			return
+		}
+
+		log.Printf("Error storing sso config: %v\n", err)
+		http.Error(w, "Error storing sso config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(config.ToApi())
+	if err != nil {
+		log.Printf("Error marshalling sso config: %v\n", err)
+		http.Error(w, "Error marshalling sso config: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully updated sso config")
+}
+
+func DeleteSsoConfigHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteSsoConfigHandler")
+
+	auth := authenticateSso(w, r)
+	if auth == nil {
+		return
+	}
+
+	err := db.DeleteOrgSsoConfig(auth.OrgId)
+	if err != nil {
+		log.Printf("Error deleting sso config: %v\n", err)
+		http.Error(w, "Error deleting sso config: "+err.Error(), http.StatusNotFound)
+		return
+	}
+
+	log.Println("Successfully deleted sso config")
+}
+
+func StartSsoHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for StartSsoHandler")
+
+	var req shared.StartSsoRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	config, org := resolveSsoConfig(w, req.Email)
+	if config == nil {
+		return
+	}
+
+	err = sso.ValidateRedirectUri(req.RedirectUri)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if req.State == "" || req.CodeChallenge == "" || req.Nonce == "" {
+		http.Error(w, "State, code challenge, and nonce are required", http.StatusBadRequest)
+		return
+	}
+
+	authUrl, err := sso.AuthorizationUrl(r.Context(), ssoClientConfig(config), req.RedirectUri, req.State, req.CodeChallenge, req.Nonce)
+	if err != nil {
+		log.Printf("Error building authorization url: %v\n", err)
+		http.Error(w, "Error building authorization url: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.StartSsoResponse{
+		OrgName:          org.Name,
+		AuthorizationUrl: authUrl,
+	})
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully started sso sign in")
+}
+
+func SsoSignInHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for SsoSignInHandler")
+
+	var req shared.SsoSignInRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	config, org := resolveSsoConfig(w, req.Email)
+	if config == nil {
+		return
+	}
+
+	err = sso.ValidateRedirectUri(req.RedirectUri)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	claims, err := sso.ExchangeCode(r.Context(), ssoClientConfig(config), req.Code, req.CodeVerifier, req.RedirectUri, req.Nonce)
+	if err != nil {
+		log.Printf("Error exchanging sso code: %v\n", err)
+		http.Error(w, "Error signing in with sso: "+err.Error(), http.StatusUnauthorized)
+		return
+	}
+
+	res := signInWithSso(w, r, config, org, claims)
+	if res == nil {
+		return
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully signed in with sso")
+}
+
+func StartSsoDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for StartSsoDeviceHandler")
+
+	var req shared.StartSsoDeviceRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	config, org := resolveSsoConfig(w, req.Email)
+	if config == nil {
+		return
+	}
+
+	auth, err := sso.StartDeviceAuthorization(r.Context(), ssoClientConfig(config))
+	if err != nil {
+		log.Printf("Error starting device authorization: %v\n", err)
+		http.Error(w, "Error starting device authorization: "+err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.StartSsoDeviceResponse{
+		OrgName:                 org.Name,
+		DeviceCode:              auth.DeviceCode,
+		UserCode:                auth.UserCode,
+		VerificationUri:         auth.VerificationUri,
+		VerificationUriComplete: auth.VerificationUriComplete,
+		ExpiresIn:               auth.ExpiresIn,
+		Interval:                auth.Interval,
+	})
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully started sso device authorization")
+}
+
+func PollSsoDeviceHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for PollSsoDeviceHandler")
+
+	var req shared.SsoDevicePollRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	config, org := resolveSsoConfig(w, req.Email)
+	if config == nil {
+		return
+	}
+
+	var res shared.SsoDevicePollResponse
+
+	claims, err := sso.PollDeviceAuthorization(r.Context(), ssoClientConfig(config), req.DeviceCode)
+	switch {
+	case errors.Is(err, sso.ErrAuthorizationPending):
+		res.Status = shared.SsoDeviceStatusPending
+	case errors.Is(err, sso.ErrSlowDown):
+		res.Status = shared.SsoDeviceStatusSlowDown
+	case err != nil:
+		log.Printf("Error polling device authorization: %v\n", err)
+		http.Error(w, "Error signing in with sso: "+err.Error(), http.StatusUnauthorized)
+		return
+	default:
+		session := signInWithSso(w, r, config, org, claims)
+		if session == nil {
+			return
+		}
+		res.Status = shared.SsoDeviceStatusComplete
+		res.Session = session
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Printf("Successfully polled sso device authorization: %s\n", res.Status)
+}
+
+func authenticateSso(w http.ResponseWriter, r *http.Request) *types.ServerAuth {
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return nil
+	}
+
+	if !auth.HasPermission(shared.PermissionManageEmailDomainAuth) {
+		log.Println("User does not have permission to manage sso")
+		http.Error(w, "User does not have permission to manage sso", http.StatusForbidden)
+		return nil
+	}
+
+	return auth
+}
+
+// resolveSsoConfig finds the org whose provider users with this email sign in with
+func resolveSsoConfig(w http.ResponseWriter, email string) (*db.OrgSsoConfig, *db.Org) {
+	split := strings.Split(strings.ToLower(strings.TrimSpace(email)), "@")
+	if len(split) != 2 || split[1] == "" {
+		http.Error(w, "Invalid email: "+email, http.StatusBadRequest)
+		return nil, nil
+	}
+
+	config, err := db.GetSsoConfigForDomain(split[1])
+	if err != nil {
+		log.Printf("Error getting sso config: %v\n", err)
+		http.Error(w, "Error getting sso config: "+err.Error(), http.StatusInternalServerError)
+		return nil, nil
+	}
+
+	if config == nil {
+		http.Error(w, "SSO isn't configured for "+split[1], http.StatusNotFound)
+		return nil, nil
+	}
+
+	org, err := db.GetOrg(config.OrgId)
+	if err != nil {
+		log.Printf("Error getting org: %v\n", err)
+		http.Error(w, "Error getting org: "+err.Error(), http.StatusInternalServerError)
+		return nil, nil
+	}
+
+	return config, org
+}
+
+func ssoClientConfig(config *db.OrgSsoConfig) sso.Config {
+	return sso.Config{
+		IssuerUrl:    config.IssuerUrl,
+		ClientId:     config.ClientId,
+		ClientSecret: config.ClientSecret,
+		Scopes:       config.Scopes,
+	}
+}
+
+// signInWithSso signs in the user that the provider vouched for. Users that aren't in the org yet are added if the org auto-adds users from its domain, or can sign in to accept a pending invite. Roles mapped from the ID token's claims are applied on every sign in, so changes at the provider carry over.
+func signInWithSso(w http.ResponseWriter, r *http.Request, config *db.OrgSsoConfig, org *db.Org, claims *sso.Claims) *shared.SessionResponse {
+	email := strings.ToLower(claims.Email)
+
+	if email == "" {
+		http.Error(w, "SSO provider didn't return an email -- make sure the email scope is allowed", http.StatusForbidden)
+		return nil
+	}
+
+	if claims.EmailVerified != nil && !*claims.EmailVerified {
+		http.Error(w, "Email isn't verified by the SSO provider", http.StatusForbidden)
+		return nil
+	}
+
+	// the provider is only trusted for the domain it's configured for
+	split := strings.Split(email, "@")
+	if len(split) != 2 || split[1] != config.Domain {
+		log.Printf("SSO email %s doesn't match domain %s\n", email, config.Domain)
+		http.Error(w, "SSO email isn't on "+config.Domain, http.StatusForbidden)
+		return nil
+	}
+	domain := split[1]
+
+	var mappedRole *db.OrgRole
+	roleName := sso.MapRole(claims, config.RoleClaim, config.RoleMapping)
+	if roleName != "" {
+		var err error
+		mappedRole, err = db.GetOrgRoleByName(org.Id, roleName)
+		if err != nil {
+			log.Printf("Error getting org role: %v\n", err)
+			http.Error(w, "Error getting org role: "+err.Error(), http.StatusInternalServerError)
+			return nil
+		}
+		if mappedRole == nil {
+			log.Printf("SSO role mapping references missing role %s -- ignoring\n", roleName)
+		}
+	}
+
+	user, err := db.GetUserByEmail(email)
+	if err != nil {
+		log.Printf("Error getting user: %v\n", err)
+		http.Error(w, "Error getting user: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	var orgUser *db.OrgUser
+	if user != nil {
+		orgUser, err = db.GetOrgUser(user.Id, org.Id)
+		if err != nil {
+			log.Printf("Error getting org user: %v\n", err)
+			http.Error(w, "Error getting org user: "+err.Error(), http.StatusInternalServerError)
+			return nil
+		}
+	}
+
+	autoJoin := org.AutoAddDomainUsers && org.Domain != nil && *org.Domain == domain
+
+	if orgUser == nil && !autoJoin {
+		// invites are accepted on the first authenticated request
+		invite, err := db.GetActiveInviteByEmail(org.Id, email)
+		if err != nil {
+			log.Printf("Error getting invite: %v\n", err)
+			http.Error(w, "Error getting invite: "+err.Error(), http.StatusInternalServerError)
+			return nil
+		}
+
+		if invite == nil {
+			log.Printf("User %s isn't a member of org %s\n", email, org.Id)
+			http.Error(w, "You aren't a member of "+org.Name+" -- ask an org owner to invite you", http.StatusForbidden)
+			return nil
+		}
+	}
+
+	ownerRoleId, err := db.GetOrgOwnerRoleId()
+	if err != nil {
+		log.Printf("Error getting org owner role id: %v\n", err)
+		http.Error(w, "Error getting org owner role id: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	var token string
+	err = db.WithTx(r.Context(), "sso sign in", func(tx *sqlx.Tx) error {
+		var err error
+
+		if user == nil {
+			name := claims.Name
+			if name == "" {
+				name = split[0]
+			}
+
+			user, err = db.CreateUser(name, email, tx)
+			if err != nil {
+				log.Printf("Error creating user: %v\n", err)
+				return fmt.Errorf("error creating user: %v", err)
+			}
+		}
+
+		if orgUser == nil && autoJoin {
+			roleId := ""
+			if mappedRole != nil {
+				roleId = mappedRole.Id
+			} else {
+				roleId, err = db.GetOrgMemberRoleId()
+				if err != nil {
+					return fmt.Errorf("error getting org member role id: %v", err)
+				}
+			}
+
+			err = db.CreateOrgUser(org.Id, user.Id, roleId, tx)
+			if err != nil {
+				log.Printf("Error adding org user: %v\n", err)
+				return fmt.Errorf("error adding org user: %v", err)
+			}
+		} else if orgUser != nil && mappedRole != nil && mappedRole.Id != orgUser.OrgRoleId {
+			isLastOwner := false
+			if orgUser.OrgRoleId == ownerRoleId {
+				numOwners, err := db.NumUsersWithRole(org.Id, ownerRoleId)
+				if err != nil {
+					return fmt.Errorf("error getting number of owners: %v", err)
+				}
+				isLastOwner = numOwners <= 1
+			}
+
+			if isLastOwner {
+				log.Printf("Not changing role for %s -- they're the org's only owner\n", email)
+			} else {
+				err = db.UpdateOrgUserRole(org.Id, user.Id, mappedRole.Id, tx)
+				if err != nil {
+					log.Printf("Error updating org user role: %v\n", err)
+					return fmt.Errorf("error updating org user role: %v", err)
+				}
+			}
+		}
+
+		token, _, err = db.CreateAuthToken(user.Id, tx)
+		if err != nil {
+			log.Printf("Error creating auth token: %v\n", err)
+			return fmt.Errorf("error creating auth token: %v", err)
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Printf("Error signing in with sso: %v\n", err)
+		http.Error(w, "Error signing in with sso: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	err = SetAuthCookieIfBrowser(w, r, user, token, org.Id)
+	if err != nil {
+		log.Printf("Error setting auth cookie: %v\n", err)
+		http.Error(w, "Error setting auth cookie: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	apiOrgs, apiErr := toApiOrgs([]*db.Org{org})
+	if apiErr != nil {
+		log.Printf("Error converting orgs to api orgs: %v\n", apiErr)
+		writeApiError(w, *apiErr)
+		return nil
+	}
+
+	log.Printf("Signed in %s with sso for org %s\n", email, org.Id)
+
+	return &shared.SessionResponse{
+		UserId:      user.Id,
+		Token:       token,
+		Email:       user.Email,
+		UserName:    user.Name,
+		Orgs:        apiOrgs,
+		IsLocalMode: os.Getenv("GOENV") == "development" && os.Getenv("LOCAL_MODE") == "1",
+	}
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"encoding/json"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"plandex-server/types"
+	"strconv"
+
+	shared "plandex-shared"
+)
+
+const maxUsageLogPageSize = 500
+
+func GetUsageReportHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetUsageReportHandler")
+
+	auth, req := authenticateUsage(w, r)
+	if auth == nil {
+		return
+	}
+
+	filter := getUsageFilter(w, auth, req)
+	if filter == nil {
+		return
+	}
+
+	res, err := db.GetUsageReport(*filter, req.UtcOffset)
+	if err != nil {
+		log.Printf("Error getting usage report: %v\n", err)
+		http.Error(w, "Error getting usage report: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	res.OrgWide = filter.UserId == ""
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling usage report: %v\n", err)
+		http.Error(w, "Error marshalling usage report: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got usage report")
+}
+
+func GetUsageLogHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for GetUsageLogHandler")
+
+	auth, req := authenticateUsage(w, r)
+	if auth == nil {
+		return
+	}
+
+	pageSize, err := strconv.Atoi(r.URL.Query().Get("size"))
+	if err != nil || pageSize < 1 || pageSize > maxUsageLogPageSize {
+		http.Error(w, "Invalid page size", http.StatusBadRequest)
+		return
+	}
+
+	pageNum, err := strconv.Atoi(r.URL.Query().Get("page"))
+	if err != nil || pageNum < 1 {
+		http.Error(w, "Invalid page number", http.StatusBadRequest)
+		return
+	}
+
+	filter := getUsageFilter(w, auth, req)
+	if filter == nil {
+		return
+	}
+
+	entries, numPages, err := db.ListModelUsage(*filter, pageSize, pageNum)
+	if err != nil {
+		log.Printf("Error listing usage: %v\n", err)
+		http.Error(w, "Error listing usage: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	res := shared.UsageLogResponse{
+		Usage:    []*shared.ModelUsage{},
+		NumPages: numPages,
+	}
+	for _, entry := range entries {
+		res.Usage = append(res.Usage, entry.ToApi())
+	}
+
+	bytes, err := json.Marshal(res)
+	if err != nil {
+		log.Printf("Error marshalling usage log: %v\n", err)
+		http.Error(w, "Error marshalling usage log: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully got usage log")
+}
+
+func authenticateUsage(w http.ResponseWriter, r *http.Request) (*types.ServerAuth, *shared.UsageRequest) {
+	if os.Getenv("IS_CLOUD") != "" {
+		http.Error(w, "The usage ledger is only available on self-hosted servers", http.StatusBadRequest)
+		return nil, nil
+	}
+
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return nil, nil
+	}
+
+	var req shared.UsageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		log.Printf("Error decoding request body: %v\n", err)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return nil, nil
+	}
+
+	return auth, &req
+}
+
+// getUsageFilter limits users without the view_org_usage permission to their own usage
+func getUsageFilter(w http.ResponseWriter, auth *types.ServerAuth, req *shared.UsageRequest) *db.UsageFilter {
+	filter := &db.UsageFilter{
+		OrgId:     auth.OrgId,
+		SessionId: req.SessionId,
+		Since:     req.Since,
+	}
+
+	if req.PlanId != "" {
+		if authorizePlan(w, req.PlanId, auth) == nil {
+			return nil
+		}
+		filter.PlanId = req.PlanId
+	}
+
+	if !auth.HasPermission(shared.PermissionViewOrgUsage) {
+		filter.UserId = auth.User.Id
+	}
+
+	return filter
+}
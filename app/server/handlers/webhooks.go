@@ -0,0 +1,250 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"plandex-server/db"
+	"plandex-server/types"
+	"plandex-server/webhooks"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/gorilla/mux"
+)
+
+const webhookDeliveriesLimit = 50
+
+func ListWebhooksHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListWebhooksHandler")
+
+	auth := authenticateWebhooks(w, r)
+	if auth == nil {
+		return
+	}
+
+	dbWebhooks, err := db.ListWebhooks(auth.OrgId)
+	if err != nil {
+		log.Printf("Error listing webhooks: %v\n", err)
+		http.Error(w, "Error listing webhooks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var apiWebhooks []*shared.Webhook
+	for _, webhook := range dbWebhooks {
+		apiWebhooks = append(apiWebhooks, webhook.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiWebhooks)
+	if err != nil {
+		log.Printf("Error marshalling webhooks: %v\n", err)
+		http.Error(w, "Error marshalling webhooks: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed webhooks")
+}
+
+func CreateWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for CreateWebhookHandler")
+
+	auth := authenticateWebhooks(w, r)
+	if auth == nil {
+		return
+	}
+
+	var req shared.CreateWebhookRequest
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		log.Printf("Error parsing request body: %v\n", err)
+		http.Error(w, "Error parsing request body", http.StatusBadRequest)
+		return
+	}
+	defer r.Body.Close()
+
+	req.Url = strings.TrimSpace(req.Url)
+	err = webhooks.ValidateUrl(req.Url)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if len(req.Events) == 0 {
+		http.Error(w, "At least one event is required", http.StatusBadRequest)
+		return
+	}
+
+	for _, event := range req.Events {
+		if !shared.IsValidWebhookEvent(event) {
+			http.Error(w, fmt.Sprintf("Invalid event: %s", event), http.StatusBadRequest)
+			return
+		}
+	}
+
+	secret, err := webhooks.GenerateSecret()
+	if err != nil {
+		log.Printf("Error generating webhook secret: %v\n", err)
+		http.Error(w, "Error generating webhook secret: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	webhook := &db.Webhook{
+		OrgId:       auth.OrgId,
+		CreatedBy:   &auth.User.Id,
+		Url:         req.Url,
+		Description: req.Description,
+		Secret:      secret,
+		Events:      req.Events,
+	}
+
+	err = db.CreateWebhook(webhook)
+	if err != nil {
+		log.Printf("Error creating webhook: %v\n", err)
+		http.Error(w, "Error creating webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(shared.CreateWebhookResponse{
+		Webhook: webhook.ToApi(),
+		Secret:  secret,
+	})
+	if err != nil {
+		log.Printf("Error marshalling response: %v\n", err)
+		http.Error(w, "Error marshalling response: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully created webhook", webhook.Id)
+}
+
+func DeleteWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for DeleteWebhookHandler")
+
+	auth := authenticateWebhooks(w, r)
+	if auth == nil {
+		return
+	}
+
+	webhookId := mux.Vars(r)["webhookId"]
+
+	if getWebhook(w, auth, webhookId) == nil {
+		return
+	}
+
+	err := db.DeleteWebhook(auth.OrgId, webhookId)
+	if err != nil {
+		log.Printf("Error deleting webhook: %v\n", err)
+		http.Error(w, "Error deleting webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	log.Println("Successfully deleted webhook", webhookId)
+}
+
+func ListWebhookDeliveriesHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for ListWebhookDeliveriesHandler")
+
+	auth := authenticateWebhooks(w, r)
+	if auth == nil {
+		return
+	}
+
+	webhookId := mux.Vars(r)["webhookId"]
+
+	if getWebhook(w, auth, webhookId) == nil {
+		return
+	}
+
+	deliveries, err := db.ListWebhookDeliveries(webhookId, webhookDeliveriesLimit)
+	if err != nil {
+		log.Printf("Error listing webhook deliveries: %v\n", err)
+		http.Error(w, "Error listing webhook deliveries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	var apiDeliveries []*shared.WebhookDelivery
+	for _, delivery := range deliveries {
+		apiDeliveries = append(apiDeliveries, delivery.ToApi())
+	}
+
+	bytes, err := json.Marshal(apiDeliveries)
+	if err != nil {
+		log.Printf("Error marshalling webhook deliveries: %v\n", err)
+		http.Error(w, "Error marshalling webhook deliveries: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully listed webhook deliveries")
+}
+
+func TestWebhookHandler(w http.ResponseWriter, r *http.Request) {
+	log.Println("Received request for TestWebhookHandler")
+
+	auth := authenticateWebhooks(w, r)
+	if auth == nil {
+		return
+	}
+
+	webhook := getWebhook(w, auth, mux.Vars(r)["webhookId"])
+	if webhook == nil {
+		return
+	}
+
+	delivery, err := webhooks.SendPing(r.Context(), webhook)
+	if err != nil {
+		log.Printf("Error sending test webhook: %v\n", err)
+		http.Error(w, "Error sending test webhook: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(delivery.ToApi())
+	if err != nil {
+		log.Printf("Error marshalling webhook delivery: %v\n", err)
+		http.Error(w, "Error marshalling webhook delivery: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Write(bytes)
+
+	log.Println("Successfully sent test webhook", webhook.Id)
+}
+
+func authenticateWebhooks(w http.ResponseWriter, r *http.Request) *types.ServerAuth {
+	auth := Authenticate(w, r, true)
+	if auth == nil {
+		return nil
+	}
+
+	if !auth.HasPermission(shared.PermissionManageWebhooks) {
+		log.Println("User does not have permission to manage webhooks")
+		http.Error(w, "User does not have permission to manage webhooks", http.StatusForbidden)
+		return nil
+	}
+
+	return auth
+}
+
+func getWebhook(w http.ResponseWriter, auth *types.ServerAuth, webhookId string) *db.Webhook {
+	webhook, err := db.GetWebhook(auth.OrgId, webhookId)
+	if err != nil {
+		log.Printf("Error getting webhook: %v\n", err)
+		http.Error(w, "Error getting webhook: "+err.Error(), http.StatusInternalServerError)
+		return nil
+	}
+
+	if webhook == nil {
+		log.Printf("Webhook not found: %s\n", webhookId)
+		http.Error(w, "Webhook not found: "+webhookId, http.StatusNotFound)
+		return nil
+	}
+
+	return webhook
+}
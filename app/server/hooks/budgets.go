@@ -0,0 +1,123 @@
+package hooks
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+func init() {
+	// budgets are enforced against the self-hosted usage ledger -- Plandex Cloud checks credits in its own hook instead
+	if os.Getenv("IS_CLOUD") == "" {
+		RegisterHook(WillSendModelRequest, checkBudgets)
+	}
+}
+
+// checkBudgets stops a model call that would put the org over one of its budgets, counting the call's estimated input. If a budget is past its warning threshold, the closest one to its limit is returned as a warning.
+func checkBudgets(params HookParams) (HookResult, *shared.ApiError) {
+	req := params.WillSendModelRequestParams
+	auth := params.Auth
+
+	if req == nil || auth == nil {
+		return HookResult{}, nil
+	}
+
+	orgBudgets, err := db.GetOrgBudgets(auth.OrgId)
+	if err != nil {
+		return HookResult{}, &shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusInternalServerError,
+			Msg:    err.Error(),
+		}
+	}
+	if orgBudgets == nil || orgBudgets.Budgets.IsEmpty() {
+		return HookResult{}, nil
+	}
+	budgets := orgBudgets.Budgets
+
+	var estimatedCost decimal.Decimal
+	pricing, err := getModelPricing(auth.OrgId, req.ModelProvider, req.ModelId, req.ModelName)
+	if err != nil {
+		// still check token limits and spend so far
+		log.Printf("checkBudgets - error getting model pricing: %v", err)
+	}
+	if pricing != nil {
+		estimatedCost = pricing.Cost(req.InputTokens, 0, 0)
+	}
+
+	now := time.Now().UTC()
+
+	type scopedLimit struct {
+		scope  shared.BudgetScope
+		limit  *shared.BudgetLimit
+		filter db.UsageFilter
+	}
+
+	var limits []scopedLimit
+	if !budgets.PerPlan.IsEmpty() && params.Plan != nil {
+		limits = append(limits, scopedLimit{shared.BudgetScopePlan, budgets.PerPlan, db.UsageFilter{OrgId: auth.OrgId, PlanId: params.Plan.Id}})
+	}
+	if !budgets.PerUserDay.IsEmpty() && auth.User != nil {
+		midnight := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		limits = append(limits, scopedLimit{shared.BudgetScopeUserDay, budgets.PerUserDay, db.UsageFilter{OrgId: auth.OrgId, UserId: auth.User.Id, Since: &midnight}})
+	}
+	if !budgets.PerOrgMonth.IsEmpty() {
+		monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		limits = append(limits, scopedLimit{shared.BudgetScopeOrgMonth, budgets.PerOrgMonth, db.UsageFilter{OrgId: auth.OrgId, Since: &monthStart}})
+	}
+
+	var warning *shared.BudgetStatus
+
+	for _, l := range limits {
+		totals, err := db.GetUsageTotals(l.filter)
+		if err != nil {
+			return HookResult{}, &shared.ApiError{
+				Type:   shared.ApiErrorTypeOther,
+				Status: http.StatusInternalServerError,
+				Msg:    err.Error(),
+			}
+		}
+
+		var statuses []shared.BudgetStatus
+		if l.limit.Tokens > 0 {
+			statuses = append(statuses, shared.BudgetStatus{
+				Scope: l.scope,
+				Unit:  shared.BudgetUnitTokens,
+				Used:  decimal.NewFromInt(int64(totals.InputTokens + totals.OutputTokens + req.InputTokens)),
+				Limit: decimal.NewFromInt(int64(l.limit.Tokens)),
+			})
+		}
+		if l.limit.Usd != nil {
+			statuses = append(statuses, shared.BudgetStatus{
+				Scope: l.scope,
+				Unit:  shared.BudgetUnitUsd,
+				Used:  totals.Spend.Add(estimatedCost),
+				Limit: *l.limit.Usd,
+			})
+		}
+
+		for i := range statuses {
+			status := statuses[i]
+			if status.Used.GreaterThanOrEqual(status.Limit) {
+				return HookResult{}, &shared.ApiError{
+					Type:                shared.ApiErrorTypeBudgetExceeded,
+					Status:              http.StatusForbidden,
+					Msg:                 fmt.Sprintf("Budget exceeded: this model call would use %s", status.String()),
+					BudgetExceededError: &status,
+				}
+			}
+			if status.Pct() >= budgets.GetWarnAtPct() && (warning == nil || status.Pct() > warning.Pct()) {
+				warning = &status
+			}
+		}
+	}
+
+	return HookResult{BudgetWarning: warning}, nil
+}
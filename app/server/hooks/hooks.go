@@ -30,9 +30,11 @@ const (
 )
 
 type WillSendModelRequestParams struct {
-	InputTokens  int
-	OutputTokens int
-	ModelName    shared.ModelName
+	InputTokens   int
+	OutputTokens  int
+	ModelName     shared.ModelName
+	ModelId       shared.ModelId
+	ModelProvider shared.ModelProvider
 }
 
 type DidSendModelRequestParams struct {
@@ -47,6 +49,7 @@ type DidSendModelRequestParams struct {
 	Purpose         string
 	GenerationId    string
 	PlanId          string
+	Branch          string
 	ModelStreamId   string
 	ConvoMessageId  string
 	BuildId         string
@@ -56,6 +59,11 @@ type DidSendModelRequestParams struct {
 	NoReportedUsage bool
 	SessionId       string
 
+	// set when the original model failed after all retries and the request was sent to a model in the role's error fallback chain
+	ErrorFallbackNum       int
+	ErrorFallbackFromModel shared.ModelId
+	ErrorFallbackReason    string
+
 	RequestStartedAt time.Time
 	Streaming        bool
 	StreamResult     string
@@ -160,6 +168,9 @@ type HookResult struct {
 	GetIntegratedModelsResult *GetIntegratedModelsResult
 	ApiOrgsById               map[string]*shared.Org
 	FastApplyResult           *FastApplyResult
+
+	// set by WillSendModelRequest when a budget is close to its limit
+	BudgetWarning *shared.BudgetStatus
 }
 
 type Hook func(params HookParams) (HookResult, *shared.ApiError)
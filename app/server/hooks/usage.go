@@ -0,0 +1,99 @@
+package hooks
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"plandex-server/db"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/shopspring/decimal"
+)
+
+func init() {
+	// self-hosted servers record every model call in a local usage ledger -- Plandex Cloud registers its own billing hook instead
+	if os.Getenv("IS_CLOUD") == "" {
+		RegisterHook(DidSendModelRequest, storeModelUsage)
+	}
+}
+
+func storeModelUsage(params HookParams) (HookResult, *shared.ApiError) {
+	req := params.DidSendModelRequestParams
+	auth := params.Auth
+
+	if req == nil || auth == nil {
+		return HookResult{}, nil
+	}
+
+	usage := &db.ModelUsage{
+		OrgId:        auth.OrgId,
+		Branch:       req.Branch,
+		ModelRole:    req.ModelRole,
+		ModelId:      req.ModelId,
+		ModelName:    req.ModelName,
+		Provider:     req.ModelProvider,
+		ModelPack:    req.ModelPackName,
+		Purpose:      req.Purpose,
+		InputTokens:  req.InputTokens,
+		OutputTokens: req.OutputTokens,
+		CachedTokens: req.CachedTokens,
+		SessionId:    req.SessionId,
+		StoppedEarly: req.StoppedEarly,
+		HadError:     req.HadError,
+	}
+
+	if auth.User != nil {
+		usage.UserId = &auth.User.Id
+	}
+	if req.PlanId != "" {
+		usage.PlanId = &req.PlanId
+	}
+	if !req.RequestStartedAt.IsZero() {
+		usage.LatencyMs = int(time.Since(req.RequestStartedAt).Milliseconds())
+	}
+
+	pricing, err := getModelPricing(auth.OrgId, req.ModelProvider, req.ModelId, req.ModelName)
+	if err != nil {
+		// still record the call, just without a cost
+		log.Printf("storeModelUsage - error getting model pricing: %v", err)
+	}
+	if pricing != nil {
+		usage.Cost = decimal.NewNullDecimal(pricing.Cost(req.InputTokens, req.OutputTokens, req.CachedTokens))
+	}
+
+	err = db.StoreModelUsage(usage)
+	if err != nil {
+		return HookResult{}, &shared.ApiError{
+			Type:   shared.ApiErrorTypeOther,
+			Status: http.StatusInternalServerError,
+			Msg:    err.Error(),
+		}
+	}
+
+	return HookResult{}, nil
+}
+
+// getModelPricing looks up a built-in model by id, then falls back to the org's custom models by provider and model name. It returns nil if the model has no pricing set.
+func getModelPricing(orgId string, provider shared.ModelProvider, modelId shared.ModelId, modelName shared.ModelName) (*shared.ModelPricing, error) {
+	if modelId != "" {
+		model := shared.GetAvailableModel(provider, modelId)
+		if model != nil {
+			return model.Pricing, nil
+		}
+	}
+
+	customModels, err := db.ListCustomModels(orgId)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, model := range customModels {
+		if model.Provider == provider && model.ModelName == modelName {
+			return model.ToApi().Pricing, nil
+		}
+	}
+
+	return nil, nil
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"log"
 	"os"
+	"plandex-server/eval"
 	"plandex-server/routes"
 	"plandex-server/setup"
 
@@ -10,6 +11,10 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "eval" {
+		os.Exit(eval.Main(os.Args[2:]))
+	}
+
 	// Configure the default logger to include milliseconds in timestamps
 	log.SetFlags(log.LstdFlags | log.Lmicroseconds | log.Lshortfile)
 
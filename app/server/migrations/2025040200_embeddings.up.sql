@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS embeddings (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  embedder VARCHAR(255) NOT NULL,
+  path TEXT NOT NULL,
+  sha VARCHAR(64) NOT NULL,
+  vector BYTEA NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX embeddings_project_embedder_sha_idx ON embeddings(project_id, embedder, sha);
+CREATE INDEX embeddings_project_embedder_path_idx ON embeddings(project_id, embedder, path);
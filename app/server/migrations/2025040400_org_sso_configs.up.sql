@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS org_sso_configs (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  domain VARCHAR(255) NOT NULL,
+  issuer_url TEXT NOT NULL,
+  client_id VARCHAR(255) NOT NULL,
+  client_secret TEXT NOT NULL DEFAULT '',
+  scopes VARCHAR(255) NOT NULL,
+  role_claim VARCHAR(255) NOT NULL DEFAULT '',
+  role_mapping JSON NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+CREATE TRIGGER update_org_sso_configs_modtime BEFORE UPDATE ON org_sso_configs FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE UNIQUE INDEX org_sso_configs_org_idx ON org_sso_configs(org_id);
+CREATE UNIQUE INDEX org_sso_configs_domain_idx ON org_sso_configs(domain);
@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'manage_plan_templates';
+
+DROP TABLE IF EXISTS plan_templates;
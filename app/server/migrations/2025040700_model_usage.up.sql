@@ -0,0 +1,44 @@
+CREATE TABLE IF NOT EXISTS model_usage (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  user_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  plan_id UUID REFERENCES plans(id) ON DELETE SET NULL,
+  branch VARCHAR(255) NOT NULL DEFAULT '',
+  model_role VARCHAR(255) NOT NULL,
+  model_id VARCHAR(255) NOT NULL DEFAULT '',
+  model_name VARCHAR(255) NOT NULL,
+  provider VARCHAR(255) NOT NULL,
+  model_pack VARCHAR(255) NOT NULL DEFAULT '',
+  purpose VARCHAR(255) NOT NULL DEFAULT '',
+  input_tokens INTEGER NOT NULL,
+  output_tokens INTEGER NOT NULL,
+  cached_tokens INTEGER NOT NULL DEFAULT 0,
+  latency_ms INTEGER NOT NULL DEFAULT 0,
+  cost NUMERIC(20, 10),
+  session_id VARCHAR(255) NOT NULL DEFAULT '',
+  stopped_early BOOLEAN NOT NULL DEFAULT FALSE,
+  had_error BOOLEAN NOT NULL DEFAULT FALSE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX model_usage_org_created_at_idx ON model_usage(org_id, created_at);
+CREATE INDEX model_usage_plan_idx ON model_usage(plan_id);
+
+ALTER TABLE custom_models ADD COLUMN input_price_per_million NUMERIC(20, 10);
+ALTER TABLE custom_models ADD COLUMN output_price_per_million NUMERIC(20, 10);
+ALTER TABLE custom_models ADD COLUMN cached_input_price_per_million NUMERIC(20, 10);
+
+INSERT INTO permissions (name, description) VALUES
+  ('view_org_usage', 'View model usage and spend for all users in the org');
+
+INSERT INTO org_roles_permissions (org_role_id, permission_id)
+SELECT
+    r.id AS org_role_id,
+    p.id AS permission_id
+FROM
+    org_roles r,
+    permissions p
+WHERE
+    r.org_id IS NULL
+    AND r.name IN ('owner', 'admin')
+    AND p.name = 'view_org_usage';
@@ -0,0 +1,2 @@
+ALTER TABLE custom_models DROP COLUMN IF EXISTS reserved_output_tokens;
+ALTER TABLE custom_models DROP COLUMN IF EXISTS max_output_tokens;
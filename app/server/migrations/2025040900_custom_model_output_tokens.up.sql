@@ -0,0 +1,2 @@
+ALTER TABLE custom_models ADD COLUMN IF NOT EXISTS max_output_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE custom_models ADD COLUMN IF NOT EXISTS reserved_output_tokens INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,21 @@
+DROP TABLE IF EXISTS default_plan_settings;
+DROP TABLE IF EXISTS custom_models;
+DROP TABLE IF EXISTS model_sets;
+DROP TABLE IF EXISTS lockable_plan_ids;
+DROP TABLE IF EXISTS repo_locks;
+DROP TABLE IF EXISTS model_streams;
+DROP TABLE IF EXISTS plan_builds;
+DROP TABLE IF EXISTS convo_summaries;
+DROP TABLE IF EXISTS branches;
+DROP TABLE IF EXISTS plans;
+DROP TABLE IF EXISTS projects;
+DROP TABLE IF EXISTS sign_in_codes;
+DROP TABLE IF EXISTS email_verifications;
+DROP TABLE IF EXISTS auth_tokens;
+DROP TABLE IF EXISTS org_roles_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS invites;
+DROP TABLE IF EXISTS orgs_users;
+DROP TABLE IF EXISTS org_roles;
+DROP TABLE IF EXISTS orgs;
+DROP TABLE IF EXISTS users;
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS embeddings (
+  id UUID PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', 1 + (abs(random()) % 4), 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  embedder VARCHAR(255) NOT NULL,
+  path TEXT NOT NULL,
+  sha VARCHAR(64) NOT NULL,
+  vector BLOB NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+
+CREATE UNIQUE INDEX embeddings_project_embedder_sha_idx ON embeddings(project_id, embedder, sha);
+CREATE INDEX embeddings_project_embedder_path_idx ON embeddings(project_id, embedder, path);
@@ -0,0 +1,20 @@
+CREATE TABLE IF NOT EXISTS org_sso_configs (
+  id UUID PRIMARY KEY DEFAULT (lower(hex(randomblob(4))) || '-' || lower(hex(randomblob(2))) || '-4' || substr(lower(hex(randomblob(2))), 2) || '-' || substr('89ab', 1 + (abs(random()) % 4), 1) || substr(lower(hex(randomblob(2))), 2) || '-' || lower(hex(randomblob(6)))),
+  org_id UUID NOT NULL REFERENCES orgs(id) ON DELETE CASCADE,
+  domain VARCHAR(255) NOT NULL,
+  issuer_url TEXT NOT NULL,
+  client_id VARCHAR(255) NOT NULL,
+  client_secret TEXT NOT NULL DEFAULT '',
+  scopes VARCHAR(255) NOT NULL,
+  role_claim VARCHAR(255) NOT NULL DEFAULT '',
+  role_mapping TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+  updated_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now'))
+);
+CREATE TRIGGER update_org_sso_configs_modtime AFTER UPDATE ON org_sso_configs FOR EACH ROW WHEN NEW.updated_at = OLD.updated_at
+BEGIN
+  UPDATE org_sso_configs SET updated_at = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now') WHERE id = NEW.id;
+END;
+
+CREATE UNIQUE INDEX org_sso_configs_org_idx ON org_sso_configs(org_id);
+CREATE UNIQUE INDEX org_sso_configs_domain_idx ON org_sso_configs(domain);
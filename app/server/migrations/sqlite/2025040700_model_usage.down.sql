@@ -0,0 +1,7 @@
+DELETE FROM permissions WHERE name = 'view_org_usage';
+
+ALTER TABLE custom_models DROP COLUMN cached_input_price_per_million;
+ALTER TABLE custom_models DROP COLUMN output_price_per_million;
+ALTER TABLE custom_models DROP COLUMN input_price_per_million;
+
+DROP TABLE IF EXISTS model_usage;
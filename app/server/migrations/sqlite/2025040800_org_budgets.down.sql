@@ -0,0 +1,3 @@
+DELETE FROM permissions WHERE name = 'manage_budgets';
+
+DROP TABLE IF EXISTS org_budgets;
@@ -0,0 +1,2 @@
+ALTER TABLE custom_models ADD COLUMN max_output_tokens INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE custom_models ADD COLUMN reserved_output_tokens INTEGER NOT NULL DEFAULT 0;
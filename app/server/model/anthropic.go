@@ -0,0 +1,586 @@
+package model
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"plandex-server/types"
+	"strings"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// Native Anthropic Messages API support. Requests are translated from the OpenAI-style ExtendedChatCompletionRequest, and streamed events are translated back into ExtendedChatCompletionStreamResponse chunks so that processChatCompletionStream and the tell/build pipelines work unchanged.
+
+const AnthropicApiVersion = "2023-06-01"
+
+const anthropicDefaultMaxTokens = 8192
+
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	Url       string `json:"url,omitempty"`
+}
+
+type anthropicContentBlock struct {
+	Type         string                  `json:"type"`
+	Text         string                  `json:"text,omitempty"`
+	Source       *anthropicImageSource   `json:"source,omitempty"`
+	CacheControl *types.CacheControlSpec `json:"cache_control,omitempty"`
+
+	// tool_use blocks
+	Id    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	// thinking blocks
+	Thinking string `json:"thinking,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string                  `json:"role"`
+	Content []anthropicContentBlock `json:"content"`
+}
+
+type anthropicTool struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	InputSchema any    `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type anthropicRequest struct {
+	Model         string                  `json:"model"`
+	System        []anthropicContentBlock `json:"system,omitempty"`
+	Messages      []anthropicMessage      `json:"messages"`
+	MaxTokens     int                     `json:"max_tokens"`
+	Temperature   *float32                `json:"temperature,omitempty"`
+	TopP          *float32                `json:"top_p,omitempty"`
+	StopSequences []string                `json:"stop_sequences,omitempty"`
+	Stream        bool                    `json:"stream,omitempty"`
+	Tools         []anthropicTool         `json:"tools,omitempty"`
+	ToolChoice    *anthropicToolChoice    `json:"tool_choice,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens"`
+}
+
+type anthropicResponse struct {
+	Id         string                  `json:"id"`
+	Type       string                  `json:"type"`
+	Role       string                  `json:"role"`
+	Model      string                  `json:"model"`
+	Content    []anthropicContentBlock `json:"content"`
+	StopReason string                  `json:"stop_reason"`
+	Usage      anthropicUsage          `json:"usage"`
+}
+
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type anthropicStreamDelta struct {
+	Type        string `json:"type"`
+	Text        string `json:"text,omitempty"`
+	PartialJson string `json:"partial_json,omitempty"`
+	Thinking    string `json:"thinking,omitempty"`
+	StopReason  string `json:"stop_reason,omitempty"`
+}
+
+type anthropicStreamEvent struct {
+	Type         string                 `json:"type"`
+	Index        int                    `json:"index"`
+	Message      *anthropicResponse     `json:"message,omitempty"`
+	ContentBlock *anthropicContentBlock `json:"content_block,omitempty"`
+	Delta        *anthropicStreamDelta  `json:"delta,omitempty"`
+	Usage        *anthropicUsage        `json:"usage,omitempty"`
+	Error        *anthropicError        `json:"error,omitempty"`
+}
+
+// anthropicStreamReader reads Messages API server-sent events and converts them to OpenAI-style stream chunks
+type anthropicStreamReader struct {
+	reader   *bufio.Reader
+	response *http.Response
+
+	id    string
+	model string
+	usage anthropicUsage
+
+	// maps content block indexes to tool call indexes
+	toolCallIdxByBlock map[int]int
+	finished           bool
+}
+
+func createAnthropicMessage(
+	modelConfig *shared.ModelRoleConfig,
+	client ClientInfo,
+	baseUrl string,
+	ctx context.Context,
+	extendedReq types.ExtendedChatCompletionRequest,
+) (openai.ChatCompletionResponse, error) {
+	anthropicReq := toAnthropicRequest(modelConfig, extendedReq)
+	anthropicReq.Stream = false
+
+	jsonBody, err := json.Marshal(anthropicReq)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", baseUrl+"/messages", bytes.NewReader(jsonBody))
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	addAnthropicHeaders(req, client)
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return openai.ChatCompletionResponse{}, fmt.Errorf("request failed: status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var anthropicRes anthropicResponse
+	err = json.Unmarshal(body, &anthropicRes)
+	if err != nil {
+		return openai.ChatCompletionResponse{}, err
+	}
+
+	return anthropicRes.toOpenAI(), nil
+}
+
+func createAnthropicMessageStream(
+	modelConfig *shared.ModelRoleConfig,
+	client ClientInfo,
+	baseUrl string,
+	ctx context.Context,
+	extendedReq types.ExtendedChatCompletionRequest,
+) (*ExtendedChatCompletionStream, error) {
+	anthropicReq := toAnthropicRequest(modelConfig, extendedReq)
+	anthropicReq.Stream = true
+
+	jsonBody, err := json.Marshal(anthropicReq)
+	if err != nil {
+		return nil, fmt.Errorf("error marshaling request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", baseUrl+"/messages", bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	addAnthropicHeaders(req, client)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set("Cache-Control", "no-cache")
+	req.Header.Set("Connection", "keep-alive")
+
+	resp, err := httpClient.Do(req) //nolint:bodyclose // body is closed in stream.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("error reading error response: %w", err)
+		}
+		return nil, fmt.Errorf("streaming request failed: status code: %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	return &ExtendedChatCompletionStream{
+		anthropicReader: &anthropicStreamReader{
+			reader:             bufio.NewReader(resp.Body),
+			response:           resp,
+			toolCallIdxByBlock: map[int]int{},
+		},
+		ctx: ctx,
+	}, nil
+}
+
+func addAnthropicHeaders(req *http.Request, client ClientInfo) {
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("x-api-key", client.ApiKey)
+	req.Header.Set("anthropic-version", AnthropicApiVersion)
+}
+
+func toAnthropicRequest(modelConfig *shared.ModelRoleConfig, req types.ExtendedChatCompletionRequest) *anthropicRequest {
+	res := &anthropicRequest{
+		Model:         string(req.Model),
+		MaxTokens:     getAnthropicMaxTokens(modelConfig, req),
+		StopSequences: req.Stop,
+		Stream:        req.Stream,
+	}
+
+	if req.Temperature != 0 {
+		temperature := req.Temperature
+		res.Temperature = &temperature
+	}
+	if req.TopP != 0 && req.TopP != 1 {
+		topP := req.TopP
+		res.TopP = &topP
+	}
+
+	for _, msg := range req.Messages {
+		blocks := toAnthropicBlocks(msg.Content)
+		if len(blocks) == 0 {
+			continue
+		}
+
+		if msg.Role == openai.ChatMessageRoleSystem {
+			res.System = append(res.System, blocks...)
+			continue
+		}
+
+		role := msg.Role
+		if role != openai.ChatMessageRoleAssistant {
+			role = openai.ChatMessageRoleUser
+		}
+
+		// the Messages API expects alternating turns, so merge consecutive messages with the same role
+		if len(res.Messages) > 0 && res.Messages[len(res.Messages)-1].Role == role {
+			last := &res.Messages[len(res.Messages)-1]
+			last.Content = append(last.Content, blocks...)
+			continue
+		}
+
+		res.Messages = append(res.Messages, anthropicMessage{
+			Role:    role,
+			Content: blocks,
+		})
+	}
+
+	for _, tool := range req.Tools {
+		if tool.Function == nil {
+			continue
+		}
+		schema := tool.Function.Parameters
+		if schema == nil {
+			schema = map[string]any{"type": "object"}
+		}
+		res.Tools = append(res.Tools, anthropicTool{
+			Name:        tool.Function.Name,
+			Description: tool.Function.Description,
+			InputSchema: schema,
+		})
+	}
+
+	if len(res.Tools) > 0 {
+		res.ToolChoice = toAnthropicToolChoice(req.ToolChoice)
+	}
+
+	return res
+}
+
+func toAnthropicBlocks(parts []types.ExtendedChatMessagePart) []anthropicContentBlock {
+	var blocks []anthropicContentBlock
+	for _, part := range parts {
+		switch part.Type {
+		case openai.ChatMessagePartTypeText:
+			// the Messages API rejects empty text blocks
+			if part.Text == "" {
+				continue
+			}
+			blocks = append(blocks, anthropicContentBlock{
+				Type:         "text",
+				Text:         part.Text,
+				CacheControl: part.CacheControl,
+			})
+		case openai.ChatMessagePartTypeImageURL:
+			if part.ImageURL == nil {
+				continue
+			}
+			blocks = append(blocks, anthropicContentBlock{
+				Type:         "image",
+				Source:       toAnthropicImageSource(part.ImageURL.URL),
+				CacheControl: part.CacheControl,
+			})
+		}
+	}
+	return blocks
+}
+
+// image parts are sent as data urls ("data:image/png;base64,...") -- anything else is passed through as a url source
+func toAnthropicImageSource(url string) *anthropicImageSource {
+	if strings.HasPrefix(url, "data:") {
+		header, data, found := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+		if found {
+			mediaType := strings.TrimSuffix(header, ";base64")
+			return &anthropicImageSource{
+				Type:      "base64",
+				MediaType: mediaType,
+				Data:      data,
+			}
+		}
+	}
+
+	return &anthropicImageSource{
+		Type: "url",
+		Url:  url,
+	}
+}
+
+func toAnthropicToolChoice(toolChoice any) *anthropicToolChoice {
+	switch tc := toolChoice.(type) {
+	case string:
+		switch tc {
+		case "required":
+			return &anthropicToolChoice{Type: "any"}
+		case "none":
+			return &anthropicToolChoice{Type: "none"}
+		}
+	case *openai.ToolChoice:
+		if tc != nil && tc.Function.Name != "" {
+			return &anthropicToolChoice{Type: "tool", Name: tc.Function.Name}
+		}
+	case openai.ToolChoice:
+		if tc.Function.Name != "" {
+			return &anthropicToolChoice{Type: "tool", Name: tc.Function.Name}
+		}
+	}
+	return &anthropicToolChoice{Type: "auto"}
+}
+
+// max_tokens is required by the Messages API, and input + max_tokens must fit in the context window
+func getAnthropicMaxTokens(modelConfig *shared.ModelRoleConfig, req types.ExtendedChatCompletionRequest) int {
+	if req.MaxCompletionTokens > 0 {
+		return req.MaxCompletionTokens
+	}
+	if req.MaxTokens > 0 {
+		return req.MaxTokens
+	}
+
+	maxTokens := modelConfig.BaseModelConfig.MaxOutputTokens
+	if maxTokens == 0 {
+		return anthropicDefaultMaxTokens
+	}
+
+	if modelConfig.BaseModelConfig.MaxTokens > 0 {
+		available := modelConfig.BaseModelConfig.MaxTokens - GetMessagesTokenEstimate(req.Messages...) - TokensPerRequest
+		if available < maxTokens {
+			maxTokens = max(available, modelConfig.GetReservedOutputTokens())
+		}
+	}
+
+	return maxTokens
+}
+
+func (res *anthropicResponse) toOpenAI() openai.ChatCompletionResponse {
+	msg := openai.ChatCompletionMessage{
+		Role: openai.ChatMessageRoleAssistant,
+	}
+
+	var content strings.Builder
+	for _, block := range res.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "tool_use":
+			msg.ToolCalls = append(msg.ToolCalls, openai.ToolCall{
+				ID:   block.Id,
+				Type: openai.ToolTypeFunction,
+				Function: openai.FunctionCall{
+					Name:      block.Name,
+					Arguments: string(block.Input),
+				},
+			})
+		}
+	}
+	msg.Content = content.String()
+
+	return openai.ChatCompletionResponse{
+		ID:     res.Id,
+		Object: "chat.completion",
+		Model:  res.Model,
+		Choices: []openai.ChatCompletionChoice{
+			{
+				Index:        0,
+				Message:      msg,
+				FinishReason: toOpenAIFinishReason(res.StopReason),
+			},
+		},
+		Usage: *res.Usage.toOpenAI(),
+	}
+}
+
+func (u anthropicUsage) toOpenAI() *openai.Usage {
+	promptTokens := u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens
+	return &openai.Usage{
+		PromptTokens:     promptTokens,
+		CompletionTokens: u.OutputTokens,
+		TotalTokens:      promptTokens + u.OutputTokens,
+		PromptTokensDetails: &openai.PromptTokensDetails{
+			CachedTokens: u.CacheReadInputTokens,
+		},
+	}
+}
+
+func toOpenAIFinishReason(stopReason string) openai.FinishReason {
+	switch stopReason {
+	case "":
+		return ""
+	case "max_tokens":
+		return openai.FinishReasonLength
+	case "tool_use":
+		return openai.FinishReasonToolCalls
+	default:
+		// end_turn, stop_sequence, pause_turn, refusal
+		return openai.FinishReasonStop
+	}
+}
+
+// Recv reads events until one can be converted to a stream chunk
+func (stream *anthropicStreamReader) Recv() (*types.ExtendedChatCompletionStreamResponse, error) {
+	for {
+		if stream.finished {
+			return nil, io.EOF
+		}
+
+		line, err := stream.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+
+		line = strings.TrimSpace(line)
+
+		// event type is duplicated in the data payload, so we only need the data lines
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+
+		var event anthropicStreamEvent
+		err = json.Unmarshal([]byte(data), &event)
+		if err != nil {
+			log.Printf("anthropicStreamReader - error unmarshalling event: %v\n", err)
+			continue
+		}
+
+		switch event.Type {
+		case "message_start":
+			if event.Message != nil {
+				stream.id = event.Message.Id
+				stream.model = event.Message.Model
+				stream.usage = event.Message.Usage
+			}
+
+		case "content_block_start":
+			if event.ContentBlock != nil && event.ContentBlock.Type == "tool_use" {
+				toolCallIdx := len(stream.toolCallIdxByBlock)
+				stream.toolCallIdxByBlock[event.Index] = toolCallIdx
+				return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{
+					ToolCalls: []openai.ToolCall{
+						{
+							Index: &toolCallIdx,
+							ID:    event.ContentBlock.Id,
+							Type:  openai.ToolTypeFunction,
+							Function: openai.FunctionCall{
+								Name: event.ContentBlock.Name,
+							},
+						},
+					},
+				}, ""), nil
+			}
+
+		case "content_block_delta":
+			if event.Delta == nil {
+				continue
+			}
+			switch event.Delta.Type {
+			case "text_delta":
+				return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{
+					Content: event.Delta.Text,
+				}, ""), nil
+			case "thinking_delta":
+				return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{
+					Reasoning: event.Delta.Thinking,
+				}, ""), nil
+			case "input_json_delta":
+				toolCallIdx := stream.toolCallIdxByBlock[event.Index]
+				return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{
+					ToolCalls: []openai.ToolCall{
+						{
+							Index: &toolCallIdx,
+							Type:  openai.ToolTypeFunction,
+							Function: openai.FunctionCall{
+								Arguments: event.Delta.PartialJson,
+							},
+						},
+					},
+				}, ""), nil
+			}
+
+		case "message_delta":
+			if event.Usage != nil {
+				stream.usage.OutputTokens = event.Usage.OutputTokens
+			}
+			if event.Delta != nil && event.Delta.StopReason != "" {
+				return stream.chunk(types.ExtendedChatCompletionStreamChoiceDelta{}, toOpenAIFinishReason(event.Delta.StopReason)), nil
+			}
+
+		case "message_stop":
+			stream.finished = true
+			return &types.ExtendedChatCompletionStreamResponse{
+				ID:      stream.id,
+				Object:  "chat.completion.chunk",
+				Model:   stream.model,
+				Choices: []types.ExtendedChatCompletionStreamChoice{},
+				Usage:   stream.usage.toOpenAI(),
+			}, nil
+
+		case "error":
+			msg := "unknown error"
+			if event.Error != nil {
+				msg = fmt.Sprintf("%s: %s", event.Error.Type, event.Error.Message)
+			}
+			return nil, fmt.Errorf("anthropic stream error: %s", msg)
+		}
+
+		// ping, content_block_stop, and events without content are skipped
+	}
+}
+
+func (stream *anthropicStreamReader) chunk(delta types.ExtendedChatCompletionStreamChoiceDelta, finishReason openai.FinishReason) *types.ExtendedChatCompletionStreamResponse {
+	return &types.ExtendedChatCompletionStreamResponse{
+		ID:     stream.id,
+		Object: "chat.completion.chunk",
+		Model:  stream.model,
+		Choices: []types.ExtendedChatCompletionStreamChoice{
+			{
+				Index:        0,
+				Delta:        delta,
+				FinishReason: finishReason,
+			},
+		},
+	}
+}
+
+func (stream *anthropicStreamReader) Close() error {
+	if stream.response != nil {
+		return stream.response.Body.Close()
+	}
+	return nil
+}
@@ -0,0 +1,254 @@
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"plandex-server/types"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// recorded from a Messages API streaming response
+const anthropicTextStream = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_01","type":"message","role":"assistant","model":"claude-3-7-sonnet-20250219","content":[],"stop_reason":null,"usage":{"input_tokens":25,"cache_creation_input_tokens":0,"cache_read_input_tokens":100,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}
+
+event: ping
+data: {"type": "ping"}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":", world"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"end_turn","stop_sequence":null},"usage":{"output_tokens":12}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+const anthropicToolStream = `event: message_start
+data: {"type":"message_start","message":{"id":"msg_02","type":"message","role":"assistant","model":"claude-3-7-sonnet-20250219","content":[],"stop_reason":null,"usage":{"input_tokens":40,"output_tokens":1}}}
+
+event: content_block_start
+data: {"type":"content_block_start","index":0,"content_block":{"type":"tool_use","id":"toolu_01","name":"commitMsg","input":{}}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"{\"msg\": "}}
+
+event: content_block_delta
+data: {"type":"content_block_delta","index":0,"delta":{"type":"input_json_delta","partial_json":"\"Fix bug\"}"}}
+
+event: content_block_stop
+data: {"type":"content_block_stop","index":0}
+
+event: message_delta
+data: {"type":"message_delta","delta":{"stop_reason":"tool_use","stop_sequence":null},"usage":{"output_tokens":8}}
+
+event: message_stop
+data: {"type":"message_stop"}
+
+`
+
+func newAnthropicStub(t *testing.T, stream string, onReq func(req anthropicRequest, r *http.Request)) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/messages" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			t.Fatalf("error reading request body: %v", err)
+		}
+
+		var req anthropicRequest
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Fatalf("error unmarshalling request: %v", err)
+		}
+
+		if onReq != nil {
+			onReq(req, r)
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, stream)
+	}))
+}
+
+func anthropicTestConfig(baseUrl string) *shared.ModelRoleConfig {
+	return &shared.ModelRoleConfig{
+		BaseModelConfig: shared.BaseModelConfig{
+			Provider:             shared.ModelProviderAnthropic,
+			ModelName:            "claude-3-7-sonnet-latest",
+			BaseUrl:              baseUrl,
+			ApiKeyEnvVar:         shared.AnthropicApiKeyEnvVar,
+			MaxTokens:            200000,
+			MaxOutputTokens:      64000,
+			ReservedOutputTokens: 20000,
+		},
+	}
+}
+
+func TestAnthropicStreamText(t *testing.T) {
+	server := newAnthropicStub(t, anthropicTextStream, func(req anthropicRequest, r *http.Request) {
+		if r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("expected x-api-key header, got %q", r.Header.Get("x-api-key"))
+		}
+		if r.Header.Get("anthropic-version") != AnthropicApiVersion {
+			t.Errorf("expected anthropic-version header, got %q", r.Header.Get("anthropic-version"))
+		}
+		if !req.Stream {
+			t.Error("expected stream to be true")
+		}
+		if len(req.System) != 1 || req.System[0].CacheControl == nil {
+			t.Errorf("expected one system block with cache control, got %+v", req.System)
+		}
+		if len(req.Messages) != 1 || req.Messages[0].Role != openai.ChatMessageRoleUser || len(req.Messages[0].Content) != 2 {
+			t.Errorf("expected consecutive user messages to be merged, got %+v", req.Messages)
+		}
+		if req.MaxTokens == 0 {
+			t.Error("expected max_tokens to be set")
+		}
+	})
+	defer server.Close()
+
+	modelConfig := anthropicTestConfig(server.URL)
+	client := ClientInfo{ApiKey: "test-key"}
+
+	req := types.ExtendedChatCompletionRequest{
+		Model: modelConfig.BaseModelConfig.ModelName,
+		Messages: []types.ExtendedChatMessage{
+			{
+				Role: openai.ChatMessageRoleSystem,
+				Content: []types.ExtendedChatMessagePart{
+					{
+						Type:         openai.ChatMessagePartTypeText,
+						Text:         "You are a helpful assistant.",
+						CacheControl: &types.CacheControlSpec{Type: types.CacheControlTypeEphemeral},
+					},
+				},
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "Say hello."}},
+			},
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "To the world."}},
+			},
+		},
+		Stream: true,
+	}
+
+	var chunks []string
+	res, err := processChatCompletionStream(modelConfig, client, server.URL, context.Background(), req, func(chunk, buffer string) bool {
+		chunks = append(chunks, chunk)
+		return false
+	}, time.Now())
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.Content != "Hello, world" {
+		t.Errorf("expected content %q, got %q", "Hello, world", res.Content)
+	}
+
+	if res.GenerationId != "msg_01" {
+		t.Errorf("expected generation id %q, got %q", "msg_01", res.GenerationId)
+	}
+
+	if res.Usage == nil {
+		t.Fatal("expected usage")
+	}
+	if res.Usage.PromptTokens != 125 || res.Usage.CompletionTokens != 12 || res.Usage.PromptTokensDetails.CachedTokens != 100 {
+		t.Errorf("unexpected usage: %+v", res.Usage)
+	}
+
+	if len(chunks) != 2 {
+		t.Errorf("expected 2 content chunks, got %d", len(chunks))
+	}
+}
+
+func TestAnthropicStreamToolUse(t *testing.T) {
+	server := newAnthropicStub(t, anthropicToolStream, func(req anthropicRequest, r *http.Request) {
+		if len(req.Tools) != 1 || req.Tools[0].Name != "commitMsg" {
+			t.Errorf("expected commitMsg tool, got %+v", req.Tools)
+		}
+		if req.ToolChoice == nil || req.ToolChoice.Type != "tool" || req.ToolChoice.Name != "commitMsg" {
+			t.Errorf("expected forced tool choice, got %+v", req.ToolChoice)
+		}
+	})
+	defer server.Close()
+
+	modelConfig := anthropicTestConfig(server.URL)
+	client := ClientInfo{ApiKey: "test-key"}
+
+	req := types.ExtendedChatCompletionRequest{
+		Model: modelConfig.BaseModelConfig.ModelName,
+		Messages: []types.ExtendedChatMessage{
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: "Write a commit message."}},
+			},
+		},
+		Tools: []openai.Tool{
+			{
+				Type: openai.ToolTypeFunction,
+				Function: &openai.FunctionDefinition{
+					Name: "commitMsg",
+					Parameters: map[string]any{
+						"type":       "object",
+						"properties": map[string]any{"msg": map[string]any{"type": "string"}},
+					},
+				},
+			},
+		},
+		ToolChoice: &openai.ToolChoice{
+			Type:     openai.ToolTypeFunction,
+			Function: openai.ToolFunction{Name: "commitMsg"},
+		},
+		Stream: true,
+	}
+
+	res, err := processChatCompletionStream(modelConfig, client, server.URL, context.Background(), req, nil, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if res.Content != `{"msg": "Fix bug"}` {
+		t.Errorf("unexpected tool call arguments: %q", res.Content)
+	}
+
+	if res.Usage == nil || res.Usage.CompletionTokens != 8 {
+		t.Errorf("unexpected usage: %+v", res.Usage)
+	}
+}
+
+func TestToAnthropicImageSource(t *testing.T) {
+	source := toAnthropicImageSource("data:image/png;base64,aGVsbG8=")
+	if source.Type != "base64" || source.MediaType != "image/png" || source.Data != "aGVsbG8=" {
+		t.Errorf("unexpected data url source: %+v", source)
+	}
+
+	source = toAnthropicImageSource("https://example.com/image.png")
+	if source.Type != "url" || source.Url != "https://example.com/image.png" {
+		t.Errorf("unexpected url source: %+v", source)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -72,11 +73,14 @@ func newClient(apiKey, endpoint, orgId string) ClientInfo {
 	}
 }
 
-// ExtendedChatCompletionStream can wrap either a native OpenAI stream or our custom implementation
+// ExtendedChatCompletionStream can wrap either a native OpenAI stream, our custom implementation, a native Anthropic stream, or a replay from the response cache
 type ExtendedChatCompletionStream struct {
-	openaiStream *openai.ChatCompletionStream
-	customReader *StreamReader[types.ExtendedChatCompletionStreamResponse]
-	ctx          context.Context
+	openaiStream      *openai.ChatCompletionStream
+	customReader      *StreamReader[types.ExtendedChatCompletionStreamResponse]
+	anthropicReader   *anthropicStreamReader
+	cacheReplayReader *responseCacheReplayReader
+	cacheRecorder     *responseCacheRecorder
+	ctx               context.Context
 }
 
 // StreamReader handles the SSE stream reading
@@ -154,6 +158,11 @@ func createChatCompletionExtended(
 	ctx context.Context,
 	extendedReq types.ExtendedChatCompletionRequest,
 ) (openai.ChatCompletionResponse, error) {
+	if modelConfig.BaseModelConfig.Provider == shared.ModelProviderAnthropic {
+		log.Println("Creating chat completion with direct Anthropic provider request")
+		return createAnthropicMessage(modelConfig, client, baseUrl, ctx, extendedReq)
+	}
+
 	var openaiReq *types.ExtendedOpenAIChatCompletionRequest
 	if modelConfig.BaseModelConfig.Provider == shared.ModelProviderOpenAI {
 		log.Println("Creating chat completion with direct OpenAI provider request")
@@ -215,6 +224,23 @@ func createChatCompletionStreamExtended(
 	ctx context.Context,
 	extendedReq types.ExtendedChatCompletionRequest,
 ) (*ExtendedChatCompletionStream, error) {
+	return withResponseCache(modelConfig, baseUrl, ctx, extendedReq, func() (*ExtendedChatCompletionStream, error) {
+		return createProviderChatCompletionStream(modelConfig, client, baseUrl, ctx, extendedReq)
+	})
+}
+
+func createProviderChatCompletionStream(
+	modelConfig *shared.ModelRoleConfig,
+	client ClientInfo,
+	baseUrl string,
+	ctx context.Context,
+	extendedReq types.ExtendedChatCompletionRequest,
+) (*ExtendedChatCompletionStream, error) {
+	if modelConfig.BaseModelConfig.Provider == shared.ModelProviderAnthropic {
+		log.Println("Creating chat completion stream with direct Anthropic provider request")
+		return createAnthropicMessageStream(modelConfig, client, baseUrl, ctx, extendedReq)
+	}
+
 	var openaiReq *types.ExtendedOpenAIChatCompletionRequest
 	if modelConfig.BaseModelConfig.Provider == shared.ModelProviderOpenAI {
 		openaiReq = extendedReq.ToOpenAI()
@@ -364,32 +390,68 @@ func (stream *ExtendedChatCompletionStream) Recv() (*types.ExtendedChatCompletio
 	case <-stream.ctx.Done():
 		return nil, stream.ctx.Err()
 	default:
-		if stream.openaiStream != nil {
-			bytes, err := stream.openaiStream.RecvRaw()
-			if err != nil {
-				return nil, err
-			}
+		if stream.cacheReplayReader != nil {
+			return stream.cacheReplayReader.Recv()
+		}
+
+		response, err := stream.recvProvider()
 
-			var response types.ExtendedChatCompletionStreamResponse
-			err = json.Unmarshal(bytes, &response)
-			if err != nil {
-				return nil, err
+		if stream.cacheRecorder != nil {
+			if err == nil {
+				stream.cacheRecorder.add(response)
+			} else if err != io.EOF {
+				stream.cacheRecorder.failed = true
 			}
-			return &response, nil
 		}
-		return stream.customReader.Recv()
+
+		return response, err
+	}
+}
+
+func (stream *ExtendedChatCompletionStream) recvProvider() (*types.ExtendedChatCompletionStreamResponse, error) {
+	if stream.openaiStream != nil {
+		bytes, err := stream.openaiStream.RecvRaw()
+		if err != nil {
+			return nil, err
+		}
+
+		var response types.ExtendedChatCompletionStreamResponse
+		err = json.Unmarshal(bytes, &response)
+		if err != nil {
+			return nil, err
+		}
+		return &response, nil
+	}
+	if stream.anthropicReader != nil {
+		return stream.anthropicReader.Recv()
 	}
+	return stream.customReader.Recv()
 }
 
 // Close the response body
 func (stream *ExtendedChatCompletionStream) Close() error {
+	if stream.cacheReplayReader != nil {
+		return nil
+	}
+	if stream.cacheRecorder != nil {
+		// the usage chunk (if any) follows the finish reason, so the response is stored once the stream is closed rather than as soon as it finishes
+		stream.cacheRecorder.store()
+	}
 	if stream.openaiStream != nil {
 		return stream.openaiStream.Close()
 	}
+	if stream.anthropicReader != nil {
+		return stream.anthropicReader.Close()
+	}
 	return stream.customReader.Close()
 }
 
 func isNonRetriableErr(err error) bool {
+	if errors.Is(err, ErrResponseCacheMiss) {
+		log.Println("Response cache miss in replay mode - no retry")
+		return true
+	}
+
 	errStr := err.Error()
 
 	// we don't want to retry on the errors below
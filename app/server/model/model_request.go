@@ -2,12 +2,18 @@ package model
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"plandex-server/db"
 	"plandex-server/hooks"
 	"plandex-server/types"
 	shared "plandex-shared"
+	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/sashabaranov/go-openai"
@@ -33,12 +39,19 @@ type ModelRequestParams struct {
 	BuildId        string
 	ModelPackName  string
 	SessionId      string
+	Branch         string
 
 	BeforeReq func()
 	AfterReq  func()
 
 	OnStream func(string, string) bool
 
+	// called when the request fails after all retries and is switched to the next model in the error fallback chain
+	OnErrorFallback func(info shared.ModelFallbackInfo)
+
+	// called when a spend budget is close to its limit before the request is sent
+	OnBudgetWarning func(status shared.BudgetStatus)
+
 	WillCacheNumTokens int
 }
 
@@ -61,6 +74,7 @@ func ModelRequest(
 	modelPackName := params.ModelPackName
 	purpose := params.Purpose
 	sessionId := params.SessionId
+	branch := params.Branch
 
 	if purpose == "" {
 		return nil, fmt.Errorf("purpose is required")
@@ -68,6 +82,8 @@ func ModelRequest(
 
 	inputTokensEstimate := GetMessagesTokenEstimate(messages...) + TokensPerRequest
 
+	baseConfig := modelConfig
+
 	config := modelConfig.GetRoleForInputTokens(inputTokensEstimate)
 	modelConfig = &config
 
@@ -76,49 +92,94 @@ func ModelRequest(
 		modelConfig = &config
 	}
 
-	log.Printf("Model config - role: %s, model: %s, max output tokens: %d\n", modelConfig.Role, modelConfig.BaseModelConfig.ModelName, modelConfig.BaseModelConfig.MaxOutputTokens)
+	selectedConfig := modelConfig
 
-	_, apiErr := hooks.ExecHook(hooks.WillSendModelRequest, hooks.HookParams{
-		Auth: auth,
-		Plan: plan,
-		WillSendModelRequestParams: &hooks.WillSendModelRequestParams{
-			InputTokens:  inputTokensEstimate,
-			OutputTokens: modelConfig.BaseModelConfig.MaxOutputTokens - inputTokensEstimate,
-			ModelName:    modelConfig.BaseModelConfig.ModelName,
-		},
-	})
+	var res *types.ModelResponse
+	var req types.ExtendedChatCompletionRequest
+	var reqStarted time.Time
 
-	if apiErr != nil {
-		return nil, apiErr
-	}
+	var errorFallbackNum int
+	var errorFallbackFrom *shared.ModelRoleConfig
+	var errorFallbackReason string
 
-	if params.BeforeReq != nil {
-		params.BeforeReq()
-	}
+	for {
+		log.Printf("Model config - role: %s, model: %s, max output tokens: %d\n", modelConfig.Role, modelConfig.BaseModelConfig.ModelName, modelConfig.BaseModelConfig.MaxOutputTokens)
 
-	reqStarted := time.Now()
+		hookResult, apiErr := hooks.ExecHook(hooks.WillSendModelRequest, hooks.HookParams{
+			Auth: auth,
+			Plan: plan,
+			WillSendModelRequestParams: &hooks.WillSendModelRequestParams{
+				InputTokens:   inputTokensEstimate,
+				OutputTokens:  modelConfig.BaseModelConfig.MaxOutputTokens - inputTokensEstimate,
+				ModelName:     modelConfig.BaseModelConfig.ModelName,
+				ModelId:       modelConfig.BaseModelConfig.ModelId,
+				ModelProvider: modelConfig.BaseModelConfig.Provider,
+			},
+		})
 
-	req := types.ExtendedChatCompletionRequest{
-		Model:       modelConfig.BaseModelConfig.ModelName,
-		Messages:    messages,
-		Temperature: modelConfig.Temperature,
-		TopP:        modelConfig.TopP,
-		Stop:        stop,
-		Tools:       tools,
-		ToolChoice:  toolChoice,
-	}
+		if apiErr != nil {
+			return nil, apiErr
+		}
 
-	if prediction != "" {
-		req.Prediction = &types.OpenAIPrediction{
-			Type:    "content",
-			Content: prediction,
+		if hookResult.BudgetWarning != nil && params.OnBudgetWarning != nil {
+			params.OnBudgetWarning(*hookResult.BudgetWarning)
 		}
-	}
 
-	res, err := CreateChatCompletionWithInternalStream(clients, modelConfig, ctx, req, params.OnStream, reqStarted)
+		if params.BeforeReq != nil && errorFallbackNum == 0 {
+			params.BeforeReq()
+		}
+
+		reqStarted = time.Now()
+
+		req = types.ExtendedChatCompletionRequest{
+			Model:       modelConfig.BaseModelConfig.ModelName,
+			Messages:    messages,
+			Temperature: modelConfig.Temperature,
+			TopP:        modelConfig.TopP,
+			Stop:        stop,
+			Tools:       tools,
+			ToolChoice:  toolChoice,
+		}
+
+		// an error fallback may not support predicted output even if the original model did
+		if prediction != "" && (errorFallbackNum == 0 || modelConfig.BaseModelConfig.PredictedOutputEnabled) {
+			req.Prediction = &types.OpenAIPrediction{
+				Type:    "content",
+				Content: prediction,
+			}
+		}
+
+		var err error
+		res, err = CreateChatCompletionWithInternalStream(clients, modelConfig, ctx, req, params.OnStream, reqStarted)
+
+		if err == nil {
+			break
+		}
+
+		if !IsErrorFallbackEligible(ctx, err) {
+			return nil, err
+		}
+
+		fallback := GetErrorFallbackConfig(baseConfig, selectedConfig, errorFallbackNum+1, inputTokensEstimate)
+		if fallback == nil {
+			return nil, err
+		}
+
+		log.Printf("Model request failed with %s - switching to error fallback %d: %s | error: %v\n", modelConfig.BaseModelConfig.ModelName, errorFallbackNum+1, fallback.BaseModelConfig.ModelName, err)
+
+		if params.OnErrorFallback != nil {
+			params.OnErrorFallback(shared.ModelFallbackInfo{
+				Role:      modelConfig.Role,
+				FromModel: modelConfig.BaseModelConfig.ModelId,
+				ToModel:   fallback.BaseModelConfig.ModelId,
+				Reason:    err.Error(),
+			})
+		}
 
-	if err != nil {
-		return nil, err
+		errorFallbackNum++
+		errorFallbackFrom = modelConfig
+		errorFallbackReason = err.Error()
+		modelConfig = fallback
 	}
 
 	if params.AfterReq != nil {
@@ -162,6 +223,7 @@ func ModelRequest(
 				Purpose:        purpose,
 				GenerationId:   res.GenerationId,
 				PlanId:         plan.Id,
+				Branch:         branch,
 				ModelStreamId:  modelStreamId,
 				ConvoMessageId: convoMessageId,
 				BuildId:        buildId,
@@ -173,6 +235,10 @@ func ModelRequest(
 				ModelConfig:      modelConfig,
 				FirstTokenAt:     res.FirstTokenAt,
 				SessionId:        sessionId,
+
+				ErrorFallbackNum:       errorFallbackNum,
+				ErrorFallbackFromModel: errorFallbackFromModelId(errorFallbackFrom),
+				ErrorFallbackReason:    errorFallbackReason,
 			},
 		})
 
@@ -183,3 +249,82 @@ func ModelRequest(
 
 	return res, nil
 }
+
+var errorStatusCodeRegex = regexp.MustCompile(`status code: (\d{3})`)
+
+// connection, timeout, and provider overload errors that don't have a status code
+var errorFallbackConnectionErrs = []string{
+	"stream timed out",
+	"stream timeout",
+	"model stream ended unexpectedly",
+	"unexpected eof",
+	"connection reset",
+	"connection refused",
+	"broken pipe",
+	"no such host",
+	"i/o timeout",
+	"tls handshake timeout",
+	"server closed",
+	"overloaded",
+	"rate_limit_error",
+	"api_error:",
+}
+
+// IsErrorFallbackEligible returns true if a request that failed after all retries should be sent again with the next model in the error fallback chain. Only provider
+// failures are eligible: 5xx and 429 responses, and connection and timeout errors. Other errors, like invalid auth, invalid requests, and content policy rejections, aren't.
+func IsErrorFallbackEligible(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	errStr := err.Error()
+	if strings.Contains(errStr, "context canceled") || strings.Contains(errStr, "context deadline exceeded") {
+		return false
+	}
+
+	if match := errorStatusCodeRegex.FindStringSubmatch(errStr); match != nil {
+		status, _ := strconv.Atoi(match[1])
+		return status == http.StatusTooManyRequests || status >= 500
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	lower := strings.ToLower(errStr)
+	for _, s := range errorFallbackConnectionErrs {
+		if strings.Contains(lower, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetErrorFallbackConfig returns the nth error fallback for a request, resolved for the request's input tokens. The chain of the model selected for the request (which may be a large context or large output fallback) is used if it has one, otherwise the chain of the role's base config.
+func GetErrorFallbackConfig(baseConfig, selectedConfig *shared.ModelRoleConfig, n, inputTokens int) *shared.ModelRoleConfig {
+	var fallback *shared.ModelRoleConfig
+	if selectedConfig.ErrorFallback != nil {
+		fallback = selectedConfig.GetErrorFallback(n)
+	} else {
+		fallback = baseConfig.GetErrorFallback(n)
+	}
+
+	if fallback == nil {
+		return nil
+	}
+
+	res := fallback.GetRoleForInputTokens(inputTokens)
+	if res.Role == "" {
+		res.Role = selectedConfig.Role
+	}
+	return &res
+}
+
+func errorFallbackFromModelId(config *shared.ModelRoleConfig) shared.ModelId {
+	if config == nil {
+		return ""
+	}
+	return config.BaseModelConfig.ModelId
+}
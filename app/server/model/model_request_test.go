@@ -0,0 +1,99 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestGetErrorFallbackConfig(t *testing.T) {
+	base := &shared.ModelRoleConfig{
+		Role:            shared.ModelRoleBuilder,
+		BaseModelConfig: shared.BaseModelConfig{ModelId: "primary", MaxTokens: 100000},
+		ErrorFallback: &shared.ModelRoleConfig{
+			BaseModelConfig: shared.BaseModelConfig{ModelId: "fallback-1", MaxTokens: 10000},
+			LargeContextFallback: &shared.ModelRoleConfig{
+				BaseModelConfig: shared.BaseModelConfig{ModelId: "fallback-1-large", MaxTokens: 200000},
+			},
+			ErrorFallback: &shared.ModelRoleConfig{
+				BaseModelConfig: shared.BaseModelConfig{ModelId: "fallback-2", MaxTokens: 100000},
+			},
+		},
+	}
+
+	res := GetErrorFallbackConfig(base, base, 1, 5000)
+	if res == nil || res.BaseModelConfig.ModelId != "fallback-1" {
+		t.Fatalf("expected fallback-1, got %+v", res)
+	}
+	if res.Role != shared.ModelRoleBuilder {
+		t.Errorf("expected role to be inherited, got %q", res.Role)
+	}
+
+	res = GetErrorFallbackConfig(base, base, 1, 50000)
+	if res == nil || res.BaseModelConfig.ModelId != "fallback-1-large" {
+		t.Errorf("expected large context fallback of fallback-1, got %+v", res)
+	}
+
+	res = GetErrorFallbackConfig(base, base, 2, 5000)
+	if res == nil || res.BaseModelConfig.ModelId != "fallback-2" {
+		t.Errorf("expected fallback-2, got %+v", res)
+	}
+
+	if res := GetErrorFallbackConfig(base, base, 3, 5000); res != nil {
+		t.Errorf("expected end of chain, got %+v", res)
+	}
+
+	// selected config without its own chain uses the base chain
+	selected := &shared.ModelRoleConfig{
+		Role:            shared.ModelRoleBuilder,
+		BaseModelConfig: shared.BaseModelConfig{ModelId: "large-output", MaxTokens: 100000},
+	}
+	res = GetErrorFallbackConfig(base, selected, 1, 5000)
+	if res == nil || res.BaseModelConfig.ModelId != "fallback-1" {
+		t.Errorf("expected base chain to be used, got %+v", res)
+	}
+}
+
+func TestIsErrorFallbackEligible(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		eligible bool
+	}{
+		{"server error", errors.New("streaming request failed: status code: 503, body: overloaded"), true},
+		{"bad gateway", errors.New("error, status code: 502, status: 502 Bad Gateway, message: upstream error"), true},
+		{"rate limited", errors.New("error, status code: 429, status: 429 Too Many Requests, message: rate limit reached"), true},
+		{"stream inactivity timeout", errors.New("stream timeout due to inactivity | The model is not responding."), true},
+		{"connection reset", errors.New("error receiving stream chunk: read tcp 10.0.0.1:443: read: connection reset by peer"), true},
+		{"unexpected eof", fmt.Errorf("model stream ended unexpectedly: %w", io.ErrUnexpectedEOF), true},
+		{"dial error", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: connection refused")}, true},
+		{"anthropic overloaded", errors.New("anthropic stream error: overloaded_error: Overloaded"), true},
+		{"anthropic api error", errors.New("anthropic stream error: api_error: Internal server error"), true},
+
+		{"invalid api key", errors.New("error, status code: 401, status: 401 Unauthorized, message: Incorrect API key provided"), false},
+		{"forbidden", errors.New("streaming request failed: status code: 403, body: permission denied"), false},
+		{"invalid request", errors.New("error, status code: 400, status: 400 Bad Request, message: Invalid value for 'messages'"), false},
+		{"content policy", errors.New("error, status code: 400, status: 400 Bad Request, message: Your request was rejected as a result of our safety system. content_policy_violation"), false},
+		{"anthropic invalid request", errors.New("anthropic stream error: invalid_request_error: prompt is too long"), false},
+		{"missing file timeout", errors.New("timeout waiting for missing file choice"), false},
+		{"canceled", context.Canceled, false},
+		{"deadline exceeded", fmt.Errorf("error receiving stream chunk: %w", context.DeadlineExceeded), false},
+	}
+
+	for _, test := range tests {
+		if got := IsErrorFallbackEligible(context.Background(), test.err); got != test.eligible {
+			t.Errorf("%s: got %v, want %v", test.name, got, test.eligible)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if IsErrorFallbackEligible(ctx, errors.New("status code: 503")) {
+		t.Error("expected error after cancellation not to be eligible")
+	}
+}
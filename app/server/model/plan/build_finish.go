@@ -8,6 +8,7 @@ import (
 	"plandex-server/db"
 	"plandex-server/hooks"
 	"plandex-server/types"
+	"plandex-server/webhooks"
 	"strings"
 	"time"
 
@@ -59,6 +60,7 @@ func (state *activeBuildStreamFileState) onFinishBuild() {
 
 	log.Println("Locking repo for finished build")
 
+	var builtPaths []string
 	err := db.ExecRepoOperation(db.ExecRepoOperationParams{
 		OrgId:       currentOrgId,
 		UserId:      currentUserId,
@@ -136,6 +138,10 @@ func (state *activeBuildStreamFileState) onFinishBuild() {
 
 		log.Println("Plan build committed")
 
+		if currentPlan.PlanResult != nil {
+			builtPaths = currentPlan.PlanResult.SortedPaths
+		}
+
 		return nil
 	})
 
@@ -152,6 +158,16 @@ func (state *activeBuildStreamFileState) onFinishBuild() {
 		return
 	}
 
+	if builtPaths != nil {
+		webhooks.Dispatch(currentOrgId, shared.WebhookEventPlanBuildFinished, &shared.WebhookEventData{
+			PlanId:   planId,
+			PlanName: state.plan.Name,
+			Branch:   branch,
+			UserId:   currentUserId,
+			Paths:    builtPaths,
+		})
+	}
+
 	active := GetActivePlan(planId, branch)
 
 	if active != nil && (active.RepliesFinished || active.BuildOnly) {
@@ -9,6 +9,8 @@ import (
 	"plandex-server/utils"
 	"strings"
 	"time"
+
+	shared "plandex-shared"
 )
 
 type raceResult struct {
@@ -256,6 +258,12 @@ func (fileState *activeBuildStreamFileState) buildRace(
 			log.Printf("buildRace - error channel received %d: %v\n", errChNumReceived, err)
 
 			if err != nil {
+				// the other attempts would be stopped by the same budget, so end the build with the budget error
+				var apiErr *shared.ApiError
+				if errors.As(err, &apiErr) && apiErr.Type == shared.ApiErrorTypeBudgetExceeded {
+					return raceResult{}, apiErr
+				}
+
 				errs = append(errs, err)
 			}
 
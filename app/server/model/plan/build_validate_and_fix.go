@@ -280,10 +280,13 @@ func (fileState *activeBuildStreamFileState) buildValidate(
 			log.Printf("Finished model request")
 			fileState.builderRun.ReplacementFinishedAt = time.Now()
 		},
-		OnStream: onStream,
+		OnStream:        onStream,
+		OnErrorFallback: streamModelFallbackFn(fileState.plan.Id, fileState.branch),
+		OnBudgetWarning: streamBudgetWarningFn(fileState.plan.Id, fileState.branch),
 
 		WillCacheNumTokens: willCacheNumTokens,
 		SessionId:          params.sessionId,
+		Branch:             fileState.branch,
 	})
 
 	if err != nil {
@@ -293,6 +296,13 @@ func (fileState *activeBuildStreamFileState) buildValidate(
 		}
 
 		log.Printf("Error calling model: %v", err)
+
+		// retrying won't get past a budget
+		var apiErr *shared.ApiError
+		if errors.As(err, &apiErr) && apiErr.Type == shared.ApiErrorTypeBudgetExceeded {
+			return buildValidateResult{}, err
+		}
+
 		return fileState.validationRetryOrError(ctx, params, err)
 	}
 
@@ -323,20 +333,48 @@ func handleXMLResponse(
 ) (buildValidateResult, error) {
 	log.Printf("Handling XML response for file: %s", fileState.filePath)
 
+	res, err := ApplyValidationResponse(content, originalWithLineNums, updated, validateOnly)
+	if err != nil {
+		return buildValidateResult{valid: false, updated: updated}, err
+	}
+
+	if res.Valid {
+		fileState.builderRun.ReplacementSuccess = true
+	}
+
+	return buildValidateResult{
+		valid:   res.Valid,
+		updated: res.Updated,
+		problem: res.Problem,
+	}, nil
+}
+
+type ValidationResponseResult struct {
+	Valid   bool
+	Updated string
+	Problem string
+}
+
+// ApplyValidationResponse applies the replacements in a builder's XML validation response to the original file. It doesn't call a model, so it's also used to replay recorded responses in evals.
+func ApplyValidationResponse(
+	content string,
+	originalWithLineNums shared.LineNumberedTextType,
+	updated string,
+	validateOnly bool,
+) (ValidationResponseResult, error) {
 	if strings.Contains(content, "<PlandexCorrect/>") {
 		log.Printf("XML response indicates changes are correct")
-		fileState.builderRun.ReplacementSuccess = true
-		return buildValidateResult{
-			valid:   true,
-			updated: updated,
+		return ValidationResponseResult{
+			Valid:   true,
+			Updated: updated,
 		}, nil
 	}
 
 	if validateOnly {
 		log.Printf("Validation-only mode, skipping replacements")
-		return buildValidateResult{
-			valid:   false,
-			updated: updated,
+		return ValidationResponseResult{
+			Valid:   false,
+			Updated: updated,
 		}, nil
 	}
 
@@ -350,10 +388,10 @@ func handleXMLResponse(
 
 	if replacementsOuter == "" {
 		log.Printf("No replacements found in XML response")
-		return buildValidateResult{
-			valid:   false,
-			updated: shared.RemoveLineNums(incremental),
-			problem: "No replacements found in XML response",
+		return ValidationResponseResult{
+			Valid:   false,
+			Updated: shared.RemoveLineNums(incremental),
+			Problem: "No replacements found in XML response",
 		}, nil
 	}
 
@@ -367,7 +405,7 @@ func handleXMLResponse(
 
 		if old == "" {
 			log.Printf("No old content found for replacement")
-			return buildValidateResult{valid: false, updated: updated}, fmt.Errorf("no old content found for replacement")
+			return ValidationResponseResult{}, fmt.Errorf("no old content found for replacement")
 		}
 
 		old = strings.TrimSpace(old)
@@ -378,7 +416,7 @@ func handleXMLResponse(
 
 		if !strings.HasPrefix(old, "pdx-") {
 			log.Printf("Old content does not have a line number prefix for first line")
-			return buildValidateResult{valid: false, updated: updated}, fmt.Errorf("old content does not have a line number prefix for first line")
+			return ValidationResponseResult{}, fmt.Errorf("old content does not have a line number prefix for first line")
 		}
 
 		oldLines := strings.Split(old, "\n")
@@ -393,27 +431,27 @@ func handleXMLResponse(
 		firstLineNum, err := shared.ExtractLineNumberWithPrefix(firstLine, "pdx-")
 		if err != nil {
 			log.Printf("Error extracting line number from first line: %v", err)
-			return buildValidateResult{valid: false, updated: updated}, fmt.Errorf("error extracting line number from first line: %v", err)
+			return ValidationResponseResult{}, fmt.Errorf("error extracting line number from first line: %v", err)
 		}
 
 		if lastLine != "" {
 			lastLineNum, err = shared.ExtractLineNumberWithPrefix(lastLine, "pdx-")
 			if err != nil {
 				log.Printf("Error extracting line number from last line: %v", err)
-				return buildValidateResult{valid: false, updated: updated}, fmt.Errorf("error extracting line number from last line: %v", err)
+				return ValidationResponseResult{}, fmt.Errorf("error extracting line number from last line: %v", err)
 			}
 		}
 
 		if lastLineNum == 0 {
 			if !(firstLineNum > 0 && firstLineNum <= len(originalFileLines)) {
 				log.Printf("Invalid line number for first line: %d", firstLineNum)
-				return buildValidateResult{valid: false, updated: updated}, fmt.Errorf("invalid line number for first line: %d", firstLineNum)
+				return ValidationResponseResult{}, fmt.Errorf("invalid line number for first line: %d", firstLineNum)
 			}
 			old = originalFileLines[firstLineNum-1]
 		} else {
 			if !(firstLineNum > 0 && firstLineNum <= len(originalFileLines) && lastLineNum > firstLineNum && lastLineNum <= len(originalFileLines)) {
 				log.Printf("Invalid line numbers for first and last lines: %d-%d", firstLineNum, lastLineNum)
-				return buildValidateResult{valid: false, updated: updated}, fmt.Errorf("invalid line numbers: %d-%d", firstLineNum, lastLineNum)
+				return ValidationResponseResult{}, fmt.Errorf("invalid line numbers: %d-%d", firstLineNum, lastLineNum)
 			}
 			old = strings.Join(originalFileLines[firstLineNum-1:lastLineNum], "\n")
 		}
@@ -439,7 +477,7 @@ func handleXMLResponse(
 
 	// log.Printf("Final content:\n\n%s", final)
 
-	return buildValidateResult{valid: false, updated: final, problem: problem}, nil
+	return ValidationResponseResult{Valid: false, Updated: final, Problem: problem}, nil
 }
 
 func (fileState *activeBuildStreamFileState) validationRetryOrError(buildCtx context.Context, validateParams buildValidateParams, err error) (buildValidateResult, error) {
@@ -94,6 +94,7 @@ func (fileState *activeBuildStreamFileState) buildWholeFileFallback(buildCtx con
 		ModelStreamId:  fileState.modelStreamId,
 		ConvoMessageId: fileState.convoMessageId,
 		BuildId:        fileState.build.Id,
+		Branch:         fileState.branch,
 
 		BeforeReq: func() {
 			fileState.builderRun.BuiltWholeFile = true
@@ -104,6 +105,9 @@ func (fileState *activeBuildStreamFileState) buildWholeFileFallback(buildCtx con
 			fileState.builderRun.BuildWholeFileFinishedAt = time.Now()
 		},
 
+		OnErrorFallback: streamModelFallbackFn(fileState.plan.Id, fileState.branch),
+		OnBudgetWarning: streamBudgetWarningFn(fileState.plan.Id, fileState.branch),
+
 		WillCacheNumTokens: willCacheNumTokens,
 
 		SessionId: sessionId,
@@ -115,7 +119,7 @@ func (fileState *activeBuildStreamFileState) buildWholeFileFallback(buildCtx con
 			return "", err
 		}
 
-		return "", fmt.Errorf("error calling model: %v", err)
+		return "", fmt.Errorf("error calling model: %w", err)
 	}
 
 	fileState.builderRun.GenerationIds = append(fileState.builderRun.GenerationIds, modelRes.GenerationId)
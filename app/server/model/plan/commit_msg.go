@@ -80,15 +80,18 @@ func (state *activeTellStreamState) genPlanDescription() (*db.ConvoMessageDescri
 	}
 
 	reqParams := model.ModelRequestParams{
-		Clients:        clients,
-		Auth:           auth,
-		Plan:           plan,
-		ModelConfig:    &config,
-		Purpose:        "Response summary",
-		Messages:       messages,
-		ModelStreamId:  state.modelStreamId,
-		ConvoMessageId: state.replyId,
-		SessionId:      activePlan.SessionId,
+		Clients:         clients,
+		Auth:            auth,
+		Plan:            plan,
+		ModelConfig:     &config,
+		Purpose:         "Response summary",
+		Messages:        messages,
+		ModelStreamId:   state.modelStreamId,
+		ConvoMessageId:  state.replyId,
+		SessionId:       activePlan.SessionId,
+		Branch:          branch,
+		OnErrorFallback: streamModelFallbackFn(planId, branch),
+		OnBudgetWarning: streamBudgetWarningFn(planId, branch),
 	}
 
 	if tools != nil {
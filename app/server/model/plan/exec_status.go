@@ -135,15 +135,18 @@ func (state *activeTellStreamState) execStatusShouldContinue(currentMessage stri
 	}
 
 	modelRes, err := model.ModelRequest(ctx, model.ModelRequestParams{
-		Clients:        clients,
-		Auth:           auth,
-		Plan:           plan,
-		ModelConfig:    &config,
-		Purpose:        "Task completion check",
-		Messages:       messages,
-		ModelStreamId:  state.modelStreamId,
-		ConvoMessageId: state.replyId,
-		SessionId:      sessionId,
+		Clients:         clients,
+		Auth:            auth,
+		Plan:            plan,
+		ModelConfig:     &config,
+		Purpose:         "Task completion check",
+		Messages:        messages,
+		ModelStreamId:   state.modelStreamId,
+		ConvoMessageId:  state.replyId,
+		SessionId:       sessionId,
+		Branch:          state.branch,
+		OnErrorFallback: streamModelFallbackFn(plan.Id, state.branch),
+		OnBudgetWarning: streamBudgetWarningFn(plan.Id, state.branch),
 	})
 
 	if err != nil {
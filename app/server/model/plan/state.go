@@ -6,6 +6,7 @@ import (
 	"plandex-server/db"
 	"plandex-server/shutdown"
 	"plandex-server/types"
+	"plandex-server/webhooks"
 	"strings"
 	"time"
 
@@ -52,6 +53,12 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 						log.Printf("Error setting plan %s status to ready: %v\n", planId, err)
 					}
 
+					webhooks.Dispatch(orgId, shared.WebhookEventPlanStreamFinished, &shared.WebhookEventData{
+						PlanId: planId,
+						Branch: branch,
+						UserId: userId,
+					})
+
 					// cancel *after* the DeleteActivePlan call
 					// allows queued operations to complete
 					DeleteActivePlan(orgId, userId, planId, branch)
@@ -65,6 +72,13 @@ func CreateActivePlan(orgId, userId, planId, branch, prompt string, buildOnly, a
 						log.Printf("Error setting plan %s status to error: %v\n", planId, err)
 					}
 
+					webhooks.Dispatch(orgId, shared.WebhookEventPlanError, &shared.WebhookEventData{
+						PlanId: planId,
+						Branch: branch,
+						UserId: userId,
+						Error:  apiErr.Msg,
+					})
+
 					log.Println("Sending error message to client")
 					activePlan.Stream(shared.StreamMessage{
 						Type:  shared.StreamMessageError,
@@ -136,6 +150,33 @@ func UpdateActivePlan(planId, branch string, fn func(*types.ActivePlan)) {
 	activePlans.Update(strings.Join([]string{planId, branch}, "|"), fn)
 }
 
+// returns a model.ModelRequestParams.OnErrorFallback callback that lets the client know the role's model was switched
+func streamModelFallbackFn(planId, branch string) func(info shared.ModelFallbackInfo) {
+	return func(info shared.ModelFallbackInfo) {
+		active := GetActivePlan(planId, branch)
+		if active == nil {
+			return
+		}
+		active.Stream(shared.StreamMessage{
+			Type:          shared.StreamMessageModelFallback,
+			ModelFallback: &info,
+		})
+	}
+}
+
+func streamBudgetWarningFn(planId, branch string) func(status shared.BudgetStatus) {
+	return func(status shared.BudgetStatus) {
+		active := GetActivePlan(planId, branch)
+		if active == nil {
+			return
+		}
+		active.Stream(shared.StreamMessage{
+			Type:          shared.StreamMessageBudgetWarning,
+			BudgetWarning: &status,
+		})
+	}
+}
+
 func SubscribePlan(ctx context.Context, planId, branch string) (string, chan string) {
 	log.Printf("Subscribing to plan %s\n", planId)
 	var id string
@@ -3,6 +3,7 @@ package plan
 import (
 	"fmt"
 	"log"
+	"plandex-server/embeddings"
 	"plandex-server/types"
 	"regexp"
 	"sort"
@@ -251,6 +252,13 @@ func (state *activeTellStreamState) formatModelContext(params formatModelContext
 
 				args = append(args, part.FilePath, body)
 			}
+		} else if part.ContextType == shared.ContextSymbolType {
+			// make it clear this isn't the whole file so the model doesn't treat it as the full file when making edits
+			fmtStr = "\n\n- %s | symbol (only this definition and the signatures enclosing it are loaded, not the whole file):\n\n```\n%s\n```"
+			args = append(args, part.Name, part.Body)
+		} else if part.ContextType.IsGit() {
+			fmtStr = "\n\n- %s | output of git:\n\n```diff\n%s\n```"
+			args = append(args, part.Name, part.Body)
 		} else if part.ContextType == shared.ContextMapType {
 			fmtStr = "\n\n- %s | map:\n\n```\n%s\n```"
 			args = append(args, part.FilePath, part.Body)
@@ -433,3 +441,66 @@ func (state *activeTellStreamState) checkAutoLoadContext() checkAutoLoadContextR
 		hasExplicitPaths:     hasExplicitPaths,
 	}
 }
+
+const maxSemanticRankingResults = 20
+
+// formatSemanticRanking ranks the project map's files by embedding similarity to the prompt, so the architect can start from the most likely files when choosing context.
+// It's a hint on top of the map rather than a requirement, so any failure just skips it.
+func (state *activeTellStreamState) formatSemanticRanking() []*types.ExtendedChatMessagePart {
+	prompt := strings.TrimSpace(state.req.Prompt)
+	if prompt == "" {
+		return nil
+	}
+
+	embedder, err := embeddings.GetEmbedder()
+	if err != nil {
+		log.Printf("Tell plan - formatSemanticRanking - error getting embedder: %v\n", err)
+		return nil
+	}
+	if embedder == nil {
+		return nil
+	}
+
+	mapParts := embeddings.MapPartsFromContexts(state.modelContext)
+	if len(mapParts) == 0 {
+		return nil
+	}
+
+	results, err := embeddings.Search(state.activePlan.Ctx, embeddings.SearchParams{
+		Embedder:  embedder,
+		OrgId:     state.currentOrgId,
+		ProjectId: state.plan.ProjectId,
+		MapParts:  mapParts,
+		Query:     prompt,
+		Limit:     maxSemanticRankingResults,
+	})
+	if err != nil {
+		log.Printf("Tell plan - formatSemanticRanking - error searching: %v\n", err)
+		return nil
+	}
+
+	if len(results) == 0 {
+		return nil
+	}
+
+	log.Printf("Tell plan - formatSemanticRanking - %d ranked files\n", len(results))
+
+	lines := []string{
+		"### SEMANTICALLY RELATED FILES ###",
+		"These files from the project map were ranked as the most similar to the user's prompt by an embedding search, most similar first. Use them as a starting point when deciding which files to load, but still check the map—a similar file isn't always a relevant one, and relevant files may be missing from this list.\n",
+	}
+	for _, result := range results {
+		line := fmt.Sprintf("- `%s` (%.2f)", result.Path, result.Score)
+		if len(result.Definitions) > 0 {
+			line += " — " + strings.Join(result.Definitions, "; ")
+		}
+		lines = append(lines, line)
+	}
+
+	return []*types.ExtendedChatMessagePart{
+		{
+			Type: openai.ChatMessagePartTypeText,
+			Text: strings.Join(lines, "\n"),
+		},
+	}
+}
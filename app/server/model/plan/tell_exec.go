@@ -62,6 +62,11 @@ type execTellPlanParams struct {
 	shouldBuildPending         bool
 	numErrorRetry              int
 	unfinishedSubtaskReasoning string
+
+	// position in the role's error fallback chain -- 0 means the original model is used
+	numErrorFallback    int
+	errorFallbackFrom   shared.ModelId
+	errorFallbackReason string
 }
 
 func execTellPlan(params execTellPlanParams) {
@@ -191,16 +196,33 @@ func execTellPlan(params execTellPlanParams) {
 			cacheControl:        true,
 		})
 
-		if state.currentStage.PlanningPhase == shared.PlanningPhaseTasks {
-			if req.AutoContext {
-				msg := types.ExtendedChatMessage{
-					Role:    openai.ChatMessageRoleSystem,
-					Content: []types.ExtendedChatMessagePart{},
-				}
-				for _, part := range planStageSharedMsgs {
-					msg.Content = append(msg.Content, *part)
+		getPartsTokens := func(parts []*types.ExtendedChatMessagePart) int {
+			msg := types.ExtendedChatMessage{
+				Role:    openai.ChatMessageRoleSystem,
+				Content: []types.ExtendedChatMessagePart{},
+			}
+			for _, part := range parts {
+				msg.Content = append(msg.Content, *part)
+			}
+			return model.GetMessagesTokenEstimate(msg)
+		}
+
+		if state.currentStage.PlanningPhase == shared.PlanningPhaseContext {
+			rankingParts := state.formatSemanticRanking()
+			if len(rankingParts) > 0 {
+				tokensRemaining := tentativeMaxTokens - (getPartsTokens(planStageSharedMsgs) + tokensWithoutContext)
+				rankingTokens := getPartsTokens(rankingParts)
+
+				// the ranking is only a hint, so leave it out rather than going over the token limit
+				if rankingTokens > tokensRemaining {
+					log.Printf("Skipping semantic ranking - %d tokens with %d remaining\n", rankingTokens, tokensRemaining)
+				} else {
+					planningPhaseOnlyMsgs = rankingParts
 				}
-				sharedMsgsTokens := model.GetMessagesTokenEstimate(msg)
+			}
+		} else if state.currentStage.PlanningPhase == shared.PlanningPhaseTasks {
+			if req.AutoContext {
+				sharedMsgsTokens := getPartsTokens(planStageSharedMsgs)
 
 				tokensRemaining := tentativeMaxTokens - (sharedMsgsTokens + tokensWithoutContext)
 
@@ -351,6 +373,17 @@ func execTellPlan(params execTellPlanParams) {
 		log.Println("Tell plan - got modelConfig for implementation stage")
 	}
 
+	selectedModelConfig := modelConfig
+	state.selectedModelConfig = &selectedModelConfig
+
+	if params.numErrorFallback > 0 {
+		fallback := model.GetErrorFallbackConfig(&tentativeModelConfig, &selectedModelConfig, params.numErrorFallback, requestTokens)
+		if fallback != nil {
+			log.Printf("Tell plan - using error fallback %d: %s\n", params.numErrorFallback, fallback.BaseModelConfig.ModelName)
+			modelConfig = *fallback
+		}
+	}
+
 	log.Println("Tell plan - modelConfig:", spew.Sdump(modelConfig))
 
 	// if the model doesn't support cache control, remove the cache control spec from the messages
@@ -385,19 +418,24 @@ func execTellPlan(params execTellPlanParams) {
 		"tokens":   requestTokens,
 	}))
 
-	_, apiErr := hooks.ExecHook(hooks.WillSendModelRequest, hooks.HookParams{
+	hookResult, apiErr := hooks.ExecHook(hooks.WillSendModelRequest, hooks.HookParams{
 		Auth: auth,
 		Plan: plan,
 		WillSendModelRequestParams: &hooks.WillSendModelRequestParams{
-			InputTokens:  requestTokens,
-			OutputTokens: modelConfig.BaseModelConfig.MaxOutputTokens - requestTokens,
-			ModelName:    modelConfig.BaseModelConfig.ModelName,
+			InputTokens:   requestTokens,
+			OutputTokens:  modelConfig.BaseModelConfig.MaxOutputTokens - requestTokens,
+			ModelName:     modelConfig.BaseModelConfig.ModelName,
+			ModelId:       modelConfig.BaseModelConfig.ModelId,
+			ModelProvider: modelConfig.BaseModelConfig.Provider,
 		},
 	})
 	if apiErr != nil {
 		active.StreamDoneCh <- apiErr
 		return
 	}
+	if hookResult.BudgetWarning != nil {
+		streamBudgetWarningFn(planId, branch)(*hookResult.BudgetWarning)
+	}
 
 	// log.Println("Stop:", stop)
 	// spew.Dump(state.messages)
@@ -435,6 +473,10 @@ func execTellPlan(params execTellPlanParams) {
 	if err != nil {
 		log.Printf("Error starting reply stream: %v\n", err)
 
+		if state.tryErrorFallback(err) {
+			return
+		}
+
 		active.StreamDoneCh <- &shared.ApiError{
 			Type:   shared.ApiErrorTypeOther,
 			Status: http.StatusInternalServerError,
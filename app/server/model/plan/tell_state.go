@@ -56,6 +56,7 @@ type activeTellStreamState struct {
 	originalReq         *types.ExtendedChatCompletionRequest
 	tenativeModelConfig *shared.ModelRoleConfig
 	modelConfig         *shared.ModelRoleConfig
+	selectedModelConfig *shared.ModelRoleConfig // selected for the request's token count, before any error fallback
 
 	skipConvoMessages map[string]bool
 }
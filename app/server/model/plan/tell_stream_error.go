@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"plandex-server/db"
+	"plandex-server/model"
 	"plandex-server/shutdown"
 	"strconv"
 	"time"
@@ -59,6 +60,12 @@ func (state *activeTellStreamState) onError(params onErrorParams) onErrorResult
 		}
 	}
 
+	if params.canRetry && !canRetry && state.tryErrorFallback(streamErr) {
+		return onErrorResult{
+			shouldReturn: true,
+		}
+	}
+
 	if canRetry {
 		// stop stream via context (ensures we stop child streams too)
 		active.CancelModelStreamFn()
@@ -191,3 +198,50 @@ func (state *activeTellStreamState) onActivePlanMissingError() {
 		storeDesc: true,
 	})
 }
+
+// if the current role has an error fallback chain with models left to try, restarts the stream with the next model and returns true
+func (state *activeTellStreamState) tryErrorFallback(streamErr error) bool {
+	planId := state.plan.Id
+	branch := state.branch
+
+	active := GetActivePlan(planId, branch)
+	if active == nil || state.modelConfig == nil || state.selectedModelConfig == nil || state.tenativeModelConfig == nil {
+		return false
+	}
+
+	if !model.IsErrorFallbackEligible(active.Ctx, streamErr) {
+		return false
+	}
+
+	numErrorFallback := state.execTellPlanParams.numErrorFallback + 1
+	fallback := model.GetErrorFallbackConfig(state.tenativeModelConfig, state.selectedModelConfig, numErrorFallback, state.totalRequestTokens)
+	if fallback == nil {
+		return false
+	}
+
+	log.Printf("tellStream tryErrorFallback - switching from %s to error fallback %d: %s\n", state.modelConfig.BaseModelConfig.ModelName, numErrorFallback, fallback.BaseModelConfig.ModelName)
+
+	active.Stream(shared.StreamMessage{
+		Type: shared.StreamMessageModelFallback,
+		ModelFallback: &shared.ModelFallbackInfo{
+			Role:      state.modelConfig.Role,
+			FromModel: state.modelConfig.BaseModelConfig.ModelId,
+			ToModel:   fallback.BaseModelConfig.ModelId,
+			Reason:    streamErr.Error(),
+		},
+	})
+
+	// stop stream via context (ensures we stop child streams too)
+	active.CancelModelStreamFn()
+	active.ResetModelCtx()
+
+	params := state.execTellPlanParams
+	params.numErrorRetry = 0
+	params.numErrorFallback = numErrorFallback
+	params.errorFallbackFrom = state.modelConfig.BaseModelConfig.ModelId
+	params.errorFallbackReason = streamErr.Error()
+
+	execTellPlan(params)
+
+	return true
+}
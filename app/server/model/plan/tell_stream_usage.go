@@ -41,6 +41,7 @@ func (state *activeTellStreamState) handleUsageChunk(usage *openai.Usage) {
 				Purpose:        "Response",
 				GenerationId:   generationId,
 				PlanId:         plan.Id,
+				Branch:         state.branch,
 				ModelStreamId:  state.modelStreamId,
 				ConvoMessageId: state.replyId,
 
@@ -52,6 +53,10 @@ func (state *activeTellStreamState) handleUsageChunk(usage *openai.Usage) {
 				ModelConfig:      state.modelConfig,
 
 				SessionId: sessionId,
+
+				ErrorFallbackNum:       state.execTellPlanParams.numErrorFallback,
+				ErrorFallbackFromModel: state.execTellPlanParams.errorFallbackFrom,
+				ErrorFallbackReason:    state.execTellPlanParams.errorFallbackReason,
 			},
 		})
 
@@ -94,6 +99,7 @@ func (state *activeTellStreamState) execHookOnStop(sendStreamErr bool) {
 				Purpose:         "Response",
 				GenerationId:    generationId,
 				PlanId:          plan.Id,
+				Branch:          branch,
 				ModelStreamId:   state.modelStreamId,
 				ConvoMessageId:  state.replyId,
 				StoppedEarly:    true,
@@ -109,6 +115,10 @@ func (state *activeTellStreamState) execHookOnStop(sendStreamErr bool) {
 				ModelConfig:      state.modelConfig,
 
 				SessionId: active.SessionId,
+
+				ErrorFallbackNum:       state.execTellPlanParams.numErrorFallback,
+				ErrorFallbackFromModel: state.execTellPlanParams.errorFallbackFrom,
+				ErrorFallbackReason:    state.execTellPlanParams.errorFallbackReason,
 			},
 		})
 
@@ -386,6 +386,7 @@ func summarizeConvo(clients map[string]model.ClientInfo, config shared.ModelRole
 		ModelPackName:               params.modelPackName,
 		ModelStreamId:               active.ModelStreamId,
 		SessionId:                   active.SessionId,
+		Branch:                      branch,
 	}, ctx)
 
 	if apiErr != nil {
@@ -94,6 +94,7 @@ This also applies to other potentially heavy commands like building Docker image
 Script execution:
 - Assumes bash/zsh shell is available (OS/shell details provided in prompt)
 - The script runs in the root directory of the plan
+- If the project has additional roots (files with paths namespaced like 'web:src/app.ts'), the script still runs in the main project root. Each additional root's absolute directory is in an environment variable named PLANDEX_ROOT_ followed by the root's name in uppercase, with '-' replaced by '_'. For example, run commands in a root named 'web' with 'cd "$PLANDEX_ROOT_WEB" && npm install'. Do NOT use 'name:path' paths in commands—only Plandex understands them, not the shell.
 - All commands execute as a single unit after all file operations are complete
 
 Special cases:
@@ -773,6 +774,7 @@ DO NOT:
 
 Always:
 - Use relative paths
+- Reach additional roots through their PLANDEX_ROOT_<NAME> environment variables, never with 'name:path' paths
 - Show full command output
 - Preserve existing commands
 - Group related commands
@@ -31,6 +31,8 @@ If you are writing a code block in a language that is not in the list of valid l
 
 The <PlandexBlock> tag MUST also include a 'path' attribute that specifies the path to the file that the code block is for. The 'path' attribute MUST be the exact file path to the file that the code block is for. It must match the file path exactly.
 
+Projects can have additional roots outside the main project directory. Files in an additional root have paths namespaced with the root's name and a colon, like 'web:src/app.ts' for 'src/app.ts' in a root named 'web'. Use the full namespaced path, exactly as it appears in context, in both the file path label and the 'path' attribute. Paths without a namespace are in the main project root—do NOT add a namespace that isn't already used in context.
+
 ***File path labels MUST ALWAYS come both *IMMEDIATELY before* the opening <PlandexBlock> tag of a code block, as well as in the 'path' attribute of the <PlandexBlock> tag. Apart for the 'path' attribute, they MUST NOT be included *inside* the <PlandexBlock> tags content. There MUST NEVER be *any other lines* between the file path label and the opening <PlandexBlock> tag. Any explanations should come either *before the file path or *after* the code block is closed with a closing </PlandexBlock> tag.*
 
 The <PlandexBlock> tag MUST ONLY contain the code for the code block and NOTHING ELSE. Do NOT wrap the code block in triple backticks, CDATA tags, or any other text or formatting. Output ONLY the code and nothing else within the <PlandexBlock> tag.
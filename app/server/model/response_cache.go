@@ -0,0 +1,291 @@
+package model
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"plandex-server/db"
+	"plandex-server/types"
+	"sync"
+	"time"
+
+	shared "plandex-shared"
+)
+
+// The response cache stores complete streamed responses on disk, keyed on the resolved request, so identical model calls can be replayed chunk-by-chunk instead of going over the network.
+// It's off by default and controlled with the PLANDEX_MODEL_CACHE env var:
+//   - "cache":  replay on a hit, call the provider and record on a miss
+//   - "record": always call the provider and record (overwriting existing entries)
+//   - "replay": only replay -- a miss is an error, so no provider is ever called (for deterministic tests)
+// PLANDEX_MODEL_CACHE_DIR sets where entries are stored (defaults to $PLANDEX_BASE_DIR/model-cache).
+
+type ResponseCacheMode string
+
+const (
+	ResponseCacheModeOff    ResponseCacheMode = ""
+	ResponseCacheModeCache  ResponseCacheMode = "cache"
+	ResponseCacheModeRecord ResponseCacheMode = "record"
+	ResponseCacheModeReplay ResponseCacheMode = "replay"
+)
+
+var ErrResponseCacheMiss = errors.New("model response cache miss in replay mode")
+
+type responseCacheEntry struct {
+	Key        string                                       `json:"key"`
+	Provider   shared.ModelProvider                         `json:"provider"`
+	Model      shared.ModelName                             `json:"model"`
+	RecordedAt time.Time                                    `json:"recordedAt"`
+	Chunks     []types.ExtendedChatCompletionStreamResponse `json:"chunks"`
+}
+
+var (
+	responseCacheMu   sync.RWMutex
+	responseCacheInit bool
+	responseCacheMode ResponseCacheMode
+	responseCacheDir  string
+)
+
+// SetResponseCache overrides the mode and dir from the environment -- mainly for tests
+func SetResponseCache(mode ResponseCacheMode, dir string) {
+	responseCacheMu.Lock()
+	defer responseCacheMu.Unlock()
+	responseCacheInit = true
+	responseCacheMode = mode
+	responseCacheDir = dir
+}
+
+func getResponseCache() (ResponseCacheMode, string) {
+	responseCacheMu.RLock()
+	if responseCacheInit {
+		defer responseCacheMu.RUnlock()
+		return responseCacheMode, responseCacheDir
+	}
+	responseCacheMu.RUnlock()
+
+	responseCacheMu.Lock()
+	defer responseCacheMu.Unlock()
+
+	if !responseCacheInit {
+		responseCacheInit = true
+
+		mode := ResponseCacheMode(os.Getenv("PLANDEX_MODEL_CACHE"))
+		switch mode {
+		case ResponseCacheModeOff, ResponseCacheModeCache, ResponseCacheModeRecord, ResponseCacheModeReplay:
+		default:
+			log.Printf("Invalid PLANDEX_MODEL_CACHE value %q - model response cache disabled\n", mode)
+			mode = ResponseCacheModeOff
+		}
+
+		// responses would be shared across orgs
+		if mode != ResponseCacheModeOff && os.Getenv("IS_CLOUD") != "" {
+			log.Println("Model response cache isn't supported on cloud - disabled")
+			mode = ResponseCacheModeOff
+		}
+
+		dir := os.Getenv("PLANDEX_MODEL_CACHE_DIR")
+		if dir == "" {
+			dir = filepath.Join(db.BaseDir, "model-cache")
+		}
+
+		if mode != ResponseCacheModeOff {
+			log.Printf("Model response cache enabled - mode: %s, dir: %s\n", mode, dir)
+		}
+
+		responseCacheMode = mode
+		responseCacheDir = dir
+	}
+
+	return responseCacheMode, responseCacheDir
+}
+
+// the key covers everything that determines the response -- the request as it will be sent, plus where it's sent to. Per-request identifiers that don't affect the output are excluded.
+func getResponseCacheKey(modelConfig *shared.ModelRoleConfig, baseUrl string, req types.ExtendedChatCompletionRequest) (string, error) {
+	req.User = ""
+	req.Metadata = nil
+
+	var customProvider string
+	if modelConfig.BaseModelConfig.CustomProvider != nil {
+		customProvider = *modelConfig.BaseModelConfig.CustomProvider
+	}
+
+	jsonBytes, err := json.Marshal(struct {
+		Provider       shared.ModelProvider                `json:"provider"`
+		CustomProvider string                              `json:"customProvider"`
+		BaseUrl        string                              `json:"baseUrl"`
+		Request        types.ExtendedChatCompletionRequest `json:"request"`
+	}{
+		Provider:       modelConfig.BaseModelConfig.Provider,
+		CustomProvider: customProvider,
+		BaseUrl:        baseUrl,
+		Request:        req,
+	})
+	if err != nil {
+		return "", fmt.Errorf("error marshalling request for cache key: %v", err)
+	}
+
+	hash := sha256.Sum256(jsonBytes)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func getResponseCachePath(dir, key string) string {
+	return filepath.Join(dir, key[:2], key+".json")
+}
+
+func loadResponseCacheEntry(dir, key string) (*responseCacheEntry, error) {
+	bytes, err := os.ReadFile(getResponseCachePath(dir, key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("error reading cache entry: %v", err)
+	}
+
+	var entry responseCacheEntry
+	err = json.Unmarshal(bytes, &entry)
+	if err != nil {
+		return nil, fmt.Errorf("error unmarshalling cache entry: %v", err)
+	}
+
+	return &entry, nil
+}
+
+func storeResponseCacheEntry(dir string, entry *responseCacheEntry) error {
+	path := getResponseCachePath(dir, entry.Key)
+
+	err := os.MkdirAll(filepath.Dir(path), os.ModePerm)
+	if err != nil {
+		return fmt.Errorf("error creating cache dir: %v", err)
+	}
+
+	bytes, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("error marshalling cache entry: %v", err)
+	}
+
+	// write to a temp file and rename so concurrent readers never see a partial entry
+	tmpPath := path + ".tmp"
+	err = os.WriteFile(tmpPath, bytes, 0644)
+	if err != nil {
+		return fmt.Errorf("error writing cache entry: %v", err)
+	}
+
+	err = os.Rename(tmpPath, path)
+	if err != nil {
+		return fmt.Errorf("error renaming cache entry: %v", err)
+	}
+
+	return nil
+}
+
+// replays a recorded stream, then returns io.EOF
+type responseCacheReplayReader struct {
+	chunks []types.ExtendedChatCompletionStreamResponse
+	idx    int
+}
+
+func (r *responseCacheReplayReader) Recv() (*types.ExtendedChatCompletionStreamResponse, error) {
+	if r.idx >= len(r.chunks) {
+		return nil, io.EOF
+	}
+	chunk := r.chunks[r.idx]
+	r.idx++
+	return &chunk, nil
+}
+
+// records chunks as they're received from the provider -- only a stream that finished without error is stored, so partial or cancelled responses are never replayed
+type responseCacheRecorder struct {
+	dir      string
+	entry    responseCacheEntry
+	finished bool
+	failed   bool
+	stored   bool
+}
+
+func (r *responseCacheRecorder) add(chunk *types.ExtendedChatCompletionStreamResponse) {
+	r.entry.Chunks = append(r.entry.Chunks, *chunk)
+
+	for _, choice := range chunk.Choices {
+		if choice.FinishReason == "error" {
+			r.failed = true
+		} else if choice.FinishReason != "" {
+			r.finished = true
+		}
+	}
+}
+
+func (r *responseCacheRecorder) store() {
+	if r.stored || r.failed || !r.finished {
+		return
+	}
+	r.stored = true
+
+	r.entry.RecordedAt = time.Now()
+	err := storeResponseCacheEntry(r.dir, &r.entry)
+	if err != nil {
+		log.Printf("Error storing model response cache entry: %v\n", err)
+		return
+	}
+
+	log.Printf("Stored model response cache entry %s (%d chunks)\n", r.entry.Key, len(r.entry.Chunks))
+}
+
+// wraps stream creation with the response cache -- returns a replaying stream on a hit, otherwise creates the provider stream and records it if the cache mode calls for it
+func withResponseCache(
+	modelConfig *shared.ModelRoleConfig,
+	baseUrl string,
+	ctx context.Context,
+	req types.ExtendedChatCompletionRequest,
+	createStream func() (*ExtendedChatCompletionStream, error),
+) (*ExtendedChatCompletionStream, error) {
+	mode, dir := getResponseCache()
+	if mode == ResponseCacheModeOff {
+		return createStream()
+	}
+
+	key, err := getResponseCacheKey(modelConfig, baseUrl, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if mode == ResponseCacheModeCache || mode == ResponseCacheModeReplay {
+		entry, err := loadResponseCacheEntry(dir, key)
+		if err != nil {
+			log.Printf("Error loading model response cache entry %s: %v\n", key, err)
+		}
+
+		if entry != nil {
+			log.Printf("Replaying model response cache entry %s (%d chunks)\n", key, len(entry.Chunks))
+			return &ExtendedChatCompletionStream{
+				cacheReplayReader: &responseCacheReplayReader{chunks: entry.Chunks},
+				ctx:               ctx,
+			}, nil
+		}
+
+		if mode == ResponseCacheModeReplay {
+			return nil, fmt.Errorf("%w: %s (%s)", ErrResponseCacheMiss, key, req.Model)
+		}
+	}
+
+	stream, err := createStream()
+	if err != nil {
+		return nil, err
+	}
+
+	stream.cacheRecorder = &responseCacheRecorder{
+		dir: dir,
+		entry: responseCacheEntry{
+			Key:      key,
+			Provider: modelConfig.BaseModelConfig.Provider,
+			Model:    req.Model,
+		},
+	}
+
+	return stream, nil
+}
@@ -0,0 +1,143 @@
+package model
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"plandex-server/types"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+const openAICompatibleStream = `data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1,"model":"qwen2.5-coder","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"}}]}
+
+data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1,"model":"qwen2.5-coder","choices":[{"index":0,"delta":{"content":", world"}}]}
+
+data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1,"model":"qwen2.5-coder","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}
+
+data: {"id":"chatcmpl-1","object":"chat.completion.chunk","created":1,"model":"qwen2.5-coder","choices":[],"usage":{"prompt_tokens":20,"completion_tokens":4,"total_tokens":24}}
+
+data: [DONE]
+
+`
+
+func newOpenAICompatibleStub(numRequests *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(numRequests, 1)
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		io.WriteString(w, openAICompatibleStream)
+	}))
+}
+
+func responseCacheTestReq(prompt string) types.ExtendedChatCompletionRequest {
+	return types.ExtendedChatCompletionRequest{
+		Model: "qwen2.5-coder",
+		Messages: []types.ExtendedChatMessage{
+			{
+				Role:    openai.ChatMessageRoleUser,
+				Content: []types.ExtendedChatMessagePart{{Type: openai.ChatMessagePartTypeText, Text: prompt}},
+			},
+		},
+		Stream: true,
+	}
+}
+
+func TestResponseCacheRecordAndReplay(t *testing.T) {
+	var numRequests int32
+	server := newOpenAICompatibleStub(&numRequests)
+	defer server.Close()
+
+	SetResponseCache(ResponseCacheModeCache, t.TempDir())
+	defer SetResponseCache(ResponseCacheModeOff, "")
+
+	modelConfig := &shared.ModelRoleConfig{
+		BaseModelConfig: shared.BaseModelConfig{
+			Provider:  shared.ModelProviderOllama,
+			ModelName: "qwen2.5-coder",
+			BaseUrl:   server.URL,
+		},
+	}
+
+	var replayedChunks []string
+	for i := 0; i < 2; i++ {
+		res, err := processChatCompletionStream(modelConfig, ClientInfo{}, server.URL, context.Background(), responseCacheTestReq("Say hello."), func(chunk, buffer string) bool {
+			if i == 1 {
+				replayedChunks = append(replayedChunks, chunk)
+			}
+			return false
+		}, time.Now())
+		if err != nil {
+			t.Fatalf("unexpected error on call %d: %v", i+1, err)
+		}
+		if res.Content != "Hello, world" {
+			t.Errorf("expected content %q on call %d, got %q", "Hello, world", i+1, res.Content)
+		}
+		if res.Usage == nil || res.Usage.CompletionTokens != 4 {
+			t.Errorf("unexpected usage on call %d: %+v", i+1, res.Usage)
+		}
+	}
+
+	if n := atomic.LoadInt32(&numRequests); n != 1 {
+		t.Errorf("expected 1 provider request, got %d", n)
+	}
+
+	if len(replayedChunks) != 2 {
+		t.Errorf("expected replay to be chunk-by-chunk, got %v", replayedChunks)
+	}
+
+	// replay mode never calls the provider
+	SetResponseCache(ResponseCacheModeReplay, responseCacheDir)
+
+	_, err := processChatCompletionStream(modelConfig, ClientInfo{}, server.URL, context.Background(), responseCacheTestReq("Say hello."), nil, time.Now())
+	if err != nil {
+		t.Fatalf("unexpected error replaying: %v", err)
+	}
+
+	_, err = processChatCompletionStream(modelConfig, ClientInfo{}, server.URL, context.Background(), responseCacheTestReq("Say goodbye."), nil, time.Now())
+	if !errors.Is(err, ErrResponseCacheMiss) {
+		t.Errorf("expected cache miss error, got %v", err)
+	}
+
+	if n := atomic.LoadInt32(&numRequests); n != 1 {
+		t.Errorf("expected no further provider requests in replay mode, got %d", n)
+	}
+}
+
+func TestResponseCacheSkipsIncompleteStreams(t *testing.T) {
+	var numRequests int32
+	server := newOpenAICompatibleStub(&numRequests)
+	defer server.Close()
+
+	SetResponseCache(ResponseCacheModeCache, t.TempDir())
+	defer SetResponseCache(ResponseCacheModeOff, "")
+
+	modelConfig := &shared.ModelRoleConfig{
+		BaseModelConfig: shared.BaseModelConfig{
+			Provider:  shared.ModelProviderOllama,
+			ModelName: "qwen2.5-coder",
+			BaseUrl:   server.URL,
+		},
+	}
+
+	for i := 0; i < 2; i++ {
+		// stop after the first chunk, before the stream finishes
+		_, err := processChatCompletionStream(modelConfig, ClientInfo{}, server.URL, context.Background(), responseCacheTestReq("Say hello."), func(chunk, buffer string) bool {
+			return true
+		}, time.Now())
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if n := atomic.LoadInt32(&numRequests); n != 2 {
+		t.Errorf("expected stopped stream not to be cached, got %d provider requests", n)
+	}
+}
@@ -26,6 +26,7 @@ type PlanSummaryParams struct {
 	LatestConvoMessageCreatedAt time.Time
 	NumMessages                 int
 	SessionId                   string
+	Branch                      string
 }
 
 func PlanSummary(clients map[string]ClientInfo, config shared.ModelRoleConfig, params PlanSummaryParams, ctx context.Context) (*db.ConvoSummary, *shared.ApiError) {
@@ -65,6 +66,7 @@ func PlanSummary(clients map[string]ClientInfo, config shared.ModelRoleConfig, p
 		ModelStreamId:  params.ModelStreamId,
 		Messages:       messages,
 		SessionId:      params.SessionId,
+		Branch:         params.Branch,
 	})
 
 	if err != nil {
@@ -69,6 +69,10 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/accounts/sign_in", handlers.SignInHandler).Methods("POST")
 	r.HandleFunc(prefix+"/accounts/sign_out", handlers.SignOutHandler).Methods("POST")
 	r.HandleFunc(prefix+"/accounts", handlers.CreateAccountHandler).Methods("POST")
+	r.HandleFunc(prefix+"/accounts/sso/start", handlers.StartSsoHandler).Methods("POST")
+	r.HandleFunc(prefix+"/accounts/sso/sign_in", handlers.SsoSignInHandler).Methods("POST")
+	r.HandleFunc(prefix+"/accounts/sso/device", handlers.StartSsoDeviceHandler).Methods("POST")
+	r.HandleFunc(prefix+"/accounts/sso/device/poll", handlers.PollSsoDeviceHandler).Methods("POST")
 
 	r.HandleFunc(prefix+"/orgs/session", handlers.GetOrgSessionHandler).Methods("GET")
 	r.HandleFunc(prefix+"/orgs", handlers.ListOrgsHandler).Methods("GET")
@@ -78,6 +82,21 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/orgs/users/{userId}", handlers.DeleteOrgUserHandler).Methods("DELETE")
 	r.HandleFunc(prefix+"/orgs/roles", handlers.ListOrgRolesHandler).Methods("GET")
 
+	r.HandleFunc(prefix+"/orgs/sso", handlers.GetSsoConfigHandler).Methods("GET")
+	r.HandleFunc(prefix+"/orgs/sso", handlers.UpdateSsoConfigHandler).Methods("PUT")
+	r.HandleFunc(prefix+"/orgs/sso", handlers.DeleteSsoConfigHandler).Methods("DELETE")
+
+	r.HandleFunc(prefix+"/orgs/exec-policy", handlers.GetOrgExecPolicyHandler).Methods("GET")
+	r.HandleFunc(prefix+"/orgs/exec-policy", handlers.UpdateOrgExecPolicyHandler).Methods("PUT")
+	r.HandleFunc(prefix+"/orgs/exec-policy", handlers.DeleteOrgExecPolicyHandler).Methods("DELETE")
+	r.HandleFunc(prefix+"/exec-policy/check", handlers.CheckExecPolicyHandler).Methods("POST")
+
+	r.HandleFunc(prefix+"/plan-templates", handlers.ListPlanTemplatesHandler).Methods("GET")
+	r.HandleFunc(prefix+"/plan-templates", handlers.CreatePlanTemplateHandler).Methods("POST")
+	r.HandleFunc(prefix+"/plan-templates/{name}", handlers.GetPlanTemplateHandler).Methods("GET")
+	r.HandleFunc(prefix+"/plan-templates/{name}", handlers.UpdatePlanTemplateHandler).Methods("PUT")
+	r.HandleFunc(prefix+"/plan-templates/{name}", handlers.DeletePlanTemplateHandler).Methods("DELETE")
+
 	r.HandleFunc(prefix+"/invites", handlers.InviteUserHandler).Methods("POST")
 	r.HandleFunc(prefix+"/invites/pending", handlers.ListPendingInvitesHandler).Methods("GET")
 	r.HandleFunc(prefix+"/invites/accepted", handlers.ListAcceptedInvitesHandler).Methods("GET")
@@ -98,9 +117,11 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/projects/{projectId}/plans", handlers.CreatePlanHandler).Methods("POST")
 
 	r.HandleFunc(prefix+"/projects/{projectId}/plans", handlers.CreatePlanHandler).Methods("DELETE")
+	r.HandleFunc(prefix+"/projects/{projectId}/plans/import", handlers.ImportPlanHandler).Methods("POST")
 
 	r.HandleFunc(prefix+"/plans/{planId}", handlers.GetPlanHandler).Methods("GET")
 	r.HandleFunc(prefix+"/plans/{planId}", handlers.DeletePlanHandler).Methods("DELETE")
+	r.HandleFunc(prefix+"/plans/{planId}/export", handlers.ExportPlanHandler).Methods("GET")
 
 	r.HandleFunc(prefix+"/plans/{planId}/current_plan/{sha}", handlers.CurrentPlanHandler).Methods("GET")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/current_plan", handlers.CurrentPlanHandler).Methods("GET")
@@ -112,6 +133,7 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/reject_all", handlers.RejectAllChangesHandler).Methods("PATCH")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/reject_file", handlers.RejectFileHandler).Methods("PATCH")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/reject_files", handlers.RejectFilesHandler).Methods("PATCH")
+	r.HandleFunc(prefix+"/plans/{planId}/{branch}/reject_replacements", handlers.RejectReplacementsHandler).Methods("PATCH")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/diffs", handlers.GetPlanDiffsHandler).Methods("GET")
 
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/context", handlers.ListContextHandler).Methods("GET")
@@ -119,6 +141,7 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/context/{contextId}/body", handlers.GetContextBodyHandler).Methods("GET")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/context", handlers.UpdateContextHandler).Methods("PUT")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/context", handlers.DeleteContextHandler).Methods("DELETE")
+	r.HandleFunc(prefix+"/plans/{planId}/{branch}/context/search", handlers.SearchContextHandler).Methods("POST")
 
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/convo", handlers.ListConvoHandler).Methods("GET")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/rewind", handlers.RewindPlanHandler).Methods("PATCH")
@@ -127,6 +150,8 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/plans/{planId}/branches", handlers.ListBranchesHandler).Methods("GET")
 	r.HandleFunc(prefix+"/plans/{planId}/branches/{branch}", handlers.DeleteBranchHandler).Methods("DELETE")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/branches", handlers.CreateBranchHandler).Methods("POST")
+	r.HandleFunc(prefix+"/plans/{planId}/{branch}/merge", handlers.MergeBranchHandler).Methods("POST")
+	r.HandleFunc(prefix+"/plans/{planId}/{branch}/cherry_pick", handlers.CherryPickHandler).Methods("POST")
 
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/settings", handlers.GetSettingsHandler).Methods("GET")
 	r.HandleFunc(prefix+"/plans/{planId}/{branch}/settings", handlers.UpdateSettingsHandler).Methods("PUT")
@@ -140,10 +165,20 @@ func addApiRoutes(r *mux.Router, prefix string) {
 	r.HandleFunc(prefix+"/custom_models", handlers.CreateCustomModelHandler).Methods("POST")
 	r.HandleFunc(prefix+"/custom_models/{modelId}", handlers.DeleteAvailableModelHandler).Methods("DELETE")
 
+	r.HandleFunc(prefix+"/usage/report", handlers.GetUsageReportHandler).Methods("POST")
+	r.HandleFunc(prefix+"/usage/log", handlers.GetUsageLogHandler).Methods("POST")
+
+	r.HandleFunc(prefix+"/orgs/budgets", handlers.GetOrgBudgetsHandler).Methods("GET")
+	r.HandleFunc(prefix+"/orgs/budgets", handlers.UpdateOrgBudgetsHandler).Methods("PUT")
+	r.HandleFunc(prefix+"/orgs/budgets", handlers.DeleteOrgBudgetsHandler).Methods("DELETE")
+
 	r.HandleFunc(prefix+"/model_sets", handlers.ListModelPacksHandler).Methods("GET")
 	r.HandleFunc(prefix+"/model_sets", handlers.CreateModelPackHandler).Methods("POST")
+	r.HandleFunc(prefix+"/model_sets/{setId}", handlers.UpdateModelPackHandler).Methods("PUT")
 	r.HandleFunc(prefix+"/model_sets/{setId}", handlers.DeleteModelPackHandler).Methods("DELETE")
 
+	r.HandleFunc(prefix+"/models/sync", handlers.SyncModelsHandler).Methods("POST")
+
 	r.HandleFunc(prefix+"/default_settings", handlers.GetDefaultSettingsHandler).Methods("GET")
 	r.HandleFunc(prefix+"/default_settings", handlers.UpdateDefaultSettingsHandler).Methods("PUT")
 
@@ -155,6 +190,16 @@ func addApiRoutes(r *mux.Router, prefix string) {
 
 	r.HandleFunc(prefix+"/default_plan_config", handlers.GetDefaultPlanConfigHandler).Methods("GET")
 	r.HandleFunc(prefix+"/default_plan_config", handlers.UpdateDefaultPlanConfigHandler).Methods("PUT")
+
+	r.HandleFunc(prefix+"/access_tokens", handlers.ListAccessTokensHandler).Methods("GET")
+	r.HandleFunc(prefix+"/access_tokens", handlers.CreateAccessTokenHandler).Methods("POST")
+	r.HandleFunc(prefix+"/access_tokens/{tokenId}", handlers.RevokeAccessTokenHandler).Methods("DELETE")
+
+	r.HandleFunc(prefix+"/webhooks", handlers.ListWebhooksHandler).Methods("GET")
+	r.HandleFunc(prefix+"/webhooks", handlers.CreateWebhookHandler).Methods("POST")
+	r.HandleFunc(prefix+"/webhooks/{webhookId}", handlers.DeleteWebhookHandler).Methods("DELETE")
+	r.HandleFunc(prefix+"/webhooks/{webhookId}/deliveries", handlers.ListWebhookDeliveriesHandler).Methods("GET")
+	r.HandleFunc(prefix+"/webhooks/{webhookId}/test", handlers.TestWebhookHandler).Methods("POST")
 }
 
 func addProxyableApiRoutes(r *mux.Router, prefix string) {
@@ -0,0 +1,244 @@
+package sso
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	shared "plandex-shared"
+)
+
+var (
+	ErrAuthorizationPending = errors.New("authorization pending")
+	ErrSlowDown             = errors.New("slow down")
+)
+
+// ValidateRedirectUri only allows the CLI's loopback callback (RFC 8252), so an authorization code can't be sent anywhere else
+func ValidateRedirectUri(redirectUri string) error {
+	u, err := url.Parse(redirectUri)
+	if err != nil {
+		return fmt.Errorf("invalid redirect uri: %v", err)
+	}
+
+	if u.Scheme != "http" {
+		return fmt.Errorf("redirect uri must be an http loopback url")
+	}
+
+	host := u.Hostname()
+	if host != "localhost" {
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			return fmt.Errorf("redirect uri must be an http loopback url")
+		}
+	}
+
+	return nil
+}
+
+func scopes(config Config) string {
+	if config.Scopes == "" {
+		return shared.SsoDefaultScopes
+	}
+	return config.Scopes
+}
+
+// AuthorizationUrl builds the url the user signs in at for the authorization code flow
+func AuthorizationUrl(ctx context.Context, config Config, redirectUri, state, codeChallenge, nonce string) (string, error) {
+	provider, err := Discover(ctx, config.IssuerUrl)
+	if err != nil {
+		return "", err
+	}
+
+	u, err := url.Parse(provider.AuthorizationEndpoint)
+	if err != nil {
+		return "", fmt.Errorf("invalid authorization endpoint: %v", err)
+	}
+
+	q := u.Query()
+	q.Set("response_type", "code")
+	q.Set("client_id", config.ClientId)
+	q.Set("redirect_uri", redirectUri)
+	q.Set("scope", scopes(config))
+	q.Set("state", state)
+	q.Set("nonce", nonce)
+	q.Set("code_challenge", codeChallenge)
+	q.Set("code_challenge_method", "S256")
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// ExchangeCode redeems an authorization code and returns the verified ID token claims
+func ExchangeCode(ctx context.Context, config Config, code, codeVerifier, redirectUri, nonce string) (*Claims, error) {
+	provider, err := Discover(ctx, config.IssuerUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("code_verifier", codeVerifier)
+	form.Set("redirect_uri", redirectUri)
+
+	idToken, err := requestToken(ctx, provider, config, form)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifyIdToken(ctx, provider, config.ClientId, idToken, nonce)
+}
+
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationUri         string `json:"verification_uri"`
+	VerificationUriComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// StartDeviceAuthorization starts the device flow, returning the code for the user to enter at the verification url
+func StartDeviceAuthorization(ctx context.Context, config Config) (*DeviceAuthorization, error) {
+	provider, err := Discover(ctx, config.IssuerUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if !provider.SupportsDeviceFlow() {
+		return nil, fmt.Errorf("the sso provider doesn't support device authorization")
+	}
+
+	form := url.Values{}
+	form.Set("scope", scopes(config))
+
+	var auth DeviceAuthorization
+	err = postForm(ctx, provider, config, provider.DeviceAuthorizationEndpoint, form, &auth)
+	if err != nil {
+		return nil, fmt.Errorf("error starting device authorization: %v", err)
+	}
+
+	if auth.DeviceCode == "" || auth.UserCode == "" || auth.VerificationUri == "" {
+		return nil, fmt.Errorf("invalid device authorization response")
+	}
+
+	// RFC 8628 default
+	if auth.Interval == 0 {
+		auth.Interval = 5
+	}
+
+	return &auth, nil
+}
+
+// PollDeviceAuthorization checks whether the user has approved a device authorization. It returns ErrAuthorizationPending or ErrSlowDown until they have.
+func PollDeviceAuthorization(ctx context.Context, config Config, deviceCode string) (*Claims, error) {
+	provider, err := Discover(ctx, config.IssuerUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "urn:ietf:params:oauth:grant-type:device_code")
+	form.Set("device_code", deviceCode)
+
+	idToken, err := requestToken(ctx, provider, config, form)
+	if err != nil {
+		return nil, err
+	}
+
+	return verifyIdToken(ctx, provider, config.ClientId, idToken, "")
+}
+
+type oauthError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *oauthError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+func requestToken(ctx context.Context, provider *Provider, config Config, form url.Values) (string, error) {
+	var res struct {
+		IdToken string `json:"id_token"`
+	}
+
+	err := postForm(ctx, provider, config, provider.TokenEndpoint, form, &res)
+	if err != nil {
+		var oauthErr *oauthError
+		if errors.As(err, &oauthErr) {
+			switch oauthErr.Code {
+			case "authorization_pending":
+				return "", ErrAuthorizationPending
+			case "slow_down":
+				return "", ErrSlowDown
+			}
+		}
+		return "", fmt.Errorf("error requesting token: %v", err)
+	}
+
+	if res.IdToken == "" {
+		return "", fmt.Errorf("token response is missing an id token")
+	}
+
+	return res.IdToken, nil
+}
+
+// postForm authenticates as the client with client_secret_basic unless the provider only supports client_secret_post. Public clients just send their client id.
+func postForm(ctx context.Context, provider *Provider, config Config, endpoint string, form url.Values, v any) error {
+	useBasicAuth := false
+	if config.ClientSecret == "" {
+		form.Set("client_id", config.ClientId)
+	} else if len(provider.TokenEndpointAuthMethodsSupported) == 0 || contains(provider.TokenEndpointAuthMethodsSupported, "client_secret_basic") {
+		useBasicAuth = true
+	} else {
+		form.Set("client_id", config.ClientId)
+		form.Set("client_secret", config.ClientSecret)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("error creating request: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	if useBasicAuth {
+		req.SetBasicAuth(url.QueryEscape(config.ClientId), url.QueryEscape(config.ClientSecret))
+	}
+
+	res, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending request: %v", err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, maxResponseBytes))
+	if err != nil {
+		return fmt.Errorf("error reading response: %v", err)
+	}
+
+	if res.StatusCode != http.StatusOK {
+		var oauthErr oauthError
+		if json.Unmarshal(body, &oauthErr) == nil && oauthErr.Code != "" {
+			return &oauthErr
+		}
+		return fmt.Errorf("unexpected status %d from %s", res.StatusCode, endpoint)
+	}
+
+	err = json.Unmarshal(body, v)
+	if err != nil {
+		return fmt.Errorf("error unmarshalling response: %v", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,342 @@
+package sso
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+// allowed clock skew between the server and the provider
+const clockLeeway = time.Minute
+
+// Claims are the ID token claims used to sign a user in. All claims are kept so any of them can be used for role mapping.
+type Claims struct {
+	Subject       string
+	Email         string
+	EmailVerified *bool
+	Name          string
+
+	raw map[string]any
+}
+
+// Values returns a claim's string values. Dots in the claim name select nested claims, like "realm_access.roles".
+func (c *Claims) Values(claim string) []string {
+	var v any = c.raw
+	for _, part := range strings.Split(claim, ".") {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil
+		}
+		v = m[part]
+	}
+
+	switch v := v.(type) {
+	case string:
+		// some providers send space-separated lists
+		return strings.Fields(v)
+	case []any:
+		var values []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwkSet struct {
+	Keys []jwk `json:"keys"`
+}
+
+var (
+	keySetsMu sync.Mutex
+	keySets   = map[string]map[string]crypto.PublicKey{}
+)
+
+// getSigningKey returns the provider's key for a kid, refetching the JWKS once if it isn't cached so rotated keys are picked up
+func getSigningKey(ctx context.Context, provider *Provider, kid string) (crypto.PublicKey, error) {
+	keySetsMu.Lock()
+	keys := keySets[provider.JwksUri]
+	keySetsMu.Unlock()
+
+	key := findKey(keys, kid)
+	if key != nil {
+		return key, nil
+	}
+
+	var set jwkSet
+	err := getJson(ctx, provider.JwksUri, &set)
+	if err != nil {
+		return nil, fmt.Errorf("error fetching jwks: %v", err)
+	}
+
+	keys = map[string]crypto.PublicKey{}
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		publicKey, err := k.publicKey()
+		if err != nil {
+			// skip key types we can't use rather than failing on keys that aren't needed
+			continue
+		}
+		keys[k.Kid] = publicKey
+	}
+
+	keySetsMu.Lock()
+	keySets[provider.JwksUri] = keys
+	keySetsMu.Unlock()
+
+	key = findKey(keys, kid)
+	if key == nil {
+		return nil, fmt.Errorf("no signing key found for kid %q", kid)
+	}
+
+	return key, nil
+}
+
+func findKey(keys map[string]crypto.PublicKey, kid string) crypto.PublicKey {
+	if key, ok := keys[kid]; ok {
+		return key
+	}
+
+	// tokens can omit the kid when there's only one key
+	if kid == "" && len(keys) == 1 {
+		for _, key := range keys {
+			return key
+		}
+	}
+
+	return nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		if !e.IsInt64() {
+			return nil, fmt.Errorf("invalid rsa exponent")
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+}
+
+func decodeBigInt(s string) (*big.Int, error) {
+	bytes, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key encoding: %v", err)
+	}
+	return new(big.Int).SetBytes(bytes), nil
+}
+
+// verifyIdToken checks an ID token's signature and standard claims. The nonce is only checked when one was sent with the authorization request (the device flow doesn't have one).
+func verifyIdToken(ctx context.Context, provider *Provider, clientId, rawIdToken, nonce string) (*Claims, error) {
+	parts := strings.Split(rawIdToken, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("malformed id token")
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}
+	err := decodeSegment(parts[0], &header)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token header: %v", err)
+	}
+
+	key, err := getSigningKey(ctx, provider, header.Kid)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token signature encoding: %v", err)
+	}
+
+	err = verifySignature(header.Alg, key, parts[0]+"."+parts[1], signature)
+	if err != nil {
+		return nil, err
+	}
+
+	var raw map[string]any
+	err = decodeSegment(parts[1], &raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id token claims: %v", err)
+	}
+
+	claims := &Claims{raw: raw}
+	claims.Subject, _ = raw["sub"].(string)
+	claims.Email, _ = raw["email"].(string)
+	claims.Name, _ = raw["name"].(string)
+
+	switch v := raw["email_verified"].(type) {
+	case bool:
+		claims.EmailVerified = &v
+	case string:
+		// some providers send a string
+		verified := v == "true"
+		claims.EmailVerified = &verified
+	}
+
+	if iss, _ := raw["iss"].(string); iss != provider.Issuer {
+		return nil, fmt.Errorf("id token issuer %q doesn't match %q", iss, provider.Issuer)
+	}
+
+	audiences := claims.Values("aud")
+	if !contains(audiences, clientId) {
+		return nil, fmt.Errorf("id token audience doesn't include the client id")
+	}
+	if len(audiences) > 1 {
+		if azp, _ := raw["azp"].(string); azp != clientId {
+			return nil, fmt.Errorf("id token authorized party doesn't match the client id")
+		}
+	}
+
+	now := time.Now()
+
+	exp, ok := raw["exp"].(float64)
+	if !ok {
+		return nil, fmt.Errorf("id token is missing an expiration")
+	}
+	if now.After(time.Unix(int64(exp), 0).Add(clockLeeway)) {
+		return nil, fmt.Errorf("id token is expired")
+	}
+
+	if nbf, ok := raw["nbf"].(float64); ok && now.Add(clockLeeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, fmt.Errorf("id token isn't valid yet")
+	}
+
+	if nonce != "" {
+		if claimNonce, _ := raw["nonce"].(string); claimNonce != nonce {
+			return nil, fmt.Errorf("id token nonce doesn't match")
+		}
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("id token is missing a subject")
+	}
+
+	return claims, nil
+}
+
+func verifySignature(alg string, key crypto.PublicKey, signed string, signature []byte) error {
+	var hash crypto.Hash
+	switch alg {
+	case "RS256", "ES256":
+		hash = crypto.SHA256
+	case "RS384", "ES384":
+		hash = crypto.SHA384
+	case "RS512":
+		hash = crypto.SHA512
+	default:
+		// this also rejects "none" and symmetric algorithms
+		return fmt.Errorf("unsupported id token algorithm: %s", alg)
+	}
+
+	hasher := hash.New()
+	hasher.Write([]byte(signed))
+	digest := hasher.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("id token algorithm %s doesn't match the rsa signing key", alg)
+		}
+		err := rsa.VerifyPKCS1v15(key, hash, digest, signature)
+		if err != nil {
+			return fmt.Errorf("invalid id token signature")
+		}
+
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("id token algorithm %s doesn't match the ec signing key", alg)
+		}
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid id token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf("invalid id token signature")
+		}
+
+	default:
+		return fmt.Errorf("unsupported signing key type: %T", key)
+	}
+
+	return nil
+}
+
+func decodeSegment(segment string, v any) error {
+	bytes, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(bytes, v)
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,46 @@
+package sso
+
+import (
+	shared "plandex-shared"
+)
+
+// built-in roles from most to least privileged -- custom roles rank below them
+var rolePrecedence = []string{"owner", "admin", "member"}
+
+// MapRole returns the org role name that the claim's values map to, or "" if none of them are mapped. When several values map to different roles, the most privileged role wins.
+func MapRole(claims *Claims, roleClaim string, mapping shared.SsoRoleMapping) string {
+	if roleClaim == "" || len(mapping) == 0 {
+		return ""
+	}
+
+	var role string
+	for _, value := range claims.Values(roleClaim) {
+		mapped, ok := mapping[value]
+		if !ok {
+			continue
+		}
+		if role == "" || outranks(mapped, role) {
+			role = mapped
+		}
+	}
+
+	return role
+}
+
+func outranks(a, b string) bool {
+	rankA, rankB := rank(a), rank(b)
+	if rankA != rankB {
+		return rankA < rankB
+	}
+	// keep the result stable between custom roles
+	return a < b
+}
+
+func rank(role string) int {
+	for i, r := range rolePrecedence {
+		if r == role {
+			return i
+		}
+	}
+	return len(rolePrecedence)
+}
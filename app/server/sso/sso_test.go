@@ -0,0 +1,428 @@
+package sso
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+)
+
+type authRequest struct {
+	codeChallenge string
+	nonce         string
+	redirectUri   string
+}
+
+// mockIssuer is a minimal OIDC provider: discovery, JWKS, an authorize endpoint that approves immediately, a token endpoint that checks PKCE, and device authorization
+type mockIssuer struct {
+	server       *httptest.Server
+	key          *rsa.PrivateKey
+	clientId     string
+	clientSecret string
+	claims       map[string]any
+
+	mu             sync.Mutex
+	codes          map[string]authRequest
+	deviceApproved bool
+}
+
+func newMockIssuer(t *testing.T) *mockIssuer {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("error generating key: %v", err)
+	}
+
+	issuer := &mockIssuer{
+		key:          key,
+		clientId:     "plandex",
+		clientSecret: "secret",
+		claims: map[string]any{
+			"sub":            "user-1",
+			"email":          "dev@example.com",
+			"email_verified": true,
+			"name":           "Dev",
+			"groups":         []string{"engineering", "plandex-admins"},
+		},
+		codes: map[string]authRequest{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", issuer.handleDiscovery)
+	mux.HandleFunc("/jwks", issuer.handleJwks)
+	mux.HandleFunc("/authorize", issuer.handleAuthorize)
+	mux.HandleFunc("/token", issuer.handleToken)
+	mux.HandleFunc("/device", issuer.handleDevice)
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+
+	return issuer
+}
+
+func (m *mockIssuer) config() Config {
+	return Config{
+		IssuerUrl:    m.server.URL,
+		ClientId:     m.clientId,
+		ClientSecret: m.clientSecret,
+	}
+}
+
+func (m *mockIssuer) handleDiscovery(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                        m.server.URL,
+		"authorization_endpoint":        m.server.URL + "/authorize",
+		"token_endpoint":                m.server.URL + "/token",
+		"device_authorization_endpoint": m.server.URL + "/device",
+		"jwks_uri":                      m.server.URL + "/jwks",
+	})
+}
+
+func (m *mockIssuer) handleJwks(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"use": "sig",
+			"n":   base64.RawURLEncoding.EncodeToString(m.key.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(m.key.E)).Bytes()),
+		}},
+	})
+}
+
+func (m *mockIssuer) handleAuthorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != m.clientId || q.Get("code_challenge_method") != "S256" || !strings.Contains(q.Get("scope"), "openid") {
+		http.Error(w, "invalid request", http.StatusBadRequest)
+		return
+	}
+
+	m.mu.Lock()
+	code := "code-" + q.Get("state")
+	m.codes[code] = authRequest{
+		codeChallenge: q.Get("code_challenge"),
+		nonce:         q.Get("nonce"),
+		redirectUri:   q.Get("redirect_uri"),
+	}
+	m.mu.Unlock()
+
+	redirect, _ := url.Parse(q.Get("redirect_uri"))
+	rq := redirect.Query()
+	rq.Set("code", code)
+	rq.Set("state", q.Get("state"))
+	redirect.RawQuery = rq.Encode()
+
+	http.Redirect(w, r, redirect.String(), http.StatusFound)
+}
+
+func (m *mockIssuer) handleToken(w http.ResponseWriter, r *http.Request) {
+	clientId, clientSecret, ok := r.BasicAuth()
+	if !ok || clientId != m.clientId || clientSecret != m.clientSecret {
+		oauthErrorResponse(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	r.ParseForm()
+
+	switch r.Form.Get("grant_type") {
+	case "authorization_code":
+		m.mu.Lock()
+		req, ok := m.codes[r.Form.Get("code")]
+		delete(m.codes, r.Form.Get("code"))
+		m.mu.Unlock()
+
+		if !ok || req.redirectUri != r.Form.Get("redirect_uri") || shared.SsoCodeChallenge(r.Form.Get("code_verifier")) != req.codeChallenge {
+			oauthErrorResponse(w, http.StatusBadRequest, "invalid_grant")
+			return
+		}
+
+		m.writeIdToken(w, req.nonce)
+
+	case "urn:ietf:params:oauth:grant-type:device_code":
+		m.mu.Lock()
+		approved := m.deviceApproved
+		m.mu.Unlock()
+
+		if r.Form.Get("device_code") != "device-code" {
+			oauthErrorResponse(w, http.StatusBadRequest, "invalid_grant")
+			return
+		}
+
+		if !approved {
+			oauthErrorResponse(w, http.StatusBadRequest, "authorization_pending")
+			return
+		}
+
+		m.writeIdToken(w, "")
+
+	default:
+		oauthErrorResponse(w, http.StatusBadRequest, "unsupported_grant_type")
+	}
+}
+
+func (m *mockIssuer) handleDevice(w http.ResponseWriter, r *http.Request) {
+	json.NewEncoder(w).Encode(map[string]any{
+		"device_code":      "device-code",
+		"user_code":        "ABCD-EFGH",
+		"verification_uri": m.server.URL + "/activate",
+		"expires_in":       600,
+	})
+}
+
+func (m *mockIssuer) writeIdToken(w http.ResponseWriter, nonce string) {
+	claims := m.baseClaims()
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+
+	json.NewEncoder(w).Encode(map[string]any{
+		"access_token": "access-token",
+		"token_type":   "Bearer",
+		"id_token":     signToken(m.key, "key-1", claims),
+	})
+}
+
+func (m *mockIssuer) baseClaims() map[string]any {
+	claims := map[string]any{
+		"iss": m.server.URL,
+		"aud": m.clientId,
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for k, v := range m.claims {
+		claims[k] = v
+	}
+	return claims
+}
+
+func oauthErrorResponse(w http.ResponseWriter, status int, code string) {
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+func signToken(key *rsa.PrivateKey, kid string, claims map[string]any) string {
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": kid})
+	payload, _ := json.Marshal(claims)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+	digest := sha256.Sum256([]byte(signed))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+// authorize follows the authorization url like a browser would, returning the code sent to the redirect uri
+func authorize(t *testing.T, authUrl, state string) string {
+	client := &http.Client{
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	res, err := client.Get(authUrl)
+	if err != nil {
+		t.Fatalf("error following authorization url: %v", err)
+	}
+	res.Body.Close()
+
+	if res.StatusCode != http.StatusFound {
+		t.Fatalf("expected a redirect from the authorize endpoint, got %d", res.StatusCode)
+	}
+
+	location, err := url.Parse(res.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("error parsing redirect: %v", err)
+	}
+
+	if location.Query().Get("state") != state {
+		t.Fatalf("expected state %q, got %q", state, location.Query().Get("state"))
+	}
+
+	return location.Query().Get("code")
+}
+
+func TestAuthorizationCodeFlow(t *testing.T) {
+	issuer := newMockIssuer(t)
+	ctx := context.Background()
+
+	redirectUri := "http://127.0.0.1:8123/callback"
+	verifier := "verifier-0123456789-0123456789-0123456789"
+
+	authUrl, err := AuthorizationUrl(ctx, issuer.config(), redirectUri, "state-1", shared.SsoCodeChallenge(verifier), "nonce-1")
+	if err != nil {
+		t.Fatalf("error building authorization url: %v", err)
+	}
+
+	code := authorize(t, authUrl, "state-1")
+
+	claims, err := ExchangeCode(ctx, issuer.config(), code, verifier, redirectUri, "nonce-1")
+	if err != nil {
+		t.Fatalf("error exchanging code: %v", err)
+	}
+
+	if claims.Email != "dev@example.com" || claims.Name != "Dev" || claims.Subject != "user-1" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+	if claims.EmailVerified == nil || !*claims.EmailVerified {
+		t.Error("expected email to be verified")
+	}
+
+	// codes are single-use
+	if _, err := ExchangeCode(ctx, issuer.config(), code, verifier, redirectUri, "nonce-1"); err == nil {
+		t.Error("expected error reusing a code")
+	}
+
+	authUrl, _ = AuthorizationUrl(ctx, issuer.config(), redirectUri, "state-2", shared.SsoCodeChallenge(verifier), "nonce-2")
+	code = authorize(t, authUrl, "state-2")
+	if _, err := ExchangeCode(ctx, issuer.config(), code, "wrong-verifier", redirectUri, "nonce-2"); err == nil {
+		t.Error("expected error with the wrong code verifier")
+	}
+
+	authUrl, _ = AuthorizationUrl(ctx, issuer.config(), redirectUri, "state-3", shared.SsoCodeChallenge(verifier), "nonce-3")
+	code = authorize(t, authUrl, "state-3")
+	if _, err := ExchangeCode(ctx, issuer.config(), code, verifier, redirectUri, "other-nonce"); err == nil {
+		t.Error("expected error with a mismatched nonce")
+	}
+
+	config := issuer.config()
+	config.ClientSecret = "wrong"
+	authUrl, _ = AuthorizationUrl(ctx, config, redirectUri, "state-4", shared.SsoCodeChallenge(verifier), "nonce-4")
+	code = authorize(t, authUrl, "state-4")
+	if _, err := ExchangeCode(ctx, config, code, verifier, redirectUri, "nonce-4"); err == nil {
+		t.Error("expected error with the wrong client secret")
+	}
+}
+
+func TestDeviceFlow(t *testing.T) {
+	issuer := newMockIssuer(t)
+	ctx := context.Background()
+
+	auth, err := StartDeviceAuthorization(ctx, issuer.config())
+	if err != nil {
+		t.Fatalf("error starting device authorization: %v", err)
+	}
+
+	if auth.UserCode != "ABCD-EFGH" || auth.Interval != 5 {
+		t.Errorf("unexpected device authorization: %+v", auth)
+	}
+
+	_, err = PollDeviceAuthorization(ctx, issuer.config(), auth.DeviceCode)
+	if err != ErrAuthorizationPending {
+		t.Fatalf("expected authorization pending, got %v", err)
+	}
+
+	issuer.mu.Lock()
+	issuer.deviceApproved = true
+	issuer.mu.Unlock()
+
+	claims, err := PollDeviceAuthorization(ctx, issuer.config(), auth.DeviceCode)
+	if err != nil {
+		t.Fatalf("error polling device authorization: %v", err)
+	}
+	if claims.Email != "dev@example.com" {
+		t.Errorf("unexpected claims: %+v", claims)
+	}
+
+	if _, err := PollDeviceAuthorization(ctx, issuer.config(), "unknown"); err == nil || err == ErrAuthorizationPending {
+		t.Errorf("expected error polling an unknown device code, got %v", err)
+	}
+}
+
+func TestVerifyIdToken(t *testing.T) {
+	issuer := newMockIssuer(t)
+	ctx := context.Background()
+
+	provider, err := Discover(ctx, issuer.server.URL)
+	if err != nil {
+		t.Fatalf("error discovering provider: %v", err)
+	}
+
+	_, err = verifyIdToken(ctx, provider, issuer.clientId, signToken(issuer.key, "key-1", issuer.baseClaims()), "")
+	if err != nil {
+		t.Fatalf("expected valid token, got %v", err)
+	}
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	tests := []struct {
+		name   string
+		key    *rsa.PrivateKey
+		modify func(claims map[string]any)
+	}{
+		{"wrong key", otherKey, nil},
+		{"expired", issuer.key, func(c map[string]any) { c["exp"] = time.Now().Add(-time.Hour).Unix() }},
+		{"wrong audience", issuer.key, func(c map[string]any) { c["aud"] = "someone-else" }},
+		{"wrong issuer", issuer.key, func(c map[string]any) { c["iss"] = "https://evil.example.com" }},
+		{"multiple audiences without azp", issuer.key, func(c map[string]any) { c["aud"] = []string{issuer.clientId, "other"} }},
+	}
+
+	for _, test := range tests {
+		claims := issuer.baseClaims()
+		if test.modify != nil {
+			test.modify(claims)
+		}
+		if _, err := verifyIdToken(ctx, provider, issuer.clientId, signToken(test.key, "key-1", claims), ""); err == nil {
+			t.Errorf("%s: expected error", test.name)
+		}
+	}
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none"}`))
+	payload, _ := json.Marshal(issuer.baseClaims())
+	unsigned := header + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+	if _, err := verifyIdToken(ctx, provider, issuer.clientId, unsigned, ""); err == nil {
+		t.Error("expected error for an unsigned token")
+	}
+}
+
+func TestMapRole(t *testing.T) {
+	claims := &Claims{raw: map[string]any{
+		"groups":       []any{"engineering", "plandex-admins"},
+		"realm_access": map[string]any{"roles": []any{"plandex-owner"}},
+		"role":         "member",
+	}}
+
+	mapping := shared.SsoRoleMapping{
+		"engineering":    "member",
+		"plandex-admins": "admin",
+		"plandex-owner":  "owner",
+	}
+
+	if role := MapRole(claims, "groups", mapping); role != "admin" {
+		t.Errorf("expected the most privileged role, got %q", role)
+	}
+	if role := MapRole(claims, "realm_access.roles", mapping); role != "owner" {
+		t.Errorf("expected nested claim to map to owner, got %q", role)
+	}
+	if role := MapRole(claims, "role", mapping); role != "" {
+		t.Errorf("expected unmapped value to map to nothing, got %q", role)
+	}
+	if role := MapRole(claims, "", mapping); role != "" {
+		t.Errorf("expected no role without a role claim, got %q", role)
+	}
+}
+
+func TestValidateRedirectUri(t *testing.T) {
+	for _, uri := range []string{"http://127.0.0.1:5000/callback", "http://localhost:5000/callback", "http://[::1]:5000/callback"} {
+		if err := ValidateRedirectUri(uri); err != nil {
+			t.Errorf("expected %s to be valid, got %v", uri, err)
+		}
+	}
+
+	for _, uri := range []string{"https://127.0.0.1/callback", "http://example.com/callback", "http://10.0.0.1/callback"} {
+		if err := ValidateRedirectUri(uri); err == nil {
+			t.Errorf("expected %s to be invalid", uri)
+		}
+	}
+}
@@ -94,7 +94,7 @@ func GetCommentSymbols(lang shared.Language) (string, string) {
 		return "//", ""
 	case shared.LanguageBash, shared.LanguageDockerfile, shared.LanguageElixir, shared.LanguageHcl, shared.LanguagePython, shared.LanguageRuby, shared.LanguageToml, shared.LanguageYaml:
 		return "#", ""
-	case shared.LanguageLua, shared.LanguageElm:
+	case shared.LanguageLua, shared.LanguageElm, shared.LanguageSql:
 		return "--", ""
 	case shared.LanguageCss:
 		return "/*", "*/"
@@ -102,7 +102,7 @@ func GetCommentSymbols(lang shared.Language) (string, string) {
 		return "<!--", "-->"
 	case shared.LanguageOCaml:
 		return "(*", "*)"
-	case shared.LanguageSvelte, shared.LanguageJsx, shared.LanguageTsx, shared.LanguageJson:
+	case shared.LanguageSvelte, shared.LanguageVue, shared.LanguageJsx, shared.LanguageTsx, shared.LanguageJson:
 		return "", "" // comments are either not allowed or correct symbols depend on the context
 	}
 
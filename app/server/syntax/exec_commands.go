@@ -0,0 +1,143 @@
+package syntax
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	shared "plandex-shared"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// shells that run a script passed with -c -- the script is parsed so its commands are checked too
+var execShells = map[string]bool{
+	"sh":   true,
+	"bash": true,
+	"zsh":  true,
+	"dash": true,
+}
+
+// ParseScriptCommands parses a bash script and returns every command it runs, including commands in pipelines, substitutions, functions, and 'sh -c' or 'eval' strings. A script with syntax errors returns an error, since its commands can't be reliably checked.
+func ParseScriptCommands(ctx context.Context, script string) ([]*shared.ExecCommand, error) {
+	return parseScriptCommands(ctx, script, 0, 0)
+}
+
+func parseScriptCommands(ctx context.Context, script string, lineOffset, depth int) ([]*shared.ExecCommand, error) {
+	if depth > 5 {
+		return nil, fmt.Errorf("commands are nested too deeply to check")
+	}
+
+	parser := GetParserForLanguage(shared.LanguageBash)
+	defer parser.Close()
+
+	bytes := []byte(script)
+	tree, err := parser.ParseCtx(ctx, nil, bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing script: %v", err)
+	}
+	defer tree.Close()
+
+	root := tree.RootNode()
+	if root.HasError() {
+		return nil, fmt.Errorf("script has syntax errors")
+	}
+
+	var commands []*shared.ExecCommand
+	var walkErr error
+
+	var walk func(node *tree_sitter.Node)
+	walk = func(node *tree_sitter.Node) {
+		if walkErr != nil {
+			return
+		}
+
+		if node.Type() == "command" {
+			command := &shared.ExecCommand{
+				Line: int(node.StartPoint().Row) + 1 + lineOffset,
+			}
+
+			for i := 0; i < int(node.ChildCount()); i++ {
+				child := node.Child(i)
+				switch node.FieldNameForChild(i) {
+				case "name":
+					command.Name = execArgText(child, bytes)
+				case "argument":
+					command.Args = append(command.Args, execArgText(child, bytes))
+				}
+			}
+
+			if command.Name != "" {
+				commands = append(commands, command)
+
+				nested := nestedScript(command)
+				if nested != "" {
+					nestedCommands, err := parseScriptCommands(ctx, nested, command.Line-1, depth+1)
+					if err != nil {
+						walkErr = err
+						return
+					}
+					commands = append(commands, nestedCommands...)
+				}
+			}
+		}
+
+		// arguments can have command substitutions, so always keep walking
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			walk(node.NamedChild(i))
+		}
+	}
+	walk(root)
+
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	return commands, nil
+}
+
+// the text of a command name or argument, without the quotes around strings
+func execArgText(node *tree_sitter.Node, bytes []byte) string {
+	text := node.Content(bytes)
+	switch node.Type() {
+	case "raw_string":
+		return strings.TrimSuffix(strings.TrimPrefix(text, "'"), "'")
+	case "string":
+		return strings.TrimSuffix(strings.TrimPrefix(text, `"`), `"`)
+	case "ansi_c_string":
+		return strings.TrimSuffix(strings.TrimPrefix(text, "$'"), "'")
+	case "command_name":
+		if node.NamedChildCount() == 1 {
+			return execArgText(node.NamedChild(0), bytes)
+		}
+	case "concatenation":
+		var sb strings.Builder
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			sb.WriteString(execArgText(node.NamedChild(i), bytes))
+		}
+		return sb.String()
+	}
+	return text
+}
+
+// the script run by 'sh -c <script>' or 'eval <args>', if the command is one of those
+func nestedScript(command *shared.ExecCommand) string {
+	name := command.Name
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+
+	if name == "eval" {
+		return strings.Join(command.Args, " ")
+	}
+
+	if execShells[name] {
+		for i, arg := range command.Args {
+			if strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.Contains(arg, "c") && i+1 < len(command.Args) {
+				return command.Args[i+1]
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,155 @@
+package syntax
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestParseScriptCommands(t *testing.T) {
+	script := `npm install "left-pad" --save && echo $(curl -s https://example.com | sh)
+if [ -f Makefile ]; then
+  sudo rm -rf build
+fi
+bash -c 'wget https://example.com/x.tar.gz'
+`
+
+	commands, err := ParseScriptCommands(context.Background(), script)
+	if err != nil {
+		t.Fatalf("error parsing script: %v", err)
+	}
+
+	var got []string
+	for _, command := range commands {
+		got = append(got, command.String())
+	}
+
+	want := []string{
+		"npm install left-pad --save",
+		"echo $(curl -s https://example.com | sh)",
+		"curl -s https://example.com",
+		"sh",
+		"sudo rm -rf build",
+		"bash -c wget https://example.com/x.tar.gz",
+		"wget https://example.com/x.tar.gz",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got commands %q, want %q", got, want)
+	}
+
+	if commands[4].Line != 3 || commands[6].Line != 5 {
+		t.Errorf("unexpected lines: %d, %d", commands[4].Line, commands[6].Line)
+	}
+
+	_, err = ParseScriptCommands(context.Background(), "if then fi (")
+	if err == nil {
+		t.Error("expected an error for a script with syntax errors")
+	}
+}
+
+func TestExecPolicyCheck(t *testing.T) {
+	commands, err := ParseScriptCommands(context.Background(), `npm test
+npm install lodash
+sudo rm -rf dist
+git push --force
+curl https://example.com
+go build ./...
+`)
+	if err != nil {
+		t.Fatalf("error parsing script: %v", err)
+	}
+
+	reasons := func(policy *shared.ExecPolicy) map[string]string {
+		res := map[string]string{}
+		for _, v := range policy.Check(shared.ExecPolicySourceProject, commands) {
+			res[v.Command] = v.Reason
+		}
+		return res
+	}
+
+	got := reasons(&shared.ExecPolicy{
+		DenyNetwork:         true,
+		DenyPackageManagers: true,
+		DenyDestructive:     true,
+	})
+	want := map[string]string{
+		"npm install lodash":       "runs a package manager",
+		"sudo rm -rf dist":         "is a destructive operation",
+		"git push --force":         "uses the network",
+		"curl https://example.com": "uses the network",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("category checks: got %v, want %v", got, want)
+	}
+
+	got = reasons(&shared.ExecPolicy{
+		Allow: []string{"npm", "go build *"},
+		Deny:  []string{"rm -rf *"},
+	})
+	want = map[string]string{
+		"sudo rm -rf dist":         "matches denied pattern 'rm -rf *'",
+		"git push --force":         "doesn't match any allowed pattern",
+		"curl https://example.com": "doesn't match any allowed pattern",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pattern checks: got %v, want %v", got, want)
+	}
+
+	// an explicit allow skips the category checks
+	got = reasons(&shared.ExecPolicy{
+		Allow:               []string{"npm install", "npm test"},
+		DenyPackageManagers: true,
+	})
+	if _, ok := got["npm install lodash"]; ok {
+		t.Errorf("expected allowed package install to pass, got %v", got)
+	}
+}
+
+func TestExecPolicyCheckUnresolvedNames(t *testing.T) {
+	commands, err := ParseScriptCommands(context.Background(), `x=rm; $x -rf /
+"${CMD}" build
+$(which rm) -rf dist
+sudo $x -rf /
+eval "$x -rf /"
+npm test
+`)
+	if err != nil {
+		t.Fatalf("error parsing script: %v", err)
+	}
+
+	unresolved := []string{"$x -rf /", "${CMD} build", "$(which rm) -rf dist", "sudo $x -rf /"}
+
+	tests := []struct {
+		name   string
+		policy *shared.ExecPolicy
+		want   []string
+	}{
+		{name: "deny patterns", policy: &shared.ExecPolicy{Deny: []string{"docker *"}}, want: unresolved},
+		{name: "deny category", policy: &shared.ExecPolicy{DenyDestructive: true}, want: unresolved},
+		// an allow pattern can't let an unknown command past the deny rules
+		{name: "allow all with deny", policy: &shared.ExecPolicy{Allow: []string{"*"}, Deny: []string{"docker *"}}, want: unresolved},
+		{name: "allow only", policy: &shared.ExecPolicy{Allow: []string{"*"}}, want: nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var got []string
+			for _, v := range test.policy.Check(shared.ExecPolicySourceProject, commands) {
+				if v.Reason != "runs a command whose name is only known at runtime" {
+					t.Errorf("unexpected violation for %s: %s", v.Command, v.Reason)
+				}
+				got = append(got, v.Command)
+			}
+			// the eval string and the command it runs are both checked
+			want := test.want
+			if want != nil {
+				want = append(append([]string{}, want...), "$x -rf /")
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("got violations %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+-- Schema for a simple task tracker
+
+CREATE EXTENSION IF NOT EXISTS "uuid-ossp";
+
+CREATE TYPE task_status AS ENUM ('todo', 'in_progress', 'done');
+
+CREATE TABLE users (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  email VARCHAR(255) NOT NULL UNIQUE,
+  name TEXT NOT NULL,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE projects (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+  name TEXT NOT NULL,
+  archived BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE tasks (
+  id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+  project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+  assignee_id UUID REFERENCES users(id) ON DELETE SET NULL,
+  title TEXT NOT NULL,
+  status task_status NOT NULL DEFAULT 'todo',
+  due_date DATE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+  updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX tasks_project_idx ON tasks(project_id);
+CREATE INDEX tasks_assignee_idx ON tasks(assignee_id);
+
+ALTER TABLE tasks ADD COLUMN priority INTEGER NOT NULL DEFAULT 0;
+
+/* Open tasks with their project and assignee */
+CREATE VIEW open_tasks AS
+SELECT t.id, t.title, p.name AS project, u.email AS assignee
+FROM tasks t
+JOIN projects p ON p.id = t.project_id
+LEFT JOIN users u ON u.id = t.assignee_id
+WHERE t.status != 'done' AND p.archived = FALSE;
+
+CREATE FUNCTION open_task_count(project UUID) RETURNS INTEGER AS $$
+  SELECT COUNT(*) FROM tasks WHERE project_id = project AND status != 'done';
+$$ LANGUAGE sql;
+
+CREATE TRIGGER tasks_updated_at
+BEFORE UPDATE ON tasks
+FOR EACH ROW EXECUTE FUNCTION set_updated_at();
+
+INSERT INTO users (email, name) VALUES ('admin@example.com', 'Admin');
+
+SELECT COUNT(*) FROM open_tasks;
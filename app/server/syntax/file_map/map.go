@@ -26,6 +26,8 @@ type Definition struct {
 	TagAttrs  []string     // For xml style markup tags, the class and id attributes
 	TagReps   int          // For tags, the number of times this tag is repeated
 	Line      int          // Line number where definition starts
+	EndLine   int          // Line number where definition ends
+	Name      string       // The declared name, if one could be found
 	Children  []Definition // For parent types that can contain nested definitions
 }
 
@@ -138,6 +140,10 @@ func mapNode(node *tree_sitter.Node, content []byte, lang shared.Language) []Def
 		return mapMarkup(content)
 	case shared.LanguageSvelte:
 		return mapSvelte(content)
+	case shared.LanguageVue:
+		return mapVue(node, content)
+	case shared.LanguageSql:
+		return mapSql(node, content)
 	default:
 		return mapTraditional(Node{
 			Lang:   lang,
@@ -147,6 +153,23 @@ func mapNode(node *tree_sitter.Node, content []byte, lang shared.Language) []Def
 	}
 }
 
+// mapSql maps each top-level sql statement, since the grammar wraps every statement (create_table, create_view, etc.) in a statement node
+func mapSql(node *tree_sitter.Node, content []byte) []Definition {
+	var defs []Definition
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		if child.Type() != "statement" {
+			continue
+		}
+		defs = append(defs, mapTraditional(Node{
+			Lang:   shared.LanguageSql,
+			TsNode: child,
+			Bytes:  content,
+		}, nil)...)
+	}
+	return defs
+}
+
 // For traditional programming languages
 func mapTraditional(baseNode Node, parentNode *Node) []Definition {
 	var defs []Definition
@@ -187,8 +210,10 @@ func mapTraditional(baseNode Node, parentNode *Node) []Definition {
 				}
 
 				def := Definition{
-					Type: node.Type,
-					Line: int(tsNode.StartPoint().Row) + 1,
+					Type:    node.Type,
+					Line:    int(tsNode.StartPoint().Row) + 1,
+					EndLine: int(tsNode.EndPoint().Row) + 1,
+					Name:    definitionName(tsNode, node.Bytes),
 				}
 
 				if isAssignmentNode(node) {
@@ -396,7 +421,7 @@ func (m *FileMap) String() string {
 
 	var writeDefinition func(def *Definition, depth int)
 	writeDefinition = func(def *Definition, depth int) {
-		if def.Type == "svelte-style" {
+		if def.Type == "svelte-style" || def.Type == "vue-style" {
 			b.WriteString("\n")
 		}
 
@@ -429,7 +454,7 @@ func (m *FileMap) String() string {
 			writeDefinition(&child, depth+1)
 		}
 
-		if def.Type == "svelte-script" {
+		if def.Type == "svelte-script" || def.Type == "vue-script" {
 			b.WriteString("\n")
 		}
 	}
@@ -470,6 +495,7 @@ func mapMarkdownSimple(content []byte) []Definition {
 				defs = append(defs, Definition{
 					Type:      fmt.Sprintf("h%d", level),
 					Signature: heading,
+					Name:      heading,
 					Line:      i + 1,
 				})
 			}
@@ -490,6 +516,7 @@ func mapMarkdownSimple(content []byte) []Definition {
 					defs = append(defs, Definition{
 						Type:      "h1",
 						Signature: prevLine,
+						Name:      prevLine,
 						Line:      i, // Use previous line's number
 					})
 				} else if isAllDashes {
@@ -497,6 +524,7 @@ func mapMarkdownSimple(content []byte) []Definition {
 					defs = append(defs, Definition{
 						Type:      "h2",
 						Signature: prevLine,
+						Name:      prevLine,
 						Line:      i, // Use previous line's number
 					})
 				}
@@ -504,5 +532,16 @@ func mapMarkdownSimple(content []byte) []Definition {
 		}
 	}
 
+	// a section runs until the next heading at the same or a higher level
+	for i := range defs {
+		defs[i].EndLine = len(lines)
+		for _, next := range defs[i+1:] {
+			if next.Type <= defs[i].Type {
+				defs[i].EndLine = next.Line - 1
+				break
+			}
+		}
+	}
+
 	return defs
 }
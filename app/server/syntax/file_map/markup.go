@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
 )
 
 func mapMarkup(content []byte) []Definition {
@@ -15,53 +16,74 @@ func mapMarkup(content []byte) []Definition {
 		return nil
 	}
 
-	var walk func(*html.Node) []Definition
-	walk = func(n *html.Node) []Definition {
-		var defs []Definition
+	defs := walkMarkup(doc)
+	defs = consolidateRepeatedTags(defs)
+	return defs
+}
 
-		if n.Type == html.ElementNode {
-			// Only track semantically significant elements
-			if isSignificantTag(n.Data) {
-				def := Definition{
-					Type:      "tag",
-					Signature: n.Data,
-				}
+// mapMarkupFragment maps markup that's parsed as the contents of a body element, like a vue template, so it isn't wrapped in html/head/body tags
+func mapMarkupFragment(content []byte) []Definition {
+	nodes, err := html.ParseFragment(bytes.NewReader(content), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "body",
+		DataAtom: atom.Body,
+	})
+	if err != nil {
+		return nil
+	}
 
-				// Only include semantic classes/ids
-				for _, attr := range n.Attr {
-					if attr.Key == "id" {
-						def.TagAttrs = append(def.TagAttrs, fmt.Sprintf("#%s", attr.Val))
-					} else if attr.Key == "class" {
-						classes := strings.Fields(attr.Val)
-						if len(classes) > 3 {
-							classes = classes[:3]
-						}
-						def.TagAttrs = append(def.TagAttrs, fmt.Sprintf(".%s", strings.Join(classes, ".")))
-					}
-				}
+	var defs []Definition
+	for _, n := range nodes {
+		defs = append(defs, walkMarkup(n)...)
+	}
+	if len(defs) == 0 {
+		return defs
+	}
 
-				// Get children of this element
-				def.Children = []Definition{}
-				for c := n.FirstChild; c != nil; c = c.NextSibling {
-					def.Children = append(def.Children, walk(c)...)
-				}
+	return consolidateRepeatedTags(defs)
+}
+
+func walkMarkup(n *html.Node) []Definition {
+	var defs []Definition
 
-				defs = append(defs, def)
+	if n.Type == html.ElementNode {
+		// Only track semantically significant elements
+		if isSignificantTag(n.Data) {
+			def := Definition{
+				Type:      "tag",
+				Signature: n.Data,
+			}
+
+			// Only include semantic classes/ids
+			for _, attr := range n.Attr {
+				if attr.Key == "id" {
+					def.TagAttrs = append(def.TagAttrs, fmt.Sprintf("#%s", attr.Val))
+				} else if attr.Key == "class" {
+					classes := strings.Fields(attr.Val)
+					if len(classes) > 3 {
+						classes = classes[:3]
+					}
+					def.TagAttrs = append(def.TagAttrs, fmt.Sprintf(".%s", strings.Join(classes, ".")))
+				}
 			}
-		}
 
-		// Only process siblings for non-significant elements
-		if !isSignificantTag(n.Data) {
+			// Get children of this element
+			def.Children = []Definition{}
 			for c := n.FirstChild; c != nil; c = c.NextSibling {
-				defs = append(defs, walk(c)...)
+				def.Children = append(def.Children, walkMarkup(c)...)
 			}
+
+			defs = append(defs, def)
 		}
+	}
 
-		return defs
+	// Only process siblings for non-significant elements
+	if !isSignificantTag(n.Data) {
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			defs = append(defs, walkMarkup(c)...)
+		}
 	}
 
-	defs := walk(doc)
-	defs = consolidateRepeatedTags(defs)
 	return defs
 }
 
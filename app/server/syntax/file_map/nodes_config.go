@@ -439,6 +439,25 @@ var definitionNodeMap = nodeMap{
 			shared.LanguageRust: true,
 		},
 	},
+	"create_": {
+		nodeMatch: matchTypePrefix,
+		languages: langSet{
+			shared.LanguageSql: true,
+		},
+	},
+	"alter_": {
+		nodeMatch: matchTypePrefix,
+		languages: langSet{
+			shared.LanguageSql: true,
+		},
+	},
+	"create_query": {
+		nodeMatch: matchTypeEqual,
+		ignore:    true,
+		languages: langSet{
+			shared.LanguageSql: true,
+		},
+	},
 }
 
 var parentNodeMap = nodeMap{
@@ -572,6 +591,13 @@ var parentNodeMap = nodeMap{
 		},
 	},
 
+	"create_table": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageSql: true,
+		},
+	},
+
 	"function_statement": {
 		nodeMatch: matchTypeEqual,
 		languages: langSet{
@@ -701,6 +727,19 @@ var implBoundaryNodeMap = nodeMap{
 			shared.LanguageScala: true,
 		},
 	},
+
+	"column_definitions": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageSql: true,
+		},
+	},
+	"create_query": {
+		nodeMatch: matchTypeEqual,
+		languages: langSet{
+			shared.LanguageSql: true,
+		},
+	},
 }
 
 var assignmentBoundaryNodeMap = nodeMap{
@@ -5,6 +5,8 @@ import (
 	"strings"
 
 	shared "plandex-shared"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
 )
 
 func isDefinitionNode(node Node, parentNode *Node) bool {
@@ -295,3 +297,50 @@ func setNodeType(node *Node) {
 		}
 	}
 }
+
+// definitionName finds the name a definition is declared with, looking through the wrapper nodes some grammars use
+// (declarators in C-like languages, specs in Go, variable declarators in JS, exports and decorators)
+func definitionName(node *tree_sitter.Node, content []byte) string {
+	return findDefinitionName(node, content, 0)
+}
+
+func findDefinitionName(node *tree_sitter.Node, content []byte, depth int) string {
+	if depth > 4 {
+		return ""
+	}
+
+	if name := node.ChildByFieldName("name"); name != nil {
+		return name.Content(content)
+	}
+
+	// sql statements name what they create or alter with an object reference, which may be schema-qualified
+	if t := node.Type(); strings.HasPrefix(t, "create_") || strings.HasPrefix(t, "alter_") {
+		for i := 0; i < int(node.NamedChildCount()); i++ {
+			child := node.NamedChild(i)
+			if child.Type() == "identifier" || child.Type() == "object_reference" {
+				return child.Content(content)
+			}
+		}
+	}
+
+	if declarator := node.ChildByFieldName("declarator"); declarator != nil {
+		if strings.HasSuffix(declarator.Type(), "identifier") {
+			return declarator.Content(content)
+		}
+		return findDefinitionName(declarator, content, depth+1)
+	}
+
+	for i := 0; i < int(node.NamedChildCount()); i++ {
+		child := node.NamedChild(i)
+		t := child.Type()
+		// only look through declaration wrappers, not parameters or bodies
+		if strings.Contains(t, "declaration") || strings.Contains(t, "declarator") ||
+			strings.Contains(t, "definition") || strings.HasSuffix(t, "_spec") || strings.HasSuffix(t, "_item") {
+			if name := findDefinitionName(child, content, depth+1); name != "" {
+				return name
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,189 @@
+package file_map
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var ErrSymbolNotFound = errors.New("symbol not found")
+
+// SymbolSlice is the source of a single definition in a file, preceded by the signatures of the definitions that enclose it
+type SymbolSlice struct {
+	Body      string
+	StartLine int
+	EndLine   int
+}
+
+type locatedDefinition struct {
+	def       *Definition
+	ancestors []*Definition
+}
+
+// ExtractSymbol finds a definition by name in the file's map and returns its source lines, including any comments directly above it.
+// Nested definitions can be selected with a dotted path like 'Class.method'. 'Type.Method' also matches methods declared outside
+// their type (as in Go), and markdown sections are selected by their heading.
+func ExtractSymbol(ctx context.Context, filename string, content []byte, symbol string) (*SymbolSlice, error) {
+	symbol = strings.TrimSpace(symbol)
+	if symbol == "" {
+		return nil, ErrSymbolNotFound
+	}
+
+	fileMap, err := MapFile(ctx, filename, content)
+	if err != nil {
+		return nil, err
+	}
+
+	var located []locatedDefinition
+	var walk func(defs []Definition, ancestors []*Definition)
+	walk = func(defs []Definition, ancestors []*Definition) {
+		for i := range defs {
+			def := &defs[i]
+			if def.Line > 0 {
+				located = append(located, locatedDefinition{def: def, ancestors: ancestors})
+			}
+			walk(def.Children, append(ancestors[:len(ancestors):len(ancestors)], def))
+		}
+	}
+	walk(fileMap.Definitions, nil)
+
+	// prefer the outermost definition when a name is declared at multiple levels
+	sort.SliceStable(located, func(i, j int) bool {
+		return len(located[i].ancestors) < len(located[j].ancestors)
+	})
+
+	match := findSymbol(located, symbol)
+	if match == nil {
+		return nil, ErrSymbolNotFound
+	}
+
+	lines := strings.Split(string(content), "\n")
+
+	start := match.def.Line
+	end := match.def.EndLine
+	if end < start {
+		end = start
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	for start > 1 && isCommentLine(lines[start-2]) {
+		start--
+	}
+
+	var b strings.Builder
+	prevLine := 0
+	for _, ancestor := range match.ancestors {
+		// skip wrappers that start on the same line as the definition, like exports
+		if ancestor.Line <= prevLine || ancestor.Line >= start {
+			continue
+		}
+		if prevLine > 0 && ancestor.Line > prevLine+1 {
+			b.WriteString(indentation(lines[ancestor.Line-1]) + "...\n")
+		}
+		b.WriteString(lines[ancestor.Line-1] + "\n")
+		prevLine = ancestor.Line
+	}
+	if prevLine > 0 && start > prevLine+1 {
+		b.WriteString(indentation(lines[start-1]) + "...\n")
+	}
+	b.WriteString(strings.Join(lines[start-1:end], "\n"))
+
+	return &SymbolSlice{
+		Body:      b.String(),
+		StartLine: start,
+		EndLine:   end,
+	}, nil
+}
+
+func findSymbol(located []locatedDefinition, symbol string) *locatedDefinition {
+	// exact name at any depth
+	for i, l := range located {
+		if nameMatches(l.def.Name, symbol) {
+			return &located[i]
+		}
+	}
+
+	parts := strings.Split(symbol, ".")
+	if len(parts) > 1 {
+		last := parts[len(parts)-1]
+		parents := parts[:len(parts)-1]
+
+		// nested path like Class.method
+		for i, l := range located {
+			if !nameMatches(l.def.Name, last) {
+				continue
+			}
+			if ancestorsMatch(l.ancestors, parents) {
+				return &located[i]
+			}
+		}
+
+		// methods declared outside their type, like Go methods with a receiver
+		if len(parts) == 2 {
+			typeRe := wordRegexp(parts[0])
+			for i, l := range located {
+				if nameMatches(l.def.Name, last) && typeRe.MatchString(l.def.Signature) {
+					return &located[i]
+				}
+			}
+		}
+	}
+
+	// definitions without a name we could find, matched on their signature
+	re := wordRegexp(symbol)
+	for i, l := range located {
+		if l.def.Name == "" && re.MatchString(l.def.Signature) {
+			return &located[i]
+		}
+	}
+
+	return nil
+}
+
+func nameMatches(name, symbol string) bool {
+	if name == "" {
+		return false
+	}
+	return name == symbol || strings.HasSuffix(name, "::"+symbol) || strings.HasSuffix(name, "."+symbol)
+}
+
+// ancestorsMatch checks that the named ancestors end with the given names, so 'Outer.Inner.method' and 'Inner.method' both match
+func ancestorsMatch(ancestors []*Definition, names []string) bool {
+	var named []string
+	for _, ancestor := range ancestors {
+		if ancestor.Name != "" {
+			named = append(named, ancestor.Name)
+		}
+	}
+	if len(named) < len(names) {
+		return false
+	}
+	named = named[len(named)-len(names):]
+	for i, name := range names {
+		if !nameMatches(named[i], name) {
+			return false
+		}
+	}
+	return true
+}
+
+func wordRegexp(word string) *regexp.Regexp {
+	return regexp.MustCompile(`(^|[^\w$])` + regexp.QuoteMeta(word) + `($|[^\w$])`)
+}
+
+func isCommentLine(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	for _, prefix := range []string{"//", "/*", "*", "#", "--", "@"} {
+		if strings.HasPrefix(trimmed, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func indentation(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
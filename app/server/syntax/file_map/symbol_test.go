@@ -0,0 +1,202 @@
+package file_map
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+const goSymbolSource = `package server
+
+import "net/http"
+
+type Server struct {
+	addr string
+}
+
+// Start listens on the server's address
+func (s *Server) Start() error {
+	return http.ListenAndServe(s.addr, nil)
+}
+
+func helper() int {
+	return 1
+}
+`
+
+const pySymbolSource = `import os
+
+
+class Greeter:
+    prefix = "hi"
+
+    def other(self):
+        pass
+
+    def greet(self, name):
+        return self.prefix + name
+
+
+def greet():
+    return "top level"
+`
+
+const sqlSymbolSource = `CREATE TABLE users (
+  id SERIAL PRIMARY KEY,
+  email TEXT NOT NULL
+);
+
+-- projects belong to a user
+CREATE TABLE projects (
+  id SERIAL PRIMARY KEY,
+  owner_id INTEGER NOT NULL REFERENCES users(id)
+);
+
+CREATE INDEX projects_owner_idx ON projects(owner_id);
+`
+
+const vueSymbolSource = `<template>
+  <button @click="increment">{{ count }}</button>
+</template>
+
+<script setup lang="ts">
+import { ref } from 'vue'
+
+const count = ref(0)
+
+function increment() {
+  count.value++
+}
+</script>
+`
+
+const mdSymbolSource = `# Project
+
+Intro
+
+## Install
+
+Run make.
+
+### From source
+
+Clone it.
+
+## Usage
+
+Run it.
+`
+
+func TestExtractSymbol(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		source   string
+		symbol   string
+		want     string
+	}{
+		{
+			name:     "go function",
+			filename: "server.go",
+			source:   goSymbolSource,
+			symbol:   "helper",
+			want:     "func helper() int {\n\treturn 1\n}",
+		},
+		{
+			name:     "go method with receiver and comment",
+			filename: "server.go",
+			source:   goSymbolSource,
+			symbol:   "Server.Start",
+			want:     "// Start listens on the server's address\nfunc (s *Server) Start() error {\n\treturn http.ListenAndServe(s.addr, nil)\n}",
+		},
+		{
+			name:     "go type",
+			filename: "server.go",
+			source:   goSymbolSource,
+			symbol:   "Server",
+			want:     "type Server struct {\n\taddr string\n}",
+		},
+		{
+			name:     "python top level preferred over nested",
+			filename: "greeter.py",
+			source:   pySymbolSource,
+			symbol:   "greet",
+			want:     "def greet():\n    return \"top level\"",
+		},
+		{
+			name:     "python method with enclosing class",
+			filename: "greeter.py",
+			source:   pySymbolSource,
+			symbol:   "Greeter.greet",
+			want:     "class Greeter:\n    ...\n    def greet(self, name):\n        return self.prefix + name",
+		},
+		{
+			name:     "sql table with comment",
+			filename: "schema.sql",
+			source:   sqlSymbolSource,
+			symbol:   "projects",
+			want:     "-- projects belong to a user\nCREATE TABLE projects (\n  id SERIAL PRIMARY KEY,\n  owner_id INTEGER NOT NULL REFERENCES users(id)\n);",
+		},
+		{
+			name:     "sql index",
+			filename: "schema.sql",
+			source:   sqlSymbolSource,
+			symbol:   "projects_owner_idx",
+			want:     "CREATE INDEX projects_owner_idx ON projects(owner_id);",
+		},
+		{
+			name:     "vue script setup function with enclosing block",
+			filename: "Counter.vue",
+			source:   vueSymbolSource,
+			symbol:   "increment",
+			want:     "<script setup lang=\"ts\">\n...\nfunction increment() {\n  count.value++\n}",
+		},
+		{
+			name:     "markdown section includes subsections",
+			filename: "README.md",
+			source:   mdSymbolSource,
+			symbol:   "Install",
+			want:     "## Install\n\nRun make.\n\n### From source\n\nClone it.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			slice, err := ExtractSymbol(context.Background(), tt.filename, []byte(tt.source), tt.symbol)
+			if err != nil {
+				t.Fatalf("error extracting symbol: %v", err)
+			}
+			if slice.Body != tt.want {
+				t.Errorf("got:\n%s\n\nwant:\n%s", slice.Body, tt.want)
+			}
+		})
+	}
+}
+
+func TestExtractSymbolMoved(t *testing.T) {
+	moved := strings.Replace(goSymbolSource, "type Server", "func added() {}\n\ntype Server", 1)
+
+	before, err := ExtractSymbol(context.Background(), "server.go", []byte(goSymbolSource), "helper")
+	if err != nil {
+		t.Fatalf("error extracting symbol: %v", err)
+	}
+	after, err := ExtractSymbol(context.Background(), "server.go", []byte(moved), "helper")
+	if err != nil {
+		t.Fatalf("error extracting moved symbol: %v", err)
+	}
+
+	if before.Body != after.Body {
+		t.Errorf("expected same body after move, got %q and %q", before.Body, after.Body)
+	}
+	if after.StartLine != before.StartLine+2 {
+		t.Errorf("expected start line to move from %d to %d, got %d", before.StartLine, before.StartLine+2, after.StartLine)
+	}
+}
+
+func TestExtractSymbolNotFound(t *testing.T) {
+	_, err := ExtractSymbol(context.Background(), "server.go", []byte(goSymbolSource), "Missing")
+	if !errors.Is(err, ErrSymbolNotFound) {
+		t.Errorf("expected ErrSymbolNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+package file_map
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"plandex-server/syntax"
+	"strings"
+
+	shared "plandex-shared"
+
+	tree_sitter "github.com/smacker/go-tree-sitter"
+)
+
+// mapVue maps a vue single file component from its html parse tree. Script and style blocks are parsed as raw text by the html grammar, so each is mapped with its own parser and line numbers are shifted back to their position in the component. The template's markup is mapped the same way as html.
+func mapVue(root *tree_sitter.Node, content []byte) []Definition {
+	var scriptDefs, templateDefs, styleDefs []Definition
+
+	for i := 0; i < int(root.NamedChildCount()); i++ {
+		child := root.NamedChild(i)
+
+		switch child.Type() {
+		case "element":
+			if def := mapVueTemplate(child, content); def != nil {
+				templateDefs = append(templateDefs, *def)
+			}
+
+		case "script_element":
+			lang := shared.LanguageJavascript
+			switch getVueBlockAttr(child, content, "lang") {
+			case "ts":
+				lang = shared.LanguageTypescript
+			case "tsx", "jsx":
+				lang = shared.LanguageTsx
+			}
+
+			def := Definition{
+				Type:      "vue-script",
+				Signature: vueStartTag(child, content),
+				Line:      int(child.StartPoint().Row) + 1,
+				EndLine:   int(child.EndPoint().Row) + 1,
+				Children:  mapVueBlock(child, content, lang),
+			}
+			scriptDefs = append(scriptDefs, def)
+
+		case "style_element":
+			def := Definition{
+				Type:      "vue-style",
+				Signature: vueStartTag(child, content),
+				Line:      int(child.StartPoint().Row) + 1,
+				EndLine:   int(child.EndPoint().Row) + 1,
+			}
+
+			// scss, less, etc. aren't mapped
+			styleLang := getVueBlockAttr(child, content, "lang")
+			if styleLang == "" || styleLang == "css" {
+				def.Children = mapVueBlock(child, content, shared.LanguageCss)
+			}
+			styleDefs = append(styleDefs, def)
+		}
+	}
+
+	defs := []Definition{}
+	defs = append(defs, scriptDefs...)
+	defs = append(defs, templateDefs...)
+	defs = append(defs, styleDefs...)
+
+	return defs
+}
+
+func mapVueTemplate(element *tree_sitter.Node, content []byte) *Definition {
+	var startTag, endTag *tree_sitter.Node
+	for i := 0; i < int(element.NamedChildCount()); i++ {
+		child := element.NamedChild(i)
+		switch child.Type() {
+		case "start_tag":
+			startTag = child
+		case "end_tag":
+			endTag = child
+		}
+	}
+	if startTag == nil || endTag == nil {
+		return nil
+	}
+
+	tagName := startTag.NamedChild(0)
+	if tagName == nil || tagName.Content(content) != "template" {
+		return nil
+	}
+
+	return &Definition{
+		Type:      "tag",
+		Signature: "template",
+		Line:      int(element.StartPoint().Row) + 1,
+		EndLine:   int(element.EndPoint().Row) + 1,
+		Children:  mapMarkupFragment(content[startTag.EndByte():endTag.StartByte()]),
+	}
+}
+
+func mapVueBlock(element *tree_sitter.Node, content []byte, lang shared.Language) []Definition {
+	var rawText *tree_sitter.Node
+	for i := 0; i < int(element.NamedChildCount()); i++ {
+		if child := element.NamedChild(i); child.Type() == "raw_text" {
+			rawText = child
+			break
+		}
+	}
+	if rawText == nil {
+		return nil
+	}
+
+	blockContent := []byte(rawText.Content(content))
+
+	parser := syntax.GetParserForLanguage(lang)
+	defer parser.Close()
+
+	tree, err := parser.ParseCtx(context.Background(), nil, blockContent)
+	if err != nil {
+		log.Printf("mapVue - error parsing %s block: %v\n", lang, err)
+		return nil
+	}
+	defer tree.Close()
+
+	defs := mapTraditional(Node{
+		Lang:   lang,
+		TsNode: tree.RootNode(),
+		Bytes:  blockContent,
+	}, nil)
+
+	offsetDefinitionLines(defs, int(rawText.StartPoint().Row))
+
+	return defs
+}
+
+func offsetDefinitionLines(defs []Definition, offset int) {
+	for i := range defs {
+		def := &defs[i]
+		if def.Line > 0 {
+			def.Line += offset
+			def.EndLine += offset
+		}
+		offsetDefinitionLines(def.Children, offset)
+	}
+}
+
+func vueStartTag(element *tree_sitter.Node, content []byte) string {
+	for i := 0; i < int(element.NamedChildCount()); i++ {
+		if child := element.NamedChild(i); child.Type() == "start_tag" {
+			return strings.Join(strings.Fields(child.Content(content)), " ")
+		}
+	}
+	return fmt.Sprintf("<%s>", strings.TrimSuffix(element.Type(), "_element"))
+}
+
+func getVueBlockAttr(element *tree_sitter.Node, content []byte, name string) string {
+	for i := 0; i < int(element.NamedChildCount()); i++ {
+		startTag := element.NamedChild(i)
+		if startTag.Type() != "start_tag" {
+			continue
+		}
+
+		for j := 0; j < int(startTag.NamedChildCount()); j++ {
+			attr := startTag.NamedChild(j)
+			if attr.Type() != "attribute" || attr.NamedChildCount() == 0 {
+				continue
+			}
+			if attr.NamedChild(0).Content(content) != name {
+				continue
+			}
+			if attr.NamedChildCount() > 1 {
+				return strings.Trim(attr.NamedChild(1).Content(content), `"'`)
+			}
+			return ""
+		}
+	}
+	return ""
+}
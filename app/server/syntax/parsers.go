@@ -29,6 +29,7 @@ import (
 	"github.com/smacker/go-tree-sitter/ruby"
 	"github.com/smacker/go-tree-sitter/rust"
 	"github.com/smacker/go-tree-sitter/scala"
+	"github.com/smacker/go-tree-sitter/sql"
 	"github.com/smacker/go-tree-sitter/svelte"
 	"github.com/smacker/go-tree-sitter/swift"
 	"github.com/smacker/go-tree-sitter/toml"
@@ -116,7 +117,8 @@ func GetParserForLanguage(lang shared.Language) *tree_sitter.Parser {
 		parser.SetLanguage(groovy.GetLanguage())
 	case shared.LanguageHcl:
 		parser.SetLanguage(hcl.GetLanguage())
-	case shared.LanguageHtml:
+	case shared.LanguageHtml, shared.LanguageVue:
+		// vue single file components are html at the top level, with script and style blocks parsed as raw text
 		parser.SetLanguage(html.GetLanguage())
 	case shared.LanguageJava:
 		parser.SetLanguage(java.GetLanguage())
@@ -140,6 +142,8 @@ func GetParserForLanguage(lang shared.Language) *tree_sitter.Parser {
 		parser.SetLanguage(rust.GetLanguage())
 	case shared.LanguageScala:
 		parser.SetLanguage(scala.GetLanguage())
+	case shared.LanguageSql:
+		parser.SetLanguage(sql.GetLanguage())
 	case shared.LanguageSvelte:
 		parser.SetLanguage(svelte.GetLanguage())
 	case shared.LanguageSwift:
@@ -1443,6 +1443,133 @@ echo "Installing dependencies for markdown processing..."
 npm install next-mdx-remote gray-matter --save                            
 echo "Dependencies installed successfully!"`,
 		},
+		{
+			name: "sql migration with reference comments",
+			original: `CREATE TABLE users (
+  id SERIAL PRIMARY KEY,
+  email TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE projects (
+  id SERIAL PRIMARY KEY,
+  owner_id INTEGER NOT NULL REFERENCES users(id),
+  name TEXT NOT NULL
+);
+
+CREATE INDEX projects_owner_idx ON projects(owner_id);
+
+CREATE TABLE tasks (
+  id SERIAL PRIMARY KEY,
+  project_id INTEGER NOT NULL REFERENCES projects(id),
+  title TEXT NOT NULL
+);`,
+			proposed: `-- ... existing code ...
+
+CREATE TABLE projects (
+  id SERIAL PRIMARY KEY,
+  owner_id INTEGER NOT NULL REFERENCES users(id),
+  name TEXT NOT NULL,
+  archived BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+-- ... existing code ...`,
+			want: `CREATE TABLE users (
+  id SERIAL PRIMARY KEY,
+  email TEXT NOT NULL UNIQUE,
+  created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE projects (
+  id SERIAL PRIMARY KEY,
+  owner_id INTEGER NOT NULL REFERENCES users(id),
+  name TEXT NOT NULL,
+  archived BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE INDEX projects_owner_idx ON projects(owner_id);
+
+CREATE TABLE tasks (
+  id SERIAL PRIMARY KEY,
+  project_id INTEGER NOT NULL REFERENCES projects(id),
+  title TEXT NOT NULL
+);`,
+			ext: "sql",
+		},
+		{
+			name: "vue component script and template update",
+			original: `<script setup lang="ts">
+import { ref } from 'vue'
+
+const count = ref(0)
+
+function increment() {
+  count.value++
+}
+</script>
+
+<template>
+  <div class="counter">
+    <p>{{ count }}</p>
+    <button @click="increment">+</button>
+  </div>
+</template>
+
+<style scoped>
+.counter {
+  display: flex;
+}
+</style>`,
+			proposed: `<script setup lang="ts">
+// ... existing code ...
+
+function increment() {
+  count.value++
+}
+
+function reset() {
+  count.value = 0
+}
+</script>
+
+<template>
+  <div class="counter">
+    <p>{{ count }}</p>
+    <button @click="increment">+</button>
+    <button @click="reset">Reset</button>
+  </div>
+</template>
+
+<!-- ... existing code ... -->`,
+			want: `<script setup lang="ts">
+import { ref } from 'vue'
+
+const count = ref(0)
+
+function increment() {
+  count.value++
+}
+
+function reset() {
+  count.value = 0
+}
+</script>
+
+<template>
+  <div class="counter">
+    <p>{{ count }}</p>
+    <button @click="increment">+</button>
+    <button @click="reset">Reset</button>
+  </div>
+</template>
+
+<style scoped>
+.counter {
+  display: flex;
+}
+</style>`,
+			ext: "vue",
+		},
 	}
 
 	onlyTests := map[int]bool{}
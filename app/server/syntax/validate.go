@@ -14,6 +14,12 @@ import (
 
 const parserTimeout = 500 * time.Millisecond
 
+// the sql grammar doesn't handle dialect-specific syntax like postgres dollar-quoted bodies, so it reports errors on valid files
+// it's still used for file maps and structured edits, but sql files are never treated as invalid
+var unvalidatedLanguages = map[shared.Language]bool{
+	shared.LanguageSql: true,
+}
+
 type ValidationRes = struct {
 	Lang     shared.Language
 	Parser   *tree_sitter.Parser
@@ -33,7 +39,7 @@ func ValidateFile(ctx context.Context, path string, file string) (*ValidationRes
 }
 
 func ValidateWithParsers(ctx context.Context, lang shared.Language, parser *tree_sitter.Parser, fallbackLang shared.Language, fallbackParser *tree_sitter.Parser, file string) (*ValidationRes, error) {
-	if file == "" {
+	if file == "" || unvalidatedLanguages[lang] {
 		return &ValidationRes{Lang: lang, Parser: parser, Valid: true}, nil
 	}
 
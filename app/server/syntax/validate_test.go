@@ -0,0 +1,44 @@
+package syntax
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	shared "plandex-shared"
+)
+
+func TestValidateFileMigrations(t *testing.T) {
+	var paths []string
+	for _, pattern := range []string{"../migrations/*.sql", "../migrations/sqlite/*.sql"} {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			t.Fatalf("error globbing %s: %v", pattern, err)
+		}
+		paths = append(paths, matches...)
+	}
+
+	if len(paths) == 0 {
+		t.Fatal("no migrations found")
+	}
+
+	for _, path := range paths {
+		bytes, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("error reading %s: %v", path, err)
+		}
+
+		res, err := ValidateFile(context.Background(), path, string(bytes))
+		if err != nil {
+			t.Fatalf("error validating %s: %v", path, err)
+		}
+
+		if res.Lang != shared.LanguageSql || res.Parser == nil {
+			t.Errorf("%s: expected the sql parser, got %s", path, res.Lang)
+		}
+		if !res.Valid {
+			t.Errorf("%s: expected valid, got errors: %v", path, res.Errors)
+		}
+	}
+}
@@ -7,7 +7,9 @@ import (
 )
 
 type ServerAuth struct {
-	AuthToken   *db.AuthToken
+	AuthToken *db.AuthToken
+	// set instead of AuthToken when authenticating with a personal access token
+	AccessToken *db.AccessToken
 	User        *db.User
 	OrgId       string
 	Permissions shared.Permissions
@@ -0,0 +1,382 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"plandex-server/db"
+	"plandex-server/shutdown"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/google/uuid"
+)
+
+// Webhooks are org-level HTTP endpoints that receive a signed json POST (a shared.WebhookPayload) when plan lifecycle events happen.
+// Each request includes these headers:
+//   - X-Plandex-Event: the event name
+//   - X-Plandex-Delivery: the delivery id (stable across retries, so receivers can dedupe)
+//   - X-Plandex-Timestamp: unix seconds when the attempt was sent
+//   - X-Plandex-Signature: "sha256=" + hex(HMAC-SHA256(secret, timestamp + "." + body))
+// Failed deliveries (network errors, 408, 429, and 5xx responses) are retried with exponential backoff. Every delivery and its outcome is logged in the webhook_deliveries table.
+// Since error responses are logged and shown to org members, webhooks can't reach private, loopback, or link-local addresses, and redirects aren't followed.
+
+const (
+	maxDeliveryAttempts    = 5
+	deliveryTimeout        = 10 * time.Second
+	maxResponseErrorLength = 1000
+	deliveryLogRetention   = 30 * 24 * time.Hour
+	deliveryLogPruneEvery  = time.Hour
+)
+
+// var so tests can shorten it
+var retryBaseDelay = 2 * time.Second
+
+// self-hosted servers can set WEBHOOKS_ALLOW_PRIVATE_NETWORKS to deliver to internal hosts -- var so tests can deliver to local servers
+var allowPrivateNetworks = func() bool {
+	return os.Getenv("WEBHOOKS_ALLOW_PRIVATE_NETWORKS") != "" && os.Getenv("IS_CLOUD") == ""
+}
+
+var httpClient = &http.Client{
+	Timeout: deliveryTimeout,
+	Transport: &http.Transport{
+		// no proxy, since the proxy would make the connection instead of the checked dialer
+		Proxy: nil,
+		DialContext: (&net.Dialer{
+			Timeout: deliveryTimeout,
+			// checked on every connection, after dns resolution, so a hostname can't be re-pointed at an internal address after it's validated
+			Control: checkDialAddr,
+		}).DialContext,
+		TLSHandshakeTimeout: deliveryTimeout,
+		MaxIdleConnsPerHost: 2,
+		IdleConnTimeout:     90 * time.Second,
+	},
+	// a redirect is returned as an unsuccessful response rather than followed
+	CheckRedirect: func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	},
+}
+
+var blockedNetworks = mustParseCIDRs(
+	"0.0.0.0/8",      // "this" network
+	"100.64.0.0/10",  // carrier-grade nat, used for some cloud metadata services
+	"192.0.0.0/24",   // ietf protocol assignments
+	"198.18.0.0/15",  // benchmarking
+	"64:ff9b::/96",   // nat64, which can map to any ipv4 address
+	"64:ff9b:1::/48", // local-use nat64
+	"2001:db8::/32",  // documentation
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	var res []*net.IPNet
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		res = append(res, network)
+	}
+	return res
+}
+
+func isBlockedIP(ip net.IP) bool {
+	if ip.IsPrivate() || ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func checkDialAddr(network, address string, _ syscall.RawConn) error {
+	if allowPrivateNetworks() {
+		return nil
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %v", address, err)
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("invalid address %s", address)
+	}
+
+	if isBlockedIP(ip) {
+		return fmt.Errorf("webhook address %s is private, loopback, or link-local", ip)
+	}
+
+	return nil
+}
+
+var (
+	lastPruneMu sync.Mutex
+	lastPruneAt time.Time
+)
+
+// Dispatch delivers an event to all of an org's webhooks that subscribe to it -- it returns immediately and delivers in the background
+func Dispatch(orgId string, event shared.WebhookEvent, data *shared.WebhookEventData) {
+	ctx := shutdown.ShutdownCtx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Printf("panic dispatching webhook event %s: %v\n%s", event, r, debug.Stack())
+			}
+		}()
+
+		err := dispatch(ctx, orgId, event, data)
+		if err != nil {
+			log.Printf("Error dispatching webhook event %s for org %s: %v\n", event, orgId, err)
+		}
+	}()
+}
+
+func dispatch(ctx context.Context, orgId string, event shared.WebhookEvent, data *shared.WebhookEventData) error {
+	webhooks, err := db.ListWebhooksForEvent(orgId, event)
+	if err != nil {
+		return err
+	}
+
+	if len(webhooks) == 0 {
+		return nil
+	}
+
+	if data != nil && data.PlanId != "" && data.PlanName == "" {
+		plan, err := db.GetPlan(data.PlanId)
+		if err != nil {
+			log.Printf("Error getting plan name for webhook payload: %v\n", err)
+		} else if plan != nil {
+			data.PlanName = plan.Name
+		}
+	}
+
+	pruneDeliveryLog()
+
+	var wg sync.WaitGroup
+	for _, webhook := range webhooks {
+		wg.Add(1)
+		go func(webhook *db.Webhook) {
+			defer wg.Done()
+			_, err := deliver(ctx, webhook, event, data, maxDeliveryAttempts)
+			if err != nil {
+				log.Printf("Error delivering webhook %s for event %s: %v\n", webhook.Id, event, err)
+			}
+		}(webhook)
+	}
+	wg.Wait()
+
+	return nil
+}
+
+// SendPing makes a single delivery attempt of a ping event and returns the logged result
+func SendPing(ctx context.Context, webhook *db.Webhook) (*db.WebhookDelivery, error) {
+	return deliver(ctx, webhook, shared.WebhookEventPing, &shared.WebhookEventData{}, 1)
+}
+
+// deliver only returns an error if the delivery couldn't be logged -- failed requests are recorded on the delivery
+func deliver(ctx context.Context, webhook *db.Webhook, event shared.WebhookEvent, data *shared.WebhookEventData, maxAttempts int) (*db.WebhookDelivery, error) {
+	delivery := &db.WebhookDelivery{
+		Id:        uuid.New().String(),
+		OrgId:     webhook.OrgId,
+		WebhookId: webhook.Id,
+		Event:     event,
+		Status:    shared.WebhookDeliveryStatusPending,
+	}
+
+	body, err := json.Marshal(shared.WebhookPayload{
+		DeliveryId: delivery.Id,
+		Event:      event,
+		OrgId:      webhook.OrgId,
+		CreatedAt:  time.Now().UTC(),
+		Data:       data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling webhook payload: %v", err)
+	}
+	delivery.Payload = string(body)
+
+	err = db.CreateWebhookDelivery(delivery)
+	if err != nil {
+		return nil, err
+	}
+
+attempts:
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		if attempt > 1 {
+			delay := retryBaseDelay * time.Duration(1<<uint(attempt-2))
+			select {
+			case <-ctx.Done():
+				break attempts
+			case <-time.After(delay):
+			}
+		}
+
+		status, err := send(ctx, webhook, delivery.Id, event, body)
+
+		delivery.NumAttempts = attempt
+		if status > 0 {
+			delivery.ResponseStatus = &status
+		}
+
+		if err == nil {
+			now := time.Now()
+			delivery.Status = shared.WebhookDeliveryStatusSucceeded
+			delivery.Error = nil
+			delivery.DeliveredAt = &now
+			break
+		}
+
+		errStr := err.Error()
+		delivery.Error = &errStr
+
+		if !isRetriableStatus(status) || attempt == maxAttempts {
+			delivery.Status = shared.WebhookDeliveryStatusFailed
+			break
+		}
+
+		log.Printf("Webhook %s delivery %s attempt %d failed, will retry: %v\n", webhook.Id, delivery.Id, attempt, err)
+
+		// keep the log current while retries are pending
+		err = db.UpdateWebhookDelivery(delivery)
+		if err != nil {
+			log.Printf("Error updating webhook delivery: %v\n", err)
+		}
+	}
+
+	if delivery.Status == shared.WebhookDeliveryStatusPending {
+		// shut down before retries finished
+		delivery.Status = shared.WebhookDeliveryStatusFailed
+	}
+
+	err = db.UpdateWebhookDelivery(delivery)
+	if err != nil {
+		return delivery, err
+	}
+
+	return delivery, nil
+}
+
+// returns the response status (0 if no response was received) and an error if the delivery didn't succeed
+func send(ctx context.Context, webhook *db.Webhook, deliveryId string, event shared.WebhookEvent, body []byte) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.Url, bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("error creating request: %v", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Plandex-Webhooks")
+	req.Header.Set("X-Plandex-Event", string(event))
+	req.Header.Set("X-Plandex-Delivery", deliveryId)
+	req.Header.Set("X-Plandex-Timestamp", timestamp)
+	req.Header.Set("X-Plandex-Signature", "sha256="+Sign(webhook.Secret, timestamp, body))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("error sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, resp.Body)
+		return resp.StatusCode, nil
+	}
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseErrorLength))
+	return resp.StatusCode, fmt.Errorf("received status %d: %s", resp.StatusCode, string(respBody))
+}
+
+func isRetriableStatus(status int) bool {
+	return status == 0 || status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= 500
+}
+
+// Sign returns the hex-encoded HMAC-SHA256 of "<timestamp>.<body>" -- receivers recompute it with their secret to verify a request
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func GenerateSecret() (string, error) {
+	bytes := make([]byte, 32)
+	_, err := rand.Read(bytes)
+	if err != nil {
+		return "", fmt.Errorf("error generating webhook secret: %v", err)
+	}
+	return "whsec_" + hex.EncodeToString(bytes), nil
+}
+
+func ValidateUrl(rawUrl string) error {
+	u, err := url.Parse(rawUrl)
+	if err != nil {
+		return fmt.Errorf("invalid url: %v", err)
+	}
+
+	if u.Host == "" {
+		return fmt.Errorf("invalid url: missing host")
+	}
+
+	// hostnames are checked when each delivery connects, but private ip literals and localhost can be rejected up front
+	if !allowPrivateNetworks() {
+		host := u.Hostname()
+		if ip := net.ParseIP(host); (ip != nil && isBlockedIP(ip)) || host == "localhost" || strings.HasSuffix(host, ".localhost") {
+			return fmt.Errorf("webhook urls can't point to private, loopback, or link-local addresses")
+		}
+	}
+
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if os.Getenv("IS_CLOUD") != "" {
+			return fmt.Errorf("webhook urls must use https")
+		}
+	default:
+		return fmt.Errorf("webhook urls must use http or https")
+	}
+
+	return nil
+}
+
+func pruneDeliveryLog() {
+	lastPruneMu.Lock()
+	if time.Since(lastPruneAt) < deliveryLogPruneEvery {
+		lastPruneMu.Unlock()
+		return
+	}
+	lastPruneAt = time.Now()
+	lastPruneMu.Unlock()
+
+	err := db.DeleteWebhookDeliveriesBefore(time.Now().Add(-deliveryLogRetention))
+	if err != nil {
+		log.Printf("Error pruning webhook delivery log: %v\n", err)
+	}
+}
@@ -0,0 +1,317 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"plandex-server/db"
+	"plandex-server/shutdown"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	shared "plandex-shared"
+
+	"github.com/jmoiron/sqlx"
+)
+
+type receivedRequest struct {
+	header http.Header
+	body   []byte
+}
+
+// org role ids are cached per process by the db package, so all tests share one db
+func TestMain(m *testing.M) {
+	baseDir, err := os.MkdirTemp("", "plandex-webhooks-test")
+	if err != nil {
+		log.Fatalf("error creating temp dir: %v", err)
+	}
+
+	db.BaseDir = baseDir
+	shutdown.ShutdownCtx = context.Background()
+
+	// test servers listen on loopback -- tests of the address checks turn this back off
+	allowPrivateNetworks = func() bool { return true }
+	os.Setenv("DATABASE_URL", "sqlite://"+filepath.Join(baseDir, "test.db"))
+
+	err = db.Connect()
+	if err != nil {
+		log.Fatalf("error connecting: %v", err)
+	}
+
+	err = db.MigrationsUpWithDir("../migrations")
+	if err != nil {
+		log.Fatalf("error running migrations: %v", err)
+	}
+
+	code := m.Run()
+
+	db.Conn.Close()
+	os.RemoveAll(baseDir)
+	os.Exit(code)
+}
+
+func setupWebhook(t *testing.T, handler func(attempt int) int) (*db.Webhook, *[]receivedRequest) {
+	var org *db.Org
+	err := db.WithTx(context.Background(), "test setup", func(tx *sqlx.Tx) error {
+		user, err := db.CreateUser("Test", t.Name()+"@example.com", tx)
+		if err != nil {
+			return err
+		}
+		org, err = db.CreateOrg(&shared.CreateOrgRequest{Name: t.Name()}, user.Id, nil, tx)
+		return err
+	})
+	if err != nil {
+		t.Fatalf("error creating org: %v", err)
+	}
+
+	prevDelay := retryBaseDelay
+	retryBaseDelay = time.Millisecond
+	t.Cleanup(func() { retryBaseDelay = prevDelay })
+
+	var mu sync.Mutex
+	var received []receivedRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		received = append(received, receivedRequest{header: r.Header.Clone(), body: body})
+		attempt := len(received)
+		mu.Unlock()
+		w.WriteHeader(handler(attempt))
+	}))
+	t.Cleanup(server.Close)
+
+	webhook := &db.Webhook{
+		OrgId:  org.Id,
+		Url:    server.URL,
+		Secret: "whsec_test",
+		Events: shared.WebhookEventList{shared.WebhookEventPlanApplied},
+	}
+	err = db.CreateWebhook(webhook)
+	if err != nil {
+		t.Fatalf("error creating webhook: %v", err)
+	}
+
+	return webhook, &received
+}
+
+func TestDeliverRetriesAndSigns(t *testing.T) {
+	webhook, received := setupWebhook(t, func(attempt int) int {
+		if attempt == 1 {
+			return http.StatusInternalServerError
+		}
+		return http.StatusOK
+	})
+
+	err := dispatch(context.Background(), webhook.OrgId, shared.WebhookEventPlanApplied, &shared.WebhookEventData{
+		PlanName:  "plan",
+		CommitMsg: "applied changes",
+	})
+	if err != nil {
+		t.Fatalf("error dispatching: %v", err)
+	}
+
+	if len(*received) != 2 {
+		t.Fatalf("expected 2 requests, got %d", len(*received))
+	}
+
+	for _, req := range *received {
+		timestamp := req.header.Get("X-Plandex-Timestamp")
+		expected := "sha256=" + Sign(webhook.Secret, timestamp, req.body)
+		if req.header.Get("X-Plandex-Signature") != expected {
+			t.Errorf("signature mismatch: got %s, expected %s", req.header.Get("X-Plandex-Signature"), expected)
+		}
+		if req.header.Get("X-Plandex-Event") != string(shared.WebhookEventPlanApplied) {
+			t.Errorf("unexpected event header: %s", req.header.Get("X-Plandex-Event"))
+		}
+	}
+
+	first, second := (*received)[0], (*received)[1]
+	if first.header.Get("X-Plandex-Delivery") != second.header.Get("X-Plandex-Delivery") {
+		t.Error("expected delivery id to be stable across retries")
+	}
+
+	var payload shared.WebhookPayload
+	err = json.Unmarshal(second.body, &payload)
+	if err != nil {
+		t.Fatalf("error unmarshalling payload: %v", err)
+	}
+	if payload.Data == nil || payload.Data.CommitMsg != "applied changes" || payload.OrgId != webhook.OrgId {
+		t.Errorf("unexpected payload: %s", string(second.body))
+	}
+
+	deliveries, err := db.ListWebhookDeliveries(webhook.Id, 10)
+	if err != nil {
+		t.Fatalf("error listing deliveries: %v", err)
+	}
+	if len(deliveries) != 1 {
+		t.Fatalf("expected 1 logged delivery, got %d", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if delivery.Status != shared.WebhookDeliveryStatusSucceeded || delivery.NumAttempts != 2 {
+		t.Errorf("expected succeeded delivery after 2 attempts, got %s after %d", delivery.Status, delivery.NumAttempts)
+	}
+	if delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusOK || delivery.DeliveredAt == nil {
+		t.Errorf("expected delivered 200 response, got %+v", delivery)
+	}
+}
+
+func TestDeliverSkipsUnsubscribedEvents(t *testing.T) {
+	webhook, received := setupWebhook(t, func(attempt int) int {
+		return http.StatusOK
+	})
+
+	err := dispatch(context.Background(), webhook.OrgId, shared.WebhookEventPlanError, &shared.WebhookEventData{})
+	if err != nil {
+		t.Fatalf("error dispatching: %v", err)
+	}
+
+	if len(*received) != 0 {
+		t.Errorf("expected no requests, got %d", len(*received))
+	}
+}
+
+func TestDeliverDoesNotRetryClientErrors(t *testing.T) {
+	webhook, received := setupWebhook(t, func(attempt int) int {
+		return http.StatusBadRequest
+	})
+
+	delivery, err := deliver(context.Background(), webhook, shared.WebhookEventPlanApplied, &shared.WebhookEventData{}, maxDeliveryAttempts)
+	if err != nil {
+		t.Fatalf("error delivering: %v", err)
+	}
+
+	if len(*received) != 1 {
+		t.Errorf("expected 1 request, got %d", len(*received))
+	}
+	if delivery.Status != shared.WebhookDeliveryStatusFailed || delivery.NumAttempts != 1 || delivery.Error == nil {
+		t.Errorf("expected failed delivery after 1 attempt, got %+v", delivery)
+	}
+}
+
+func blockPrivateNetworks(t *testing.T) {
+	prev := allowPrivateNetworks
+	allowPrivateNetworks = func() bool { return false }
+	t.Cleanup(func() { allowPrivateNetworks = prev })
+}
+
+func TestDeliverBlocksPrivateAddresses(t *testing.T) {
+	webhook, received := setupWebhook(t, func(attempt int) int {
+		return http.StatusOK
+	})
+	blockPrivateNetworks(t)
+
+	delivery, err := SendPing(context.Background(), webhook)
+	if err != nil {
+		t.Fatalf("error delivering: %v", err)
+	}
+
+	if len(*received) != 0 {
+		t.Errorf("expected no requests, got %d", len(*received))
+	}
+	if delivery.Status != shared.WebhookDeliveryStatusFailed || delivery.ResponseStatus != nil || delivery.Error == nil || !strings.Contains(*delivery.Error, "private") {
+		t.Errorf("expected a blocked delivery, got %+v", delivery)
+	}
+}
+
+func TestDeliverDoesNotFollowRedirects(t *testing.T) {
+	webhook, _ := setupWebhook(t, func(attempt int) int {
+		return http.StatusOK
+	})
+
+	var targetHits atomic.Int32
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		targetHits.Add(1)
+		w.Write([]byte("internal response"))
+	}))
+	t.Cleanup(target.Close)
+
+	redirect := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, target.URL, http.StatusTemporaryRedirect)
+	}))
+	t.Cleanup(redirect.Close)
+
+	webhook.Url = redirect.URL
+
+	delivery, err := SendPing(context.Background(), webhook)
+	if err != nil {
+		t.Fatalf("error delivering: %v", err)
+	}
+
+	if targetHits.Load() != 0 {
+		t.Errorf("expected the redirect not to be followed")
+	}
+	if delivery.Status != shared.WebhookDeliveryStatusFailed || delivery.ResponseStatus == nil || *delivery.ResponseStatus != http.StatusTemporaryRedirect {
+		t.Errorf("expected a failed delivery with the redirect status, got %+v", delivery)
+	}
+	if delivery.Error != nil && strings.Contains(*delivery.Error, "internal response") {
+		t.Errorf("redirect target's response was logged: %s", *delivery.Error)
+	}
+}
+
+func TestIsBlockedIP(t *testing.T) {
+	tests := []struct {
+		ip      string
+		blocked bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.100.100.200", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"::", true},
+		{"fd00::1", true},
+		{"fe80::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"::ffff:169.254.169.254", true},
+		{"64:ff9b::a9fe:a9fe", true},
+		{"8.8.8.8", false},
+		{"93.184.216.34", false},
+		{"2606:4700:4700::1111", false},
+	}
+
+	for _, test := range tests {
+		if got := isBlockedIP(net.ParseIP(test.ip)); got != test.blocked {
+			t.Errorf("isBlockedIP(%s) = %v, want %v", test.ip, got, test.blocked)
+		}
+	}
+}
+
+func TestValidateUrl(t *testing.T) {
+	blockPrivateNetworks(t)
+
+	tests := []struct {
+		url   string
+		valid bool
+	}{
+		{"https://example.com/plandex", true},
+		{"http://example.com/plandex", true},
+		{"https://93.184.216.34/hook", true},
+		{"ftp://example.com", false},
+		{"https:///path", false},
+		{"http://localhost:8080/hook", false},
+		{"http://api.localhost/hook", false},
+		{"http://127.0.0.1/hook", false},
+		{"http://169.254.169.254/latest/meta-data", false},
+		{"http://[::1]:8080/hook", false},
+		{"http://10.0.0.5/hook", false},
+	}
+
+	for _, test := range tests {
+		err := ValidateUrl(test.url)
+		if (err == nil) != test.valid {
+			t.Errorf("ValidateUrl(%s): got err %v, want valid %v", test.url, err, test.valid)
+		}
+	}
+}
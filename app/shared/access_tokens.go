@@ -0,0 +1,80 @@
+package shared
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// AccessTokenPrefix marks personal access tokens so they can be told apart from sign-in session tokens
+const AccessTokenPrefix = "pdx_pat_"
+
+const MaxAccessTokenExpirationDays = 365
+
+func IsAccessToken(token string) bool {
+	return strings.HasPrefix(token, AccessTokenPrefix)
+}
+
+// PermissionList is stored as a json column
+type PermissionList []Permission
+
+func (l PermissionList) Includes(permission Permission) bool {
+	for _, p := range l {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *PermissionList) Scan(src interface{}) error {
+	if src == nil {
+		*l = nil
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, l)
+	case string:
+		return json.Unmarshal([]byte(s), l)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (l PermissionList) Value() (driver.Value, error) {
+	if l == nil {
+		l = PermissionList{}
+	}
+	return json.Marshal(l)
+}
+
+// Scope limits a user's permissions to the token's permissions. Resource-scoped permissions ('name|resourceId') are kept when their name is in the token's list.
+func (l PermissionList) Scope(perms Permissions) Permissions {
+	if len(l) == 0 {
+		return perms
+	}
+
+	res := Permissions{}
+	for p := range perms {
+		name := Permission(strings.Split(p, "|")[0])
+		if l.Includes(name) {
+			res[p] = true
+		}
+	}
+	return res
+}
+
+type AccessToken struct {
+	Id   string `json:"id"`
+	Name string `json:"name"`
+
+	// empty if the token has all of the user's permissions
+	Permissions PermissionList `json:"permissions"`
+
+	ExpiresAt  *time.Time `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
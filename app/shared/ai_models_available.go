@@ -42,6 +42,8 @@ When checking for sufficient credits on Plandex Cloud, we use MaxOutputTokens-In
 'PredictedOutputEnabled' is used to enable predicted output for the model (currently only supported by gpt-4o).
 
 'ApiKeyEnvVar' is the environment variable that contains the API key for the model.
+
+'Pricing' is the provider's list price per million tokens, used to estimate spend in the usage ledger on self-hosted servers. It's left nil when the price isn't known, in which case usage is still recorded but without a cost.
 */
 
 var AvailableModels = []*AvailableModel{
@@ -49,6 +51,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "OpenAI o3-mini-high",
 		DefaultMaxConvoTokens: 10000,
+		Pricing:               usdPerMillion(1.10, 4.40, 0.55),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenAI,
 			ModelName:                  "o3-mini",
@@ -68,6 +71,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "OpenAI o3-mini-medium",
 		DefaultMaxConvoTokens: 10000,
+		Pricing:               usdPerMillion(1.10, 4.40, 0.55),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenAI,
 			ModelName:                  "o3-mini",
@@ -87,6 +91,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "OpenAI o3-mini-low",
 		DefaultMaxConvoTokens: 10000,
+		Pricing:               usdPerMillion(1.10, 4.40, 0.55),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenAI,
 			ModelName:                  "o3-mini",
@@ -106,6 +111,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "OpenAI o1",
 		DefaultMaxConvoTokens: 15000,
+		Pricing:               usdPerMillion(15, 60, 7.50),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenAI,
 			ModelName:                  "o1",
@@ -124,6 +130,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "OpenAI gpt-4o",
 		DefaultMaxConvoTokens: 10000,
+		Pricing:               usdPerMillion(2.50, 10, 1.25),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenAI,
 			ModelName:                  "gpt-4o",
@@ -141,6 +148,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "OpenAI gpt-4o-mini",
 		DefaultMaxConvoTokens: 10000,
+		Pricing:               usdPerMillion(0.15, 0.60, 0.075),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenAI,
 			ModelName:                  "gpt-4o-mini",
@@ -156,10 +164,67 @@ var AvailableModels = []*AvailableModel{
 		},
 	},
 
+	// Direct Anthropic models
+	{
+		Description:           "Anthropic Claude 3.7 Sonnet",
+		DefaultMaxConvoTokens: 15000,
+		Pricing:               usdPerMillion(3, 15, 0.30),
+		BaseModelConfig: BaseModelConfig{
+			Provider:                   ModelProviderAnthropic,
+			ModelName:                  "claude-3-7-sonnet-latest",
+			ModelId:                    "claude-3.7-sonnet",
+			MaxTokens:                  200000,
+			MaxOutputTokens:            64000,
+			ReservedOutputTokens:       20000,
+			SupportsCacheControl:       true,
+			ApiKeyEnvVar:               ApiKeyByProvider[ModelProviderAnthropic],
+			ModelCompatibility:         fullCompatibility,
+			BaseUrl:                    BaseUrlByProvider[ModelProviderAnthropic],
+			PreferredModelOutputFormat: ModelOutputFormatXml,
+		},
+	},
+	{
+		Description:           "Anthropic Claude 3.5 Sonnet",
+		DefaultMaxConvoTokens: 15000,
+		Pricing:               usdPerMillion(3, 15, 0.30),
+		BaseModelConfig: BaseModelConfig{
+			Provider:                   ModelProviderAnthropic,
+			ModelName:                  "claude-3-5-sonnet-latest",
+			ModelId:                    "claude-3.5-sonnet",
+			MaxTokens:                  200000,
+			MaxOutputTokens:            8192,
+			ReservedOutputTokens:       8192,
+			SupportsCacheControl:       true,
+			ApiKeyEnvVar:               ApiKeyByProvider[ModelProviderAnthropic],
+			ModelCompatibility:         fullCompatibility,
+			BaseUrl:                    BaseUrlByProvider[ModelProviderAnthropic],
+			PreferredModelOutputFormat: ModelOutputFormatXml,
+		},
+	},
+	{
+		Description:           "Anthropic Claude 3.5 Haiku",
+		DefaultMaxConvoTokens: 15000,
+		Pricing:               usdPerMillion(0.80, 4, 0.08),
+		BaseModelConfig: BaseModelConfig{
+			Provider:                   ModelProviderAnthropic,
+			ModelName:                  "claude-3-5-haiku-latest",
+			ModelId:                    "claude-3.5-haiku",
+			MaxTokens:                  200000,
+			MaxOutputTokens:            8192,
+			ReservedOutputTokens:       8192,
+			SupportsCacheControl:       true,
+			ApiKeyEnvVar:               ApiKeyByProvider[ModelProviderAnthropic],
+			ModelCompatibility:         fullCompatibility,
+			BaseUrl:                    BaseUrlByProvider[ModelProviderAnthropic],
+			PreferredModelOutputFormat: ModelOutputFormatXml,
+		},
+	},
+
 	// OpenRouter models
 	{
 		Description:           "Anthropic Claude 3.7 Sonnet via OpenRouter",
 		DefaultMaxConvoTokens: 15000,
+		Pricing:               usdPerMillion(3, 15, 0.30),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenRouter,
 			ModelName:                  "anthropic/claude-3.7-sonnet",
@@ -177,6 +242,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "Anthropic Claude 3.5 Sonnet via OpenRouter",
 		DefaultMaxConvoTokens: 15000,
+		Pricing:               usdPerMillion(3, 15, 0.30),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenRouter,
 			ModelName:                  "anthropic/claude-3.5-sonnet",
@@ -194,6 +260,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "Anthropic Claude 3.5 Haiku via OpenRouter",
 		DefaultMaxConvoTokens: 15000,
+		Pricing:               usdPerMillion(0.80, 4, 0.08),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenRouter,
 			ModelName:                  "anthropic/claude-3.5-haiku",
@@ -211,6 +278,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "Google Gemini Pro 1.5 via OpenRouter",
 		DefaultMaxConvoTokens: 100000,
+		Pricing:               usdPerMillion(1.25, 5, 0),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenRouter,
 			ModelName:                  "google/gemini-pro-1.5",
@@ -227,6 +295,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "Google Gemini Pro 2.0 Experimental via OpenRouter",
 		DefaultMaxConvoTokens: 100000,
+		Pricing:               usdPerMillion(0, 0, 0),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenRouter,
 			ModelName:                  "google/gemini-2.0-pro-exp-02-05:free",
@@ -243,6 +312,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "Google Gemini Flash 2.0 via OpenRouter",
 		DefaultMaxConvoTokens: 75000,
+		Pricing:               usdPerMillion(0.10, 0.40, 0.025),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenRouter,
 			ModelName:                  "google/gemini-2.0-flash-001",
@@ -318,6 +388,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "Perplexity R1 1776 via OpenRouter (includes reasoning)",
 		DefaultMaxConvoTokens: 7500,
+		Pricing:               usdPerMillion(2, 8, 0),
 		BaseModelConfig: BaseModelConfig{
 			Provider:             ModelProviderOpenRouter,
 			ModelName:            "perplexity/r1-1776",
@@ -338,6 +409,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "Perplexity Sonar Reasoning via OpenRouter (includes reasoning)",
 		DefaultMaxConvoTokens: 7500,
+		Pricing:               usdPerMillion(1, 5, 0),
 		BaseModelConfig: BaseModelConfig{
 			Provider:             ModelProviderOpenRouter,
 			ModelName:            "perplexity/sonar-reasoning",
@@ -414,6 +486,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "OpenAI o3-mini-high via OpenRouter",
 		DefaultMaxConvoTokens: 10000,
+		Pricing:               usdPerMillion(1.10, 4.40, 0.55),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenRouter,
 			ModelName:                  "openai/o3-mini",
@@ -434,6 +507,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "OpenAI o3-mini-medium via OpenRouter",
 		DefaultMaxConvoTokens: 10000,
+		Pricing:               usdPerMillion(1.10, 4.40, 0.55),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenRouter,
 			ModelName:                  "openai/o3-mini",
@@ -454,6 +528,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "OpenAI o3-mini-low via OpenRouter",
 		DefaultMaxConvoTokens: 10000,
+		Pricing:               usdPerMillion(1.10, 4.40, 0.55),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenRouter,
 			ModelName:                  "openai/o3-mini",
@@ -474,6 +549,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "OpenAI o1 via OpenRouter",
 		DefaultMaxConvoTokens: 15000,
+		Pricing:               usdPerMillion(15, 60, 7.50),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenRouter,
 			ModelName:                  "openai/o1",
@@ -492,6 +568,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "OpenAI gpt-4o via OpenRouter",
 		DefaultMaxConvoTokens: 10000,
+		Pricing:               usdPerMillion(2.50, 10, 1.25),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenRouter,
 			ModelName:                  "openai/gpt-4o",
@@ -509,6 +586,7 @@ var AvailableModels = []*AvailableModel{
 	{
 		Description:           "OpenAI gpt-4o-mini via OpenRouter",
 		DefaultMaxConvoTokens: 10000,
+		Pricing:               usdPerMillion(0.15, 0.60, 0.075),
 		BaseModelConfig: BaseModelConfig{
 			Provider:                   ModelProviderOpenRouter,
 			ModelName:                  "openai/gpt-4o-mini",
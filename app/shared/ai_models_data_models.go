@@ -5,10 +5,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"time"
+
+	"github.com/shopspring/decimal"
 )
 
 type ModelCompatibility struct {
-	HasImageSupport bool `json:"hasImageSupport"`
+	HasImageSupport bool `json:"hasImageSupport" yaml:"hasImageSupport,omitempty"`
 }
 
 type ModelOutputFormat string
@@ -23,33 +25,70 @@ type ModelName string
 type ModelId string
 
 type BaseModelConfig struct {
-	Provider                   ModelProvider     `json:"provider"`
-	CustomProvider             *string           `json:"customProvider,omitempty"`
-	BaseUrl                    string            `json:"baseUrl"`
-	ModelName                  ModelName         `json:"modelName"`
-	ModelId                    ModelId           `json:"modelId"`
-	MaxTokens                  int               `json:"maxTokens"`
-	MaxOutputTokens            int               `json:"maxOutputTokens"`
-	ReservedOutputTokens       int               `json:"reservedOutputTokens"`
-	ApiKeyEnvVar               string            `json:"apiKeyEnvVar"`
-	PreferredModelOutputFormat ModelOutputFormat `json:"preferredModelOutputFormat"`
-	SystemPromptDisabled       bool              `json:"systemPromptDisabled"`
-	RoleParamsDisabled         bool              `json:"roleParamsDisabled"`
-	PredictedOutputEnabled     bool              `json:"predictedOutputEnabled"`
-	ReasoningEffortEnabled     bool              `json:"reasoningEffortEnabled"`
-	ReasoningEffort            ReasoningEffort   `json:"reasoningEffort"`
-	IncludeReasoning           bool              `json:"includeReasoning"`
-	SupportsCacheControl       bool              `json:"supportsCacheControl"`
-	ModelCompatibility
+	Provider                   ModelProvider     `json:"provider" yaml:"provider,omitempty"`
+	CustomProvider             *string           `json:"customProvider,omitempty" yaml:"customProvider,omitempty"`
+	BaseUrl                    string            `json:"baseUrl" yaml:"baseUrl,omitempty"`
+	ModelName                  ModelName         `json:"modelName" yaml:"modelName,omitempty"`
+	ModelId                    ModelId           `json:"modelId" yaml:"modelId,omitempty"`
+	MaxTokens                  int               `json:"maxTokens" yaml:"maxTokens,omitempty"`
+	MaxOutputTokens            int               `json:"maxOutputTokens" yaml:"maxOutputTokens,omitempty"`
+	ReservedOutputTokens       int               `json:"reservedOutputTokens" yaml:"reservedOutputTokens,omitempty"`
+	ApiKeyEnvVar               string            `json:"apiKeyEnvVar" yaml:"apiKeyEnvVar,omitempty"`
+	PreferredModelOutputFormat ModelOutputFormat `json:"preferredModelOutputFormat" yaml:"preferredModelOutputFormat,omitempty"`
+	SystemPromptDisabled       bool              `json:"systemPromptDisabled" yaml:"systemPromptDisabled,omitempty"`
+	RoleParamsDisabled         bool              `json:"roleParamsDisabled" yaml:"roleParamsDisabled,omitempty"`
+	PredictedOutputEnabled     bool              `json:"predictedOutputEnabled" yaml:"predictedOutputEnabled,omitempty"`
+	ReasoningEffortEnabled     bool              `json:"reasoningEffortEnabled" yaml:"reasoningEffortEnabled,omitempty"`
+	ReasoningEffort            ReasoningEffort   `json:"reasoningEffort" yaml:"reasoningEffort,omitempty"`
+	IncludeReasoning           bool              `json:"includeReasoning" yaml:"includeReasoning,omitempty"`
+	SupportsCacheControl       bool              `json:"supportsCacheControl" yaml:"supportsCacheControl,omitempty"`
+	ModelCompatibility         `yaml:",inline"`
 }
 
 type AvailableModel struct {
-	Id string `json:"id"`
-	BaseModelConfig
-	Description           string    `json:"description"`
-	DefaultMaxConvoTokens int       `json:"defaultMaxConvoTokens"`
-	CreatedAt             time.Time `json:"createdAt"`
-	UpdatedAt             time.Time `json:"updatedAt"`
+	Id                    string `json:"id" yaml:"-"`
+	BaseModelConfig       `yaml:",inline"`
+	Description           string        `json:"description" yaml:"description,omitempty"`
+	DefaultMaxConvoTokens int           `json:"defaultMaxConvoTokens" yaml:"defaultMaxConvoTokens,omitempty"`
+	Pricing               *ModelPricing `json:"pricing,omitempty" yaml:"pricing,omitempty"`
+	CreatedAt             time.Time     `json:"createdAt" yaml:"-"`
+	UpdatedAt             time.Time     `json:"updatedAt" yaml:"-"`
+}
+
+// ModelPricing is a model's price in USD per million tokens. Cached input tokens are billed at CachedInputPerMillion, or at InputPerMillion if the provider has no separate cached price.
+type ModelPricing struct {
+	InputPerMillion       decimal.Decimal `json:"inputPerMillion" yaml:"inputPerMillion"`
+	OutputPerMillion      decimal.Decimal `json:"outputPerMillion" yaml:"outputPerMillion"`
+	CachedInputPerMillion decimal.Decimal `json:"cachedInputPerMillion" yaml:"cachedInputPerMillion,omitempty"`
+}
+
+// Cost returns the price of a single model call. inputTokens includes cachedTokens, matching how providers report usage.
+func (p *ModelPricing) Cost(inputTokens, outputTokens, cachedTokens int) decimal.Decimal {
+	million := decimal.NewFromInt(1000000)
+
+	cachedPrice := p.CachedInputPerMillion
+	if cachedPrice.IsZero() {
+		cachedPrice = p.InputPerMillion
+	}
+
+	uncached := inputTokens - cachedTokens
+	if uncached < 0 {
+		uncached = 0
+	}
+
+	cost := p.InputPerMillion.Mul(decimal.NewFromInt(int64(uncached)))
+	cost = cost.Add(cachedPrice.Mul(decimal.NewFromInt(int64(cachedTokens))))
+	cost = cost.Add(p.OutputPerMillion.Mul(decimal.NewFromInt(int64(outputTokens))))
+
+	return cost.Div(million)
+}
+
+func usdPerMillion(input, output, cachedInput float64) *ModelPricing {
+	return &ModelPricing{
+		InputPerMillion:       decimal.NewFromFloat(input),
+		OutputPerMillion:      decimal.NewFromFloat(output),
+		CachedInputPerMillion: decimal.NewFromFloat(cachedInput),
+	}
 }
 
 func (m *AvailableModel) ModelString() string {
@@ -62,7 +101,7 @@ func (m *AvailableModel) ModelString() string {
 }
 
 type PlannerModelConfig struct {
-	MaxConvoTokens int `json:"maxConvoTokens"`
+	MaxConvoTokens int `json:"maxConvoTokens" yaml:"maxConvoTokens,omitempty"`
 }
 
 type ReasoningEffort string
@@ -74,18 +113,18 @@ const (
 )
 
 type ModelRoleConfig struct {
-	Role                 ModelRole       `json:"role"`
-	BaseModelConfig      BaseModelConfig `json:"baseModelConfig"`
-	Temperature          float32         `json:"temperature"`
-	TopP                 float32         `json:"topP"`
-	ReservedOutputTokens int             `json:"reservedOutputTokens"`
-	ReasoningEffort      ReasoningEffort `json:"reasoningEffort"`
-
-	LargeContextFallback *ModelRoleConfig `json:"largeContextFallback"`
-	LargeOutputFallback  *ModelRoleConfig `json:"largeOutputFallback"`
-	// ErrorFallback        *ModelRoleConfig `json:"errorFallback"`
-
-	StrongModel *ModelRoleConfig `json:"strongModel"`
+	Role                 ModelRole       `json:"role" yaml:"role"`
+	BaseModelConfig      BaseModelConfig `json:"baseModelConfig" yaml:"baseModelConfig"`
+	Temperature          float32         `json:"temperature" yaml:"temperature,omitempty"`
+	TopP                 float32         `json:"topP" yaml:"topP,omitempty"`
+	ReservedOutputTokens int             `json:"reservedOutputTokens" yaml:"reservedOutputTokens,omitempty"`
+	ReasoningEffort      ReasoningEffort `json:"reasoningEffort" yaml:"reasoningEffort,omitempty"`
+
+	LargeContextFallback *ModelRoleConfig `json:"largeContextFallback" yaml:"largeContextFallback,omitempty"`
+	LargeOutputFallback  *ModelRoleConfig `json:"largeOutputFallback" yaml:"largeOutputFallback,omitempty"`
+	ErrorFallback        *ModelRoleConfig `json:"errorFallback" yaml:"errorFallback,omitempty"`
+
+	StrongModel *ModelRoleConfig `json:"strongModel" yaml:"strongModel,omitempty"`
 }
 
 func (m ModelRoleConfig) GetReservedOutputTokens() int {
@@ -157,6 +196,35 @@ func (m ModelRoleConfig) GetFinalLargeOutputFallback() ModelRoleConfig {
 	return currentConfig
 }
 
+// returns the nth model (1-indexed) in the error fallback chain, or nil if the chain is shorter than n
+func (m ModelRoleConfig) GetErrorFallback(n int) *ModelRoleConfig {
+	if n > maxFallbackDepth {
+		return nil
+	}
+
+	currentConfig := &m
+	for i := 0; i < n; i++ {
+		if currentConfig.ErrorFallback == nil {
+			return nil
+		}
+		currentConfig = currentConfig.ErrorFallback
+	}
+
+	return currentConfig
+}
+
+func (m ModelRoleConfig) GetErrorFallbackChain() []ModelRoleConfig {
+	var chain []ModelRoleConfig
+	for n := 1; n <= maxFallbackDepth; n++ {
+		fallback := m.GetErrorFallback(n)
+		if fallback == nil {
+			break
+		}
+		chain = append(chain, *fallback)
+	}
+	return chain
+}
+
 // note that if the token number exeeds all the fallback models, it will return the last fallback model
 func (m ModelRoleConfig) GetRoleForInputTokens(inputTokens int) ModelRoleConfig {
 	var currentConfig ModelRoleConfig = m
@@ -205,9 +273,9 @@ func (m ModelRoleConfig) GetRoleForOutputTokens(outputTokens int) ModelRoleConfi
 }
 
 type PlannerRoleConfig struct {
-	ModelRoleConfig
-	PlannerModelConfig
-	PlannerLargeContextFallback *PlannerRoleConfig `json:"plannerLargeContextFallback"`
+	ModelRoleConfig             `yaml:",inline"`
+	PlannerModelConfig          `yaml:",inline"`
+	PlannerLargeContextFallback *PlannerRoleConfig `json:"plannerLargeContextFallback" yaml:"plannerLargeContextFallback,omitempty"`
 	// PlannerErrorFallback        *PlannerRoleConfig `json:"plannerErrorFallback"`
 }
 
@@ -273,18 +341,18 @@ func (p PlannerRoleConfig) Value() (driver.Value, error) {
 }
 
 type ModelPack struct {
-	Id               string            `json:"id"`
-	Name             string            `json:"name"`
-	Description      string            `json:"description"`
-	Planner          PlannerRoleConfig `json:"planner"`
-	Coder            *ModelRoleConfig  `json:"coder"`
-	PlanSummary      ModelRoleConfig   `json:"planSummary"`
-	Builder          ModelRoleConfig   `json:"builder"`
-	WholeFileBuilder *ModelRoleConfig  `json:"wholeFileBuilder"` // optional, defaults to builder model — access via GetWholeFileBuilder()
-	Namer            ModelRoleConfig   `json:"namer"`
-	CommitMsg        ModelRoleConfig   `json:"commitMsg"`
-	ExecStatus       ModelRoleConfig   `json:"execStatus"`
-	Architect        *ModelRoleConfig  `json:"contextLoader"`
+	Id               string            `json:"id" yaml:"-"`
+	Name             string            `json:"name" yaml:"name"`
+	Description      string            `json:"description" yaml:"description,omitempty"`
+	Planner          PlannerRoleConfig `json:"planner" yaml:"planner"`
+	Coder            *ModelRoleConfig  `json:"coder" yaml:"coder,omitempty"`
+	PlanSummary      ModelRoleConfig   `json:"planSummary" yaml:"planSummary"`
+	Builder          ModelRoleConfig   `json:"builder" yaml:"builder"`
+	WholeFileBuilder *ModelRoleConfig  `json:"wholeFileBuilder" yaml:"wholeFileBuilder,omitempty"` // optional, defaults to builder model — access via GetWholeFileBuilder()
+	Namer            ModelRoleConfig   `json:"namer" yaml:"namer"`
+	CommitMsg        ModelRoleConfig   `json:"commitMsg" yaml:"commitMsg"`
+	ExecStatus       ModelRoleConfig   `json:"execStatus" yaml:"execStatus"`
+	Architect        *ModelRoleConfig  `json:"contextLoader" yaml:"architect,omitempty"`
 }
 
 func (m *ModelPack) GetCoder() ModelRoleConfig {
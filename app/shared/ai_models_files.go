@@ -0,0 +1,237 @@
+package shared
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ModelsFile is an org's custom models and model packs in a form that can be kept in a YAML file and synced with 'plandex models sync'. Custom models are matched by provider and model name, and model packs by name.
+type ModelsFile struct {
+	Models     []*AvailableModel `json:"models" yaml:"models,omitempty"`
+	ModelPacks []*ModelPack      `json:"modelPacks" yaml:"modelPacks,omitempty"`
+}
+
+func (f *ModelsFile) Validate() error {
+	modelKeys := map[string]bool{}
+	for i, model := range f.Models {
+		if model == nil {
+			return fmt.Errorf("models[%d] is empty", i)
+		}
+		err := model.Validate()
+		if err != nil {
+			return fmt.Errorf("model %s: %v", model.CustomModelKey(), err)
+		}
+		key := model.CustomModelKey()
+		if modelKeys[key] {
+			return fmt.Errorf("model %s is listed more than once", key)
+		}
+		modelKeys[key] = true
+	}
+
+	packNames := map[string]bool{}
+	for i, pack := range f.ModelPacks {
+		if pack == nil {
+			return fmt.Errorf("modelPacks[%d] is empty", i)
+		}
+		err := pack.Validate()
+		if err != nil {
+			return fmt.Errorf("model pack %s: %v", pack.Name, err)
+		}
+		if packNames[pack.Name] {
+			return fmt.Errorf("model pack %s is listed more than once", pack.Name)
+		}
+		packNames[pack.Name] = true
+	}
+
+	return nil
+}
+
+// CustomModelKey identifies a custom model within an org
+func (m *AvailableModel) CustomModelKey() string {
+	provider := string(m.Provider)
+	if m.Provider == ModelProviderCustom && m.CustomProvider != nil {
+		provider = *m.CustomProvider
+	}
+	return provider + " → " + string(m.ModelName)
+}
+
+func (m *AvailableModel) Validate() error {
+	err := m.BaseModelConfig.Validate()
+	if err != nil {
+		return err
+	}
+	if m.DefaultMaxConvoTokens < 0 {
+		return fmt.Errorf("defaultMaxConvoTokens can't be negative")
+	}
+	if m.Pricing != nil {
+		if m.Pricing.InputPerMillion.IsNegative() || m.Pricing.OutputPerMillion.IsNegative() || m.Pricing.CachedInputPerMillion.IsNegative() {
+			return fmt.Errorf("pricing can't be negative")
+		}
+	}
+	return nil
+}
+
+// Validate checks that a model config has everything needed to call the model
+func (c *BaseModelConfig) Validate() error {
+	validProvider := false
+	for _, provider := range AllModelProviders {
+		if string(c.Provider) == provider {
+			validProvider = true
+			break
+		}
+	}
+	if !validProvider {
+		return fmt.Errorf("invalid provider: %q", c.Provider)
+	}
+
+	if c.Provider == ModelProviderCustom {
+		if c.CustomProvider == nil || strings.TrimSpace(*c.CustomProvider) == "" {
+			return fmt.Errorf("customProvider is required for the custom provider")
+		}
+		if c.BaseUrl == "" {
+			return fmt.Errorf("baseUrl is required for the custom provider")
+		}
+	}
+
+	if strings.TrimSpace(string(c.ModelName)) == "" {
+		return fmt.Errorf("modelName is required")
+	}
+	if c.MaxTokens <= 0 {
+		return fmt.Errorf("maxTokens must be greater than zero")
+	}
+	if c.MaxOutputTokens <= 0 {
+		return fmt.Errorf("maxOutputTokens must be greater than zero")
+	}
+	if c.ReservedOutputTokens < 0 || c.ReservedOutputTokens > c.MaxTokens {
+		return fmt.Errorf("reservedOutputTokens must be between 0 and maxTokens")
+	}
+
+	switch c.PreferredModelOutputFormat {
+	case "", ModelOutputFormatXml, ModelOutputFormatToolCallJson:
+	default:
+		return fmt.Errorf("invalid preferredModelOutputFormat: %q", c.PreferredModelOutputFormat)
+	}
+
+	return validateReasoningEffort(c.ReasoningEffort)
+}
+
+// Validate checks every role config, including fallbacks. Coder, architect, and wholeFileBuilder are optional.
+func (m *ModelPack) Validate() error {
+	if strings.TrimSpace(m.Name) == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	err := m.Planner.validate(0)
+	if err != nil {
+		return fmt.Errorf("planner: %v", err)
+	}
+
+	roles := []struct {
+		key    string
+		role   ModelRole
+		config *ModelRoleConfig
+	}{
+		{"planSummary", ModelRolePlanSummary, &m.PlanSummary},
+		{"builder", ModelRoleBuilder, &m.Builder},
+		{"namer", ModelRoleName, &m.Namer},
+		{"commitMsg", ModelRoleCommitMsg, &m.CommitMsg},
+		{"execStatus", ModelRoleExecStatus, &m.ExecStatus},
+		{"coder", ModelRoleCoder, m.Coder},
+		{"wholeFileBuilder", ModelRoleWholeFileBuilder, m.WholeFileBuilder},
+		{"architect", ModelRoleArchitect, m.Architect},
+	}
+
+	for _, r := range roles {
+		if r.config == nil {
+			continue
+		}
+		err := r.config.validate(r.role, 0)
+		if err != nil {
+			return fmt.Errorf("%s: %v", r.key, err)
+		}
+	}
+
+	return nil
+}
+
+func (p *PlannerRoleConfig) validate(depth int) error {
+	if depth > maxFallbackDepth {
+		return fmt.Errorf("too many nested fallbacks")
+	}
+
+	err := p.ModelRoleConfig.validate(ModelRolePlanner, depth)
+	if err != nil {
+		return err
+	}
+	if p.MaxConvoTokens < 0 {
+		return fmt.Errorf("maxConvoTokens can't be negative")
+	}
+
+	if p.PlannerLargeContextFallback != nil {
+		err = p.PlannerLargeContextFallback.validate(depth + 1)
+		if err != nil {
+			return fmt.Errorf("plannerLargeContextFallback: %v", err)
+		}
+	}
+
+	return nil
+}
+
+func (m *ModelRoleConfig) validate(role ModelRole, depth int) error {
+	if depth > maxFallbackDepth {
+		return fmt.Errorf("too many nested fallbacks")
+	}
+
+	if m.Role != role {
+		return fmt.Errorf("role must be %q, got %q", role, m.Role)
+	}
+
+	err := m.BaseModelConfig.Validate()
+	if err != nil {
+		return fmt.Errorf("baseModelConfig: %v", err)
+	}
+
+	if m.Temperature < 0 || m.Temperature > 2 {
+		return fmt.Errorf("temperature must be between 0 and 2")
+	}
+	if m.TopP < 0 || m.TopP > 1 {
+		return fmt.Errorf("topP must be between 0 and 1")
+	}
+	if m.ReservedOutputTokens < 0 {
+		return fmt.Errorf("reservedOutputTokens can't be negative")
+	}
+
+	err = validateReasoningEffort(m.ReasoningEffort)
+	if err != nil {
+		return err
+	}
+
+	fallbacks := []struct {
+		key    string
+		config *ModelRoleConfig
+	}{
+		{"largeContextFallback", m.LargeContextFallback},
+		{"largeOutputFallback", m.LargeOutputFallback},
+		{"errorFallback", m.ErrorFallback},
+		{"strongModel", m.StrongModel},
+	}
+	for _, f := range fallbacks {
+		if f.config == nil {
+			continue
+		}
+		err := f.config.validate(role, depth+1)
+		if err != nil {
+			return fmt.Errorf("%s: %v", f.key, err)
+		}
+	}
+
+	return nil
+}
+
+func validateReasoningEffort(effort ReasoningEffort) error {
+	switch effort {
+	case "", ReasoningEffortLow, ReasoningEffortMedium, ReasoningEffortHigh:
+		return nil
+	}
+	return fmt.Errorf("invalid reasoningEffort: %q", effort)
+}
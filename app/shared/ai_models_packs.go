@@ -211,8 +211,8 @@ func init() {
 type modelConfig struct {
 	largeContextFallback *ModelRoleConfig
 	largeOutputFallback  *ModelRoleConfig
-	// errorFallback        *ModelRoleConfig
-	strongModel *ModelRoleConfig
+	errorFallback        *ModelRoleConfig
+	strongModel          *ModelRoleConfig
 }
 
 func getModelConfig(role ModelRole, provider ModelProvider, modelId ModelId, fallbacks *modelConfig) *ModelRoleConfig {
@@ -228,8 +228,8 @@ func getModelConfig(role ModelRole, provider ModelProvider, modelId ModelId, fal
 
 		LargeContextFallback: fallbacks.largeContextFallback,
 		LargeOutputFallback:  fallbacks.largeOutputFallback,
-		// ErrorFallback:        fallbacks.errorFallback,
-		StrongModel: fallbacks.strongModel,
+		ErrorFallback:        fallbacks.errorFallback,
+		StrongModel:          fallbacks.strongModel,
 	}
 }
 
@@ -6,17 +6,32 @@ const OpenAIV1BaseUrl = "https://api.openai.com/v1"
 const OpenRouterApiKeyEnvVar = "OPENROUTER_API_KEY"
 const OpenRouterBaseUrl = "https://openrouter.ai/api/v1"
 
+const AnthropicApiKeyEnvVar = "ANTHROPIC_API_KEY"
+const AnthropicV1BaseUrl = "https://api.anthropic.com/v1"
+
+// local servers don't require an api key by default, but one can be set if the server is configured to require it
+const OllamaApiKeyEnvVar = "OLLAMA_API_KEY"
+const OllamaDefaultHost = "http://localhost:11434"
+const OllamaV1BaseUrl = OllamaDefaultHost + "/v1"
+
+const LlamaCppApiKeyEnvVar = "LLAMA_API_KEY"
+const LlamaCppCustomProvider = "llama.cpp"
+
 type ModelProvider string
 
 const (
 	ModelProviderOpenRouter ModelProvider = "openrouter"
 	ModelProviderOpenAI     ModelProvider = "openai"
+	ModelProviderAnthropic  ModelProvider = "anthropic"
+	ModelProviderOllama     ModelProvider = "ollama"
 	ModelProviderCustom     ModelProvider = "custom"
 )
 
 var AllModelProviders = []string{
 	string(ModelProviderOpenAI),
 	string(ModelProviderOpenRouter),
+	string(ModelProviderAnthropic),
+	string(ModelProviderOllama),
 	// string(ModelProviderTogether),
 	string(ModelProviderCustom),
 }
@@ -24,9 +39,24 @@ var AllModelProviders = []string{
 var BaseUrlByProvider = map[ModelProvider]string{
 	ModelProviderOpenAI:     OpenAIV1BaseUrl,
 	ModelProviderOpenRouter: OpenRouterBaseUrl,
+	ModelProviderAnthropic:  AnthropicV1BaseUrl,
+	ModelProviderOllama:     OllamaV1BaseUrl,
 }
 
 var ApiKeyByProvider = map[ModelProvider]string{
 	ModelProviderOpenAI:     OpenAIEnvVar,
 	ModelProviderOpenRouter: OpenRouterApiKeyEnvVar,
+	ModelProviderAnthropic:  AnthropicApiKeyEnvVar,
+	ModelProviderOllama:     OllamaApiKeyEnvVar,
+}
+
+// api keys for these env vars are sent if set, but aren't required
+var OptionalApiKeyEnvVars = map[string]bool{
+	OllamaApiKeyEnvVar:   true,
+	LlamaCppApiKeyEnvVar: true,
+}
+
+// providers that run on the user's own machine or network -- these can't be reached from Plandex Cloud
+var LocalModelProviders = map[ModelProvider]bool{
+	ModelProviderOllama: true,
 }
@@ -17,6 +17,8 @@ const (
 
 	ApiErrorTypeContinueNoMessages ApiErrorType = "continue_no_messages"
 
+	ApiErrorTypeBudgetExceeded ApiErrorType = "budget_exceeded"
+
 	ApiErrorTypeCloudInsufficientCredits ApiErrorType = "cloud_insufficient_credits"
 	ApiErrorTypeCloudMonthlyMaxReached   ApiErrorType = "cloud_monthly_max_reached"
 	ApiErrorTypeCloudSubscriptionPaused  ApiErrorType = "cloud_subscription_paused"
@@ -51,6 +53,9 @@ type ApiError struct {
 
 	// only used for billing errors
 	BillingError *BillingError `json:"billingError,omitempty"`
+
+	// only used for budget exceeded errors
+	BudgetExceededError *BudgetStatus `json:"budgetExceededError,omitempty"`
 }
 
 func (e *ApiError) Error() string {
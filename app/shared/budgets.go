@@ -0,0 +1,142 @@
+package shared
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+const DefaultBudgetWarnAtPct = 80
+
+// SpendBudgets caps model usage for an org on a self-hosted server. Each limit can be set in tokens (input + output), in USD (priced from the usage ledger), or both. Limits that aren't set aren't enforced.
+//
+// Budgets are checked before every model call. A call that would go over a limit is stopped with a budget exceeded error, and calls that reach WarnAtPct of a limit stream a warning to the client.
+type SpendBudgets struct {
+	PerPlan     *BudgetLimit `json:"perPlan,omitempty"`
+	PerUserDay  *BudgetLimit `json:"perUserDay,omitempty"`
+	PerOrgMonth *BudgetLimit `json:"perOrgMonth,omitempty"`
+
+	WarnAtPct int `json:"warnAtPct,omitempty"`
+}
+
+type BudgetLimit struct {
+	Tokens int              `json:"tokens,omitempty"`
+	Usd    *decimal.Decimal `json:"usd,omitempty"`
+}
+
+func (l *BudgetLimit) IsEmpty() bool {
+	return l == nil || (l.Tokens == 0 && l.Usd == nil)
+}
+
+func (b *SpendBudgets) Scan(src interface{}) error {
+	if src == nil {
+		*b = SpendBudgets{}
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, b)
+	case string:
+		return json.Unmarshal([]byte(s), b)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (b SpendBudgets) Value() (driver.Value, error) {
+	return json.Marshal(b)
+}
+
+func (b *SpendBudgets) Validate() error {
+	for _, limit := range []*BudgetLimit{b.PerPlan, b.PerUserDay, b.PerOrgMonth} {
+		if limit == nil {
+			continue
+		}
+		if limit.Tokens < 0 {
+			return fmt.Errorf("token limits can't be negative")
+		}
+		if limit.Usd != nil && !limit.Usd.IsPositive() {
+			return fmt.Errorf("USD limits must be greater than zero")
+		}
+	}
+	if b.WarnAtPct < 0 || b.WarnAtPct > 100 {
+		return fmt.Errorf("warnAtPct must be between 0 and 100")
+	}
+	return nil
+}
+
+func (b *SpendBudgets) IsEmpty() bool {
+	return b.PerPlan.IsEmpty() && b.PerUserDay.IsEmpty() && b.PerOrgMonth.IsEmpty()
+}
+
+func (b *SpendBudgets) GetWarnAtPct() int {
+	if b.WarnAtPct == 0 {
+		return DefaultBudgetWarnAtPct
+	}
+	return b.WarnAtPct
+}
+
+type BudgetScope string
+
+const (
+	BudgetScopePlan     BudgetScope = "plan"
+	BudgetScopeUserDay  BudgetScope = "userDay"
+	BudgetScopeOrgMonth BudgetScope = "orgMonth"
+)
+
+var budgetScopeLabels = map[BudgetScope]string{
+	BudgetScopePlan:     "plan budget",
+	BudgetScopeUserDay:  "daily budget",
+	BudgetScopeOrgMonth: "org's monthly budget",
+}
+
+type BudgetUnit string
+
+const (
+	BudgetUnitTokens BudgetUnit = "tokens"
+	BudgetUnitUsd    BudgetUnit = "usd"
+)
+
+// BudgetStatus is how much of a single budget limit has been used, including the model call that's about to be sent
+type BudgetStatus struct {
+	Scope BudgetScope     `json:"scope"`
+	Unit  BudgetUnit      `json:"unit"`
+	Used  decimal.Decimal `json:"used"`
+	Limit decimal.Decimal `json:"limit"`
+}
+
+func (s *BudgetStatus) Pct() int {
+	if s.Limit.IsZero() {
+		return 100
+	}
+	return int(s.Used.Div(s.Limit).Mul(decimal.NewFromInt(100)).IntPart())
+}
+
+func (s *BudgetStatus) String() string {
+	var used, limit string
+	if s.Unit == BudgetUnitUsd {
+		used = "$" + s.Used.StringFixed(2)
+		limit = "$" + s.Limit.StringFixed(2)
+	} else {
+		used = FormatBudgetTokens(int(s.Used.IntPart()))
+		limit = FormatBudgetTokens(int(s.Limit.IntPart())) + " tokens"
+	}
+	return fmt.Sprintf("%s of %s %s (%d%%)", used, limit, budgetScopeLabels[s.Scope], s.Pct())
+}
+
+// FormatBudgetTokens abbreviates large token counts, like 1.5M or 250k
+func FormatBudgetTokens(n int) string {
+	var s string
+	switch {
+	case n >= 1000000:
+		s = fmt.Sprintf("%.1fM", float64(n)/1000000)
+	case n >= 1000:
+		s = fmt.Sprintf("%.1fk", float64(n)/1000)
+	default:
+		return fmt.Sprintf("%d", n)
+	}
+	return strings.Replace(s, ".0", "", 1)
+}
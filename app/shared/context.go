@@ -31,6 +31,7 @@ type ContextUpdateResult struct {
 	NumImages       int
 	NumTrees        int
 	NumMaps         int
+	NumDiffs        int
 	MaxTokens       int
 }
 
@@ -59,6 +60,18 @@ func (c *Context) TypeAndIcon() (string, string) {
 	case ContextMapType:
 		icon = "🗺️ "
 		t = "map"
+	case ContextSymbolType:
+		icon = "🧩"
+		t = "symbol"
+	case ContextGitDiffType:
+		icon = "🔀"
+		t = "diff"
+	case ContextGitRefDiffType:
+		icon = "🔀"
+		t = "ref diff"
+	case ContextGitCommitsType:
+		icon = "🔀"
+		t = "commits"
 	}
 
 	return t, icon
@@ -117,6 +130,8 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 	var numTrees int
 	var numUrls int
 	var numMaps int
+	var numSymbols int
+	var numDiffs int
 
 	for _, context := range contexts {
 		switch context.ContextType {
@@ -132,6 +147,10 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 			hasPiped = true
 		case ContextMapType:
 			numMaps++
+		case ContextSymbolType:
+			numSymbols++
+		case ContextGitDiffType, ContextGitRefDiffType, ContextGitCommitsType:
+			numDiffs++
 		}
 	}
 
@@ -171,6 +190,20 @@ func SummaryForLoadContext(contexts []*Context, tokensAdded, totalTokens int) st
 		}
 		added = append(added, fmt.Sprintf("%d %s", numMaps, label))
 	}
+	if numSymbols > 0 {
+		label := "symbol"
+		if numSymbols > 1 {
+			label = "symbols"
+		}
+		added = append(added, fmt.Sprintf("%d %s", numSymbols, label))
+	}
+	if numDiffs > 0 {
+		label := "git diff"
+		if numDiffs > 1 {
+			label = "git diffs"
+		}
+		added = append(added, fmt.Sprintf("%d %s", numDiffs, label))
+	}
 
 	msg := "Loaded "
 
@@ -239,6 +272,7 @@ type SummaryForUpdateContextParams struct {
 	NumTrees    int
 	NumUrls     int
 	NumMaps     int
+	NumDiffs    int
 	TokensDiff  int
 	TotalTokens int
 }
@@ -248,6 +282,7 @@ func SummaryForUpdateContext(params SummaryForUpdateContextParams) string {
 	numTrees := params.NumTrees
 	numUrls := params.NumUrls
 	numMaps := params.NumMaps
+	numDiffs := params.NumDiffs
 	tokensDiff := params.TokensDiff
 	totalTokens := params.TotalTokens
 
@@ -281,6 +316,13 @@ func SummaryForUpdateContext(params SummaryForUpdateContextParams) string {
 		}
 		toAdd = append(toAdd, fmt.Sprintf("%d map%s", numMaps, postfix))
 	}
+	if numDiffs > 0 {
+		postfix := "s"
+		if numDiffs == 1 {
+			postfix = ""
+		}
+		toAdd = append(toAdd, fmt.Sprintf("%d git diff%s", numDiffs, postfix))
+	}
 
 	if len(toAdd) <= 2 {
 		msg += " " + strings.Join(toAdd, " and ")
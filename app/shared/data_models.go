@@ -90,8 +90,16 @@ const (
 	ContextPipedDataType     ContextType = "piped data"
 	ContextImageType         ContextType = "image"
 	ContextMapType           ContextType = "map"
+	ContextSymbolType        ContextType = "symbol"
+	ContextGitDiffType       ContextType = "git diff"
+	ContextGitRefDiffType    ContextType = "git ref diff"
+	ContextGitCommitsType    ContextType = "git commits"
 )
 
+func (t ContextType) IsGit() bool {
+	return t == ContextGitDiffType || t == ContextGitRefDiffType || t == ContextGitCommitsType
+}
+
 type FileMapBodies map[string]string
 
 type Context struct {
@@ -101,6 +109,9 @@ type Context struct {
 	Name            string                `json:"name"`
 	Url             string                `json:"url"`
 	FilePath        string                `json:"file_path"`
+	Symbol          string                `json:"symbol,omitempty"`
+	GitRef          string                `json:"gitRef,omitempty"`
+	GitStaged       bool                  `json:"gitStaged,omitempty"`
 	Sha             string                `json:"sha"`
 	NumTokens       int                   `json:"numTokens"`
 	Body            string                `json:"body,omitempty"`
@@ -296,6 +307,9 @@ type CurrentPlanState struct {
 	ConvoMessageDescriptions []*ConvoMessageDescription `json:"convoMessageDescriptions"`
 	PlanApplies              []*PlanApply               `json:"planApplies"`
 	ContextsByPath           map[string]*Context        `json:"contextsByPath"`
+
+	// lets the client skip checking commands when neither the org nor the project has an exec policy
+	HasOrgExecPolicy bool `json:"hasOrgExecPolicy,omitempty"`
 }
 
 type OrgRole struct {
@@ -394,3 +408,39 @@ func (t *CreditsTransaction) ModelString() string {
 
 	return s
 }
+
+// ModelUsage is a single model call recorded in a self-hosted server's usage ledger. Cost is nil if the model's pricing isn't known.
+type ModelUsage struct {
+	Id            string           `json:"id"`
+	OrgId         string           `json:"orgId"`
+	UserId        *string          `json:"userId,omitempty"`
+	UserName      *string          `json:"userName,omitempty"`
+	UserEmail     *string          `json:"userEmail,omitempty"`
+	PlanId        *string          `json:"planId,omitempty"`
+	PlanName      *string          `json:"planName,omitempty"`
+	Branch        string           `json:"branch"`
+	ModelRole     ModelRole        `json:"modelRole"`
+	ModelId       ModelId          `json:"modelId"`
+	ModelName     ModelName        `json:"modelName"`
+	ModelProvider ModelProvider    `json:"modelProvider"`
+	ModelPackName string           `json:"modelPackName"`
+	Purpose       string           `json:"purpose"`
+	InputTokens   int              `json:"inputTokens"`
+	OutputTokens  int              `json:"outputTokens"`
+	CachedTokens  int              `json:"cachedTokens"`
+	LatencyMs     int              `json:"latencyMs"`
+	Cost          *decimal.Decimal `json:"cost,omitempty"`
+	SessionId     string           `json:"sessionId"`
+	StoppedEarly  bool             `json:"stoppedEarly"`
+	HadError      bool             `json:"hadError"`
+	CreatedAt     time.Time        `json:"createdAt"`
+}
+
+func (u *ModelUsage) ModelString() string {
+	s := ""
+	if u.ModelProvider != ModelProviderOpenAI {
+		s += string(u.ModelProvider) + "/"
+	}
+	s += string(u.ModelName)
+	return s
+}
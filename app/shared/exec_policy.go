@@ -0,0 +1,429 @@
+package shared
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type ExecPolicyOnViolation string
+
+const (
+	// ask the user before running the commands, even with auto-exec
+	ExecPolicyOnViolationPrompt ExecPolicyOnViolation = "prompt"
+	// don't run the commands and send the violations back to the model to fix
+	ExecPolicyOnViolationDebug ExecPolicyOnViolation = "debug"
+	// don't run the commands
+	ExecPolicyOnViolationBlock ExecPolicyOnViolation = "block"
+)
+
+// ordered from least to most strict
+var execPolicyOnViolationStrictness = map[ExecPolicyOnViolation]int{
+	ExecPolicyOnViolationPrompt: 0,
+	ExecPolicyOnViolationDebug:  1,
+	ExecPolicyOnViolationBlock:  2,
+}
+
+type ExecPolicySource string
+
+const (
+	ExecPolicySourceOrg     ExecPolicySource = "org"
+	ExecPolicySourceProject ExecPolicySource = "project"
+)
+
+// ExecPolicy limits the commands in _apply.sh that can run without review. Patterns match a command with its arguments, like 'npm test' or 'rm -rf *'. '*' matches anything, and a pattern without '*' also matches the command with more arguments after it, so 'npm test' matches 'npm test --watch'.
+//
+// Deny patterns always win. A command matching an allow pattern skips the category checks. If there are any allow patterns, commands that don't match one are violations.
+type ExecPolicy struct {
+	OnViolation         ExecPolicyOnViolation `json:"onViolation,omitempty" yaml:"onViolation,omitempty"`
+	Allow               []string              `json:"allow,omitempty" yaml:"allow,omitempty"`
+	Deny                []string              `json:"deny,omitempty" yaml:"deny,omitempty"`
+	DenyNetwork         bool                  `json:"denyNetwork,omitempty" yaml:"denyNetwork,omitempty"`
+	DenyPackageManagers bool                  `json:"denyPackageManagers,omitempty" yaml:"denyPackageManagers,omitempty"`
+	DenyDestructive     bool                  `json:"denyDestructive,omitempty" yaml:"denyDestructive,omitempty"`
+}
+
+func (p *ExecPolicy) Scan(src interface{}) error {
+	if src == nil {
+		*p = ExecPolicy{}
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, p)
+	case string:
+		return json.Unmarshal([]byte(s), p)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (p ExecPolicy) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *ExecPolicy) Validate() error {
+	if p.OnViolation != "" {
+		if _, ok := execPolicyOnViolationStrictness[p.OnViolation]; !ok {
+			return fmt.Errorf("onViolation must be one of: %s, %s, %s", ExecPolicyOnViolationPrompt, ExecPolicyOnViolationDebug, ExecPolicyOnViolationBlock)
+		}
+	}
+	for _, pattern := range append(append([]string{}, p.Allow...), p.Deny...) {
+		if strings.TrimSpace(pattern) == "" {
+			return fmt.Errorf("patterns can't be empty")
+		}
+	}
+	return nil
+}
+
+func (p *ExecPolicy) GetOnViolation() ExecPolicyOnViolation {
+	if p.OnViolation == "" {
+		return ExecPolicyOnViolationPrompt
+	}
+	return p.OnViolation
+}
+
+func (p *ExecPolicy) IsEmpty() bool {
+	return len(p.Allow) == 0 && len(p.Deny) == 0 && !p.DenyNetwork && !p.DenyPackageManagers && !p.DenyDestructive
+}
+
+func (p *ExecPolicy) hasDenyRules() bool {
+	return len(p.Deny) > 0 || p.DenyNetwork || p.DenyPackageManagers || p.DenyDestructive
+}
+
+// StricterExecPolicyOnViolation returns whichever of two actions is more strict
+func StricterExecPolicyOnViolation(a, b ExecPolicyOnViolation) ExecPolicyOnViolation {
+	if execPolicyOnViolationStrictness[b] > execPolicyOnViolationStrictness[a] {
+		return b
+	}
+	return a
+}
+
+// ExecCommand is a single command parsed from a script
+type ExecCommand struct {
+	Name string   `json:"name"`
+	Args []string `json:"args"`
+	Line int      `json:"line"`
+}
+
+func (c *ExecCommand) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+type ExecPolicyViolation struct {
+	Source  ExecPolicySource `json:"source"`
+	Command string           `json:"command"`
+	Line    int              `json:"line"`
+	Reason  string           `json:"reason"`
+}
+
+// Check returns a violation for each command that the policy doesn't allow
+func (p *ExecPolicy) Check(source ExecPolicySource, commands []*ExecCommand) []*ExecPolicyViolation {
+	var violations []*ExecPolicyViolation
+
+	for _, command := range commands {
+		reason := p.checkCommand(command)
+		if reason != "" {
+			violations = append(violations, &ExecPolicyViolation{
+				Source:  source,
+				Command: command.String(),
+				Line:    command.Line,
+				Reason:  reason,
+			})
+		}
+	}
+
+	return violations
+}
+
+func (p *ExecPolicy) checkCommand(command *ExecCommand) string {
+	// 'sudo rm -rf x' is checked as both 'sudo rm -rf x' and 'rm -rf x'
+	forms := unwrapExecCommand(command)
+
+	for _, form := range forms {
+		for _, pattern := range p.Deny {
+			if ExecPatternMatches(pattern, form.String()) {
+				return fmt.Sprintf("matches denied pattern '%s'", pattern)
+			}
+		}
+	}
+
+	// with 'x=rm; $x -rf /', what runs isn't known until runtime, so it can't be checked against the deny rules
+	if p.hasDenyRules() {
+		for _, form := range forms {
+			if isUnresolvedExecName(form.Name) {
+				return "runs a command whose name is only known at runtime"
+			}
+		}
+	}
+
+	for _, pattern := range p.Allow {
+		if ExecPatternMatches(pattern, command.String()) {
+			return ""
+		}
+	}
+
+	for _, form := range forms {
+		if p.DenyNetwork && isNetworkCommand(form) {
+			return "uses the network"
+		}
+		if p.DenyPackageManagers && isPackageManagerCommand(form) {
+			return "runs a package manager"
+		}
+		if p.DenyDestructive && isDestructiveCommand(form) {
+			return "is a destructive operation"
+		}
+	}
+
+	if len(p.Allow) > 0 {
+		return "doesn't match any allowed pattern"
+	}
+
+	return ""
+}
+
+// ExecPatternMatches checks a policy pattern against a command and its arguments
+func ExecPatternMatches(pattern, commandLine string) bool {
+	pattern = strings.Join(strings.Fields(pattern), " ")
+	commandLine = strings.Join(strings.Fields(commandLine), " ")
+
+	if !strings.ContainsAny(pattern, "*?") {
+		return commandLine == pattern || strings.HasPrefix(commandLine, pattern+" ")
+	}
+
+	var expr strings.Builder
+	expr.WriteString("^")
+	for _, r := range pattern {
+		switch r {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	expr.WriteString("$")
+
+	return regexp.MustCompile(expr.String()).MatchString(commandLine)
+}
+
+// commands that run another command given as their arguments
+var execWrapperCommands = map[string]bool{
+	"sudo":    true,
+	"doas":    true,
+	"env":     true,
+	"nohup":   true,
+	"time":    true,
+	"nice":    true,
+	"exec":    true,
+	"command": true,
+	"xargs":   true,
+	"timeout": true,
+}
+
+func unwrapExecCommand(command *ExecCommand) []*ExecCommand {
+	forms := []*ExecCommand{command}
+
+	current := command
+	for execWrapperCommands[current.Name] {
+		i := 0
+		for i < len(current.Args) {
+			arg := current.Args[i]
+			if strings.HasPrefix(arg, "-") || strings.Contains(arg, "=") {
+				i++
+				continue
+			}
+			// timeout's duration comes before the command
+			if current.Name == "timeout" && i == 0 {
+				i++
+				continue
+			}
+			break
+		}
+		if i >= len(current.Args) {
+			break
+		}
+		current = &ExecCommand{Name: current.Args[i], Args: current.Args[i+1:], Line: command.Line}
+		forms = append(forms, current)
+	}
+
+	return forms
+}
+
+// a name built from a variable or command substitution, like '$x' or '$(which rm)'
+func isUnresolvedExecName(name string) bool {
+	return strings.ContainsAny(name, "$`")
+}
+
+func execCommandBase(name string) string {
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// the first argument that isn't an option, like 'install' in 'npm --silent install'
+func execSubcommand(command *ExecCommand) string {
+	for _, arg := range command.Args {
+		if !strings.HasPrefix(arg, "-") {
+			return arg
+		}
+	}
+	return ""
+}
+
+func hasExecFlag(command *ExecCommand, long string, shortChars string) bool {
+	for _, arg := range command.Args {
+		if arg == "--" {
+			return false
+		}
+		if long != "" && (arg == long || strings.HasPrefix(arg, long+"=")) {
+			return true
+		}
+		if shortChars != "" && strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && strings.ContainsAny(arg[1:], shortChars) {
+			return true
+		}
+	}
+	return false
+}
+
+var networkCommands = map[string]bool{
+	"curl":     true,
+	"wget":     true,
+	"aria2c":   true,
+	"http":     true,
+	"https":    true,
+	"ssh":      true,
+	"scp":      true,
+	"sftp":     true,
+	"rsync":    true,
+	"ftp":      true,
+	"telnet":   true,
+	"nc":       true,
+	"ncat":     true,
+	"netcat":   true,
+	"socat":    true,
+	"ping":     true,
+	"dig":      true,
+	"nslookup": true,
+}
+
+var networkGitSubcommands = map[string]bool{
+	"clone":     true,
+	"fetch":     true,
+	"pull":      true,
+	"push":      true,
+	"ls-remote": true,
+	"submodule": true,
+}
+
+func isNetworkCommand(command *ExecCommand) bool {
+	name := execCommandBase(command.Name)
+	if networkCommands[name] {
+		return true
+	}
+	if name == "git" {
+		return networkGitSubcommands[execSubcommand(command)]
+	}
+	return false
+}
+
+// subcommands that install or change packages for each package manager -- nil means any use, and "" means running it without a subcommand
+var packageManagerSubcommands = map[string][]string{
+	"npm":      {"install", "i", "ci", "add", "uninstall", "remove", "rm", "update", "upgrade", "exec"},
+	"pnpm":     {"install", "i", "add", "remove", "rm", "update", "up", "dlx"},
+	"yarn":     {"", "install", "add", "remove", "upgrade", "dlx"},
+	"bun":      {"install", "i", "add", "remove", "rm", "update", "x"},
+	"npx":      nil,
+	"pnpx":     nil,
+	"bunx":     nil,
+	"pip":      {"install", "uninstall", "download"},
+	"pip3":     {"install", "uninstall", "download"},
+	"pipx":     {"install", "uninstall", "run", "upgrade"},
+	"poetry":   {"add", "install", "remove", "update"},
+	"uv":       {"add", "remove", "sync", "pip", "tool"},
+	"conda":    {"install", "create", "update", "remove"},
+	"gem":      {"install", "uninstall", "update"},
+	"bundle":   {"", "install", "add", "update"},
+	"cargo":    {"install", "add", "remove", "update"},
+	"go":       {"get", "install"},
+	"composer": {"install", "require", "remove", "update"},
+	"apt":      nil,
+	"apt-get":  nil,
+	"yum":      nil,
+	"dnf":      nil,
+	"pacman":   nil,
+	"apk":      nil,
+	"snap":     nil,
+	"brew":     {"install", "uninstall", "reinstall", "upgrade", "tap"},
+}
+
+func isPackageManagerCommand(command *ExecCommand) bool {
+	subcommands, ok := packageManagerSubcommands[execCommandBase(command.Name)]
+	if !ok {
+		return false
+	}
+	if subcommands == nil {
+		return true
+	}
+	sub := execSubcommand(command)
+	for _, s := range subcommands {
+		if s == sub {
+			return true
+		}
+	}
+	return false
+}
+
+var destructiveCommands = map[string]bool{
+	"sudo":     true,
+	"doas":     true,
+	"su":       true,
+	"dd":       true,
+	"shred":    true,
+	"wipefs":   true,
+	"fdisk":    true,
+	"parted":   true,
+	"truncate": true,
+	"shutdown": true,
+	"reboot":   true,
+	"halt":     true,
+	"poweroff": true,
+}
+
+func isDestructiveCommand(command *ExecCommand) bool {
+	name := execCommandBase(command.Name)
+
+	if destructiveCommands[name] || strings.HasPrefix(name, "mkfs") {
+		return true
+	}
+
+	switch name {
+	case "rm":
+		return hasExecFlag(command, "--recursive", "rR")
+	case "chmod", "chown", "chgrp":
+		return hasExecFlag(command, "--recursive", "R")
+	case "find":
+		for _, arg := range command.Args {
+			if arg == "-delete" {
+				return true
+			}
+		}
+	case "git":
+		switch execSubcommand(command) {
+		case "reset":
+			return hasExecFlag(command, "--hard", "")
+		case "clean":
+			return hasExecFlag(command, "--force", "f")
+		case "push":
+			return hasExecFlag(command, "--force", "f") || hasExecFlag(command, "--force-with-lease", "") || hasExecFlag(command, "--delete", "d")
+		case "branch":
+			return hasExecFlag(command, "", "D")
+		}
+	}
+
+	return false
+}
@@ -2,6 +2,7 @@ package shared
 
 import (
 	"fmt"
+	"regexp"
 	"sort"
 	"strings"
 )
@@ -31,3 +32,24 @@ func (m FileMapBodies) CombinedMap(tokensByPath map[string]int) string {
 func MapFileHeading(path string, tokens int) string {
 	return fmt.Sprintf("\n### %s (%d 🪙)\n\n", path, tokens)
 }
+
+var mapFileHeadingRegex = regexp.MustCompile(`(?m)^### (.+) \(\d+ 🪙\)$`)
+
+// ParseCombinedMap splits a combined map (as produced by CombinedMap) back into bodies by path
+func ParseCombinedMap(combined string) FileMapBodies {
+	res := FileMapBodies{}
+	locs := mapFileHeadingRegex.FindAllStringSubmatchIndex(combined, -1)
+	for i, loc := range locs {
+		path := combined[loc[2]:loc[3]]
+		end := len(combined)
+		if i+1 < len(locs) {
+			end = locs[i+1][0]
+		}
+		body := strings.TrimSpace(combined[loc[1]:end])
+		if body == "[NO MAP]" {
+			body = ""
+		}
+		res[path] = body
+	}
+	return res
+}
@@ -16,6 +16,15 @@ const (
 
 const defaultEditor = EditorTypeVim
 
+type ExecBackendType string
+
+const (
+	ExecBackendHost    ExecBackendType = "host"
+	ExecBackendSandbox ExecBackendType = "sandbox"
+)
+
+var ExecBackendChoices = []string{string(ExecBackendHost), string(ExecBackendSandbox)}
+
 type AutoModeType string
 
 const (
@@ -81,6 +90,15 @@ type PlanConfig struct {
 
 	AutoRevertOnRewind bool `json:"autoRevertOnRewind"`
 
+	// empty is the same as ExecBackendHost
+	ExecBackend ExecBackendType `json:"execBackend"`
+
+	// only apply to the sandbox backend—limits of 0 mean no limit
+	SandboxNetwork    bool `json:"sandboxNetwork"`
+	SandboxTimeout    int  `json:"sandboxTimeout"`
+	SandboxCpuSeconds int  `json:"sandboxCpuSeconds"`
+	SandboxMemoryMb   int  `json:"sandboxMemoryMb"`
+
 	// ReplMode    bool     `json:"replMode"`
 	// DefaultRepl ReplType `json:"defaultRepl"`
 
@@ -120,6 +138,13 @@ func (p PlanConfig) Value() (driver.Value, error) {
 	return json.Marshal(p)
 }
 
+func (p *PlanConfig) GetExecBackend() ExecBackendType {
+	if p.ExecBackend == "" {
+		return ExecBackendHost
+	}
+	return p.ExecBackend
+}
+
 func (p *PlanConfig) SetAutoMode(mode AutoModeType) {
 	p.AutoMode = mode
 
@@ -465,6 +490,69 @@ var ConfigSettingsByKey = map[string]ConfigSetting{
 			return fmt.Sprintf("%t", p.AutoRevertOnRewind)
 		},
 	},
+	"execbackend": {
+		Name: "exec-backend",
+		Desc: "Where commands run: 'host' runs them directly, 'sandbox' isolates them with Linux namespaces (read-only system, writable project, no network)",
+		StringSetter: func(p *PlanConfig, value string) {
+			p.ExecBackend = ExecBackendType(value)
+		},
+		Getter: func(p *PlanConfig) string {
+			return string(p.GetExecBackend())
+		},
+		Choices: &ExecBackendChoices,
+	},
+	"sandboxnetwork": {
+		Name: "sandbox-network",
+		Desc: "Allow network access for sandboxed commands",
+		Visible: func(p *PlanConfig) bool {
+			return p.GetExecBackend() == ExecBackendSandbox
+		},
+		BoolSetter: func(p *PlanConfig, enabled bool) {
+			p.SandboxNetwork = enabled
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%t", p.SandboxNetwork)
+		},
+	},
+	"sandboxtimeout": {
+		Name: "sandbox-timeout",
+		Desc: "Seconds before sandboxed commands are killed (0 for no limit)",
+		Visible: func(p *PlanConfig) bool {
+			return p.GetExecBackend() == ExecBackendSandbox
+		},
+		IntSetter: func(p *PlanConfig, value int) {
+			p.SandboxTimeout = max(value, 0)
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%d", p.SandboxTimeout)
+		},
+	},
+	"sandboxcpuseconds": {
+		Name: "sandbox-cpu-seconds",
+		Desc: "CPU time limit in seconds for each sandboxed process (0 for no limit)",
+		Visible: func(p *PlanConfig) bool {
+			return p.GetExecBackend() == ExecBackendSandbox
+		},
+		IntSetter: func(p *PlanConfig, value int) {
+			p.SandboxCpuSeconds = max(value, 0)
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%d", p.SandboxCpuSeconds)
+		},
+	},
+	"sandboxmemorymb": {
+		Name: "sandbox-memory-mb",
+		Desc: "Memory limit in MB for each sandboxed process (0 for no limit)",
+		Visible: func(p *PlanConfig) bool {
+			return p.GetExecBackend() == ExecBackendSandbox
+		},
+		IntSetter: func(p *PlanConfig, value int) {
+			p.SandboxMemoryMb = max(value, 0)
+		},
+		Getter: func(p *PlanConfig) string {
+			return fmt.Sprintf("%d", p.SandboxMemoryMb)
+		},
+	},
 }
 
 func init() {
@@ -145,6 +145,24 @@ func (ps PlanSettings) GetRequiredEnvVars() map[string]bool {
 	envVars[ms.Architect.BaseModelConfig.ApiKeyEnvVar] = true
 	envVars[ms.Coder.BaseModelConfig.ApiKeyEnvVar] = true
 
+	// error fallbacks may use a different provider than the model they fall back from
+	roleConfigs := []ModelRoleConfig{
+		ms.Planner.ModelRoleConfig,
+		ms.Builder,
+		ms.GetWholeFileBuilder(),
+		ms.PlanSummary,
+		ms.Namer,
+		ms.CommitMsg,
+		ms.ExecStatus,
+		ms.GetArchitect(),
+		ms.GetCoder(),
+	}
+	for _, config := range roleConfigs {
+		for _, fallback := range config.GetErrorFallbackChain() {
+			envVars[fallback.BaseModelConfig.ApiKeyEnvVar] = true
+		}
+	}
+
 	// for backward compatibility with <= 0.8.4 server versions
 	if len(envVars) == 0 {
 		envVars["OPENAI_API_KEY"] = true
@@ -29,6 +29,11 @@ func applyReplacements(content string, replacements []*Replacement, setFailed, v
 		lastInsertedIdx := 0
 
 		for i, replacement := range replacements {
+			// rejected hunks are left out, the rest of the diff still applies since hunks don't overlap
+			if replacement.RejectedAt != nil {
+				continue
+			}
+
 			if verbose {
 				log.Println("replacement.Old:\n", replacement.Old)
 				log.Println("updated:\n", updated)
@@ -182,7 +187,7 @@ func (planState *CurrentPlanState) GetFilesBeforeReplacement(
 
 			if len(planRes.Replacements) == 0 {
 				if updated != "" {
-					log.Println("plan updates out of order: %s", path)
+					log.Printf("plan updates out of order: %s\n", path)
 					log.Println("updated:")
 					log.Println(updated)
 					log.Println("planRes.Content:")
@@ -0,0 +1,164 @@
+package shared
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const MaxPlanTemplateNameLength = 100
+
+// StringList is stored as a json column
+type StringList []string
+
+func (l *StringList) Scan(src interface{}) error {
+	if src == nil {
+		*l = nil
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, l)
+	case string:
+		return json.Unmarshal([]byte(s), l)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		l = StringList{}
+	}
+	return json.Marshal(l)
+}
+
+// PlanConfigOverrides are the plan config settings a template sets, keyed by their json names (like 'autoMode' or 'autoApply'). Settings that aren't included keep the user's defaults.
+type PlanConfigOverrides map[string]interface{}
+
+func (o *PlanConfigOverrides) Scan(src interface{}) error {
+	if src == nil {
+		*o = nil
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, o)
+	case string:
+		return json.Unmarshal([]byte(s), o)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (o PlanConfigOverrides) Value() (driver.Value, error) {
+	if o == nil {
+		o = PlanConfigOverrides{}
+	}
+	return json.Marshal(o)
+}
+
+// Apply returns a copy of the base config with the overrides set
+func (o PlanConfigOverrides) Apply(base *PlanConfig) (*PlanConfig, error) {
+	res := DefaultPlanConfig
+	if base != nil {
+		res = *base
+	}
+
+	if len(o) == 0 {
+		return &res, nil
+	}
+
+	// an auto mode sets a group of settings, and any other overrides are applied on top of it
+	if mode, ok := o["autoMode"].(string); ok {
+		res.SetAutoMode(AutoModeType(mode))
+	}
+
+	data, err := json.Marshal(o)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling config overrides: %v", err)
+	}
+
+	err = json.Unmarshal(data, &res)
+	if err != nil {
+		return nil, fmt.Errorf("error applying config overrides: %v", err)
+	}
+
+	return &res, nil
+}
+
+func (o PlanConfigOverrides) Validate() error {
+	if len(o) == 0 {
+		return nil
+	}
+
+	data, err := json.Marshal(o)
+	if err != nil {
+		return fmt.Errorf("error marshalling config: %v", err)
+	}
+
+	var config PlanConfig
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	err = decoder.Decode(&config)
+	if err != nil {
+		return fmt.Errorf("invalid config: %v", err)
+	}
+
+	if _, ok := AutoModeDescriptions[config.AutoMode]; config.AutoMode != "" && !ok {
+		return fmt.Errorf("invalid autoMode: %s", config.AutoMode)
+	}
+
+	if config.ExecBackend != "" && config.ExecBackend != ExecBackendHost && config.ExecBackend != ExecBackendSandbox {
+		return fmt.Errorf("invalid execBackend: %s", config.ExecBackend)
+	}
+
+	return nil
+}
+
+// PlanTemplate is a reusable starting point for new plans that's shared across an org. Context paths are relative to the project root and can be globs like 'src/**/*.go'.
+type PlanTemplate struct {
+	Id           string              `json:"id" yaml:"-"`
+	Name         string              `json:"name" yaml:"name"`
+	Description  string              `json:"description" yaml:"description,omitempty"`
+	Notes        StringList          `json:"notes" yaml:"notes,omitempty"`
+	ContextPaths StringList          `json:"contextPaths" yaml:"context,omitempty"`
+	Config       PlanConfigOverrides `json:"config" yaml:"config,omitempty"`
+	ModelPack    string              `json:"modelPack" yaml:"modelPack,omitempty"`
+	Prompt       string              `json:"prompt" yaml:"prompt,omitempty"`
+	CreatedAt    time.Time           `json:"createdAt" yaml:"-"`
+	UpdatedAt    time.Time           `json:"updatedAt" yaml:"-"`
+}
+
+// Validate checks everything that doesn't depend on the org -- the model pack is checked by the server
+func (t *PlanTemplate) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if len(t.Name) > MaxPlanTemplateNameLength {
+		return fmt.Errorf("name can't be longer than %d characters", MaxPlanTemplateNameLength)
+	}
+	if strings.ContainsAny(t.Name, " \t\n/") {
+		return fmt.Errorf("name can't contain spaces or slashes")
+	}
+
+	for _, note := range t.Notes {
+		if strings.TrimSpace(note) == "" {
+			return fmt.Errorf("notes can't be empty")
+		}
+	}
+
+	for _, path := range t.ContextPaths {
+		if strings.TrimSpace(path) == "" {
+			return fmt.Errorf("context paths can't be empty")
+		}
+		if strings.HasPrefix(path, "/") {
+			return fmt.Errorf("context path %s must be relative to the project root", path)
+		}
+	}
+
+	return t.Config.Validate()
+}
@@ -23,8 +23,46 @@ const (
 	PermissionDeleteAnyPlan         Permission = "delete_any_plan"
 	PermissionUpdateAnyPlan         Permission = "update_any_plan"
 	PermissionArchiveAnyPlan        Permission = "archive_any_plan"
+	PermissionManageWebhooks        Permission = "manage_webhooks"
+	PermissionManageExecPolicy      Permission = "manage_exec_policy"
+	PermissionManagePlanTemplates   Permission = "manage_plan_templates"
+	PermissionViewOrgUsage          Permission = "view_org_usage"
+	PermissionManageBudgets         Permission = "manage_budgets"
 )
 
+var AllPermissions = []Permission{
+	PermissionDeleteOrg,
+	PermissionManageEmailDomainAuth,
+	PermissionManageBilling,
+	PermissionInviteUser,
+	PermissionRemoveUser,
+	PermissionSetUserRole,
+	PermissionListOrgRoles,
+	PermissionCreateProject,
+	PermissionRenameAnyProject,
+	PermissionDeleteAnyProject,
+	PermissionCreatePlan,
+	PermissionManageAnyPlanShares,
+	PermissionRenameAnyPlan,
+	PermissionDeleteAnyPlan,
+	PermissionUpdateAnyPlan,
+	PermissionArchiveAnyPlan,
+	PermissionManageWebhooks,
+	PermissionManageExecPolicy,
+	PermissionManagePlanTemplates,
+	PermissionViewOrgUsage,
+	PermissionManageBudgets,
+}
+
+func IsValidPermission(permission Permission) bool {
+	for _, p := range AllPermissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 type Permissions map[string]bool
 
 func (perms Permissions) HasPermission(permission Permission) bool {
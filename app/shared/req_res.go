@@ -192,6 +192,13 @@ type LoadContextParams struct {
 	ImageDetail     openai.ImageURLDetail `json:"imageDetail"`
 	AutoLoaded      bool                  `json:"autoLoaded"`
 
+	// For symbol contexts, Body is the whole file and the server extracts the symbol from it
+	Symbol string `json:"symbol,omitempty"`
+
+	// For git contexts, the ref or range the diff is computed from
+	GitRef    string `json:"gitRef,omitempty"`
+	GitStaged bool   `json:"gitStaged,omitempty"`
+
 	InputShas   map[string]string `json:"inputShas"`
 	InputTokens map[string]int    `json:"inputTokens"`
 	InputSizes  map[string]int64  `json:"inputSizes"`
@@ -240,6 +247,21 @@ type LoadCachedFileMapResponse struct {
 	CachedByPath map[string]bool      `json:"cachedByPath"`
 }
 
+type SearchContextRequest struct {
+	Query string `json:"query"`
+	Limit int    `json:"limit"`
+}
+
+type SemanticSearchResult struct {
+	Path        string   `json:"path"`
+	Score       float64  `json:"score"`
+	Definitions []string `json:"definitions"`
+}
+
+type SearchContextResponse struct {
+	Results []*SemanticSearchResult `json:"results"`
+}
+
 type GetContextBodyRequest struct {
 	ContextId string `json:"contextId"`
 }
@@ -270,6 +292,23 @@ type RejectFilesRequest struct {
 	Paths []string `json:"paths"`
 }
 
+type ReplacementRef struct {
+	ResultId string `json:"resultId"`
+	// empty to reject the whole result, as for new or removed files
+	ReplacementId string `json:"replacementId,omitempty"`
+}
+
+type ReplacementEdit struct {
+	ResultId      string `json:"resultId"`
+	ReplacementId string `json:"replacementId"`
+	New           string `json:"new"`
+}
+
+type RejectReplacementsRequest struct {
+	Rejected []ReplacementRef  `json:"rejected"`
+	Edited   []ReplacementEdit `json:"edited"`
+}
+
 type RewindPlanRequest struct {
 	Sha string `json:"sha"`
 }
@@ -288,6 +327,37 @@ type CreateBranchRequest struct {
 	Name string `json:"name"`
 }
 
+// MergeResolution picks which side's pending changes are kept for paths where both branches have conflicting pending changes
+type MergeResolution string
+
+const (
+	MergeResolutionNone   MergeResolution = ""
+	MergeResolutionOurs   MergeResolution = "ours"
+	MergeResolutionTheirs MergeResolution = "theirs"
+)
+
+type MergeBranchRequest struct {
+	Branch     string          `json:"branch"`
+	Resolution MergeResolution `json:"resolution"`
+}
+
+type CherryPickRequest struct {
+	Sha        string          `json:"sha"`
+	Resolution MergeResolution `json:"resolution"`
+}
+
+// MergeBranchResponse is returned for both merges and cherry-picks. If there are conflicted paths and no resolution was given, nothing is merged.
+type MergeBranchResponse struct {
+	Merged          bool     `json:"merged"`
+	UpToDate        bool     `json:"upToDate"`
+	ConflictedPaths []string `json:"conflictedPaths"`
+	NumMessages     int      `json:"numMessages"`
+	NumContexts     int      `json:"numContexts"`
+	NumResults      int      `json:"numResults"`
+	LatestSha       string   `json:"latestSha"`
+	LatestCommit    string   `json:"latestCommit"`
+}
+
 type UpdateSettingsRequest struct {
 	Settings *PlanSettings `json:"settings"`
 }
@@ -333,6 +403,82 @@ type GetBuildStatusResponse struct {
 	IsBuildingByPath map[string]bool `json:"isBuildingByPath"`
 }
 
+// Usage ledger requests and responses (self-hosted)
+type UsageRequest struct {
+	PlanId    string     `json:"planId"`
+	SessionId string     `json:"sessionId"`
+	Since     *time.Time `json:"since"`
+
+	// the caller's offset from UTC in seconds, used to group spend by local day
+	UtcOffset int `json:"utcOffset"`
+}
+
+type UsageSummary struct {
+	NumCalls     int             `json:"numCalls"`
+	InputTokens  int             `json:"inputTokens"`
+	OutputTokens int             `json:"outputTokens"`
+	CachedTokens int             `json:"cachedTokens"`
+	Spend        decimal.Decimal `json:"spend"`
+
+	// calls to models without known pricing, which aren't included in Spend
+	NumUnpriced int `json:"numUnpriced"`
+}
+
+type UsageReportResponse struct {
+	// false if the user can only see their own usage
+	OrgWide bool `json:"orgWide"`
+
+	Total *UsageSummary `json:"total"`
+
+	ByPlanId      map[string]*UsageSummary `json:"byPlanId"`
+	PlanNamesById map[string]string        `json:"planNamesById"`
+
+	ByUserId      map[string]*UsageSummary `json:"byUserId"`
+	UserNamesById map[string]string        `json:"userNamesById"`
+
+	ByModel map[string]*UsageSummary `json:"byModel"`
+	ByDay   map[string]*UsageSummary `json:"byDay"`
+}
+
+type UsageLogResponse struct {
+	Usage    []*ModelUsage `json:"usage"`
+	NumPages int           `json:"numPages"`
+}
+
+type SyncModelsRequest struct {
+	ModelsFile
+	DryRun bool `json:"dryRun"`
+}
+
+type ModelsSyncAction string
+
+const (
+	ModelsSyncActionCreate ModelsSyncAction = "create"
+	ModelsSyncActionUpdate ModelsSyncAction = "update"
+	ModelsSyncActionDelete ModelsSyncAction = "delete"
+)
+
+type ModelsSyncKind string
+
+const (
+	ModelsSyncKindModel     ModelsSyncKind = "model"
+	ModelsSyncKindModelPack ModelsSyncKind = "modelPack"
+)
+
+type ModelsSyncChange struct {
+	Action ModelsSyncAction `json:"action"`
+	Kind   ModelsSyncKind   `json:"kind"`
+	Name   string           `json:"name"`
+
+	// top-level fields that changed, for updates
+	Fields []string `json:"fields,omitempty"`
+}
+
+type SyncModelsResponse struct {
+	Changes []*ModelsSyncChange `json:"changes"`
+	Applied bool                `json:"applied"`
+}
+
 // Cloud requests and responses
 type CreditsLogRequest struct {
 	TransactionType CreditsTransactionType `json:"transactionType"`
@@ -365,3 +511,110 @@ type CreditsSummaryResponse struct {
 
 	CacheSavings decimal.Decimal `json:"cacheSavings"`
 }
+
+type CreateWebhookRequest struct {
+	Url         string           `json:"url"`
+	Description string           `json:"description"`
+	Events      WebhookEventList `json:"events"`
+}
+
+type CreateAccessTokenRequest struct {
+	Name string `json:"name"`
+
+	// empty to inherit all of the user's permissions in the org
+	Permissions PermissionList `json:"permissions"`
+
+	// 0 for a token that doesn't expire
+	ExpiresInDays int `json:"expiresInDays"`
+}
+
+type CreateAccessTokenResponse struct {
+	AccessToken *AccessToken `json:"accessToken"`
+
+	// the token itself is only returned when it's created
+	Token string `json:"token"`
+}
+
+type CreateWebhookResponse struct {
+	Webhook *Webhook `json:"webhook"`
+
+	// the signing secret is only returned when the webhook is created
+	Secret string `json:"secret"`
+}
+
+type UpdateSsoConfigRequest struct {
+	IssuerUrl string `json:"issuerUrl"`
+	ClientId  string `json:"clientId"`
+
+	// empty to keep the current secret -- public clients that only use PKCE don't need one
+	ClientSecret string `json:"clientSecret"`
+
+	// empty for SsoDefaultScopes
+	Scopes string `json:"scopes"`
+
+	// the ID token claim that's matched against RoleMapping, like "groups" or "roles"
+	RoleClaim   string         `json:"roleClaim"`
+	RoleMapping SsoRoleMapping `json:"roleMapping"`
+}
+
+type StartSsoRequest struct {
+	Email string `json:"email"`
+
+	// the CLI's loopback callback url
+	RedirectUri   string `json:"redirectUri"`
+	State         string `json:"state"`
+	CodeChallenge string `json:"codeChallenge"`
+	Nonce         string `json:"nonce"`
+}
+
+type StartSsoResponse struct {
+	OrgName          string `json:"orgName"`
+	AuthorizationUrl string `json:"authorizationUrl"`
+}
+
+type SsoSignInRequest struct {
+	Email        string `json:"email"`
+	Code         string `json:"code"`
+	CodeVerifier string `json:"codeVerifier"`
+	RedirectUri  string `json:"redirectUri"`
+	Nonce        string `json:"nonce"`
+}
+
+type StartSsoDeviceRequest struct {
+	Email string `json:"email"`
+}
+
+type StartSsoDeviceResponse struct {
+	OrgName                 string `json:"orgName"`
+	DeviceCode              string `json:"deviceCode"`
+	UserCode                string `json:"userCode"`
+	VerificationUri         string `json:"verificationUri"`
+	VerificationUriComplete string `json:"verificationUriComplete"`
+	ExpiresIn               int    `json:"expiresIn"`
+	Interval                int    `json:"interval"`
+}
+
+type SsoDevicePollRequest struct {
+	Email      string `json:"email"`
+	DeviceCode string `json:"deviceCode"`
+}
+
+type SsoDevicePollResponse struct {
+	Status SsoDeviceStatus `json:"status"`
+
+	// only set once the status is complete
+	Session *SessionResponse `json:"session,omitempty"`
+}
+
+type CheckExecPolicyRequest struct {
+	Script string `json:"script"`
+
+	// the project's policy file, if there is one -- the org's policy is applied on the server
+	ProjectPolicy *ExecPolicy `json:"projectPolicy"`
+}
+
+type CheckExecPolicyResponse struct {
+	Commands    []*ExecCommand         `json:"commands"`
+	Violations  []*ExecPolicyViolation `json:"violations"`
+	OnViolation ExecPolicyOnViolation  `json:"onViolation"`
+}
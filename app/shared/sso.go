@@ -0,0 +1,64 @@
+package shared
+
+import (
+	"crypto/sha256"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const SsoDefaultScopes = "openid email profile"
+
+// SsoRoleMapping maps values of an ID token claim (like a group name) to org role names -- stored as a json column
+type SsoRoleMapping map[string]string
+
+func (m *SsoRoleMapping) Scan(src interface{}) error {
+	if src == nil {
+		*m = nil
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, m)
+	case string:
+		return json.Unmarshal([]byte(s), m)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (m SsoRoleMapping) Value() (driver.Value, error) {
+	if m == nil {
+		m = SsoRoleMapping{}
+	}
+	return json.Marshal(m)
+}
+
+// SsoConfig is an org's OIDC single sign-on config. The client secret is write-only, so it's never included.
+type SsoConfig struct {
+	Domain          string         `json:"domain"`
+	IssuerUrl       string         `json:"issuerUrl"`
+	ClientId        string         `json:"clientId"`
+	HasClientSecret bool           `json:"hasClientSecret"`
+	Scopes          string         `json:"scopes"`
+	RoleClaim       string         `json:"roleClaim"`
+	RoleMapping     SsoRoleMapping `json:"roleMapping"`
+	CreatedAt       time.Time      `json:"createdAt"`
+	UpdatedAt       time.Time      `json:"updatedAt"`
+}
+
+// SsoCodeChallenge is the PKCE S256 code challenge for a code verifier
+func SsoCodeChallenge(codeVerifier string) string {
+	hash := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
+type SsoDeviceStatus string
+
+const (
+	SsoDeviceStatusPending  SsoDeviceStatus = "pending"
+	SsoDeviceStatusSlowDown SsoDeviceStatus = "slow_down"
+	SsoDeviceStatusComplete SsoDeviceStatus = "complete"
+)
@@ -9,6 +9,14 @@ type BuildInfo struct {
 	Removed   bool   `json:"removed,omitempty"`
 }
 
+// sent when a model request fails after all retries and the request is switched to the next model in the role's error fallback chain
+type ModelFallbackInfo struct {
+	Role      ModelRole `json:"role"`
+	FromModel ModelId   `json:"fromModel"`
+	ToModel   ModelId   `json:"toModel"`
+	Reason    string    `json:"reason"`
+}
+
 type StreamMessageType string
 
 const (
@@ -24,6 +32,8 @@ const (
 	StreamMessageAborted           StreamMessageType = "aborted"
 	StreamMessageFinished          StreamMessageType = "finished"
 	StreamMessageError             StreamMessageType = "error"
+	StreamMessageModelFallback     StreamMessageType = "modelFallback"
+	StreamMessageBudgetWarning     StreamMessageType = "budgetWarning"
 
 	StreamMessageMulti StreamMessageType = "multi"
 )
@@ -36,6 +46,8 @@ type StreamMessage struct {
 	BuildInfo              *BuildInfo               `json:"buildInfo,omitempty"`
 	Description            *ConvoMessageDescription `json:"description,omitempty"`
 	Error                  *ApiError                `json:"error,omitempty"`
+	ModelFallback          *ModelFallbackInfo       `json:"modelFallback,omitempty"`
+	BudgetWarning          *BudgetStatus            `json:"budgetWarning,omitempty"`
 	MissingFilePath        string                   `json:"missingFilePath,omitempty"`
 	MissingFileAutoContext bool                     `json:"missingFileAutoContext,omitempty"`
 	ModelStreamId          string                   `json:"modelStreamId,omitempty"`
@@ -33,12 +33,14 @@ const (
 	LanguageRuby       Language = "ruby"
 	LanguageRust       Language = "rust"
 	LanguageScala      Language = "scala"
+	LanguageSql        Language = "sql"
 	LanguageSvelte     Language = "svelte"
 	LanguageSwift      Language = "swift"
 	LanguageToml       Language = "toml"
 	LanguageTypescript Language = "typescript"
 	LanguageJsx        Language = "jsx"
 	LanguageTsx        Language = "tsx"
+	LanguageVue        Language = "vue"
 	LanguageYaml       Language = "yaml"
 	LanguageMarkdown   Language = "markdown"
 )
@@ -70,12 +72,14 @@ var Languages = []Language{
 	LanguageRuby,
 	LanguageRust,
 	LanguageScala,
+	LanguageSql,
 	LanguageSvelte,
 	LanguageSwift,
 	LanguageToml,
 	LanguageTypescript,
 	LanguageJsx,
 	LanguageTsx,
+	LanguageVue,
 	LanguageYaml,
 }
 
@@ -160,11 +164,13 @@ var LanguageByExtension = map[string]Language{
 	".rb":     LanguageRuby,
 	".rs":     LanguageRust,
 	".scala":  LanguageScala,
+	".sql":    LanguageSql,
 	".svelte": LanguageSvelte,
 	".swift":  LanguageSwift,
 	".toml":   LanguageToml,
 	".ts":     LanguageTypescript,
 	".tsx":    LanguageTsx,
+	".vue":    LanguageVue,
 	".yaml":   LanguageYaml,
 	".yml":    LanguageYaml,
 	".md":     LanguageMarkdown,
@@ -0,0 +1,140 @@
+package shared
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+type WebhookEvent string
+
+const (
+	WebhookEventPlanStreamFinished WebhookEvent = "plan.stream_finished"
+	WebhookEventPlanBuildFinished  WebhookEvent = "plan.build_finished"
+	WebhookEventPlanApplied        WebhookEvent = "plan.applied"
+	WebhookEventContextLoaded      WebhookEvent = "plan.context_loaded"
+	WebhookEventPlanError          WebhookEvent = "plan.error"
+
+	// sent by 'plandex webhooks test' -- webhooks always receive it regardless of their events
+	WebhookEventPing WebhookEvent = "ping"
+)
+
+var WebhookEvents = []WebhookEvent{
+	WebhookEventPlanStreamFinished,
+	WebhookEventPlanBuildFinished,
+	WebhookEventPlanApplied,
+	WebhookEventContextLoaded,
+	WebhookEventPlanError,
+}
+
+var WebhookEventDescriptions = map[WebhookEvent]string{
+	WebhookEventPlanStreamFinished: "a plan's model response stream finished",
+	WebhookEventPlanBuildFinished:  "pending changes finished building",
+	WebhookEventPlanApplied:        "pending changes were applied",
+	WebhookEventContextLoaded:      "context was loaded into a plan",
+	WebhookEventPlanError:          "a plan stream failed with an error",
+}
+
+func IsValidWebhookEvent(event WebhookEvent) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookEventList is stored as a json column
+type WebhookEventList []WebhookEvent
+
+func (l WebhookEventList) Includes(event WebhookEvent) bool {
+	for _, e := range l {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *WebhookEventList) Scan(src interface{}) error {
+	if src == nil {
+		*l = nil
+		return nil
+	}
+	switch s := src.(type) {
+	case []byte:
+		return json.Unmarshal(s, l)
+	case string:
+		return json.Unmarshal([]byte(s), l)
+	default:
+		return fmt.Errorf("unsupported data type: %T", src)
+	}
+}
+
+func (l WebhookEventList) Value() (driver.Value, error) {
+	if l == nil {
+		l = WebhookEventList{}
+	}
+	return json.Marshal(l)
+}
+
+type Webhook struct {
+	Id          string           `json:"id"`
+	Url         string           `json:"url"`
+	Description string           `json:"description"`
+	Events      WebhookEventList `json:"events"`
+	CreatedAt   time.Time        `json:"createdAt"`
+	UpdatedAt   time.Time        `json:"updatedAt"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryStatusPending   WebhookDeliveryStatus = "pending"
+	WebhookDeliveryStatusSucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryStatusFailed    WebhookDeliveryStatus = "failed"
+)
+
+type WebhookDelivery struct {
+	Id             string                `json:"id"`
+	WebhookId      string                `json:"webhookId"`
+	Event          WebhookEvent          `json:"event"`
+	Status         WebhookDeliveryStatus `json:"status"`
+	NumAttempts    int                   `json:"numAttempts"`
+	ResponseStatus *int                  `json:"responseStatus,omitempty"`
+	Error          *string               `json:"error,omitempty"`
+	CreatedAt      time.Time             `json:"createdAt"`
+	DeliveredAt    *time.Time            `json:"deliveredAt,omitempty"`
+}
+
+// WebhookEventData is the 'data' field of a webhook payload -- fields that don't apply to an event are omitted
+type WebhookEventData struct {
+	PlanId   string `json:"planId,omitempty"`
+	PlanName string `json:"planName,omitempty"`
+	Branch   string `json:"branch,omitempty"`
+	UserId   string `json:"userId,omitempty"`
+
+	// plan.applied
+	CommitMsg string `json:"commitMsg,omitempty"`
+
+	// plan.context_loaded
+	ContextNames []string `json:"contextNames,omitempty"`
+	TokensAdded  int      `json:"tokensAdded,omitempty"`
+	TotalTokens  int      `json:"totalTokens,omitempty"`
+
+	// plan.build_finished
+	Paths []string `json:"paths,omitempty"`
+
+	// plan.error
+	Error string `json:"error,omitempty"`
+}
+
+// WebhookPayload is the json body posted to a webhook's url
+type WebhookPayload struct {
+	DeliveryId string            `json:"deliveryId"`
+	Event      WebhookEvent      `json:"event"`
+	OrgId      string            `json:"orgId"`
+	CreatedAt  time.Time         `json:"createdAt"`
+	Data       *WebhookEventData `json:"data"`
+}
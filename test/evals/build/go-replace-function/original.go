@@ -0,0 +1,17 @@
+package config
+
+import "os"
+
+func Default() *Config {
+	return &Config{Port: 8080}
+}
+
+// Load reads the config file at path
+func Load(path string) (*Config, error) {
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(bytes)
+}
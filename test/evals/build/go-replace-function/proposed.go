@@ -0,0 +1,14 @@
+// ... existing code ...
+
+// Load reads the config file at path, or returns the default config if it doesn't exist
+func Load(path string) (*Config, error) {
+	bytes, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return Default(), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return parse(bytes)
+}
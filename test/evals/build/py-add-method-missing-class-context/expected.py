@@ -0,0 +1,12 @@
+class Engine:
+    def __init__(self):
+        self.running = False
+
+    def start(self):
+        self.running = True
+
+    def debug_status(self):
+        print(f"running: {self.running}")
+
+    def stop(self):
+        self.running = False
@@ -0,0 +1,6 @@
+# ... existing code ...
+
+def debug_status(self):
+    print(f"running: {self.running}")
+
+# ... existing code ...
@@ -0,0 +1,15 @@
+export class Counter {
+  private count = 0;
+
+  increment() {
+    this.count++;
+  }
+
+  get value() {
+    return this.count;
+  }
+
+  reset() {
+    this.count = 0;
+  }
+}
@@ -0,0 +1,11 @@
+export class Counter {
+  private count = 0;
+
+  increment() {
+    this.count++;
+  }
+
+  get value() {
+    return this.count;
+  }
+}
@@ -0,0 +1,11 @@
+export class Counter {
+  // ... existing code ...
+
+  get value() {
+    return this.count;
+  }
+
+  reset() {
+    this.count = 0;
+  }
+}